@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"workline/internal/config"
+	"workline/internal/db"
+	"workline/internal/repo"
+)
+
+// completionLimit caps the ids offered for one completion.
+const completionLimit = 200
+
+// refArgs names, by command path, the commands whose arguments are task,
+// iteration or decision ids.
+var refArgs = map[string]string{
+	"wl task get":               "task",
+	"wl task update":            "task",
+	"wl task bulk-update":       "task",
+	"wl task done":              "task",
+	"wl task claim":             "task",
+	"wl task release":           "task",
+	"wl task revert":            "task",
+	"wl task assign":            "task",
+	"wl task log-time":          "task",
+	"wl task time":              "task",
+	"wl task history":           "task",
+	"wl task relation add":      "task",
+	"wl task relation remove":   "task",
+	"wl task relation list":     "task",
+	"wl policy check":           "task",
+	"wl iteration set-capacity": "iteration",
+	"wl iteration progress":     "iteration",
+	"wl iteration set-status":   "iteration",
+	"wl decision get":           "decision",
+}
+
+// refFlags names the flags holding task or iteration ids on any command.
+var refFlags = map[string]string{
+	"task":      "task",
+	"iteration": "iteration",
+}
+
+// policyFlags name a policy preset on any command.
+var policyFlags = []string{"policy", "set-policy"}
+
+// kindFlags name an attestation kind on any command; --kind only means one
+// on the commands in kindCommands.
+var (
+	kindFlags    = []string{"require", "attestation"}
+	kindCommands = []string{"wl attest add", "wl attest list", "wl rbac allow-attestation", "wl rbac deny-attestation"}
+)
+
+func completionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Print a shell completion script",
+		Long: `Prints a completion script for the shell. Task, iteration and decision ids,
+policy presets and attestation kinds complete from the workspace database.
+
+  bash: source <(wl completion bash)
+  zsh:  wl completion zsh > "${fpath[1]}/_wl"
+  fish: wl completion fish > ~/.config/fish/completions/wl.fish`,
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(out)
+			case "fish":
+				return cmd.Root().GenFishCompletion(out, true)
+			}
+			return fmt.Errorf("unsupported shell %s", args[0])
+		},
+	}
+	return cmd
+}
+
+// registerCompletions attaches dynamic completions to cmd and its
+// subcommands: ids for the commands in refArgs and the flags in refFlags,
+// policy presets and attestation kinds for their flags.
+func registerCompletions(cmd *cobra.Command) {
+	path := cmd.CommandPath()
+	if !cmd.HasParent() {
+		_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]cobra.Completion{"table", "json", "yaml", "go-template="}, cobra.ShellCompDirectiveNoSpace|cobra.ShellCompDirectiveNoFileComp))
+	}
+	if kind, ok := refArgs[path]; ok {
+		cmd.ValidArgsFunction = completeRefArgs(kind)
+	}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Value.Type() == "bool" {
+			return
+		}
+		var fn cobra.CompletionFunc
+		switch {
+		case refFlags[f.Name] != "":
+			fn = completeRefs(refFlags[f.Name])
+		case slices.Contains(policyFlags, f.Name):
+			fn = completePolicies
+		case slices.Contains(kindFlags, f.Name), f.Name == "kind" && slices.Contains(kindCommands, path):
+			fn = completeAttestationKinds
+		case f.Name == "entity-id":
+			fn = completeEntityIDs
+		default:
+			return
+		}
+		_ = cmd.RegisterFlagCompletionFunc(f.Name, fn)
+	})
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+}
+
+// completeRefArgs completes the first argument, or every argument of a
+// command taking a list (`<id>...`), with ids of the kind.
+func completeRefArgs(kind string) cobra.CompletionFunc {
+	refs := completeRefs(kind)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 && !strings.HasSuffix(cmd.Use, "...") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return refs(cmd, args, toComplete)
+	}
+}
+
+// completeRefs completes ids of the kind starting with what was typed, with
+// their titles as descriptions.
+func completeRefs(kind string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return withCompletion(cmd, func(ctx context.Context, r repo.Repo, cfg *config.Config) ([]cobra.Completion, error) {
+			refs, err := r.FindEntityRefs(ctx, kind, cfg.Project.ID, toComplete, completionLimit)
+			if err != nil {
+				return nil, err
+			}
+			var res []cobra.Completion
+			for _, ref := range refs {
+				if ref.Match == "title" || slices.Contains(args, ref.ID) {
+					continue
+				}
+				res = append(res, cobra.CompletionWithDesc(ref.ID, ref.Title))
+			}
+			return res, nil
+		})
+	}
+}
+
+// completeEntityIDs completes --entity-id with ids of the --entity-kind
+// given before it.
+func completeEntityIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	kind, _ := cmd.Flags().GetString("entity-kind")
+	switch kind {
+	case "task", "iteration", "decision":
+		return completeRefs(kind)(cmd, args, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePolicies completes policy preset names, those of --type when it
+// was given, with the task types using them as descriptions.
+func completePolicies(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return withCompletion(cmd, func(ctx context.Context, r repo.Repo, cfg *config.Config) ([]cobra.Completion, error) {
+		taskType, _ := cmd.Flags().GetString("type")
+		types := map[string][]string{}
+		for name, tt := range cfg.Project.TaskTypes {
+			if cmd.Flags().Changed("type") && name != taskType {
+				continue
+			}
+			for policy := range tt.Policies {
+				types[policy] = append(types[policy], name)
+			}
+		}
+		var res []cobra.Completion
+		for _, policy := range slices.Sorted(maps.Keys(types)) {
+			slices.Sort(types[policy])
+			res = append(res, cobra.CompletionWithDesc(policy, strings.Join(types[policy], ", ")))
+		}
+		return res, nil
+	})
+}
+
+// completeAttestationKinds completes the attestation kinds of the project's
+// catalog with their descriptions.
+func completeAttestationKinds(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return withCompletion(cmd, func(ctx context.Context, r repo.Repo, cfg *config.Config) ([]cobra.Completion, error) {
+		var res []cobra.Completion
+		for _, att := range cfg.Project.Attestations {
+			res = append(res, cobra.CompletionWithDesc(att.ID, att.Description))
+		}
+		return res, nil
+	})
+}
+
+// withCompletion runs fn against the workspace database and the current
+// project's config. Completing never creates a workspace, project or config
+// and never reports an error: without them there is nothing to offer.
+func withCompletion(cmd *cobra.Command, fn func(context.Context, repo.Repo, *config.Config) ([]cobra.Completion, error)) ([]cobra.Completion, cobra.ShellCompDirective) {
+	const directive = cobra.ShellCompDirectiveNoFileComp
+	workspace, err := workspaceFromFlag(cmd)
+	if err != nil {
+		return nil, directive
+	}
+	if _, err := os.Stat(db.Path(workspace)); err != nil {
+		return nil, directive
+	}
+	conn, err := db.Open(db.Config{Workspace: workspace})
+	if err != nil {
+		return nil, directive
+	}
+	defer conn.Close()
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := repo.Repo{DB: conn}
+	projectID := viper.GetString("project")
+	if projectID == "" {
+		projectID = os.Getenv("WORKLINE_DEFAULT_PROJECT")
+	}
+	if id, err := r.ResolveProjectAlias(ctx, projectID); err == nil {
+		projectID = id
+	}
+	cfg, err := r.GetProjectConfig(ctx, projectID)
+	if err != nil {
+		return nil, directive
+	}
+	cfg.Project.ID = projectID
+	res, err := fn(ctx, r, cfg)
+	if err != nil {
+		return nil, directive
+	}
+	return res, directive
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"workline/internal/server"
+)
+
+// Exit statuses of wl. Scripts branch on them, so they never change meaning.
+const (
+	exitError      = 1 // any other failure
+	exitUsage      = 2 // bad flags, arguments or input
+	exitValidation = 3 // policy, workflow or payload validation failed
+	exitForbidden  = 4 // missing permission, role or attestation authority
+	exitNotFound   = 5
+	exitLease      = 6 // the task's lease is held by someone else, expired or missing
+	exitConflict   = 7 // any other conflict: duplicate id, WIP or capacity limit, pending force
+)
+
+// cliError is the error object printed with --json, shaped like the API's
+// error envelope plus the exit status.
+type cliError struct {
+	Code       string         `json:"code"`
+	Message    string         `json:"message"`
+	ExitStatus int            `json:"exit_status"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// usageError marks a bad command line rejected before the command ran.
+type usageError struct {
+	cmd string
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// gateFailedError reports tasks failing `wl gate`, whose results were
+// already printed.
+type gateFailedError struct{ failed, total int }
+
+func (e gateFailedError) Error() string {
+	return fmt.Sprintf("gate failed for %d of %d tasks", e.failed, e.total)
+}
+
+// describeError maps err to its stable code, as the API reports it, and
+// exit status.
+func describeError(err error) cliError {
+	var ue usageError
+	if errors.As(err, &ue) {
+		return cliError{Code: "usage", Message: err.Error(), ExitStatus: exitUsage, Details: map[string]any{"command": ue.cmd}}
+	}
+	var gf gateFailedError
+	if errors.As(err, &gf) {
+		return cliError{Code: "gate_failed", Message: err.Error(), ExitStatus: exitValidation, Details: map[string]any{"failed": gf.failed, "total": gf.total}}
+	}
+	status, code, message, details := server.DescribeError(err)
+	out := cliError{Code: code, Message: message, Details: details, ExitStatus: exitError}
+	switch {
+	case code == "lease_conflict":
+		out.ExitStatus = exitLease
+	case status == http.StatusBadRequest, status == http.StatusRequestEntityTooLarge:
+		out.ExitStatus = exitUsage
+	case status == http.StatusUnprocessableEntity:
+		out.ExitStatus = exitValidation
+	case status == http.StatusForbidden:
+		out.ExitStatus = exitForbidden
+	case status == http.StatusNotFound:
+		out.ExitStatus = exitNotFound
+	case status == http.StatusConflict:
+		out.ExitStatus = exitConflict
+	}
+	return out
+}
+
+// reportError prints err, as {"error": {...}} on stdout with --output json
+// or yaml, and returns the exit status. Cobra's own error and usage output
+// is silenced.
+func reportError(err error) int {
+	ce := describeError(err)
+	format, _ := outputFormat()
+	if format == "table" {
+		// A command line that failed to parse never set --output.
+		format = outputFromArgs(os.Args[1:])
+	}
+	if structuredOutput() && ce.Code == "gate_failed" {
+		// The gate results on stdout already say which tasks failed.
+		return ce.ExitStatus
+	}
+	switch format {
+	case "json", "yaml":
+		_ = printAs(format, "", map[string]cliError{"error": ce})
+	default:
+		fmt.Println("error:", ce.Message)
+		if ce.Code == "usage" {
+			fmt.Printf("run '%s --help' for usage\n", ce.Details["command"])
+		}
+	}
+	return ce.ExitStatus
+}
+
+// outputFromArgs finds --json or --output in raw arguments.
+func outputFromArgs(args []string) string {
+	for i, a := range args {
+		switch {
+		case a == "--json":
+			return "json"
+		case (a == "--output" || a == "-o") && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(a, "--output="):
+			return strings.TrimPrefix(a, "--output=")
+		case strings.HasPrefix(a, "-o") && len(a) > 2:
+			return strings.TrimPrefix(strings.TrimPrefix(a, "-o"), "=")
+		}
+	}
+	return ""
+}
+
+// markUsageErrors makes flag and argument errors of cmd and its subcommands
+// usage errors.
+func markUsageErrors(cmd *cobra.Command) {
+	if !cmd.HasParent() {
+		cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+			return usageError{c.CommandPath(), err}
+		})
+	}
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return usageError{c.CommandPath(), err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
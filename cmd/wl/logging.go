@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"workline/internal/db"
+)
+
+// cliLog writes diagnostics to stderr, leaving stdout to command output.
+var cliLog = log.New(os.Stderr, "", 0)
+
+func quiet() bool   { return viper.GetBool("quiet") }
+func verbose() bool { return viper.GetBool("verbose") }
+
+func checkVerbosity() error {
+	if quiet() && verbose() {
+		return fmt.Errorf("--quiet and --verbose cannot be combined")
+	}
+	return nil
+}
+
+// infof prints a confirmation or progress message, unless --quiet.
+func infof(format string, args ...any) {
+	if !quiet() {
+		fmt.Printf(format, args...)
+	}
+}
+
+// warnf logs a warning, unless --quiet.
+func warnf(format string, args ...any) {
+	if !quiet() {
+		cliLog.Printf("warning: "+format, args...)
+	}
+}
+
+// debugf logs a diagnostic with --verbose.
+func debugf(format string, args ...any) {
+	if verbose() {
+		cliLog.Printf(format, args...)
+	}
+}
+
+// sqlTrace logs statements and their timing with --verbose.
+func sqlTrace() db.TraceFunc {
+	if !verbose() {
+		return nil
+	}
+	return func(query string, elapsed time.Duration, err error) {
+		query = strings.Join(strings.Fields(query), " ")
+		if err != nil {
+			debugf("sql: %s (%s): %v", query, elapsed.Round(time.Microsecond), err)
+			return
+		}
+		debugf("sql: %s (%s)", query, elapsed.Round(time.Microsecond))
+	}
+}
+
+// traceHTTP logs outgoing HTTP requests (Jira imports, webhooks and hooks,
+// S3 evidence) with --verbose. Clients without their own transport use
+// http.DefaultTransport, which it wraps.
+func traceHTTP() {
+	if verbose() {
+		http.DefaultTransport = httpTrace{next: http.DefaultTransport}
+	}
+}
+
+type httpTrace struct{ next http.RoundTripper }
+
+func (t httpTrace) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	u := *req.URL
+	u.User, u.RawQuery = nil, ""
+	if err != nil {
+		debugf("http: %s %s (%s): %v", req.Method, u.String(), time.Since(start).Round(time.Millisecond), err)
+		return res, err
+	}
+	debugf("http: %s %s -> %d (%s)", req.Method, u.String(), res.StatusCode, time.Since(start).Round(time.Millisecond))
+	return res, nil
+}
+
+// printIDs prints, with --quiet, the id of v or of each of its items (their
+// first field when they have no id) and reports whether it did.
+func printIDs(v any) bool {
+	if !quiet() {
+		return false
+	}
+	generic, err := toGeneric(v)
+	if err != nil {
+		return false
+	}
+	items, ok := generic.([]any)
+	if !ok {
+		items = []any{generic}
+	}
+	fields := jsonFields(reflect.TypeOf(v), items)
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		switch {
+		case !ok:
+			fmt.Println(cell(item))
+		case m["id"] != nil:
+			fmt.Println(cell(m["id"]))
+		case len(fields) > 0:
+			fmt.Println(cell(m[fields[0]]))
+		}
+	}
+	return true
+}
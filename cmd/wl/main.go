@@ -2,31 +2,50 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/tls"
+	"database/sql"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"workline/internal/app"
+	"workline/internal/blob"
+	"workline/internal/cluster"
 	"workline/internal/config"
 	"workline/internal/db"
 	"workline/internal/domain"
 	"workline/internal/engine"
+	"workline/internal/fieldcrypt"
 	"workline/internal/migrate"
+	"workline/internal/outbox"
 	"workline/internal/repo"
 	"workline/internal/server"
+	worklinesdk "workline/sdk/go"
 )
 
 var rootCmd = &cobra.Command{
@@ -47,7 +66,25 @@ Core concepts (kid-friendly):
 - Leases: temporary "I’m working on this" tags (wl task claim/release).
 - Event log: diary of changes, view with 'wl log tail'.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		workspace := viper.GetString("workspace")
+		workspace, err := workspaceFromFlag(cmd)
+		if err != nil {
+			return err
+		}
+		viper.Set("workspace", workspace)
+		if err := checkOutput(); err != nil {
+			return usageError{cmd.CommandPath(), err}
+		}
+		if err := checkVerbosity(); err != nil {
+			return usageError{cmd.CommandPath(), err}
+		}
+		traceHTTP()
+		if cmd.HasParent() && (cmd.Parent().Name() == "workspace" || cmd.Parent().Name() == "openapi") {
+			return nil
+		}
+		// Completion scripts and requests never create a workspace.
+		if cmd.Name() == "completion" || cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			return nil
+		}
 		if _, err := db.EnsureWorkspace(workspace); err != nil {
 			return err
 		}
@@ -55,13 +92,27 @@ Core concepts (kid-friendly):
 	},
 }
 
+// workspaceFromFlag resolves --workspace, which may name a registered
+// workspace, to a directory.
+func workspaceFromFlag(cmd *cobra.Command) (string, error) {
+	regPath, err := app.RegistryPath()
+	if err != nil {
+		return "", err
+	}
+	reg, err := app.LoadRegistry(regPath)
+	if err != nil {
+		return "", err
+	}
+	flag := cmd.Root().PersistentFlags().Lookup("workspace")
+	return app.ResolveWorkspace(flag.Value.String(), flag.Changed, reg), nil
+}
+
 func main() {
 	cobra.OnInitialize(initConfig)
 	addPersistentFlags()
 	registerCommands()
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println("error:", err)
-		os.Exit(1)
+		os.Exit(reportError(err))
 	}
 }
 
@@ -73,31 +124,189 @@ func initConfig() {
 
 func addPersistentFlags() {
 	rootCmd.PersistentFlags().StringP("workspace", "w", ".", "workspace directory")
-	rootCmd.PersistentFlags().Bool("json", false, "output JSON")
+	rootCmd.PersistentFlags().Bool("json", false, "output JSON (same as --output json)")
+	rootCmd.PersistentFlags().StringP("output", "o", "table", outputUsage)
+	rootCmd.PersistentFlags().String("columns", "", "comma-separated fields to show in tables, e.g. id,title,status")
 	rootCmd.PersistentFlags().String("actor-id", "local-user", "actor identifier")
 	rootCmd.PersistentFlags().Bool("force", false, "force operation")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "print only ids, one per line, and no messages (for piping)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "log SQL statements with their timing and outgoing HTTP requests to stderr")
 	rootCmd.PersistentFlags().String("project", "", "project id (overrides config default)")
+	rootCmd.PersistentFlags().Bool("auto-migrate", false, "upgrade an outdated database schema instead of refusing to run")
+	rootCmd.PersistentFlags().Bool("local", false, "show table timestamps in the project timezone (project.calendar.timezone), or the system's when unset")
 	_ = viper.BindPFlag("workspace", rootCmd.PersistentFlags().Lookup("workspace"))
 	_ = viper.BindPFlag("json", rootCmd.PersistentFlags().Lookup("json"))
+	_ = viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	_ = viper.BindPFlag("columns", rootCmd.PersistentFlags().Lookup("columns"))
 	_ = viper.BindPFlag("actor-id", rootCmd.PersistentFlags().Lookup("actor-id"))
 	_ = viper.BindPFlag("force", rootCmd.PersistentFlags().Lookup("force"))
+	_ = viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+	_ = viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	_ = viper.BindPFlag("project", rootCmd.PersistentFlags().Lookup("project"))
+	_ = viper.BindPFlag("auto-migrate", rootCmd.PersistentFlags().Lookup("auto-migrate"))
+	_ = viper.BindPFlag("local", rootCmd.PersistentFlags().Lookup("local"))
 }
 
 func registerCommands() {
+	rootCmd.AddCommand(initCmd())
+	rootCmd.AddCommand(seedCmd())
+	rootCmd.AddCommand(workspaceCmd())
+	rootCmd.AddCommand(orgCmd())
 	rootCmd.AddCommand(projectCmd())
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(tuiCmd())
 	rootCmd.AddCommand(taskCmd())
 	rootCmd.AddCommand(iterationCmd())
+	rootCmd.AddCommand(milestoneCmd())
+	rootCmd.AddCommand(releaseCmd())
+	rootCmd.AddCommand(changelogCmd())
 	rootCmd.AddCommand(decisionCmd())
 	rootCmd.AddCommand(attestCmd())
 	rootCmd.AddCommand(logCmd())
+	rootCmd.AddCommand(projectionCmd())
+	rootCmd.AddCommand(outboxCmd())
 	rootCmd.AddCommand(serveCmd())
+	rootCmd.AddCommand(openapiCmd())
+	rootCmd.AddCommand(dbCmd())
 	rootCmd.AddCommand(rbacCmd())
 	rootCmd.AddCommand(missionCmd())
+	rootCmd.AddCommand(actorCmd())
 	rootCmd.AddCommand(validationCmd())
+	rootCmd.AddCommand(policyCmd())
+	rootCmd.AddCommand(forceCmd())
+	rootCmd.AddCommand(webhookCmd())
+	rootCmd.AddCommand(digestCmd())
+	rootCmd.AddCommand(calendarCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(syncCmd())
+	rootCmd.AddCommand(remoteCmd())
+	rootCmd.AddCommand(queueCmd())
+	rootCmd.AddCommand(gitCmd())
+	rootCmd.AddCommand(gateCmd())
 	rootCmd.AddCommand(apiKeyCmd())
+	rootCmd.AddCommand(completionCmd())
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	registerCompletions(rootCmd)
+	markUsageErrors(rootCmd)
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+}
+
+func orgCmd() *cobra.Command {
+	org := &cobra.Command{
+		Use:   "org",
+		Short: "Manage organizations",
+		Long:  "Orgs group projects and people. Org owners and admins manage membership; JWT tokens are scoped to one org.",
+	}
+	org.AddCommand(orgCreateCmd())
+	org.AddCommand(orgListCmd())
+	org.AddCommand(orgMembersCmd())
+	org.AddCommand(orgSetMemberCmd())
+	org.AddCommand(orgRemoveMemberCmd())
+	return org
+}
+
+func orgCreateCmd() *cobra.Command {
+	var id, name string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create organization",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				e := engine.New(r.DB, nil)
+				org, err := e.CreateOrg(ctx, id, name, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(org)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&id, "id", "", "org id")
+	cmd.Flags().StringVar(&name, "name", "", "display name")
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func orgListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List organizations of the current actor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				items, err := r.ListOrgsForActor(ctx, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	return cmd
+}
+
+func orgMembersCmd() *cobra.Command {
+	var orgID string
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "List organization members",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				items, err := r.ListOrgMembers(ctx, orgID)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&orgID, "org", "", "org id")
+	_ = cmd.MarkFlagRequired("org")
+	return cmd
+}
+
+func orgSetMemberCmd() *cobra.Command {
+	var orgID, target, role string
+	cmd := &cobra.Command{
+		Use:   "set-member",
+		Short: "Add member or change org role",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				e := engine.New(r.DB, nil)
+				m, err := e.SetOrgMember(ctx, orgID, viper.GetString("actor-id"), target, role)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(m)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&orgID, "org", "", "org id")
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	cmd.Flags().StringVar(&role, "role", engine.OrgRoleMember, "org role (owner, admin, member)")
+	_ = cmd.MarkFlagRequired("org")
+	_ = cmd.MarkFlagRequired("actor")
+	return cmd
+}
+
+func orgRemoveMemberCmd() *cobra.Command {
+	var orgID, target string
+	cmd := &cobra.Command{
+		Use:   "remove-member",
+		Short: "Remove organization member",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				e := engine.New(r.DB, nil)
+				return e.RemoveOrgMember(ctx, orgID, viper.GetString("actor-id"), target)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&orgID, "org", "", "org id")
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	_ = cmd.MarkFlagRequired("org")
+	_ = cmd.MarkFlagRequired("actor")
+	return cmd
 }
 
 func projectCmd() *cobra.Command {
@@ -107,18 +316,272 @@ func projectCmd() *cobra.Command {
 	prj.AddCommand(projectShowCmd())
 	prj.AddCommand(projectUpdateCmd())
 	prj.AddCommand(projectDeleteCmd())
+	prj.AddCommand(projectRenameCmd())
 	prj.AddCommand(projectConfigCmd())
 	prj.AddCommand(projectUseCmd())
+	prj.AddCommand(projectVerifyCmd())
 	return prj
 }
 
+func initCmd() *cobra.Command {
+	var projectID, orgID, desc, kind, preset string
+	var overwrite bool
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up a workspace with a project and starter config",
+		Long:  "Creates the .workline directory and database, writes workline.yml from the starter config of the project kind (" + strings.Join(config.Kinds(), ", ") + ") or --preset, creates the project with you as owner, and makes it the default project. Missing values are asked for when run in a terminal.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := bufio.NewReader(os.Stdin)
+			interactive := isTerminal(os.Stdin)
+			if projectID == "" && interactive {
+				projectID = prompt(in, "Project id", "")
+			}
+			if strings.TrimSpace(projectID) == "" {
+				return fmt.Errorf("--project-id required")
+			}
+			if !cmd.Flags().Changed("kind") && interactive {
+				kind = prompt(in, "Kind ("+strings.Join(config.Kinds(), "/")+")", kind)
+			}
+			var err error
+			if kind, err = config.NormalizeKind(kind); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("preset") {
+				preset = kind
+			}
+			if !cmd.Flags().Changed("description") && interactive {
+				desc = prompt(in, "Description", desc)
+			}
+			data, err := config.PresetYAML(preset, projectID)
+			if err != nil {
+				return err
+			}
+			cfg, err := config.FromYAML([]byte(data))
+			if err != nil {
+				return err
+			}
+			if err := cfg.CheckKind(kind); err != nil {
+				return fmt.Errorf("preset %s: %w", preset, err)
+			}
+			workspace := viper.GetString("workspace")
+			if _, err := db.EnsureWorkspace(workspace); err != nil {
+				return err
+			}
+			cfgPath := config.Path(workspace)
+			if _, err := os.Stat(cfgPath); err == nil && !overwrite {
+				return fmt.Errorf("%s already exists; use --force-config to overwrite", cfgPath)
+			}
+			conn, err := db.Open(db.Config{Workspace: workspace, Trace: sqlTrace()})
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			if err := migrate.Migrate(conn); err != nil {
+				return err
+			}
+			e := engine.New(conn, cfg)
+			if _, err := e.Repo.GetProject(cmd.Context(), projectID); err == nil {
+				return fmt.Errorf("project %s already exists", projectID)
+			} else if !errors.Is(err, repo.ErrNotFound) {
+				return err
+			}
+			if err := os.WriteFile(cfgPath, []byte(data), 0o644); err != nil {
+				return err
+			}
+			p, err := e.InitProject(cmd.Context(), projectID, orgID, kind, desc, viper.GetString("actor-id"))
+			if err != nil {
+				return err
+			}
+			if err := setEnvValue(filepath.Join(workspace, ".env"), "WORKLINE_DEFAULT_PROJECT", projectID); err != nil {
+				return err
+			}
+			return printJSONOrTable(map[string]any{
+				"project":  p,
+				"preset":   preset,
+				"config":   cfgPath,
+				"database": db.Path(workspace),
+				"owner":    viper.GetString("actor-id"),
+			})
+		},
+	}
+	cmd.Flags().StringVar(&projectID, "project-id", "", "project id")
+	cmd.Flags().StringVar(&orgID, "org-id", "default-org", "organization id")
+	cmd.Flags().StringVar(&desc, "description", "", "description")
+	cmd.Flags().StringVar(&kind, "kind", config.DefaultKind, "project kind: "+strings.Join(config.Kinds(), ", "))
+	cmd.Flags().StringVar(&preset, "preset", "", "starter config (default: the kind's): "+strings.Join(config.Presets(), ", "))
+	cmd.Flags().BoolVar(&overwrite, "force-config", false, "overwrite an existing workline.yml")
+	return cmd
+}
+
+func seedCmd() *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Load projects and their contents from a fixture file",
+		Long:  "Creates every project in the file with its config, role grants, API keys, iterations, tasks and attestations, owned by --actor-id. IDs the file leaves out are derived from the project and position, so seeding the same file into a fresh workspace is reproducible. See examples/seed.yml.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := app.LoadSeed(file)
+			if err != nil {
+				return err
+			}
+			workspace := viper.GetString("workspace")
+			if _, err := db.EnsureWorkspace(workspace); err != nil {
+				return err
+			}
+			conn, err := openDB(workspace)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			summaries, err := app.SeedWorkspace(cmd.Context(), conn, s, viper.GetString("actor-id"))
+			if err != nil {
+				return err
+			}
+			return printJSONOrTable(summaries)
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "seed file (YAML)")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func workspaceCmd() *cobra.Command {
+	ws := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage registered workspaces",
+		Long:  "Registered workspaces live in ~/.config/workline/workspaces.yaml (override with WORKLINE_REGISTRY). The workspace used is --workspace, then WORKLINE_WORKSPACE, then the registered default; either may name a registered workspace instead of a path.",
+	}
+	ws.AddCommand(workspaceAddCmd())
+	ws.AddCommand(workspaceListCmd())
+	ws.AddCommand(workspaceUseCmd())
+	ws.AddCommand(workspaceRemoveCmd())
+	return ws
+}
+
+// withRegistry loads the workspace registry and saves it after fn when save is set.
+func withRegistry(save bool, fn func(*app.WorkspaceRegistry) error) error {
+	path, err := app.RegistryPath()
+	if err != nil {
+		return err
+	}
+	reg, err := app.LoadRegistry(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(reg); err != nil {
+		return err
+	}
+	if save {
+		return reg.Save(path)
+	}
+	return nil
+}
+
+func workspaceAddCmd() *cobra.Command {
+	var makeDefault bool
+	cmd := &cobra.Command{
+		Use:   "add <name> [path]",
+		Short: "Register a workspace (defaults to the current one)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := viper.GetString("workspace")
+			if len(args) == 2 {
+				path = args[1]
+			}
+			return withRegistry(true, func(reg *app.WorkspaceRegistry) error {
+				added, err := reg.Add(args[0], path)
+				if err != nil {
+					return err
+				}
+				if makeDefault || reg.Default == "" {
+					reg.Default = added.Name
+				}
+				return printJSONOrTable(added)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&makeDefault, "default", false, "make it the default workspace")
+	return cmd
+}
+
+func workspaceListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered workspaces",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRegistry(false, func(reg *app.WorkspaceRegistry) error {
+				return printJSONOrTable(reg)
+			})
+		},
+	}
+	return cmd
+}
+
+func workspaceUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a registered workspace the default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRegistry(true, func(reg *app.WorkspaceRegistry) error {
+				if err := reg.Use(args[0]); err != nil {
+					return err
+				}
+				infof("Default workspace: %s\n", args[0])
+				return nil
+			})
+		},
+	}
+	return cmd
+}
+
+func workspaceRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Forget a registered workspace (files are kept)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withRegistry(true, func(reg *app.WorkspaceRegistry) error {
+				return reg.Remove(args[0])
+			})
+		},
+	}
+	return cmd
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too but nobody is typing into it.
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+func prompt(in *bufio.Reader, label, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, _ := in.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return def
+}
+
 func projectListCmd() *cobra.Command {
+	var all bool
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
-				items, err := r.ListProjects(ctx)
+				items, err := r.ListProjects(ctx, all)
 				if err != nil {
 					return err
 				}
@@ -126,11 +589,12 @@ func projectListCmd() *cobra.Command {
 			})
 		},
 	}
+	cmd.Flags().BoolVar(&all, "all", false, "include archived projects")
 	return cmd
 }
 
 func projectCreateCmd() *cobra.Command {
-	var id, orgID, desc string
+	var id, orgID, kind, desc string
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create project",
@@ -142,7 +606,7 @@ func projectCreateCmd() *cobra.Command {
 			if _, err := db.EnsureWorkspace(workspace); err != nil {
 				return err
 			}
-			conn, err := db.Open(db.Config{Workspace: workspace})
+			conn, err := db.Open(db.Config{Workspace: workspace, Trace: sqlTrace()})
 			if err != nil {
 				return err
 			}
@@ -150,9 +614,12 @@ func projectCreateCmd() *cobra.Command {
 			if err := migrate.Migrate(conn); err != nil {
 				return err
 			}
-			cfg := config.Default(id)
+			cfg, err := config.ForKind(kind, id)
+			if err != nil {
+				return err
+			}
 			e := engine.New(conn, cfg)
-			p, err := e.InitProject(cmd.Context(), id, orgID, desc, viper.GetString("actor-id"))
+			p, err := e.InitProject(cmd.Context(), id, orgID, kind, desc, viper.GetString("actor-id"))
 			if err != nil {
 				return err
 			}
@@ -164,12 +631,55 @@ func projectCreateCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&id, "id", "", "project id")
 	cmd.Flags().StringVar(&orgID, "org-id", "", "organization id")
+	cmd.Flags().StringVar(&kind, "kind", config.DefaultKind, "project kind with its starter config: "+strings.Join(config.Kinds(), ", "))
 	cmd.Flags().StringVar(&desc, "description", "", "description")
 	_ = cmd.MarkFlagRequired("id")
 	_ = cmd.MarkFlagRequired("org-id")
 	return cmd
 }
 
+func projectVerifyCmd() *cobra.Command {
+	var flag bool
+	var format string
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Re-check completed tasks against their required attestations",
+		Long:  "Reports completed tasks that lack a required attestation (valid at completion or added since) or one the current policy for their type now requires, such as tasks completed with --force or before a policy was tightened. --flag records a compliance.flagged event on each (needs project.verify).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutputFormat(format); err != nil {
+				return err
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				report, err := e.VerifyProject(ctx, e.Config.Project.ID, viper.GetString("actor-id"), flag)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(report)
+				}
+				rows := make([][]string, 0, len(report.NonCompliant))
+				for _, i := range report.NonCompliant {
+					forced := ""
+					if i.Forced {
+						forced = "yes"
+					}
+					rows = append(rows, []string{i.TaskID, i.Title, i.CompletedAt, forced, strings.Join(i.Missing, ", "), strings.Join(i.Tightened, ", ")})
+				}
+				if format == "table" {
+					fmt.Printf("%d completed tasks checked, %d non-compliant\n", report.Checked, len(report.NonCompliant))
+					if len(rows) == 0 {
+						return nil
+					}
+				}
+				return renderRows(format, []string{"ID", "Title", "Completed", "Forced", "Missing", "Tightened"}, rows)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&flag, "flag", false, "record a compliance.flagged event per non-compliant task")
+	cmd.Flags().StringVar(&format, "format", "table", outputFormatUsage)
+	return cmd
+}
+
 func projectShowCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "show",
@@ -224,23 +734,107 @@ func projectUpdateCmd() *cobra.Command {
 }
 
 func projectDeleteCmd() *cobra.Command {
+	var confirm, archive string
+	var archiveFirst bool
 	cmd := &cobra.Command{
 		Use:   "delete",
 		Short: "Delete a project",
+		Long:  "Deletes the project with its tasks, iterations, attestations, leases, configs, events and evidence files, and records project.deleted in the global event log. --confirm must repeat the project id. --archive-first writes every row of the project to a gzip-compressed NDJSON archive before deleting.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			target := viper.GetString("project")
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
 				if target == "" {
 					target = e.Config.Project.ID
 				}
-				return e.Repo.DeleteProject(ctx, target)
+				if confirm != target {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("refusing to delete project %s: pass --confirm %s", target, target)
+				}
+				if archiveFirst && archive == "" {
+					dir := filepath.Join(viper.GetString("workspace"), ".workline", "archive")
+					if err := os.MkdirAll(dir, 0o755); err != nil {
+						return err
+					}
+					archive = filepath.Join(dir, fmt.Sprintf("project-%s-%s.ndjson.gz", target, time.Now().UTC().Format("20060102T150405Z")))
+				}
+				if !archiveFirst {
+					archive = ""
+				}
+				res, err := e.DeleteProject(ctx, engine.DeleteProjectOptions{
+					ProjectID:   target,
+					ActorID:     viper.GetString("actor-id"),
+					ArchivePath: archive,
+				})
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(res)
+				}
+				for _, w := range res.Warnings {
+					warnf("%s", w)
+				}
+				if printIDs(res) {
+					return nil
+				}
+				fmt.Printf("Deleted project %s\n", res.ProjectID)
+				for _, table := range slices.Sorted(maps.Keys(res.Rows)) {
+					fmt.Printf("  %s: %d\n", table, res.Rows[table])
+				}
+				if res.Archive != "" {
+					fmt.Printf("Archive: %s\n", res.Archive)
+				}
+				return nil
 			})
 		},
 	}
+	cmd.Flags().StringVar(&confirm, "confirm", "", "project id to delete, repeated as a safeguard")
+	cmd.Flags().BoolVar(&archiveFirst, "archive-first", false, "archive the project before deleting it")
+	cmd.Flags().StringVar(&archive, "archive", "", "archive file for --archive-first (default .workline/archive/project-<project>-<timestamp>.ndjson.gz)")
 	return cmd
 }
 
-func projectUseCmd() *cobra.Command {
+func projectRenameCmd() *cobra.Command {
+	var to string
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Change a project's id",
+		Long:  "Changes the project id in every table referencing it, in one transaction, and logs project.renamed. The former id keeps resolving as an alias in the API and the CLI. The workspace default project follows the rename.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(to) == "" {
+				return fmt.Errorf("--to required")
+			}
+			target := viper.GetString("project")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if target == "" {
+					target = e.Config.Project.ID
+				}
+				p, err := e.RenameProject(ctx, target, to, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				workspace := viper.GetString("workspace")
+				if os.Getenv("WORKLINE_DEFAULT_PROJECT") == target {
+					if err := setEnvValue(filepath.Join(workspace, ".env"), "WORKLINE_DEFAULT_PROJECT", p.ID); err != nil {
+						return err
+					}
+				}
+				if structuredOutput() {
+					return printStructured(p)
+				}
+				if printIDs(p) {
+					return nil
+				}
+				fmt.Printf("Renamed project %s to %s\n", target, p.ID)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "new project id")
+	return cmd
+}
+
+func projectUseCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "use <id>",
 		Short: "Set current project for this workspace",
@@ -254,7 +848,7 @@ func projectUseCmd() *cobra.Command {
 			if err := setEnvValue(filepath.Join(workspace, ".env"), "WORKLINE_DEFAULT_PROJECT", projectID); err != nil {
 				return err
 			}
-			fmt.Printf("Set WORKLINE_DEFAULT_PROJECT=%s in %s/.env\n", projectID, workspace)
+			infof("Set WORKLINE_DEFAULT_PROJECT=%s in %s/.env\n", projectID, workspace)
 			return nil
 		},
 	}
@@ -268,6 +862,9 @@ func projectConfigCmd() *cobra.Command {
 	}
 	cfg.AddCommand(projectConfigShowCmd())
 	cfg.AddCommand(projectConfigImportCmd())
+	cfg.AddCommand(projectConfigVersionsCmd())
+	cfg.AddCommand(projectConfigDiffCmd())
+	cfg.AddCommand(projectConfigRollbackCmd())
 	return cfg
 }
 
@@ -277,7 +874,12 @@ func projectConfigShowCmd() *cobra.Command {
 		Short: "Show project config stored in DB",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return printJSONOrTable(e.Config)
+				// Print the stored config so secret references are not expanded.
+				stored, err := e.Repo.GetProjectConfig(ctx, e.Config.Project.ID)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(stored)
 			})
 		},
 	}
@@ -286,6 +888,7 @@ func projectConfigShowCmd() *cobra.Command {
 
 func projectConfigImportCmd() *cobra.Command {
 	var filePath string
+	var dryRun bool
 	cmd := &cobra.Command{
 		Use:   "import",
 		Short: "Import project config from YAML into the DB",
@@ -303,7 +906,17 @@ func projectConfigImportCmd() *cobra.Command {
 				if projectID == "" {
 					projectID = e.Config.Project.ID
 				}
-				if err := e.Repo.UpsertProjectConfig(ctx, projectID, cfg); err != nil {
+				if dryRun {
+					plan, err := e.PlanProjectConfigImport(ctx, projectID, viper.GetString("actor-id"), cfg)
+					if err != nil {
+						return err
+					}
+					for _, w := range plan.Warnings {
+						warnf("%s", w)
+					}
+					return printJSONOrTable(plan)
+				}
+				if _, err := e.UpdateProjectConfig(ctx, projectID, viper.GetString("actor-id"), cfg); err != nil {
 					return err
 				}
 				return printJSONOrTable(cfg)
@@ -311,17 +924,86 @@ func projectConfigImportCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&filePath, "file", "", "path to YAML config")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what the import would change without storing it")
 	_ = cmd.MarkFlagRequired("file")
 	return cmd
 }
 
+func projectConfigVersionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "List stored project config versions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				versions, err := e.ProjectConfigVersions(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(versions)
+			})
+		},
+	}
+	return cmd
+}
+
+func projectConfigDiffCmd() *cobra.Command {
+	var from, to int
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Diff two project config versions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				changes, err := e.DiffProjectConfigVersions(ctx, e.Config.Project.ID, viper.GetString("actor-id"), from, to)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(changes)
+			})
+		},
+	}
+	cmd.Flags().IntVar(&from, "from", 0, "base version")
+	cmd.Flags().IntVar(&to, "to", 0, "target version (default latest)")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+func projectConfigRollbackCmd() *cobra.Command {
+	var version int
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore an earlier project config version",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				v, err := e.RollbackProjectConfig(ctx, e.Config.Project.ID, viper.GetString("actor-id"), version)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(v)
+			})
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "config version to restore")
+	_ = cmd.MarkFlagRequired("version")
+	return cmd
+}
+
 func statusCmd() *cobra.Command {
-	var projectID string
+	var projectID, format, orgID string
+	var all bool
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show project status",
-		Long:  "See the scoreboard for your project: current iteration, task counts, and overall project state.",
+		Long:  "See the scoreboard for your project: current iteration, task counts, and overall project state. With --all, one line per active project of this workspace and of every registered one (see wl workspace), with its blocked tasks, for portfolio stand-ups.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutputFormat(format); err != nil {
+				return err
+			}
+			if orgID != "" && !all {
+				return usageError{cmd.CommandPath(), fmt.Errorf("--org requires --all")}
+			}
+			if all {
+				return statusAll(cmd.Context(), orgID, format)
+			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
 				projectID = strings.TrimSpace(projectID)
 				if projectID == "" {
@@ -335,18 +1017,26 @@ func statusCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
+				componentCounts, err := e.Repo.CountTasksByComponent(ctx, projectID)
+				if err != nil {
+					return err
+				}
 				running, err := e.Repo.LatestRunningIteration(ctx, projectID)
 				if err != nil {
 					return err
 				}
 				out := map[string]any{
-					"project_id":  p.ID,
-					"status":      p.Status,
-					"iteration":   running,
-					"task_counts": counts,
+					"project_id":       p.ID,
+					"status":           p.Status,
+					"iteration":        running,
+					"task_counts":      counts,
+					"component_counts": componentCounts,
 				}
-				if viper.GetBool("json") {
-					return printJSON(out)
+				if structuredOutput() {
+					return printStructured(out)
+				}
+				if format != "table" {
+					return renderStatusTable(p, running, counts, componentCounts, format)
 				}
 				fmt.Printf("Project: %s (%s)\n", p.ID, p.Status)
 				if running != nil {
@@ -358,28 +1048,172 @@ func statusCmd() *cobra.Command {
 				for status, c := range counts {
 					fmt.Printf("  %s: %d\n", status, c)
 				}
+				if len(componentCounts) > 0 {
+					fmt.Println("Components:")
+					for _, component := range slices.Sorted(maps.Keys(componentCounts)) {
+						var parts []string
+						for _, status := range slices.Sorted(maps.Keys(componentCounts[component])) {
+							parts = append(parts, fmt.Sprintf("%s=%d", status, componentCounts[component][status]))
+						}
+						fmt.Printf("  %s: %s\n", component, strings.Join(parts, " "))
+					}
+				}
 				return nil
 			})
 		},
 	}
 	cmd.Flags().StringVar(&projectID, "project", "", "project id")
+	cmd.Flags().StringVar(&format, "format", "table", outputFormatUsage)
+	cmd.Flags().BoolVar(&all, "all", false, "overview of every project in this and the registered workspaces")
+	cmd.Flags().StringVar(&orgID, "org", "", "only projects of this org, with --all")
 	return cmd
 }
 
+// statusAll prints the overview of every project the actor may read in the
+// current workspace and the registered ones, with a column per task status.
+func statusAll(ctx context.Context, orgID, format string) error {
+	workspaces := []app.Workspace{{Path: viper.GetString("workspace")}}
+	if err := withRegistry(false, func(reg *app.WorkspaceRegistry) error {
+		workspaces = append(workspaces, reg.Workspaces...)
+		return nil
+	}); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	overviews := []domain.ProjectOverview{}
+	for i, ws := range workspaces {
+		path, err := filepath.Abs(ws.Path)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			// The current workspace goes by its registered name, if any.
+			for _, reg := range workspaces[1:] {
+				if reg.Path == path {
+					ws.Name = reg.Name
+				}
+			}
+		}
+		if ws.Name == "" {
+			ws.Name = path
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(db.Path(path)); err != nil {
+			warnf("workspace %s has no database", ws.Name)
+			continue
+		}
+		conn, err := openDB(path)
+		if err != nil {
+			warnf("workspace %s: %v", ws.Name, err)
+			continue
+		}
+		found, err := engine.New(conn, nil).ProjectOverviews(ctx, orgID, viper.GetString("actor-id"))
+		conn.Close()
+		if err != nil {
+			return fmt.Errorf("workspace %s: %w", ws.Name, err)
+		}
+		for _, o := range found {
+			o.Workspace = ws.Name
+			overviews = append(overviews, o)
+		}
+	}
+	if structuredOutput() {
+		return printStructured(overviews)
+	}
+	// Statuses in workflow order, then any others.
+	var statuses []string
+	for _, o := range overviews {
+		for status := range o.TaskCounts {
+			if !slices.Contains(statuses, status) {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	order := config.DefaultWorkflow().States
+	slices.SortFunc(statuses, func(a, b string) int {
+		ia, ib := slices.Index(order, a), slices.Index(order, b)
+		switch {
+		case ia >= 0 && ib >= 0:
+			return ia - ib
+		case ia >= 0:
+			return -1
+		case ib >= 0:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	header := append([]string{"Project", "Workspace", "Org", "Iteration", "Tasks"}, statuses...)
+	header = append(header, "Blocked")
+	rows := make([][]string, 0, len(overviews))
+	for _, o := range overviews {
+		iteration := "-"
+		if o.Iteration != nil {
+			iteration = *o.Iteration
+			if o.IterationGoal != "" {
+				iteration += " (" + o.IterationGoal + ")"
+			}
+		}
+		row := []string{o.ProjectID, o.Workspace, o.OrgID, iteration, strconv.Itoa(o.Tasks)}
+		for _, status := range statuses {
+			row = append(row, strconv.Itoa(o.TaskCounts[status]))
+		}
+		rows = append(rows, append(row, strconv.Itoa(o.Blocked)))
+	}
+	return renderRows(format, header, rows)
+}
+
+// renderStatusTable prints task counts per status, with a column per
+// component, as CSV or Markdown. Markdown gets a project heading first.
+func renderStatusTable(p domain.Project, running *domain.Iteration, counts map[string]int, componentCounts map[string]map[string]int, format string) error {
+	components := slices.Sorted(maps.Keys(componentCounts))
+	header := append([]string{"Status", "Tasks"}, components...)
+	var rows [][]string
+	for _, status := range slices.Sorted(maps.Keys(counts)) {
+		row := []string{status, strconv.Itoa(counts[status])}
+		for _, c := range components {
+			row = append(row, strconv.Itoa(componentCounts[c][status]))
+		}
+		rows = append(rows, row)
+	}
+	if format == "md" {
+		fmt.Printf("## %s (%s)\n\n", p.ID, p.Status)
+		if running != nil {
+			fmt.Printf("Running iteration: %s - %s\n\n", running.ID, running.Goal)
+		} else {
+			fmt.Print("Running iteration: none\n\n")
+		}
+	}
+	return renderRows(format, header, rows)
+}
+
 func taskCmd() *cobra.Command {
 	task := &cobra.Command{
 		Use:   "task",
 		Short: "Manage tasks",
-		Long:  "Tasks are the work items (features, bugs, docs). They flow planned -> ready -> in_progress -> review -> done, can depend on each other, and may need proof stickers per policy. Leases prevent two people doing the same task at once.",
+		Long:  "Tasks are the work items (features, bugs, docs). They flow planned -> ready -> in_progress -> review -> done (or through the task type's configured workflow), can depend on each other, and may need proof stickers per policy. Leases prevent two people doing the same task at once.",
 	}
 	task.AddCommand(taskCreateCmd())
 	task.AddCommand(taskListCmd())
 	task.AddCommand(taskGetCmd())
 	task.AddCommand(taskUpdateCmd())
+	task.AddCommand(taskBulkUpdateCmd())
 	task.AddCommand(taskDoneCmd())
 	task.AddCommand(taskClaimCmd())
+	task.AddCommand(taskClaimNextCmd())
 	task.AddCommand(taskReleaseCmd())
+	task.AddCommand(taskSweepCmd())
+	task.AddCommand(taskEscalationsCmd())
+	task.AddCommand(taskLogTimeCmd())
+	task.AddCommand(taskTimeCmd())
+	task.AddCommand(taskHistoryCmd())
+	task.AddCommand(taskRevertCmd())
+	task.AddCommand(taskAssignCmd())
 	task.AddCommand(taskTreeCmd())
+	task.AddCommand(taskRelationCmd())
+	task.AddCommand(taskGraphCmd())
 	return task
 }
 
@@ -389,6 +1223,7 @@ func taskCreateCmd() *cobra.Command {
 	var dependsOn []string
 	var policy string
 	var priority int
+	var estimate float64
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a task",
@@ -400,6 +1235,9 @@ func taskCreateCmd() *cobra.Command {
 			if cmd.Flags().Changed("priority") {
 				opts.Priority = &priority
 			}
+			if cmd.Flags().Changed("estimate") {
+				opts.Estimate = &estimate
+			}
 			if cmd.Flags().Changed("require") {
 				opts.PolicyOverride = true
 			}
@@ -415,28 +1253,44 @@ func taskCreateCmd() *cobra.Command {
 			})
 		},
 	}
-	cmd.Flags().StringVar(&opts.ID, "id", "", "task id (optional, deterministic UUID if omitted)")
+	cmd.Flags().StringVar(&opts.ID, "id", "", "task id (generated by the project's id strategy if omitted)")
 	cmd.Flags().StringVar(&opts.ProjectID, "project", "", "project id")
 	cmd.Flags().StringVar(&opts.IterationID, "iteration", "", "iteration id")
 	cmd.Flags().StringVar(&opts.ParentID, "parent", "", "parent task id")
 	cmd.Flags().StringVar(&opts.Type, "type", "technical", "task type")
+	cmd.Flags().StringVar(&opts.Component, "component", "", "component from the config registry")
 	cmd.Flags().StringVar(&opts.Title, "title", "", "title")
 	cmd.Flags().StringVar(&opts.Description, "description", "", "description")
 	cmd.Flags().StringArrayVar(&dependsOn, "depends-on", []string{}, "dependency task id (repeatable)")
 	cmd.Flags().StringVar(&opts.AssigneeID, "assignee-id", "", "assignee id")
 	cmd.Flags().IntVar(&priority, "priority", 0, "priority (lower is higher)")
+	cmd.Flags().Float64Var(&estimate, "estimate", 0, "estimate in the configured unit (points or hours)")
+	cmd.Flags().StringVar(&opts.DueAt, "due", "", "due date, RFC3339 or YYYY-MM-DD for the end of that day (defaults from project.sla)")
 	cmd.Flags().StringVar(&opts.PolicyPreset, "policy", "", "policy preset to apply (defaults use config mapping by task type)")
 	cmd.Flags().StringArrayVar(&requires, "require", []string{}, "required attestation kind (repeatable)")
+	cmd.Flags().StringToStringVar(&opts.ExternalRefs, "external-ref", nil, "id in an external system as system=id, e.g. jira=ABC-123 (repeatable)")
 	_ = cmd.MarkFlagRequired("title")
 	return cmd
 }
 
 func taskListCmd() *cobra.Command {
 	var f repo.TaskFilters
+	var sortBy, format string
+	var overdue bool
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List tasks",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if overdue {
+				f.OverdueAt = time.Now().UTC().Format(time.RFC3339)
+			}
+			if f.Sort, err = repo.ParseTaskSort(sortBy); err != nil {
+				return err
+			}
+			if err := checkOutputFormat(format); err != nil {
+				return err
+			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
 				if f.ProjectID == "" {
 					f.ProjectID = e.Config.Project.ID
@@ -445,12 +1299,13 @@ func taskListCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
-				if viper.GetBool("json") {
-					return printJSON(tasks)
+				if structuredOutput() {
+					return printStructured(tasks)
 				}
-				tw := table.NewWriter()
-				tw.SetOutputMirror(os.Stdout)
-				tw.AppendHeader(table.Row{"ID", "Title", "Status", "Assignee", "Iteration"})
+				if len(outputColumns()) > 0 {
+					return renderList(format, tasks)
+				}
+				rows := make([][]string, 0, len(tasks))
 				for _, t := range tasks {
 					assignee := ""
 					if t.AssigneeID != nil {
@@ -460,30 +1315,61 @@ func taskListCmd() *cobra.Command {
 					if t.IterationID != nil {
 						iter = *t.IterationID
 					}
-					tw.AppendRow(table.Row{t.ID, t.Title, t.Status, assignee, iter})
+					rows = append(rows, []string{t.ID, t.Title, t.Status, assignee, iter})
 				}
-				tw.Render()
-				return nil
+				return renderRows(format, []string{"ID", "Title", "Status", "Assignee", "Iteration"}, rows)
 			})
 		},
 	}
 	cmd.Flags().StringVar(&f.ProjectID, "project", "", "project id")
-	cmd.Flags().StringVar(&f.Status, "status", "", "status filter")
+	cmd.Flags().StringVar(&format, "format", "table", outputFormatUsage)
+	cmd.Flags().StringSliceVar(&f.Statuses, "status", nil, "status filter (repeatable or comma-separated; matches any)")
+	cmd.Flags().StringSliceVar(&f.Types, "type", nil, "task type filter (repeatable or comma-separated; matches any)")
+	cmd.Flags().StringVar(&f.Query, "search", "", "case-insensitive title substring")
+	cmd.Flags().StringSliceVar(&f.MissingAttestations, "missing-attestation", nil, "only tasks without an unexpired attestation of this kind (repeatable)")
+	cmd.Flags().BoolVar(&f.Stale, "stale", false, "only tasks marked stale (see wl task sweep)")
+	cmd.Flags().BoolVar(&overdue, "overdue", false, "only open tasks past their due date")
+	cmd.Flags().StringVar(&f.CreatedAfter, "created-after", "", "created at or after (RFC3339)")
+	cmd.Flags().StringVar(&f.CreatedBefore, "created-before", "", "created before (RFC3339)")
+	cmd.Flags().StringVar(&f.CompletedAfter, "completed-after", "", "completed at or after (RFC3339)")
+	cmd.Flags().StringVar(&f.CompletedBefore, "completed-before", "", "completed before (RFC3339)")
 	cmd.Flags().StringVar(&f.Iteration, "iteration", "", "iteration filter")
 	cmd.Flags().StringVar(&f.Parent, "parent", "", "parent task id")
 	cmd.Flags().StringVar(&f.AssigneeID, "assignee-id", "", "assignee filter")
+	cmd.Flags().StringVar(&f.Component, "component", "", "component filter")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "sort by created_at, updated_at, priority, status or title, optionally with :asc or :desc")
 	return cmd
 }
 
 func taskGetCmd() *cobra.Command {
+	var external string
 	cmd := &cobra.Command{
 		Use:   "get <id>",
 		Short: "Get task",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Get a task by id, or with --external by its id in an external system.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if external != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				t, err := e.Repo.GetTask(ctx, id)
+				var t domain.Task
+				var err error
+				if external != "" {
+					system, id, ok := strings.Cut(external, "=")
+					if !ok || system == "" || id == "" {
+						return fmt.Errorf("invalid --external %q: use system=id", external)
+					}
+					t, err = e.TaskByExternalRef(ctx, e.Config.Project.ID, system, id)
+				} else {
+					var id string
+					if id, err = resolveRef(ctx, e, "task", args[0]); err != nil {
+						return err
+					}
+					t, err = e.Repo.GetTask(ctx, id)
+				}
 				if err != nil {
 					return err
 				}
@@ -491,6 +1377,7 @@ func taskGetCmd() *cobra.Command {
 			})
 		},
 	}
+	cmd.Flags().StringVar(&external, "external", "", "find the task by its id in an external system, as system=id")
 	return cmd
 }
 
@@ -500,9 +1387,14 @@ func taskUpdateCmd() *cobra.Command {
 	var setParent string
 	var workOutcomes string
 	var assign string
+	var component string
 	var setPolicy string
 	var priority int
 	var clearPriority bool
+	var estimate float64
+	var clearEstimate bool
+	var due string
+	var iteration string
 	cmd := &cobra.Command{
 		Use:   "update <id>",
 		Short: "Update task",
@@ -528,6 +1420,25 @@ func taskUpdateCmd() *cobra.Command {
 					opts.SetPriority = &priority
 				}
 			}
+			if cmd.Flags().Changed("estimate") || clearEstimate {
+				opts.EstimateProvided = true
+				if clearEstimate {
+					opts.ClearEstimate = true
+				} else {
+					opts.SetEstimate = &estimate
+				}
+			}
+			if cmd.Flags().Changed("due") {
+				opts.DueAtProvided = true
+				opts.SetDueAt = &due
+			}
+			if cmd.Flags().Changed("iteration") {
+				opts.IterationProvided = true
+				opts.SetIteration = &iteration
+			}
+			if cmd.Flags().Changed("component") {
+				opts.SetComponent = &component
+			}
 			opts.RequiredKindsSet = cmd.Flags().Changed("require")
 			if opts.WorkOutcomesSet && opts.SetWorkOutcomes == nil {
 				opts.ClearWorkOutcomes = true
@@ -537,6 +1448,10 @@ func taskUpdateCmd() *cobra.Command {
 			}
 			opts.Force = viper.GetBool("force")
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				var err error
+				if opts.ID, err = resolveRef(ctx, e, "task", opts.ID); err != nil {
+					return err
+				}
 				t, err := e.UpdateTask(ctx, opts)
 				if err != nil {
 					return err
@@ -553,8 +1468,72 @@ func taskUpdateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&workOutcomes, "set-work-outcomes-json", "", "set work outcomes JSON")
 	cmd.Flags().IntVar(&priority, "priority", 0, "priority (lower is higher)")
 	cmd.Flags().BoolVar(&clearPriority, "clear-priority", false, "clear priority")
+	cmd.Flags().Float64Var(&estimate, "estimate", 0, "estimate in the configured unit (points or hours)")
+	cmd.Flags().BoolVar(&clearEstimate, "clear-estimate", false, "clear estimate")
+	cmd.Flags().StringVar(&due, "due", "", "due date, RFC3339 or YYYY-MM-DD for the end of that day (empty clears it)")
+	cmd.Flags().StringVar(&iteration, "iteration", "", "move to iteration (empty removes from iteration)")
+	cmd.Flags().StringVar(&component, "component", "", "move to component (empty removes from component)")
 	cmd.Flags().StringVar(&opts.PolicyPreset, "set-policy", "", "apply policy preset to task")
 	cmd.Flags().StringArrayVar(&requires, "require", []string{}, "required attestation kind")
+	cmd.Flags().StringToStringVar(&opts.SetExternalRefs, "external-ref", nil, "set the id in an external system as system=id; system= removes it (repeatable)")
+	return cmd
+}
+
+func taskBulkUpdateCmd() *cobra.Command {
+	var opts engine.BulkTaskUpdateOptions
+	var assign, iteration string
+	cmd := &cobra.Command{
+		Use:   "bulk-update <id>...",
+		Short: "Apply one status, iteration or assignee change to several tasks",
+		Long:  "Updates every listed task in one transaction. Tasks that fail a check are reported and left unchanged unless --atomic is set, in which case nothing is applied.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskIDs = args
+			opts.ActorID = viper.GetString("actor-id")
+			opts.Force = viper.GetBool("force")
+			if cmd.Flags().Changed("assign") {
+				opts.AssignProvided = true
+				opts.Assign = &assign
+			}
+			if cmd.Flags().Changed("iteration") {
+				opts.IterationProvided = true
+				opts.SetIteration = &iteration
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if opts.ProjectID == "" {
+					opts.ProjectID = e.Config.Project.ID
+				}
+				results, err := e.BulkUpdateTasks(ctx, opts)
+				if err != nil {
+					return err
+				}
+				out := make([]map[string]any, 0, len(results))
+				failed := 0
+				for _, res := range results {
+					item := map[string]any{"task_id": res.TaskID}
+					if res.Err != nil {
+						failed++
+						item["error"] = res.Err.Error()
+					} else {
+						item["status"] = res.Task.Status
+					}
+					out = append(out, item)
+				}
+				if err := printJSONOrTable(out); err != nil {
+					return err
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d of %d tasks not updated", failed, len(results))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&opts.Status, "status", "", "new status")
+	cmd.Flags().StringVar(&assign, "assign", "", "set assignee id (empty clears)")
+	cmd.Flags().StringVar(&iteration, "iteration", "", "move to iteration (empty removes from iteration)")
+	cmd.Flags().BoolVar(&opts.Atomic, "atomic", false, "apply all tasks or none")
 	return cmd
 }
 
@@ -570,6 +1549,10 @@ func taskDoneCmd() *cobra.Command {
 			}
 			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "task", id)
+				if err != nil {
+					return err
+				}
 				t, err := e.TaskDone(ctx, id, workOutcomes, viper.GetString("actor-id"), viper.GetBool("force"))
 				if err != nil {
 					return err
@@ -591,6 +1574,10 @@ func taskClaimCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "task", id)
+				if err != nil {
+					return err
+				}
 				lease, err := e.ClaimLease(ctx, id, viper.GetString("actor-id"), leaseSeconds)
 				if err != nil {
 					return err
@@ -603,6 +1590,40 @@ func taskClaimCmd() *cobra.Command {
 	return cmd
 }
 
+func taskClaimNextCmd() *cobra.Command {
+	var opts engine.ClaimNextOptions
+	var noUnassigned bool
+	cmd := &cobra.Command{
+		Use:   "claim-next",
+		Short: "Pick your next task and claim its lease in one step",
+		Long:  "Exits with an error when no task is available. Tasks another actor holds a live lease on are skipped.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ActorID = viper.GetString("actor-id")
+			opts.IncludeUnassigned = !noUnassigned
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if opts.ProjectID == "" {
+					opts.ProjectID = e.Config.Project.ID
+				}
+				t, lease, err := e.ClaimNextTask(ctx, opts)
+				if errors.Is(err, repo.ErrNotFound) {
+					return fmt.Errorf("no task available")
+				}
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(map[string]any{"task": t, "lease": lease})
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&opts.IterationID, "iteration", "", "iteration id (defaults to the running iteration)")
+	cmd.Flags().StringSliceVar(&opts.Types, "type", nil, "only these task types")
+	cmd.Flags().BoolVar(&noUnassigned, "assigned-only", false, "only tasks assigned to you")
+	cmd.Flags().IntVar(&opts.LeaseSeconds, "lease-seconds", 900, "lease duration seconds")
+	return cmd
+}
+
 func taskReleaseCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "release <id>",
@@ -611,6 +1632,10 @@ func taskReleaseCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "task", id)
+				if err != nil {
+					return err
+				}
 				return e.ReleaseLease(ctx, id, viper.GetString("actor-id"))
 			})
 		},
@@ -618,104 +1643,124 @@ func taskReleaseCmd() *cobra.Command {
 	return cmd
 }
 
-func taskTreeCmd() *cobra.Command {
-	var iteration, status string
+func taskRevertCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "tree",
-		Short: "Show task tree",
+		Use:   "revert <id>",
+		Short: "Revert the last status transition",
+		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				tasks, err := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: e.Config.Project.ID, Iteration: iteration, Status: status})
+				id, err := resolveRef(ctx, e, "task", id)
 				if err != nil {
 					return err
 				}
-				nodes := map[string][]domain.Task{}
-				var roots []domain.Task
-				for _, t := range tasks {
-					if t.ParentID != nil {
-						nodes[*t.ParentID] = append(nodes[*t.ParentID], t)
-					} else {
-						roots = append(roots, t)
-					}
+				t, err := e.RevertTask(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
 				}
-				if viper.GetBool("json") {
-					type Node struct {
-						Task     domain.Task `json:"task"`
-						Children []Node      `json:"children,omitempty"`
-					}
-					var build func(t domain.Task) Node
-					build = func(t domain.Task) Node {
-						children := nodes[t.ID]
-						var childNodes []Node
-						for _, c := range children {
-							childNodes = append(childNodes, build(c))
-						}
-						return Node{Task: t, Children: childNodes}
+				return printJSONOrTable(t)
+			})
+		},
+	}
+	return cmd
+}
+
+func taskAssignCmd() *cobra.Command {
+	var auto, suggest bool
+	cmd := &cobra.Command{
+		Use:   "assign <id> [actor-id]",
+		Short: "Assign task to an actor, or to the best suggestion with --auto",
+		Long:  "Suggestions are active actors allowed to claim and update the task, ranked by fewest open tasks, then most recent activity. --suggest lists them without assigning.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			if (auto || suggest) == (len(args) == 2) {
+				return fmt.Errorf("give an actor id or one of --auto, --suggest")
+			}
+			if auto && suggest {
+				return fmt.Errorf("--auto and --suggest are mutually exclusive")
+			}
+			actorID := viper.GetString("actor-id")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "task", id)
+				if err != nil {
+					return err
+				}
+				if suggest {
+					items, err := e.SuggestAssignees(ctx, id, actorID)
+					if err != nil {
+						return err
 					}
-					var treeNodes []Node
-					for _, r := range roots {
-						treeNodes = append(treeNodes, build(r))
+					return printJSONOrTable(items)
+				}
+				if auto {
+					t, err := e.AutoAssignTask(ctx, id, actorID)
+					if err != nil {
+						return err
 					}
-					return printJSON(treeNodes)
+					return printJSONOrTable(t)
 				}
-				for _, r := range roots {
-					printTaskTree(r, nodes, "", true)
+				assignee := args[1]
+				t, err := e.UpdateTask(ctx, engine.TaskUpdateOptions{
+					ID:             id,
+					ActorID:        actorID,
+					AssignProvided: true,
+					Assign:         &assignee,
+				})
+				if err != nil {
+					return err
 				}
-				return nil
+				return printJSONOrTable(t)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&iteration, "iteration", "", "iteration filter")
-	cmd.Flags().StringVar(&status, "status", "", "status filter")
+	cmd.Flags().BoolVar(&auto, "auto", false, "assign to the top suggested actor")
+	cmd.Flags().BoolVar(&suggest, "suggest", false, "list suggested assignees without assigning")
 	return cmd
 }
 
-func iterationCmd() *cobra.Command {
-	iter := &cobra.Command{
-		Use:   "iteration",
-		Short: "Manage iterations",
-		Long:  "Iterations are mini-adventures for the project: pending -> running -> delivered -> validated/rejected. Validation can require a specific attestation kind from config.",
-	}
-	iter.AddCommand(iterationCreateCmd())
-	iter.AddCommand(iterationListCmd())
-	iter.AddCommand(iterationStatusCmd())
-	return iter
-}
-
-func iterationCreateCmd() *cobra.Command {
-	var it domain.Iteration
+func taskLogTimeCmd() *cobra.Command {
+	var opts engine.TimeLogOptions
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create iteration",
+		Use:   "log-time <id>",
+		Short: "Log time spent on task",
+		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.TaskID = args[0]
+			opts.ActorID = viper.GetString("actor-id")
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				if it.ProjectID == "" {
-					it.ProjectID = e.Config.Project.ID
+				var err error
+				if opts.TaskID, err = resolveRef(ctx, e, "task", opts.TaskID); err != nil {
+					return err
 				}
-				it.Status = "pending"
-				res, err := e.CreateIteration(ctx, it, viper.GetString("actor-id"))
+				te, err := e.LogTime(ctx, opts)
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(res)
+				return printJSONOrTable(te)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&it.ID, "id", "", "iteration id")
-	cmd.Flags().StringVar(&it.ProjectID, "project", "", "project id")
-	cmd.Flags().StringVar(&it.Goal, "goal", "", "goal")
-	_ = cmd.MarkFlagRequired("id")
-	_ = cmd.MarkFlagRequired("goal")
+	cmd.Flags().IntVar(&opts.Minutes, "minutes", 0, "minutes spent")
+	cmd.Flags().StringVar(&opts.Note, "note", "", "what the time was spent on")
+	_ = cmd.MarkFlagRequired("minutes")
 	return cmd
 }
 
-func iterationListCmd() *cobra.Command {
+func taskTimeCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List iterations",
+		Use:   "time <id>",
+		Short: "List time logged on task",
+		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				items, err := e.Repo.ListIterations(ctx, e.Config.Project.ID)
+				id, err := resolveRef(ctx, e, "task", id)
+				if err != nil {
+					return err
+				}
+				items, err := e.ListTimeEntries(ctx, id, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
@@ -726,143 +1771,256 @@ func iterationListCmd() *cobra.Command {
 	return cmd
 }
 
-func iterationStatusCmd() *cobra.Command {
-	var status string
+func taskHistoryCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "set-status <id>",
-		Short: "Update iteration status",
+		Use:   "history <id>",
+		Short: "Show task lifecycle timeline",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				it, err := e.SetIterationStatus(ctx, id, status, viper.GetString("actor-id"), viper.GetBool("force"))
+				id, err := resolveRef(ctx, e, "task", id)
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(it)
+				entries, err := e.TaskHistory(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(entries)
+				}
+				for _, h := range entries {
+					actor := h.ActorID
+					if actor == "" {
+						actor = "-"
+					}
+					fmt.Printf("%-20s  %-11s  %-12s  %s\n", h.TS, h.Category, actor, h.Summary)
+				}
+				return nil
 			})
 		},
 	}
-	cmd.Flags().StringVar(&status, "status", "", "new status")
-	_ = cmd.MarkFlagRequired("status")
 	return cmd
 }
 
-func configCmd() *cobra.Command {
-	cfg := &cobra.Command{
-		Use:   "config",
-		Short: "Inspect project config",
-		Long:  "Config is the rulebook (stored in DB): project id/kind, attestation catalog, and policy presets/defaults that decide which proof is needed. Import from workline.yml if desired.",
+func taskTreeCmd() *cobra.Command {
+	var iteration, status string
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show task tree",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				tasks, err := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: e.Config.Project.ID, Iteration: iteration, Status: status})
+				if err != nil {
+					return err
+				}
+				nodes := map[string][]domain.Task{}
+				var roots []domain.Task
+				for _, t := range tasks {
+					if t.ParentID != nil {
+						nodes[*t.ParentID] = append(nodes[*t.ParentID], t)
+					} else {
+						roots = append(roots, t)
+					}
+				}
+				if structuredOutput() {
+					type Node struct {
+						Task     domain.Task `json:"task"`
+						Children []Node      `json:"children,omitempty"`
+					}
+					var build func(t domain.Task) Node
+					build = func(t domain.Task) Node {
+						children := nodes[t.ID]
+						var childNodes []Node
+						for _, c := range children {
+							childNodes = append(childNodes, build(c))
+						}
+						return Node{Task: t, Children: childNodes}
+					}
+					var treeNodes []Node
+					for _, r := range roots {
+						treeNodes = append(treeNodes, build(r))
+					}
+					return printStructured(treeNodes)
+				}
+				for _, r := range roots {
+					printTaskTree(r, nodes, "", true)
+				}
+				return nil
+			})
+		},
 	}
-	cfg.AddCommand(configShowCmd())
-	cfg.AddCommand(configValidateCmd())
-	return cfg
+	cmd.Flags().StringVar(&iteration, "iteration", "", "iteration filter")
+	cmd.Flags().StringVar(&status, "status", "", "status filter")
+	return cmd
 }
 
-func configShowCmd() *cobra.Command {
+func taskRelationCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "show",
-		Short: "Show loaded config",
+		Use:   "relation",
+		Short: "Manage typed relations between tasks",
+		Long:  "Relations read `<id> <type> <related-id>`: blocks keeps the related task from completing or being claimed until this one is done, duplicates closes this task as a duplicate of the related one, and relates_to and caused_by are informational.",
+	}
+	cmd.AddCommand(taskRelationAddCmd())
+	cmd.AddCommand(taskRelationRemoveCmd())
+	cmd.AddCommand(taskRelationListCmd())
+	return cmd
+}
+
+func taskRelationAddCmd() *cobra.Command {
+	var opts engine.TaskRelationOptions
+	cmd := &cobra.Command{
+		Use:   "add <id> <type> <related-id>",
+		Short: "Relate a task to another",
+		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Type = args[1]
+			opts.ActorID = viper.GetString("actor-id")
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return printJSONOrTable(e.Config)
+				var err error
+				if opts.TaskID, err = resolveRef(ctx, e, "task", args[0]); err != nil {
+					return err
+				}
+				if opts.RelatedTaskID, err = resolveRef(ctx, e, "task", args[2]); err != nil {
+					return err
+				}
+				rel, err := e.AddTaskRelation(ctx, opts)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(rel)
 			})
 		},
 	}
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "close a duplicate leased by another actor")
 	return cmd
 }
 
-func configValidateCmd() *cobra.Command {
+func taskRelationRemoveCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "validate",
-		Short: "Validate stored config",
+		Use:   "remove <id> <type> <related-id>",
+		Short: "Remove a relation",
+		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return e.Config.Validate()
+			opts := engine.TaskRelationOptions{Type: args[1], ActorID: viper.GetString("actor-id")}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				var err error
+				if opts.TaskID, err = resolveRef(ctx, e, "task", args[0]); err != nil {
+					return err
+				}
+				if opts.RelatedTaskID, err = resolveRef(ctx, e, "task", args[2]); err != nil {
+					return err
+				}
+				return e.RemoveTaskRelation(ctx, opts)
 			})
-			if viper.GetBool("json") {
-				return printJSON(map[string]any{"ok": err == nil, "error": fmt.Sprint(err)})
-			}
-			if err != nil {
-				return err
-			}
-			fmt.Println("config OK")
-			return nil
 		},
 	}
 	return cmd
 }
 
-func decisionCmd() *cobra.Command {
-	dec := &cobra.Command{
-		Use:   "decision",
-		Short: "Manage decisions",
-		Long:  "Decisions capture the important choices, who decided, and why—so future you knows the reasoning.",
+func taskRelationListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <id>",
+		Short: "List the relations of a task, from either side",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "task", args[0])
+				if err != nil {
+					return err
+				}
+				items, err := e.ListTaskRelations(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
 	}
-	dec.AddCommand(decisionCreateCmd())
-	return dec
+	return cmd
 }
 
-func decisionCreateCmd() *cobra.Command {
-	var d domain.Decision
-	var rationale []string
-	var alternatives []string
+func taskGraphCmd() *cobra.Command {
+	var projectID, format string
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create decision",
+		Use:   "graph",
+		Short: "Export the task graph",
+		Long:  "Prints every link between the project's tasks as `from type to` edges: subtask_of, depends_on and the relation types. --format dot writes a Graphviz digraph with one node per task; --json or --output give nodes and edges.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			d.RationaleJSON = toJSONArray(rationale)
-			d.AlternativesJSON = toJSONArray(alternatives)
+			if format != "dot" && checkOutputFormat(format) != nil {
+				return fmt.Errorf("invalid --format %q: expected table, csv, md or dot", format)
+			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				if d.ProjectID == "" {
-					d.ProjectID = e.Config.Project.ID
+				if projectID == "" {
+					projectID = e.Config.Project.ID
 				}
-				res, err := e.CreateDecision(ctx, d, viper.GetString("actor-id"))
+				graph, err := e.TaskGraph(ctx, projectID, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(res)
+				if structuredOutput() {
+					return printStructured(graph)
+				}
+				if format == "dot" {
+					printTaskGraphDot(graph)
+					return nil
+				}
+				rows := make([][]string, 0, len(graph.Edges))
+				for _, edge := range graph.Edges {
+					rows = append(rows, []string{edge.From, edge.Type, edge.To})
+				}
+				return renderRows(format, []string{"From", "Type", "To"}, rows)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&d.ID, "id", "", "decision id")
-	cmd.Flags().StringVar(&d.ProjectID, "project", "", "project id")
-	cmd.Flags().StringVar(&d.Title, "title", "", "title")
-	cmd.Flags().StringVar(&d.Decision, "decision", "", "decision text")
-	cmd.Flags().StringArrayVar(&rationale, "rationale", []string{}, "rationale entries")
-	cmd.Flags().StringArrayVar(&alternatives, "alternatives", []string{}, "alternative entries")
-	cmd.Flags().StringVar(&d.ContextJSON, "context-json", "", "context JSON")
-	cmd.Flags().StringVar(&d.DeciderID, "decider-id", "", "decider id")
-	_ = cmd.MarkFlagRequired("id")
-	_ = cmd.MarkFlagRequired("title")
-	_ = cmd.MarkFlagRequired("decision")
-	_ = cmd.MarkFlagRequired("decider-id")
+	cmd.Flags().StringVar(&projectID, "project", "", "project id")
+	cmd.Flags().StringVar(&format, "format", "table", "output format: table, csv, md or dot (ignored with --json or --output)")
 	return cmd
 }
 
-func attestCmd() *cobra.Command {
-	a := &cobra.Command{
-		Use:   "attest",
-		Short: "Manage attestations",
-		Long:  "Attestations are proof stickers (ci.passed, review.approved, acceptance.passed, etc.) attached to tasks or iterations. Policies check these before letting work finish.",
+func printTaskGraphDot(graph domain.TaskGraph) {
+	fmt.Printf("digraph %s {\n", strconv.Quote(graph.ProjectID))
+	for _, n := range graph.Nodes {
+		fmt.Printf("  %s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(fmt.Sprintf("%s\n%s (%s)", n.ID, n.Title, n.Status)))
 	}
-	a.AddCommand(attestAddCmd())
-	a.AddCommand(attestListCmd())
-	return a
+	for _, edge := range graph.Edges {
+		fmt.Printf("  %s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(edge.Type))
+	}
+	fmt.Println("}")
 }
 
-func attestAddCmd() *cobra.Command {
-	var att domain.Attestation
+func iterationCmd() *cobra.Command {
+	iter := &cobra.Command{
+		Use:   "iteration",
+		Short: "Manage iterations",
+		Long:  "Iterations are mini-adventures for the project: pending -> running -> delivered -> validated/rejected. Validation can require a specific attestation kind from config.",
+	}
+	iter.AddCommand(iterationCreateCmd())
+	iter.AddCommand(iterationListCmd())
+	iter.AddCommand(iterationStatusCmd())
+	iter.AddCommand(iterationTimeCmd())
+	iter.AddCommand(iterationCapacityCmd())
+	iter.AddCommand(iterationProgressCmd())
+	return iter
+}
+
+func iterationCreateCmd() *cobra.Command {
+	var it domain.Iteration
+	var capacity float64
 	cmd := &cobra.Command{
-		Use:   "add",
-		Short: "Add attestation",
+		Use:   "create",
+		Short: "Create iteration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			att.ActorID = viper.GetString("actor-id")
+			if cmd.Flags().Changed("capacity") {
+				it.Capacity = &capacity
+			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				if att.ProjectID == "" {
-					att.ProjectID = e.Config.Project.ID
+				if it.ProjectID == "" {
+					it.ProjectID = e.Config.Project.ID
 				}
-				res, err := e.AddAttestation(ctx, att, viper.GetString("actor-id"))
+				it.Status = "pending"
+				res, err := e.CreateIteration(ctx, it, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
@@ -870,163 +2028,2470 @@ func attestAddCmd() *cobra.Command {
 			})
 		},
 	}
-	cmd.Flags().StringVar(&att.ProjectID, "project", "", "project id")
-	cmd.Flags().StringVar(&att.EntityKind, "entity-kind", "", "entity kind")
-	cmd.Flags().StringVar(&att.EntityID, "entity-id", "", "entity id")
-	cmd.Flags().StringVar(&att.Kind, "kind", "", "attestation kind")
-	cmd.Flags().StringVar(&att.PayloadJSON, "payload-json", "", "payload JSON")
-	_ = cmd.MarkFlagRequired("entity-kind")
-	_ = cmd.MarkFlagRequired("entity-id")
-	_ = cmd.MarkFlagRequired("kind")
+	cmd.Flags().StringVar(&it.ID, "id", "", "iteration id (generated by the project's id strategy if omitted)")
+	cmd.Flags().StringVar(&it.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&it.Goal, "goal", "", "goal")
+	cmd.Flags().Float64Var(&capacity, "capacity", 0, "capacity in the configured estimate unit")
+	_ = cmd.MarkFlagRequired("goal")
 	return cmd
 }
 
-func attestListCmd() *cobra.Command {
-	var f repo.AttestationFilters
+func iterationListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List attestations",
+		Short: "List iterations",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				if f.ProjectID == "" {
-					f.ProjectID = e.Config.Project.ID
+				items, err := e.Repo.ListIterations(ctx, e.Config.Project.ID)
+				if err != nil {
+					return err
 				}
-				items, err := e.Repo.ListAttestations(ctx, f)
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	return cmd
+}
+
+func iterationTimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "time",
+		Short: "Show time logged per iteration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				totals, err := e.IterationTimeTotals(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(items)
+				return printJSONOrTable(totals)
+			})
+		},
+	}
+	return cmd
+}
+
+func iterationCapacityCmd() *cobra.Command {
+	var capacity float64
+	var clearCapacity bool
+	cmd := &cobra.Command{
+		Use:   "set-capacity <id>",
+		Short: "Set iteration capacity",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !clearCapacity && !cmd.Flags().Changed("capacity") {
+				return fmt.Errorf("--capacity or --clear required")
+			}
+			var value *float64
+			if !clearCapacity {
+				value = &capacity
+			}
+			id := args[0]
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "iteration", id)
+				if err != nil {
+					return err
+				}
+				it, err := e.SetIterationCapacity(ctx, id, value, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(it)
+			})
+		},
+	}
+	cmd.Flags().Float64Var(&capacity, "capacity", 0, "capacity in the configured estimate unit")
+	cmd.Flags().BoolVar(&clearCapacity, "clear", false, "remove the capacity")
+	return cmd
+}
+
+func iterationProgressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "progress <id>",
+		Short: "Show planned vs completed estimates",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "iteration", id)
+				if err != nil {
+					return err
+				}
+				p, err := e.IterationProgress(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(p)
+			})
+		},
+	}
+	return cmd
+}
+
+func iterationStatusCmd() *cobra.Command {
+	var status string
+	cmd := &cobra.Command{
+		Use:   "set-status <id>",
+		Short: "Update iteration status",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "iteration", id)
+				if err != nil {
+					return err
+				}
+				it, err := e.SetIterationStatus(ctx, id, status, viper.GetString("actor-id"), viper.GetBool("force"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(it)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", "", "new status")
+	_ = cmd.MarkFlagRequired("status")
+	return cmd
+}
+
+func milestoneCmd() *cobra.Command {
+	ms := &cobra.Command{
+		Use:   "milestone",
+		Short: "Manage milestones",
+		Long:  "Milestones track a goal with a target date above the iteration level. Progress counts the tasks linked directly and those in linked iterations.",
+	}
+	ms.AddCommand(milestoneCreateCmd())
+	ms.AddCommand(milestoneListCmd())
+	ms.AddCommand(milestoneLinkCmd())
+	ms.AddCommand(milestoneStatusCmd())
+	return ms
+}
+
+func milestoneCreateCmd() *cobra.Command {
+	var opts engine.MilestoneOptions
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create milestone",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ActorID = viper.GetString("actor-id")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if opts.ProjectID == "" {
+					opts.ProjectID = e.Config.Project.ID
+				}
+				m, err := e.CreateMilestone(ctx, opts)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(m)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.ID, "id", "", "milestone id")
+	cmd.Flags().StringVar(&opts.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&opts.Goal, "goal", "", "goal")
+	cmd.Flags().StringVar(&opts.TargetDate, "target-date", "", "target date (YYYY-MM-DD)")
+	cmd.Flags().StringSliceVar(&opts.IterationIDs, "iteration", nil, "iteration id to link (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.TaskIDs, "task", nil, "task id to link (repeatable)")
+	_ = cmd.MarkFlagRequired("id")
+	_ = cmd.MarkFlagRequired("goal")
+	_ = cmd.MarkFlagRequired("target-date")
+	return cmd
+}
+
+func milestoneListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List milestones",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListMilestones(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	return cmd
+}
+
+func milestoneLinkCmd() *cobra.Command {
+	var iterationIDs, taskIDs []string
+	cmd := &cobra.Command{
+		Use:   "link <id>",
+		Short: "Link iterations and tasks to milestone",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				m, err := e.LinkMilestone(ctx, id, iterationIDs, taskIDs, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(m)
+			})
+		},
+	}
+	cmd.Flags().StringSliceVar(&iterationIDs, "iteration", nil, "iteration id to link (repeatable)")
+	cmd.Flags().StringSliceVar(&taskIDs, "task", nil, "task id to link (repeatable)")
+	return cmd
+}
+
+func milestoneStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <id>",
+		Short: "Show milestone progress",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := args[0]
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				p, err := e.MilestoneStatus(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(p)
+			})
+		},
+	}
+	return cmd
+}
+
+func changelogCmd() *cobra.Command {
+	var iterationID, format, out string
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Render an iteration changelog for release notes",
+		Long:  "Lists the iteration's done tasks grouped by task type. Each task gets the summary, notes or description from its work outcomes and the http(s) links found in them, such as pull requests. Needs iteration.list and task.list.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if iterationID == "" {
+				return fmt.Errorf("--iteration required")
+			}
+			if format != "md" && format != "json" {
+				return fmt.Errorf("invalid --format %q: expected md or json", format)
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "iteration", iterationID)
+				if err != nil {
+					return err
+				}
+				c, err := e.Changelog(ctx, id, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				if structuredOutput() && out == "" {
+					return printStructured(c)
+				}
+				data := []byte(engine.ChangelogMarkdown(c))
+				if format == "json" {
+					if data, err = json.MarshalIndent(c, "", "  "); err != nil {
+						return err
+					}
+					data = append(data, '\n')
+				}
+				if out == "" || out == "-" {
+					_, err = os.Stdout.Write(data)
+					return err
+				}
+				if err := os.WriteFile(out, data, 0o644); err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(map[string]string{"changelog": out})
+				}
+				infof("Changelog written to %s\n", out)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&iterationID, "iteration", "", "iteration id")
+	cmd.Flags().StringVar(&format, "format", "md", "md or json")
+	cmd.Flags().StringVar(&out, "out", "", "output file (default stdout)")
+	return cmd
+}
+
+func releaseCmd() *cobra.Command {
+	rel := &cobra.Command{
+		Use:   "release",
+		Short: "Record and list releases",
+		Long:  "Releases record the artifacts shipped from a validated iteration: name, version, checksum and download URL. Recording one needs an iteration.approved attestation on the iteration.",
+	}
+	rel.AddCommand(releaseCreateCmd())
+	rel.AddCommand(releaseListCmd())
+	return rel
+}
+
+func releaseCreateCmd() *cobra.Command {
+	var opts engine.ReleaseOptions
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Record an iteration release",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ActorID = viper.GetString("actor-id")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				r, err := e.RecordRelease(ctx, opts)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(r)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.IterationID, "iteration", "", "iteration id")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "artifact name")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "artifact version")
+	cmd.Flags().StringVar(&opts.Checksum, "checksum", "", "artifact checksum (<algorithm>:<hex digest>)")
+	cmd.Flags().StringVar(&opts.URL, "url", "", "artifact download URL")
+	_ = cmd.MarkFlagRequired("iteration")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("version")
+	return cmd
+}
+
+func releaseListCmd() *cobra.Command {
+	var iterationID string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List releases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListReleases(ctx, e.Config.Project.ID, iterationID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&iterationID, "iteration", "", "only releases of this iteration")
+	return cmd
+}
+
+func configCmd() *cobra.Command {
+	cfg := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect project config",
+		Long:  "Config is the rulebook (stored in DB): project id/kind, attestation catalog, and policy presets/defaults that decide which proof is needed. Import from workline.yml if desired.",
+	}
+	cfg.AddCommand(configShowCmd())
+	cfg.AddCommand(configValidateCmd())
+	cfg.AddCommand(configSchemaCmd())
+	return cfg
+}
+
+func configShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show loaded config",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				// Print the stored config so secret references are not expanded.
+				stored, err := e.Repo.GetProjectConfig(ctx, e.Config.Project.ID)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(stored)
+			})
+		},
+	}
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	var filePath string
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate stored config, or a YAML file with --file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if filePath != "" {
+				_, err = config.FromFile(filePath)
+			} else {
+				err = withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+					return e.Config.Validate()
+				})
+			}
+			var problems config.ValidationErrors
+			errors.As(err, &problems)
+			if structuredOutput() {
+				return printStructured(map[string]any{"ok": err == nil, "error": fmt.Sprint(err), "errors": problems})
+			}
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Fprintln(os.Stderr, p.Error())
+				}
+				return fmt.Errorf("config has %d problem(s)", len(problems))
+			}
+			if err != nil {
+				return err
+			}
+			infof("config OK\n")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&filePath, "file", "", "validate a YAML file instead of the stored config")
+	return cmd
+}
+
+func configSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for workline.yml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printJSON(config.Schema())
+		},
+	}
+	return cmd
+}
+
+func decisionCmd() *cobra.Command {
+	dec := &cobra.Command{
+		Use:   "decision",
+		Short: "Manage decisions",
+		Long:  "Decisions capture the important choices, who decided, and why—so future you knows the reasoning.",
+	}
+	dec.AddCommand(decisionCreateCmd())
+	dec.AddCommand(decisionGetCmd())
+	dec.AddCommand(decisionExportCmd())
+	return dec
+}
+
+func decisionGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Get decision",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				id, err := resolveRef(ctx, e, "decision", args[0])
+				if err != nil {
+					return err
+				}
+				d, err := e.Repo.GetDecision(ctx, id)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(d)
+			})
+		},
+	}
+	return cmd
+}
+
+func decisionExportCmd() *cobra.Command {
+	var format, out string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write decisions as ADR files",
+		Long:  "Writes one Markdown Architecture Decision Record per decision of the project into --out, named after the decision's number and title (0001-adopt-go-for-backend.md), with its status, context, decision, and consequences from its rationale and alternatives. Numbers never change, so re-running the export keeps the directory in sync: new decisions get a file, changed files are rewritten, and a file left under an older name for the same number is removed. Other files are left alone. Needs decision.list.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "adr" {
+				return fmt.Errorf("invalid --format %q: expected adr", format)
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				decisions, err := e.ListDecisions(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				files, err := writeADRs(out, decisions)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(files)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "adr", "export format: adr")
+	cmd.Flags().StringVar(&out, "out", "docs/adr", "directory to write the ADR files to")
+	return cmd
+}
+
+// adrFile reports what a decision export did with one file: created,
+// updated, unchanged, or removed when it was superseded by a new name.
+type adrFile struct {
+	Number     int    `json:"number"`
+	DecisionID string `json:"decision_id"`
+	File       string `json:"file"`
+	Status     string `json:"status"`
+}
+
+// writeADRs brings dir in line with decisions, touching only files named
+// NNNN-*.md after a decision's number.
+func writeADRs(dir string, decisions []domain.Decision) ([]adrFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9][0-9]-*.md"))
+	if err != nil {
+		return nil, err
+	}
+	files := []adrFile{}
+	for _, d := range decisions {
+		name := engine.ADRFileName(d)
+		path := filepath.Join(dir, name)
+		for _, old := range existing {
+			if filepath.Base(old) != name && strings.HasPrefix(filepath.Base(old), name[:5]) {
+				if err := os.Remove(old); err != nil {
+					return files, err
+				}
+				files = append(files, adrFile{Number: d.Number, DecisionID: d.ID, File: old, Status: "removed"})
+			}
+		}
+		content := []byte(engine.DecisionADR(d))
+		status := "created"
+		if current, err := os.ReadFile(path); err == nil {
+			status = "updated"
+			if bytes.Equal(current, content) {
+				status = "unchanged"
+			}
+		}
+		if status != "unchanged" {
+			if err := os.WriteFile(path, content, 0o644); err != nil {
+				return files, err
+			}
+		}
+		files = append(files, adrFile{Number: d.Number, DecisionID: d.ID, File: path, Status: status})
+	}
+	return files, nil
+}
+
+func decisionCreateCmd() *cobra.Command {
+	var d domain.Decision
+	var rationale []string
+	var alternatives []string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create decision",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d.RationaleJSON = toJSONArray(rationale)
+			d.AlternativesJSON = toJSONArray(alternatives)
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if d.ProjectID == "" {
+					d.ProjectID = e.Config.Project.ID
+				}
+				res, err := e.CreateDecision(ctx, d, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(res)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&d.ID, "id", "", "decision id (generated by the project's id strategy if omitted)")
+	cmd.Flags().StringVar(&d.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&d.Title, "title", "", "title")
+	cmd.Flags().StringVar(&d.Decision, "decision", "", "decision text")
+	cmd.Flags().StringArrayVar(&rationale, "rationale", []string{}, "rationale entries")
+	cmd.Flags().StringArrayVar(&alternatives, "alternatives", []string{}, "alternative entries")
+	cmd.Flags().StringVar(&d.ContextJSON, "context-json", "", "context JSON")
+	cmd.Flags().StringVar(&d.DeciderID, "decider-id", "", "decider id")
+	_ = cmd.MarkFlagRequired("title")
+	_ = cmd.MarkFlagRequired("decision")
+	_ = cmd.MarkFlagRequired("decider-id")
+	return cmd
+}
+
+func attestCmd() *cobra.Command {
+	a := &cobra.Command{
+		Use:   "attest",
+		Short: "Manage attestations",
+		Long:  "Attestations are proof stickers (ci.passed, review.approved, acceptance.passed, etc.) attached to tasks or iterations. Policies check these before letting work finish.",
+	}
+	a.AddCommand(attestAddCmd())
+	a.AddCommand(attestListCmd())
+	a.AddCommand(attestSweepCmd())
+	a.AddCommand(attestEvidenceCmd())
+	a.AddCommand(attestSLSACmd())
+	return a
+}
+
+func attestEvidenceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "evidence",
+		Short: "Manage attestation evidence files",
+	}
+	cmd.AddCommand(attestEvidenceAddCmd())
+	cmd.AddCommand(attestEvidenceListCmd())
+	cmd.AddCommand(attestEvidenceGetCmd())
+	return cmd
+}
+
+func attestEvidenceAddCmd() *cobra.Command {
+	var attestationID, file, contentType string
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Attach a file to an attestation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if attestationID == "" || file == "" {
+				return fmt.Errorf("--attestation and --file required")
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if contentType == "" {
+				contentType = mime.TypeByExtension(filepath.Ext(file))
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				ev, err := e.AddEvidence(ctx, engine.EvidenceOptions{
+					ProjectID:     e.Config.Project.ID,
+					AttestationID: attestationID,
+					Name:          filepath.Base(file),
+					ContentType:   contentType,
+					Body:          f,
+					ActorID:       viper.GetString("actor-id"),
+				})
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(ev)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&attestationID, "attestation", "", "attestation id")
+	cmd.Flags().StringVar(&file, "file", "", "path of the evidence file")
+	cmd.Flags().StringVar(&contentType, "content-type", "", "content type (guessed from extension by default)")
+	return cmd
+}
+
+func attestEvidenceListCmd() *cobra.Command {
+	var attestationID string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List evidence attached to an attestation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if attestationID == "" {
+				return fmt.Errorf("--attestation required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListEvidence(ctx, e.Config.Project.ID, attestationID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&attestationID, "attestation", "", "attestation id")
+	return cmd
+}
+
+func attestEvidenceGetCmd() *cobra.Command {
+	var attestationID, evidenceID, out string
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Download an evidence file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if attestationID == "" || evidenceID == "" {
+				return fmt.Errorf("--attestation and --id required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				ev, rc, err := e.OpenEvidence(ctx, e.Config.Project.ID, attestationID, evidenceID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+				if out == "" {
+					out = ev.Name
+				}
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(f, rc); err != nil {
+					f.Close()
+					return err
+				}
+				return f.Close()
+			})
+		},
+	}
+	cmd.Flags().StringVar(&attestationID, "attestation", "", "attestation id")
+	cmd.Flags().StringVar(&evidenceID, "id", "", "evidence id")
+	cmd.Flags().StringVar(&out, "out", "", "output path (defaults to the original file name)")
+	return cmd
+}
+
+func attestAddCmd() *cobra.Command {
+	var att domain.Attestation
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add attestation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			att.ActorID = viper.GetString("actor-id")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if att.ProjectID == "" {
+					att.ProjectID = e.Config.Project.ID
+				}
+				res, err := e.AddAttestation(ctx, att, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(res)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&att.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&att.EntityKind, "entity-kind", "", "entity kind")
+	cmd.Flags().StringVar(&att.EntityID, "entity-id", "", "entity id")
+	cmd.Flags().StringVar(&att.Kind, "kind", "", "attestation kind")
+	cmd.Flags().StringVar(&att.PayloadJSON, "payload-json", "", "payload JSON")
+	_ = cmd.MarkFlagRequired("entity-kind")
+	_ = cmd.MarkFlagRequired("entity-id")
+	_ = cmd.MarkFlagRequired("kind")
+	return cmd
+}
+
+func attestSLSACmd() *cobra.Command {
+	var taskID, file string
+	cmd := &cobra.Command{
+		Use:   "slsa",
+		Short: "Attest SLSA build provenance on a task",
+		Long:  "Reads an in-toto statement with a SLSA provenance predicate (v0.2 or v1), bare or in a DSSE envelope, checks its subjects and builder, and records it as the payload of a provenance.verified attestation. Signatures are not verified. Use - to read stdin.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			var err error
+			if file == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(file)
+			}
+			if err != nil {
+				return err
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				att, err := e.AttestProvenance(ctx, taskID, data, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(att)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&taskID, "task", "", "task id")
+	cmd.Flags().StringVar(&file, "provenance", "", "provenance file (in-toto statement or DSSE envelope)")
+	_ = cmd.MarkFlagRequired("task")
+	_ = cmd.MarkFlagRequired("provenance")
+	return cmd
+}
+
+func attestListCmd() *cobra.Command {
+	var f repo.AttestationFilters
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List attestations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if f.ProjectID == "" {
+					f.ProjectID = e.Config.Project.ID
+				}
+				items, err := e.Repo.ListAttestations(ctx, f)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&f.ProjectID, "project", "", "project id")
+	cmd.Flags().StringVar(&f.EntityKind, "entity-kind", "", "entity kind filter")
+	cmd.Flags().StringVar(&f.EntityID, "entity-id", "", "entity id filter")
+	cmd.Flags().StringVar(&f.Kind, "kind", "", "kind filter")
+	return cmd
+}
+
+func attestSweepCmd() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Expire attestations past their kind's valid_days",
+		Long:  "Marks attestations older than their kind's valid_days as expired and records an attestation.expired event for each. Expired attestations stop satisfying policies whether or not the sweep has run; wl serve sweeps periodically.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if projectID == "" {
+					projectID = e.Config.Project.ID
+				}
+				expired, err := e.ExpireAttestations(ctx, projectID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				if expired == nil {
+					expired = []domain.Attestation{}
+				}
+				return printJSONOrTable(expired)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&projectID, "project", "", "project id")
+	return cmd
+}
+
+func taskSweepCmd() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Mark stale tasks, report missed due dates and escalate priorities",
+		Long:  "Marks tasks that have sat in one of project.staleness.statuses (in_progress by default) without an update for project.staleness.days as stale, records a task.stale event for each and emails their assignees listed in project.staleness.notify; any later update clears the flag. Then records an sla.breached event for each open task past its due date, once per due date, and raises open tasks one priority level under project.escalation, recording task.escalated. wl serve sweeps periodically.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if projectID == "" {
+					projectID = e.Config.Project.ID
+				}
+				actorID := viper.GetString("actor-id")
+				stale, err := e.MarkStaleTasks(ctx, projectID, actorID)
+				if err != nil {
+					return err
+				}
+				breached, err := e.BreachSLAs(ctx, projectID, actorID)
+				if err != nil {
+					return err
+				}
+				escalated, err := e.EscalatePriorities(ctx, projectID, actorID)
+				if err != nil {
+					return err
+				}
+				if escalated == nil {
+					escalated = []domain.Escalation{}
+				}
+				if structuredOutput() {
+					return printStructured(struct {
+						Stale       []domain.Task       `json:"stale"`
+						SLABreached []domain.Task       `json:"sla_breached"`
+						Escalated   []domain.Escalation `json:"escalated"`
+					}{nonNilTasks(stale), nonNilTasks(breached), escalated})
+				}
+				var rows [][]string
+				for _, t := range stale {
+					rows = append(rows, []string{t.ID, t.Title, t.Status, "stale, last updated " + t.UpdatedAt})
+				}
+				for _, t := range breached {
+					rows = append(rows, []string{t.ID, t.Title, t.Status, "past due " + *t.DueAt})
+				}
+				for _, esc := range escalated {
+					rows = append(rows, []string{esc.TaskID, esc.Title, esc.Status, fmt.Sprintf("priority escalated %d -> %d", esc.From, esc.To)})
+				}
+				if len(rows) == 0 {
+					infof("(none)")
+					return nil
+				}
+				return renderRows("table", []string{"ID", "Title", "Status", "Found"}, rows)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&projectID, "project", "", "project id")
+	return cmd
+}
+
+func taskEscalationsCmd() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "escalations",
+		Short: "Preview the priority escalations of the next sweep",
+		Long:  "Lists the open tasks the next sweep would raise one priority level under project.escalation, with their current and new priority, without changing them.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if projectID == "" {
+					projectID = e.Config.Project.ID
+				}
+				items, err := e.PreviewEscalations(ctx, projectID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&projectID, "project", "", "project id")
+	return cmd
+}
+
+func nonNilTasks(tasks []domain.Task) []domain.Task {
+	if tasks == nil {
+		return []domain.Task{}
+	}
+	return tasks
+}
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the workspace database",
+	}
+	cmd.AddCommand(dbMigrateCmd())
+	cmd.AddCommand(dbBackupCmd())
+	cmd.AddCommand(dbRestoreCmd())
+	cmd.AddCommand(dbAnalyzeCmd())
+	cmd.AddCommand(dbFieldKeyCmd())
+	cmd.AddCommand(dbEncryptFieldsCmd())
+	return cmd
+}
+
+func dbFieldKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "field-key",
+		Short: "Generate a key for field encryption",
+		Long:  "Prints a random base64 key for encryption.key. Keep it out of the config itself: export it and reference it as ${ENV}, or store it in a file referenced as file://.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := fieldcrypt.GenerateKey()
+			if err != nil {
+				return err
+			}
+			fmt.Println(key)
+			return nil
+		},
+	}
+}
+
+func dbEncryptFieldsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt-fields",
+		Short: "Seal stored attestation payloads, work outcomes and decision context",
+		Long:  "Encrypts the values written before encryption was turned on and re-encrypts those sealed with one of encryption.previous_keys, so a rotated key can then be dropped. Run it after setting or rotating encryption.key.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				n, err := e.EncryptFields(ctx)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(map[string]int{"sealed": n})
+				}
+				infof("Sealed %d values\n", n)
+				return nil
+			})
+		},
+	}
+}
+
+func dbAnalyzeCmd() *cobra.Command {
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Show query plans of the hot read paths",
+		Long:  "Prints EXPLAIN QUERY PLAN output for the queries behind task lists, task reads, attestation checks, event pages and permission checks, flagging full table scans. With --strict, any full scan is an error, for catching index regressions in CI.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(conn *sql.DB) error {
+				if err := migrate.Check(conn); err != nil {
+					return err
+				}
+				plans, err := db.Analyze(cmd.Context(), conn)
+				if err != nil {
+					return err
+				}
+				var scans []string
+				for _, p := range plans {
+					if len(p.FullScans) > 0 {
+						scans = append(scans, p.Name)
+					}
+				}
+				if structuredOutput() {
+					if err := printStructured(plans); err != nil {
+						return err
+					}
+				} else {
+					for _, p := range plans {
+						fmt.Printf("%s\n  %s\n", p.Name, p.SQL)
+						for _, step := range p.Steps {
+							fmt.Printf("  - %s\n", step)
+						}
+						if len(p.FullScans) > 0 {
+							fmt.Printf("  ! full table scan\n")
+						}
+					}
+				}
+				if strict && len(scans) > 0 {
+					return fmt.Errorf("full table scans in %s", strings.Join(scans, ", "))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail when a hot query scans a whole table")
+	return cmd
+}
+
+func dbBackupCmd() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Snapshot the database (safe while wl serve runs)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if out == "" {
+				out = db.BackupName(time.Now())
+			}
+			return withDB(func(conn *sql.DB) error {
+				if err := db.Backup(cmd.Context(), conn, out); err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(map[string]string{"backup": out})
+				}
+				infof("Backup written to %s\n", out)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "output file (default workline-<timestamp>.db)")
+	return cmd
+}
+
+func dbRestoreCmd() *cobra.Command {
+	var from string
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Replace the database with a backup",
+		Long:  "Replaces .workline/workline.db with the backup. Stop wl serve first; the current database is overwritten, so --force is required.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				return fmt.Errorf("--from is required")
+			}
+			if !viper.GetBool("force") {
+				return fmt.Errorf("restore overwrites the current database; rerun with --force")
+			}
+			if err := checkBackupVersion(from); err != nil {
+				return err
+			}
+			workspace := viper.GetString("workspace")
+			if err := db.Restore(workspace, from); err != nil {
+				return err
+			}
+			if structuredOutput() {
+				return printStructured(map[string]string{"restored": from, "database": db.Path(workspace)})
+			}
+			infof("Restored %s from %s\n", db.Path(workspace), from)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "backup file")
+	return cmd
+}
+
+// checkBackupVersion refuses backups taken with a newer schema than this binary knows.
+func checkBackupVersion(path string) error {
+	conn, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	current, err := migrate.CurrentVersion(conn)
+	if err != nil {
+		return fmt.Errorf("%s is not a workline database: %w", path, err)
+	}
+	latest, err := migrate.LatestVersion()
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return migrate.VersionError{Current: current, Supported: latest}
+	}
+	return nil
+}
+
+func dbMigrateCmd() *cobra.Command {
+	var to int
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or revert schema migrations",
+		Long:  "Moves the schema to --to (default: latest). Reverting drops tables or columns and needs --force.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(conn *sql.DB) error {
+				target, err := migrateTarget(cmd, to)
+				if err != nil {
+					return err
+				}
+				steps, err := migrate.Plan(conn, target)
+				if err != nil {
+					return err
+				}
+				for _, step := range steps {
+					if step.Direction == migrate.DirectionDown && !viper.GetBool("force") {
+						return fmt.Errorf("reverting %s loses data; rerun with --force", step.Name)
+					}
+				}
+				if err := migrate.MigrateTo(conn, target); err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(map[string]any{"version": target, "steps": steps})
+				}
+				for _, step := range steps {
+					fmt.Printf("%s %s\n", step.Direction, step.Name)
+				}
+				infof("Schema at version %d\n", target)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "target schema version (default latest)")
+	cmd.AddCommand(dbMigrateStatusCmd())
+	cmd.AddCommand(dbMigratePlanCmd())
+	return cmd
+}
+
+func dbMigrateStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show applied and pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(conn *sql.DB) error {
+				migrations, current, err := migrate.Status(conn)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(map[string]any{"version": current, "migrations": migrations})
+				}
+				fmt.Printf("Schema version: %d\n", current)
+				for _, m := range migrations {
+					state := "pending"
+					if m.Applied {
+						state = "applied"
+					}
+					fmt.Printf("  %-8s %s\n", state, m.Name)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func dbMigratePlanCmd() *cobra.Command {
+	var to int
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "List the migrations that would run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDB(func(conn *sql.DB) error {
+				target, err := migrateTarget(cmd, to)
+				if err != nil {
+					return err
+				}
+				steps, err := migrate.Plan(conn, target)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(steps)
+				}
+				if len(steps) == 0 {
+					fmt.Println("Nothing to do")
+					return nil
+				}
+				for _, step := range steps {
+					fmt.Printf("%-4s %s\n", step.Direction, step.Name)
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "target schema version (default latest)")
+	return cmd
+}
+
+func migrateTarget(cmd *cobra.Command, to int) (int, error) {
+	if cmd.Flags().Changed("to") {
+		return to, nil
+	}
+	return migrate.LatestVersion()
+}
+
+func logCmd() *cobra.Command {
+	log := &cobra.Command{
+		Use:   "log",
+		Short: "Event log",
+		Long:  "The diary of everything that happened: task changes, policy applications, leases, and more.",
+	}
+	log.AddCommand(logTailCmd())
+	log.AddCommand(logCompactCmd())
+	log.AddCommand(logVerifyCmd())
+	log.AddCommand(logReadsCmd())
+	return log
+}
+
+func logCompactCmd() *cobra.Command {
+	var archive string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Archive and delete events outside project.event_retention",
+		Long:  "Events older than max_age_days or beyond the newest max_rows are written to a gzip-compressed NDJSON archive, then deleted. Exempt types (default force.*, rbac.*, org.*) are always kept.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				projectID := e.Config.Project.ID
+				if archive == "" && !dryRun {
+					dir := filepath.Join(viper.GetString("workspace"), ".workline", "archive")
+					if err := os.MkdirAll(dir, 0o755); err != nil {
+						return err
+					}
+					archive = filepath.Join(dir, fmt.Sprintf("events-%s-%s.ndjson.gz", projectID, time.Now().UTC().Format("20060102T150405Z")))
+				}
+				res, err := e.CompactEvents(ctx, engine.CompactEventsOptions{
+					ProjectID:   projectID,
+					ActorID:     viper.GetString("actor-id"),
+					ArchivePath: archive,
+					DryRun:      dryRun,
+				})
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(res)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&archive, "archive", "", "archive file (default .workline/archive/events-<project>-<timestamp>.ndjson.gz)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be compacted without deleting")
+	return cmd
+}
+
+func logVerifyCmd() *cobra.Command {
+	var anchor string
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the event hash chain",
+		Long:  "Recomputes the hash of each project event from its content and the previous event's hash and reports events that were altered, removed or inserted. Gaps left by wl log compact are accepted. --anchor checks that a head hash recorded earlier (GET /v0/projects/{id}/events/head) is still part of the chain. Exits non-zero when the chain is broken.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				report, err := e.VerifyEventChain(ctx, engine.EventChainOptions{
+					ProjectID: e.Config.Project.ID,
+					ActorID:   viper.GetString("actor-id"),
+					Anchor:    anchor,
+				})
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					if err := printStructured(report); err != nil {
+						return err
+					}
+				} else {
+					fmt.Printf("checked %d events (%d unhashed, %d compaction gaps), head %d %s\n", report.Checked, report.Unhashed, report.Compacted, report.Head.EventID, report.Head.Hash)
+					for _, b := range report.Breaks {
+						fmt.Printf("event %d: %s\n", b.EventID, b.Reason)
+					}
+				}
+				if !report.Valid {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("event chain broken: %d problems", len(report.Breaks))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&anchor, "anchor", "", "head hash recorded earlier that must still be in the chain")
+	return cmd
+}
+
+func projectionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "projection",
+		Short: "Read models rebuilt from the event log",
+		Long:  "Projections are tables derived from events only. Rebuilding one replays every project event into it, so a read model can be added or fixed without a data migration. Available: " + strings.Join(engine.Projections(), ", ") + ".",
+	}
+	cmd.AddCommand(projectionListCmd())
+	cmd.AddCommand(projectionRebuildCmd())
+	cmd.AddCommand(projectionShowCmd())
+	return cmd
+}
+
+func projectionListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List projections and how far behind the event log they are",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListProjections(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+}
+
+func projectionRebuildCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild <name>",
+		Short: "Rebuild a projection by replaying the project's events",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				res, err := e.RebuildProjection(ctx, args[0], e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(res)
+			})
+		},
+	}
+}
+
+func projectionShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a projection as of its last rebuild",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				res, err := e.ReadProjection(ctx, args[0], e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(res)
+			})
+		},
+	}
+}
+
+func outboxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Event outbox for message brokers",
+		Long:  "With an outbox section in workline.yml, every event is queued in the outbox in the same transaction that records it; a relay publishes queued events to NATS or Kafka and deletes them once acknowledged. wl serve runs the relay unless --no-outbox-relay is set.",
+	}
+	cmd.AddCommand(outboxStatusCmd())
+	cmd.AddCommand(outboxRelayCmd())
+	return cmd
+}
+
+func outboxStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show how many events wait for publishing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				pending, oldest, err := e.Repo.OutboxBacklog(ctx)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(map[string]any{
+					"broker":  e.Config.Outbox.Broker,
+					"pending": pending,
+					"oldest":  oldest,
+				})
+			})
+		},
+	}
+}
+
+func outboxRelayCmd() *cobra.Command {
+	var once bool
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "relay",
+		Short: "Publish queued events to the configured broker",
+		Long:  "Runs the outbox relay until interrupted, for deployments that write events without wl serve. With --once it publishes everything due and exits.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if !e.Config.Outbox.Enabled() {
+					return fmt.Errorf("project %s has no outbox config", e.Config.Project.ID)
+				}
+				pub, err := outbox.Open(e.Config.Outbox)
+				if err != nil {
+					return err
+				}
+				defer pub.Close()
+				relay := outbox.Relay{Repo: e.Repo, Publisher: pub}
+				if once {
+					total := 0
+					for {
+						n, err := relay.RunOnce(ctx)
+						if err != nil {
+							return err
+						}
+						if total += n; n < outbox.DefaultBatch {
+							infof("Published %d events\n", total)
+							return nil
+						}
+					}
+				}
+				ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				infof("Relaying outbox to %s\n", e.Config.Outbox.Broker)
+				relay.Run(ctx, interval)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&once, "once", false, "publish everything due, then exit")
+	cmd.Flags().DurationVar(&interval, "interval", outbox.DefaultInterval, "how often to poll an empty outbox")
+	return cmd
+}
+
+func rbacCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rbac",
+		Short: "RBAC management",
+	}
+	cmd.AddCommand(rbacWhoamiCmd())
+	cmd.AddCommand(rbacGrantCmd())
+	cmd.AddCommand(rbacRevokeCmd())
+	cmd.AddCommand(rbacGrantOrgCmd())
+	cmd.AddCommand(rbacRevokeOrgCmd())
+	cmd.AddCommand(rbacRolesCmd())
+	cmd.AddCommand(rbacPermissionsCmd())
+	cmd.AddCommand(rbacRoleCmd())
+	cmd.AddCommand(rbacAllowAttCmd())
+	cmd.AddCommand(rbacDenyAttCmd())
+	cmd.AddCommand(rbacApplyCmd())
+	cmd.AddCommand(rbacSimulateCmd())
+	cmd.AddCommand(rbacBootstrapCmd())
+	return cmd
+}
+
+func actorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "actor",
+		Short: "Actor lifecycle",
+	}
+	cmd.AddCommand(actorDeactivateCmd())
+	cmd.AddCommand(actorReactivateCmd())
+	cmd.AddCommand(actorScrubCmd())
+	return cmd
+}
+
+func actorDeactivateCmd() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "deactivate <actor-id>",
+		Short: "Suspend actor credentials and release its leases",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				released, err := e.DeactivateActor(ctx, e.Config.Project.ID, viper.GetString("actor-id"), args[0], reason)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(map[string]any{
+					"actor_id":        args[0],
+					"status":          engine.ActorStatusSuspended,
+					"released_leases": released,
+				})
+			})
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "reason recorded in the event log")
+	return cmd
+}
+
+func actorReactivateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reactivate <actor-id>",
+		Short: "Lift an actor suspension",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return e.ReactivateActor(ctx, e.Config.Project.ID, viper.GetString("actor-id"), args[0])
+			})
+		},
+	}
+}
+
+func actorScrubCmd() *cobra.Command {
+	var reason, confirm string
+	cmd := &cobra.Command{
+		Use:   "scrub <actor-id>",
+		Short: "Pseudonymize an actor for a data-deletion request",
+		Long:  "Replaces the actor id with a random pseudonym in tasks, leases, attestations, decisions, events and every other record naming it, releases its leases, deletes its role grants, API keys and missions, and blocks the id from signing in or being registered again. Only a hash of the id is kept. Rewritten events are rehashed, so event chain anchors recorded before the scrub no longer verify. It needs actor.scrub and owner or admin of every org the actor is known to. It cannot be undone: --confirm must repeat the actor id.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if confirm != args[0] {
+				return fmt.Errorf("refusing to scrub actor %s: pass --confirm %s", args[0], args[0])
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				scrub, err := e.ScrubActor(ctx, e.Config.Project.ID, viper.GetString("actor-id"), args[0], reason)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(scrub)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "reason recorded in the event log, such as the request reference")
+	cmd.Flags().StringVar(&confirm, "confirm", "", "actor id to scrub, repeated as a safeguard")
+	return cmd
+}
+
+func missionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mission",
+		Short: "Manage actor missions",
+	}
+	cmd.AddCommand(missionGetCmd())
+	cmd.AddCommand(missionListCmd())
+	cmd.AddCommand(missionSetCmd())
+	cmd.AddCommand(missionDeleteCmd())
+	return cmd
+}
+
+func missionGetCmd() *cobra.Command {
+	var actorID string
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get actor mission",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(actorID) == "" {
+				return fmt.Errorf("--actor required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				m, err := e.GetActorMission(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(m)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
+	return cmd
+}
+
+func missionListCmd() *cobra.Command {
+	var actorID string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List actor missions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListActorMissions(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actorID, "actor", "", "filter by actor id")
+	return cmd
+}
+
+func missionSetCmd() *cobra.Command {
+	var actorID, mission string
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set actor mission",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(actorID) == "" {
+				return fmt.Errorf("--actor required")
+			}
+			if strings.TrimSpace(mission) == "" {
+				return fmt.Errorf("--mission required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				m, err := e.SetActorMission(ctx, e.Config.Project.ID, actorID, mission, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(m)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
+	cmd.Flags().StringVar(&mission, "mission", "", "mission text")
+	return cmd
+}
+
+func missionDeleteCmd() *cobra.Command {
+	var actorID string
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete actor mission",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(actorID) == "" {
+				return fmt.Errorf("--actor required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return e.DeleteActorMission(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
+	return cmd
+}
+
+func policyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Check which policy applies and what done still needs",
+	}
+	cmd.AddCommand(policyCheckCmd())
+	cmd.AddCommand(policySimulateCmd())
+	return cmd
+}
+
+func policyCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check <task-id>",
+		Short: "Show a task's policy, missing attestations and other blockers for done",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				check, err := e.CheckTaskPolicy(ctx, args[0], viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printPolicyCheck(check)
+			})
+		},
+	}
+	return cmd
+}
+
+func policySimulateCmd() *cobra.Command {
+	var sim engine.PolicySimulation
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Show the policy a hypothetical task would get, without creating it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				check, err := e.SimulatePolicy(ctx, e.Config.Project.ID, sim, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printPolicyCheck(check)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&sim.Type, "type", "technical", "task type")
+	cmd.Flags().StringVar(&sim.Component, "component", "", "component")
+	cmd.Flags().StringVar(&sim.Policy, "policy", "", "policy preset (defaults to the task type's)")
+	cmd.Flags().StringSliceVar(&sim.Attestations, "attestation", nil, "attestation kind assumed recorded (repeatable)")
+	return cmd
+}
+
+func forceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "force",
+		Short: "Review forced operations waiting for approval",
+		Long:  "With force.require_approval set, --force records a force request instead of running the operation. A second actor with force.approve lists and approves them here.",
+	}
+	cmd.AddCommand(forceListCmd())
+	cmd.AddCommand(forceApproveCmd())
+	return cmd
+}
+
+func forceListCmd() *cobra.Command {
+	var status string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List force requests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				items, err := e.ListForceRequests(ctx, e.Config.Project.ID, status, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(items)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", "pending", "pending, approved, or empty for all")
+	return cmd
+}
+
+func forceApproveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve <request-id>",
+		Short: "Approve a force request and run its operation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				fr, err := e.ApproveForce(ctx, e.Config.Project.ID, args[0], viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(fr)
+			})
+		},
+	}
+	return cmd
+}
+
+func webhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Test webhooks and inspect or replay deliveries",
+		Long:  "Every webhook delivery attempt is logged with the receiver's status code. Deliveries carry X-Workline-Signature: sha256=<hex HMAC-SHA256 of the body keyed with the webhook secret>.",
+	}
+	cmd.AddCommand(webhookTestCmd())
+	cmd.AddCommand(webhookDeliveriesCmd())
+	cmd.AddCommand(webhookReplayCmd())
+	return cmd
+}
+
+func digestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Email the project digest",
+		Long:  "The digest summarizes completed tasks, newly blocked tasks, policy overrides and milestones due in the next two weeks since the previous digest. Configure SMTP, recipients and the schedule under digest: in workline.yml; wl serve sends scheduled digests.",
+	}
+	cmd.AddCommand(digestSendCmd())
+	return cmd
+}
+
+func digestSendCmd() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send the digest now",
+		Long:  "Emails the digest to digest.recipients and starts the next digest's window. --dry-run prints the email instead and changes nothing.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				actor := viper.GetString("actor-id")
+				if dryRun {
+					d, err := e.Digest(ctx, e.Config.Project.ID, actor)
+					if err != nil {
+						return err
+					}
+					if structuredOutput() {
+						return printStructured(d)
+					}
+					subject, body := engine.DigestMessage(d)
+					fmt.Printf("To: %s\nSubject: %s\n\n%s", strings.Join(d.Recipients, ", "), subject, body)
+					return nil
+				}
+				d, err := e.SendDigest(ctx, e.Config.Project.ID, actor)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(d)
+				}
+				infof("Digest sent to %s\n", strings.Join(d.Recipients, ", "))
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the email without sending it")
+	return cmd
+}
+
+func calendarCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calendar",
+		Short: "Manage calendar feed tokens",
+		Long:  "wl serve exposes iterations and milestones at /v0/projects/{id}/calendar.ics. Calendar clients subscribe with ?token=<feed token>: a token that reads that calendar only, as the actor that created it. API keys are not accepted in the URL.",
+	}
+	feed := &cobra.Command{
+		Use:   "feed",
+		Short: "Create, list and revoke calendar feed tokens",
+	}
+	feed.AddCommand(calendarFeedCreateCmd())
+	feed.AddCommand(calendarFeedListCmd())
+	feed.AddCommand(calendarFeedRevokeCmd())
+	cmd.AddCommand(feed)
+	return cmd
+}
+
+func calendarFeedCreateCmd() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a calendar feed token",
+		Long:  "Prints the token once; only its hash is stored. Needs iteration.list and milestone.list.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				feed, err := e.CreateCalendarFeed(ctx, e.Config.Project.ID, viper.GetString("actor-id"), name)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(feed)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "optional feed name")
+	return cmd
+}
+
+func calendarFeedListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List calendar feed tokens",
+		Long:  "Lists your feeds, or every feed of the project with rbac.manage. Tokens are not shown.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				feeds, err := e.CalendarFeeds(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(feeds)
+			})
+		},
+	}
+}
+
+func calendarFeedRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <feed-id>",
+		Short: "Revoke a calendar feed token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if err := e.RevokeCalendarFeed(ctx, e.Config.Project.ID, viper.GetString("actor-id"), args[0]); err != nil {
+					return err
+				}
+				infof("Calendar feed %s revoked\n", args[0])
+				return nil
+			})
+		},
+	}
+}
+
+func importCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import work from other trackers",
+	}
+	cmd.AddCommand(importJiraCmd())
+	return cmd
+}
+
+func importJiraCmd() *cobra.Command {
+	var filePath, siteURL, jql, user, token, epicField string
+	var typeMap map[string]string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "jira",
+		Short: "Import Jira issues, epics, sprints and links",
+		Long:  "Maps Jira issues to tasks, epics and parents to task parents, sprints to iterations and \"blocks\" links to dependencies, then prints a mapping report. Read a saved search result with --file, or search a site with --url and --jql. Issues are matched to earlier imports by key, so re-running the import updates status, parent, sprint, priority and assignee instead of creating duplicates. Statuses are forced, which needs force.use.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (filePath == "") == (siteURL == "") {
+				return fmt.Errorf("use either --file or --url")
+			}
+			var export app.JiraExport
+			var err error
+			if filePath != "" {
+				export, err = app.LoadJiraExport(filePath)
+			} else {
+				if jql == "" {
+					return fmt.Errorf("--jql is required with --url")
+				}
+				if token == "" {
+					token = os.Getenv("JIRA_API_TOKEN")
+				}
+				export, err = app.FetchJira(cmd.Context(), siteURL, jql, user, token)
+			}
+			if err != nil {
+				return err
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				report, err := app.ImportJira(ctx, e, export, app.JiraImportOptions{
+					ProjectID: e.Config.Project.ID,
+					ActorID:   viper.GetString("actor-id"),
+					EpicField: epicField,
+					TypeMap:   typeMap,
+					DryRun:    dryRun,
+				})
+				if err != nil {
+					return err
+				}
+				for _, w := range report.Warnings {
+					warnf("%s", w)
+				}
+				return printJSONOrTable(report)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&filePath, "file", "", "saved Jira search result (JSON)")
+	cmd.Flags().StringVar(&siteURL, "url", "", "Jira site to search, e.g. https://acme.atlassian.net")
+	cmd.Flags().StringVar(&jql, "jql", "", "JQL selecting the issues to import, with --url")
+	cmd.Flags().StringVar(&user, "user", "", "account email for Jira Cloud basic auth (omit to send the token as a bearer token)")
+	cmd.Flags().StringVar(&token, "token", "", "API token or personal access token (default $JIRA_API_TOKEN)")
+	cmd.Flags().StringVar(&epicField, "epic-field", app.DefaultJiraEpicField, "custom field holding epic links")
+	cmd.Flags().StringToStringVar(&typeMap, "type-map", nil, "Jira issue type to task type, e.g. Story=feature,Spike=technical")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report the mapping without importing")
+	return cmd
+}
+
+// remoteFlags name a Workline server and the credentials for it, for the
+// commands that talk to one.
+type remoteFlags struct {
+	url, project, apiKey, token string
+}
+
+func (f *remoteFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.url, "remote", "", "root URL of the server, e.g. https://workline.example.com")
+	cmd.Flags().StringVar(&f.project, "remote-project", "", "project id on the server (default the local project id)")
+	cmd.Flags().StringVar(&f.apiKey, "api-key", "", "API key on the server (default $WORKLINE_REMOTE_API_KEY)")
+	cmd.Flags().StringVar(&f.token, "token", "", "bearer token on the server (default $WORKLINE_REMOTE_TOKEN)")
+}
+
+// name returns the name sync state and the offline queue are kept under.
+func (f *remoteFlags) name(projectID string) string {
+	project := f.project
+	if project == "" {
+		project = projectID
+	}
+	return strings.TrimRight(f.url, "/") + "#" + project
+}
+
+// client returns the server's copy of the local project.
+func (f *remoteFlags) client(projectID string) (app.RemoteTasks, error) {
+	if strings.TrimSpace(f.url) == "" {
+		return app.RemoteTasks{}, fmt.Errorf("--remote required")
+	}
+	apiKey, token := f.apiKey, f.token
+	if apiKey == "" {
+		apiKey = os.Getenv("WORKLINE_REMOTE_API_KEY")
+	}
+	if token == "" {
+		token = os.Getenv("WORKLINE_REMOTE_TOKEN")
+	}
+	project := f.project
+	if project == "" {
+		project = projectID
+	}
+	client := worklinesdk.New(f.url, project)
+	client.APIKey, client.BearerToken = apiKey, token
+	client.Timeout = 30 * time.Second
+	return app.RemoteTasks{Client: client}, nil
+}
+
+// options returns the engine options for remote-mode commands and the
+// offline queue of the server.
+func (f *remoteFlags) options(e engine.Engine) (engine.RemoteOptions, error) {
+	projectID := e.Config.Project.ID
+	client, err := f.client(projectID)
+	if err != nil {
+		return engine.RemoteOptions{}, err
+	}
+	key, err := app.QueueKey(viper.GetString("workspace"))
+	if err != nil {
+		return engine.RemoteOptions{}, err
+	}
+	return engine.RemoteOptions{
+		ProjectID: projectID,
+		ActorID:   viper.GetString("actor-id"),
+		Remote:    f.name(projectID),
+		Client:    client,
+		Key:       key,
+	}, nil
+}
+
+func syncCmd() *cobra.Command {
+	var remote remoteFlags
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Push and pull task changes with a central server",
+		Long: "Pushes the tasks changed locally since the last sync with --remote and pulls those changed on the server, following the event cursors of both sides, so a workspace can work offline and catch up with a hub. " +
+			"Status, assignee, priority and work outcomes are synced; tasks missing on one side are created there with their id, type, title and description. " +
+			"A field changed on one side takes that side's value. When both sides changed it the server wins, except work outcomes, which are merged key by key. " +
+			"Pushes the server refuses, such as a status transition its workflow rejects, also leave the server's value; each such case is listed under conflicts. " +
+			"Pulled changes are logged as task.synced. Needs project.sync locally and task permissions on the server.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(remote.url) == "" {
+				return fmt.Errorf("--remote required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				projectID := e.Config.Project.ID
+				client, err := remote.client(projectID)
+				if err != nil {
+					return err
+				}
+				report, err := e.Sync(ctx, engine.SyncOptions{
+					ProjectID: projectID,
+					ActorID:   viper.GetString("actor-id"),
+					Remote:    remote.name(projectID),
+					Client:    client,
+				})
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(report)
+				}
+				if quiet() {
+					printIDs(append(append([]string{}, report.Pushed...), report.Pulled...))
+					return nil
+				}
+				fmt.Printf("Pushed %d, pulled %d tasks with %s\n", len(report.Pushed), len(report.Pulled), remote.url)
+				if len(report.Conflicts) == 0 {
+					return nil
+				}
+				rows := make([][]string, 0, len(report.Conflicts))
+				for _, c := range report.Conflicts {
+					rows = append(rows, []string{c.TaskID, c.Field, cell(c.Local), cell(c.Remote), c.Resolution, c.Reason})
+				}
+				return renderTable("table", []string{"TASK", "FIELD", "LOCAL", "REMOTE", "RESOLUTION", "REASON"}, rows)
+			})
+		},
+	}
+	remote.register(cmd)
+	return cmd
+}
+
+func remoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Run commands on a central server (remote mode)",
+		Long:  "Runs mutating commands directly on the server named by --remote instead of the local workspace. When the server cannot be reached, or earlier commands for it are still queued, the command is kept in the signed offline queue (wl queue list) and sent by wl queue replay.",
+	}
+	task := &cobra.Command{Use: "task", Short: "Create and update tasks on the server"}
+	task.AddCommand(remoteTaskCreateCmd(), remoteTaskUpdateCmd())
+	cmd.AddCommand(task)
+	return cmd
+}
+
+// runRemote runs cmd through the offline queue and reports where it went.
+func runRemote(ctx context.Context, e engine.Engine, remote *remoteFlags, cmd engine.RemoteCommand) error {
+	opts, err := remote.options(e)
+	if err != nil {
+		return err
+	}
+	task, queued, err := e.RunRemoteCommand(ctx, opts, cmd)
+	if err != nil {
+		return err
+	}
+	if queued.Seq != 0 {
+		if structuredOutput() {
+			return printStructured(queued)
+		}
+		if quiet() {
+			fmt.Println(queued.Seq)
+			return nil
+		}
+		fmt.Printf("Queued as %d for %s (task %s); send it with wl queue replay\n", queued.Seq, remote.url, queued.TaskID)
+		return nil
+	}
+	if structuredOutput() {
+		return printStructured(task)
+	}
+	if quiet() {
+		fmt.Println(task.ID)
+		return nil
+	}
+	fmt.Printf("Task %s %s on %s\n", task.ID, map[string]string{engine.RemoteTaskCreate: "created", engine.RemoteTaskUpdate: "updated"}[cmd.Op], remote.url)
+	return nil
+}
+
+func remoteTaskCreateCmd() *cobra.Command {
+	var remote remoteFlags
+	var id, title, taskType, description, assignee string
+	var priority int
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a task on the server, or queue it",
+		Long:  "Creates a task on the server. A task queued while the server is unreachable gets an id now, so later commands can name it.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t := engine.SyncTask{ID: id, Type: taskType, Title: title, Description: description}
+			if assignee != "" {
+				t.AssigneeID = &assignee
+			}
+			if cmd.Flags().Changed("priority") {
+				t.Priority = &priority
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return runRemote(ctx, e, &remote, engine.RemoteCommand{Op: engine.RemoteTaskCreate, Task: t})
+			})
+		},
+	}
+	remote.register(cmd)
+	cmd.Flags().StringVar(&id, "id", "", "task id (default assigned by the server, or generated when queued)")
+	cmd.Flags().StringVar(&title, "title", "", "task title")
+	cmd.Flags().StringVar(&taskType, "type", "", "task type")
+	cmd.Flags().StringVar(&description, "description", "", "task description")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "assignee actor id")
+	cmd.Flags().IntVar(&priority, "priority", 0, "priority")
+	return cmd
+}
+
+func remoteTaskUpdateCmd() *cobra.Command {
+	var remote remoteFlags
+	var status, assignee, outcomesJSON string
+	var priority int
+	cmd := &cobra.Command{
+		Use:   "update <task-id>",
+		Short: "Update a task on the server, or queue the update",
+		Long: "Sets the status, assignee (empty to unassign), priority or work outcomes of a task on the server, holding its lease for the update. " +
+			"A queued update remembers the task as last synced with the server; replay holds it as a conflict when the server changed one of its fields since.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := engine.RemoteCommand{Op: engine.RemoteTaskUpdate, Task: engine.SyncTask{ID: args[0]}}
+			if cmd.Flags().Changed("status") {
+				c.Task.Status = status
+				c.Fields = append(c.Fields, "status")
+			}
+			if cmd.Flags().Changed("assignee") {
+				if assignee != "" {
+					c.Task.AssigneeID = &assignee
+				}
+				c.Fields = append(c.Fields, "assignee_id")
+			}
+			if cmd.Flags().Changed("priority") {
+				c.Task.Priority = &priority
+				c.Fields = append(c.Fields, "priority")
+			}
+			if cmd.Flags().Changed("work-outcomes-json") {
+				if outcomesJSON != "" {
+					if err := json.Unmarshal([]byte(outcomesJSON), &c.Task.WorkOutcomes); err != nil {
+						return fmt.Errorf("work-outcomes-json: %w", err)
+					}
+				}
+				c.Fields = append(c.Fields, "work_outcomes")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return runRemote(ctx, e, &remote, c)
+			})
+		},
+	}
+	remote.register(cmd)
+	cmd.Flags().StringVar(&status, "status", "", "new status")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "assignee actor id, empty to unassign")
+	cmd.Flags().IntVar(&priority, "priority", 0, "priority")
+	cmd.Flags().StringVar(&outcomesJSON, "work-outcomes-json", "", "work outcomes as a JSON object, empty to clear")
+	return cmd
+}
+
+func queueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspect and replay the offline queue of remote-mode commands",
+	}
+	cmd.AddCommand(queueListCmd(), queueReplayCmd(), queueDropCmd())
+	return cmd
+}
+
+func queueListCmd() *cobra.Command {
+	var remote remoteFlags
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List queued remote-mode commands",
+		Long:  "Lists the commands waiting for a server in the order they will be replayed, with --remote only those for that server. Held commands show why.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				name := ""
+				if remote.url != "" {
+					name = remote.name(e.Config.Project.ID)
+				}
+				queued, err := e.QueuedCommands(ctx, e.Config.Project.ID, viper.GetString("actor-id"), name)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(queued)
+				}
+				if quiet() {
+					for _, c := range queued {
+						fmt.Println(c.Seq)
+					}
+					return nil
+				}
+				rows := make([][]string, 0, len(queued))
+				for _, c := range queued {
+					rows = append(rows, []string{strconv.FormatInt(c.Seq, 10), c.Remote, c.Op, c.TaskID, strings.Join(c.Fields, ","), c.Status, c.Reason, c.QueuedAt})
+				}
+				return renderTable("table", []string{"SEQ", "REMOTE", "OP", "TASK", "FIELDS", "STATUS", "REASON", "QUEUED_AT"}, rows)
+			})
+		},
+	}
+	remote.register(cmd)
+	return cmd
+}
+
+func queueReplayCmd() *cobra.Command {
+	var remote remoteFlags
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Send queued remote-mode commands to the server",
+		Long: "Sends the commands queued for --remote in order, dropping those the server takes. Commands whose signature does not verify or that the server refuses are held as rejected. " +
+			"An update is held as a conflict when the server changed one of its fields since it was queued, a create when the task already exists; --force sends conflicts anyway. " +
+			"Later commands for a held task wait behind it; wl queue drop discards one. Replay stops when the server is unreachable again.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				opts, err := remote.options(e)
+				if err != nil {
+					return err
+				}
+				report, replayErr := e.ReplayQueue(ctx, opts, force)
+				if replayErr != nil && !errors.Is(replayErr, engine.ErrRemoteUnreachable) {
+					return replayErr
+				}
+				if structuredOutput() {
+					if err := printStructured(report); err != nil {
+						return err
+					}
+					return replayErr
+				}
+				if quiet() {
+					printIDs(report.Applied)
+					return replayErr
+				}
+				fmt.Printf("Replayed %d commands to %s, %d held, %d still queued\n", len(report.Applied), remote.url, len(report.Held), report.Pending)
+				if len(report.Held) > 0 {
+					rows := make([][]string, 0, len(report.Held))
+					for _, c := range report.Held {
+						rows = append(rows, []string{strconv.FormatInt(c.Seq, 10), c.Op, c.TaskID, c.Status, c.Reason})
+					}
+					if err := renderTable("table", []string{"SEQ", "OP", "TASK", "STATUS", "REASON"}, rows); err != nil {
+						return err
+					}
+				}
+				return replayErr
 			})
 		},
 	}
-	cmd.Flags().StringVar(&f.ProjectID, "project", "", "project id")
-	cmd.Flags().StringVar(&f.EntityKind, "entity-kind", "", "entity kind filter")
-	cmd.Flags().StringVar(&f.EntityID, "entity-id", "", "entity id filter")
-	cmd.Flags().StringVar(&f.Kind, "kind", "", "kind filter")
+	remote.register(cmd)
+	cmd.Flags().BoolVar(&force, "force", false, "send commands held as conflicts anyway")
 	return cmd
 }
 
-func logCmd() *cobra.Command {
-	log := &cobra.Command{
-		Use:   "log",
-		Short: "Event log",
-		Long:  "The diary of everything that happened: task changes, policy applications, leases, and more.",
+func queueDropCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drop <seq>",
+		Short: "Discard a queued remote-mode command",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			seq, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid seq %q", args[0])
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if err := e.DropQueuedCommand(ctx, e.Config.Project.ID, viper.GetString("actor-id"), seq); err != nil {
+					return err
+				}
+				infof("Queued command %d dropped\n", seq)
+				return nil
+			})
+		},
 	}
-	log.AddCommand(logTailCmd())
-	return log
 }
 
-func rbacCmd() *cobra.Command {
+func gitCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "rbac",
-		Short: "RBAC management",
+		Use:   "git",
+		Short: "Link git commits to tasks",
 	}
-	cmd.AddCommand(rbacWhoamiCmd())
-	cmd.AddCommand(rbacGrantCmd())
-	cmd.AddCommand(rbacRevokeCmd())
-	cmd.AddCommand(rbacAllowAttCmd())
-	cmd.AddCommand(rbacDenyAttCmd())
-	cmd.AddCommand(rbacBootstrapCmd())
+	cmd.AddCommand(gitScanCmd())
 	return cmd
 }
 
-func missionCmd() *cobra.Command {
+func gitScanCmd() *cobra.Command {
+	var opts app.GitScanOptions
 	cmd := &cobra.Command{
-		Use:   "mission",
-		Short: "Manage actor missions",
+		Use:   "scan",
+		Short: "Attach commits with WL-Task trailers to their tasks",
+		Long: "Scans the messages of the commits after --since up to --rev for WL-Task: <task-id> trailers and appends each commit (sha, subject, author, date) to the named tasks' work outcomes under \"commits\". With --attest, each newly attached commit also adds a code.committed attestation. Commits already attached are skipped, so rescanning is safe.\n\n" +
+			"In a post-receive hook, scan each pushed range:\n\n" +
+			"  while read old new ref; do wl git scan --repo . --since \"$old\" --rev \"$new\" --attest; done",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				opts.ProjectID = e.Config.Project.ID
+				opts.ActorID = viper.GetString("actor-id")
+				report, err := app.ScanGit(ctx, e, opts)
+				if err != nil {
+					return err
+				}
+				for _, w := range report.Warnings {
+					warnf("%s", w)
+				}
+				return printJSONOrTable(report)
+			})
+		},
 	}
-	cmd.AddCommand(missionGetCmd())
-	cmd.AddCommand(missionListCmd())
-	cmd.AddCommand(missionSetCmd())
-	cmd.AddCommand(missionDeleteCmd())
+	cmd.Flags().StringVar(&opts.Repo, "repo", ".", "path of the git repository")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "scan commits after this revision (default: the whole history)")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "HEAD", "last revision to scan")
+	cmd.Flags().BoolVar(&opts.Attest, "attest", false, "add a code.committed attestation for each attached commit")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "report the links without attaching anything")
 	return cmd
 }
 
-func missionGetCmd() *cobra.Command {
-	var actorID string
+func gateCmd() *cobra.Command {
+	var opts engine.GateOptions
+	var taskIDs []string
+	var commit, repoPath string
 	cmd := &cobra.Command{
-		Use:   "get",
-		Short: "Get actor mission",
+		Use:   "gate",
+		Short: "Fail unless tasks have the required status and attestations",
+		Long: "Checks tasks against the conditions a CI pipeline or release script requires and exits non-zero when any is unmet. Name tasks with --task, or with --commit to gate the tasks of that commit's WL-Task trailers.\n\n" +
+			"  wl gate --task <id> --require-status review --require ci.passed\n" +
+			"  wl gate --commit HEAD --require-status done --policy",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if strings.TrimSpace(actorID) == "" {
-				return fmt.Errorf("--actor required")
-			}
-			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				m, err := e.GetActorMission(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+			ids := slices.Clone(taskIDs)
+			if commit != "" {
+				c, err := app.ReadGitCommit(cmd.Context(), repoPath, commit)
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(m)
+				if len(c.TaskIDs) == 0 {
+					return fmt.Errorf("commit %s has no %s trailer", commit, app.GitTaskTrailer)
+				}
+				ids = append(ids, c.TaskIDs...)
+			}
+			if len(ids) == 0 {
+				return fmt.Errorf("--task or --commit is required")
+			}
+			opts.ActorID = viper.GetString("actor-id")
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				results := make([]domain.GateResult, 0, len(ids))
+				failed := 0
+				for _, id := range ids {
+					opts.TaskID = id
+					res, err := e.Gate(ctx, opts)
+					if errors.Is(err, repo.ErrNotFound) {
+						res = domain.GateResult{TaskID: id, Failures: []string{"task not found"}}
+					} else if err != nil {
+						return fmt.Errorf("task %s: %w", id, err)
+					}
+					if !res.Passed {
+						failed++
+					}
+					results = append(results, res)
+				}
+				if structuredOutput() {
+					if err := printStructured(results); err != nil {
+						return err
+					}
+				} else {
+					for _, res := range results {
+						label := res.TaskID
+						if res.Status != "" {
+							label += fmt.Sprintf("  %s (%s)", res.Title, res.Status)
+						}
+						if res.Passed {
+							fmt.Printf("pass  %s\n", label)
+							continue
+						}
+						fmt.Printf("FAIL  %s\n", label)
+						for _, f := range res.Failures {
+							fmt.Printf("      %s\n", f)
+						}
+					}
+				}
+				if failed > 0 {
+					return gateFailedError{failed: failed, total: len(results)}
+				}
+				return nil
 			})
 		},
 	}
-	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
+	cmd.Flags().StringArrayVar(&taskIDs, "task", nil, "task id to gate (repeatable)")
+	cmd.Flags().StringVar(&commit, "commit", "", "gate the tasks named by this commit's WL-Task trailers")
+	cmd.Flags().StringVar(&repoPath, "repo", ".", "git repository for --commit")
+	cmd.Flags().StringArrayVar(&opts.Statuses, "require-status", nil, "status the task must be in (repeatable; any matches)")
+	cmd.Flags().StringArrayVar(&opts.Require, "require", nil, "attestation kind the task must have, unexpired (repeatable)")
+	cmd.Flags().BoolVar(&opts.Policy, "policy", false, "also require every attestation of the task's policy")
 	return cmd
 }
 
-func missionListCmd() *cobra.Command {
-	var actorID string
+// printDelivery prints a delivery attempt and fails when it did not go through.
+func printDelivery(d domain.WebhookDelivery) error {
+	if err := printJSONOrTable(d); err != nil {
+		return err
+	}
+	if d.Error != "" {
+		return fmt.Errorf("delivery %s failed: %s", d.ID, d.Error)
+	}
+	return nil
+}
+
+func webhookTestCmd() *cobra.Command {
+	var url, secret string
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List actor missions",
+		Use:   "test",
+		Short: "Send a signed sample event to a webhook URL",
+		Long:  "Posts a webhook.test event to --url. A configured webhook with that URL supplies the secret and timeout unless --secret is given.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				items, err := e.ListActorMissions(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+				d, err := e.TestWebhook(ctx, e.Config.Project.ID, url, secret, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(items)
+				return printDelivery(d)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&actorID, "actor", "", "filter by actor id")
+	cmd.Flags().StringVar(&url, "url", "", "webhook URL")
+	cmd.Flags().StringVar(&secret, "secret", "", "signing secret (default: the configured webhook's)")
+	_ = cmd.MarkFlagRequired("url")
 	return cmd
 }
 
-func missionSetCmd() *cobra.Command {
-	var actorID, mission string
+func webhookDeliveriesCmd() *cobra.Command {
+	var failed bool
+	var limit int
 	cmd := &cobra.Command{
-		Use:   "set",
-		Short: "Set actor mission",
+		Use:   "deliveries",
+		Short: "List recent delivery attempts",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if strings.TrimSpace(actorID) == "" {
-				return fmt.Errorf("--actor required")
-			}
-			if strings.TrimSpace(mission) == "" {
-				return fmt.Errorf("--mission required")
-			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				m, err := e.SetActorMission(ctx, e.Config.Project.ID, actorID, mission, viper.GetString("actor-id"))
+				items, err := e.ListWebhookDeliveries(ctx, e.Config.Project.ID, failed, limit, viper.GetString("actor-id"))
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(m)
+				return printJSONOrTable(items)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
-	cmd.Flags().StringVar(&mission, "mission", "", "mission text")
+	cmd.Flags().BoolVar(&failed, "failed", false, "only failed attempts")
+	cmd.Flags().IntVar(&limit, "limit", 20, "number of attempts")
 	return cmd
 }
 
-func missionDeleteCmd() *cobra.Command {
-	var actorID string
+func webhookReplayCmd() *cobra.Command {
+	var deliveryID string
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete actor mission",
+		Use:   "replay",
+		Short: "Resend a logged delivery",
+		Long:  "Resends the exact body of --delivery to its webhook, signed with the webhook's current secret. The webhook must still be configured.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if strings.TrimSpace(actorID) == "" {
-				return fmt.Errorf("--actor required")
-			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return e.DeleteActorMission(ctx, e.Config.Project.ID, actorID, viper.GetString("actor-id"))
+				d, err := e.ReplayWebhookDelivery(ctx, e.Config.Project.ID, deliveryID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printDelivery(d)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&actorID, "actor", "", "actor id")
+	cmd.Flags().StringVar(&deliveryID, "delivery", "", "delivery id (see wl webhook deliveries)")
+	_ = cmd.MarkFlagRequired("delivery")
 	return cmd
 }
 
+func printPolicyCheck(c domain.PolicyCheck) error {
+	if structuredOutput() {
+		return printStructured(c)
+	}
+	policy := c.Policy
+	if policy == "" {
+		policy = "none"
+	}
+	if c.Override {
+		policy += " (task overrides it)"
+	}
+	fmt.Printf("type:      %s\n", c.TaskType)
+	if c.Component != "" {
+		fmt.Printf("component: %s\n", c.Component)
+	}
+	fmt.Printf("policy:    %s\n", policy)
+	fmt.Printf("required:  %s\n", listOrDash(c.Required))
+	fmt.Printf("present:   %s\n", listOrDash(c.Present))
+	fmt.Printf("missing:   %s\n", listOrDash(c.Missing))
+	if len(c.Expired) > 0 {
+		fmt.Printf("expired:   %s\n", strings.Join(c.Expired, ", "))
+	}
+	for _, b := range c.Blockers {
+		fmt.Printf("blocked:   %s\n", b)
+	}
+	switch {
+	case !c.Satisfied:
+		fmt.Println("done:      not yet, attestations missing")
+	case len(c.Blockers) > 0:
+		fmt.Println("done:      not yet, blocked")
+	default:
+		fmt.Println("done:      allowed")
+	}
+	return nil
+}
+
+func listOrDash(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
+
 func validationCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "validation",
@@ -1231,84 +4696,295 @@ func apiKeyListCmd() *cobra.Command {
 						CreatedAt: key.CreatedAt,
 					})
 				}
-				return printJSONOrTable(out)
+				return printJSONOrTable(out)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&actorID, "actor", "", "filter by actor id")
+	return cmd
+}
+
+func apiKeyRevokeCmd() *cobra.Command {
+	var id string
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke API key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(id) == "" {
+				return fmt.Errorf("--id required")
+			}
+			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
+				return r.DeleteAPIKey(ctx, id)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&id, "id", "", "api key id")
+	return cmd
+}
+
+func rbacWhoamiCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show current actor roles and permissions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				who, err := e.WhoAmI(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(who)
+			})
+		},
+	}
+	return cmd
+}
+
+func rbacGrantCmd() *cobra.Command {
+	var target, role, expires string
+	var expiresIn time.Duration
+	cmd := &cobra.Command{
+		Use:   "grant-role",
+		Short: "Grant role to actor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" || role == "" {
+				return fmt.Errorf("--actor and --role required")
+			}
+			if expires != "" && expiresIn != 0 {
+				return fmt.Errorf("--expires and --for are mutually exclusive")
+			}
+			if expiresIn < 0 {
+				return fmt.Errorf("--for must be positive")
+			}
+			if expiresIn > 0 {
+				expires = time.Now().UTC().Add(expiresIn).Format(time.RFC3339)
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return e.GrantRoleUntil(ctx, e.Config.Project.ID, viper.GetString("actor-id"), target, role, expires)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	cmd.Flags().StringVar(&role, "role", "", "role id")
+	cmd.Flags().StringVar(&expires, "expires", "", "revoke the grant at this time, RFC3339 or YYYY-MM-DD for the end of that day")
+	cmd.Flags().DurationVar(&expiresIn, "for", 0, "revoke the grant after this long, e.g. 72h")
+	return cmd
+}
+
+func rbacRevokeCmd() *cobra.Command {
+	var target, role string
+	cmd := &cobra.Command{
+		Use:   "revoke-role",
+		Short: "Revoke role from actor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" || role == "" {
+				return fmt.Errorf("--actor and --role required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				return e.RevokeRole(ctx, e.Config.Project.ID, viper.GetString("actor-id"), target, role)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	cmd.Flags().StringVar(&role, "role", "", "role id")
+	return cmd
+}
+
+func rbacGrantOrgCmd() *cobra.Command {
+	var orgID, target, role string
+	cmd := &cobra.Command{
+		Use:   "grant-org-role",
+		Short: "Grant role to actor in every project of an org",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" || role == "" {
+				return fmt.Errorf("--actor and --role required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if orgID == "" {
+					p, err := e.Repo.GetProject(ctx, e.Config.Project.ID)
+					if err != nil {
+						return err
+					}
+					orgID = p.OrgID
+				}
+				return e.GrantOrgRole(ctx, orgID, viper.GetString("actor-id"), target, role)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&orgID, "org", "", "org id (defaults to the current project's org)")
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	cmd.Flags().StringVar(&role, "role", "", "role id")
+	return cmd
+}
+
+func rbacRevokeOrgCmd() *cobra.Command {
+	var orgID, target, role string
+	cmd := &cobra.Command{
+		Use:   "revoke-org-role",
+		Short: "Revoke org-level role from actor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" || role == "" {
+				return fmt.Errorf("--actor and --role required")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				if orgID == "" {
+					p, err := e.Repo.GetProject(ctx, e.Config.Project.ID)
+					if err != nil {
+						return err
+					}
+					orgID = p.OrgID
+				}
+				return e.RevokeOrgRole(ctx, orgID, viper.GetString("actor-id"), target, role)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&orgID, "org", "", "org id (defaults to the current project's org)")
+	cmd.Flags().StringVar(&target, "actor", "", "actor id")
+	cmd.Flags().StringVar(&role, "role", "", "role id")
+	return cmd
+}
+
+func rbacRolesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "roles",
+		Short: "List roles and their permissions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				roles, err := e.ListRoles(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(roles)
+			})
+		},
+	}
+}
+
+func rbacPermissionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "permissions",
+		Short: "List permissions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				perms, err := e.ListPermissions(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(perms)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&actorID, "actor", "", "filter by actor id")
-	return cmd
 }
 
-func apiKeyRevokeCmd() *cobra.Command {
-	var id string
+func rbacRoleShowCmd() *cobra.Command {
+	var roleID string
 	cmd := &cobra.Command{
-		Use:   "revoke",
-		Short: "Revoke API key",
+		Use:   "show",
+		Short: "Show role grants, attestation authorities and members",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if strings.TrimSpace(id) == "" {
+			if roleID == "" {
 				return fmt.Errorf("--id required")
 			}
-			return withRepo(cmd.Context(), func(ctx context.Context, r repo.Repo) error {
-				return r.DeleteAPIKey(ctx, id)
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				role, err := e.InspectRole(ctx, e.Config.Project.ID, viper.GetString("actor-id"), roleID)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(role)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&id, "id", "", "api key id")
+	cmd.Flags().StringVar(&roleID, "id", "", "role id")
 	return cmd
 }
 
-func rbacWhoamiCmd() *cobra.Command {
+func rbacRoleCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "whoami",
-		Short: "Show current actor roles and permissions",
+		Use:   "role",
+		Short: "Define custom roles",
+	}
+	cmd.AddCommand(rbacRoleShowCmd())
+	cmd.AddCommand(rbacRoleCreateCmd())
+	cmd.AddCommand(rbacRoleUpdateCmd())
+	cmd.AddCommand(rbacRoleDeleteCmd())
+	return cmd
+}
+
+func rbacRoleCreateCmd() *cobra.Command {
+	var opts engine.RoleCreateOptions
+	var grants []string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a project role with a set of permissions",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.RoleID == "" {
+				return fmt.Errorf("--id required")
+			}
+			opts.ActorID = viper.GetString("actor-id")
+			opts.Grants = grants
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				who, err := e.WhoAmI(ctx, e.Config.Project.ID, viper.GetString("actor-id"))
+				opts.ProjectID = e.Config.Project.ID
+				role, err := e.CreateRole(ctx, opts)
 				if err != nil {
 					return err
 				}
-				return printJSONOrTable(who)
+				return printJSONOrTable(role)
 			})
 		},
 	}
+	cmd.Flags().StringVar(&opts.RoleID, "id", "", "role id")
+	cmd.Flags().StringVar(&opts.Description, "description", "", "role description")
+	cmd.Flags().StringArrayVar(&grants, "grant", []string{}, "permission or permission set (repeatable)")
 	return cmd
 }
 
-func rbacGrantCmd() *cobra.Command {
-	var target, role string
+func rbacRoleUpdateCmd() *cobra.Command {
+	var roleID, description string
+	var grants []string
 	cmd := &cobra.Command{
-		Use:   "grant-role",
-		Short: "Grant role to actor",
+		Use:   "update",
+		Short: "Update a custom role description or replace its permissions",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if target == "" || role == "" {
-				return fmt.Errorf("--actor and --role required")
+			if roleID == "" {
+				return fmt.Errorf("--id required")
+			}
+			opts := engine.RoleUpdateOptions{RoleID: roleID, ActorID: viper.GetString("actor-id")}
+			if cmd.Flags().Changed("description") {
+				opts.Description = &description
+			}
+			if cmd.Flags().Changed("grant") {
+				opts.Grants = &grants
 			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return e.GrantRole(ctx, e.Config.Project.ID, viper.GetString("actor-id"), target, role)
+				opts.ProjectID = e.Config.Project.ID
+				role, err := e.UpdateRole(ctx, opts)
+				if err != nil {
+					return err
+				}
+				return printJSONOrTable(role)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&target, "actor", "", "actor id")
-	cmd.Flags().StringVar(&role, "role", "", "role id")
+	cmd.Flags().StringVar(&roleID, "id", "", "role id")
+	cmd.Flags().StringVar(&description, "description", "", "role description")
+	cmd.Flags().StringArrayVar(&grants, "grant", []string{}, "permission or permission set; replaces existing grants (repeatable)")
 	return cmd
 }
 
-func rbacRevokeCmd() *cobra.Command {
-	var target, role string
+func rbacRoleDeleteCmd() *cobra.Command {
+	var roleID string
 	cmd := &cobra.Command{
-		Use:   "revoke-role",
-		Short: "Revoke role from actor",
+		Use:   "delete",
+		Short: "Delete a custom role",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if target == "" || role == "" {
-				return fmt.Errorf("--actor and --role required")
+			if roleID == "" {
+				return fmt.Errorf("--id required")
 			}
 			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
-				return e.RevokeRole(ctx, e.Config.Project.ID, viper.GetString("actor-id"), target, role)
+				return e.DeleteRole(ctx, e.Config.Project.ID, viper.GetString("actor-id"), roleID)
 			})
 		},
 	}
-	cmd.Flags().StringVar(&target, "actor", "", "actor id")
-	cmd.Flags().StringVar(&role, "role", "", "role id")
+	cmd.Flags().StringVar(&roleID, "id", "", "role id")
 	return cmd
 }
 
@@ -1350,6 +5026,101 @@ func rbacDenyAttCmd() *cobra.Command {
 	return cmd
 }
 
+func rbacApplyCmd() *cobra.Command {
+	var filePath string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconcile role grants with a team roster",
+		Long: `Makes the project's role grants match a YAML roster:
+
+  actors:
+    - id: alice
+      roles: [admin]
+    - id: bob
+      roles: [developer, reviewer]
+  attestation_authorities:
+    review.approved: [reviewer]
+
+Listed actors are registered and hold exactly their roles on the project; actors left out lose their project roles. When attestation_authorities is present, each kind is issued by exactly the roles listed; without it authorities are left alone. Org-level grants are untouched. Prints the grants added and removed; --dry-run only reports them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			roster, err := config.RosterFromFile(filePath)
+			if err != nil {
+				return err
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				res, err := e.ApplyRoster(ctx, e.Config.Project.ID, viper.GetString("actor-id"), roster, dryRun)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(res)
+				}
+				if len(res.Changes) == 0 {
+					infof("roster already applied, nothing to change\n")
+					return nil
+				}
+				rows := make([][]string, 0, len(res.Changes))
+				for _, c := range res.Changes {
+					rows = append(rows, []string{c.Op, c.Type, c.ActorID, c.RoleID, c.AttestationKind})
+				}
+				return renderRows("table", []string{"Op", "Type", "Actor", "Role", "Attestation Kind"}, rows)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&filePath, "file", "", "roster YAML file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report the changes without applying them")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func rbacSimulateCmd() *cobra.Command {
+	var target, action, kind string
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Explain whether an actor may perform an action",
+		Long:  `Runs the permission checks for an action without performing it and shows each one: the actor is active, a role grants the permission, a role may issue the attestation kind (attestation.add --kind) or is among the task type's done_roles (task.done --kind), and the project is not archived. Passing checks name the grants behind them; failing ones name the roles that would satisfy them. Explaining another actor needs rbac.manage.`,
+		Example: `  wl rbac simulate --actor bob --action task.done --kind feature
+  wl rbac simulate --action attestation.add --kind review.approved`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				sim, err := e.SimulatePermission(ctx, engine.PermissionSimulationOptions{
+					ProjectID:     e.Config.Project.ID,
+					ActorID:       viper.GetString("actor-id"),
+					TargetActorID: target,
+					Action:        action,
+					Kind:          kind,
+				})
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(sim)
+				}
+				verdict := "allowed"
+				if !sim.Allowed {
+					verdict = "denied"
+				}
+				infof("%s: %s %s\n", sim.ActorID, sim.Action, verdict)
+				rows := make([][]string, 0, len(sim.Checks))
+				for _, c := range sim.Checks {
+					result := "pass"
+					if !c.Passed {
+						result = "fail"
+					}
+					rows = append(rows, []string{c.Check, result, c.Detail})
+				}
+				return renderRows("table", []string{"Check", "Result", "Detail"}, rows)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&target, "actor", "", "actor to explain (defaults to you)")
+	cmd.Flags().StringVar(&action, "action", "", "permission id, e.g. task.done")
+	cmd.Flags().StringVar(&kind, "kind", "", "attestation kind for attestation.add, task type for task.done")
+	_ = cmd.MarkFlagRequired("action")
+	return cmd
+}
+
 func rbacBootstrapCmd() *cobra.Command {
 	var target, role string
 	cmd := &cobra.Command{
@@ -1378,23 +5149,21 @@ func rbacBootstrapCmd() *cobra.Command {
 						if err := r.InsertRole(ctx, tx, role, roleDef.Description); err != nil {
 							return err
 						}
-						perms := map[string]bool{}
+						perms := map[string]string{}
 						for _, grant := range roleDef.Grants {
 							set, ok := cfg.Project.RBAC.Permissions[grant]
 							if !ok {
 								return fmt.Errorf("unknown permission set %s for role %s", grant, role)
 							}
 							for _, perm := range set {
-								perms[perm] = true
+								perms[perm] = ""
 							}
 						}
-						for perm := range perms {
-							if err := r.InsertPermission(ctx, tx, perm, ""); err != nil {
-								return err
-							}
-							if err := r.AddRolePermission(ctx, tx, role, perm); err != nil {
-								return err
-							}
+						if err := r.InsertPermissions(ctx, tx, perms); err != nil {
+							return err
+						}
+						if err := r.AddRolePermissions(ctx, tx, role, slices.Sorted(maps.Keys(perms))); err != nil {
+							return err
 						}
 					} else {
 						if err := r.InsertRole(ctx, tx, role, ""); err != nil {
@@ -1421,54 +5190,309 @@ func rbacBootstrapCmd() *cobra.Command {
 	return cmd
 }
 
-func serveCmd() *cobra.Command {
-	var addr, basePath string
+func openapiCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "serve",
-		Short: "Start HTTP API server",
+		Use:   "openapi",
+		Short: "Work with the API description",
+	}
+	cmd.AddCommand(openapiExportCmd())
+	return cmd
+}
+
+func openapiExportCmd() *cobra.Command {
+	var out string
+	var basePath string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the OpenAPI spec for client generation",
+		Long:  "Writes the same spec wl serve publishes at <base-path>/openapi.json, without a servers section. No workspace is needed.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			workspace := viper.GetString("workspace")
-			if _, err := db.EnsureWorkspace(workspace); err != nil {
+			data, err := server.OpenAPISpec(basePath)
+			if err != nil {
 				return err
 			}
-			conn, err := db.Open(db.Config{Workspace: workspace})
-			if err != nil {
+			if out == "" || out == "-" {
+				_, err = os.Stdout.Write(data)
 				return err
 			}
-			defer conn.Close()
-			if err := migrate.Migrate(conn); err != nil {
+			if err := os.WriteFile(out, data, 0o644); err != nil {
 				return err
 			}
-			r := repo.Repo{DB: conn}
-			_, cfg, err := app.ResolveProjectAndConfig(cmd.Context(), workspace, viper.GetString("project"), viper.GetString("actor-id"), r)
+			if structuredOutput() {
+				return printStructured(map[string]string{"spec": out})
+			}
+			infof("OpenAPI spec written to %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "output file (default stdout)")
+	cmd.Flags().StringVar(&basePath, "base-path", "/v0", "API base path the spec's routes are mounted under")
+	return cmd
+}
+
+func serveCmd() *cobra.Command {
+	var addr, grpcAddr, basePath, backupDir string
+	var backupInterval time.Duration
+	var attestationSweep, staleSweep, slaSweep, escalationSweep, roleExpirySweep, hookRetry time.Duration
+	var digestCheck time.Duration
+	var backupKeep int
+	var readOnly, noUI, noOutboxRelay, ephemeral bool
+	var publicURL, fixture string
+	var cors server.CORSConfig
+	var tlsOpts server.TLSOptions
+	var drainTimeout time.Duration
+	var certActor string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start HTTP API server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fixture != "" && !ephemeral {
+				return fmt.Errorf("--fixture needs --ephemeral")
+			}
+			var e engine.Engine
+			var err error
+			if ephemeral {
+				var fx app.Fixture
+				if fixture != "" {
+					if fx, err = app.LoadFixture(fixture); err != nil {
+						return err
+					}
+				}
+				if e, err = app.OpenEphemeral(cmd.Context(), fx, viper.GetString("actor-id")); err != nil {
+					return err
+				}
+			} else {
+				workspace := viper.GetString("workspace")
+				if _, err := db.EnsureWorkspace(workspace); err != nil {
+					return err
+				}
+				conn, err := openDB(workspace)
+				if err != nil {
+					return err
+				}
+				_, cfg, err := app.ResolveProjectAndConfig(cmd.Context(), workspace, viper.GetString("project"), viper.GetString("actor-id"), repo.Repo{DB: conn})
+				if err != nil {
+					conn.Close()
+					return err
+				}
+				e = engine.New(conn, cfg)
+				if e.Blobs, err = blob.Open(cfg.Evidence, workspace); err != nil {
+					conn.Close()
+					return err
+				}
+			}
+			defer e.DB.Close()
+			cfg := e.Config
+			e.ReadOnly = readOnly
+			e.Perms = engine.NewPermissionCache()
+			closeCluster, err := attachCluster(&e)
 			if err != nil {
 				return err
 			}
-			e := engine.New(conn, cfg)
+			defer closeCluster()
 			authCfg := server.AuthConfig{JWTSecret: os.Getenv("WORKLINE_JWT_SECRET")}
 			if authCfg.JWTSecret == "" {
 				return fmt.Errorf("WORKLINE_JWT_SECRET is required for bearer auth")
 			}
-			handler, err := server.New(server.Config{Engine: e, BasePath: basePath, Auth: authCfg})
+			var tlsCfg *tls.Config
+			if tlsOpts.Enabled() {
+				if tlsCfg, err = server.NewTLSConfig(tlsOpts); err != nil {
+					return err
+				}
+			} else if tlsOpts.ClientCAFile != "" {
+				return fmt.Errorf("--tls-client-ca needs --tls-cert and --tls-key")
+			}
+			if tlsOpts.ClientCAFile != "" {
+				if !server.ValidCertActorField(certActor) {
+					return fmt.Errorf("invalid --tls-client-actor %s (cn, email, dns or uri)", certActor)
+				}
+				authCfg.ClientCertActor = certActor
+			}
+			backup := server.BackupConfig{Dir: backupDir, Interval: backupInterval, Keep: backupKeep}
+			if backup.Dir != "" && backup.Interval <= 0 {
+				return fmt.Errorf("--backup-interval must be positive when --backup-dir is set")
+			}
+			leases := server.NewLeaseTracker()
+			handler, err := server.New(server.Config{Engine: e, BasePath: basePath, Auth: authCfg, Backup: backup, CORS: cors, PublicURL: publicURL, Leases: leases, AttestationSweep: attestationSweep, StaleSweep: staleSweep, SLASweep: slaSweep, EscalationSweep: escalationSweep, RoleExpirySweep: roleExpirySweep, HookRetry: hookRetry, DigestCheck: digestCheck, DisableUI: noUI})
 			if err != nil {
 				return err
 			}
-			srv := &http.Server{Addr: addr, Handler: handler}
+			srv := &http.Server{Addr: addr, Handler: handler, TLSConfig: tlsCfg}
+			var grpcSrv *server.GRPCServer
+			var grpcErr chan error
+			if grpcAddr != "" {
+				lis, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					return err
+				}
+				var opts []grpc.ServerOption
+				if tlsCfg != nil {
+					opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+				}
+				grpcSrv = server.NewGRPC(server.Config{Engine: e, Auth: authCfg}, opts...)
+				grpcErr = make(chan error, 1)
+				go func() { grpcErr <- grpcSrv.Serve(lis) }()
+			}
+			sigCtx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if cfg.Outbox.Enabled() && !noOutboxRelay {
+				pub, err := outbox.Open(cfg.Outbox)
+				if err != nil {
+					return err
+				}
+				relayDone := make(chan struct{})
+				go func() {
+					defer close(relayDone)
+					outbox.Relay{Repo: e.Repo, Publisher: pub}.Run(sigCtx, outbox.DefaultInterval)
+				}()
+				defer func() {
+					stop()
+					<-relayDone
+					pub.Close()
+				}()
+			}
+			drained := make(chan struct{})
 			go func() {
-				<-cmd.Context().Done()
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer close(drained)
+				<-sigCtx.Done()
+				stop()
+				infof("Shutting down; draining in-flight requests for up to %s\n", drainTimeout)
+				ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 				defer cancel()
-				srv.Shutdown(ctx)
+				grpcDrained := make(chan struct{})
+				go func() {
+					defer close(grpcDrained)
+					if grpcSrv == nil {
+						return
+					}
+					if err := grpcSrv.Shutdown(ctx); err != nil {
+						warnf("shutdown grpc: %v; closed remaining connections", err)
+					}
+				}()
+				if err := srv.Shutdown(ctx); err != nil {
+					// Drain timed out: cancel what is left, then release any
+					// lease a cut-off handler claimed internally.
+					srv.Close()
+					warnf("shutdown: %v; closed remaining connections", err)
+				}
+				<-grpcDrained
+				releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancelRelease()
+				if err := leases.ReleaseAll(releaseCtx, e); err != nil {
+					warnf("shutdown: release leases: %v", err)
+				}
 			}()
-			fmt.Printf("Serving Workline API on http://%s%s (OpenAPI at /openapi.json, Swagger UI at /docs)\n", addr, basePath)
-			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			if readOnly {
+				infof("Read-only mode: mutating requests are rejected\n")
+			}
+			if ephemeral {
+				infof("Ephemeral mode: project %s lives in memory and is discarded on exit\n", cfg.Project.ID)
+			}
+			root := "http://" + addr
+			if tlsCfg != nil {
+				root = "https://" + addr
+			}
+			if publicURL != "" {
+				root = strings.TrimRight(publicURL, "/")
+			}
+			infof("Serving Workline API on %s%s (OpenAPI at /openapi.json, Swagger UI at /docs)\n", root, basePath)
+			if !noUI {
+				infof("Dashboard at %s/ui/\n", root)
+			}
+			if grpcSrv != nil {
+				infof("Serving gRPC API on %s\n", grpcAddr)
+			}
+			if e.Locks != nil {
+				infof("Sharing leases and caches with other replicas through Redis\n")
+			}
+			if cfg.Outbox.Enabled() && !noOutboxRelay {
+				infof("Relaying outbox to %s\n", cfg.Outbox.Broker)
+			}
+			if tlsCfg != nil {
+				err = srv.ListenAndServeTLS("", "")
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
 				return err
 			}
+			<-drained
+			if grpcSrv != nil {
+				if err := <-grpcErr; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					return err
+				}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().DurationVar(&drainTimeout, "shutdown-timeout", 30*time.Second, "how long to wait for in-flight requests on SIGINT/SIGTERM before closing connections")
 	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "listen address")
+	cmd.Flags().StringVar(&grpcAddr, "grpc-addr", "", "also serve the gRPC API on this address (disabled when empty)")
 	cmd.Flags().StringVar(&basePath, "base-path", "/v0", "API base path")
+	cmd.Flags().StringVar(&publicURL, "public-url", "", "external root URL behind a proxy, advertised in OpenAPI servers (e.g. https://example.com/workline)")
+	cmd.Flags().StringSliceVar(&cors.AllowedOrigins, "cors-origin", nil, "allowed CORS origin (repeatable, * for any)")
+	cmd.Flags().StringSliceVar(&cors.AllowedMethods, "cors-method", nil, "allowed CORS method (repeatable, default GET,POST,PUT,PATCH,DELETE)")
+	cmd.Flags().StringSliceVar(&cors.AllowedHeaders, "cors-header", nil, "allowed CORS request header (repeatable, default Authorization,Content-Type,X-Api-Key)")
+	cmd.Flags().IntVar(&cors.MaxAge, "cors-max-age", 600, "seconds browsers may cache CORS preflight responses")
+	cmd.Flags().StringVar(&tlsOpts.CertFile, "tls-cert", "", "TLS certificate file (PEM); serves HTTPS with --tls-key")
+	cmd.Flags().StringVar(&tlsOpts.KeyFile, "tls-key", "", "TLS private key file (PEM)")
+	cmd.Flags().StringVar(&tlsOpts.ClientCAFile, "tls-client-ca", "", "CA bundle (PEM) for verifying client certificates; verified certificates authenticate as an actor")
+	cmd.Flags().BoolVar(&tlsOpts.RequireClientCert, "tls-require-client-cert", false, "reject connections without a valid client certificate")
+	cmd.Flags().StringVar(&certActor, "tls-client-actor", server.CertActorCN, "client certificate field naming the actor: cn, email, dns or uri")
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "reject every mutating request (403 read_only_mode)")
+	cmd.Flags().BoolVar(&noUI, "no-ui", false, "do not serve the web dashboard at /ui")
+	cmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "run on an in-memory database and evidence store instead of the workspace; nothing is written to disk")
+	cmd.Flags().StringVar(&fixture, "fixture", "", "YAML fixture seeding the --ephemeral project (org, project, config, actors, iterations, tasks)")
+	cmd.Flags().BoolVar(&noOutboxRelay, "no-outbox-relay", false, "do not publish outbox events (when wl outbox relay runs elsewhere)")
+	cmd.Flags().StringVar(&backupDir, "backup-dir", "", "directory for periodic database backups (disabled when empty)")
+	cmd.Flags().DurationVar(&attestationSweep, "attestation-sweep-interval", time.Hour, "how often to expire attestations past their kind's valid_days (0 disables)")
+	cmd.Flags().DurationVar(&staleSweep, "stale-sweep-interval", time.Hour, "how often to mark tasks idle for project.staleness.days as stale (0 disables)")
+	cmd.Flags().DurationVar(&slaSweep, "sla-sweep-interval", 5*time.Minute, "how often to report open tasks past their due date with sla.breached (0 disables)")
+	cmd.Flags().DurationVar(&escalationSweep, "escalation-sweep-interval", time.Hour, "how often to raise task priorities under project.escalation (0 disables)")
+	cmd.Flags().DurationVar(&roleExpirySweep, "role-expiry-sweep-interval", 5*time.Minute, "how often to delete role grants past their expires_at (0 disables)")
+	cmd.Flags().DurationVar(&hookRetry, "hook-retry-interval", time.Minute, "how often to post again webhook hook deliveries that failed (0 disables)")
+	cmd.Flags().DurationVar(&digestCheck, "digest-check-interval", 15*time.Minute, "how often to send the scheduled email digest when it is due (0 disables)")
+	cmd.Flags().DurationVar(&backupInterval, "backup-interval", 24*time.Hour, "time between periodic backups")
+	cmd.Flags().IntVar(&backupKeep, "backup-keep", 7, "number of periodic backups to keep (0 keeps all)")
+	return cmd
+}
+
+func logReadsCmd() *cobra.Command {
+	var f repo.ReadAuditFilters
+	var format string
+	cmd := &cobra.Command{
+		Use:   "reads",
+		Short: "List reads recorded under project.read_audit",
+		Long:  "Shows who read the project's config, events and attestations through the API, newest first, when project.read_audit is on. Needs events.audit.read. --format csv with --limit 0 exports the whole trail.",
+		Example: `  wl log reads --actor bob --since 2026-01-01
+  wl log reads --resource attestations --limit 0 --format csv > reads.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutputFormat(format); err != nil {
+				return err
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				f.ProjectID = e.Config.Project.ID
+				entries, err := e.ReadAudit(ctx, viper.GetString("actor-id"), f)
+				if err != nil {
+					return err
+				}
+				if structuredOutput() {
+					return printStructured(entries)
+				}
+				rows := make([][]string, 0, len(entries))
+				for _, entry := range entries {
+					rows = append(rows, []string{strconv.FormatInt(entry.ID, 10), entry.TS, entry.ActorID, entry.Resource, entry.Target})
+				}
+				return renderRows(format, []string{"ID", "TS", "Actor", "Resource", "Target"}, rows)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&f.ActorID, "actor", "", "only reads by this actor")
+	cmd.Flags().StringVar(&f.Resource, "resource", "", "only reads of config, events or attestations")
+	cmd.Flags().StringVar(&f.Since, "since", "", "only reads at or after this time, RFC3339 or YYYY-MM-DD")
+	cmd.Flags().StringVar(&f.Until, "until", "", "only reads before this time, RFC3339 or YYYY-MM-DD")
+	cmd.Flags().IntVar(&f.Limit, "limit", 50, "number of reads (0 for all)")
+	cmd.Flags().StringVar(&format, "format", "table", "output format: table, csv or md")
 	return cmd
 }
 
@@ -1499,50 +5523,165 @@ func logTailCmd() *cobra.Command {
 
 func withEngine(ctx context.Context, fn func(context.Context, engine.Engine) error) error {
 	workspace := viper.GetString("workspace")
-	conn, err := db.Open(db.Config{Workspace: workspace})
+	conn, err := openDB(workspace)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
-	if err := migrate.Migrate(conn); err != nil {
-		return err
-	}
 	r := repo.Repo{DB: conn}
 	_, cfg, err := app.ResolveProjectAndConfig(ctx, workspace, viper.GetString("project"), viper.GetString("actor-id"), r)
 	if err != nil {
 		return err
 	}
+	setDisplayLocation(cfg)
 	e := engine.New(conn, cfg)
+	if e.Blobs, err = blob.Open(cfg.Evidence, workspace); err != nil {
+		return err
+	}
+	closeCluster, err := attachCluster(&e)
+	if err != nil {
+		return err
+	}
+	defer closeCluster()
 	return fn(ctx, e)
 }
 
+// attachCluster shares leases and caches through the Redis named by
+// WORKLINE_REDIS_URL, if any, so the CLI and server replicas agree.
+func attachCluster(e *engine.Engine) (func(), error) {
+	r, err := cluster.FromEnv()
+	if err != nil || r == nil {
+		return func() {}, err
+	}
+	e.Locks = r
+	if r.CacheEnabled() {
+		e.Cache = r
+	}
+	return func() { r.Close() }, nil
+}
+
 func withRepo(ctx context.Context, fn func(context.Context, repo.Repo) error) error {
-	workspace := viper.GetString("workspace")
-	conn, err := db.Open(db.Config{Workspace: workspace})
+	conn, err := openDB(viper.GetString("workspace"))
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
-	if err := migrate.Migrate(conn); err != nil {
-		return err
-	}
 	r := repo.Repo{DB: conn}
 	return fn(ctx, r)
 }
 
-func printJSONOrTable(v any) error {
-	if viper.GetBool("json") {
-		return printJSON(v)
+func withDB(fn func(*sql.DB) error) error {
+	conn, err := db.Open(db.Config{Workspace: viper.GetString("workspace"), Trace: sqlTrace()})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return fn(conn)
+}
+
+// openDB opens the workspace database and makes sure its schema matches this
+// binary. A fresh database is migrated; an existing one is only upgraded with
+// --auto-migrate, so a schema pinned by `wl db migrate --to` stays put.
+func openDB(workspace string) (*sql.DB, error) {
+	conn, err := db.Open(db.Config{Workspace: workspace, Trace: sqlTrace()})
+	if err != nil {
+		return nil, err
+	}
+	current, err := migrate.CurrentVersion(conn)
+	if err == nil {
+		if current == 0 || viper.GetBool("auto-migrate") {
+			err = migrate.Migrate(conn)
+		} else {
+			err = migrate.Check(conn)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+const outputFormatUsage = "output format: table, csv or md (ignored with --json or --output)"
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case "table", "csv", "md":
+		return nil
+	}
+	return fmt.Errorf("invalid --format %q: expected table, csv or md", format)
+}
+
+// renderRows prints rows as an aligned table, RFC 4180 CSV or Markdown,
+// keeping only the --columns given, if any; with --quiet only their ids.
+func renderRows(format string, header []string, rows [][]string) error {
+	if quiet() {
+		// Only ids, or the first column of tables without one.
+		col := max(slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(h, "id") }), 0)
+		for _, row := range rows {
+			if col < len(row) {
+				fmt.Println(row[col])
+			}
+		}
+		return nil
+	}
+	if cols := outputColumns(); len(cols) > 0 {
+		var keep []int
+		for _, c := range cols {
+			for i, h := range header {
+				if strings.EqualFold(strings.ReplaceAll(h, " ", "_"), c) {
+					keep = append(keep, i)
+					break
+				}
+			}
+		}
+		pick := func(cells []string) []string {
+			out := make([]string, len(keep))
+			for j, i := range keep {
+				if i < len(cells) {
+					out[j] = cells[i]
+				}
+			}
+			return out
+		}
+		picked := make([][]string, len(rows))
+		for r, row := range rows {
+			picked[r] = pick(row)
+		}
+		header, rows = pick(header), picked
+	}
+	return renderTable(format, header, rows)
+}
+
+// renderTable prints rows as an aligned table, RFC 4180 CSV or Markdown.
+func renderTable(format string, header []string, rows [][]string) error {
+	if format == "csv" {
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		return w.WriteAll(rows)
+	}
+	tw := table.NewWriter()
+	tw.SetOutputMirror(os.Stdout)
+	tw.AppendHeader(tableRow(header))
+	for _, row := range rows {
+		tw.AppendRow(tableRow(localTimes(row)))
+	}
+	if format == "md" {
+		tw.RenderMarkdown()
+	} else {
+		tw.Render()
 	}
-	b, _ := json.MarshalIndent(v, "", "  ")
-	fmt.Println(string(b))
 	return nil
 }
 
-func printJSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+func tableRow(cells []string) table.Row {
+	row := make(table.Row, 0, len(cells))
+	for _, c := range cells {
+		row = append(row, c)
+	}
+	return row
 }
 
 func toJSONArray(items []string) string {
@@ -1598,12 +5737,32 @@ func printTaskTree(t domain.Task, children map[string][]domain.Task, prefix stri
 		connector = "└── "
 		newPrefix = prefix + "    "
 	}
-	fmt.Printf("%s%s%s [%s]\n", prefix, connector, t.Title, t.Status)
+	due := ""
+	if day, overdue := taskDue(t, time.Now()); overdue {
+		due = " (overdue, due " + day + ")"
+	} else if day != "" {
+		due = " (due " + day + ")"
+	}
+	fmt.Printf("%s%s%s [%s]%s\n", prefix, connector, t.Title, t.Status, due)
 	for i, c := range children[t.ID] {
 		printTaskTree(c, children, newPrefix, i == len(children[t.ID])-1)
 	}
 }
 
+// taskDue returns the day a task is due, empty when it has no due date, and
+// whether it is still open past it at now.
+func taskDue(t domain.Task, now time.Time) (string, bool) {
+	if t.DueAt == nil {
+		return "", false
+	}
+	due, err := time.Parse(time.RFC3339, *t.DueAt)
+	if err != nil {
+		return *t.DueAt, false
+	}
+	open := t.CompletedAt == nil && t.Status != "canceled" && t.Status != "rejected"
+	return due.UTC().Format(time.DateOnly), open && due.Before(now)
+}
+
 func optionalString(s string) *string {
 	if s == "" {
 		return nil
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"workline/internal/config"
+)
+
+const outputUsage = "output format: table, json, yaml or go-template=TEMPLATE (fields by their JSON names, e.g. '{{range .}}{{.id}}{{\"\\n\"}}{{end}}')"
+
+// outputFormat returns the --output format, json with --json, and the
+// template of go-template=TEMPLATE.
+func outputFormat() (format, tmpl string) {
+	if viper.GetBool("json") {
+		return "json", ""
+	}
+	out := strings.TrimSpace(viper.GetString("output"))
+	if t, ok := strings.CutPrefix(out, "go-template="); ok {
+		return "go-template", t
+	}
+	if out == "" {
+		return "table", ""
+	}
+	return out, ""
+}
+
+func checkOutput() error {
+	switch format, tmpl := outputFormat(); format {
+	case "table", "json", "yaml":
+		return nil
+	case "go-template":
+		_, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid --output go-template: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: expected table, json, yaml or go-template=TEMPLATE", format)
+	}
+}
+
+// structuredOutput reports whether --output asks for json, yaml or a
+// template rather than the command's table.
+func structuredOutput() bool {
+	format, _ := outputFormat()
+	return format != "table"
+}
+
+// outputColumns returns the --columns selection, lowercased.
+func outputColumns() []string {
+	var cols []string
+	for _, c := range strings.Split(viper.GetString("columns"), ",") {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// printStructured prints v as JSON, YAML or through the --output template.
+// YAML and templates see v as its JSON form, so fields go by their JSON
+// names.
+func printStructured(v any) error {
+	format, tmpl := outputFormat()
+	return printAs(format, tmpl, v)
+}
+
+func printAs(format, tmpl string, v any) error {
+	switch format {
+	case "yaml", "go-template":
+		generic, err := toGeneric(v)
+		if err != nil {
+			return err
+		}
+		if format == "yaml" {
+			enc := yaml.NewEncoder(os.Stdout)
+			enc.SetIndent(2)
+			if err := enc.Encode(generic); err != nil {
+				return err
+			}
+			return enc.Close()
+		}
+		t, err := template.New("output").Option("missingkey=zero").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid --output go-template: %w", err)
+		}
+		return t.Execute(os.Stdout, generic)
+	default:
+		return printJSON(v)
+	}
+}
+
+// printJSONOrTable prints v in the --output format. As a table, a list of
+// objects gets a row per item and a column per field, and a single object
+// a row per field; --columns picks the fields.
+func printJSONOrTable(v any) error {
+	if structuredOutput() {
+		return printStructured(v)
+	}
+	if printIDs(v) {
+		return nil
+	}
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	cols := outputColumns()
+	switch val := generic.(type) {
+	case []any:
+		if len(val) == 0 {
+			fmt.Println("(none)")
+			return nil
+		}
+		if _, ok := val[0].(map[string]any); !ok {
+			for _, item := range val {
+				fmt.Println(cell(item))
+			}
+			return nil
+		}
+		header, rows := listRows(v, val)
+		return renderTable("table", header, rows)
+	case map[string]any:
+		fields := jsonFields(reflect.TypeOf(v), []any{val})
+		if len(cols) > 0 {
+			fields = selectColumns(fields, cols)
+		}
+		var rows [][]string
+		for _, f := range fields {
+			if c := cell(val[f]); c != "" || len(cols) > 0 {
+				rows = append(rows, []string{f, c})
+			}
+		}
+		return renderTable("table", []string{"FIELD", "VALUE"}, rows)
+	default:
+		fmt.Println(cell(val))
+		return nil
+	}
+}
+
+// renderList prints the list v with a column per field, as renderRows
+// does, for commands whose own table lacks a field picked by --columns.
+func renderList(format string, v any) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	items, _ := generic.([]any)
+	header, rows := listRows(v, items)
+	return renderTable(format, header, rows)
+}
+
+// listRows lays out items, the JSON form of the list v, as table rows: a
+// column per field, only the --columns ones if given.
+func listRows(v any, items []any) ([]string, [][]string) {
+	cols := outputColumns()
+	fields := jsonFields(reflect.TypeOf(v), items)
+	if len(cols) > 0 {
+		fields = selectColumns(fields, cols)
+	} else {
+		fields = slices.DeleteFunc(fields, func(f string) bool {
+			// Nested values and fields empty on every row would only
+			// widen the table.
+			for _, item := range items {
+				switch x := item.(map[string]any)[f].(type) {
+				case map[string]any, []any:
+					return true
+				case nil:
+				default:
+					if cell(x) != "" {
+						return false
+					}
+				}
+			}
+			return true
+		})
+	}
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		m, _ := item.(map[string]any)
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = cell(m[f])
+		}
+		rows = append(rows, row)
+	}
+	return headerOf(fields), rows
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// toGeneric turns v into the maps, slices and scalars of its JSON form.
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// jsonFields lists the fields of t, when it is a struct (or a slice or
+// pointer of one), in declaration order, then the other fields present in
+// items, sorted.
+func jsonFields(t reflect.Type, items []any) []string {
+	present := map[string]bool{}
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			for k := range m {
+				present[k] = true
+			}
+		}
+	}
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	var fields []string
+	if t != nil && t.Kind() == reflect.Struct {
+		for _, name := range structFields(t) {
+			if !slices.Contains(fields, name) {
+				fields = append(fields, name)
+				delete(present, name)
+			}
+		}
+	}
+	rest := make([]string, 0, len(present))
+	for name := range present {
+		rest = append(rest, name)
+	}
+	slices.Sort(rest)
+	return append(fields, rest...)
+}
+
+// structFields returns the JSON names of t's fields, embedded structs
+// flattened, in declaration order.
+func structFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				names = append(names, structFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// selectColumns keeps the requested fields, in the requested order,
+// matching names without regard to case.
+func selectColumns(fields, cols []string) []string {
+	var out []string
+	for _, c := range cols {
+		for _, f := range fields {
+			if strings.EqualFold(f, c) {
+				out = append(out, f)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func headerOf(fields []string) []string {
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = strings.ToUpper(f)
+	}
+	return header
+}
+
+// cell formats one table value: scalars as is, nested values as compact
+// JSON.
+func cell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// displayLocation is where --local shows timestamps: the project timezone
+// once a command has loaded a config that sets one, else the system's.
+var displayLocation = time.Local
+
+func setDisplayLocation(cfg *config.Config) {
+	if cfg != nil && cfg.Project.Calendar.Timezone != "" {
+		displayLocation = cfg.Project.Calendar.Location()
+	}
+}
+
+// localTimes rewrites the RFC3339 cells of a table row in displayLocation
+// when --local is set. Stored and structured output stays UTC.
+func localTimes(row []string) []string {
+	if !viper.GetBool("local") {
+		return row
+	}
+	out := make([]string, len(row))
+	for i, c := range row {
+		out[i] = c
+		if t, err := time.Parse(time.RFC3339, c); err == nil {
+			out[i] = t.In(displayLocation).Format("2006-01-02 15:04 MST")
+		}
+	}
+	return out
+}
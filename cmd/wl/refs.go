@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+)
+
+// refMatchLimit caps the candidates offered for an ambiguous reference.
+const refMatchLimit = 20
+
+// resolveRef turns what the user typed for a task, iteration or decision
+// into its id: the id itself, a unique id prefix, or a unique title
+// substring within the current project. Several matches are offered as a
+// list to pick from in a terminal, and are an error otherwise. A reference
+// matching nothing is returned as is, so the command reports it not found.
+func resolveRef(ctx context.Context, e engine.Engine, kind, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || e.Config == nil {
+		return ref, nil
+	}
+	refs, err := e.Repo.FindEntityRefs(ctx, kind, e.Config.Project.ID, ref, refMatchLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(refs) == 0 {
+		return ref, nil
+	}
+	// Exact ids win, then id prefixes, then titles.
+	best := refs[0].Match
+	var matches []domain.EntityRef
+	for _, r := range refs {
+		if r.Match == best {
+			matches = append(matches, r)
+		}
+	}
+	if len(matches) == 1 || best == "id" {
+		return matches[0].ID, nil
+	}
+	if !isTerminal(os.Stdin) {
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID
+		}
+		return "", fmt.Errorf("%q matches %d %ss: %s; use a longer prefix or the full id", ref, len(matches), kind, strings.Join(ids, ", "))
+	}
+	return pickRef(bufio.NewReader(os.Stdin), kind, ref, matches)
+}
+
+// pickRef asks which of several matches the user meant. The list goes to
+// stderr so JSON output stays clean.
+func pickRef(in *bufio.Reader, kind, ref string, matches []domain.EntityRef) (string, error) {
+	fmt.Fprintf(os.Stderr, "%q matches several %ss:\n", ref, kind)
+	for i, m := range matches {
+		fmt.Fprintf(os.Stderr, "  %d) %s  %s\n", i+1, m.ID, m.Title)
+	}
+	fmt.Fprintf(os.Stderr, "Pick 1-%d: ", len(matches))
+	line, _ := in.ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(matches) {
+		return "", fmt.Errorf("no %s picked for %q", kind, ref)
+	}
+	return matches[n-1].ID, nil
+}
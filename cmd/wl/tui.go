@@ -0,0 +1,583 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/repo"
+)
+
+const (
+	tuiFeedSize = 8
+	// tuiMinColumnWidth is the narrowest a status column gets before the
+	// board scrolls sideways instead.
+	tuiMinColumnWidth = 16
+
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiReverse = "\x1b[7m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+)
+
+const tuiHelp = "←→/hl column  ↑↓/jk task  c claim  x release  ]/[ next/prev status  d done  r refresh  q quit"
+
+func tuiCmd() *cobra.Command {
+	var leaseSeconds int
+	var refresh time.Duration
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Interactive task board in the terminal",
+		Long:  "Shows the project's tasks in one column per workflow status, with attestation badges and a live event feed. Tasks are claimed, released and moved through the same engine calls as the task commands, so policies and leases apply unchanged.\n\nKeys: " + tuiHelp,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+			if !term.IsTerminal(in) || !term.IsTerminal(out) {
+				return errors.New("wl tui needs an interactive terminal")
+			}
+			if refresh <= 0 {
+				return errors.New("invalid --refresh: must be positive")
+			}
+			return withEngine(cmd.Context(), func(ctx context.Context, e engine.Engine) error {
+				b := &tuiBoard{
+					e:            e,
+					projectID:    e.Config.Project.ID,
+					actorID:      viper.GetString("actor-id"),
+					leaseSeconds: leaseSeconds,
+				}
+				if err := b.load(ctx); err != nil {
+					return err
+				}
+				return runTUI(ctx, b, in, out, refresh)
+			})
+		},
+	}
+	cmd.Flags().IntVar(&leaseSeconds, "lease-seconds", 900, "lease duration seconds when claiming")
+	cmd.Flags().DurationVar(&refresh, "refresh", 2*time.Second, "how often to poll for new events")
+	return cmd
+}
+
+// tuiBoard is the state behind wl tui: the engine's board, what is selected,
+// and the latest events. Every engine call happens on the goroutine
+// running the UI loop, since the database allows a single connection.
+type tuiBoard struct {
+	e            engine.Engine
+	projectID    string
+	actorID      string
+	leaseSeconds int
+
+	columns []domain.BoardColumn
+	// present holds the unexpired attestation kinds per task.
+	present map[string]map[string]bool
+	leases  map[string]domain.Lease
+	events  []domain.Event
+	lastID  int64
+
+	col, row int
+	message  string
+	failed   bool
+}
+
+// load re-reads the board, keeping the selected task selected even when it
+// moved to another column.
+func (b *tuiBoard) load(ctx context.Context) error {
+	selected := b.selected()
+	board, err := b.e.Board(ctx, b.projectID, b.actorID)
+	if err != nil {
+		return err
+	}
+	atts, err := b.e.Repo.ListAttestations(ctx, repo.AttestationFilters{ProjectID: b.projectID, EntityKind: "task"})
+	if err != nil {
+		return err
+	}
+	leases, err := b.e.Repo.ListLeases(ctx, b.projectID)
+	if err != nil {
+		return err
+	}
+	evts, err := b.e.Repo.LatestEvents(ctx, tuiFeedSize, b.projectID, "", "", "")
+	if err != nil {
+		return err
+	}
+	lastID, err := b.e.Repo.LatestEventID(ctx, b.projectID)
+	if err != nil {
+		return err
+	}
+
+	b.columns = board.Columns
+	b.present = map[string]map[string]bool{}
+	for _, a := range atts {
+		if a.ExpiredAt != nil {
+			continue
+		}
+		if b.present[a.EntityID] == nil {
+			b.present[a.EntityID] = map[string]bool{}
+		}
+		b.present[a.EntityID][a.Kind] = true
+	}
+	now := time.Now().UTC()
+	b.leases = map[string]domain.Lease{}
+	for _, l := range leases {
+		if exp, err := time.Parse(time.RFC3339, l.ExpiresAt); err == nil && exp.After(now) {
+			b.leases[l.TaskID] = l
+		}
+	}
+	b.events = evts
+	b.lastID = lastID
+
+	if selected != nil {
+		for c, col := range b.columns {
+			for r, t := range col.Tasks {
+				if t.ID == selected.ID {
+					b.col, b.row = c, r
+					return nil
+				}
+			}
+		}
+	}
+	b.clamp()
+	return nil
+}
+
+// poll reloads the board when new events arrived since the last load.
+func (b *tuiBoard) poll(ctx context.Context) (bool, error) {
+	id, err := b.e.Repo.LatestEventID(ctx, b.projectID)
+	if err != nil || id == b.lastID {
+		return false, err
+	}
+	return true, b.load(ctx)
+}
+
+func (b *tuiBoard) selected() *domain.Task {
+	if b.col < 0 || b.col >= len(b.columns) {
+		return nil
+	}
+	ts := b.columns[b.col].Tasks
+	if b.row < 0 || b.row >= len(ts) {
+		return nil
+	}
+	return &ts[b.row]
+}
+
+func (b *tuiBoard) clamp() {
+	b.col = max(0, min(b.col, len(b.columns)-1))
+	if len(b.columns) == 0 {
+		b.row = 0
+		return
+	}
+	b.row = max(0, min(b.row, len(b.columns[b.col].Tasks)-1))
+}
+
+// handleKey applies one key press and reports whether the UI should exit.
+func (b *tuiBoard) handleKey(ctx context.Context, key string) (bool, error) {
+	switch key {
+	case "q", "ctrl+c", "esc":
+		return true, nil
+	case "left", "h":
+		b.col--
+	case "right", "l":
+		b.col++
+	case "up", "k":
+		b.row--
+	case "down", "j":
+		b.row++
+	case "r":
+		b.notify("refreshed", nil)
+		return false, b.load(ctx)
+	case "c", "x", "]", "[", "d":
+		t := b.selected()
+		if t == nil {
+			b.notify("", errors.New("no task selected"))
+			return false, nil
+		}
+		b.notify(b.act(ctx, *t, key))
+		return false, b.load(ctx)
+	}
+	b.clamp()
+	return false, nil
+}
+
+// act runs the engine call bound to key on t.
+func (b *tuiBoard) act(ctx context.Context, t domain.Task, key string) (string, error) {
+	switch key {
+	case "c":
+		l, err := b.e.ClaimLease(ctx, t.ID, b.actorID, b.leaseSeconds)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("claimed %s until %s", t.ID, tuiClock(l.ExpiresAt)), nil
+	case "x":
+		if err := b.e.ReleaseLease(ctx, t.ID, b.actorID); err != nil {
+			return "", err
+		}
+		return "released " + t.ID, nil
+	}
+	wf := b.e.Config.TaskWorkflow(t.Type)
+	status := wf.Done
+	switch key {
+	case "]":
+		next, ok := tuiStep(wf.States, t.Status, 1)
+		if !ok {
+			return "", fmt.Errorf("%s has no next status", t.ID)
+		}
+		status = next
+	case "[":
+		prev, ok := tuiStep(wf.States, t.Status, -1)
+		if !ok {
+			return "", fmt.Errorf("%s has no previous status", t.ID)
+		}
+		status = prev
+	}
+	if _, err := b.e.UpdateTask(ctx, engine.TaskUpdateOptions{ID: t.ID, Status: status, ActorID: b.actorID}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s → %s", t.ID, status), nil
+}
+
+// tuiStep returns the state step places away from status in states.
+func tuiStep(states []string, status string, step int) (string, bool) {
+	for i, s := range states {
+		if s == status {
+			if j := i + step; j >= 0 && j < len(states) {
+				return states[j], true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func (b *tuiBoard) notify(msg string, err error) {
+	b.failed = err != nil
+	if err != nil {
+		msg = err.Error()
+	}
+	b.message = msg
+}
+
+// required returns the attestation kinds t must collect.
+func (b *tuiBoard) required(t domain.Task) []string {
+	var kinds []string
+	if t.RequiredAttestationsJSON != nil {
+		_ = json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &kinds)
+	}
+	return kinds
+}
+
+// badge renders "present/required" for tasks with an attestation policy.
+func (b *tuiBoard) badge(t domain.Task) (string, string) {
+	req := b.required(t)
+	if len(req) == 0 {
+		return "", ""
+	}
+	have := 0
+	for _, k := range req {
+		if b.present[t.ID][k] {
+			have++
+		}
+	}
+	color := ansiYellow
+	if have == len(req) {
+		color = ansiGreen
+	}
+	return fmt.Sprintf("%d/%d", have, len(req)), color
+}
+
+// render draws the whole screen as width-wide lines.
+func (b *tuiBoard) render(width, height int) []string {
+	lines := []string{
+		ansiBold + tuiFit(fmt.Sprintf("workline · %s · %s", b.projectID, b.actorID), width) + ansiReset,
+		ansiDim + tuiFit(tuiHelp, width) + ansiReset,
+	}
+	feed := min(tuiFeedSize, max(1, height/4))
+	boardHeight := height - len(lines) - 3 - (feed + 1) - 1
+	lines = append(lines, b.renderColumns(width, max(2, boardHeight))...)
+	lines = append(lines, b.renderDetail(width)...)
+	lines = append(lines, ansiBold+tuiFit("Events", width)+ansiReset)
+	for i := 0; i < feed; i++ {
+		line := ""
+		if i < len(b.events) {
+			ev := b.events[i]
+			line = fmt.Sprintf("%s  %-22s %s/%s by %s", tuiClock(ev.TS), ev.Type, ev.EntityKind, ev.EntityID, ev.ActorID)
+		}
+		lines = append(lines, tuiFit(line, width))
+	}
+	status := tuiFit(b.message, width)
+	if b.failed {
+		status = ansiRed + status + ansiReset
+	}
+	lines = append(lines, status)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+func (b *tuiBoard) renderColumns(width, height int) []string {
+	lines := make([]string, height)
+	if len(b.columns) == 0 {
+		lines[0] = tuiFit("no workflow states configured", width)
+		return lines
+	}
+	visible := max(1, min(len(b.columns), (width+1)/(tuiMinColumnWidth+1)))
+	first := 0
+	if b.col >= visible {
+		first = b.col - visible + 1
+	}
+	colWidth := (width - (visible - 1)) / visible
+	for c := first; c < first+visible && c < len(b.columns); c++ {
+		col := b.columns[c]
+		ts := col.Tasks
+		count := fmt.Sprint(col.Count)
+		if col.WIPLimit != nil {
+			count += fmt.Sprintf("/%d", *col.WIPLimit)
+		}
+		header := tuiFit(fmt.Sprintf("%s (%s)", col.Status, count), colWidth)
+		switch {
+		case col.OverLimit:
+			header = ansiRed + header + ansiReset
+		case c == b.col:
+			header = ansiBold + header + ansiReset
+		default:
+			header = ansiDim + header + ansiReset
+		}
+		cells := []string{header}
+		offset := 0
+		if c == b.col && b.row >= height-1 {
+			offset = b.row - (height - 2)
+		}
+		for r := offset; r < len(ts) && len(cells) < height; r++ {
+			cells = append(cells, b.renderCard(ts[r], colWidth, c == b.col && r == b.row))
+		}
+		for r := range lines {
+			cell := strings.Repeat(" ", colWidth)
+			if r < len(cells) {
+				cell = cells[r]
+			}
+			if c > first {
+				lines[r] += " "
+			}
+			lines[r] += cell
+		}
+	}
+	return lines
+}
+
+// renderCard draws one task as a single line: a lease marker, the title (red
+// when overdue) and the attestation badge on the right.
+func (b *tuiBoard) renderCard(t domain.Task, width int, selected bool) string {
+	marker := "  "
+	if l, ok := b.leases[t.ID]; ok {
+		marker = "+ "
+		if l.OwnerID == b.actorID {
+			marker = "* "
+		}
+	}
+	badge, color := b.badge(t)
+	textWidth := width
+	if badge != "" {
+		textWidth -= runewidth.StringWidth(badge) + 1
+	}
+	text := tuiFit(marker+t.Title, max(0, textWidth))
+	if selected {
+		text = ansiReverse + text + ansiReset
+	} else if _, overdue := taskDue(t, time.Now()); overdue {
+		text = ansiRed + text + ansiReset
+	}
+	if badge == "" {
+		return text
+	}
+	return text + " " + color + badge + ansiReset
+}
+
+// renderDetail describes the selected task on three lines.
+func (b *tuiBoard) renderDetail(width int) []string {
+	t := b.selected()
+	if t == nil {
+		return []string{strings.Repeat("─", width), "", ""}
+	}
+	info := []string{t.ID, t.Type, t.Status}
+	if t.Component != "" {
+		info = append(info, "component "+t.Component)
+	}
+	if t.AssigneeID != nil {
+		info = append(info, "assignee "+*t.AssigneeID)
+	}
+	if day, overdue := taskDue(*t, time.Now()); overdue {
+		info = append(info, "overdue since "+day)
+	} else if day != "" {
+		info = append(info, "due "+day)
+	}
+	if l, ok := b.leases[t.ID]; ok {
+		info = append(info, fmt.Sprintf("leased by %s until %s", l.OwnerID, tuiClock(l.ExpiresAt)))
+	}
+	return []string{
+		strings.Repeat("─", width),
+		tuiFit(strings.Join(info, " · "), width),
+		b.renderAttestations(*t, width),
+	}
+}
+
+// renderAttestations lists the task's required kinds with a check or a cross,
+// dropping the kinds that do not fit in width.
+func (b *tuiBoard) renderAttestations(t domain.Task, width int) string {
+	req := b.required(t)
+	line := "attestations:"
+	if len(req) == 0 {
+		return tuiFit(line+" none required", width)
+	}
+	used := runewidth.StringWidth(line)
+	for _, k := range req {
+		part, color := " ✗ "+k, ansiYellow
+		if b.present[t.ID][k] {
+			part, color = " ✓ "+k, ansiGreen
+		}
+		if used+runewidth.StringWidth(part)+2 > width {
+			return line + " …"
+		}
+		used += runewidth.StringWidth(part)
+		line += color + part + ansiReset
+	}
+	return line
+}
+
+// tuiFit truncates or pads s to exactly width columns.
+func tuiFit(s string, width int) string {
+	return runewidth.FillRight(runewidth.Truncate(s, width, "…"), width)
+}
+
+// tuiClock shows an RFC3339 timestamp as local wall-clock time.
+func tuiClock(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.Local().Format("15:04:05")
+}
+
+// tuiKeys splits raw terminal input into key names: arrows, "esc",
+// "ctrl+c", or the character typed.
+func tuiKeys(buf []byte) []string {
+	var keys []string
+	for len(buf) > 0 {
+		if buf[0] == 0x1b {
+			if len(buf) >= 3 && buf[1] == '[' {
+				if k, ok := map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}[buf[2]]; ok {
+					keys = append(keys, k)
+				}
+				buf = buf[3:]
+				continue
+			}
+			keys = append(keys, "esc")
+			buf = buf[1:]
+			continue
+		}
+		if buf[0] == 0x03 {
+			keys = append(keys, "ctrl+c")
+			buf = buf[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(buf)
+		keys = append(keys, string(r))
+		buf = buf[size:]
+	}
+	return keys
+}
+
+// runTUI puts the terminal in raw mode on the alternate screen and runs the
+// board until the user quits.
+func runTUI(ctx context.Context, b *tuiBoard, in, out int, refresh time.Duration) error {
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(in, state)
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan []string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- tuiKeys(buf[:n])
+		}
+	}()
+	// Resizes are picked up by polling the size, which works the same on
+	// every platform.
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+	poll := time.NewTicker(refresh)
+	defer poll.Stop()
+
+	width, height := 0, 0
+	draw := func() {
+		w, h, err := term.GetSize(out)
+		if err != nil || w <= 0 || h <= 0 {
+			w, h = 80, 24
+		}
+		width, height = w, h
+		var sb strings.Builder
+		sb.WriteString("\x1b[H")
+		for i, line := range b.render(width, height) {
+			if i > 0 {
+				sb.WriteString("\r\n")
+			}
+			sb.WriteString(line)
+			sb.WriteString("\x1b[K")
+		}
+		sb.WriteString("\x1b[J")
+		fmt.Fprint(os.Stdout, sb.String())
+	}
+	draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ks, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range ks {
+				quit, err := b.handleKey(ctx, k)
+				if err != nil {
+					return err
+				}
+				if quit {
+					return nil
+				}
+			}
+			draw()
+		case <-poll.C:
+			changed, err := b.poll(ctx)
+			if err != nil {
+				return err
+			}
+			if changed {
+				draw()
+			}
+		case <-resize.C:
+			if w, h, err := term.GetSize(out); err == nil && (w != width || h != height) {
+				draw()
+			}
+		}
+	}
+}
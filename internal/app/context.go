@@ -23,6 +23,11 @@ func ResolveProjectAndConfig(ctx context.Context, workspace, projectOverride, ac
 	if projectID == "" {
 		return "", nil, fmt.Errorf("project not specified; use --project or set WORKLINE_DEFAULT_PROJECT (wl project use <id>)")
 	}
+	// The former id of a renamed project resolves to it rather than
+	// creating a new project.
+	if id, err := r.ResolveProjectAlias(ctx, projectID); err == nil {
+		projectID = id
+	}
 	seedCfg := config.Default(projectID)
 
 	if _, err := r.GetProject(ctx, projectID); err != nil {
@@ -49,7 +54,11 @@ func ResolveProjectAndConfig(ctx context.Context, workspace, projectOverride, ac
 		}
 	}
 	cfg.Project.ID = projectID
-	return projectID, cfg, nil
+	resolved, err := cfg.ResolveSecrets()
+	if err != nil {
+		return "", nil, fmt.Errorf("project config secrets: %w", err)
+	}
+	return projectID, resolved, nil
 }
 
 // createProject inserts a minimal project/org/rbac footprint using the seed config.
@@ -66,7 +75,7 @@ func createProject(ctx context.Context, r repo.Repo, projectID, orgID string, se
 	p := domain.Project{
 		ID:          projectID,
 		OrgID:       orgID,
-		Kind:        "software-project",
+		Kind:        config.DefaultKind,
 		Status:      "active",
 		Description: "",
 		CreatedAt:   now,
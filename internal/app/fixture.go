@@ -0,0 +1,323 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"workline/internal/blob"
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/repo"
+)
+
+// Fixture is the data an ephemeral server starts with. Every field is
+// optional: an empty fixture gives project "demo" in org "demo" with the
+// default kind's config, owned by the serving actor.
+type Fixture struct {
+	Org     string `yaml:"org"`
+	Project string `yaml:"project"`
+	Kind    string `yaml:"kind"`
+	// Config is an inline workline.yml; its project.id is replaced by Project.
+	Config     yaml.Node          `yaml:"config"`
+	Actors     []FixtureActor     `yaml:"actors"`
+	Iterations []FixtureIteration `yaml:"iterations"`
+	Tasks      []FixtureTask      `yaml:"tasks"`
+	// Attestations are recorded after the tasks, so they can refer to them.
+	Attestations []FixtureAttestation `yaml:"attestations"`
+}
+
+type FixtureActor struct {
+	ID    string   `yaml:"id"`
+	Roles []string `yaml:"roles"`
+	// APIKey is stored as given, so scripts and agents can use a known key.
+	APIKey string `yaml:"api_key"`
+}
+
+type FixtureIteration struct {
+	ID       string   `yaml:"id"`
+	Goal     string   `yaml:"goal"`
+	Status   string   `yaml:"status"`
+	Capacity *float64 `yaml:"capacity"`
+}
+
+type FixtureTask struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Type        string   `yaml:"type"`
+	Description string   `yaml:"description"`
+	Iteration   string   `yaml:"iteration"`
+	Assignee    string   `yaml:"assignee"`
+	Priority    *int     `yaml:"priority"`
+	DependsOn   []string `yaml:"depends_on"`
+	// Status is forced after creation, skipping policies.
+	Status string `yaml:"status"`
+}
+
+type FixtureAttestation struct {
+	ID         string `yaml:"id"`
+	EntityKind string `yaml:"entity_kind"`
+	EntityID   string `yaml:"entity_id"`
+	Kind       string `yaml:"kind"`
+	// Actor attests; it defaults to the seeding actor and needs the kind's
+	// authority like any other attester.
+	Actor   string `yaml:"actor"`
+	TS      string `yaml:"ts"`
+	Payload any    `yaml:"payload"`
+}
+
+// Seed is a `wl seed` file: several projects, each in fixture form.
+type Seed struct {
+	Projects []Fixture `yaml:"projects"`
+}
+
+// SeedSummary counts what `wl seed` created in one project.
+type SeedSummary struct {
+	Project      string `json:"project"`
+	Grants       int    `json:"grants"`
+	Iterations   int    `json:"iterations"`
+	Tasks        int    `json:"tasks"`
+	Attestations int    `json:"attestations"`
+}
+
+// LoadFixture reads a fixture file, rejecting unknown keys.
+func LoadFixture(path string) (Fixture, error) {
+	var fx Fixture
+	return fx, decodeStrict(path, "fixture", &fx)
+}
+
+// LoadSeed reads a `wl seed` file, rejecting unknown keys.
+func LoadSeed(path string) (Seed, error) {
+	var s Seed
+	if err := decodeStrict(path, "seed file", &s); err != nil {
+		return s, err
+	}
+	if len(s.Projects) == 0 {
+		return s, fmt.Errorf("invalid seed file %s: no projects", path)
+	}
+	for i, fx := range s.Projects {
+		if fx.Project == "" || fx.Org == "" {
+			return s, fmt.Errorf("invalid seed file %s: projects[%d] needs project and org", path, i)
+		}
+	}
+	return s, nil
+}
+
+func decodeStrict(path, what string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid %s %s: %w", what, path, err)
+	}
+	return nil
+}
+
+// OpenEphemeral builds an engine on a fresh in-memory database and evidence
+// store, seeded from fx by actorID, who owns the project. Closing e.DB
+// discards everything.
+func OpenEphemeral(ctx context.Context, fx Fixture, actorID string) (engine.Engine, error) {
+	if fx.Project == "" {
+		fx.Project = "demo"
+	}
+	if fx.Org == "" {
+		fx.Org = "demo"
+	}
+	cfg, err := fixtureConfig(fx)
+	if err != nil {
+		return engine.Engine{}, err
+	}
+	r, err := repo.NewMemorySQLite()
+	if err != nil {
+		return engine.Engine{}, err
+	}
+	e := engine.New(r.DB, cfg)
+	e.Blobs = blob.NewMemory()
+	if _, err := seedFixture(ctx, e, fx, actorID, map[string]bool{}); err != nil {
+		r.DB.Close()
+		return engine.Engine{}, err
+	}
+	if e.Config, err = cfg.ResolveSecrets(); err != nil {
+		r.DB.Close()
+		return engine.Engine{}, fmt.Errorf("fixture config secrets: %w", err)
+	}
+	return e, nil
+}
+
+// SeedWorkspace creates every project of s in conn, owned by actorID. IDs the
+// file leaves out are derived from the project and position, so seeding the
+// same file into a fresh workspace always gives the same IDs. Projects must
+// not exist yet.
+func SeedWorkspace(ctx context.Context, conn *sql.DB, s Seed, actorID string) ([]SeedSummary, error) {
+	r := repo.Repo{DB: conn}
+	for _, fx := range s.Projects {
+		if _, err := r.GetProject(ctx, fx.Project); err == nil {
+			return nil, fmt.Errorf("project %s already exists; seed into a fresh workspace", fx.Project)
+		} else if !errors.Is(err, repo.ErrNotFound) {
+			return nil, err
+		}
+	}
+	keyed := map[string]bool{}
+	out := make([]SeedSummary, 0, len(s.Projects))
+	for _, fx := range s.Projects {
+		cfg, err := fixtureConfig(fx)
+		if err != nil {
+			return out, fmt.Errorf("project %s: %w", fx.Project, err)
+		}
+		summary, err := seedFixture(ctx, engine.New(conn, cfg), fx, actorID, keyed)
+		if err != nil {
+			return out, fmt.Errorf("project %s: %w", fx.Project, err)
+		}
+		out = append(out, summary)
+	}
+	return out, nil
+}
+
+func fixtureConfig(fx Fixture) (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if fx.Config.Kind != 0 {
+		data, merr := yaml.Marshal(&fx.Config)
+		if merr != nil {
+			return nil, merr
+		}
+		cfg, err = config.FromYAML(data)
+	} else {
+		cfg, err = config.ForKind(fx.Kind, fx.Project)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixture config: %w", err)
+	}
+	cfg.Project.ID = fx.Project
+	return cfg, nil
+}
+
+// stableID names a fixture entity the file gave no ID.
+func stableID(project, kind string, i int) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("workline:"+project+"/"+kind+"/"+strconv.Itoa(i))).String()
+}
+
+// seedFixture creates fx's project and contents; keyed tracks actors whose
+// API key is already stored, as an actor may appear in several projects.
+func seedFixture(ctx context.Context, e engine.Engine, fx Fixture, actorID string, keyed map[string]bool) (SeedSummary, error) {
+	summary := SeedSummary{Project: fx.Project}
+	if _, err := e.InitProject(ctx, fx.Project, fx.Org, fx.Kind, "", actorID); err != nil {
+		return summary, err
+	}
+	if err := e.Repo.UpsertProjectConfig(ctx, fx.Project, e.Config); err != nil {
+		return summary, err
+	}
+	for _, a := range fx.Actors {
+		for _, role := range a.Roles {
+			if err := e.GrantRole(ctx, fx.Project, actorID, a.ID, role); err != nil {
+				return summary, fmt.Errorf("fixture actor %s: %w", a.ID, err)
+			}
+			summary.Grants++
+		}
+		if a.APIKey != "" && !keyed[a.ID] {
+			if err := insertFixtureKey(ctx, e, a); err != nil {
+				return summary, fmt.Errorf("fixture actor %s: %w", a.ID, err)
+			}
+			keyed[a.ID] = true
+		}
+	}
+	for i, it := range fx.Iterations {
+		if it.ID == "" {
+			it.ID = stableID(fx.Project, "iteration", i)
+		}
+		if _, err := e.CreateIteration(ctx, domain.Iteration{ID: it.ID, ProjectID: fx.Project, Goal: it.Goal, Capacity: it.Capacity}, actorID); err != nil {
+			return summary, fmt.Errorf("fixture iteration %s: %w", it.ID, err)
+		}
+		if it.Status != "" && it.Status != "pending" {
+			if _, err := e.SetIterationStatus(ctx, it.ID, it.Status, actorID, true); err != nil {
+				return summary, fmt.Errorf("fixture iteration %s: %w", it.ID, err)
+			}
+		}
+		summary.Iterations++
+	}
+	for i, t := range fx.Tasks {
+		if t.ID == "" {
+			t.ID = stableID(fx.Project, "task", i)
+		}
+		task, err := e.CreateTask(ctx, engine.TaskCreateOptions{
+			ID:          t.ID,
+			ProjectID:   fx.Project,
+			IterationID: t.Iteration,
+			Type:        t.Type,
+			Title:       t.Title,
+			Description: t.Description,
+			DependsOn:   t.DependsOn,
+			AssigneeID:  t.Assignee,
+			Priority:    t.Priority,
+			ActorID:     actorID,
+		})
+		if err != nil {
+			return summary, fmt.Errorf("fixture task %q: %w", t.Title, err)
+		}
+		if t.Status != "" && t.Status != task.Status {
+			if _, err := e.UpdateTask(ctx, engine.TaskUpdateOptions{ID: task.ID, Status: t.Status, ActorID: actorID, Force: true}); err != nil {
+				return summary, fmt.Errorf("fixture task %q: %w", t.Title, err)
+			}
+		}
+		summary.Tasks++
+	}
+	for i, a := range fx.Attestations {
+		att := domain.Attestation{
+			ID:         a.ID,
+			ProjectID:  fx.Project,
+			EntityKind: a.EntityKind,
+			EntityID:   a.EntityID,
+			Kind:       a.Kind,
+			ActorID:    a.Actor,
+			TS:         a.TS,
+		}
+		if att.ID == "" {
+			att.ID = stableID(fx.Project, "attestation", i)
+		}
+		if att.ActorID == "" {
+			att.ActorID = actorID
+		}
+		if a.Payload != nil {
+			b, err := json.Marshal(a.Payload)
+			if err != nil {
+				return summary, fmt.Errorf("fixture attestation %s: %w", att.ID, err)
+			}
+			att.PayloadJSON = string(b)
+		}
+		if _, err := e.AddAttestation(ctx, att, att.ActorID); err != nil {
+			return summary, fmt.Errorf("fixture attestation %s on %s: %w", att.Kind, att.EntityID, err)
+		}
+		summary.Attestations++
+	}
+	return summary, nil
+}
+
+func insertFixtureKey(ctx context.Context, e engine.Engine, a FixtureActor) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.Repo.EnsureActor(ctx, tx, a.ID, now); err != nil {
+		return err
+	}
+	key := domain.APIKey{ID: stableID(a.ID, "api-key", 0), ActorID: a.ID, Name: "fixture", KeyHash: repo.HashAPIKey(a.APIKey), CreatedAt: now}
+	if err := e.Repo.InsertAPIKey(ctx, tx, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/repo"
+)
+
+// GitTaskTrailer is the commit message trailer naming the tasks a commit
+// works on, one per trailer or comma-separated.
+const GitTaskTrailer = "WL-Task"
+
+// CodeCommittedKind is the attestation kind ScanGit adds with Attest.
+const CodeCommittedKind = "code.committed"
+
+// GitCommitsOutcome is the work outcomes key holding a task's commits.
+const GitCommitsOutcome = "commits"
+
+// GitCommit is a scanned commit and the tasks its trailers name.
+type GitCommit struct {
+	SHA         string   `json:"sha"`
+	Subject     string   `json:"subject"`
+	Author      string   `json:"author"`
+	CommittedAt string   `json:"committed_at"`
+	TaskIDs     []string `json:"task_ids,omitempty"`
+}
+
+type GitScanOptions struct {
+	ProjectID string
+	ActorID   string
+	// Repo is the path of the repository; bare repositories work too.
+	Repo string
+	// Since excludes it and its ancestors; empty or all zeros (a new branch
+	// in a post-receive hook) scans the whole history of Rev.
+	Since string
+	// Rev is the last commit scanned, HEAD when empty.
+	Rev string
+	// Attest adds a code.committed attestation for each newly attached
+	// commit.
+	Attest bool
+	DryRun bool
+}
+
+type GitScanReport struct {
+	DryRun   bool         `json:"dry_run"`
+	Scanned  int          `json:"scanned"`
+	Links    []GitTaskRef `json:"links"`
+	Warnings []string     `json:"warnings"`
+}
+
+// GitTaskRef reports one commit referencing one task. Status is "attached",
+// "already_attached" or "skipped" with the reason in the warnings.
+type GitTaskRef struct {
+	SHA      string `json:"sha"`
+	TaskID   string `json:"task_id"`
+	Status   string `json:"status"`
+	Attested bool   `json:"attested,omitempty"`
+}
+
+// ReadGitCommits lists the commits reachable from rev but not from since,
+// oldest first, with the task ids of their WL-Task trailers.
+func ReadGitCommits(ctx context.Context, repoPath, since, rev string) ([]GitCommit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	revRange := rev
+	if since != "" && strings.Trim(since, "0") != "" {
+		revRange = since + ".." + rev
+	}
+	return gitLog(ctx, repoPath, "--reverse", revRange)
+}
+
+// ReadGitCommit returns one commit with the task ids of its WL-Task
+// trailers.
+func ReadGitCommit(ctx context.Context, repoPath, rev string) (GitCommit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	commits, err := gitLog(ctx, repoPath, "-1", rev)
+	if err != nil {
+		return GitCommit{}, err
+	}
+	if len(commits) == 0 {
+		return GitCommit{}, fmt.Errorf("git log %s: no commit", rev)
+	}
+	return commits[0], nil
+}
+
+func gitLog(ctx context.Context, repoPath string, args ...string) ([]GitCommit, error) {
+	// Fields are split by the unit separator and commits by the record
+	// separator, which commit messages do not contain.
+	format := "%H%x1f%an%x1f%cI%x1f%s%x1f%(trailers:key=" + GitTaskTrailer + ",valueonly,separator=%x2c)%x1e"
+	cmd := exec.CommandContext(ctx, "git", append(append([]string{"-C", repoPath, "log", "--format=" + format}, args...), "--")...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git log %s: %s", args[len(args)-1], msg)
+		}
+		return nil, fmt.Errorf("git log %s: %w", args[len(args)-1], err)
+	}
+	var commits []GitCommit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		c := GitCommit{SHA: fields[0], Author: fields[1], CommittedAt: fields[2], Subject: fields[3]}
+		for _, id := range strings.Split(fields[4], ",") {
+			if id = strings.TrimSpace(id); id != "" && !slices.Contains(c.TaskIDs, id) {
+				c.TaskIDs = append(c.TaskIDs, id)
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// ScanGit attaches the commits whose WL-Task trailers name a project task to
+// that task's work outcomes, under "commits". Commits already attached are
+// left alone, so rescanning a range is harmless. Unknown tasks and tasks that
+// cannot be updated, such as ones leased by another actor, are reported as
+// warnings and skipped.
+func ScanGit(ctx context.Context, e engine.Engine, opts GitScanOptions) (GitScanReport, error) {
+	report := GitScanReport{DryRun: opts.DryRun, Links: []GitTaskRef{}, Warnings: []string{}}
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
+		return report, err
+	}
+	commits, err := ReadGitCommits(ctx, opts.Repo, opts.Since, opts.Rev)
+	if err != nil {
+		return report, err
+	}
+	report.Scanned = len(commits)
+	for _, c := range commits {
+		for _, taskID := range c.TaskIDs {
+			link := GitTaskRef{SHA: c.SHA, TaskID: taskID, Status: "skipped"}
+			if err := attachGitCommit(ctx, e, opts, c, &link); err != nil {
+				var warning string
+				if errors.Is(err, repo.ErrNotFound) {
+					warning = fmt.Sprintf("commit %s: task %s not found", shortSHA(c.SHA), taskID)
+				} else {
+					warning = fmt.Sprintf("commit %s: task %s: %v", shortSHA(c.SHA), taskID, err)
+				}
+				report.Warnings = append(report.Warnings, warning)
+			}
+			report.Links = append(report.Links, link)
+		}
+	}
+	return report, nil
+}
+
+func attachGitCommit(ctx context.Context, e engine.Engine, opts GitScanOptions, c GitCommit, link *GitTaskRef) error {
+	t, err := e.Repo.GetTask(ctx, link.TaskID)
+	if err != nil {
+		return err
+	}
+	if t.ProjectID != opts.ProjectID {
+		return repo.ErrNotFound
+	}
+	if gitCommitAttached(t, c.SHA) {
+		link.Status = "already_attached"
+		return nil
+	}
+	if opts.DryRun {
+		link.Status = "attached"
+		link.Attested = opts.Attest
+		return nil
+	}
+	entry := map[string]any{"sha": c.SHA, "subject": c.Subject, "author": c.Author, "committed_at": c.CommittedAt}
+	if _, err := e.UpdateWorkOutcomes(ctx, t.ID, opts.ActorID, func(outcomes map[string]any) error {
+		list, ok := outcomes[GitCommitsOutcome].([]any)
+		if !ok && outcomes[GitCommitsOutcome] != nil {
+			return fmt.Errorf("work outcomes %q is not a list", GitCommitsOutcome)
+		}
+		outcomes[GitCommitsOutcome] = append(list, entry)
+		return nil
+	}); err != nil {
+		return err
+	}
+	link.Status = "attached"
+	if !opts.Attest {
+		return nil
+	}
+	payload, err := json.Marshal(map[string]string{"sha": c.SHA, "subject": c.Subject})
+	if err != nil {
+		return err
+	}
+	if _, err := e.AddAttestation(ctx, domain.Attestation{
+		ProjectID:   t.ProjectID,
+		EntityKind:  "task",
+		EntityID:    t.ID,
+		Kind:        CodeCommittedKind,
+		PayloadJSON: string(payload),
+	}, opts.ActorID); err != nil {
+		return fmt.Errorf("attest: %w", err)
+	}
+	link.Attested = true
+	return nil
+}
+
+// gitCommitAttached reports whether the task's work outcomes already list
+// the commit.
+func gitCommitAttached(t domain.Task, sha string) bool {
+	if t.WorkOutcomesJSON == nil {
+		return false
+	}
+	var outcomes struct {
+		Commits []struct {
+			SHA string `json:"sha"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(*t.WorkOutcomesJSON), &outcomes); err != nil {
+		return false
+	}
+	for _, c := range outcomes.Commits {
+		if c.SHA == sha {
+			return true
+		}
+	}
+	return false
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
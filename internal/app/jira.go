@@ -0,0 +1,724 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/repo"
+)
+
+// JiraSource names Jira in external refs.
+const JiraSource = "jira"
+
+// DefaultJiraEpicField is the epic link field of company-managed Jira Cloud
+// projects; team-managed projects link epics through the parent field.
+const DefaultJiraEpicField = "customfield_10014"
+
+// JiraExport is the result of Jira's issue search API (/rest/api/2/search or
+// /rest/api/3/search), the shape `wl import jira --file` reads.
+type JiraExport struct {
+	Issues []JiraIssue `json:"issues"`
+}
+
+type JiraIssue struct {
+	ID     string     `json:"id"`
+	Key    string     `json:"key"`
+	Fields JiraFields `json:"fields"`
+}
+
+type JiraFields struct {
+	Summary string `json:"summary"`
+	// Description is a string in API v2 and an Atlassian document in v3.
+	Description json.RawMessage `json:"description"`
+	IssueType   struct {
+		Name    string `json:"name"`
+		Subtask bool   `json:"subtask"`
+	} `json:"issuetype"`
+	Status struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"status"`
+	Priority *struct {
+		Name string `json:"name"`
+	} `json:"priority"`
+	Assignee *struct {
+		AccountID    string `json:"accountId"`
+		Name         string `json:"name"`
+		EmailAddress string `json:"emailAddress"`
+	} `json:"assignee"`
+	Parent     *JiraIssueRef `json:"parent"`
+	IssueLinks []JiraLink    `json:"issuelinks"`
+	// Custom holds the customfield_* values, where sprints and epic links
+	// live.
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+func (f *JiraFields) UnmarshalJSON(data []byte) error {
+	type plain JiraFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	f.Custom = map[string]json.RawMessage{}
+	for name, v := range all {
+		if strings.HasPrefix(name, "customfield_") || name == "sprint" {
+			f.Custom[name] = v
+		}
+	}
+	return nil
+}
+
+type JiraIssueRef struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type JiraLink struct {
+	Type struct {
+		Name    string `json:"name"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	} `json:"type"`
+	InwardIssue  *JiraIssueRef `json:"inwardIssue"`
+	OutwardIssue *JiraIssueRef `json:"outwardIssue"`
+}
+
+type JiraSprint struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Goal  string `json:"goal"`
+}
+
+// JiraImportOptions tune a Jira import.
+type JiraImportOptions struct {
+	ProjectID string
+	ActorID   string
+	// EpicField is the custom field holding epic links; empty means
+	// DefaultJiraEpicField.
+	EpicField string
+	// TypeMap maps Jira issue type names to task types, on top of the
+	// built-in mapping.
+	TypeMap map[string]string
+	// DryRun reports what the import would do without writing anything.
+	DryRun bool
+}
+
+// JiraImportReport maps every imported Jira issue and sprint to the task or
+// iteration it became. Created, Updated and Unchanged count issues.
+type JiraImportReport struct {
+	DryRun       bool          `json:"dry_run,omitempty"`
+	Issues       []JiraMapping `json:"issues"`
+	Sprints      []JiraMapping `json:"sprints"`
+	Created      int           `json:"created"`
+	Updated      int           `json:"updated"`
+	Unchanged    int           `json:"unchanged"`
+	Dependencies int           `json:"dependencies_added"`
+	Warnings     []string      `json:"warnings"`
+}
+
+// JiraMapping is one Jira issue or sprint and what the import did with it:
+// created, updated or unchanged.
+type JiraMapping struct {
+	Key      string `json:"key"`
+	EntityID string `json:"entity_id"`
+	Type     string `json:"type,omitempty"`
+	Status   string `json:"status"`
+	Action   string `json:"action"`
+}
+
+// LoadJiraExport reads a saved search result; a bare array of issues works too.
+func LoadJiraExport(path string) (JiraExport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JiraExport{}, err
+	}
+	var export JiraExport
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &export.Issues)
+	} else {
+		err = json.Unmarshal(data, &export)
+	}
+	if err != nil {
+		return JiraExport{}, fmt.Errorf("invalid jira export %s: %w", path, err)
+	}
+	return export, nil
+}
+
+// FetchJira runs a JQL search on a Jira site and collects every page. With a
+// user, the token is an API token sent with basic auth (Jira Cloud);
+// otherwise it is a personal access token sent as a bearer token (Data
+// Center).
+func FetchJira(ctx context.Context, baseURL, jql, user, token string) (JiraExport, error) {
+	var export JiraExport
+	client := &http.Client{Timeout: time.Minute}
+	for {
+		q := url.Values{"jql": {jql}, "startAt": {strconv.Itoa(len(export.Issues))}, "maxResults": {"100"}, "fields": {"*all"}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/rest/api/2/search?"+q.Encode(), nil)
+		if err != nil {
+			return export, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if user != "" {
+			req.SetBasicAuth(user, token)
+		} else if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return export, err
+		}
+		var page struct {
+			Total  int         `json:"total"`
+			Issues []JiraIssue `json:"issues"`
+		}
+		if res.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+			res.Body.Close()
+			return export, fmt.Errorf("jira search: status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return export, fmt.Errorf("jira search: %w", err)
+		}
+		export.Issues = append(export.Issues, page.Issues...)
+		if len(page.Issues) == 0 || len(export.Issues) >= page.Total {
+			return export, nil
+		}
+	}
+}
+
+// ImportJira maps Jira issues to tasks, epics and parents to task parents,
+// sprints to iterations and "blocks" links to dependencies. Issues and
+// sprints are matched to earlier imports by key, so re-importing updates
+// status, parent, iteration, priority and assignee and adds new
+// dependencies instead of duplicating tasks. Titles and descriptions are set
+// only on creation. Statuses are forced, which needs force.use.
+func ImportJira(ctx context.Context, e engine.Engine, export JiraExport, opts JiraImportOptions) (JiraImportReport, error) {
+	report := JiraImportReport{DryRun: opts.DryRun, Issues: []JiraMapping{}, Sprints: []JiraMapping{}, Warnings: []string{}}
+	if e.Config == nil {
+		return report, errors.New("config not loaded")
+	}
+	if opts.EpicField == "" {
+		opts.EpicField = DefaultJiraEpicField
+	}
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
+		return report, err
+	}
+	refs, err := e.Repo.ListExternalRefs(ctx, opts.ProjectID, JiraSource)
+	if err != nil {
+		return report, err
+	}
+	im := &jiraImport{e: e, opts: opts, refs: refs, report: &report, warned: map[string]bool{}, linked: map[[2]string]bool{}}
+
+	iterations := map[int64]string{}
+	for _, s := range jiraSprints(export.Issues) {
+		id, err := im.sprint(ctx, s)
+		if err != nil {
+			return report, fmt.Errorf("jira sprint %s: %w", s.Name, err)
+		}
+		iterations[s.ID] = id
+	}
+	tasks := map[string]string{}
+	for key, ref := range refs {
+		if ref.EntityKind == "task" {
+			tasks[key] = ref.EntityID
+		}
+	}
+	for _, issue := range jiraParentsFirst(export.Issues, opts.EpicField) {
+		if issue.Key == "" {
+			return report, errors.New("invalid jira export: issue without key")
+		}
+		id, err := im.issue(ctx, issue, tasks, iterations)
+		if err != nil {
+			return report, fmt.Errorf("jira issue %s: %w", issue.Key, err)
+		}
+		tasks[issue.Key] = id
+	}
+	for _, issue := range export.Issues {
+		if err := im.dependencies(ctx, issue, tasks); err != nil {
+			return report, fmt.Errorf("jira issue %s: %w", issue.Key, err)
+		}
+	}
+	return report, nil
+}
+
+type jiraImport struct {
+	e      engine.Engine
+	opts   JiraImportOptions
+	refs   map[string]domain.ExternalRef
+	report *JiraImportReport
+	warned map[string]bool
+	linked map[[2]string]bool
+}
+
+func (im *jiraImport) warnOnce(key, format string, args ...any) {
+	if !im.warned[key] {
+		im.warned[key] = true
+		im.report.Warnings = append(im.report.Warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+func (im *jiraImport) count(action string) {
+	switch action {
+	case "created":
+		im.report.Created++
+	case "updated":
+		im.report.Updated++
+	default:
+		im.report.Unchanged++
+	}
+}
+
+// importedID names an entity created for a Jira issue or sprint, so a
+// dry run reports the id a real import would use.
+func (im *jiraImport) importedID(externalID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("workline:"+im.opts.ProjectID+"/jira/"+externalID)).String()
+}
+
+func (im *jiraImport) saveRef(ctx context.Context, externalID, kind, entityID string) error {
+	if im.opts.DryRun {
+		return nil
+	}
+	return im.e.Repo.UpsertExternalRef(ctx, domain.ExternalRef{
+		ProjectID:  im.opts.ProjectID,
+		Source:     JiraSource,
+		ExternalID: externalID,
+		EntityKind: kind,
+		EntityID:   entityID,
+		ImportedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (im *jiraImport) sprint(ctx context.Context, s JiraSprint) (string, error) {
+	externalID := "sprint:" + strconv.FormatInt(s.ID, 10)
+	status := jiraSprintStatus(s.State)
+	m := JiraMapping{Key: s.Name, Status: status, Action: "created"}
+	ref, known := im.refs[externalID]
+	var current domain.Iteration
+	if known {
+		it, err := im.e.Repo.GetIteration(ctx, ref.EntityID)
+		if err != nil {
+			return "", err
+		}
+		current, m.EntityID, m.Action = it, it.ID, "unchanged"
+	} else {
+		m.EntityID = im.importedID(externalID)
+		goal := s.Name
+		if s.Goal != "" {
+			goal += ": " + s.Goal
+		}
+		if !im.opts.DryRun {
+			it, err := im.e.CreateIteration(ctx, domain.Iteration{ID: m.EntityID, ProjectID: im.opts.ProjectID, Goal: goal}, im.opts.ActorID)
+			if err != nil {
+				return "", err
+			}
+			current = it
+		} else {
+			current.Status = "pending"
+		}
+	}
+	if current.Status != status {
+		if m.Action == "unchanged" {
+			m.Action = "updated"
+		}
+		if !im.opts.DryRun {
+			if _, err := im.e.SetIterationStatus(ctx, m.EntityID, status, im.opts.ActorID, true); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := im.saveRef(ctx, externalID, "iteration", m.EntityID); err != nil {
+		return "", err
+	}
+	im.report.Sprints = append(im.report.Sprints, m)
+	return m.EntityID, nil
+}
+
+func (im *jiraImport) issue(ctx context.Context, issue JiraIssue, tasks map[string]string, iterations map[int64]string) (string, error) {
+	f := issue.Fields
+	taskType := im.taskType(f.IssueType.Name)
+	status := jiraStatus(im.e.Config.TaskWorkflow(taskType), f.Status.Name, f.Status.StatusCategory.Key)
+	m := JiraMapping{Key: issue.Key, Type: taskType, Status: status}
+
+	var parentID, iterationID, assignee string
+	if parentKey := jiraParentKey(f, im.opts.EpicField); parentKey != "" {
+		if parentID = tasks[parentKey]; parentID == "" {
+			im.warnOnce("parent:"+issue.Key, "%s: parent %s is not imported; left without a parent", issue.Key, parentKey)
+		}
+	}
+	if s, ok := jiraCurrentSprint(f); ok {
+		iterationID = iterations[s.ID]
+	}
+	if f.Assignee != nil {
+		assignee = firstNonEmpty(f.Assignee.EmailAddress, f.Assignee.Name, f.Assignee.AccountID)
+	}
+	var priority *int
+	if f.Priority != nil {
+		priority = jiraPriority(f.Priority.Name)
+	}
+
+	ref, known := im.refs[issue.Key]
+	if !known {
+		m.EntityID, m.Action = im.importedID(issue.Key), "created"
+		if !im.opts.DryRun {
+			t, err := im.e.CreateTask(ctx, engine.TaskCreateOptions{
+				ID:          m.EntityID,
+				ProjectID:   im.opts.ProjectID,
+				IterationID: iterationID,
+				ParentID:    parentID,
+				Type:        taskType,
+				Title:       firstNonEmpty(f.Summary, issue.Key),
+				Description: jiraText(f.Description),
+				AssigneeID:  assignee,
+				Priority:    priority,
+				ActorID:     im.opts.ActorID,
+			})
+			if err != nil {
+				return "", err
+			}
+			if t.Status != status {
+				if _, err := im.e.UpdateTask(ctx, engine.TaskUpdateOptions{ID: t.ID, Status: status, ActorID: im.opts.ActorID, Force: true}); err != nil {
+					return "", err
+				}
+			}
+		}
+	} else {
+		t, err := im.e.Repo.GetTask(ctx, ref.EntityID)
+		if err != nil {
+			return "", err
+		}
+		m.EntityID = t.ID
+		if t.Title != f.Summary && f.Summary != "" {
+			im.warnOnce("title:"+issue.Key, "%s: title changed in Jira; titles are only set on creation", issue.Key)
+		}
+		upd := engine.TaskUpdateOptions{ID: t.ID, ActorID: im.opts.ActorID}
+		changed := false
+		if t.Status != status {
+			upd.Status, upd.Force, changed = status, true, true
+		}
+		if parentID != "" && derefString(t.ParentID) != parentID {
+			upd.SetParent, upd.ParentProvided, changed = &parentID, true, true
+		}
+		if iterationID != "" && derefString(t.IterationID) != iterationID {
+			upd.SetIteration, upd.IterationProvided, changed = &iterationID, true, true
+		}
+		if assignee != "" && derefString(t.AssigneeID) != assignee {
+			upd.Assign, upd.AssignProvided, changed = &assignee, true, true
+		}
+		if priority != nil && (t.Priority == nil || *t.Priority != *priority) {
+			upd.SetPriority, upd.PriorityProvided, changed = priority, true, true
+		}
+		m.Action = "unchanged"
+		if changed {
+			m.Action = "updated"
+			if !im.opts.DryRun {
+				if _, err := im.e.UpdateTask(ctx, upd); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	if err := im.saveRef(ctx, issue.Key, "task", m.EntityID); err != nil {
+		return "", err
+	}
+	im.count(m.Action)
+	im.report.Issues = append(im.report.Issues, m)
+	return m.EntityID, nil
+}
+
+// dependencies adds the issue's "blocks" links as dependencies: an issue
+// that is blocked by another depends on it.
+func (im *jiraImport) dependencies(ctx context.Context, issue JiraIssue, tasks map[string]string) error {
+	for _, l := range issue.Fields.IssueLinks {
+		if !jiraBlockingLink(l) {
+			im.warnOnce("link:"+l.Type.Name, "links of type %q are not imported", l.Type.Name)
+			continue
+		}
+		blocked, blocker := issue.Key, ""
+		switch {
+		case l.InwardIssue != nil:
+			blocker = l.InwardIssue.Key
+		case l.OutwardIssue != nil:
+			blocked, blocker = l.OutwardIssue.Key, issue.Key
+		}
+		taskID, depID := tasks[blocked], tasks[blocker]
+		if taskID == "" || depID == "" {
+			im.warnOnce("dep:"+blocked+">"+blocker, "%s blocked by %s: both issues must be imported to link them", blocked, blocker)
+			continue
+		}
+		// Jira lists a link on both issues.
+		pair := [2]string{taskID, depID}
+		if im.linked[pair] {
+			continue
+		}
+		im.linked[pair] = true
+		t, err := im.e.Repo.GetTask(ctx, taskID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return err
+		}
+		if slices.Contains(t.DependsOn, depID) {
+			continue
+		}
+		if !im.opts.DryRun {
+			if _, err := im.e.UpdateTask(ctx, engine.TaskUpdateOptions{ID: taskID, AddDeps: []string{depID}, ActorID: im.opts.ActorID}); err != nil {
+				return err
+			}
+		}
+		im.report.Dependencies++
+	}
+	return nil
+}
+
+var jiraTypes = map[string]string{
+	"bug":           "bug",
+	"story":         "feature",
+	"epic":          "feature",
+	"new feature":   "feature",
+	"improvement":   "feature",
+	"task":          "technical",
+	"sub-task":      "technical",
+	"subtask":       "technical",
+	"documentation": "docs",
+}
+
+func (im *jiraImport) taskType(name string) string {
+	allowed := im.e.Config.AllowedTaskTypes()
+	t, ok := im.opts.TypeMap[name]
+	if !ok {
+		t = jiraTypes[strings.ToLower(name)]
+	}
+	if allowed[t] {
+		return t
+	}
+	fallback := "technical"
+	if !allowed[fallback] {
+		types := make([]string, 0, len(allowed))
+		for tt := range allowed {
+			types = append(types, tt)
+		}
+		slices.Sort(types)
+		fallback = types[0]
+	}
+	im.warnOnce("type:"+name, "issue type %q has no task type; imported as %s (map it with --type-map)", name, fallback)
+	return fallback
+}
+
+// jiraStatus maps a Jira status to the task type's workflow by its category:
+// to do stays in the initial state, in progress becomes in_progress (review
+// for review statuses) and done becomes done, or canceled for statuses that
+// say so.
+func jiraStatus(wf config.WorkflowConfig, name, category string) string {
+	name = strings.ToLower(name)
+	has := func(s string) bool { return slices.Contains(wf.States, s) }
+	switch category {
+	case "done":
+		for _, word := range []string{"cancel", "won't", "wont", "reject", "duplicate"} {
+			if strings.Contains(name, word) && has("canceled") {
+				return "canceled"
+			}
+		}
+		return wf.Done
+	case "indeterminate":
+		if strings.Contains(name, "review") && has("review") {
+			return "review"
+		}
+		if has("in_progress") {
+			return "in_progress"
+		}
+	}
+	return wf.Initial
+}
+
+func jiraSprintStatus(state string) string {
+	switch strings.ToLower(state) {
+	case "active":
+		return "running"
+	case "closed":
+		return "delivered"
+	}
+	return "pending"
+}
+
+// jiraPriority maps Jira's default priorities to 1 (highest) to 5.
+func jiraPriority(name string) *int {
+	p, ok := map[string]int{"highest": 1, "blocker": 1, "high": 2, "critical": 2, "medium": 3, "major": 3, "low": 4, "minor": 4, "lowest": 5, "trivial": 5}[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+	return &p
+}
+
+func jiraBlockingLink(l JiraLink) bool {
+	return strings.EqualFold(l.Type.Name, "blocks") || strings.Contains(strings.ToLower(l.Type.Outward), "blocks")
+}
+
+func jiraParentKey(f JiraFields, epicField string) string {
+	if f.Parent != nil && f.Parent.Key != "" {
+		return f.Parent.Key
+	}
+	var key string
+	if raw, ok := f.Custom[epicField]; ok && json.Unmarshal(raw, &key) == nil {
+		return key
+	}
+	return ""
+}
+
+// jiraIssueSprints returns the sprints an issue was in. Sprints live in a
+// custom field whose id differs between sites, so every custom field holding
+// sprint objects is read.
+func jiraIssueSprints(f JiraFields) []JiraSprint {
+	var res []JiraSprint
+	for _, raw := range f.Custom {
+		var sprints []JiraSprint
+		if json.Unmarshal(raw, &sprints) != nil {
+			var one JiraSprint
+			if json.Unmarshal(raw, &one) != nil {
+				continue
+			}
+			sprints = []JiraSprint{one}
+		}
+		for _, s := range sprints {
+			if s.ID != 0 && s.Name != "" && s.State != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+// jiraCurrentSprint is the sprint an issue belongs to now: the active one,
+// else the latest it was carried into.
+func jiraCurrentSprint(f JiraFields) (JiraSprint, bool) {
+	sprints := jiraIssueSprints(f)
+	if len(sprints) == 0 {
+		return JiraSprint{}, false
+	}
+	for _, s := range sprints {
+		if strings.EqualFold(s.State, "active") {
+			return s, true
+		}
+	}
+	return slices.MaxFunc(sprints, func(a, b JiraSprint) int { return int(a.ID - b.ID) }), true
+}
+
+func jiraSprints(issues []JiraIssue) []JiraSprint {
+	seen := map[int64]bool{}
+	var res []JiraSprint
+	for _, issue := range issues {
+		for _, s := range jiraIssueSprints(issue.Fields) {
+			if !seen[s.ID] {
+				seen[s.ID] = true
+				res = append(res, s)
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b JiraSprint) int { return int(a.ID - b.ID) })
+	return res
+}
+
+// jiraParentsFirst orders issues so every parent comes before its children.
+func jiraParentsFirst(issues []JiraIssue, epicField string) []JiraIssue {
+	byKey := map[string]JiraIssue{}
+	for _, issue := range issues {
+		byKey[issue.Key] = issue
+	}
+	depth := map[string]int{}
+	var depthOf func(key string, seen map[string]bool) int
+	depthOf = func(key string, seen map[string]bool) int {
+		if d, ok := depth[key]; ok {
+			return d
+		}
+		issue, ok := byKey[key]
+		if !ok || seen[key] {
+			return -1
+		}
+		seen[key] = true
+		d := depthOf(jiraParentKey(issue.Fields, epicField), seen) + 1
+		depth[key] = d
+		return d
+	}
+	ordered := slices.Clone(issues)
+	for _, issue := range ordered {
+		depthOf(issue.Key, map[string]bool{})
+	}
+	slices.SortStableFunc(ordered, func(a, b JiraIssue) int { return depth[a.Key] - depth[b.Key] })
+	return ordered
+}
+
+// jiraText returns a description as plain text, flattening Atlassian
+// documents.
+func jiraText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var doc adfNode
+	if json.Unmarshal(raw, &doc) != nil {
+		return ""
+	}
+	var b strings.Builder
+	doc.write(&b)
+	return strings.TrimSpace(b.String())
+}
+
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+func (n adfNode) write(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+	case "hardBreak":
+		b.WriteString("\n")
+	case "listItem":
+		b.WriteString("- ")
+	}
+	for _, c := range n.Content {
+		c.write(b)
+	}
+	switch n.Type {
+	case "paragraph", "heading", "codeBlock", "blockquote":
+		b.WriteString("\n")
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"workline/internal/db"
+)
+
+// QueueKey returns the key signing the workspace's offline queue, kept in
+// .workline/queue.key and created on first use. It stays out of the
+// database, so a copy or edit of the queue there cannot be re-signed.
+func QueueKey(workspace string) ([]byte, error) {
+	dir, err := db.EnsureWorkspace(workspace)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "queue.key")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) < 32 {
+			return nil, fmt.Errorf("queue key %s is malformed", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return key, f.Close()
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"workline/internal/engine"
+	"workline/internal/repo"
+	worklinesdk "workline/sdk/go"
+)
+
+// syncLeaseSeconds is how long RemoteTasks holds a task's lease on the
+// server while pushing to it.
+const syncLeaseSeconds = 60
+
+// RemoteTasks is a Workline server a local project syncs with, through its
+// HTTP API. The client's project is the server's copy of the project.
+type RemoteTasks struct {
+	Client *worklinesdk.Client
+}
+
+var _ engine.SyncRemote = RemoteTasks{}
+
+// ChangedTasks reads the server's change feed after since to its end.
+func (r RemoteTasks) ChangedTasks(ctx context.Context, since int64) ([]string, bool, int64, error) {
+	var ids []string
+	bulk := false
+	seen := map[string]bool{}
+	for {
+		page, err := r.Client.Changes(ctx, since, 0, "task", "project")
+		if err != nil {
+			return nil, false, since, err
+		}
+		for _, c := range page.Changes {
+			switch {
+			case c.EntityKind == "task" && !seen[c.EntityID]:
+				seen[c.EntityID] = true
+				ids = append(ids, c.EntityID)
+			case c.EntityKind == "project" && slices.Contains(c.Types, "task.bulk_updated"):
+				bulk = true
+			}
+		}
+		if len(page.Changes) == 0 || page.Cursor <= since {
+			return ids, bulk, since, nil
+		}
+		since = page.Cursor
+	}
+}
+
+func (r RemoteTasks) GetTask(ctx context.Context, id string) (engine.SyncTask, error) {
+	t, err := r.Client.GetTask(ctx, id)
+	if err != nil {
+		return engine.SyncTask{}, remoteError(err)
+	}
+	return syncTask(t), nil
+}
+
+func (r RemoteTasks) CreateTask(ctx context.Context, t engine.SyncTask) (engine.SyncTask, error) {
+	created, err := r.Client.CreateTaskFrom(ctx, worklinesdk.TaskInput{
+		ID:           t.ID,
+		Type:         t.Type,
+		Title:        t.Title,
+		Description:  t.Description,
+		AssigneeID:   t.AssigneeID,
+		Priority:     t.Priority,
+		WorkOutcomes: t.WorkOutcomes,
+	})
+	if err != nil {
+		return engine.SyncTask{}, remoteError(err)
+	}
+	return syncTask(created), nil
+}
+
+// UpdateTask pushes fields under the task's lease, which status and work
+// outcome changes need.
+func (r RemoteTasks) UpdateTask(ctx context.Context, id string, fields []string, t engine.SyncTask) error {
+	body := map[string]any{}
+	for _, f := range fields {
+		switch f {
+		case "status":
+			body["status"] = t.Status
+		case "assignee_id":
+			// An empty id unassigns.
+			assignee := ""
+			if t.AssigneeID != nil {
+				assignee = *t.AssigneeID
+			}
+			body["assignee_id"] = assignee
+		case "priority":
+			body["priority"] = t.Priority
+		case "work_outcomes":
+			if len(t.WorkOutcomes) == 0 {
+				body["work_outcomes"] = nil
+			} else {
+				body["work_outcomes"] = t.WorkOutcomes
+			}
+		}
+	}
+	if err := r.Client.ClaimTask(ctx, id, syncLeaseSeconds); err != nil {
+		return remoteError(err)
+	}
+	_, err := r.Client.UpdateTask(ctx, id, body)
+	if releaseErr := r.Client.ReleaseTask(ctx, id); err == nil && releaseErr != nil {
+		return remoteError(releaseErr)
+	}
+	return remoteError(err)
+}
+
+func syncTask(t worklinesdk.Task) engine.SyncTask {
+	return engine.SyncTask{
+		ID:                   t.ID,
+		Type:                 t.Type,
+		Title:                t.Title,
+		Description:          t.Description,
+		Status:               t.Status,
+		AssigneeID:           t.AssigneeID,
+		Priority:             t.Priority,
+		WorkOutcomes:         t.WorkOutcomes,
+		RequiredAttestations: t.RequiredAttestations,
+	}
+}
+
+// remoteError maps the server's 404 to repo.ErrNotFound and its other
+// refusals of a request to engine.SyncRejectedError. A server that cannot
+// be reached, or answers that it is down, is engine.ErrRemoteUnreachable.
+// Authentication failures and other server errors stop the sync.
+func remoteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *worklinesdk.APIError
+	if !errors.As(err, &apiErr) {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("%w: %w", engine.ErrRemoteUnreachable, err)
+		}
+		return err
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return repo.ErrNotFound
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: %w", engine.ErrRemoteUnreachable, err)
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity:
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		reason := apiErr.Body
+		if json.Unmarshal([]byte(apiErr.Body), &body) == nil && body.Error.Message != "" {
+			reason = body.Error.Message
+		}
+		return engine.SyncRejectedError{Reason: reason}
+	}
+	return err
+}
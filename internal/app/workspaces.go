@@ -0,0 +1,145 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is a named workspace directory in the user registry.
+type Workspace struct {
+	Name string `yaml:"name" json:"name"`
+	Path string `yaml:"path" json:"path"`
+}
+
+// WorkspaceRegistry is the user-level list of known workspaces, stored in
+// ~/.config/workline/workspaces.yaml.
+type WorkspaceRegistry struct {
+	Default    string      `yaml:"default,omitempty" json:"default,omitempty"`
+	Workspaces []Workspace `yaml:"workspaces" json:"workspaces"`
+}
+
+// RegistryPath returns the registry file location. WORKLINE_REGISTRY
+// overrides the user config directory.
+func RegistryPath() (string, error) {
+	if path := os.Getenv("WORKLINE_REGISTRY"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workline", "workspaces.yaml"), nil
+}
+
+// LoadRegistry reads the registry; a missing file is an empty registry.
+func LoadRegistry(path string) (*WorkspaceRegistry, error) {
+	reg := &WorkspaceRegistry{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return reg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, reg); err != nil {
+		return nil, fmt.Errorf("invalid workspace registry %s: %w", path, err)
+	}
+	return reg, nil
+}
+
+// Save writes the registry, creating its directory if needed.
+func (r *WorkspaceRegistry) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Add registers a workspace under name, replacing the path of an existing entry.
+func (r *WorkspaceRegistry) Add(name, path string) (Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Workspace{}, errors.New("workspace name is required")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Workspace{}, err
+	}
+	ws := Workspace{Name: name, Path: abs}
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Name == name {
+			r.Workspaces[i] = ws
+			return ws, nil
+		}
+	}
+	r.Workspaces = append(r.Workspaces, ws)
+	sort.Slice(r.Workspaces, func(i, j int) bool { return r.Workspaces[i].Name < r.Workspaces[j].Name })
+	return ws, nil
+}
+
+// Remove drops a workspace from the registry.
+func (r *WorkspaceRegistry) Remove(name string) error {
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Name == name {
+			r.Workspaces = append(r.Workspaces[:i], r.Workspaces[i+1:]...)
+			if r.Default == name {
+				r.Default = ""
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("workspace %s not found", name)
+}
+
+// Use makes a registered workspace the default.
+func (r *WorkspaceRegistry) Use(name string) error {
+	if _, ok := r.Lookup(name); !ok {
+		return fmt.Errorf("workspace %s not found", name)
+	}
+	r.Default = name
+	return nil
+}
+
+// Lookup returns the workspace registered under name.
+func (r *WorkspaceRegistry) Lookup(name string) (Workspace, bool) {
+	for _, ws := range r.Workspaces {
+		if ws.Name == name {
+			return ws, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// ResolveWorkspace picks the workspace directory: an explicit --workspace
+// flag wins, then WORKLINE_WORKSPACE, then the registry default, then ".".
+// A flag or env value naming a registered workspace resolves to its path.
+func ResolveWorkspace(flagValue string, flagSet bool, reg *WorkspaceRegistry) string {
+	byName := func(v string) string {
+		if ws, ok := reg.Lookup(v); ok {
+			if _, err := os.Stat(v); err != nil {
+				return ws.Path
+			}
+		}
+		return v
+	}
+	if flagSet {
+		return byName(flagValue)
+	}
+	if env := os.Getenv("WORKLINE_WORKSPACE"); env != "" {
+		return byName(env)
+	}
+	if ws, ok := reg.Lookup(reg.Default); ok {
+		return ws.Path
+	}
+	return "."
+}
@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalRejectsKeysOutsideDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := Local{Dir: filepath.Join(root, "evidence")}
+	for _, key := range []string{"", ".", "..", "../secret", "a/../../secret", "/etc/passwd", "a/b/../../../secret"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Fatalf("put %q: expected invalid key", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Fatalf("get %q: expected invalid key, got %v", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Fatalf("delete %q: expected invalid key", key)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "secret")); !os.IsNotExist(err) {
+		t.Fatalf("nothing should be written outside the store: %v", err)
+	}
+
+	if err := store.Put(ctx, "task-1/../task-2/proof.txt", strings.NewReader("ok"), 2, "text/plain"); err != nil {
+		t.Fatalf("put key resolving inside the store: %v", err)
+	}
+	r, err := store.Get(ctx, "task-2/proof.txt")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "ok" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if err := store.Delete(ctx, "task-2/proof.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "task-2/proof.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"workline/internal/config"
+)
+
+// S3 stores blobs in an S3-compatible bucket using SigV4-signed requests.
+// Credentials come from WORKLINE_S3_ACCESS_KEY_ID / WORKLINE_S3_SECRET_ACCESS_KEY,
+// falling back to the standard AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY.
+type S3 struct {
+	Endpoint  *url.URL
+	Bucket    string
+	Region    string
+	Prefix    string
+	PathStyle bool
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+	Now       func() time.Time
+}
+
+func NewS3(cfg config.S3Config) (S3, error) {
+	if cfg.Bucket == "" {
+		return S3{}, errors.New("evidence.s3.bucket is required")
+	}
+	endpoint := cfg.Endpoint
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return S3{}, fmt.Errorf("invalid evidence.s3.endpoint %s", endpoint)
+	}
+	access := firstEnv("WORKLINE_S3_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID")
+	secret := firstEnv("WORKLINE_S3_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY")
+	if access == "" || secret == "" {
+		return S3{}, errors.New("s3 credentials missing: set WORKLINE_S3_ACCESS_KEY_ID and WORKLINE_S3_SECRET_ACCESS_KEY")
+	}
+	return S3{
+		Endpoint:  u,
+		Bucket:    cfg.Bucket,
+		Region:    region,
+		Prefix:    strings.Trim(cfg.Prefix, "/"),
+		PathStyle: cfg.PathStyle,
+		AccessKey: access,
+		SecretKey: secret,
+		Client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (s S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func (s S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, nil)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func s3Error(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func (s S3) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	objectKey := key
+	if s.Prefix != "" {
+		objectKey = s.Prefix + "/" + key
+	}
+	u := *s.Endpoint
+	if s.PathStyle {
+		u.Path = "/" + s.Bucket + "/" + objectKey
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = "/" + objectKey
+	}
+	// Send the path exactly as it is signed.
+	u.RawPath = s3EscapePath(u.Path)
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), rdr)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = int64(len(body))
+	}
+	return req, nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request.
+func (s S3) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath URI-encodes each segment of an object path as S3 canonical
+// requests expect: every byte but A-Z, a-z, 0-9, '-', '.', '_' and '~' becomes
+// %XX, so spaces, '+', '$' and non-ASCII keys sign the way S3 reads them.
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		var b strings.Builder
+		for j := 0; j < len(seg); j++ {
+			c := seg[j]
+			if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+				b.WriteByte(c)
+				continue
+			}
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+		segments[i] = b.String()
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
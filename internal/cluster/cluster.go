@@ -0,0 +1,54 @@
+// Package cluster shares lease locks and hot read caches between wl serve
+// replicas. Without it each process relies on the database alone.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Locks arbitrates task leases between replicas.
+type Locks interface {
+	// Acquire takes taskID for owner until ttl elapses. It succeeds when the
+	// lock is free or already held by owner, in which case ttl is renewed.
+	Acquire(ctx context.Context, taskID, owner string, ttl time.Duration) (bool, error)
+	// Release drops owner's lock on taskID; a lock held by anyone else is kept.
+	Release(ctx context.Context, taskID, owner string) error
+}
+
+// Cache holds hot read data shared by replicas. Entries expire on their own,
+// so a missed invalidation is bounded by the cache TTL.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// DefaultCacheTTL bounds how long cached entries live.
+const DefaultCacheTTL = 30 * time.Second
+
+// FromEnv connects to the Redis named by WORKLINE_REDIS_URL and returns nil
+// when it is unset. WORKLINE_REDIS_PREFIX namespaces keys (default
+// "workline:") and WORKLINE_REDIS_CACHE_TTL sets the cache TTL; 0 turns
+// caching off and keeps only lease locks.
+func FromEnv() (*Redis, error) {
+	url := os.Getenv("WORKLINE_REDIS_URL")
+	if url == "" {
+		return nil, nil
+	}
+	prefix, ok := os.LookupEnv("WORKLINE_REDIS_PREFIX")
+	if !ok {
+		prefix = "workline:"
+	}
+	ttl := DefaultCacheTTL
+	if v := os.Getenv("WORKLINE_REDIS_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid WORKLINE_REDIS_CACHE_TTL %q", v)
+		}
+		ttl = d
+	}
+	return OpenRedis(url, prefix, ttl)
+}
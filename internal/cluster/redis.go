@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis implements Locks and Cache on a Redis server.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// OpenRedis connects to url (redis:// or rediss://) and checks it responds.
+func OpenRedis(url, prefix string, ttl time.Duration) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect redis: %w", err)
+	}
+	return &Redis{client: client, prefix: prefix, ttl: ttl}, nil
+}
+
+// CacheEnabled reports whether the cache TTL is positive. With caching off
+// the Redis still serves lease locks.
+func (r *Redis) CacheEnabled() bool {
+	return r.ttl > 0
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// acquireScript sets the lock when it is free or already owned by the caller.
+var acquireScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner == false or owner == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return 1
+end
+return 0`)
+
+// releaseScript deletes the lock only when the caller owns it.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+func (r *Redis) Acquire(ctx context.Context, taskID, owner string, ttl time.Duration) (bool, error) {
+	n, err := acquireScript.Run(ctx, r.client, []string{r.prefix + "lease:" + taskID}, owner, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (r *Redis) Release(ctx context.Context, taskID, owner string) error {
+	return releaseScript.Run(ctx, r.client, []string{r.prefix + "lease:" + taskID}, owner).Err()
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := r.client.Get(ctx, r.prefix+"cache:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	return b, err == nil, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte) error {
+	return r.client.Set(ctx, r.prefix+"cache:"+key, value, r.ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = r.prefix + "cache:" + k
+	}
+	return r.client.Del(ctx, full...).Err()
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	// Timezones resolve the same on hosts without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+// CalendarConfig is the project's timezone and working calendar. Stored
+// timestamps stay UTC; SLA hours count only working time, staleness days
+// only working days, and `wl --local` shows times in the timezone.
+type CalendarConfig struct {
+	// Timezone is an IANA name such as Europe/Paris; UTC when unset.
+	Timezone string `yaml:"timezone,omitempty"`
+	// WorkingDays lists working weekdays (mon, tue, ...); every day when
+	// unset.
+	WorkingDays []string `yaml:"working_days,omitempty"`
+	// WorkingHours limits working days to HH:MM-HH:MM in the timezone; the
+	// whole day when unset.
+	WorkingHours string `yaml:"working_hours,omitempty"`
+	// Holidays are non-working days, as YYYY-MM-DD.
+	Holidays []string `yaml:"holidays,omitempty"`
+}
+
+// Weekdays are the names working_days takes, Sunday first as in time.Weekday.
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Location returns the project timezone, UTC when unset or unknown.
+func (c CalendarConfig) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Restricted reports whether some time is not working time.
+func (c CalendarConfig) Restricted() bool {
+	return len(c.WorkingDays) > 0 || c.WorkingHours != "" || len(c.Holidays) > 0
+}
+
+// WorkingDay reports whether the day of t, in the project timezone, is a
+// working day.
+func (c CalendarConfig) WorkingDay(t time.Time) bool {
+	t = t.In(c.Location())
+	if len(c.WorkingDays) > 0 && !slices.Contains(c.WorkingDays, Weekdays[t.Weekday()]) {
+		return false
+	}
+	return !slices.Contains(c.Holidays, t.Format(time.DateOnly))
+}
+
+// AddWorkingTime returns when d of working time has passed after from.
+func (c CalendarConfig) AddWorkingTime(from time.Time, d time.Duration) time.Time {
+	if !c.Restricted() {
+		return from.Add(d)
+	}
+	loc := c.Location()
+	startMin, endMin, _ := parseWorkingHours(c.WorkingHours)
+	t := from.In(loc)
+	for {
+		y, m, day := t.Date()
+		if c.WorkingDay(t) {
+			open := time.Date(y, m, day, 0, startMin, 0, 0, loc)
+			closing := time.Date(y, m, day, 0, endMin, 0, 0, loc)
+			if t.Before(open) {
+				t = open
+			}
+			if left := closing.Sub(t); left > 0 {
+				if d <= left {
+					return t.Add(d)
+				}
+				d -= left
+			}
+		}
+		t = time.Date(y, m, day+1, 0, 0, 0, 0, loc)
+	}
+}
+
+// WorkingDaysBefore steps back from t one day at a time until days working
+// days are behind it; it is t less days days when every day works.
+func (c CalendarConfig) WorkingDaysBefore(t time.Time, days int) time.Time {
+	t = t.In(c.Location())
+	for days > 0 {
+		t = t.AddDate(0, 0, -1)
+		if c.WorkingDay(t) {
+			days--
+		}
+	}
+	return t
+}
+
+// parseWorkingHours reads HH:MM-HH:MM, where the end may be 24:00, as
+// minutes since midnight; empty means the whole day.
+func parseWorkingHours(s string) (int, int, error) {
+	if s == "" {
+		return 0, 24 * 60, nil
+	}
+	from, to, _ := strings.Cut(s, "-")
+	start, err := parseClock(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end := 24 * 60
+	if strings.TrimSpace(to) != "24:00" {
+		if end, err = parseClock(to); err != nil {
+			return 0, 0, err
+		}
+	}
+	if end <= start {
+		return 0, 0, errors.New("must end after it starts")
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, errors.New("must be HH:MM-HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (c CalendarConfig) validate(v *validator) {
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			v.addf("project.calendar.timezone", "config.project.calendar.timezone %s is not a known timezone", c.Timezone)
+		}
+	}
+	for i, day := range c.WorkingDays {
+		if !slices.Contains(Weekdays, day) {
+			v.addf(fmt.Sprintf("project.calendar.working_days[%d]", i), "config.project.calendar.working_days names unknown day %s: use %s", day, strings.Join(Weekdays, ", "))
+		}
+	}
+	if _, _, err := parseWorkingHours(c.WorkingHours); err != nil {
+		v.addf("project.calendar.working_hours", "config.project.calendar.working_hours %s", err)
+	}
+	for i, day := range c.Holidays {
+		if _, err := time.Parse(time.DateOnly, day); err != nil {
+			v.addf(fmt.Sprintf("project.calendar.holidays[%d]", i), "config.project.calendar.holidays[%d] must be YYYY-MM-DD", i)
+		}
+	}
+}
@@ -5,28 +5,186 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"workline/internal/jsonschema"
 )
 
 // Config models workline.yml.
 type Config struct {
 	Project struct {
-		ID             string                       `yaml:"id"`
-		TaskTypes      map[string]TaskTypeConfig    `yaml:"task_types"`
+		ID             string                       `yaml:"id" required:"true"`
+		TaskTypes      map[string]TaskTypeConfig    `yaml:"task_types" required:"true"`
 		IterationTypes map[string]IterationTypeSpec `yaml:"iteration_types"`
+		Components     map[string]ComponentConfig   `yaml:"components,omitempty"`
 		Attestations   []AttestationConfig          `yaml:"attestations"`
 		ActorMissions  []ActorMissionConfig         `yaml:"actor_missions,omitempty"`
 		Validation     ValidationConfig             `yaml:"validation,omitempty"`
+		Planning       PlanningConfig               `yaml:"planning,omitempty"`
+		Board          BoardConfig                  `yaml:"board,omitempty"`
+		Force          ForceConfig                  `yaml:"force,omitempty"`
+		WorkOutcomes   WorkOutcomesConfig           `yaml:"work_outcomes,omitempty"`
+		Hooks          []HookConfig                 `yaml:"hooks,omitempty"`
+		EventRetention EventRetentionConfig         `yaml:"event_retention,omitempty"`
+		Staleness      StalenessConfig              `yaml:"staleness,omitempty"`
+		SLA            []SLARule                    `yaml:"sla,omitempty"`
+		Calendar       CalendarConfig               `yaml:"calendar,omitempty"`
+		Escalation     []EscalationRule             `yaml:"escalation,omitempty"`
+		ReadAudit      ReadAuditConfig              `yaml:"read_audit,omitempty"`
+		IDs            IDsConfig                    `yaml:"ids,omitempty"`
 		RBAC           RBACConfig                   `yaml:"rbac"`
-	} `yaml:"project"`
-	Webhooks []WebhookConfig `yaml:"webhooks"`
+	} `yaml:"project" required:"true"`
+	Webhooks   []WebhookConfig  `yaml:"webhooks"`
+	Evidence   EvidenceConfig   `yaml:"evidence,omitempty"`
+	Outbox     OutboxConfig     `yaml:"outbox,omitempty"`
+	Digest     DigestConfig     `yaml:"digest,omitempty"`
+	Encryption EncryptionConfig `yaml:"encryption,omitempty"`
+
+	// lines maps dotted config paths to YAML line numbers for error reports.
+	lines map[string]int
 }
 
 type TaskTypeConfig struct {
 	Policies map[string]PolicyRule `yaml:"policies"`
+	// Workflow replaces the built-in status flow for this task type.
+	Workflow *WorkflowConfig `yaml:"workflow,omitempty"`
+	// WorkOutcomesSchema is an optional JSON Schema the task's work outcomes
+	// must satisfy whenever they are written and when the task completes.
+	WorkOutcomesSchema map[string]any `yaml:"work_outcomes_schema,omitempty"`
+	// DoneRoles, when set, limits completing tasks of this type to actors
+	// holding one of these roles, on top of the task.done permission.
+	DoneRoles []string `yaml:"done_roles,omitempty"`
+}
+
+// ComponentConfig is a part of the project, such as a package of a monorepo,
+// that tasks can be scoped to.
+type ComponentConfig struct {
+	Description string `yaml:"description,omitempty"`
+	// Policies replace task type policies for tasks in the component, keyed
+	// by task type and then policy name. Policies not listed fall back to
+	// the task type's.
+	Policies map[string]map[string]PolicyRule `yaml:"policies,omitempty"`
+}
+
+// WorkOutcomesConfig limits task work outcomes.
+type WorkOutcomesConfig struct {
+	// MaxBytes caps the serialized size of a task's work outcomes; 0 means
+	// no limit.
+	MaxBytes int `yaml:"max_bytes,omitempty"`
+}
+
+// WorkflowConfig is a task state machine: the states, the allowed moves out
+// of each state, and which states end the work.
+type WorkflowConfig struct {
+	Initial     string              `yaml:"initial" required:"true"`
+	States      []string            `yaml:"states" required:"true"`
+	Transitions map[string][]string `yaml:"transitions"`
+	// Terminal states have no further work; Done is the terminal state that
+	// completes the task and is gated by the attestation policy.
+	Terminal []string `yaml:"terminal"`
+	Done     string   `yaml:"done" required:"true"`
+}
+
+// DefaultWorkflow is the status flow used when a task type defines none.
+func DefaultWorkflow() WorkflowConfig {
+	return WorkflowConfig{
+		Initial: "planned",
+		States:  []string{"planned", "ready", "in_progress", "review", "done", "rejected", "canceled"},
+		Transitions: map[string][]string{
+			"planned":     {"ready", "in_progress", "canceled", "review", "done"},
+			"ready":       {"in_progress", "canceled", "review", "done"},
+			"in_progress": {"rejected", "canceled", "review", "done"},
+			"review":      {"done", "rejected"},
+			"rejected":    {"planned"},
+		},
+		Terminal: []string{"done", "canceled"},
+		Done:     "done",
+	}
+}
+
+// HasState reports whether status is a state of the workflow.
+func (w WorkflowConfig) HasState(status string) bool {
+	for _, s := range w.States {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the workflow permits moving from one state to another.
+func (w WorkflowConfig) Allows(from, to string) bool {
+	for _, next := range w.Transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether status ends the work.
+func (w WorkflowConfig) IsTerminal(status string) bool {
+	for _, s := range w.Terminal {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (w WorkflowConfig) validate(taskType string) error {
+	if len(w.States) == 0 {
+		return fmt.Errorf("task type %s workflow has no states", taskType)
+	}
+	seen := map[string]bool{}
+	for _, state := range w.States {
+		if strings.TrimSpace(state) == "" {
+			return fmt.Errorf("task type %s workflow has empty state", taskType)
+		}
+		if seen[state] {
+			return fmt.Errorf("task type %s workflow repeats state %s", taskType, state)
+		}
+		seen[state] = true
+	}
+	if !seen[w.Initial] {
+		return fmt.Errorf("task type %s workflow initial state %q is not a state", taskType, w.Initial)
+	}
+	if !seen[w.Done] {
+		return fmt.Errorf("task type %s workflow done state %q is not a state", taskType, w.Done)
+	}
+	if !w.IsTerminal(w.Done) {
+		return fmt.Errorf("task type %s workflow done state %s must be terminal", taskType, w.Done)
+	}
+	for _, state := range w.Terminal {
+		if !seen[state] {
+			return fmt.Errorf("task type %s workflow terminal state %s is not a state", taskType, state)
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("task type %s workflow has transitions from unknown state %s", taskType, from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("task type %s workflow transition %s -> %s targets unknown state", taskType, from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// TaskWorkflow returns the state machine for a task type.
+func (c *Config) TaskWorkflow(taskType string) WorkflowConfig {
+	if tt, ok := c.Project.TaskTypes[taskType]; ok && tt.Workflow != nil {
+		return *tt.Workflow
+	}
+	return DefaultWorkflow()
 }
 
 type IterationTypeSpec struct {
@@ -38,14 +196,19 @@ type PolicyRule struct {
 }
 
 type AttestationConfig struct {
-	ID          string `yaml:"id"`
+	ID          string `yaml:"id" required:"true"`
 	Category    string `yaml:"category"`
 	Description string `yaml:"description"`
+	// Schema is an optional JSON Schema the attestation payload must satisfy.
+	Schema map[string]any `yaml:"schema,omitempty"`
+	// ValidDays is how long an attestation of this kind satisfies policies
+	// after it was issued; 0 means it never expires.
+	ValidDays int `yaml:"valid_days,omitempty"`
 }
 
 type ActorMissionConfig struct {
-	ActorID string `yaml:"actor_id"`
-	Mission string `yaml:"mission"`
+	ActorID string `yaml:"actor_id" required:"true"`
+	Mission string `yaml:"mission" required:"true"`
 }
 
 type ValidationConfig struct {
@@ -53,6 +216,223 @@ type ValidationConfig struct {
 	ChallengerPrompt string `yaml:"challenger_prompt,omitempty"`
 }
 
+// PlanningConfig controls task estimates and iteration capacity checks.
+type PlanningConfig struct {
+	// EstimateUnit is "points" (default) or "hours".
+	EstimateUnit string `yaml:"estimate_unit,omitempty" enum:"points,hours"`
+	// CapacityCheck is "warn" (default) or "block" when an iteration's
+	// estimates exceed its capacity.
+	CapacityCheck string `yaml:"capacity_check,omitempty" enum:"warn,block"`
+}
+
+// BoardConfig sets work-in-progress limits on the task board.
+type BoardConfig struct {
+	// WIPLimits caps how many tasks of the project may hold a status at once.
+	WIPLimits map[string]int `yaml:"wip_limits,omitempty"`
+	// StrictWIP rejects transitions past a limit instead of warning.
+	StrictWIP bool `yaml:"strict_wip,omitempty"`
+}
+
+// ForceConfig controls how the force flag takes effect.
+type ForceConfig struct {
+	// RequireApproval records forced operations as pending requests that a
+	// second actor with force.approve must approve before they run.
+	RequireApproval bool `yaml:"require_approval,omitempty"`
+}
+
+// IDsConfig picks how ids are made for new tasks, iterations and decisions
+// created without one.
+type IDsConfig struct {
+	// Strategy is uuid (the default: derived from project, title and time),
+	// ulid, or sequence: <prefix>-<n> for tasks, <prefix>-IT-<n> for
+	// iterations and <prefix>-DEC-<n> for decisions, counted per project.
+	Strategy string `yaml:"strategy,omitempty" enum:"uuid,ulid,sequence"`
+	// Prefix of sequence ids; defaults to the upper-cased project id.
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
+// SequencePrefix returns the prefix of sequence ids in a project.
+func (c IDsConfig) SequencePrefix(projectID string) string {
+	if c.Prefix != "" {
+		return c.Prefix
+	}
+	return strings.ToUpper(projectID)
+}
+
+// EventRetentionConfig bounds the event log; `wl log compact` archives and
+// deletes what falls outside it.
+type EventRetentionConfig struct {
+	// MaxAgeDays drops events older than this many days; 0 keeps them.
+	MaxAgeDays int `yaml:"max_age_days,omitempty"`
+	// MaxRows keeps at most this many events per project, newest first.
+	MaxRows int `yaml:"max_rows,omitempty"`
+	// Exempt lists event types that are never compacted and do not count
+	// toward MaxRows; a trailing "*" matches a prefix. Unset means
+	// DefaultRetentionExempt.
+	Exempt []string `yaml:"exempt,omitempty"`
+}
+
+// DefaultRetentionExempt keeps audit-relevant events when no exemptions are configured.
+var DefaultRetentionExempt = []string{"force.*", "rbac.*", "org.*"}
+
+// Enabled reports whether any retention limit is set.
+func (r EventRetentionConfig) Enabled() bool {
+	return r.MaxAgeDays > 0 || r.MaxRows > 0
+}
+
+// ExemptTypes returns the configured exemptions, or the defaults when unset.
+func (r EventRetentionConfig) ExemptTypes() []string {
+	if r.Exempt == nil {
+		return DefaultRetentionExempt
+	}
+	return r.Exempt
+}
+
+// StalenessConfig flags tasks left without an update; the staleness sweep
+// marks them stale and records a task.stale event for each.
+type StalenessConfig struct {
+	// Days without an update after which a task is stale, counting only
+	// project.calendar working days; 0 disables.
+	Days int `yaml:"days,omitempty"`
+	// Statuses a task must be in to go stale; defaults to in_progress.
+	Statuses []string `yaml:"statuses,omitempty"`
+	// Notify maps assignee actor ids to the email address told when their
+	// tasks go stale, sent through digest.smtp.
+	Notify map[string]string `yaml:"notify,omitempty"`
+}
+
+// DefaultStaleStatuses are the statuses tasks go stale in when none are configured.
+var DefaultStaleStatuses = []string{"in_progress"}
+
+// StaleStatuses returns the configured statuses, or the defaults when unset.
+func (s StalenessConfig) StaleStatuses() []string {
+	if len(s.Statuses) == 0 {
+		return DefaultStaleStatuses
+	}
+	return s.Statuses
+}
+
+// SLARule sets the due date of new tasks matching it: Hours of
+// project.calendar working time after creation.
+// Type and Priority narrow the match when set; the first matching rule of
+// project.sla wins, and tasks created with an explicit due date keep it.
+type SLARule struct {
+	Type     string `yaml:"type,omitempty"`
+	Priority *int   `yaml:"priority,omitempty"`
+	Hours    int    `yaml:"hours" required:"true"`
+}
+
+// SLAFor returns how long a new task of taskType and priority has until it
+// is due, from the first matching project.sla rule.
+func (c *Config) SLAFor(taskType string, priority *int) (time.Duration, bool) {
+	for _, rule := range c.Project.SLA {
+		if rule.Type != "" && rule.Type != taskType {
+			continue
+		}
+		if rule.Priority != nil && (priority == nil || *priority != *rule.Priority) {
+			continue
+		}
+		return time.Duration(rule.Hours) * time.Hour, true
+	}
+	return 0, false
+}
+
+// EscalationRule raises the priority of open tasks matching it by one level
+// (toward 1) each AfterDays they stay open, counted from creation and then
+// from the last escalation. Type narrows the match when set; the first
+// matching rule of project.escalation wins. Tasks without a priority are left
+// alone.
+type EscalationRule struct {
+	Type      string `yaml:"type,omitempty"`
+	AfterDays int    `yaml:"after_days" required:"true"`
+	// Highest is the highest priority the rule escalates to; defaults to 1.
+	Highest int `yaml:"highest,omitempty"`
+}
+
+// Ceiling returns the configured highest priority, or 1 when unset.
+func (r EscalationRule) Ceiling() int {
+	if r.Highest == 0 {
+		return 1
+	}
+	return r.Highest
+}
+
+// After is how long a task waits between escalations.
+func (r EscalationRule) After() time.Duration {
+	return time.Duration(r.AfterDays) * 24 * time.Hour
+}
+
+// EscalationFor returns the first project.escalation rule matching taskType.
+func (c *Config) EscalationFor(taskType string) (EscalationRule, bool) {
+	for _, rule := range c.Project.Escalation {
+		if rule.Type == "" || rule.Type == taskType {
+			return rule, true
+		}
+	}
+	return EscalationRule{}, false
+}
+
+// ReadAuditConfig records who reads the project's config, events and
+// attestations through the API, in a table of its own; `wl log reads` lists
+// and exports it.
+type ReadAuditConfig struct {
+	// Mode is off (the default), sampled or full.
+	Mode string `yaml:"mode,omitempty" enum:"off,sampled,full"`
+	// SampleRate is the share of reads recorded in sampled mode, above 0 and
+	// at most 1.
+	SampleRate float64 `yaml:"sample_rate,omitempty"`
+	// Resources narrows auditing to some of ReadAuditResources; unset audits
+	// them all.
+	Resources []string `yaml:"resources,omitempty"`
+}
+
+// ReadAuditResources are the reads project.read_audit can record.
+var ReadAuditResources = []string{"config", "events", "attestations"}
+
+// Rate returns the share of reads of resource to record: 0 when auditing is
+// off or skips resource, 1 in full mode.
+func (r ReadAuditConfig) Rate(resource string) float64 {
+	if len(r.Resources) > 0 && !slices.Contains(r.Resources, resource) {
+		return 0
+	}
+	switch r.Mode {
+	case "full":
+		return 1
+	case "sampled":
+		return r.SampleRate
+	}
+	return 0
+}
+
+// HookConfig runs an action when a task or iteration changes status.
+type HookConfig struct {
+	Name string `yaml:"name,omitempty"`
+	// On is the entity kind: "task" or "iteration".
+	On string `yaml:"on" required:"true" enum:"task,iteration"`
+	// From and To filter the transition; empty matches any status.
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+	// Run names a built-in hook (assign_reviewer, create_task) or "webhook".
+	Run  string         `yaml:"run" required:"true"`
+	With map[string]any `yaml:"with,omitempty"`
+	// URL, Secret and TimeoutSeconds configure webhook hooks.
+	URL            string `yaml:"url,omitempty"`
+	Secret         string `yaml:"secret,omitempty"`
+	TimeoutSeconds int    `yaml:"timeout_seconds,omitempty"`
+	// Required makes a failing hook abort the transition instead of being
+	// recorded and skipped. Webhook hooks are posted after the transition
+	// commits and cannot be required.
+	Required bool `yaml:"required,omitempty"`
+}
+
+// HookLabel returns the hook name, falling back to its position and action.
+func (h HookConfig) HookLabel(idx int) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return fmt.Sprintf("hooks[%d].%s", idx, h.Run)
+}
+
 type RBACConfig struct {
 	Permissions map[string][]string `yaml:"permissions"`
 	Roles       map[string]RBACRole `yaml:"roles"`
@@ -65,13 +445,186 @@ type RBACRole struct {
 }
 
 type WebhookConfig struct {
-	URL            string   `yaml:"url"`
+	URL            string   `yaml:"url" required:"true"`
 	Events         []string `yaml:"events"`
 	Secret         string   `yaml:"secret"`
 	Enabled        *bool    `yaml:"enabled"`
 	TimeoutSeconds int      `yaml:"timeout_seconds"`
 }
 
+// EvidenceConfig selects where attestation evidence files are stored.
+type EvidenceConfig struct {
+	// Store is "local" (default) or "s3".
+	Store    string   `yaml:"store,omitempty" enum:"local,s3"`
+	Dir      string   `yaml:"dir,omitempty"`
+	MaxBytes int64    `yaml:"max_bytes,omitempty"`
+	S3       S3Config `yaml:"s3,omitempty"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	Bucket    string `yaml:"bucket,omitempty"`
+	Prefix    string `yaml:"prefix,omitempty"`
+	PathStyle bool   `yaml:"path_style,omitempty"`
+}
+
+// OutboxConfig publishes events to a message broker through the outbox table.
+type OutboxConfig struct {
+	// Broker is "nats" or "kafka"; empty disables the outbox.
+	Broker  string   `yaml:"broker,omitempty" enum:"nats,kafka"`
+	Servers []string `yaml:"servers,omitempty"`
+	// Topic is the subject (NATS) or topic (Kafka) template; {project},
+	// {type} and {entity_kind} are replaced per event.
+	Topic string `yaml:"topic,omitempty"`
+	// Events limits publishing to these event types; empty publishes all.
+	Events []string `yaml:"events,omitempty"`
+}
+
+// DefaultOutboxTopic routes every project's events to its own topic.
+const DefaultOutboxTopic = "workline.{project}"
+
+var outboxTopicVars = regexp.MustCompile(`\{[^}]*\}`)
+
+// Enabled reports whether events are written to the outbox.
+func (o OutboxConfig) Enabled() bool {
+	return o.Broker != ""
+}
+
+// Route returns the topic an event is published to, or "" when the event is
+// not published.
+func (o OutboxConfig) Route(projectID, evtType, entityKind string) string {
+	if !o.Enabled() {
+		return ""
+	}
+	if len(o.Events) > 0 && !slices.Contains(o.Events, evtType) {
+		return ""
+	}
+	topic := o.Topic
+	if topic == "" {
+		topic = DefaultOutboxTopic
+	}
+	if projectID == "" {
+		projectID = "_"
+	}
+	return strings.NewReplacer("{project}", projectID, "{type}", evtType, "{entity_kind}", entityKind).Replace(topic)
+}
+
+// DigestConfig emails a project summary to recipients on a schedule.
+type DigestConfig struct {
+	// Schedule is "daily" or "weekly"; empty sends digests only on demand.
+	Schedule string `yaml:"schedule,omitempty" enum:"daily,weekly"`
+	// Weekday is the day weekly digests go out; defaults to monday.
+	Weekday string `yaml:"weekday,omitempty"`
+	// Time is the UTC time of day digests go out, as HH:MM; defaults to 08:00.
+	Time       string     `yaml:"time,omitempty"`
+	Recipients []string   `yaml:"recipients,omitempty"`
+	SMTP       SMTPConfig `yaml:"smtp,omitempty"`
+}
+
+// SMTPConfig is the mail server digests are sent through. STARTTLS is used
+// whenever the server offers it.
+type SMTPConfig struct {
+	Host     string `yaml:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	From     string `yaml:"from,omitempty"`
+}
+
+// EncryptionConfig turns on at-rest encryption of attestation payloads, work
+// outcomes and decision context. The 32-byte AES key comes from Key, base64
+// encoded (usually a ${ENV} or file:// reference), or from the standard output
+// of KMSCommand, a program and its arguments (run without a shell) asking a
+// key management service for it.
+// PreviousKeys still decrypt values sealed before a key rotation.
+type EncryptionConfig struct {
+	Key          string   `yaml:"key,omitempty"`
+	KMSCommand   []string `yaml:"kms_command,omitempty"`
+	PreviousKeys []string `yaml:"previous_keys,omitempty"`
+}
+
+// Enabled reports whether a key source is configured.
+func (c EncryptionConfig) Enabled() bool {
+	return strings.TrimSpace(c.Key) != "" || len(c.KMSCommand) > 0
+}
+
+// DefaultDigestTime is when scheduled digests go out when digest.time is unset.
+const DefaultDigestTime = "08:00"
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// Period is how far back a digest looks when no digest was sent before.
+func (d DigestConfig) Period() time.Duration {
+	if d.Schedule == "daily" {
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// LastSlot returns the latest scheduled send time at or before now, or the
+// zero time when digests are not scheduled.
+func (d DigestConfig) LastSlot(now time.Time) time.Time {
+	if d.Schedule == "" {
+		return time.Time{}
+	}
+	at := d.Time
+	if at == "" {
+		at = DefaultDigestTime
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}
+	}
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if d.Schedule == "weekly" {
+		day, ok := weekdays[strings.ToLower(d.Weekday)]
+		if !ok {
+			day = time.Monday
+		}
+		back := (int(slot.Weekday()) - int(day) + 7) % 7
+		slot = slot.AddDate(0, 0, -back)
+	}
+	return slot
+}
+
+// DefaultEvidenceMaxBytes caps evidence uploads when evidence.max_bytes is unset.
+const DefaultEvidenceMaxBytes = 10 << 20
+
+// EvidenceMaxBytes returns the configured evidence size limit.
+func (c *Config) EvidenceMaxBytes() int64 {
+	if c.Evidence.MaxBytes > 0 {
+		return c.Evidence.MaxBytes
+	}
+	return DefaultEvidenceMaxBytes
+}
+
+// EstimateUnit returns the unit task estimates are expressed in.
+func (c *Config) EstimateUnit() string {
+	if c.Project.Planning.EstimateUnit == "" {
+		return "points"
+	}
+	return c.Project.Planning.EstimateUnit
+}
+
+// BlockOverCapacity reports whether planning past iteration capacity is rejected.
+func (c *Config) BlockOverCapacity() bool {
+	return c.Project.Planning.CapacityCheck == "block"
+}
+
+// WIPLimit returns the work-in-progress limit of status, if one is set.
+func (c *Config) WIPLimit(status string) (int, bool) {
+	limit, ok := c.Project.Board.WIPLimits[status]
+	return limit, ok
+}
+
 // Load reads and validates config from workspace.
 func Load(workspace string) (*Config, error) {
 	path := Path(workspace)
@@ -85,129 +638,387 @@ func Load(workspace string) (*Config, error) {
 	return FromYAML(data)
 }
 
-// Validate ensures the config meets required structure.
+// Validate ensures the config meets required structure. It reports every
+// problem found as ValidationErrors, with YAML line numbers when the config
+// was parsed from YAML.
 func (c *Config) Validate() error {
+	v := &validator{lines: c.lines}
 	if c.Project.ID == "" {
-		return fmt.Errorf("config.project.id is required")
+		v.addf("project.id", "config.project.id is required")
 	}
 	if len(c.Project.TaskTypes) == 0 {
-		return fmt.Errorf("config.project.task_types is required")
+		v.addf("project.task_types", "config.project.task_types is required")
 	}
 	attestationKinds := c.attestationKinds()
-	for id, tt := range c.Project.TaskTypes {
+	for _, id := range sortedKeys(c.Project.TaskTypes) {
+		tt := c.Project.TaskTypes[id]
+		path := "project.task_types." + id
 		if strings.TrimSpace(id) == "" {
-			return fmt.Errorf("config.project.task_types contains empty type id")
+			v.addf(path, "config.project.task_types contains empty type id")
 		}
 		if len(tt.Policies) == 0 {
-			return fmt.Errorf("task type %s has no policies", id)
+			v.addf(path, "task type %s has no policies", id)
 		}
-		for policyName, rule := range tt.Policies {
-			if strings.TrimSpace(policyName) == "" {
-				return fmt.Errorf("task type %s has empty policy name", id)
+		if tt.Workflow != nil {
+			if err := tt.Workflow.validate(id); err != nil {
+				v.addf(path+".workflow", "%s", err)
 			}
-			for _, kind := range rule.All {
-				if kind == "" {
-					return fmt.Errorf("task type %s policy %s has empty attestation kind", id, policyName)
-				}
-				if len(attestationKinds) > 0 && !attestationKinds[kind] {
-					return fmt.Errorf("task type %s policy %s requires unknown attestation kind %s", id, policyName, kind)
-				}
+		}
+		if tt.WorkOutcomesSchema != nil {
+			if err := jsonschema.Check(tt.WorkOutcomesSchema); err != nil {
+				v.addf(path+".work_outcomes_schema", "task type %s: %s", id, err)
 			}
 		}
+		for i, role := range tt.DoneRoles {
+			if strings.TrimSpace(role) == "" {
+				v.addf(fmt.Sprintf("%s.done_roles[%d]", path, i), "task type %s: done_roles contains an empty role", id)
+			}
+		}
+		v.checkPolicies(path+".policies", "task type "+id, tt.Policies, attestationKinds)
 	}
-	for id, it := range c.Project.IterationTypes {
+	if c.Project.WorkOutcomes.MaxBytes < 0 {
+		v.addf("project.work_outcomes.max_bytes", "work_outcomes.max_bytes must not be negative")
+	}
+	for _, id := range sortedKeys(c.Project.IterationTypes) {
+		path := "project.iteration_types." + id
 		if strings.TrimSpace(id) == "" {
-			return fmt.Errorf("config.project.iteration_types contains empty type id")
+			v.addf(path, "config.project.iteration_types contains empty type id")
 		}
-		for policyName, rule := range it.Policies {
-			if strings.TrimSpace(policyName) == "" {
-				return fmt.Errorf("iteration type %s has empty policy name", id)
-			}
-			for _, kind := range rule.All {
-				if kind == "" {
-					return fmt.Errorf("iteration type %s policy %s has empty attestation kind", id, policyName)
-				}
-				if len(attestationKinds) > 0 && !attestationKinds[kind] {
-					return fmt.Errorf("iteration type %s policy %s requires unknown attestation kind %s", id, policyName, kind)
-				}
+		v.checkPolicies(path+".policies", "iteration type "+id, c.Project.IterationTypes[id].Policies, attestationKinds)
+	}
+	for _, id := range sortedKeys(c.Project.Components) {
+		path := "project.components." + id
+		if strings.TrimSpace(id) == "" {
+			v.addf(path, "config.project.components contains empty component id")
+		}
+		for _, taskType := range sortedKeys(c.Project.Components[id].Policies) {
+			if _, ok := c.Project.TaskTypes[taskType]; !ok {
+				v.addf(path+".policies."+taskType, "component %s overrides unknown task type %s", id, taskType)
+				continue
 			}
+			v.checkPolicies(path+".policies."+taskType, "component "+id+" task type "+taskType, c.Project.Components[id].Policies[taskType], attestationKinds)
 		}
 	}
-	if len(c.Project.Attestations) > 0 {
-		seen := map[string]bool{}
-		for _, att := range c.Project.Attestations {
-			if strings.TrimSpace(att.ID) == "" {
-				return fmt.Errorf("config.project.attestations contains empty id")
-			}
-			if seen[att.ID] {
-				return fmt.Errorf("duplicate attestation id %s", att.ID)
+	seenAttestations := map[string]bool{}
+	for i, att := range c.Project.Attestations {
+		path := fmt.Sprintf("project.attestations[%d]", i)
+		if strings.TrimSpace(att.ID) == "" {
+			v.addf(path, "config.project.attestations contains empty id")
+			continue
+		}
+		if seenAttestations[att.ID] {
+			v.addf(path+".id", "duplicate attestation id %s", att.ID)
+		}
+		seenAttestations[att.ID] = true
+		if att.Schema != nil {
+			if err := jsonschema.Check(att.Schema); err != nil {
+				v.addf(path+".schema", "attestation %s: %s", att.ID, err)
 			}
-			seen[att.ID] = true
+		}
+		if att.ValidDays < 0 {
+			v.addf(path+".valid_days", "attestation %s: valid_days must not be negative", att.ID)
 		}
 	}
-	if c.Project.ActorMissions != nil {
-		seen := map[string]bool{}
-		for i, m := range c.Project.ActorMissions {
-			actorID := strings.TrimSpace(m.ActorID)
-			if actorID == "" {
-				return fmt.Errorf("config.project.actor_missions[%d].actor_id is required", i)
-			}
-			if strings.TrimSpace(m.Mission) == "" {
-				return fmt.Errorf("config.project.actor_missions[%d].mission is required", i)
-			}
-			if seen[actorID] {
-				return fmt.Errorf("config.project.actor_missions contains duplicate actor_id %s", actorID)
+	seenMissions := map[string]bool{}
+	for i, m := range c.Project.ActorMissions {
+		path := fmt.Sprintf("project.actor_missions[%d]", i)
+		actorID := strings.TrimSpace(m.ActorID)
+		if actorID == "" {
+			v.addf(path, "config.project.actor_missions[%d].actor_id is required", i)
+		}
+		if strings.TrimSpace(m.Mission) == "" {
+			v.addf(path, "config.project.actor_missions[%d].mission is required", i)
+		}
+		if actorID != "" && seenMissions[actorID] {
+			v.addf(path+".actor_id", "config.project.actor_missions contains duplicate actor_id %s", actorID)
+		}
+		seenMissions[actorID] = true
+	}
+	known := Permissions()
+	for _, set := range sortedKeys(c.Project.RBAC.Permissions) {
+		for i, perm := range c.Project.RBAC.Permissions[set] {
+			if _, ok := known[perm]; !ok {
+				v.addf(fmt.Sprintf("project.rbac.permissions.%s[%d]", set, i), "permission set %s grants unknown permission %s", set, perm)
 			}
-			seen[actorID] = true
 		}
 	}
 	if len(c.Project.RBAC.Roles) > 0 {
 		if len(c.Project.RBAC.Permissions) == 0 {
-			return fmt.Errorf("config.project.rbac.permissions is required when roles are defined")
+			v.addf("project.rbac.permissions", "config.project.rbac.permissions is required when roles are defined")
 		}
 		if _, ok := c.Project.RBAC.Roles["owner"]; !ok {
-			return fmt.Errorf("config.project.rbac.roles must include owner")
+			v.addf("project.rbac.roles", "config.project.rbac.roles must include owner")
 		}
-		for roleID, role := range c.Project.RBAC.Roles {
+		for _, roleID := range sortedKeys(c.Project.RBAC.Roles) {
+			role := c.Project.RBAC.Roles[roleID]
+			path := "project.rbac.roles." + roleID
 			if roleID == "" {
-				return fmt.Errorf("config.project.rbac.roles contains empty role id")
+				v.addf(path, "config.project.rbac.roles contains empty role id")
 			}
-			for _, grant := range role.Grants {
+			for i, grant := range role.Grants {
+				grantPath := fmt.Sprintf("%s.grants[%d]", path, i)
 				if grant == "" {
-					return fmt.Errorf("role %s has empty grant id", roleID)
+					v.addf(grantPath, "role %s has empty grant id", roleID)
+					continue
 				}
 				if len(c.Project.RBAC.Permissions) > 0 {
 					if _, ok := c.Project.RBAC.Permissions[grant]; !ok {
-						return fmt.Errorf("role %s references unknown permission set %s", roleID, grant)
+						v.addf(grantPath, "role %s references unknown permission set %s", roleID, grant)
 					}
 				}
 			}
-			for _, kind := range role.CanAttest {
+			for i, kind := range role.CanAttest {
+				kindPath := fmt.Sprintf("%s.can_attest[%d]", path, i)
 				if kind == "" {
-					return fmt.Errorf("role %s has empty attestation kind", roleID)
+					v.addf(kindPath, "role %s has empty attestation kind", roleID)
+					continue
 				}
 				if len(attestationKinds) > 0 && !attestationKinds[kind] {
-					return fmt.Errorf("role %s references unknown attestation kind %s", roleID, kind)
+					v.addf(kindPath, "role %s references unknown attestation kind %s", roleID, kind)
 				}
 			}
 		}
 	}
+	switch c.Project.Planning.EstimateUnit {
+	case "", "points", "hours":
+	default:
+		v.addf("project.planning.estimate_unit", "config.project.planning.estimate_unit must be points or hours")
+	}
+	switch c.Project.Planning.CapacityCheck {
+	case "", "warn", "block":
+	default:
+		v.addf("project.planning.capacity_check", "config.project.planning.capacity_check must be warn or block")
+	}
+	states := map[string]bool{}
+	for _, taskType := range sortedKeys(c.AllowedTaskTypes()) {
+		for _, state := range c.TaskWorkflow(taskType).States {
+			states[state] = true
+		}
+	}
+	for _, status := range sortedKeys(c.Project.Board.WIPLimits) {
+		path := "project.board.wip_limits." + status
+		if !states[status] {
+			v.addf(path, "config.project.board.wip_limits names unknown status %s", status)
+		}
+		if c.Project.Board.WIPLimits[status] <= 0 {
+			v.addf(path, "config.project.board.wip_limits.%s must be positive", status)
+		}
+	}
+	if c.Project.EventRetention.MaxAgeDays < 0 {
+		v.addf("project.event_retention.max_age_days", "config.project.event_retention.max_age_days must not be negative")
+	}
+	if c.Project.EventRetention.MaxRows < 0 {
+		v.addf("project.event_retention.max_rows", "config.project.event_retention.max_rows must not be negative")
+	}
+	for i, pattern := range c.Project.EventRetention.Exempt {
+		if strings.TrimSpace(strings.TrimSuffix(pattern, "*")) == "" {
+			v.addf(fmt.Sprintf("project.event_retention.exempt[%d]", i), "config.project.event_retention.exempt[%d] must name an event type or prefix", i)
+		}
+	}
+	if c.Project.Staleness.Days < 0 {
+		v.addf("project.staleness.days", "config.project.staleness.days must not be negative")
+	}
+	for i, status := range c.Project.Staleness.Statuses {
+		if !states[status] {
+			v.addf(fmt.Sprintf("project.staleness.statuses[%d]", i), "config.project.staleness.statuses names unknown status %s", status)
+		}
+	}
+	for _, actor := range sortedKeys(c.Project.Staleness.Notify) {
+		if !strings.Contains(c.Project.Staleness.Notify[actor], "@") {
+			v.addf("project.staleness.notify."+actor, "config.project.staleness.notify.%s is not an email address", actor)
+		}
+	}
+	if len(c.Project.Staleness.Notify) > 0 && strings.TrimSpace(c.Digest.SMTP.Host) == "" {
+		v.addf("project.staleness.notify", "config.project.staleness.notify needs digest.smtp.host")
+	}
+	switch audit := c.Project.ReadAudit; audit.Mode {
+	case "", "off", "full":
+		if audit.SampleRate != 0 {
+			v.addf("project.read_audit.sample_rate", "config.project.read_audit.sample_rate needs mode sampled")
+		}
+	case "sampled":
+		if audit.SampleRate <= 0 || audit.SampleRate > 1 {
+			v.addf("project.read_audit.sample_rate", "config.project.read_audit.sample_rate must be above 0 and at most 1")
+		}
+	default:
+		v.addf("project.read_audit.mode", "config.project.read_audit.mode must be off, sampled or full")
+	}
+	for i, resource := range c.Project.ReadAudit.Resources {
+		if !slices.Contains(ReadAuditResources, resource) {
+			v.addf(fmt.Sprintf("project.read_audit.resources[%d]", i), "config.project.read_audit.resources names unknown resource %s", resource)
+		}
+	}
+	c.Project.Calendar.validate(v)
+	taskTypes := c.AllowedTaskTypes()
+	for i, rule := range c.Project.SLA {
+		path := fmt.Sprintf("project.sla[%d]", i)
+		if rule.Hours <= 0 {
+			v.addf(path+".hours", "config.project.sla[%d].hours must be positive", i)
+		}
+		if rule.Type != "" && !taskTypes[rule.Type] {
+			v.addf(path+".type", "config.project.sla[%d] names unknown task type %s", i, rule.Type)
+		}
+	}
+	for i, rule := range c.Project.Escalation {
+		path := fmt.Sprintf("project.escalation[%d]", i)
+		if rule.AfterDays <= 0 {
+			v.addf(path+".after_days", "config.project.escalation[%d].after_days must be positive", i)
+		}
+		if rule.Highest < 0 {
+			v.addf(path+".highest", "config.project.escalation[%d].highest must not be negative", i)
+		}
+		if rule.Type != "" && !taskTypes[rule.Type] {
+			v.addf(path+".type", "config.project.escalation[%d] names unknown task type %s", i, rule.Type)
+		}
+	}
+	switch c.Project.IDs.Strategy {
+	case "", "uuid", "ulid", "sequence":
+	default:
+		v.addf("project.ids.strategy", "config.project.ids.strategy must be uuid, ulid or sequence")
+	}
+	if strings.ContainsAny(c.Project.IDs.Prefix, "/ \t\n") {
+		v.addf("project.ids.prefix", "config.project.ids.prefix must not contain slashes or spaces")
+	}
+	seenHooks := map[string]bool{}
+	for i, hook := range c.Project.Hooks {
+		path := fmt.Sprintf("project.hooks[%d]", i)
+		if hook.Name != "" {
+			if seenHooks[hook.Name] {
+				v.addf(path+".name", "config.project.hooks contains duplicate name %s", hook.Name)
+			}
+			seenHooks[hook.Name] = true
+		}
+		if hook.On != "task" && hook.On != "iteration" {
+			v.addf(path+".on", "config.project.hooks[%d].on must be task or iteration", i)
+		}
+		switch hook.Run {
+		case "webhook":
+			if strings.TrimSpace(hook.URL) == "" {
+				v.addf(path, "config.project.hooks[%d].url is required for webhook hooks", i)
+			}
+			if hook.Required {
+				v.addf(path+".required", "config.project.hooks[%d].required is not supported for webhook hooks: they are posted after the transition commits", i)
+			}
+		case "":
+			v.addf(path, "config.project.hooks[%d].run is required", i)
+		}
+	}
+	switch c.Evidence.Store {
+	case "", "local":
+	case "s3":
+		if strings.TrimSpace(c.Evidence.S3.Bucket) == "" {
+			v.addf("evidence.s3", "config.evidence.s3.bucket is required")
+		}
+	default:
+		v.addf("evidence.store", "config.evidence.store must be local or s3")
+	}
+	if c.Evidence.MaxBytes < 0 {
+		v.addf("evidence.max_bytes", "config.evidence.max_bytes must be positive")
+	}
+	switch c.Outbox.Broker {
+	case "":
+	case "nats", "kafka":
+		if len(c.Outbox.Servers) == 0 {
+			v.addf("outbox.servers", "config.outbox.servers is required")
+		}
+		for _, name := range outboxTopicVars.FindAllString(c.Outbox.Topic, -1) {
+			switch name {
+			case "{project}", "{type}", "{entity_kind}":
+			default:
+				v.addf("outbox.topic", "config.outbox.topic has unknown placeholder %s", name)
+			}
+		}
+	default:
+		v.addf("outbox.broker", "config.outbox.broker must be nats or kafka")
+	}
+	switch c.Digest.Schedule {
+	case "":
+	case "daily", "weekly":
+		if len(c.Digest.Recipients) == 0 {
+			v.addf("digest.recipients", "config.digest.recipients is required")
+		}
+		if strings.TrimSpace(c.Digest.SMTP.Host) == "" {
+			v.addf("digest.smtp.host", "config.digest.smtp.host is required")
+		}
+	default:
+		v.addf("digest.schedule", "config.digest.schedule must be daily or weekly")
+	}
+	if _, ok := weekdays[strings.ToLower(c.Digest.Weekday)]; c.Digest.Weekday != "" && !ok {
+		v.addf("digest.weekday", "config.digest.weekday must be a day of the week")
+	}
+	if _, err := time.Parse("15:04", c.Digest.Time); c.Digest.Time != "" && err != nil {
+		v.addf("digest.time", "config.digest.time must be HH:MM")
+	}
+	for i, to := range c.Digest.Recipients {
+		if !strings.Contains(to, "@") {
+			v.addf(fmt.Sprintf("digest.recipients[%d]", i), "config.digest.recipients[%d] is not an email address", i)
+		}
+	}
+	if c.Digest.SMTP.Host != "" && strings.TrimSpace(c.Digest.SMTP.From) == "" {
+		v.addf("digest.smtp.from", "config.digest.smtp.from is required")
+	}
+	if strings.TrimSpace(c.Encryption.Key) != "" && len(c.Encryption.KMSCommand) > 0 {
+		v.addf("encryption", "config.encryption takes key or kms_command, not both")
+	}
+	if len(c.Encryption.KMSCommand) > 0 && strings.TrimSpace(c.Encryption.KMSCommand[0]) == "" {
+		v.addf("encryption.kms_command", "config.encryption.kms_command must start with the program to run")
+	}
+	if len(c.Encryption.PreviousKeys) > 0 && !c.Encryption.Enabled() {
+		v.addf("encryption.previous_keys", "config.encryption.previous_keys requires key or kms_command")
+	}
 	for i, hook := range c.Webhooks {
 		if hook.Enabled != nil && !*hook.Enabled {
 			continue
 		}
+		path := fmt.Sprintf("webhooks[%d]", i)
 		if strings.TrimSpace(hook.URL) == "" {
-			return fmt.Errorf("config.webhooks[%d].url is required", i)
+			v.addf(path, "config.webhooks[%d].url is required", i)
 		}
-		for _, evt := range hook.Events {
+		for j, evt := range hook.Events {
 			if strings.TrimSpace(evt) == "" {
-				return fmt.Errorf("config.webhooks[%d] has empty event type", i)
+				v.addf(fmt.Sprintf("%s.events[%d]", path, j), "config.webhooks[%d] has empty event type", i)
 			}
 		}
 	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// AttestationSchema returns the payload schema declared for an attestation kind, if any.
+func (c *Config) AttestationSchema(kind string) map[string]any {
+	for _, att := range c.Project.Attestations {
+		if att.ID == kind {
+			return att.Schema
+		}
+	}
 	return nil
 }
 
+// DoneRoles returns the roles allowed to complete tasks of a type; empty
+// means anyone with task.done.
+func (c *Config) DoneRoles(taskType string) []string {
+	return c.Project.TaskTypes[taskType].DoneRoles
+}
+
+// WorkOutcomesSchema returns the work outcomes schema declared for a task
+// type, if any.
+func (c *Config) WorkOutcomesSchema(taskType string) map[string]any {
+	return c.Project.TaskTypes[taskType].WorkOutcomesSchema
+}
+
+// AttestationValidity returns how long attestations of a kind stay valid, or
+// 0 when they never expire.
+func (c *Config) AttestationValidity(kind string) time.Duration {
+	for _, att := range c.Project.Attestations {
+		if att.ID == kind {
+			return time.Duration(att.ValidDays) * 24 * time.Hour
+		}
+	}
+	return 0
+}
+
 func (c *Config) attestationKinds() map[string]bool {
 	kinds := map[string]bool{}
 	for _, att := range c.Project.Attestations {
@@ -221,7 +1032,7 @@ func (c *Config) attestationKinds() map[string]bool {
 }
 
 func defaultTaskTypes() map[string]bool {
-	types := []string{"technical", "feature", "bug", "docs", "chore", "workshop", "plan", "decision", "security"}
+	types := []string{"technical", "feature", "bug", "docs", "chore", "workshop", "plan", "decision", "security", "release"}
 	allowed := make(map[string]bool, len(types))
 	for _, taskType := range types {
 		allowed[taskType] = true
@@ -255,6 +1066,28 @@ func (c *Config) TaskPolicy(taskType, policyName string) (PolicyRule, bool) {
 	return rule, ok
 }
 
+// HasComponent reports whether the component is in the registry; the empty
+// component (no component) always is.
+func (c *Config) HasComponent(component string) bool {
+	if component == "" {
+		return true
+	}
+	_, ok := c.Project.Components[component]
+	return ok
+}
+
+// ComponentTaskPolicy returns a task type's policy as it applies to tasks in
+// the component: the component's override when it has one, else the task
+// type's own policy.
+func (c *Config) ComponentTaskPolicy(component, taskType, policyName string) (PolicyRule, bool) {
+	if comp, ok := c.Project.Components[component]; ok {
+		if rule, ok := comp.Policies[taskType][policyName]; ok {
+			return rule, true
+		}
+	}
+	return c.TaskPolicy(taskType, policyName)
+}
+
 // DefaultTaskPolicyName returns the default policy name for a task type.
 func (c *Config) DefaultTaskPolicyName(taskType string) string {
 	tt, ok := c.Project.TaskTypes[taskType]
@@ -330,13 +1163,24 @@ func Default(projectID string) *Config {
 
 // FromYAML parses and validates config from raw YAML bytes.
 func FromYAML(data []byte) (*Config, error) {
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("invalid config yaml: %w", err)
 	}
+	var cfg Config
+	if doc.Kind != 0 {
+		if err := doc.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("invalid config yaml: %w", err)
+		}
+	}
+	cfg.lines = map[string]int{}
+	collectLines(&doc, "", cfg.lines)
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	if _, err := cfg.ResolveSecrets(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -398,6 +1242,10 @@ const defaultTemplate = `project:
       policies:
         done:
           all: [security.ok, review.approved, analysis.validated, responsibility.accepted]
+    release:
+      policies:
+        done:
+          all: [ci.passed, review.approved, provenance.verified]
   iteration_types:
     standard:
       policies:
@@ -416,6 +1264,9 @@ const defaultTemplate = `project:
     - id: ci.passed
       category: delivery
       description: "CI pipeline completed successfully"
+    - id: code.committed
+      category: delivery
+      description: "Commit referencing the task pushed"
     - id: review.approved
       category: delivery
       description: "Code review approved"
@@ -434,6 +1285,9 @@ const defaultTemplate = `project:
     - id: security.ok
       category: security
       description: "Security checks passed"
+    - id: provenance.verified
+      category: security
+      description: "SLSA build provenance recorded for the artifacts"
     - id: iteration.approved
       category: iteration
       description: "Iteration approved"
@@ -471,10 +1325,23 @@ const defaultTemplate = `project:
         - project.config.read
         - project.status.read
         - project.events.read
+        - decision.list
       project.admin:
         - project.create
+        - org.create
         - project.update
         - project.delete
+        - project.rename
+        - actor.scrub
+        - project.sync
+        - project.events.compact
+        - projection.rebuild
+        - project.verify
+        - force.approve
+        - events.audit.read
+        - task.revert
+        - webhook.manage
+        - digest.send
       task.viewer:
         - task.list
         - task.read
@@ -486,16 +1353,24 @@ const defaultTemplate = `project:
         - task.update
         - task.claim
         - task.release
+        - task.time.log
       task.executor:
         - task.done
       iteration.viewer:
         - iteration.list
+        - milestone.list
+        - release.list
       iteration.writer:
         - iteration.create
         - iteration.list
         - iteration.set_status
+        - milestone.create
+        - milestone.list
+        - release.create
+        - release.list
       decision.writer:
         - decision.create
+        - decision.list
       attestation.viewer:
         - attestation.list
       attestation.writer:
@@ -538,6 +1413,8 @@ const defaultTemplate = `project:
           - force.use
         can_attest:
           - ci.passed
+          - code.committed
+          - provenance.verified
           - review.approved
           - acceptance.passed
           - responsibility.accepted
@@ -578,6 +1455,8 @@ const defaultTemplate = `project:
           - attestation.writer
         can_attest:
           - ci.passed
+          - code.committed
+          - provenance.verified
       reviewer:
         description: "Reviews work and approves gates"
         grants:
@@ -612,6 +1491,7 @@ const defaultTemplate = `project:
           - attestation.writer
         can_attest:
           - security.ok
+          - provenance.verified
       observer:
         description: "Read-only observer"
         grants:
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultKind is the project kind used when none is given.
+const DefaultKind = "software"
+
+// legacyKinds maps kind names stored by older releases to their built-in kind.
+var legacyKinds = map[string]string{
+	"software-project": "software",
+}
+
+// kindTaskTypes are the task types each built-in project kind's policies are
+// built around. A config used for a project of that kind must keep a done
+// policy for each of them.
+var kindTaskTypes = map[string][]string{
+	"software": {"feature", "bug"},
+	"research": {"experiment"},
+	"ops":      {"incident", "change"},
+	"content":  {"article"},
+}
+
+// Kinds lists the built-in project kinds. Each kind's starter config is the
+// preset of the same name.
+func Kinds() []string {
+	return sortedKeys(kindTaskTypes)
+}
+
+// NormalizeKind resolves a project kind name, defaulting an empty name to
+// DefaultKind.
+func NormalizeKind(kind string) (string, error) {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		return DefaultKind, nil
+	}
+	if k, ok := legacyKinds[kind]; ok {
+		return k, nil
+	}
+	if _, ok := kindTaskTypes[kind]; !ok {
+		return "", fmt.Errorf("invalid project kind %s (available: %v)", kind, Kinds())
+	}
+	return kind, nil
+}
+
+// ForKind returns the validated starter config for a project of the kind.
+func ForKind(kind, projectID string) (*Config, error) {
+	kind, err := NormalizeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	data, err := PresetYAML(kind, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return FromYAML([]byte(data))
+}
+
+// CheckKind reports whether the config keeps the policy defaults of a
+// project kind: every task type the kind is built around exists and
+// requires at least one attestation to be done.
+func (c *Config) CheckKind(kind string) error {
+	kind, err := NormalizeKind(kind)
+	if err != nil {
+		return err
+	}
+	for _, taskType := range kindTaskTypes[kind] {
+		tt, ok := c.Project.TaskTypes[taskType]
+		if !ok {
+			return fmt.Errorf("%s projects need task type %s", kind, taskType)
+		}
+		if done, ok := tt.Policies["done"]; !ok || len(done.All) == 0 {
+			return fmt.Errorf("%s projects need a done policy for task type %s", kind, taskType)
+		}
+	}
+	return nil
+}
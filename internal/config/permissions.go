@@ -0,0 +1,60 @@
+package config
+
+// Permissions returns the permission catalog: every permission id the engine
+// checks, with its description. Permission sets may only grant these.
+func Permissions() map[string]string {
+	return map[string]string{
+		"project.create":         "Create project",
+		"org.create":             "Create organization",
+		"project.list":           "List projects",
+		"project.read":           "Read project",
+		"project.update":         "Update project",
+		"project.delete":         "Delete project",
+		"project.rename":         "Rename project",
+		"project.sync":           "Sync project with a remote server",
+		"project.config.read":    "Read project config",
+		"project.status.read":    "Read project status",
+		"project.events.read":    "Read project events",
+		"events.audit.read":      "Read audit events",
+		"project.events.compact": "Compact project events",
+		"projection.rebuild":     "Rebuild projections",
+		"project.verify":         "Flag non-compliant tasks",
+		"actor.mission.read":     "Read actor mission",
+		"actor.mission.list":     "List actor missions",
+		"actor.mission.write":    "Update actor mission",
+		"actor.mission.delete":   "Delete actor mission",
+		"actor.scrub":            "Scrub actor personal data",
+		"validation.create":      "Create validation",
+		"validation.read":        "Read validation",
+		"validation.list":        "List validations",
+		"validation.update":      "Update validation",
+		"task.create":            "Create task",
+		"task.list":              "List tasks",
+		"task.read":              "Read task",
+		"task.next":              "Read next task",
+		"task.tree":              "Read task tree",
+		"task.validation.read":   "Read task validation",
+		"task.update":            "Update task",
+		"task.done":              "Complete task",
+		"task.claim":             "Claim task",
+		"task.release":           "Release task",
+		"task.time.log":          "Log time on task",
+		"task.revert":            "Revert task transition",
+		"iteration.create":       "Create iteration",
+		"iteration.list":         "List iterations",
+		"iteration.set_status":   "Update iteration status",
+		"milestone.create":       "Create milestone",
+		"milestone.list":         "List milestones",
+		"decision.create":        "Create decision",
+		"decision.list":          "List decisions",
+		"attestation.add":        "Add attestation",
+		"attestation.list":       "List attestations",
+		"rbac.manage":            "Manage RBAC",
+		"force.use":              "Use force flag",
+		"force.approve":          "Approve force requests",
+		"webhook.manage":         "Inspect, test and replay webhook deliveries",
+		"digest.send":            "Email the project digest",
+		"release.create":         "Record iteration release",
+		"release.list":           "List releases",
+	}
+}
@@ -0,0 +1,294 @@
+package config
+
+import "fmt"
+
+// presetTemplates are the starter workline.yml files offered by wl init.
+// Each takes the project id as its only format argument.
+var presetTemplates = map[string]string{
+	"software": defaultTemplate,
+	"research": researchTemplate,
+	"ops":      opsTemplate,
+	"content":  contentTemplate,
+}
+
+// Presets lists the built-in starter config names.
+func Presets() []string {
+	return sortedKeys(presetTemplates)
+}
+
+// PresetYAML returns the starter workline.yml for a preset.
+func PresetYAML(name, projectID string) (string, error) {
+	tmpl, ok := presetTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("invalid preset %s (available: %v)", name, Presets())
+	}
+	return fmt.Sprintf(tmpl, projectID), nil
+}
+
+// presetPermissions is the permission-set catalog shared by the research,
+// ops and content presets.
+const presetPermissions = `  rbac:
+    permissions:
+      project.viewer:
+        - project.list
+        - project.read
+        - project.config.read
+        - project.status.read
+        - project.events.read
+        - decision.list
+      project.admin:
+        - project.create
+        - org.create
+        - project.update
+        - project.delete
+        - project.rename
+        - actor.scrub
+        - project.sync
+        - project.events.compact
+        - projection.rebuild
+        - project.verify
+        - force.approve
+        - events.audit.read
+        - task.revert
+        - webhook.manage
+        - digest.send
+      task.viewer:
+        - task.list
+        - task.read
+        - task.next
+        - task.tree
+        - task.validation.read
+      task.writer:
+        - task.create
+        - task.update
+        - task.claim
+        - task.release
+        - task.time.log
+      task.executor:
+        - task.done
+      iteration.viewer:
+        - iteration.list
+        - milestone.list
+        - release.list
+      iteration.writer:
+        - iteration.create
+        - iteration.list
+        - iteration.set_status
+        - milestone.create
+        - milestone.list
+        - release.create
+        - release.list
+      decision.writer:
+        - decision.create
+        - decision.list
+      attestation.viewer:
+        - attestation.list
+      attestation.writer:
+        - attestation.add
+        - attestation.list
+      validation.viewer:
+        - validation.read
+        - validation.list
+      validation.writer:
+        - validation.create
+        - validation.update
+      actor.mission.viewer:
+        - actor.mission.read
+        - actor.mission.list
+      actor.mission.writer:
+        - actor.mission.write
+        - actor.mission.delete
+      rbac.admin:
+        - rbac.manage
+      force.use:
+        - force.use
+`
+
+const researchTemplate = `project:
+  id: %s
+  task_types:
+    experiment:
+      policies:
+        ready:
+          all: [hypothesis.defined]
+        done:
+          all: [results.reproduced, peer.reviewed, responsibility.accepted]
+    analysis:
+      policies:
+        done:
+          all: [data.reviewed, peer.reviewed]
+    writeup:
+      policies:
+        done:
+          all: [peer.reviewed, responsibility.accepted]
+    technical:
+      policies:
+        done:
+          all: [peer.reviewed]
+  iteration_types:
+    standard:
+      policies:
+        validation:
+          all: [iteration.approved]
+  attestations:
+    - id: hypothesis.defined
+      category: planning
+      description: "Hypothesis and success criteria written down"
+    - id: data.reviewed
+      category: analysis
+      description: "Data sources and cleaning reviewed"
+    - id: results.reproduced
+      category: analysis
+      description: "Results reproduced from a clean run"
+    - id: peer.reviewed
+      category: review
+      description: "Peer review approved"
+    - id: responsibility.accepted
+      category: responsibility
+      description: "Human accepts the conclusions and their impact"
+    - id: iteration.approved
+      category: iteration
+      description: "Iteration approved"
+` + presetPermissions + `    roles:
+      owner:
+        description: "Project owner"
+        grants: [project.viewer, project.admin, task.viewer, task.writer, task.executor, iteration.viewer, iteration.writer, decision.writer, attestation.writer, validation.viewer, validation.writer, actor.mission.viewer, actor.mission.writer, rbac.admin, force.use]
+        can_attest: [hypothesis.defined, data.reviewed, results.reproduced, peer.reviewed, responsibility.accepted, iteration.approved]
+      researcher:
+        description: "Runs experiments and analyses"
+        grants: [project.viewer, task.viewer, task.writer, task.executor, iteration.viewer, decision.writer, attestation.writer]
+        can_attest: [hypothesis.defined, results.reproduced]
+      reviewer:
+        description: "Reviews methods and findings"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.writer, validation.viewer, validation.writer]
+        can_attest: [data.reviewed, peer.reviewed, iteration.approved]
+      observer:
+        description: "Read-only observer"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.viewer]
+`
+
+const opsTemplate = `project:
+  id: %s
+  task_types:
+    incident:
+      policies:
+        done:
+          all: [service.restored, postmortem.written]
+    change:
+      policies:
+        ready:
+          all: [change.approved]
+        done:
+          all: [change.verified, runbook.updated]
+    maintenance:
+      policies:
+        done:
+          all: [change.verified]
+    technical:
+      policies:
+        done:
+          all: [change.verified]
+  iteration_types:
+    standard:
+      policies:
+        validation:
+          all: [iteration.approved]
+  attestations:
+    - id: change.approved
+      category: planning
+      description: "Change reviewed and approved, rollback plan known"
+    - id: change.verified
+      category: delivery
+      description: "Change verified in production"
+    - id: runbook.updated
+      category: docs
+      description: "Runbook reflects the change"
+    - id: service.restored
+      category: delivery
+      description: "Service restored and monitored"
+    - id: postmortem.written
+      category: review
+      description: "Postmortem written and reviewed"
+    - id: iteration.approved
+      category: iteration
+      description: "Iteration approved"
+` + presetPermissions + `    roles:
+      owner:
+        description: "Project owner"
+        grants: [project.viewer, project.admin, task.viewer, task.writer, task.executor, iteration.viewer, iteration.writer, decision.writer, attestation.writer, validation.viewer, validation.writer, actor.mission.viewer, actor.mission.writer, rbac.admin, force.use]
+        can_attest: [change.approved, change.verified, runbook.updated, service.restored, postmortem.written, iteration.approved]
+      operator:
+        description: "Handles incidents and runs changes"
+        grants: [project.viewer, task.viewer, task.writer, task.executor, iteration.viewer, attestation.writer]
+        can_attest: [change.verified, runbook.updated, service.restored]
+      approver:
+        description: "Approves changes and postmortems"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.writer, validation.viewer, validation.writer]
+        can_attest: [change.approved, postmortem.written, iteration.approved]
+      observer:
+        description: "Read-only observer"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.viewer]
+`
+
+const contentTemplate = `project:
+  id: %s
+  task_types:
+    article:
+      policies:
+        ready:
+          all: [brief.approved]
+        done:
+          all: [copy.edited, facts.checked, responsibility.accepted]
+    media:
+      policies:
+        done:
+          all: [copy.edited, rights.cleared]
+    revision:
+      policies:
+        done:
+          all: [copy.edited]
+    technical:
+      policies:
+        done:
+          all: [copy.edited]
+  iteration_types:
+    standard:
+      policies:
+        validation:
+          all: [iteration.approved]
+  attestations:
+    - id: brief.approved
+      category: planning
+      description: "Brief, audience and angle agreed"
+    - id: copy.edited
+      category: review
+      description: "Copy edited and style checked"
+    - id: facts.checked
+      category: review
+      description: "Facts and sources verified"
+    - id: rights.cleared
+      category: review
+      description: "Image, audio and quote rights cleared"
+    - id: responsibility.accepted
+      category: responsibility
+      description: "Human accepts publishing the piece"
+    - id: iteration.approved
+      category: iteration
+      description: "Iteration approved"
+` + presetPermissions + `    roles:
+      owner:
+        description: "Project owner"
+        grants: [project.viewer, project.admin, task.viewer, task.writer, task.executor, iteration.viewer, iteration.writer, decision.writer, attestation.writer, validation.viewer, validation.writer, actor.mission.viewer, actor.mission.writer, rbac.admin, force.use]
+        can_attest: [brief.approved, copy.edited, facts.checked, rights.cleared, responsibility.accepted, iteration.approved]
+      writer:
+        description: "Drafts and revises pieces"
+        grants: [project.viewer, task.viewer, task.writer, task.executor, iteration.viewer, attestation.writer]
+        can_attest: [rights.cleared]
+      editor:
+        description: "Edits, fact-checks and approves briefs"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.writer, validation.viewer, validation.writer]
+        can_attest: [brief.approved, copy.edited, facts.checked, iteration.approved]
+      observer:
+        description: "Read-only observer"
+        grants: [project.viewer, task.viewer, iteration.viewer, attestation.viewer]
+`
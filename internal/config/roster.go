@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Roster is a team roster for `wl rbac apply`: the actors holding project
+// roles and, optionally, the roles allowed to issue each attestation kind.
+// Applying it makes the project's grants match the file exactly; a roster
+// without attestation_authorities leaves them as they are.
+type Roster struct {
+	Actors                 []RosterActor       `yaml:"actors" json:"actors"`
+	AttestationAuthorities map[string][]string `yaml:"attestation_authorities,omitempty" json:"attestation_authorities,omitempty"`
+}
+
+type RosterActor struct {
+	ID    string   `yaml:"id" json:"id"`
+	Roles []string `yaml:"roles" json:"roles"`
+}
+
+// Validate rejects actors without an id, listed twice, or without roles.
+func (r Roster) Validate() error {
+	seen := map[string]bool{}
+	for i, a := range r.Actors {
+		id := strings.TrimSpace(a.ID)
+		if id == "" {
+			return fmt.Errorf("invalid roster: actors[%d] missing id", i)
+		}
+		if seen[id] {
+			return fmt.Errorf("invalid roster: actor %s listed twice", id)
+		}
+		seen[id] = true
+		if len(a.Roles) == 0 {
+			return fmt.Errorf("invalid roster: actor %s has no roles", id)
+		}
+	}
+	for kind, roles := range r.AttestationAuthorities {
+		if strings.TrimSpace(kind) == "" {
+			return errors.New("invalid roster: attestation_authorities has an empty kind")
+		}
+		if len(roles) == 0 {
+			return fmt.Errorf("invalid roster: attestation kind %s has no roles", kind)
+		}
+	}
+	return nil
+}
+
+// RosterFromFile reads and validates a YAML roster.
+func RosterFromFile(path string) (Roster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Roster{}, err
+	}
+	var roster Roster
+	if err := yaml.Unmarshal(data, &roster); err != nil {
+		return Roster{}, fmt.Errorf("invalid roster yaml: %w", err)
+	}
+	return roster, roster.Validate()
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate resolves secret references in a config value: a value of the
+// form file://<path> is replaced by the file contents (without the trailing
+// newline) and every ${NAME} by the environment variable NAME. Unset
+// variables and unreadable files are errors.
+func Interpolate(value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, "file://"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret file %s: %w", path, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// ResolveSecrets returns a copy of the config with every ${ENV} and file://
+// reference resolved. The stored config keeps the references, so secrets
+// never reach the database; resolve only the copy the engine runs with.
+func (c *Config) ResolveSecrets() (*Config, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var out Config
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return nil, err
+	}
+	out.lines = c.lines
+	v := &validator{lines: c.lines}
+	interpolateValue(reflect.ValueOf(&out).Elem(), "", v)
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+	return &out, nil
+}
+
+func interpolateValue(v reflect.Value, path string, errs *validator) {
+	switch v.Kind() {
+	case reflect.String:
+		resolved, err := Interpolate(v.String())
+		if err != nil {
+			errs.addf(path, "%s: %s", path, err)
+			return
+		}
+		v.SetString(resolved)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), path, errs)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		interpolateValue(elem, path, errs)
+		v.Set(elem)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			interpolateValue(v.Field(i), child, errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			interpolateValue(elem, fmt.Sprintf("%s.%v", path, iter.Key()), errs)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is one config problem. Path is the dotted location in
+// workline.yml; Line is set when the config was parsed from YAML.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// ValidationErrors is every problem found by Config.Validate.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type validator struct {
+	lines map[string]int
+	errs  ValidationErrors
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Line: v.line(path), Message: fmt.Sprintf(format, args...)})
+}
+
+// line returns the YAML line of path, falling back to its nearest parent.
+func (v *validator) line(path string) int {
+	for path != "" {
+		if n, ok := v.lines[path]; ok {
+			return n
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return 0
+}
+
+func (v *validator) checkPolicies(path, owner string, policies map[string]PolicyRule, attestationKinds map[string]bool) {
+	for _, name := range sortedKeys(policies) {
+		policyPath := path + "." + name
+		if strings.TrimSpace(name) == "" {
+			v.addf(policyPath, "%s has empty policy name", owner)
+		}
+		for i, kind := range policies[name].All {
+			kindPath := fmt.Sprintf("%s.all[%d]", policyPath, i)
+			if kind == "" {
+				v.addf(kindPath, "%s policy %s has empty attestation kind", owner, name)
+				continue
+			}
+			if len(attestationKinds) > 0 && !attestationKinds[kind] {
+				v.addf(kindPath, "%s policy %s requires unknown attestation kind %s", owner, name, kind)
+			}
+		}
+	}
+}
+
+// collectLines records the line of every mapping key and sequence item
+// under node, keyed by dotted path with [i] for sequence indexes.
+func collectLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectLines(child, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := key.Value
+			if path != "" {
+				child = path + "." + key.Value
+			}
+			lines[child] = key.Line
+			collectLines(node.Content[i+1], child, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			lines[child] = item.Line
+			collectLines(item, child, lines)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// HotQuery is a query on a hot read path, in the shape the repo runs it.
+type HotQuery struct {
+	Name  string
+	SQL   string
+	Args  []any
+	Notes string
+}
+
+// HotQueries are the reads every request or list page runs. Keep them in step
+// with the repo queries they mirror.
+var HotQueries = []HotQuery{
+	{
+		Name:  "task-list",
+		SQL:   `SELECT id FROM tasks WHERE project_id=? AND status IN (?) ORDER BY created_at DESC, id DESC LIMIT ?`,
+		Args:  []any{"", "", 50},
+		Notes: "default task list page filtered by status",
+	},
+	{
+		Name: "task-get",
+		SQL:  `SELECT id FROM tasks WHERE id=?`,
+		Args: []any{""},
+	},
+	{
+		Name: "task-dependencies",
+		SQL:  `SELECT depends_on_task_id FROM task_deps WHERE task_id=?`,
+		Args: []any{""},
+	},
+	{
+		Name:  "entity-attestations",
+		SQL:   `SELECT id FROM attestations WHERE entity_kind=? AND entity_id=? AND kind=? ORDER BY ts DESC, id DESC`,
+		Args:  []any{"task", "", ""},
+		Notes: "attestation checks on task completion and missing_attestation filters",
+	},
+	{
+		Name: "project-events",
+		SQL:  `SELECT id FROM events WHERE project_id=? ORDER BY id DESC LIMIT ?`,
+		Args: []any{"", 50},
+	},
+	{
+		Name:  "latest-project-event",
+		SQL:   `SELECT COALESCE(MAX(id),0) FROM events WHERE project_id=?`,
+		Args:  []any{""},
+		Notes: "ETags of list reads",
+	},
+	{
+		Name:  "rbac-version",
+		SQL:   `SELECT COALESCE(MAX(id),0) FROM events WHERE entity_kind='rbac' AND (type LIKE 'rbac.%' OR type LIKE 'org.%')`,
+		Notes: "permission cache validation",
+	},
+}
+
+// QueryPlan is the EXPLAIN QUERY PLAN output of a hot query. FullScans lists
+// the steps that read a whole table instead of using an index.
+type QueryPlan struct {
+	Name      string   `json:"name"`
+	SQL       string   `json:"sql"`
+	Notes     string   `json:"notes,omitempty"`
+	Steps     []string `json:"steps"`
+	FullScans []string `json:"full_scans,omitempty"`
+}
+
+// Analyze explains every HotQuery against conn's schema.
+func Analyze(ctx context.Context, conn *sql.DB) ([]QueryPlan, error) {
+	var plans []QueryPlan
+	for _, q := range HotQueries {
+		plan := QueryPlan{Name: q.Name, SQL: q.SQL, Notes: q.Notes}
+		rows, err := conn.QueryContext(ctx, "EXPLAIN QUERY PLAN "+q.SQL, q.Args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			plan.Steps = append(plan.Steps, detail)
+			if strings.HasPrefix(detail, "SCAN ") && !strings.Contains(detail, " USING ") {
+				plan.FullScans = append(plan.FullScans, detail)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const backupPrefix = "workline-"
+
+// Backup writes a consistent snapshot of the open database to out. It runs
+// inside a read transaction, so other connections (a running `wl serve`) can
+// keep writing while it is taken.
+func Backup(ctx context.Context, conn *sql.DB, out string) error {
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if dir := filepath.Dir(out); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, `VACUUM INTO ?`, out); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the workspace database with a backup. The backup is
+// checked for integrity first and swapped in with a rename, so a failed
+// restore leaves the current database untouched. Nothing may hold the
+// database open while it runs.
+func Restore(workspace, src string) error {
+	if err := verifyBackup(src); err != nil {
+		return err
+	}
+	dst := dbPath(workspace)
+	if _, err := EnsureWorkspace(workspace); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".restore-*.db")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	in, err := os.Open(src)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	_, err = io.Copy(tmp, in)
+	in.Close()
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	// Stale journal files belong to the replaced database.
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dst + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func verifyBackup(src string) error {
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	conn, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", src))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var result string
+	if err := conn.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%s is not a workline database: %w", src, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%s failed integrity check: %s", src, result)
+	}
+	var tables int
+	if err := conn.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type='table' AND name='schema_version'`).Scan(&tables); err != nil {
+		return err
+	}
+	if tables == 0 {
+		return fmt.Errorf("%s is not a workline database", src)
+	}
+	return nil
+}
+
+// BackupName returns the file name used for periodic backups taken at t.
+func BackupName(t time.Time) string {
+	return backupPrefix + t.UTC().Format("20060102T150405Z") + ".db"
+}
+
+// PruneBackups deletes the oldest periodic backups in dir, keeping keep.
+func PruneBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), ".db") {
+			names = append(names, e.Name())
+		}
+	}
+	// Names embed a sortable UTC timestamp.
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
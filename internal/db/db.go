@@ -13,6 +13,8 @@ const defaultDBName = "workline.db"
 
 type Config struct {
 	Workspace string
+	// Trace, when set, receives every statement with its duration.
+	Trace TraceFunc
 }
 
 func dbPath(workspace string) string {
@@ -37,12 +39,33 @@ func Open(cfg Config) (*sql.DB, error) {
 		return nil, err
 	}
 	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", dbPath(cfg.Workspace))
-	conn, err := sql.Open("sqlite", dsn)
+	var conn *sql.DB
+	var err error
+	if cfg.Trace != nil {
+		conn, err = openTraced(dsn, cfg.Trace)
+	} else {
+		conn, err = sql.Open("sqlite", dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	return conn, nil
+}
+
+// OpenMemory opens a private in-memory database with foreign keys on. The
+// data lives only as long as the handle's single connection, so the pool
+// never closes or recycles it.
+func OpenMemory() (*sql.DB, error) {
+	conn, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, err
 	}
 	conn.SetMaxOpenConns(1)
 	conn.SetMaxIdleConns(1)
+	conn.SetConnMaxLifetime(0)
+	conn.SetConnMaxIdleTime(0)
 	return conn, nil
 }
 
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+)
+
+// TraceFunc receives every statement run on a traced database with how
+// long it took.
+type TraceFunc func(query string, elapsed time.Duration, err error)
+
+// openTraced opens dsn with the sqlite driver, reporting statements to
+// trace.
+func openTraced(dsn string, trace TraceFunc) (*sql.DB, error) {
+	base, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := base.Driver()
+	base.Close()
+	return sql.OpenDB(traceConnector{drv: drv, dsn: dsn, trace: trace}), nil
+}
+
+type traceConnector struct {
+	drv   driver.Driver
+	dsn   string
+	trace TraceFunc
+}
+
+func (c traceConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.drv.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return traceConn{Conn: conn, trace: c.trace}, nil
+}
+
+func (c traceConnector) Driver() driver.Driver { return c.drv }
+
+// traceConn times the statements of a sqlite connection, which implements
+// the context-aware driver interfaces.
+type traceConn struct {
+	driver.Conn
+	trace TraceFunc
+}
+
+func (c traceConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	res, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	c.trace(query, time.Since(start), err)
+	return res, err
+}
+
+func (c traceConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	c.trace(query, time.Since(start), err)
+	return rows, err
+}
+
+func (c traceConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c traceConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c traceConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
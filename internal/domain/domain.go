@@ -1,5 +1,101 @@
 package domain
 
+type Org struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+type OrgMember struct {
+	OrgID   string `json:"org_id"`
+	ActorID string `json:"actor_id"`
+	Role    string `json:"role" enum:"owner,admin,member"`
+}
+
+type Role struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	// ProjectID is the project owning a custom role; empty for the seeded
+	// roles every project shares.
+	ProjectID   string   `json:"project_id,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+type Permission struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+}
+
+// RoleMember is an actor holding a role, either directly on the project or through its org.
+type RoleMember struct {
+	ActorID string `json:"actor_id"`
+	Scope   string `json:"scope" enum:"project,org"`
+	// ExpiresAt is set on time-boxed project grants.
+	ExpiresAt *string `json:"expires_at,omitempty" format:"date-time"`
+}
+
+// RoleGrant is a project role granted to an actor, for good or until
+// ExpiresAt.
+type RoleGrant struct {
+	ActorID   string  `json:"actor_id"`
+	RoleID    string  `json:"role_id"`
+	ExpiresAt *string `json:"expires_at,omitempty" format:"date-time"`
+}
+
+type RoleDetail struct {
+	Role
+	AttestationKinds []string     `json:"attestation_kinds"`
+	Members          []RoleMember `json:"members"`
+}
+
+// RosterChange is one difference between a team roster and the project's
+// grants: an actor registered, a role granted or revoked, or an attestation
+// authority allowed or denied.
+type RosterChange struct {
+	Op              string `json:"op" enum:"add,remove"`
+	Type            string `json:"type" enum:"actor,role,attestation_authority"`
+	ActorID         string `json:"actor_id,omitempty"`
+	RoleID          string `json:"role_id,omitempty"`
+	AttestationKind string `json:"attestation_kind,omitempty"`
+}
+
+type RosterResult struct {
+	ProjectID string         `json:"project_id"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+	Changes   []RosterChange `json:"changes"`
+}
+
+// PermissionSimulation explains whether an actor may perform an action in a
+// project: each check the engine would run, whether it passes, and the roles
+// behind the outcome.
+type PermissionSimulation struct {
+	ProjectID string            `json:"project_id"`
+	ActorID   string            `json:"actor_id"`
+	Action    string            `json:"action"`
+	Kind      string            `json:"kind,omitempty"`
+	Allowed   bool              `json:"allowed"`
+	Checks    []PermissionCheck `json:"checks"`
+}
+
+// PermissionCheck is one condition of a simulated action. GrantedBy lists the
+// actor's grants satisfying it; Candidates, when it fails, the roles that
+// would.
+type PermissionCheck struct {
+	Check      string       `json:"check" enum:"actor_active,permission,attestation_authority,done_role,project_active"`
+	Passed     bool         `json:"passed"`
+	Detail     string       `json:"detail"`
+	GrantedBy  []RoleSource `json:"granted_by,omitempty"`
+	Candidates []string     `json:"candidates,omitempty"`
+}
+
+// RoleSource is a role an actor holds in a project and the scope granting
+// it.
+type RoleSource struct {
+	RoleID    string  `json:"role_id"`
+	Scope     string  `json:"scope" enum:"project,org"`
+	ExpiresAt *string `json:"expires_at,omitempty" format:"date-time"`
+}
+
 type Project struct {
 	ID          string `json:"id"`
 	OrgID       string `json:"org_id"`
@@ -10,11 +106,12 @@ type Project struct {
 }
 
 type Iteration struct {
-	ID        string `json:"id"`
-	ProjectID string `json:"project_id"`
-	Goal      string `json:"goal"`
-	Status    string `json:"status" enum:"pending,running,delivered,validated,rejected"`
-	CreatedAt string `json:"created_at" format:"date-time"`
+	ID        string   `json:"id"`
+	ProjectID string   `json:"project_id"`
+	Goal      string   `json:"goal"`
+	Status    string   `json:"status" enum:"pending,running,delivered,validated,rejected"`
+	Capacity  *float64 `json:"capacity,omitempty"`
+	CreatedAt string   `json:"created_at" format:"date-time"`
 }
 
 type Task struct {
@@ -23,22 +120,42 @@ type Task struct {
 	IterationID              *string  `json:"iteration_id,omitempty"`
 	ParentID                 *string  `json:"parent_id,omitempty"`
 	Type                     string   `json:"type"`
+	Component                string   `json:"component,omitempty"`
 	Title                    string   `json:"title"`
 	Description              string   `json:"description,omitempty"`
-	Status                   string   `json:"status" enum:"planned,ready,in_progress,review,done,rejected,canceled"`
+	Status                   string   `json:"status"`
 	AssigneeID               *string  `json:"assignee_id,omitempty"`
 	Priority                 *int     `json:"priority,omitempty"`
+	Estimate                 *float64 `json:"estimate,omitempty"`
 	WorkOutcomesJSON         *string  `json:"work_outcomes_json,omitempty"`
 	RequiredAttestationsJSON *string  `json:"required_attestations_json,omitempty"`
 	DependsOn                []string `json:"depends_on,omitempty"`
-	CreatedAt                string   `json:"created_at" format:"date-time"`
-	UpdatedAt                string   `json:"updated_at" format:"date-time"`
-	CompletedAt              *string  `json:"completed_at,omitempty" format:"date-time"`
+	// Relations are the task's typed links to other tasks, those it is the
+	// subject of.
+	Relations []TaskRelation `json:"relations,omitempty"`
+	// ExternalRefs maps an external system to the task's id there, such as
+	// jira: ABC-123 or github: org/repo#45.
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+	CreatedAt    string            `json:"created_at" format:"date-time"`
+	UpdatedAt    string            `json:"updated_at" format:"date-time"`
+	CompletedAt  *string           `json:"completed_at,omitempty" format:"date-time"`
+	// DueAt is when the task should be done, set explicitly or from the
+	// project's SLA rules at creation.
+	DueAt *string `json:"due_at,omitempty" format:"date-time"`
+	// Stale is set once the staleness sweep finds the task idle for
+	// project.staleness.days, until its next update.
+	Stale bool `json:"stale,omitempty"`
+	// Warnings carries non-blocking notices from the write that returned the
+	// task, such as an iteration going over capacity. Not persisted.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type Decision struct {
-	ID               string `json:"id"`
-	ProjectID        string `json:"project_id"`
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	// Number counts the project's decisions in creation order; it names
+	// exported ADR files.
+	Number           int    `json:"number"`
 	Title            string `json:"title"`
 	ContextJSON      string `json:"context_json,omitempty"`
 	Decision         string `json:"decision"`
@@ -48,6 +165,14 @@ type Decision struct {
 	CreatedAt        string `json:"created_at"`
 }
 
+// EntityRef is a task, iteration or decision matching what a user typed in
+// place of its id. Match is id (exact), prefix (of the id) or title.
+type EntityRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Match string `json:"match"`
+}
+
 type Lease struct {
 	TaskID     string `json:"task_id"`
 	OwnerID    string `json:"owner_id"`
@@ -64,6 +189,407 @@ type Attestation struct {
 	ActorID     string `json:"actor_id"`
 	TS          string `json:"ts" format:"date-time"`
 	PayloadJSON string `json:"payload_json,omitempty"`
+	// ExpiredAt is set once the sweeper finds the attestation past its
+	// kind's validity.
+	ExpiredAt *string `json:"expired_at,omitempty" format:"date-time"`
+}
+
+// Evidence is a file attached to an attestation; the bytes live in the blob store.
+type Evidence struct {
+	ID            string `json:"id"`
+	AttestationID string `json:"attestation_id"`
+	ProjectID     string `json:"project_id"`
+	Name          string `json:"name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	StorageKey    string `json:"-"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+}
+
+// IterationProgress compares planned and completed estimates with capacity.
+type IterationProgress struct {
+	IterationID     string   `json:"iteration_id"`
+	Unit            string   `json:"unit"`
+	Capacity        *float64 `json:"capacity,omitempty"`
+	PlannedPoints   float64  `json:"planned_points"`
+	CompletedPoints float64  `json:"completed_points"`
+	OverCapacity    bool     `json:"over_capacity"`
+}
+
+// Changelog lists an iteration's done tasks grouped by task type, for
+// release notes.
+type Changelog struct {
+	ProjectID   string             `json:"project_id"`
+	IterationID string             `json:"iteration_id"`
+	Goal        string             `json:"goal"`
+	Sections    []ChangelogSection `json:"sections"`
+}
+
+// ChangelogSection holds the done tasks of one type, in completion order.
+type ChangelogSection struct {
+	Type    string           `json:"type"`
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+// ChangelogEntry is a done task with the summary and links taken from its
+// work outcomes.
+type ChangelogEntry struct {
+	TaskID      string   `json:"task_id"`
+	Title       string   `json:"title"`
+	AssigneeID  *string  `json:"assignee_id,omitempty"`
+	CompletedAt string   `json:"completed_at" format:"date-time"`
+	Summary     string   `json:"summary,omitempty"`
+	Links       []string `json:"links,omitempty"`
+}
+
+// Milestone is a goal with a target date that spans iterations and tasks.
+type Milestone struct {
+	ID           string   `json:"id"`
+	ProjectID    string   `json:"project_id"`
+	Goal         string   `json:"goal"`
+	TargetDate   string   `json:"target_date" format:"date"`
+	IterationIDs []string `json:"iteration_ids"`
+	TaskIDs      []string `json:"task_ids"`
+	CreatedAt    string   `json:"created_at" format:"date-time"`
+}
+
+// Release is an artifact shipped from a validated iteration.
+type Release struct {
+	ID          string `json:"id"`
+	ProjectID   string `json:"project_id"`
+	IterationID string `json:"iteration_id"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Checksum    string `json:"checksum,omitempty"`
+	URL         string `json:"url,omitempty"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   string `json:"created_at" format:"date-time"`
+}
+
+// Board groups a project's tasks by status, one column per workflow state.
+type Board struct {
+	ProjectID string        `json:"project_id"`
+	StrictWIP bool          `json:"strict_wip"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// BoardColumn holds the tasks in one status, highest priority first.
+type BoardColumn struct {
+	Status string `json:"status"`
+	// WIPLimit is the configured work-in-progress limit, if any.
+	WIPLimit  *int   `json:"wip_limit,omitempty"`
+	Count     int    `json:"count"`
+	OverLimit bool   `json:"over_limit"`
+	Tasks     []Task `json:"tasks"`
+}
+
+// Escalation is a task's priority raised one level by a project.escalation
+// rule, done or due on the next sweep.
+type Escalation struct {
+	TaskID     string  `json:"task_id"`
+	Title      string  `json:"title"`
+	Type       string  `json:"type"`
+	Status     string  `json:"status"`
+	AssigneeID *string `json:"assignee_id,omitempty"`
+	From       int     `json:"from"`
+	To         int     `json:"to"`
+	// Since is when the task was created or last escalated.
+	Since     string `json:"since"`
+	AfterDays int    `json:"after_days"`
+}
+
+// MilestoneProgress counts the tasks a milestone covers: those linked directly
+// plus those in its linked iterations, ignoring canceled and rejected tasks.
+type MilestoneProgress struct {
+	MilestoneID         string  `json:"milestone_id"`
+	Goal                string  `json:"goal"`
+	TargetDate          string  `json:"target_date" format:"date"`
+	TotalTasks          int     `json:"total_tasks"`
+	DoneTasks           int     `json:"done_tasks"`
+	Percent             float64 `json:"percent"`
+	PlannedPoints       float64 `json:"planned_points"`
+	CompletedPoints     float64 `json:"completed_points"`
+	Iterations          int     `json:"iterations"`
+	ValidatedIterations int     `json:"validated_iterations"`
+	DaysLeft            int     `json:"days_left"`
+	Overdue             bool    `json:"overdue"`
+}
+
+// CalendarEntry is an all-day span on the project calendar: an iteration from
+// the day it started running to the day it was delivered or closed, or a
+// milestone on its target date. End is inclusive; Ongoing marks an iteration
+// still running, whose End is today.
+type CalendarEntry struct {
+	Kind     string `json:"kind" enum:"iteration,milestone"`
+	EntityID string `json:"entity_id"`
+	Summary  string `json:"summary"`
+	Status   string `json:"status,omitempty"`
+	Start    string `json:"start" format:"date"`
+	End      string `json:"end" format:"date"`
+	Ongoing  bool   `json:"ongoing,omitempty"`
+}
+
+// CalendarFeed is a token that reads one project's calendar feed as ActorID
+// and nothing else. Token is only set when the feed is created.
+type CalendarFeed struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	ActorID   string `json:"actor_id"`
+	Name      string `json:"name,omitempty"`
+	Token     string `json:"token,omitempty"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+// StatusReport summarizes a project over a reporting window: open or recently
+// changed iterations, tasks completed in the window with their attestations,
+// and open tasks waiting on unfinished dependencies.
+type StatusReport struct {
+	ProjectID  string            `json:"project_id"`
+	Since      string            `json:"since" format:"date-time"`
+	Until      string            `json:"until" format:"date-time"`
+	TaskCounts map[string]int    `json:"task_counts"`
+	Iterations []ReportIteration `json:"iterations"`
+	DoneTasks  []ReportTask      `json:"done_tasks"`
+	Blockers   []ReportBlocker   `json:"blockers"`
+}
+
+// ProjectOverview is one project's line in a portfolio status: its running
+// iteration, task counts by status and open tasks waiting on unfinished
+// dependencies.
+type ProjectOverview struct {
+	// Workspace is set when overviews span several workspaces.
+	Workspace     string         `json:"workspace,omitempty"`
+	OrgID         string         `json:"org_id"`
+	ProjectID     string         `json:"project_id"`
+	Status        string         `json:"status"`
+	Iteration     *string        `json:"iteration,omitempty"`
+	IterationGoal string         `json:"iteration_goal,omitempty"`
+	Tasks         int            `json:"tasks"`
+	TaskCounts    map[string]int `json:"task_counts"`
+	Blocked       int            `json:"blocked"`
+}
+
+// ReportIteration counts an iteration's tasks, ignoring canceled and rejected ones.
+type ReportIteration struct {
+	ID         string `json:"id"`
+	Goal       string `json:"goal"`
+	Status     string `json:"status"`
+	TotalTasks int    `json:"total_tasks"`
+	DoneTasks  int    `json:"done_tasks"`
+}
+
+// ReportTask is a task completed in the report window.
+type ReportTask struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	IterationID  string   `json:"iteration_id,omitempty"`
+	CompletedAt  string   `json:"completed_at" format:"date-time"`
+	Attestations []string `json:"attestations"`
+}
+
+// ReportBlocker is an open task with dependencies that are not done.
+type ReportBlocker struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Status    string   `json:"status"`
+	BlockedBy []string `json:"blocked_by"`
+}
+
+// Digest is the email summary of a project since the previous digest: tasks
+// completed, tasks that became blocked, policy overrides and forced moves, and
+// milestones coming due.
+type Digest struct {
+	ProjectID    string           `json:"project_id"`
+	Since        string           `json:"since" format:"date-time"`
+	Until        string           `json:"until" format:"date-time"`
+	Recipients   []string         `json:"recipients"`
+	DoneTasks    []ReportTask     `json:"done_tasks"`
+	NewlyBlocked []ReportBlocker  `json:"newly_blocked"`
+	Overrides    []DigestOverride `json:"overrides"`
+	Deadlines    []DigestDeadline `json:"deadlines"`
+}
+
+// DigestOverride is a policy override or forced change on a task.
+type DigestOverride struct {
+	EventID int64  `json:"event_id"`
+	Type    string `json:"type"`
+	TaskID  string `json:"task_id"`
+	Title   string `json:"title"`
+	ActorID string `json:"actor_id"`
+	TS      string `json:"ts" format:"date-time"`
+}
+
+// DigestDeadline is a milestone coming due with the iterations it still waits
+// on.
+type DigestDeadline struct {
+	MilestoneID string            `json:"milestone_id"`
+	Goal        string            `json:"goal"`
+	TargetDate  string            `json:"target_date" format:"date"`
+	Iterations  []ReportIteration `json:"iterations"`
+}
+
+// PolicyCheck says which policy governs a task, real or hypothetical, and
+// what done still needs.
+type PolicyCheck struct {
+	TaskType  string `json:"task_type"`
+	Component string `json:"component,omitempty"`
+	// Policy is the policy the config applies to the task type and component;
+	// empty when the type has none.
+	Policy string `json:"policy,omitempty"`
+	// Override is set when an existing task's requirements differ from
+	// Policy, after a manual override or a config change.
+	Override bool     `json:"override,omitempty"`
+	Required []string `json:"required"`
+	Present  []string `json:"present"`
+	Missing  []string `json:"missing"`
+	// Expired lists missing kinds whose attestations have all expired.
+	Expired []string `json:"expired"`
+	// Blockers are the other reasons done would be refused: unfinished
+	// dependencies or subtasks and rejected validations.
+	Blockers []string `json:"blockers"`
+	// Satisfied means every required attestation is present; done also
+	// needs Blockers to be empty.
+	Satisfied bool `json:"satisfied"`
+}
+
+// ComplianceReport lists the completed tasks of a project that fall short of
+// their required attestations or of the policy the config applies today.
+type ComplianceReport struct {
+	ProjectID string `json:"project_id"`
+	CheckedAt string `json:"checked_at" format:"date-time"`
+	// Checked counts the completed tasks examined.
+	Checked int `json:"checked"`
+	// Flagged is set when each non-compliant task got a compliance.flagged event.
+	Flagged      bool              `json:"flagged"`
+	NonCompliant []ComplianceIssue `json:"non_compliant"`
+}
+
+// ComplianceIssue is one non-compliant completed task.
+type ComplianceIssue struct {
+	TaskID      string `json:"task_id"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	CompletedAt string `json:"completed_at" format:"date-time"`
+	// Forced is set when the task was completed with the force flag.
+	Forced bool `json:"forced"`
+	// Missing are required kinds without an attestation valid at completion.
+	Missing []string `json:"missing"`
+	// Tightened are kinds the current policy requires that the task never
+	// had to provide and does not have.
+	Tightened []string `json:"tightened"`
+}
+
+// GateResult is the outcome of checking a task against the conditions a CI
+// pipeline or release script requires before going ahead.
+type GateResult struct {
+	TaskID string `json:"task_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Passed bool   `json:"passed"`
+	// Failures explain each unmet condition; empty when Passed.
+	Failures []string `json:"failures"`
+}
+
+// TimeEntry is time an actor logged against a task.
+type TimeEntry struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	ProjectID string `json:"project_id"`
+	ActorID   string `json:"actor_id"`
+	Minutes   int    `json:"minutes"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+// TaskRelation is a typed link between two tasks, read as "TaskID Type
+// RelatedTaskID", such as "B-2 duplicates B-1".
+type TaskRelation struct {
+	TaskID        string `json:"task_id"`
+	Type          string `json:"type" enum:"blocks,relates_to,duplicates,caused_by"`
+	RelatedTaskID string `json:"related_task_id"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+	// Closed is the status the duplicate was closed into by the request that
+	// added a duplicates relation. Not persisted.
+	Closed string `json:"closed,omitempty"`
+}
+
+// TaskGraph is a project's tasks as nodes and the links between them as
+// edges: parents, dependencies and typed relations.
+type TaskGraph struct {
+	ProjectID string          `json:"project_id"`
+	Nodes     []TaskGraphNode `json:"nodes"`
+	Edges     []TaskGraphEdge `json:"edges"`
+}
+
+type TaskGraphNode struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// TaskGraphEdge reads "From Type To", Type being subtask_of, depends_on or a
+// relation type.
+type TaskGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type" enum:"subtask_of,depends_on,blocks,relates_to,duplicates,caused_by"`
+}
+
+// IterationTime is the total time logged on tasks of an iteration. An empty
+// IterationID groups tasks outside any iteration.
+type IterationTime struct {
+	IterationID string `json:"iteration_id"`
+	Minutes     int    `json:"minutes"`
+}
+
+// TaskHistoryEntry is one step of a task's lifecycle. Entries come from the
+// event log, with attestations read from their table so they survive event
+// compaction; EventID is zero for those.
+type TaskHistoryEntry struct {
+	TS       string         `json:"ts" format:"date-time"`
+	Category string         `json:"category" enum:"created,status,policy,lease,attestation,outcomes,assignment,update,validation,time,sla"`
+	Type     string         `json:"type"`
+	ActorID  string         `json:"actor_id,omitempty"`
+	Summary  string         `json:"summary"`
+	EventID  int64          `json:"event_id,omitempty"`
+	Payload  map[string]any `json:"payload,omitempty"`
+}
+
+// AssigneeSuggestion is a candidate assignee for a task. OpenTasks counts the
+// actor's other assigned tasks not yet in a terminal state; CanAttest lists
+// the task's required attestation kinds the actor may record.
+type AssigneeSuggestion struct {
+	ActorID      string   `json:"actor_id"`
+	OpenTasks    int      `json:"open_tasks"`
+	LastActiveAt string   `json:"last_active_at,omitempty" format:"date-time"`
+	Roles        []string `json:"roles"`
+	CanAttest    []string `json:"can_attest"`
+}
+
+// ReadAuditEntry records one read of sensitive project data: the resource
+// (config, events or attestations) and what exactly was read.
+type ReadAuditEntry struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	TS        string `json:"ts" format:"date-time"`
+	ActorID   string `json:"actor_id"`
+	Resource  string `json:"resource" enum:"config,events,attestations"`
+	Target    string `json:"target,omitempty"`
+}
+
+// ActorScrub reports an actor scrubbed for a data-deletion request: the
+// pseudonym now standing in for it, how many rows were rewritten, the tasks
+// whose leases it lost and the projects whose event chain was rehashed.
+type ActorScrub struct {
+	Pseudonym         string   `json:"pseudonym"`
+	ScrubbedAt        string   `json:"scrubbed_at" format:"date-time"`
+	RowsRewritten     int      `json:"rows_rewritten"`
+	ReleasedTasks     []string `json:"released_tasks"`
+	RechainedProjects []string `json:"rechained_projects"`
 }
 
 type Event struct {
@@ -75,6 +601,126 @@ type Event struct {
 	EntityID   string `json:"entity_id,omitempty"`
 	ActorID    string `json:"actor_id"`
 	Payload    string `json:"payload_json"`
+	// PrevHash and Hash chain the event to its project's previous event;
+	// both are only read where the chain matters, such as archives.
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// EventChainHead is the latest hashed event of a project. Recording its hash
+// elsewhere lets an auditor later prove the history up to it was not altered.
+type EventChainHead struct {
+	ProjectID string `json:"project_id"`
+	EventID   int64  `json:"event_id"`
+	TS        string `json:"ts,omitempty" format:"date-time"`
+	Hash      string `json:"hash"`
+}
+
+// EventChainReport is the result of recomputing a project's event hash
+// chain. Unhashed counts events written before hashing was introduced;
+// Breaks lists every event whose hash or link does not check out.
+type EventChainReport struct {
+	ProjectID string `json:"project_id"`
+	Valid     bool   `json:"valid"`
+	Checked   int    `json:"checked"`
+	Unhashed  int    `json:"unhashed"`
+	// Compacted counts links that skip events removed by a compaction.
+	Compacted int               `json:"compacted"`
+	Head      EventChainHead    `json:"head"`
+	Breaks    []EventChainBreak `json:"breaks"`
+}
+
+// EventChainBreak is an event failing chain verification.
+type EventChainBreak struct {
+	EventID int64  `json:"event_id"`
+	Reason  string `json:"reason"`
+}
+
+// SyncReport is the outcome of syncing a project's tasks with a remote
+// server. Pushed and Pulled list the tasks changed on each side.
+type SyncReport struct {
+	ProjectID    string         `json:"project_id"`
+	Remote       string         `json:"remote"`
+	Pushed       []string       `json:"pushed"`
+	Pulled       []string       `json:"pulled"`
+	Conflicts    []SyncConflict `json:"conflicts"`
+	LocalCursor  int64          `json:"local_cursor"`
+	RemoteCursor int64          `json:"remote_cursor"`
+}
+
+// SyncConflict is a task field changed on both sides, or a push the server
+// rejected. Resolution is remote (the server's value won) or merged.
+type SyncConflict struct {
+	TaskID     string `json:"task_id"`
+	Field      string `json:"field"`
+	Local      any    `json:"local"`
+	Remote     any    `json:"remote"`
+	Resolution string `json:"resolution"`
+	// Reason is the server's error when it rejected the local value.
+	Reason string `json:"reason,omitempty"`
+}
+
+// QueuedCommand is a remote-mode command kept while its server was
+// unreachable. Op is task.create or task.update; Payload holds the task to
+// create or the values of Fields, and Base the task as the server should
+// have it when the command runs, against which replay detects conflicts. Status is pending,
+// conflict or rejected, the latter two explained by Reason.
+type QueuedCommand struct {
+	Seq       int64    `json:"seq"`
+	ProjectID string   `json:"project_id"`
+	Remote    string   `json:"remote"`
+	ActorID   string   `json:"actor_id"`
+	Op        string   `json:"op"`
+	TaskID    string   `json:"task_id"`
+	Fields    []string `json:"fields,omitempty"`
+	Payload   string   `json:"payload"`
+	Base      string   `json:"base,omitempty"`
+	Status    string   `json:"status"`
+	Reason    string   `json:"reason,omitempty"`
+	Signature string   `json:"signature"`
+	QueuedAt  string   `json:"queued_at"`
+}
+
+// QueueReplayReport is the outcome of replaying a remote's offline queue.
+// Applied lists the seqs the server took; Held lists the commands left in
+// the queue as conflicts or rejections.
+type QueueReplayReport struct {
+	ProjectID string          `json:"project_id"`
+	Remote    string          `json:"remote"`
+	Applied   []int64         `json:"applied"`
+	Held      []QueuedCommand `json:"held"`
+	Pending   int             `json:"pending"`
+}
+
+// OutboxMessage is an event queued for publishing to a message broker.
+type OutboxMessage struct {
+	ID            int64  `json:"id"`
+	EventID       int64  `json:"event_id"`
+	ProjectID     string `json:"project_id,omitempty"`
+	Topic         string `json:"topic"`
+	Key           string `json:"key"`
+	Payload       string `json:"payload_json"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at" format:"date-time"`
+}
+
+// HookDelivery is a webhook hook queued by a status transition, posted
+// after the transition commits.
+type HookDelivery struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	// Hook is the name of the project.hooks entry, if it has one. The secret
+	// and timeout of the webhook hook posting to URL apply when posting.
+	Hook          string `json:"hook,omitempty"`
+	URL           string `json:"url"`
+	Event         string `json:"event"`
+	Body          string `json:"body"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at" format:"date-time"`
 }
 
 type APIKey struct {
@@ -115,3 +761,149 @@ type Validation struct {
 	CreatedAt string   `json:"created_at" format:"date-time"`
 	UpdatedAt string   `json:"updated_at" format:"date-time"`
 }
+
+// Dashboard aggregates quality-gate metrics for a project.
+type Dashboard struct {
+	ProjectID string `json:"project_id"`
+	DoneTasks int    `json:"done_tasks"`
+	// CoveredTasks are done tasks completed without force whose required attestations are all present.
+	CoveredTasks        int                `json:"covered_tasks"`
+	AttestationCoverage float64            `json:"attestation_coverage"`
+	AvgLeadTimeHours    float64            `json:"avg_lead_time_hours"`
+	ValidationsDecided  int                `json:"validations_decided"`
+	ValidationsRejected int                `json:"validations_rejected"`
+	RejectionRate       float64            `json:"rejection_rate"`
+	Iterations          []IterationQuality `json:"iterations"`
+}
+
+type IterationQuality struct {
+	IterationID       string `json:"iteration_id"`
+	DoneTasks         int    `json:"done_tasks"`
+	ForcedCompletions int    `json:"forced_completions"`
+	PolicyOverrides   int    `json:"policy_overrides"`
+}
+
+// ConfigVersion is a stored snapshot of a project config.
+type ConfigVersion struct {
+	ProjectID  string         `json:"project_id"`
+	Version    int            `json:"version"`
+	ActorID    string         `json:"actor_id,omitempty"`
+	CreatedAt  string         `json:"created_at" format:"date-time"`
+	ConfigJSON string         `json:"-"`
+	Changes    []ConfigChange `json:"changes,omitempty"`
+}
+
+// ConfigChange is one differing setting between two config versions, keyed by
+// its dotted path. A nil From or To means the setting was added or removed.
+type ConfigChange struct {
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+	// Redacted is set when credentials were left out of From and To.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// ConfigImportPlan previews what importing a config would change.
+type ConfigImportPlan struct {
+	ProjectID           string         `json:"project_id"`
+	AddedAttestations   []string       `json:"added_attestations,omitempty"`
+	RemovedAttestations []string       `json:"removed_attestations,omitempty"`
+	PolicyChanges       []ConfigChange `json:"policy_changes,omitempty"`
+	RBACChanges         []ConfigChange `json:"rbac_changes,omitempty"`
+	Changes             []ConfigChange `json:"changes"`
+	Warnings            []string       `json:"warnings,omitempty"`
+}
+
+// EventCompaction reports the events archived and removed by a compaction.
+type EventCompaction struct {
+	ProjectID string   `json:"project_id"`
+	Before    string   `json:"before,omitempty"`
+	MaxRows   int      `json:"max_rows,omitempty"`
+	Exempt    []string `json:"exempt"`
+	Count     int      `json:"count"`
+	FirstID   int64    `json:"first_id,omitempty"`
+	LastID    int64    `json:"last_id,omitempty"`
+	Archive   string   `json:"archive,omitempty"`
+	DryRun    bool     `json:"dry_run"`
+}
+
+// ProjectDeletion reports a deleted project: rows removed per table, the
+// evidence files left behind and the archive written first, if any.
+type ProjectDeletion struct {
+	ProjectID string         `json:"project_id"`
+	Rows      map[string]int `json:"rows"`
+	Archive   string         `json:"archive,omitempty"`
+	Warnings  []string       `json:"warnings"`
+}
+
+// ProjectAlias is a former id of a renamed project. LastEventID is the newest
+// event written under it.
+type ProjectAlias struct {
+	Alias       string `json:"alias"`
+	ProjectID   string `json:"project_id"`
+	LastEventID int64  `json:"last_event_id"`
+	RenamedAt   string `json:"renamed_at" format:"date-time"`
+}
+
+// ProjectionState is how far a projection has read a project's event log.
+// Lag counts the events recorded since, -1 when it was never built.
+type ProjectionState struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ProjectID   string `json:"project_id"`
+	LastEventID int64  `json:"last_event_id"`
+	RebuiltAt   string `json:"rebuilt_at,omitempty" format:"date-time"`
+	Lag         int    `json:"lag"`
+}
+
+// ProjectionRebuild reports a projection rebuilt by replaying events.
+type ProjectionRebuild struct {
+	Name        string   `json:"name"`
+	ProjectID   string   `json:"project_id"`
+	Events      int      `json:"events"`
+	LastEventID int64    `json:"last_event_id"`
+	RebuiltAt   string   `json:"rebuilt_at" format:"date-time"`
+	Warnings    []string `json:"warnings"`
+}
+
+// ForceRequest is a forced operation held until a second actor approves it.
+type ForceRequest struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	Operation   string  `json:"operation"`
+	EntityKind  string  `json:"entity_kind"`
+	EntityID    string  `json:"entity_id"`
+	ParamsJSON  string  `json:"-"`
+	Status      string  `json:"status"`
+	RequestedBy string  `json:"requested_by"`
+	RequestedAt string  `json:"requested_at" format:"date-time"`
+	ApprovedBy  *string `json:"approved_by,omitempty"`
+	ApprovedAt  *string `json:"approved_at,omitempty" format:"date-time"`
+}
+
+// WebhookDelivery is one attempt to post an event to a configured webhook.
+// StatusCode is 0 when no response came back; Error is set for any failure.
+type WebhookDelivery struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	URL         string  `json:"url"`
+	EventID     int64   `json:"event_id"`
+	EventType   string  `json:"event_type"`
+	Body        string  `json:"-"`
+	StatusCode  int     `json:"status_code"`
+	Error       string  `json:"error,omitempty"`
+	DurationMS  int64   `json:"duration_ms"`
+	AttemptedAt string  `json:"attempted_at" format:"date-time"`
+	ReplayOf    *string `json:"replay_of,omitempty"`
+}
+
+// ExternalRef links an entity to its id in an external system, such as the
+// tracker it was imported from. Source names the system.
+type ExternalRef struct {
+	ProjectID  string `json:"project_id"`
+	Source     string `json:"source"`
+	ExternalID string `json:"external_id"`
+	EntityKind string `json:"entity_kind"`
+	EntityID   string `json:"entity_id"`
+	ImportedAt string `json:"imported_at" format:"date-time"`
+}
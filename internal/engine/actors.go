@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// Actor lifecycle states.
+const (
+	ActorStatusActive    = "active"
+	ActorStatusSuspended = "suspended"
+)
+
+// DeactivateActor suspends an actor so its credentials are rejected, and
+// releases every lease it holds. Actors are shared by every org, so besides
+// rbac.manage on the project the caller must own or administer each org the
+// actor is known to; see requireActorAuthority. It returns the released task
+// ids.
+func (e Engine) DeactivateActor(ctx context.Context, projectID, actorID, targetActor, reason string) ([]string, error) {
+	if targetActor == "" {
+		return nil, errors.New("actor_id is required")
+	}
+	if targetActor == actorID {
+		return nil, errors.New("invalid change: actors cannot deactivate themselves")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return nil, err
+	}
+	if err := e.requireActorAuthority(ctx, tx, projectID, actorID, targetActor); err != nil {
+		return nil, err
+	}
+	now := e.now().UTC().Format(time.RFC3339)
+	if err := e.Repo.SetActorStatusTx(ctx, tx, targetActor, ActorStatusSuspended, &now); err != nil {
+		return nil, err
+	}
+	leases, err := e.Repo.ListLeasesByOwnerTx(ctx, tx, targetActor)
+	if err != nil {
+		return nil, err
+	}
+	released := make([]string, 0, len(leases))
+	for _, l := range leases {
+		t, err := e.Repo.GetTaskTx(ctx, tx, l.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.Repo.DeleteLease(ctx, tx, l.TaskID); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, "lease.released", t.ProjectID, "task", l.TaskID, actorID, events.EventPayload{
+			"owner_id": targetActor,
+			"reason":   "actor_deactivated",
+		}); err != nil {
+			return nil, err
+		}
+		released = append(released, l.TaskID)
+	}
+	payload := events.EventPayload{"actor_id": targetActor, "released_tasks": released}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+	if err := e.Events.Append(ctx, tx, "actor.deactivated", projectID, "rbac", projectID, actorID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	e.unlockLeases(ctx, leases...)
+	return released, nil
+}
+
+// ReactivateActor lifts a suspension, with the same authority as
+// DeactivateActor. Released leases are not restored.
+func (e Engine) ReactivateActor(ctx context.Context, projectID, actorID, targetActor string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return err
+	}
+	if err := e.requireActorAuthority(ctx, tx, projectID, actorID, targetActor); err != nil {
+		return err
+	}
+	if err := e.Repo.SetActorStatusTx(ctx, tx, targetActor, ActorStatusActive, nil); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "actor.reactivated", projectID, "rbac", projectID, actorID, events.EventPayload{"actor_id": targetActor}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ActorScrubbedEvent records an actor scrubbed for a data-deletion request.
+// It names only the pseudonym.
+const ActorScrubbedEvent = "actor.scrubbed"
+
+// ScrubActor pseudonymizes an actor for a data-deletion request. Its id is
+// replaced by a random pseudonym in tasks, leases, attestations, decisions,
+// time entries, events and every other row naming it, including values inside
+// event payloads, queued outbox messages and webhook delivery bodies. Its
+// leases are released and its role grants, API keys and missions deleted.
+// Only a hash of the former id is kept, so that id can no longer sign in or
+// be registered again. Rewritten events are rehashed, so event chain anchors
+// recorded before the scrub no longer verify. Besides actor.scrub on the
+// project, the caller must own or administer every org the actor is known
+// to, as for DeactivateActor, so the rewrite stays within orgs it runs.
+func (e Engine) ScrubActor(ctx context.Context, projectID, actorID, targetActor, reason string) (domain.ActorScrub, error) {
+	if targetActor == "" {
+		return domain.ActorScrub{}, errors.New("actor_id is required")
+	}
+	if targetActor == actorID {
+		return domain.ActorScrub{}, errors.New("invalid change: actors cannot scrub themselves")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ActorScrub{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "actor.scrub"); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	exists, err := e.Repo.ActorExistsTx(ctx, tx, targetActor)
+	if err != nil {
+		return domain.ActorScrub{}, err
+	}
+	if !exists {
+		return domain.ActorScrub{}, fmt.Errorf("unknown actor %s", targetActor)
+	}
+	if err := e.requireActorAuthority(ctx, tx, projectID, actorID, targetActor); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	scrub := domain.ActorScrub{
+		Pseudonym:         "anon-" + hex.EncodeToString(suffix),
+		ScrubbedAt:        e.now().UTC().Format(time.RFC3339),
+		ReleasedTasks:     []string{},
+		RechainedProjects: []string{},
+	}
+
+	leases, err := e.Repo.ListLeasesByOwnerTx(ctx, tx, targetActor)
+	if err != nil {
+		return domain.ActorScrub{}, err
+	}
+	for _, l := range leases {
+		t, err := e.Repo.GetTaskTx(ctx, tx, l.TaskID)
+		if err != nil {
+			return domain.ActorScrub{}, err
+		}
+		if err := e.Repo.DeleteLease(ctx, tx, l.TaskID); err != nil {
+			return domain.ActorScrub{}, err
+		}
+		if err := e.Events.Append(ctx, tx, "lease.released", t.ProjectID, "task", l.TaskID, actorID, events.EventPayload{
+			"owner_id": scrub.Pseudonym,
+			"reason":   "actor_scrubbed",
+		}); err != nil {
+			return domain.ActorScrub{}, err
+		}
+		scrub.ReleasedTasks = append(scrub.ReleasedTasks, l.TaskID)
+	}
+
+	chains, err := e.Repo.ScrubbedEventsTx(ctx, tx, targetActor)
+	if err != nil {
+		return domain.ActorScrub{}, err
+	}
+	if scrub.RowsRewritten, err = e.Repo.ScrubActorTx(ctx, tx, targetActor, scrub.Pseudonym, scrub.ScrubbedAt); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	for _, chain := range slices.Sorted(maps.Keys(chains)) {
+		if err := e.rechainEventsTx(ctx, tx, chain, chains[chain]); err != nil {
+			return domain.ActorScrub{}, err
+		}
+		if chain != "" {
+			scrub.RechainedProjects = append(scrub.RechainedProjects, chain)
+		}
+	}
+
+	payload := events.EventPayload{
+		"actor_id":           scrub.Pseudonym,
+		"rows_rewritten":     scrub.RowsRewritten,
+		"released_tasks":     scrub.ReleasedTasks,
+		"rechained_projects": scrub.RechainedProjects,
+	}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+	if err := e.Events.Append(ctx, tx, ActorScrubbedEvent, projectID, "rbac", projectID, actorID, payload); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ActorScrub{}, err
+	}
+	e.forgetPermissions(ctx)
+	e.unlockLeases(ctx, leases...)
+	return scrub, nil
+}
+
+// requireActorAuthority checks that actorID owns or administers the
+// project's org and every org targetActor is a member of, holds grants,
+// leases or assignments in, or is named by events of. Actor ids are shared
+// across orgs, so suspending or scrubbing one reaches each of them; an admin
+// of one org cannot act on an actor another org also knows.
+func (e Engine) requireActorAuthority(ctx context.Context, tx *sql.Tx, projectID, actorID, targetActor string) error {
+	orgID, err := e.Repo.ProjectOrgTx(ctx, tx, projectID)
+	if err != nil {
+		return err
+	}
+	orgs, err := e.Repo.ActorOrgsTx(ctx, tx, targetActor)
+	if err != nil {
+		return err
+	}
+	for _, id := range append([]string{orgID}, orgs...) {
+		if err := e.requireOrgAdmin(ctx, tx, id, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"workline/internal/repo"
+)
+
+// ProjectArchivedError is returned for task, iteration and attestation
+// writes on an archived project. Setting the project status back is allowed.
+type ProjectArchivedError struct {
+	ProjectID  string
+	Permission string
+}
+
+func (e ProjectArchivedError) Error() string {
+	return fmt.Sprintf("project %s is archived: %s is not allowed", e.ProjectID, e.Permission)
+}
+
+// archivedGuardedPrefixes are the permission families an archived project
+// refuses.
+var archivedGuardedPrefixes = []string{"task.", "iteration.", "attestation."}
+
+func (e Engine) requireNotArchived(ctx context.Context, tx *sql.Tx, projectID, perm string) error {
+	if projectID == "" || isReadPermission(perm) {
+		return nil
+	}
+	guarded := false
+	for _, prefix := range archivedGuardedPrefixes {
+		guarded = guarded || strings.HasPrefix(perm, prefix)
+	}
+	if !guarded {
+		return nil
+	}
+	status, err := e.Repo.ProjectStatusTx(ctx, tx, projectID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status == "archived" {
+		return ProjectArchivedError{ProjectID: projectID, Permission: perm}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// assigneePermissions are what an actor needs to pick up and work a task.
+var assigneePermissions = []string{"task.claim", "task.update"}
+
+// ErrNoAssignee is returned when no actor is eligible to take a task.
+var ErrNoAssignee = errors.New("no eligible assignee")
+
+// SuggestAssignees ranks the project's active actors who hold the permissions
+// to work a task: fewest other open tasks first, then the most recently
+// active, then by id. Each suggestion lists which of the task's required
+// attestation kinds the actor may record.
+func (e Engine) SuggestAssignees(ctx context.Context, taskID, actorID string) ([]domain.AssigneeSuggestion, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.read"); err != nil {
+		return nil, err
+	}
+	actors, err := e.Repo.ProjectActorsTx(ctx, tx, t.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := e.Repo.AssignedTasksTx(ctx, tx, t.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	open := map[string]int{}
+	for _, other := range assigned {
+		if other.ID == t.ID || e.workflow(other.Type).IsTerminal(other.Status) {
+			continue
+		}
+		open[*other.AssigneeID]++
+	}
+	lastActive, err := e.Repo.LastActivityTx(ctx, tx, t.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	required := currentPolicy(t).Require
+	suggestions := []domain.AssigneeSuggestion{}
+	for _, candidate := range actors {
+		suspended, err := e.Auth.ActorSuspended(ctx, tx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if suspended {
+			continue
+		}
+		eligible := true
+		for _, perm := range assigneePermissions {
+			ok, err := e.Auth.ActorHasPermission(ctx, tx, t.ProjectID, candidate, perm)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				eligible = false
+				break
+			}
+		}
+		if !eligible {
+			continue
+		}
+		roles, err := e.Auth.ActorRoles(ctx, tx, t.ProjectID, candidate)
+		if err != nil {
+			return nil, err
+		}
+		canAttest := []string{}
+		for _, kind := range required {
+			ok, err := e.Auth.ActorCanAttest(ctx, tx, t.ProjectID, candidate, kind)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				canAttest = append(canAttest, kind)
+			}
+		}
+		suggestions = append(suggestions, domain.AssigneeSuggestion{
+			ActorID:      candidate,
+			OpenTasks:    open[candidate],
+			LastActiveAt: lastActive[candidate],
+			Roles:        roles,
+			CanAttest:    canAttest,
+		})
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(suggestions, func(a, b domain.AssigneeSuggestion) int {
+		if a.OpenTasks != b.OpenTasks {
+			return a.OpenTasks - b.OpenTasks
+		}
+		if c := strings.Compare(b.LastActiveAt, a.LastActiveAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ActorID, b.ActorID)
+	})
+	return suggestions, nil
+}
+
+// AutoAssignTask assigns a task to the top suggestion from SuggestAssignees.
+func (e Engine) AutoAssignTask(ctx context.Context, taskID, actorID string) (domain.Task, error) {
+	suggestions, err := e.SuggestAssignees(ctx, taskID, actorID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if len(suggestions) == 0 {
+		return domain.Task{}, ErrNoAssignee
+	}
+	assignee := suggestions[0].ActorID
+	return e.UpdateTask(ctx, TaskUpdateOptions{
+		ID:             taskID,
+		ActorID:        actorID,
+		AssignProvided: true,
+		Assign:         &assignee,
+	})
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// MaxAttestationBatch caps the attestations accepted by AddAttestations.
+const MaxAttestationBatch = 100
+
+// AttestationResult is the outcome of one attestation in a batch; Err is set
+// when that item was rejected.
+type AttestationResult struct {
+	Attestation domain.Attestation
+	Err         error
+}
+
+// AddAttestations records a batch of attestations for one project in a single
+// transaction. Authority is checked once per kind, and each item is inserted
+// in a savepoint so a rejected item does not affect the others. The returned
+// results follow the order of atts; the error is set only when the batch as a
+// whole could not be processed.
+func (e Engine) AddAttestations(ctx context.Context, projectID string, atts []domain.Attestation, actorID string) ([]AttestationResult, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if len(atts) == 0 {
+		return nil, errors.New("at least one attestation required")
+	}
+	if len(atts) > MaxAttestationBatch {
+		return nil, fmt.Errorf("invalid batch: %d attestations (max %d)", len(atts), MaxAttestationBatch)
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "attestation.add"); err != nil {
+		return nil, err
+	}
+	now := e.now().UTC().Format(time.RFC3339)
+	authority := map[string]error{}
+	results := make([]AttestationResult, len(atts))
+	for i, att := range atts {
+		att.ID = uuid.New().String()
+		att.ProjectID = projectID
+		att.ActorID = actorID
+		if att.TS == "" {
+			att.TS = now
+		}
+		results[i].Attestation = att
+		if att.EntityKind == "" || att.EntityID == "" || att.Kind == "" {
+			results[i].Err = errors.New("entity-kind, entity-id and kind required")
+			continue
+		}
+		authErr, checked := authority[att.Kind]
+		if !checked {
+			authErr = e.requireAttestationAuthority(ctx, tx, projectID, actorID, att.Kind)
+			authority[att.Kind] = authErr
+		}
+		if authErr != nil {
+			results[i].Err = authErr
+			continue
+		}
+		if err := e.validateAttestationPayload(att); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT attestation`); err != nil {
+			return nil, err
+		}
+		err := e.Repo.InsertAttestationTx(ctx, tx, att)
+		if err == nil {
+			err = e.Events.Append(ctx, tx, "attestation.added", projectID, att.EntityKind, att.EntityID, actorID, events.EventPayload{
+				"kind":           att.Kind,
+				"entity":         att.EntityID,
+				"attestation_id": att.ID,
+				"batch":          true,
+			})
+		}
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO attestation`); rbErr != nil {
+				return nil, rbErr
+			}
+			results[i].Err = err
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE attestation`); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// AttestationExpiresAt returns when an attestation stops satisfying policies.
+// ok is false when its kind has no valid_days or its timestamp is unreadable.
+func (e Engine) AttestationExpiresAt(att domain.Attestation) (time.Time, bool) {
+	if e.Config == nil {
+		return time.Time{}, false
+	}
+	validity := e.Config.AttestationValidity(att.Kind)
+	if validity <= 0 {
+		return time.Time{}, false
+	}
+	issued, err := time.Parse(time.RFC3339, att.TS)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return issued.Add(validity), true
+}
+
+// AttestationExpired reports whether an attestation no longer satisfies
+// policies at asOf.
+func (e Engine) AttestationExpired(att domain.Attestation, asOf time.Time) bool {
+	expiresAt, ok := e.AttestationExpiresAt(att)
+	return ok && !asOf.Before(expiresAt)
+}
+
+// ExpireAttestations marks the project's attestations past their kind's
+// valid_days and records an attestation.expired event for each. Attestations
+// already marked are skipped, so the sweep can run repeatedly.
+func (e Engine) ExpireAttestations(ctx context.Context, projectID, actorID string) ([]domain.Attestation, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if err := e.requireWritable("attestation.expire"); err != nil {
+		return nil, err
+	}
+	var kinds []string
+	for _, att := range e.Config.Project.Attestations {
+		if att.ValidDays > 0 {
+			kinds = append(kinds, att.ID)
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	now := e.now().UTC()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	candidates, err := e.Repo.UnexpiredAttestationsTx(ctx, tx, projectID, kinds)
+	if err != nil {
+		return nil, err
+	}
+	stamp := now.Format(time.RFC3339)
+	var expired []domain.Attestation
+	for _, att := range candidates {
+		expiresAt, ok := e.AttestationExpiresAt(att)
+		if !ok || now.Before(expiresAt) {
+			continue
+		}
+		if err := e.Repo.MarkAttestationExpiredTx(ctx, tx, att.ID, stamp); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, "attestation.expired", projectID, att.EntityKind, att.EntityID, actorID, events.EventPayload{
+			"kind":           att.Kind,
+			"entity":         att.EntityID,
+			"attestation_id": att.ID,
+			"issued_at":      att.TS,
+			"expires_at":     expiresAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return nil, err
+		}
+		att.ExpiredAt = &stamp
+		expired = append(expired, att)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
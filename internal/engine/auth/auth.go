@@ -5,7 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"workline/internal/domain"
+	"workline/internal/repo"
 )
 
 // ForbiddenError indicates missing permission.
@@ -26,25 +30,90 @@ func (e ForbiddenAttestationError) Error() string {
 	return fmt.Sprintf("attestation authority required for kind %s", e.Kind)
 }
 
+// ForbiddenRoleError indicates a task type whose completion is limited to
+// roles the actor does not hold.
+type ForbiddenRoleError struct {
+	TaskType string
+	Roles    []string
+}
+
+func (e ForbiddenRoleError) Error() string {
+	return fmt.Sprintf("completing %s tasks requires role %s", e.TaskType, strings.Join(e.Roles, " or "))
+}
+
+// SuspendedActorError indicates the acting actor has been deactivated.
+type SuspendedActorError struct {
+	ActorID string
+}
+
+func (e SuspendedActorError) Error() string {
+	return fmt.Sprintf("actor %s is suspended", e.ActorID)
+}
+
+// effectiveRolesSQL lists the roles an actor holds in a project (?1) as actor (?2).
+// Grants are additive: project-level roles, project roles granted at org level,
+// and the project owner role implied by org ownership. Revoking a project-level
+// grant does not remove a role that is also granted through the org. Project
+// grants past their expires_at no longer count, even before the sweep deletes
+// them.
+const effectiveRolesSQL = `
+SELECT ar.role_id AS role_id FROM actor_roles ar WHERE ar.project_id=?1 AND ar.actor_id=?2 AND ` + unexpiredSQL + `
+UNION
+SELECT oar.role_id FROM org_actor_roles oar JOIN projects p ON p.org_id=oar.org_id WHERE p.id=?1 AND oar.actor_id=?2
+UNION
+SELECT r.id FROM org_roles om JOIN projects p ON p.org_id=om.org_id JOIN roles r ON r.id='owner' WHERE p.id=?1 AND om.actor_id=?2 AND om.role='owner'`
+
+// unexpiredSQL keeps project grants (ar) without expires_at or with one
+// still ahead.
+const unexpiredSQL = `(ar.expires_at IS NULL OR ar.expires_at > strftime('%Y-%m-%dT%H:%M:%SZ','now'))`
+
 // Service provides RBAC helpers backed by SQL.
 type Service struct {
 	DB *sql.DB
 }
 
+// EnsureActor registers an actor on first use. Scrubbed ids are refused, as
+// suspended, so they cannot come back.
 func (s Service) EnsureActor(ctx context.Context, tx *sql.Tx, actorID string) error {
 	if actorID == "" {
 		return errors.New("actor_id required")
 	}
+	scrubbed, err := actorScrubbed(ctx, tx, actorID)
+	if err != nil {
+		return err
+	}
+	if scrubbed {
+		return SuspendedActorError{ActorID: actorID}
+	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO actors(id, created_at) VALUES (?,?)`, actorID, now)
+	_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO actors(id, created_at) VALUES (?,?)`, actorID, now)
 	return err
 }
 
+// ActorSuspended reports whether the actor has been deactivated or scrubbed.
+func (s Service) ActorSuspended(ctx context.Context, tx *sql.Tx, actorID string) (bool, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM actors WHERE id=?`, actorID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return actorScrubbed(ctx, tx, actorID)
+	}
+	return status == "suspended", err
+}
+
+func actorScrubbed(ctx context.Context, tx *sql.Tx, actorID string) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM scrubbed_actors WHERE id_hash=?`, repo.HashActorID(actorID)).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (s Service) ActorHasPermission(ctx context.Context, tx *sql.Tx, projectID, actorID, perm string) (bool, error) {
 	row := tx.QueryRowContext(ctx, `
-SELECT 1 FROM actor_roles ar
-JOIN role_permissions rp ON rp.role_id=ar.role_id
-WHERE ar.project_id=? AND ar.actor_id=? AND rp.permission_id=? LIMIT 1`,
+SELECT 1 FROM (`+effectiveRolesSQL+`) er
+JOIN role_permissions rp ON rp.role_id=er.role_id
+WHERE rp.permission_id=?3 LIMIT 1`,
 		projectID, actorID, perm)
 	var n int
 	err := row.Scan(&n)
@@ -55,7 +124,7 @@ WHERE ar.project_id=? AND ar.actor_id=? AND rp.permission_id=? LIMIT 1`,
 }
 
 func (s Service) ActorRoles(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]string, error) {
-	rows, err := tx.QueryContext(ctx, `SELECT role_id FROM actor_roles WHERE project_id=? AND actor_id=?`, projectID, actorID)
+	rows, err := tx.QueryContext(ctx, `SELECT role_id FROM (`+effectiveRolesSQL+`) ORDER BY role_id`, projectID, actorID)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +140,67 @@ func (s Service) ActorRoles(ctx context.Context, tx *sql.Tx, projectID, actorID
 	return roles, nil
 }
 
+// ActorRoleExpiries returns when each time-boxed role the actor holds in the
+// project lapses, keyed by role. Roles the actor also holds through the org
+// do not lapse and are left out.
+func (s Service) ActorRoleExpiries(ctx context.Context, tx *sql.Tx, projectID, actorID string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT ar.role_id, ar.expires_at FROM actor_roles ar
+WHERE ar.project_id=?1 AND ar.actor_id=?2 AND ar.expires_at IS NOT NULL AND `+unexpiredSQL+`
+AND ar.role_id NOT IN (
+  SELECT oar.role_id FROM org_actor_roles oar JOIN projects p ON p.org_id=oar.org_id WHERE p.id=?1 AND oar.actor_id=?2
+  UNION
+  SELECT 'owner' FROM org_roles om JOIN projects p ON p.org_id=om.org_id WHERE p.id=?1 AND om.actor_id=?2 AND om.role='owner'
+)`, projectID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	expiries := map[string]string{}
+	for rows.Next() {
+		var role, expiresAt string
+		if err := rows.Scan(&role, &expiresAt); err != nil {
+			return nil, err
+		}
+		expiries[role] = expiresAt
+	}
+	return expiries, rows.Err()
+}
+
+// ActorRoleSources returns the roles the actor holds in the project with the
+// scope of each grant; a role held both ways appears once per scope.
+func (s Service) ActorRoleSources(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]domain.RoleSource, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT ar.role_id, 'project', ar.expires_at FROM actor_roles ar WHERE ar.project_id=?1 AND ar.actor_id=?2 AND `+unexpiredSQL+`
+UNION
+SELECT oar.role_id, 'org', NULL FROM org_actor_roles oar JOIN projects p ON p.org_id=oar.org_id WHERE p.id=?1 AND oar.actor_id=?2
+UNION
+SELECT r.id, 'org', NULL FROM org_roles om JOIN projects p ON p.org_id=om.org_id JOIN roles r ON r.id='owner' WHERE p.id=?1 AND om.actor_id=?2 AND om.role='owner'
+ORDER BY 1, 2`, projectID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sources []domain.RoleSource
+	for rows.Next() {
+		var src domain.RoleSource
+		var expiresAt sql.NullString
+		if err := rows.Scan(&src.RoleID, &src.Scope, &expiresAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			src.ExpiresAt = &expiresAt.String
+		}
+		sources = append(sources, src)
+	}
+	return sources, rows.Err()
+}
+
 func (s Service) ActorPermissions(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]string, error) {
 	rows, err := tx.QueryContext(ctx, `
 SELECT DISTINCT rp.permission_id
-FROM actor_roles ar
-JOIN role_permissions rp ON rp.role_id=ar.role_id
-WHERE ar.project_id=? AND ar.actor_id=?`, projectID, actorID)
+FROM (`+effectiveRolesSQL+`) er
+JOIN role_permissions rp ON rp.role_id=er.role_id`, projectID, actorID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,10 +218,10 @@ WHERE ar.project_id=? AND ar.actor_id=?`, projectID, actorID)
 
 func (s Service) ActorCanAttest(ctx context.Context, tx *sql.Tx, projectID, actorID, kind string) (bool, error) {
 	row := tx.QueryRowContext(ctx, `
-SELECT 1 FROM actor_roles ar
-JOIN attestation_authorities aa ON aa.role_id=ar.role_id
-WHERE ar.project_id=? AND ar.actor_id=? AND aa.project_id=? AND aa.kind=? LIMIT 1`,
-		projectID, actorID, projectID, kind)
+SELECT 1 FROM (`+effectiveRolesSQL+`) er
+JOIN attestation_authorities aa ON aa.role_id=er.role_id
+WHERE aa.project_id=?1 AND aa.kind=?3 LIMIT 1`,
+		projectID, actorID, kind)
 	var n int
 	err := row.Scan(&n)
 	if err == sql.ErrNoRows {
@@ -109,10 +233,10 @@ WHERE ar.project_id=? AND ar.actor_id=? AND aa.project_id=? AND aa.kind=? LIMIT
 func (s Service) ActorAttestationKinds(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]string, error) {
 	rows, err := tx.QueryContext(ctx, `
 SELECT DISTINCT aa.kind
-FROM actor_roles ar
-JOIN attestation_authorities aa ON aa.role_id=ar.role_id
-WHERE ar.project_id=? AND ar.actor_id=? AND aa.project_id=?`,
-		projectID, actorID, projectID)
+FROM (`+effectiveRolesSQL+`) er
+JOIN attestation_authorities aa ON aa.role_id=er.role_id
+WHERE aa.project_id=?1`,
+		projectID, actorID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// WIPExceededError is returned when board.strict_wip is set and a transition
+// would put more tasks in a status than its WIP limit allows.
+type WIPExceededError struct {
+	Status string
+	Limit  int
+	Count  int
+}
+
+func (e WIPExceededError) Error() string {
+	return fmt.Sprintf("status %s over wip limit: %d of %d tasks", e.Status, e.Count, e.Limit)
+}
+
+// checkWIPLimit compares the project's tasks in status, with t moved into it,
+// against the status's WIP limit. Over the limit it either fails with
+// WIPExceededError (strict_wip, unless forced) or records a wip.exceeded
+// event and returns a warning.
+func (e Engine) checkWIPLimit(ctx context.Context, tx *sql.Tx, t domain.Task, status, actorID string, force bool) (string, error) {
+	if e.Config == nil || t.Status == status {
+		return "", nil
+	}
+	limit, ok := e.Config.WIPLimit(status)
+	if !ok {
+		return "", nil
+	}
+	counts, err := e.Repo.CountTasksByStatusTx(ctx, tx, t.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	count := counts[status] + 1
+	if count <= limit {
+		return "", nil
+	}
+	over := WIPExceededError{Status: status, Limit: limit, Count: count}
+	if e.Config.Project.Board.StrictWIP && !force {
+		return "", over
+	}
+	payload := events.EventPayload{"status": status, "limit": limit, "count": count}
+	if force {
+		payload["forced"] = true
+	}
+	if err := e.Events.Append(ctx, tx, "wip.exceeded", t.ProjectID, "task", t.ID, actorID, payload); err != nil {
+		return "", err
+	}
+	return over.Error(), nil
+}
+
+// Board returns the project's tasks grouped by status with each status's WIP
+// limit and whether it is exceeded.
+func (e Engine) Board(ctx context.Context, projectID, actorID string) (domain.Board, error) {
+	if e.Config == nil {
+		return domain.Board{}, fmt.Errorf("config not loaded")
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.Board{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Board{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "task.list"); err != nil {
+		return domain.Board{}, err
+	}
+	tasks, err := e.Repo.ListTasksTx(ctx, tx, repo.TaskFilters{ProjectID: projectID, Sort: repo.TaskSort{Field: "priority"}})
+	if err != nil {
+		return domain.Board{}, err
+	}
+	byStatus := map[string][]domain.Task{}
+	for _, t := range tasks {
+		byStatus[t.Status] = append(byStatus[t.Status], t)
+	}
+	board := domain.Board{ProjectID: projectID, StrictWIP: e.Config.Project.Board.StrictWIP, Columns: []domain.BoardColumn{}}
+	for _, status := range boardStatuses(e.Config, tasks) {
+		col := domain.BoardColumn{Status: status, Count: len(byStatus[status]), Tasks: byStatus[status]}
+		if col.Tasks == nil {
+			col.Tasks = []domain.Task{}
+		}
+		if limit, ok := e.Config.WIPLimit(status); ok {
+			col.WIPLimit = &limit
+			col.OverLimit = col.Count > limit
+		}
+		board.Columns = append(board.Columns, col)
+	}
+	return board, nil
+}
+
+// boardStatuses lists the workflow states of every task type in workflow
+// order, followed by any status a task holds outside them.
+func boardStatuses(cfg *config.Config, tasks []domain.Task) []string {
+	seen := map[string]bool{}
+	var statuses []string
+	add := func(status string) {
+		if status != "" && !seen[status] {
+			seen[status] = true
+			statuses = append(statuses, status)
+		}
+	}
+	var types []string
+	for taskType := range cfg.AllowedTaskTypes() {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+	for _, taskType := range types {
+		for _, status := range cfg.TaskWorkflow(taskType).States {
+			add(status)
+		}
+	}
+	for _, t := range tasks {
+		add(t.Status)
+	}
+	return statuses
+}
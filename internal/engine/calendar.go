@@ -0,0 +1,238 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/engine/auth"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ErrInvalidFeedToken is returned for a calendar feed token that is unknown,
+// revoked or issued for another project.
+var ErrInvalidFeedToken = errors.New("invalid calendar feed token")
+
+// calendarPermissions are needed to read a project's calendar.
+var calendarPermissions = []string{"iteration.list", "milestone.list"}
+
+// Calendar lists a project's iterations and milestones as calendar entries.
+// Iteration dates come from status-change events: the first move to running
+// starts it and the next move to delivered, validated or rejected ends it.
+// Iterations that never ran, or whose events were compacted away, are left
+// out.
+func (e Engine) Calendar(ctx context.Context, projectID, actorID string) ([]domain.CalendarEntry, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, perm := range calendarPermissions {
+		if err := e.requirePermission(ctx, tx, projectID, actorID, perm); err != nil {
+			return nil, err
+		}
+	}
+	iterations, err := e.Repo.ListIterationsTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	evts, err := e.Repo.IterationStatusEventsTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	milestones, err := e.Repo.ListMilestonesTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	type span struct{ start, end string }
+	spans := map[string]*span{}
+	for _, ev := range evts {
+		var payload struct {
+			To string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(ev.Payload), &payload); err != nil {
+			continue
+		}
+		day := eventDay(ev.TS)
+		if day == "" {
+			continue
+		}
+		s := spans[ev.EntityID]
+		switch payload.To {
+		case "running":
+			if s == nil {
+				s = &span{start: day}
+				spans[ev.EntityID] = s
+			}
+			// Reopened iterations run until their next close.
+			s.end = ""
+		case "delivered", "validated", "rejected":
+			if s != nil && s.end == "" {
+				s.end = day
+			}
+		}
+	}
+
+	today := e.now().UTC().Format(time.DateOnly)
+	var res []domain.CalendarEntry
+	for _, it := range iterations {
+		s := spans[it.ID]
+		if s == nil {
+			continue
+		}
+		entry := domain.CalendarEntry{
+			Kind:     "iteration",
+			EntityID: it.ID,
+			Summary:  "Iteration " + it.ID + ": " + it.Goal,
+			Status:   it.Status,
+			Start:    s.start,
+			End:      s.end,
+		}
+		if entry.End == "" {
+			entry.End = today
+			entry.Ongoing = true
+		}
+		if entry.End < entry.Start {
+			entry.End = entry.Start
+		}
+		res = append(res, entry)
+	}
+	for _, m := range milestones {
+		res = append(res, domain.CalendarEntry{
+			Kind:     "milestone",
+			EntityID: m.ID,
+			Summary:  "Milestone " + m.ID + ": " + m.Goal,
+			Start:    m.TargetDate,
+			End:      m.TargetDate,
+		})
+	}
+	return res, nil
+}
+
+// eventDay returns the UTC date of an RFC 3339 event timestamp.
+func eventDay(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
+// CreateCalendarFeed issues a token that reads the project's calendar as the
+// actor, for subscription URLs. The token is returned once; only its hash is
+// stored.
+func (e Engine) CreateCalendarFeed(ctx context.Context, projectID, actorID, name string) (domain.CalendarFeed, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	feed := domain.CalendarFeed{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Name:      strings.TrimSpace(name),
+		Token:     "wlcal_" + base64.RawURLEncoding.EncodeToString(buf),
+		CreatedAt: e.now().UTC().Format(time.RFC3339),
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	defer tx.Rollback()
+	for _, perm := range calendarPermissions {
+		if err := e.requirePermission(ctx, tx, projectID, actorID, perm); err != nil {
+			return domain.CalendarFeed{}, err
+		}
+	}
+	if err := e.Repo.InsertCalendarFeedTx(ctx, tx, feed, repo.HashAPIKey(feed.Token)); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "calendar.feed_created", projectID, events.AuditEntityKind, feed.ID, actorID, events.EventPayload{"name": feed.Name}); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.CalendarFeed{}, err
+	}
+	return feed, nil
+}
+
+// CalendarFeeds lists the actor's calendar feeds in the project, or every
+// actor's with rbac.manage.
+func (e Engine) CalendarFeeds(ctx context.Context, projectID, actorID string) ([]domain.CalendarFeed, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	owner, err := e.feedOwnerFilter(ctx, tx, projectID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	return e.Repo.ListCalendarFeedsTx(ctx, tx, projectID, owner)
+}
+
+// RevokeCalendarFeed deletes a calendar feed so its token stops working.
+// Actors revoke their own feeds; others' need rbac.manage.
+func (e Engine) RevokeCalendarFeed(ctx context.Context, projectID, actorID, feedID string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	owner, err := e.feedOwnerFilter(ctx, tx, projectID, actorID)
+	if err != nil {
+		return err
+	}
+	feed, err := e.Repo.GetCalendarFeedTx(ctx, tx, projectID, feedID)
+	if err != nil {
+		return err
+	}
+	if owner != "" && feed.ActorID != owner {
+		return auth.ForbiddenError{Permission: "rbac.manage"}
+	}
+	if err := e.Repo.DeleteCalendarFeedTx(ctx, tx, feed.ID); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "calendar.feed_revoked", projectID, events.AuditEntityKind, feed.ID, actorID, events.EventPayload{"actor_id": feed.ActorID}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// feedOwnerFilter returns "" when the actor manages every feed of the
+// project, or the actor itself when it may only see its own.
+func (e Engine) feedOwnerFilter(ctx context.Context, tx *sql.Tx, projectID, actorID string) (string, error) {
+	err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage")
+	var fe auth.ForbiddenError
+	if errors.As(err, &fe) {
+		return actorID, e.requirePermission(ctx, tx, projectID, actorID, calendarPermissions[0])
+	}
+	return "", err
+}
+
+// FeedCalendar reads the project's calendar with a feed token. The feed reads
+// as the actor that created it, so suspending the actor or taking away its
+// permissions stops the feed too.
+func (e Engine) FeedCalendar(ctx context.Context, projectID, token string) ([]domain.CalendarEntry, error) {
+	feed, err := e.Repo.GetCalendarFeedByHash(ctx, repo.HashAPIKey(token))
+	if errors.Is(err, repo.ErrNotFound) || err == nil && feed.ProjectID != projectID {
+		return nil, ErrInvalidFeedToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e.Calendar(ctx, projectID, feed.ActorID)
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// changelogSummaryKeys are the work outcome keys read as a changelog entry's
+// summary; the first holding text wins.
+var changelogSummaryKeys = []string{"summary", "notes", "description"}
+
+// Changelog lists the iteration's done tasks grouped by task type, types
+// sorted by name and tasks by completion. Each entry takes its summary from
+// the summary, notes or description work outcome and its links from the
+// http(s) URLs anywhere in the work outcomes, such as pr or pr_url. It needs
+// iteration.list and task.list.
+func (e Engine) Changelog(ctx context.Context, iterationID, actorID string) (domain.Changelog, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Changelog{}, err
+	}
+	defer tx.Rollback()
+	it, err := e.Repo.GetIterationTx(ctx, tx, iterationID)
+	if err != nil {
+		return domain.Changelog{}, err
+	}
+	for _, perm := range []string{"iteration.list", "task.list"} {
+		if err := e.requirePermission(ctx, tx, it.ProjectID, actorID, perm); err != nil {
+			return domain.Changelog{}, err
+		}
+	}
+	tasks, err := e.Repo.ListTasksTx(ctx, tx, repo.TaskFilters{ProjectID: it.ProjectID, Iteration: it.ID})
+	if err != nil {
+		return domain.Changelog{}, err
+	}
+	tasks = slices.DeleteFunc(tasks, func(t domain.Task) bool { return t.CompletedAt == nil })
+	slices.SortFunc(tasks, func(a, b domain.Task) int {
+		if c := strings.Compare(*a.CompletedAt, *b.CompletedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	c := domain.Changelog{ProjectID: it.ProjectID, IterationID: it.ID, Goal: it.Goal, Sections: []domain.ChangelogSection{}}
+	byType := map[string][]domain.ChangelogEntry{}
+	for _, t := range tasks {
+		entry := domain.ChangelogEntry{TaskID: t.ID, Title: t.Title, AssigneeID: t.AssigneeID, CompletedAt: *t.CompletedAt}
+		if t.WorkOutcomesJSON != nil {
+			var outcomes map[string]any
+			if json.Unmarshal([]byte(*t.WorkOutcomesJSON), &outcomes) == nil {
+				entry.Summary = changelogSummary(outcomes)
+				entry.Links = changelogLinks(outcomes, nil)
+			}
+		}
+		byType[t.Type] = append(byType[t.Type], entry)
+	}
+	for _, typ := range slices.Sorted(maps.Keys(byType)) {
+		c.Sections = append(c.Sections, domain.ChangelogSection{Type: typ, Entries: byType[typ]})
+	}
+	return c, nil
+}
+
+func changelogSummary(outcomes map[string]any) string {
+	for _, key := range changelogSummaryKeys {
+		if s, ok := outcomes[key].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// changelogLinks appends the http(s) URLs in v, walking objects in key order,
+// skipping duplicates.
+func changelogLinks(v any, links []string) []string {
+	switch x := v.(type) {
+	case string:
+		web := strings.HasPrefix(x, "https://") || strings.HasPrefix(x, "http://")
+		if web && !strings.ContainsAny(x, " <>") && !slices.Contains(links, x) {
+			links = append(links, x)
+		}
+	case []any:
+		for _, item := range x {
+			links = changelogLinks(item, links)
+		}
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(x)) {
+			links = changelogLinks(x[k], links)
+		}
+	}
+	return links
+}
+
+// ChangelogMarkdown renders a changelog as Markdown for release notes: a
+// section per task type and a bullet per task with its summary and links.
+func ChangelogMarkdown(c domain.Changelog) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changelog: %s\n\n", changelogEscaper.Replace(c.IterationID))
+	if c.Goal != "" {
+		fmt.Fprintf(&b, "_%s_\n\n", changelogEscaper.Replace(c.Goal))
+	}
+	if len(c.Sections) == 0 {
+		b.WriteString("No tasks done yet.\n")
+		return b.String()
+	}
+	for i, s := range c.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", changelogEscaper.Replace(s.Type))
+		for _, entry := range s.Entries {
+			fmt.Fprintf(&b, "- %s (`%s`)", changelogEscaper.Replace(entry.Title), entry.TaskID)
+			if entry.Summary != "" {
+				fmt.Fprintf(&b, ": %s", changelogEscaper.Replace(entry.Summary))
+			}
+			for _, link := range entry.Links {
+				fmt.Fprintf(&b, " <%s>", link)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+var changelogEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "\n", " ",
+)
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ClaimNextOptions narrows which task ClaimNextTask may pick.
+type ClaimNextOptions struct {
+	ProjectID string
+	// IterationID defaults to the project's running iteration.
+	IterationID       string
+	Types             []string
+	IncludeUnassigned bool
+	ActorID           string
+	LeaseSeconds      int
+}
+
+// ClaimNextTask picks the actor's next task the way NextTask does and claims
+// its lease in the same transaction, so two agents asking at once never get
+// the same task. Tasks another actor holds a live lease on are skipped. It
+// returns repo.ErrNotFound when nothing is available.
+func (e Engine) ClaimNextTask(ctx context.Context, opts ClaimNextOptions) (domain.Task, domain.Lease, error) {
+	if e.Config == nil {
+		return domain.Task{}, domain.Lease{}, errors.New("config not loaded")
+	}
+	if opts.LeaseSeconds <= 0 {
+		return domain.Task{}, domain.Lease{}, errors.New("lease_seconds must be positive")
+	}
+	iterationID := opts.IterationID
+	if iterationID == "" {
+		items, err := e.Repo.ListIterationsWithCursor(ctx, opts.ProjectID, 50, "", "")
+		if err != nil {
+			return domain.Task{}, domain.Lease{}, err
+		}
+		for _, it := range items {
+			if it.Status == "running" {
+				iterationID = it.ID
+				break
+			}
+		}
+		if iterationID == "" {
+			return domain.Task{}, domain.Lease{}, repo.ErrNotFound
+		}
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, domain.Lease{}, err
+	}
+	defer tx.Rollback()
+	for _, perm := range []string{"task.next", "task.claim"} {
+		if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, perm); err != nil {
+			return domain.Task{}, domain.Lease{}, err
+		}
+	}
+	now := e.now().UTC()
+	// Another replica may hold the cluster lock of a task whose lease it has
+	// not written yet; skip it and pick the next one.
+	var contended []string
+	var lease domain.Lease
+	var unlock func()
+	for {
+		taskID, err := e.Repo.NextTaskIDTx(ctx, tx, repo.NextTaskFilters{
+			ProjectID:         opts.ProjectID,
+			IterationID:       iterationID,
+			AssigneeID:        opts.ActorID,
+			IncludeUnassigned: opts.IncludeUnassigned,
+			Types:             opts.Types,
+			FreeAt:            now.Format(time.RFC3339),
+			Exclude:           contended,
+		})
+		if err != nil {
+			return domain.Task{}, domain.Lease{}, err
+		}
+		lease = domain.Lease{
+			TaskID:     taskID,
+			OwnerID:    opts.ActorID,
+			AcquiredAt: now.Format(time.RFC3339),
+			ExpiresAt:  now.Add(time.Duration(opts.LeaseSeconds) * time.Second).Format(time.RFC3339),
+		}
+		unlock, err = e.lockLease(ctx, lease, time.Duration(opts.LeaseSeconds)*time.Second)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLeaseLocked) {
+			return domain.Task{}, domain.Lease{}, err
+		}
+		contended = append(contended, taskID)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			unlock()
+		}
+	}()
+	taskID := lease.TaskID
+	if err := e.Repo.UpsertLease(ctx, tx, lease); err != nil {
+		return domain.Task{}, domain.Lease{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "lease.claimed", opts.ProjectID, "task", taskID, opts.ActorID, events.EventPayload{"expires_at": lease.ExpiresAt}); err != nil {
+		return domain.Task{}, domain.Lease{}, err
+	}
+	t, err := e.Repo.GetTaskTx(ctx, tx, taskID)
+	if err != nil {
+		return domain.Task{}, domain.Lease{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Task{}, domain.Lease{}, err
+	}
+	committed = true
+	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
+	return t, lease, nil
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+)
+
+// rbacGenKey holds a token that is part of every cached permission set key;
+// replacing it after an RBAC change invalidates all of them at once.
+const rbacGenKey = "rbac:gen"
+
+func configCacheKey(projectID string) string {
+	return "config:" + projectID
+}
+
+// actorHasPermission checks perm against the actor's cached permission set,
+// falling back to the database when there is no cache entry. tx may hold
+// uncommitted changes, so sets read through it are never stored; caches are
+// filled from committed data by HasPermission.
+func (e Engine) actorHasPermission(ctx context.Context, tx *sql.Tx, projectID, actorID, perm string) (bool, error) {
+	if e.Perms != nil {
+		version, err := e.Repo.RBACVersionTx(ctx, tx)
+		if err != nil {
+			return false, err
+		}
+		if perms, ok := e.Perms.get(version, projectID, actorID); ok {
+			return slices.Contains(perms, perm), nil
+		}
+	}
+	if gen, ok := e.permissionGen(ctx); ok {
+		if perms, ok := e.cachedPermissions(ctx, gen, projectID, actorID); ok {
+			return slices.Contains(perms, perm), nil
+		}
+	}
+	return e.Auth.ActorHasPermission(ctx, tx, projectID, actorID, perm)
+}
+
+// permissionGen returns the current permission cache generation, starting
+// one when there is none. ok is false without a usable cache.
+func (e Engine) permissionGen(ctx context.Context) ([]byte, bool) {
+	if e.Cache == nil {
+		return nil, false
+	}
+	gen, ok, err := e.Cache.Get(ctx, rbacGenKey)
+	if err == nil && !ok {
+		gen = []byte(uuid.NewString())
+		err = e.Cache.Set(ctx, rbacGenKey, gen)
+	}
+	return gen, err == nil
+}
+
+func permissionsCacheKey(gen []byte, projectID, actorID string) string {
+	return "perms:" + string(gen) + ":" + projectID + ":" + actorID
+}
+
+// cachedPermissions returns the actor's set stored under generation gen.
+func (e Engine) cachedPermissions(ctx context.Context, gen []byte, projectID, actorID string) ([]string, bool) {
+	data, ok, err := e.Cache.Get(ctx, permissionsCacheKey(gen, projectID, actorID))
+	if err != nil || !ok {
+		return nil, false
+	}
+	var perms []string
+	if json.Unmarshal(data, &perms) != nil {
+		return nil, false
+	}
+	return perms, true
+}
+
+// storePermissions caches a set read from committed data under gen, which
+// must have been read before the set: an RBAC change committed in between
+// replaces the generation, orphaning the entry instead of serving it stale.
+func (e Engine) storePermissions(ctx context.Context, gen []byte, projectID, actorID string, perms []string) {
+	if data, err := json.Marshal(perms); err == nil {
+		_ = e.Cache.Set(ctx, permissionsCacheKey(gen, projectID, actorID), data)
+	}
+}
+
+// forgetPermissions drops every cached permission set; call it after
+// committing an RBAC change.
+func (e Engine) forgetPermissions(ctx context.Context) {
+	if e.Cache != nil {
+		_ = e.Cache.Set(ctx, rbacGenKey, []byte(uuid.NewString()))
+	}
+}
+
+// ProjectConfig returns a project's stored config, from the cache when one
+// is configured.
+func (e Engine) ProjectConfig(ctx context.Context, projectID string) (*config.Config, error) {
+	if e.Cache != nil {
+		if data, ok, err := e.Cache.Get(ctx, configCacheKey(projectID)); err == nil && ok {
+			var cfg config.Config
+			if json.Unmarshal(data, &cfg) == nil {
+				return &cfg, cfg.Validate()
+			}
+		}
+	}
+	cfg, err := e.Repo.GetProjectConfig(ctx, projectID)
+	if err != nil || e.Cache == nil {
+		return cfg, err
+	}
+	if data, err := json.Marshal(cfg); err == nil {
+		_ = e.Cache.Set(ctx, configCacheKey(projectID), data)
+	}
+	return cfg, nil
+}
+
+// forgetProjectConfig drops a project's cached config; call it after
+// committing a config change.
+func (e Engine) forgetProjectConfig(ctx context.Context, projectID string) {
+	if e.Cache != nil {
+		_ = e.Cache.Delete(ctx, configCacheKey(projectID))
+	}
+}
+
+// errLeaseLocked reports a lease another replica holds the cluster lock of.
+var errLeaseLocked = errors.New("lease already held")
+
+// lockLease takes the cluster lock for a lease about to be written. The
+// returned func releases it again, for claims that end up not committed.
+func (e Engine) lockLease(ctx context.Context, l domain.Lease, ttl time.Duration) (func(), error) {
+	if e.Locks == nil {
+		return func() {}, nil
+	}
+	ok, err := e.Locks.Acquire(ctx, l.TaskID, l.OwnerID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("lease lock: %w", err)
+	}
+	if !ok {
+		return nil, errLeaseLocked
+	}
+	return func() {
+		_ = e.Locks.Release(context.WithoutCancel(ctx), l.TaskID, l.OwnerID)
+	}, nil
+}
+
+// unlockLeases drops the cluster locks of leases that were deleted.
+func (e Engine) unlockLeases(ctx context.Context, leases ...domain.Lease) {
+	if e.Locks == nil {
+		return
+	}
+	for _, l := range leases {
+		if l.TaskID != "" {
+			_ = e.Locks.Release(ctx, l.TaskID, l.OwnerID)
+		}
+	}
+}
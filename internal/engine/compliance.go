@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// VerifyProject re-checks every completed task of a project against its
+// required attestations, counting attestations valid when the task was
+// completed or added since, and against the policy the config applies
+// today. Tasks short of either are reported; with flag, each also gets a
+// compliance.flagged event, which needs project.verify. Flagging is not
+// deduplicated: every flagged run records new events.
+func (e Engine) VerifyProject(ctx context.Context, projectID, actorID string, flag bool) (domain.ComplianceReport, error) {
+	if e.Config == nil {
+		return domain.ComplianceReport{}, errors.New("config not loaded")
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.ComplianceReport{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ComplianceReport{}, err
+	}
+	defer tx.Rollback()
+	perm := "task.validation.read"
+	if flag {
+		perm = "project.verify"
+	}
+	if err := e.requirePermission(ctx, tx, projectID, actorID, perm); err != nil {
+		return domain.ComplianceReport{}, err
+	}
+	tasks, err := e.Repo.ListTasksTx(ctx, tx, repo.TaskFilters{ProjectID: projectID})
+	if err != nil {
+		return domain.ComplianceReport{}, err
+	}
+	forced, err := e.Repo.ForcedCompletionsTx(ctx, tx, projectID, e.doneStates())
+	if err != nil {
+		return domain.ComplianceReport{}, err
+	}
+	now := e.now().UTC()
+	report := domain.ComplianceReport{
+		ProjectID:    projectID,
+		CheckedAt:    now.Format(time.RFC3339),
+		Flagged:      flag,
+		NonCompliant: []domain.ComplianceIssue{},
+	}
+	for _, t := range tasks {
+		if t.CompletedAt == nil {
+			continue
+		}
+		report.Checked++
+		issue, err := e.taskCompliance(ctx, tx, t)
+		if err != nil {
+			return domain.ComplianceReport{}, err
+		}
+		if len(issue.Missing) == 0 && len(issue.Tightened) == 0 {
+			continue
+		}
+		issue.Forced = forced[t.ID]
+		report.NonCompliant = append(report.NonCompliant, issue)
+		if flag {
+			if err := e.Events.Append(ctx, tx, "compliance.flagged", projectID, "task", t.ID, actorID, events.EventPayload{
+				"missing":   issue.Missing,
+				"tightened": issue.Tightened,
+				"forced":    issue.Forced,
+			}); err != nil {
+				return domain.ComplianceReport{}, err
+			}
+		}
+	}
+	if !flag {
+		return report, nil
+	}
+	return report, tx.Commit()
+}
+
+// taskCompliance lists the kinds a completed task lacks: Missing from its own
+// requirements, Tightened from the current policy's additions.
+func (e Engine) taskCompliance(ctx context.Context, tx *sql.Tx, t domain.Task) (domain.ComplianceIssue, error) {
+	issue := domain.ComplianceIssue{
+		TaskID:      t.ID,
+		Title:       t.Title,
+		Type:        t.Type,
+		CompletedAt: *t.CompletedAt,
+		Missing:     []string{},
+		Tightened:   []string{},
+	}
+	var required []string
+	if t.RequiredAttestationsJSON != nil {
+		if err := json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &required); err != nil {
+			return issue, err
+		}
+	}
+	// A policy since removed from the config only leaves the task's own
+	// requirements to check.
+	_, configured, _ := resolveTaskPolicy(e.Config, t.Component, t.Type, "")
+	if len(required) == 0 && len(configured) == 0 {
+		return issue, nil
+	}
+	atts, err := e.Repo.EntityAttestationsTx(ctx, tx, "task", t.ID)
+	if err != nil {
+		return issue, err
+	}
+	asOf := e.now()
+	if completed, err := time.Parse(time.RFC3339, *t.CompletedAt); err == nil {
+		asOf = completed
+	}
+	valid := map[string]bool{}
+	for _, att := range atts {
+		if !e.AttestationExpired(att, asOf) {
+			valid[att.Kind] = true
+		}
+	}
+	for _, kind := range required {
+		if !valid[kind] {
+			issue.Missing = append(issue.Missing, kind)
+		}
+	}
+	for _, kind := range configured {
+		if !valid[kind] && !slices.Contains(required, kind) {
+			issue.Tightened = append(issue.Tightened, kind)
+		}
+	}
+	return issue, nil
+}
@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// UpdateProjectConfig stores a new project config as the next version and
+// records the change set in a config.updated event.
+func (e Engine) UpdateProjectConfig(ctx context.Context, projectID, actorID string, cfg *config.Config) (domain.ConfigVersion, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.update"); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	v, err := e.saveProjectConfig(ctx, tx, projectID, actorID, cfg, events.EventPayload{})
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	e.forgetProjectConfig(ctx, projectID)
+	return v, nil
+}
+
+// RollbackProjectConfig restores an earlier config version. The restored
+// config is stored as a new version so the history stays append-only.
+func (e Engine) RollbackProjectConfig(ctx context.Context, projectID, actorID string, version int) (domain.ConfigVersion, error) {
+	if version <= 0 {
+		return domain.ConfigVersion{}, errors.New("version is required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.update"); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	target, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, version)
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	var cfg config.Config
+	if err := json.Unmarshal([]byte(target.ConfigJSON), &cfg); err != nil {
+		return domain.ConfigVersion{}, fmt.Errorf("config version %d: %w", version, err)
+	}
+	v, err := e.saveProjectConfig(ctx, tx, projectID, actorID, &cfg, events.EventPayload{"rolled_back_to": version})
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	e.forgetProjectConfig(ctx, projectID)
+	return v, nil
+}
+
+// ProjectConfigVersions lists the stored config versions, newest first.
+func (e Engine) ProjectConfigVersions(ctx context.Context, projectID, actorID string) ([]domain.ConfigVersion, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.config.read"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListProjectConfigVersionsTx(ctx, tx, projectID)
+}
+
+// DiffProjectConfigVersions compares two config versions; version 0 means the latest.
+func (e Engine) DiffProjectConfigVersions(ctx context.Context, projectID, actorID string, from, to int) ([]domain.ConfigChange, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.config.read"); err != nil {
+		return nil, err
+	}
+	fromVersion, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffConfigJSON(fromVersion.ConfigJSON, toVersion.ConfigJSON)
+}
+
+// PlanProjectConfigImport previews importing cfg without storing it: the
+// attestation kinds added and removed, policy and RBAC changes, and warnings
+// for removed kinds that open tasks still require.
+func (e Engine) PlanProjectConfigImport(ctx context.Context, projectID, actorID string, cfg *config.Config) (domain.ConfigImportPlan, error) {
+	if cfg == nil {
+		return domain.ConfigImportPlan{}, errors.New("config is required")
+	}
+	cfg.Project.ID = projectID
+	if err := cfg.Validate(); err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.config.read"); err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	current, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, 0)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return domain.ConfigImportPlan{}, err
+	}
+	plan := domain.ConfigImportPlan{ProjectID: projectID}
+	if plan.Changes, err = diffConfigJSON(current.ConfigJSON, string(payload)); err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	if plan.Changes == nil {
+		plan.Changes = []domain.ConfigChange{}
+	}
+	for _, c := range plan.Changes {
+		switch {
+		case strings.HasPrefix(c.Path, "project.rbac."):
+			plan.RBACChanges = append(plan.RBACChanges, c)
+		case strings.Contains(c.Path, ".policies."), strings.HasSuffix(c.Path, ".policies"):
+			plan.PolicyChanges = append(plan.PolicyChanges, c)
+		}
+	}
+	before := map[string]bool{}
+	if current.ConfigJSON != "" {
+		var old config.Config
+		if err := json.Unmarshal([]byte(current.ConfigJSON), &old); err != nil {
+			return domain.ConfigImportPlan{}, err
+		}
+		for _, att := range old.Project.Attestations {
+			before[att.ID] = true
+		}
+	}
+	after := map[string]bool{}
+	for _, att := range cfg.Project.Attestations {
+		after[att.ID] = true
+		if !before[att.ID] {
+			plan.AddedAttestations = append(plan.AddedAttestations, att.ID)
+		}
+	}
+	for kind := range before {
+		if !after[kind] {
+			plan.RemovedAttestations = append(plan.RemovedAttestations, kind)
+		}
+	}
+	sort.Strings(plan.AddedAttestations)
+	sort.Strings(plan.RemovedAttestations)
+	if len(plan.RemovedAttestations) == 0 {
+		return plan, nil
+	}
+	tasks, err := e.Repo.OpenTaskRequirementsTx(ctx, tx, projectID)
+	if err != nil {
+		return domain.ConfigImportPlan{}, err
+	}
+	for _, kind := range plan.RemovedAttestations {
+		var users []string
+		for _, t := range tasks {
+			if e.workflow(t.Type).IsTerminal(t.Status) {
+				continue
+			}
+			var required []string
+			if err := json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &required); err != nil {
+				return domain.ConfigImportPlan{}, err
+			}
+			for _, r := range required {
+				if r == kind {
+					users = append(users, t.ID)
+					break
+				}
+			}
+		}
+		if len(users) > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("attestation kind %s is removed but required by open tasks: %s", kind, strings.Join(users, ", ")))
+		}
+	}
+	return plan, nil
+}
+
+func (e Engine) saveProjectConfig(ctx context.Context, tx *sql.Tx, projectID, actorID string, cfg *config.Config, payload events.EventPayload) (domain.ConfigVersion, error) {
+	before, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, 0)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return domain.ConfigVersion{}, err
+	}
+	if err := e.Repo.SaveProjectConfigTx(ctx, tx, projectID, actorID, cfg); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	after, err := e.Repo.GetProjectConfigVersionTx(ctx, tx, projectID, 0)
+	if err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	if after.Version == before.Version {
+		return after, nil
+	}
+	if after.Changes, err = diffConfigJSON(before.ConfigJSON, after.ConfigJSON); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	payload["from_version"] = before.Version
+	payload["version"] = after.Version
+	payload["changes"] = after.Changes
+	if err := e.Events.Append(ctx, tx, "config.updated", projectID, "project", projectID, actorID, payload); err != nil {
+		return domain.ConfigVersion{}, err
+	}
+	return after, nil
+}
+
+// secretConfigKeys are the config keys holding credentials: webhook and hook
+// secrets, the SMTP password and encryption keys. Their values never leave
+// the stored config.
+var secretConfigKeys = map[string]bool{"secret": true, "password": true, "key": true, "previous_keys": true}
+
+// redactedConfigValue replaces credentials inside a changed list or object.
+const redactedConfigValue = "[redacted]"
+
+// diffConfigJSON lists the settings that differ between two stored configs,
+// keyed by their workline.yml paths. Objects are walked key by key; lists are
+// compared as a whole. Credentials are redacted.
+func diffConfigJSON(from, to string) ([]domain.ConfigChange, error) {
+	a, err := configTree(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := configTree(to)
+	if err != nil {
+		return nil, err
+	}
+	var changes []domain.ConfigChange
+	diffValues("", a, b, &changes)
+	for i, c := range changes {
+		changes[i] = redactConfigChange(c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// configTree decodes a stored config into a generic tree with YAML key names.
+func configTree(payload string) (map[string]any, error) {
+	if payload == "" {
+		return nil, nil
+	}
+	var cfg config.Config
+	if err := json.Unmarshal([]byte(payload), &cfg); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func diffValues(path string, from, to any, changes *[]domain.ConfigChange) {
+	fromMap, fromIsMap := from.(map[string]any)
+	toMap, toIsMap := to.(map[string]any)
+	if (fromIsMap || from == nil) && (toIsMap || to == nil) && (fromIsMap || toIsMap) {
+		keys := map[string]bool{}
+		for k := range fromMap {
+			keys[k] = true
+		}
+		for k := range toMap {
+			keys[k] = true
+		}
+		for k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			diffValues(child, fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, domain.ConfigChange{Path: path, From: from, To: to})
+	}
+}
+
+// redactConfigChange keeps credentials out of a change: a changed credential
+// is reported by path only, and credentials inside a changed list, such as
+// webhooks, are masked.
+func redactConfigChange(c domain.ConfigChange) domain.ConfigChange {
+	if secretConfigKeys[c.Path[strings.LastIndex(c.Path, ".")+1:]] {
+		return domain.ConfigChange{Path: c.Path, Redacted: true}
+	}
+	from, fromRedacted := redactConfigSecrets(c.From)
+	to, toRedacted := redactConfigSecrets(c.To)
+	return domain.ConfigChange{Path: c.Path, From: from, To: to, Redacted: fromRedacted || toRedacted}
+}
+
+func redactConfigSecrets(v any) (any, bool) {
+	var redacted bool
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			if secretConfigKeys[k] && val != nil && val != "" {
+				out[k] = redactedConfigValue
+				redacted = true
+				continue
+			}
+			var ok bool
+			out[k], ok = redactConfigSecrets(val)
+			redacted = redacted || ok
+		}
+		return out, redacted
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			var ok bool
+			out[i], ok = redactConfigSecrets(val)
+			redacted = redacted || ok
+		}
+		return out, redacted
+	}
+	return v, false
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"workline/internal/domain"
+)
+
+// Dashboard computes quality-gate metrics for a project: how often done tasks
+// actually met their attestation policy, lead time, validation rejection rate
+// and per-iteration overrides.
+func (e Engine) Dashboard(ctx context.Context, projectID, actorID string) (domain.Dashboard, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.Dashboard{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.status.read"); err != nil {
+		return domain.Dashboard{}, err
+	}
+	done, err := e.Repo.DoneTasksTx(ctx, tx, projectID)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	forced, err := e.Repo.ForcedCompletionsTx(ctx, tx, projectID, e.doneStates())
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	overrides, err := e.Repo.PolicyOverridesByIterationTx(ctx, tx, projectID)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	validations, err := e.Repo.ValidationStatusCountsTx(ctx, tx, projectID)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+
+	d := domain.Dashboard{ProjectID: projectID, DoneTasks: len(done)}
+	perIteration := map[string]*domain.IterationQuality{}
+	iteration := func(id string) *domain.IterationQuality {
+		q, ok := perIteration[id]
+		if !ok {
+			q = &domain.IterationQuality{IterationID: id}
+			perIteration[id] = q
+		}
+		return q
+	}
+	var leadTotal time.Duration
+	var leadCount int
+	for _, t := range done {
+		if !forced[t.ID] {
+			// Coverage is judged when the task completed, not against
+			// attestations that have expired since.
+			asOf := e.now()
+			if t.CompletedAt != nil {
+				if completed, err := time.Parse(time.RFC3339, *t.CompletedAt); err == nil {
+					asOf = completed
+				}
+			}
+			ok, err := e.taskAttestationsSatisfied(ctx, tx, t, asOf)
+			if err != nil {
+				return domain.Dashboard{}, err
+			}
+			if ok {
+				d.CoveredTasks++
+			}
+		}
+		if t.CompletedAt != nil {
+			created, errC := time.Parse(time.RFC3339, t.CreatedAt)
+			completed, errD := time.Parse(time.RFC3339, *t.CompletedAt)
+			if errC == nil && errD == nil && !completed.Before(created) {
+				leadTotal += completed.Sub(created)
+				leadCount++
+			}
+		}
+		if t.IterationID != nil {
+			q := iteration(*t.IterationID)
+			q.DoneTasks++
+			if forced[t.ID] {
+				q.ForcedCompletions++
+			}
+		}
+	}
+	for id, n := range overrides {
+		iteration(id).PolicyOverrides = n
+	}
+	if d.DoneTasks > 0 {
+		d.AttestationCoverage = round2(100 * float64(d.CoveredTasks) / float64(d.DoneTasks))
+	}
+	if leadCount > 0 {
+		d.AvgLeadTimeHours = round2(leadTotal.Hours() / float64(leadCount))
+	}
+	d.ValidationsRejected = validations["rejected"]
+	d.ValidationsDecided = validations["accepted"] + validations["rejected"]
+	if d.ValidationsDecided > 0 {
+		d.RejectionRate = round2(100 * float64(d.ValidationsRejected) / float64(d.ValidationsDecided))
+	}
+	d.Iterations = make([]domain.IterationQuality, 0, len(perIteration))
+	for _, q := range perIteration {
+		d.Iterations = append(d.Iterations, *q)
+	}
+	sort.Slice(d.Iterations, func(i, j int) bool { return d.Iterations[i].IterationID < d.Iterations[j].IterationID })
+	return d, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// ListDecisions returns the project's decisions by number. It needs
+// decision.list.
+func (e Engine) ListDecisions(ctx context.Context, projectID, actorID string) ([]domain.Decision, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "decision.list"); err != nil {
+		return nil, err
+	}
+	decisions, err := e.Repo.ListDecisionsTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if decisions == nil {
+		decisions = []domain.Decision{}
+	}
+	return decisions, nil
+}
+
+// ADRFileName names the ADR of d after its number and title, such as
+// 0003-adopt-go-for-backend.md, so exports keep rewriting the same file.
+func ADRFileName(d domain.Decision) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(d.Title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	slug := b.String()
+	if slug == "" {
+		slug = "decision"
+	}
+	return fmt.Sprintf("%04d-%s.md", d.Number, slug)
+}
+
+// DecisionADR renders d as a Markdown Architecture Decision Record: status,
+// context, decision, and consequences from its rationale and alternatives.
+// Decisions are recorded once made, so the status is always Accepted.
+func DecisionADR(d domain.Decision) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %d. %s\n\n", d.Number, strings.ReplaceAll(d.Title, "\n", " "))
+	fmt.Fprintf(&b, "Date: %s\n\n", digestDay(d.CreatedAt))
+	b.WriteString("## Status\n\n")
+	fmt.Fprintf(&b, "Accepted, decided by %s (decision `%s`).\n\n", d.DeciderID, d.ID)
+	b.WriteString("## Context\n\n")
+	b.WriteString(adrContext(d.ContextJSON))
+	b.WriteString("\n## Decision\n\n")
+	fmt.Fprintf(&b, "%s\n", strings.TrimSpace(d.Decision))
+	b.WriteString("\n## Consequences\n\n")
+	b.WriteString(adrList(d.RationaleJSON))
+	if alternatives := adrList(d.AlternativesJSON); alternatives != "None recorded.\n" {
+		b.WriteString("\n### Alternatives considered\n\n")
+		b.WriteString(alternatives)
+	}
+	return b.String()
+}
+
+// adrContext renders decision context: text as is, an object as a list of
+// its fields.
+func adrContext(raw string) string {
+	var v any
+	if strings.TrimSpace(raw) == "" || json.Unmarshal([]byte(raw), &v) != nil {
+		if raw = strings.TrimSpace(raw); raw != "" {
+			return raw + "\n"
+		}
+		return "None recorded.\n"
+	}
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x) + "\n"
+	case map[string]any:
+		if len(x) == 0 {
+			return "None recorded.\n"
+		}
+		var b strings.Builder
+		for _, k := range slices.Sorted(maps.Keys(x)) {
+			fmt.Fprintf(&b, "- %s: %s\n", k, adrValue(x[k]))
+		}
+		return b.String()
+	}
+	return adrValue(v) + "\n"
+}
+
+// adrList renders a JSON list of rationale or alternatives as bullets.
+func adrList(raw string) string {
+	var items []any
+	if json.Unmarshal([]byte(raw), &items) != nil || len(items) == 0 {
+		return "None recorded.\n"
+	}
+	var b strings.Builder
+	for _, item := range items {
+		fmt.Fprintf(&b, "- %s\n", adrValue(item))
+	}
+	return b.String()
+}
+
+func adrValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", " ")
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
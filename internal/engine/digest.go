@@ -0,0 +1,293 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/mail"
+)
+
+// DigestLookahead is how far ahead a digest lists milestones coming due.
+const DigestLookahead = 14 * 24 * time.Hour
+
+// DigestSentEvent records a sent digest; the next digest starts where it ended.
+const DigestSentEvent = "digest.sent"
+
+// Digest builds the project digest the next send would email, without sending
+// it. It covers the time since the previous digest, or one schedule period
+// when none was sent.
+func (e Engine) Digest(ctx context.Context, projectID, actorID string) (domain.Digest, error) {
+	last, err := e.lastDigest(ctx, projectID)
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.status.read"); err != nil {
+		return domain.Digest{}, err
+	}
+	d, _, err := e.buildDigest(ctx, tx, projectID, last)
+	return d, err
+}
+
+// SendDigest emails the project digest to the configured recipients and
+// records a digest.sent event. Needs digest.send.
+func (e Engine) SendDigest(ctx context.Context, projectID, actorID string) (domain.Digest, error) {
+	if err := e.requireWritable("digest.send"); err != nil {
+		return domain.Digest{}, err
+	}
+	if err := e.checkDigestConfig(); err != nil {
+		return domain.Digest{}, err
+	}
+	last, err := e.lastDigest(ctx, projectID)
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "digest.send"); err != nil {
+		return domain.Digest{}, err
+	}
+	return e.sendDigest(ctx, tx, projectID, actorID, last)
+}
+
+// SendDueDigest sends the scheduled digest when its latest slot has passed
+// since the previous one went out. It runs as a system job, without
+// permission checks, and reports whether a digest was sent.
+func (e Engine) SendDueDigest(ctx context.Context, projectID, actorID string) (bool, error) {
+	if e.Config == nil || e.Config.Digest.Schedule == "" || e.ReadOnly {
+		return false, nil
+	}
+	slot := e.Config.Digest.LastSlot(e.now())
+	last, err := e.lastDigest(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+	if last != nil {
+		if sent, err := time.Parse(time.RFC3339, last.TS); err == nil && !sent.Before(slot) {
+			return false, nil
+		}
+	}
+	if err := e.checkDigestConfig(); err != nil {
+		return false, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if _, err := e.sendDigest(ctx, tx, projectID, actorID, last); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (e Engine) checkDigestConfig() error {
+	if e.Config == nil {
+		return errors.New("config not loaded")
+	}
+	if len(e.Config.Digest.Recipients) == 0 {
+		return errors.New("invalid digest config: digest.recipients is required")
+	}
+	if e.Mail == nil && strings.TrimSpace(e.Config.Digest.SMTP.Host) == "" {
+		return errors.New("invalid digest config: digest.smtp.host is required")
+	}
+	return nil
+}
+
+// sendDigest builds, mails and records a digest. The event is committed only
+// after the mail server accepted the message, so a failed send is retried
+// with the same window.
+func (e Engine) sendDigest(ctx context.Context, tx *sql.Tx, projectID, actorID string, last *domain.Event) (domain.Digest, error) {
+	d, blocked, err := e.buildDigest(ctx, tx, projectID, last)
+	if err != nil {
+		return d, err
+	}
+	done := []string{}
+	for _, t := range d.DoneTasks {
+		done = append(done, t.ID)
+	}
+	sender := e.Mail
+	if sender == nil {
+		sender = mail.SMTP{Config: e.Config.Digest.SMTP}
+	}
+	subject, body := DigestMessage(d)
+	if err := sender.Send(ctx, mail.Message{From: e.Config.Digest.SMTP.From, To: d.Recipients, Subject: subject, Body: body}); err != nil {
+		return d, fmt.Errorf("send digest: %w", err)
+	}
+	if err := e.Events.Append(ctx, tx, DigestSentEvent, projectID, "project", projectID, actorID, events.EventPayload{
+		"since":      d.Since,
+		"until":      d.Until,
+		"recipients": d.Recipients,
+		"done":       done,
+		"blocked":    blocked,
+	}); err != nil {
+		return d, err
+	}
+	return d, tx.Commit()
+}
+
+// lastDigest returns the project's latest digest.sent event, or nil when the
+// project never sent one.
+func (e Engine) lastDigest(ctx context.Context, projectID string) (*domain.Event, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	evts, err := e.Repo.LatestEvents(ctx, 1, projectID, DigestSentEvent, "project", projectID)
+	if err != nil || len(evts) == 0 {
+		return nil, err
+	}
+	return &evts[0], nil
+}
+
+// buildDigest collects the digest since last and returns it with the ids of
+// every task blocked now, which the next digest compares against.
+func (e Engine) buildDigest(ctx context.Context, tx *sql.Tx, projectID string, last *domain.Event) (domain.Digest, []string, error) {
+	until := e.now().UTC()
+	var since time.Time
+	var afterID int64
+	var prev struct {
+		Done    []string `json:"done"`
+		Blocked []string `json:"blocked"`
+	}
+	if last != nil {
+		since, _ = time.Parse(time.RFC3339, last.TS)
+		afterID = last.ID
+		_ = json.Unmarshal([]byte(last.Payload), &prev)
+	}
+	if since.IsZero() {
+		period := 7 * 24 * time.Hour
+		if e.Config != nil {
+			period = e.Config.Digest.Period()
+		}
+		since = until.Add(-period)
+	}
+	d := domain.Digest{
+		ProjectID:    projectID,
+		Since:        since.Format(time.RFC3339),
+		Until:        until.Format(time.RFC3339),
+		Recipients:   []string{},
+		DoneTasks:    []domain.ReportTask{},
+		NewlyBlocked: []domain.ReportBlocker{},
+		Overrides:    []domain.DigestOverride{},
+		Deadlines:    []domain.DigestDeadline{},
+	}
+	if e.Config != nil {
+		d.Recipients = append(d.Recipients, e.Config.Digest.Recipients...)
+	}
+	done, err := e.Repo.CompletedTasksTx(ctx, tx, projectID, d.Since, d.Until)
+	if err != nil {
+		return d, nil, err
+	}
+	for _, t := range done {
+		// Tasks done in the second the previous digest went out are in
+		// both windows; it already reported some of them.
+		if !slices.Contains(prev.Done, t.ID) {
+			d.DoneTasks = append(d.DoneTasks, t)
+		}
+	}
+	blockers, err := e.Repo.BlockedTasksTx(ctx, tx, projectID)
+	if err != nil {
+		return d, nil, err
+	}
+	blocked := []string{}
+	for _, b := range blockers {
+		blocked = append(blocked, b.ID)
+		if !slices.Contains(prev.Blocked, b.ID) {
+			d.NewlyBlocked = append(d.NewlyBlocked, b)
+		}
+	}
+	overrides, err := e.Repo.OverrideEventsTx(ctx, tx, projectID, d.Since, afterID)
+	if err != nil {
+		return d, nil, err
+	}
+	d.Overrides = append(d.Overrides, overrides...)
+
+	milestones, err := e.Repo.ListMilestonesTx(ctx, tx, projectID)
+	if err != nil {
+		return d, nil, err
+	}
+	iterations, err := e.Repo.ListIterationsTx(ctx, tx, projectID)
+	if err != nil {
+		return d, nil, err
+	}
+	counts, err := e.Repo.IterationTaskCountsTx(ctx, tx, projectID)
+	if err != nil {
+		return d, nil, err
+	}
+	today := until.Format(time.DateOnly)
+	horizon := until.Add(DigestLookahead).Format(time.DateOnly)
+	for _, m := range milestones {
+		if m.TargetDate < today || m.TargetDate > horizon {
+			continue
+		}
+		dl := domain.DigestDeadline{MilestoneID: m.ID, Goal: m.Goal, TargetDate: m.TargetDate, Iterations: []domain.ReportIteration{}}
+		for _, it := range iterations {
+			if !slices.Contains(m.IterationIDs, it.ID) || it.Status == "validated" {
+				continue
+			}
+			ri := counts[it.ID]
+			ri.ID, ri.Goal, ri.Status = it.ID, it.Goal, it.Status
+			dl.Iterations = append(dl.Iterations, ri)
+		}
+		d.Deadlines = append(d.Deadlines, dl)
+	}
+	slices.SortStableFunc(d.Deadlines, func(a, b domain.DigestDeadline) int { return strings.Compare(a.TargetDate, b.TargetDate) })
+	return d, blocked, nil
+}
+
+// DigestMessage renders a digest as an email subject and plain-text body.
+func DigestMessage(d domain.Digest) (string, string) {
+	since, until := digestDay(d.Since), digestDay(d.Until)
+	subject := fmt.Sprintf("[%s] Digest %s to %s", d.ProjectID, since, until)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Workline digest for %s, %s to %s\n", d.ProjectID, since, until)
+
+	fmt.Fprintf(&b, "\nCompleted (%d)\n", len(d.DoneTasks))
+	for _, t := range d.DoneTasks {
+		fmt.Fprintf(&b, "- %s (%s, %s)\n", t.Title, t.ID, digestDay(t.CompletedAt))
+	}
+	fmt.Fprintf(&b, "\nNewly blocked (%d)\n", len(d.NewlyBlocked))
+	for _, bl := range d.NewlyBlocked {
+		fmt.Fprintf(&b, "- %s (%s, %s) waiting on %s\n", bl.Title, bl.ID, bl.Status, strings.Join(bl.BlockedBy, ", "))
+	}
+	fmt.Fprintf(&b, "\nPolicy overrides (%d)\n", len(d.Overrides))
+	for _, o := range d.Overrides {
+		what := o.Type
+		if o.Type != "policy.override" {
+			what += " (forced)"
+		}
+		fmt.Fprintf(&b, "- %s on %s (%s) by %s, %s\n", what, o.Title, o.TaskID, o.ActorID, digestDay(o.TS))
+	}
+	fmt.Fprintf(&b, "\nUpcoming deadlines (%d)\n", len(d.Deadlines))
+	for _, dl := range d.Deadlines {
+		fmt.Fprintf(&b, "- %s: %s (%s)\n", dl.TargetDate, dl.Goal, dl.MilestoneID)
+		for _, it := range dl.Iterations {
+			fmt.Fprintf(&b, "    %s %s, %d/%d tasks done\n", it.ID, it.Status, it.DoneTasks, it.TotalTasks)
+		}
+	}
+	return subject, b.String()
+}
+
+// digestDay shortens an RFC 3339 timestamp to its date.
+func digestDay(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.UTC().Format(time.DateOnly)
+}
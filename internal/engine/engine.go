@@ -6,32 +6,62 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"workline/internal/blob"
+	"workline/internal/cluster"
 	"workline/internal/config"
 	"workline/internal/domain"
 	"workline/internal/engine/auth"
 	"workline/internal/events"
+	"workline/internal/fieldcrypt"
+	"workline/internal/jsonschema"
+	"workline/internal/mail"
 	"workline/internal/repo"
 )
 
 type Engine struct {
-	DB     *sql.DB
-	Repo   repo.Repo
+	DB *sql.DB
+	// Repo is the storage backend; New uses the SQLite repo.Repo.
+	Repo   repo.Repository
 	Events events.Writer
 	Config *config.Config
 	Now    func() time.Time
 	Auth   auth.Service
+	// Blobs stores attestation evidence; nil disables uploads.
+	Blobs blob.Store
+	// Locks arbitrates leases between server replicas; nil relies on the
+	// database alone.
+	Locks cluster.Locks
+	// Cache shares project configs and RBAC permission sets between
+	// replicas; nil reads them from the database every time.
+	Cache cluster.Cache
+	// Perms keeps permission sets in process, in front of Cache and the
+	// database; nil skips it.
+	Perms *PermissionCache
+	// Mail sends digests; nil sends through the digest.smtp settings.
+	Mail mail.Sender
+	// ReadOnly rejects every operation that needs a write permission.
+	ReadOnly bool
 }
 
 func New(db *sql.DB, cfg *config.Config) Engine {
+	w := events.Writer{DB: db}
+	r := repo.Repo{DB: db}
+	if cfg != nil {
+		if cfg.Outbox.Enabled() {
+			w.Outbox = cfg.Outbox.Route
+		}
+		r.Fields = fieldcrypt.New(cfg.Encryption)
+	}
 	return Engine{
 		DB:     db,
-		Repo:   repo.Repo{DB: db},
-		Events: events.Writer{DB: db},
+		Repo:   r,
+		Events: w,
 		Config: cfg,
 		Now:    time.Now,
 		Auth:   auth.Service{DB: db},
@@ -45,8 +75,13 @@ func (e Engine) now() time.Time {
 	return time.Now()
 }
 
-// InitProject initializes a new project with migrations already run.
-func (e Engine) InitProject(ctx context.Context, projectID, orgID, description, actorID string) (domain.Project, error) {
+// InitProject initializes a new project with migrations already run. An empty
+// kind means config.DefaultKind; the seed config must keep the kind's policy
+// defaults.
+func (e Engine) InitProject(ctx context.Context, projectID, orgID, kind, description, actorID string) (domain.Project, error) {
+	if err := e.requireWritable("project.create"); err != nil {
+		return domain.Project{}, err
+	}
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return domain.Project{}, err
@@ -56,10 +91,14 @@ func (e Engine) InitProject(ctx context.Context, projectID, orgID, description,
 	if orgID == "" {
 		return domain.Project{}, errors.New("org_id is required")
 	}
+	kind, err = config.NormalizeKind(kind)
+	if err != nil {
+		return domain.Project{}, err
+	}
 	p := domain.Project{
 		ID:          projectID,
 		OrgID:       orgID,
-		Kind:        "software-project",
+		Kind:        kind,
 		Status:      "active",
 		Description: description,
 		CreatedAt:   e.now().UTC().Format(time.RFC3339),
@@ -73,7 +112,13 @@ func (e Engine) InitProject(ctx context.Context, projectID, orgID, description,
 	}
 	seedCfg := e.Config
 	if seedCfg == nil {
-		seedCfg = config.Default(p.ID)
+		seedCfg, err = config.ForKind(kind, p.ID)
+		if err != nil {
+			return domain.Project{}, err
+		}
+	}
+	if err := seedCfg.CheckKind(kind); err != nil {
+		return domain.Project{}, err
 	}
 	seedCfg.Project.ID = p.ID
 	if err := e.Repo.UpsertProjectConfigTx(ctx, tx, p.ID, seedCfg); err != nil {
@@ -91,26 +136,36 @@ func (e Engine) InitProject(ctx context.Context, projectID, orgID, description,
 	if err := tx.Commit(); err != nil {
 		return domain.Project{}, err
 	}
+	e.forgetProjectConfig(ctx, p.ID)
+	e.forgetPermissions(ctx)
 	return p, nil
 }
 
 // TaskCreateOptions are parameters for creating a task.
 type TaskCreateOptions struct {
-	ID               string
-	ProjectID        string
-	IterationID      string
-	ParentID         string
-	Type             string
-	Title            string
-	Description      string
-	DependsOn        []string
-	AssigneeID       string
-	Priority         *int
+	ID          string
+	ProjectID   string
+	IterationID string
+	ParentID    string
+	Type        string
+	Component   string
+	Title       string
+	Description string
+	DependsOn   []string
+	AssigneeID  string
+	Priority    *int
+	Estimate    *float64
+	// DueAt is RFC3339 or YYYY-MM-DD (end of that day, UTC); empty takes
+	// the due date from project.sla, if a rule matches.
+	DueAt            string
 	WorkOutcomesJSON *string
 	PolicyPreset     string
 	RequiredKinds    []string
 	ActorID          string
 	PolicyOverride   bool
+	// ExternalRefs links the task to its ids in external systems, keyed by
+	// system.
+	ExternalRefs map[string]string
 }
 
 func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.Task, error) {
@@ -126,19 +181,31 @@ func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.
 	if opts.Title == "" {
 		return domain.Task{}, errors.New("title is required")
 	}
+	if e.Config != nil && !e.Config.HasComponent(opts.Component) {
+		return domain.Task{}, fmt.Errorf("unknown component %s", opts.Component)
+	}
 	if opts.ProjectID == "" {
 		return domain.Task{}, errors.New("project is required")
 	}
+	if err := validateEstimate(opts.Estimate, "estimate"); err != nil {
+		return domain.Task{}, err
+	}
+	dueAt, err := parseDueAt(opts.DueAt)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := validateExternalRefs(opts.ExternalRefs, false); err != nil {
+		return domain.Task{}, err
+	}
 	cfg := e.Config
 	if cfg == nil {
-		cfgFromDB, err := e.Repo.GetProjectConfig(ctx, opts.ProjectID)
+		cfgFromDB, err := e.ProjectConfig(ctx, opts.ProjectID)
 		if err != nil {
 			return domain.Task{}, errors.New("config not loaded")
 		}
 		cfg = cfgFromDB
 	}
-	_, err := e.Repo.GetProject(ctx, opts.ProjectID)
-	if err != nil {
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
 		return domain.Task{}, err
 	}
 	if opts.IterationID != "" {
@@ -162,25 +229,23 @@ func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.
 			return domain.Task{}, err
 		}
 	}
-	id := opts.ID
-	now := e.now().UTC().Format(time.RFC3339)
-	if id == "" {
-		id = uuid.NewSHA1(uuid.NameSpaceOID, []byte(opts.ProjectID+"|"+opts.Title+"|"+now)).String()
+	created := e.now().UTC()
+	now := created.Format(time.RFC3339)
+	if dueAt == nil {
+		dueAt = slaDueAt(cfg, opts.Type, opts.Priority, created)
 	}
 	var reqJSON *string
 	policyName := opts.PolicyPreset
 	manualPolicy := opts.PolicyOverride
 	if !manualPolicy {
-		if policyName == "" {
-			policyName = cfg.DefaultTaskPolicyName(opts.Type)
+		var required []string
+		policyName, required, err = resolveTaskPolicy(cfg, opts.Component, opts.Type, policyName)
+		if err != nil {
+			return domain.Task{}, err
 		}
 		if policyName != "" {
-			policy, ok := cfg.TaskPolicy(opts.Type, policyName)
-			if !ok {
-				return domain.Task{}, fmt.Errorf("policy %s not found for task type %s", policyName, opts.Type)
-			}
-			opts.RequiredKinds = policy.All
-			reqJSON, err = marshalStringSlice(policy.All)
+			opts.RequiredKinds = required
+			reqJSON, err = marshalStringSlice(required)
 			if err != nil {
 				return domain.Task{}, err
 			}
@@ -196,22 +261,28 @@ func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.
 		if err := validateJSON(*opts.WorkOutcomesJSON); err != nil {
 			return domain.Task{}, fmt.Errorf("work-outcomes-json: %w", err)
 		}
+		if err := e.validateWorkOutcomes(opts.Type, *opts.WorkOutcomesJSON); err != nil {
+			return domain.Task{}, err
+		}
 	}
 	t := domain.Task{
-		ID:                       id,
+		ID:                       opts.ID,
 		ProjectID:                opts.ProjectID,
 		IterationID:              optionalString(opts.IterationID),
 		ParentID:                 optionalString(opts.ParentID),
 		Type:                     opts.Type,
+		Component:                opts.Component,
 		Title:                    opts.Title,
 		Description:              opts.Description,
-		Status:                   "planned",
+		Status:                   cfg.TaskWorkflow(opts.Type).Initial,
 		AssigneeID:               optionalString(opts.AssigneeID),
 		Priority:                 opts.Priority,
+		Estimate:                 opts.Estimate,
 		WorkOutcomesJSON:         opts.WorkOutcomesJSON,
 		RequiredAttestationsJSON: reqJSON,
 		CreatedAt:                now,
 		UpdatedAt:                now,
+		DueAt:                    dueAt,
 	}
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -221,6 +292,18 @@ func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.
 	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "task.create"); err != nil {
 		return domain.Task{}, err
 	}
+	if t.ID == "" {
+		if t.ID, err = e.newEntityID(ctx, tx, cfg, opts.ProjectID, "task", opts.Title, now); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	warning, err := e.checkIterationCapacity(ctx, tx, opts.IterationID, t.ID, t.Estimate, opts.ActorID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if warning != "" {
+		t.Warnings = append(t.Warnings, warning)
+	}
 
 	if err := e.Repo.InsertTask(ctx, tx, t); err != nil {
 		return domain.Task{}, err
@@ -244,7 +327,20 @@ func (e Engine) CreateTask(ctx context.Context, opts TaskCreateOptions) (domain.
 			return domain.Task{}, err
 		}
 	}
-	if err := e.Events.Append(ctx, tx, "task.created", t.ProjectID, "task", t.ID, opts.ActorID, events.EventPayload{"title": t.Title, "status": t.Status}); err != nil {
+	if len(opts.ExternalRefs) > 0 {
+		if err := e.setExternalRefsTx(ctx, tx, t.ProjectID, "task", t.ID, opts.ExternalRefs); err != nil {
+			return domain.Task{}, err
+		}
+		t.ExternalRefs = opts.ExternalRefs
+	}
+	createdPayload := events.EventPayload{"title": t.Title, "status": t.Status}
+	if t.DueAt != nil {
+		createdPayload["due_at"] = *t.DueAt
+	}
+	if len(t.ExternalRefs) > 0 {
+		createdPayload["external_refs"] = t.ExternalRefs
+	}
+	if err := e.Events.Append(ctx, tx, "task.created", t.ProjectID, "task", t.ID, opts.ActorID, createdPayload); err != nil {
 		return domain.Task{}, err
 	}
 	if err := tx.Commit(); err != nil {
@@ -286,26 +382,53 @@ func (e Engine) ensureNoCycle(ctx context.Context, parentID, childID string) err
 }
 
 func (e Engine) ensureActor(ctx context.Context, tx *sql.Tx, actorID string) error {
+	// A read-only engine never registers actors; unknown actors just hold no grants.
+	if e.ReadOnly {
+		return nil
+	}
 	return e.Auth.EnsureActor(ctx, tx, actorID)
 }
 
-func (e Engine) requirePermission(ctx context.Context, tx *sql.Tx, projectID, actorID, perm string) error {
+// requireActiveActor registers the actor if needed and rejects suspended actors.
+func (e Engine) requireActiveActor(ctx context.Context, tx *sql.Tx, actorID string) error {
 	if err := e.ensureActor(ctx, tx, actorID); err != nil {
 		return err
 	}
-	ok, err := e.Auth.ActorHasPermission(ctx, tx, projectID, actorID, perm)
+	suspended, err := e.Auth.ActorSuspended(ctx, tx, actorID)
+	if err != nil {
+		return err
+	}
+	if suspended {
+		return auth.SuspendedActorError{ActorID: actorID}
+	}
+	return nil
+}
+
+func (e Engine) requirePermission(ctx context.Context, tx *sql.Tx, projectID, actorID, perm string) error {
+	if err := e.requireWritable(perm); err != nil {
+		return err
+	}
+	if err := e.requireActiveActor(ctx, tx, actorID); err != nil {
+		return err
+	}
+	ok, err := e.actorHasPermission(ctx, tx, projectID, actorID, perm)
 	if err != nil {
 		return err
 	}
 	if !ok {
-		_ = e.Events.Append(ctx, tx, "auth.denied", projectID, "rbac", projectID, actorID, events.EventPayload{"permission": perm, "reason": "missing_permission"})
+		if !e.ReadOnly {
+			_ = e.Events.Append(ctx, tx, "auth.denied", projectID, "rbac", projectID, actorID, events.EventPayload{"permission": perm, "reason": "missing_permission"})
+		}
 		return auth.ForbiddenError{Permission: perm}
 	}
-	return nil
+	return e.requireNotArchived(ctx, tx, projectID, perm)
 }
 
 func (e Engine) requireAttestationAuthority(ctx context.Context, tx *sql.Tx, projectID, actorID, kind string) error {
-	if err := e.ensureActor(ctx, tx, actorID); err != nil {
+	if err := e.requireWritable("attestation.add"); err != nil {
+		return err
+	}
+	if err := e.requireActiveActor(ctx, tx, actorID); err != nil {
 		return err
 	}
 	ok, err := e.Auth.ActorCanAttest(ctx, tx, projectID, actorID, kind)
@@ -316,14 +439,40 @@ func (e Engine) requireAttestationAuthority(ctx context.Context, tx *sql.Tx, pro
 		_ = e.Events.Append(ctx, tx, "auth.denied", projectID, "rbac", projectID, actorID, events.EventPayload{"kind": kind, "reason": "missing_authority"})
 		return auth.ForbiddenAttestationError{Kind: kind}
 	}
-	return nil
+	return e.requireNotArchived(ctx, tx, projectID, "attestation.add")
+}
+
+// requireDoneRole enforces the task type's done_roles; force does not lift it.
+func (e Engine) requireDoneRole(ctx context.Context, tx *sql.Tx, t domain.Task, actorID string) error {
+	if e.Config == nil {
+		return nil
+	}
+	allowed := e.Config.DoneRoles(t.Type)
+	if len(allowed) == 0 {
+		return nil
+	}
+	roles, err := e.Auth.ActorRoles(ctx, tx, t.ProjectID, actorID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if slices.Contains(allowed, role) {
+			return nil
+		}
+	}
+	_ = e.Events.Append(ctx, tx, "auth.denied", t.ProjectID, "rbac", t.ProjectID, actorID, events.EventPayload{"task_id": t.ID, "task_type": t.Type, "roles": allowed, "reason": "missing_done_role"})
+	return auth.ForbiddenRoleError{TaskType: t.Type, Roles: allowed}
 }
 
 func (e Engine) requireForcePermission(ctx context.Context, tx *sql.Tx, projectID, actorID string) error {
 	if err := e.requirePermission(ctx, tx, projectID, actorID, "force.use"); err != nil {
 		return err
 	}
-	return e.Events.Append(ctx, tx, "force.used", projectID, "rbac", projectID, actorID, events.EventPayload{})
+	payload := events.EventPayload{}
+	if requestID, ok := ctx.Value(forceApprovedKey{}).(string); ok {
+		payload["request_id"] = requestID
+	}
+	return e.Events.Append(ctx, tx, "force.used", projectID, "rbac", projectID, actorID, payload)
 }
 
 // TaskUpdateOptions encapsulates allowed updates.
@@ -342,12 +491,26 @@ type TaskUpdateOptions struct {
 	SetPriority       *int
 	PriorityProvided  bool
 	ClearPriority     bool
+	SetEstimate       *float64
+	EstimateProvided  bool
+	ClearEstimate     bool
+	// SetDueAt is RFC3339 or YYYY-MM-DD; nil or empty clears the due date.
+	SetDueAt          *string
+	DueAtProvided     bool
+	SetIteration      *string
+	IterationProvided bool
 	PolicyPreset      string
 	RequiredKinds     []string
 	RequiredKindsSet  bool
 	ActorID           string
 	Force             bool
 	PolicyOverride    bool
+	// SetComponent moves the task to a component; "" removes it from its
+	// component. Required attestations are left as they are.
+	SetComponent *string
+	// SetExternalRefs sets the task's ids in external systems, keyed by
+	// system; an empty id removes the system's id. Other systems are kept.
+	SetExternalRefs map[string]string
 }
 
 func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.Task, error) {
@@ -358,16 +521,35 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 	if err != nil {
 		return t, err
 	}
-	if t.Status == "" {
-		t.Status = "planned"
+	if opts.Force && e.forceNeedsApproval(ctx) {
+		return t, e.requestForce(ctx, t.ProjectID, "task.update", "task", t.ID, opts, opts.ActorID)
 	}
-	oldPolicy := currentPolicy(t)
-	original := t
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return t, err
 	}
 	defer tx.Rollback()
+	if t, err = e.updateTaskTx(ctx, tx, t, opts); err != nil {
+		return t, err
+	}
+	if err := tx.Commit(); err != nil {
+		return t, err
+	}
+	e.sendHookDeliveries(ctx)
+	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
+	return t, nil
+}
+
+// updateTaskTx applies opts to the loaded task t inside tx, recording events
+// and running transition hooks. The caller commits.
+func (e Engine) updateTaskTx(ctx context.Context, tx *sql.Tx, t domain.Task, opts TaskUpdateOptions) (domain.Task, error) {
+	workflow := e.workflow(t.Type)
+	if t.Status == "" {
+		t.Status = workflow.Initial
+	}
+	oldPolicy := currentPolicy(t)
+	original := t
+	var err error
 	if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.update"); err != nil {
 		return t, err
 	}
@@ -402,6 +584,62 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 			t.Priority = opts.SetPriority
 		}
 	}
+	if opts.EstimateProvided {
+		if opts.ClearEstimate {
+			t.Estimate = nil
+		} else {
+			if err := validateEstimate(opts.SetEstimate, "estimate"); err != nil {
+				return t, err
+			}
+			t.Estimate = opts.SetEstimate
+		}
+	}
+	if opts.DueAtProvided {
+		t.DueAt = nil
+		if opts.SetDueAt != nil {
+			if t.DueAt, err = parseDueAt(*opts.SetDueAt); err != nil {
+				return t, err
+			}
+		}
+	}
+	if opts.IterationProvided {
+		if opts.SetIteration == nil || *opts.SetIteration == "" {
+			t.IterationID = nil
+		} else {
+			it, err := e.Repo.GetIterationTx(ctx, tx, *opts.SetIteration)
+			if err != nil {
+				return t, err
+			}
+			if it.ProjectID != t.ProjectID {
+				return t, fmt.Errorf("iteration %s not in project %s", it.ID, t.ProjectID)
+			}
+			t.IterationID = &it.ID
+		}
+	}
+	if opts.SetComponent != nil {
+		if !e.Config.HasComponent(*opts.SetComponent) {
+			return t, fmt.Errorf("unknown component %s", *opts.SetComponent)
+		}
+		t.Component = *opts.SetComponent
+	}
+	if len(opts.SetExternalRefs) > 0 {
+		if err := validateExternalRefs(opts.SetExternalRefs, true); err != nil {
+			return t, err
+		}
+		if err := e.setExternalRefsTx(ctx, tx, t.ProjectID, "task", t.ID, opts.SetExternalRefs); err != nil {
+			return t, err
+		}
+		t.ExternalRefs = mergeExternalRefs(t.ExternalRefs, opts.SetExternalRefs)
+	}
+	if (opts.EstimateProvided || opts.IterationProvided) && t.IterationID != nil {
+		warning, err := e.checkIterationCapacity(ctx, tx, *t.IterationID, t.ID, t.Estimate, opts.ActorID)
+		if err != nil {
+			return t, err
+		}
+		if warning != "" {
+			t.Warnings = append(t.Warnings, warning)
+		}
+	}
 	if opts.WorkOutcomesSet {
 		if opts.ClearWorkOutcomes {
 			if !opts.Force {
@@ -414,6 +652,9 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 			if err := validateJSON(*opts.SetWorkOutcomes); err != nil {
 				return t, fmt.Errorf("work outcomes JSON: %w", err)
 			}
+			if err := e.validateWorkOutcomes(t.Type, *opts.SetWorkOutcomes); err != nil {
+				return t, err
+			}
 			t.WorkOutcomesJSON = opts.SetWorkOutcomes
 			if !opts.Force {
 				if err := e.requireLeaseOrForce(ctx, tx, t.ID, opts.ActorID, opts.Force); err != nil {
@@ -423,7 +664,7 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 		}
 	}
 	if opts.PolicyPreset != "" {
-		policy, ok := e.Config.TaskPolicy(t.Type, opts.PolicyPreset)
+		policy, ok := e.Config.ComponentTaskPolicy(t.Component, t.Type, opts.PolicyPreset)
 		if !ok {
 			return t, fmt.Errorf("policy %s not found for task type %s", opts.PolicyPreset, t.Type)
 		}
@@ -441,27 +682,31 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 		t.RequiredAttestationsJSON = reqJSON
 	}
 	if opts.Status != "" && opts.Status != t.Status {
-		if opts.Status == "done" {
+		completing := opts.Status == workflow.Done
+		if completing {
 			if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.done"); err != nil {
 				return t, err
 			}
+			if err := e.requireDoneRole(ctx, tx, t, opts.ActorID); err != nil {
+				return t, err
+			}
 		}
 		if !opts.Force {
 			if err := e.requireLeaseOrForce(ctx, tx, t.ID, opts.ActorID, opts.Force); err != nil {
 				return t, err
 			}
 		}
-		if err := ensureTaskTransition(t.Status, opts.Status, opts.Force); err != nil {
+		if err := ensureTaskTransition(workflow, t.Type, t.Status, opts.Status, opts.Force); err != nil {
 			return t, err
 		}
-		if opts.Status == "done" && !opts.Force {
+		if completing && !opts.Force {
 			if err := e.ensureDependenciesDone(ctx, tx, t.ID, t.ProjectID, opts.Force); err != nil {
 				return t, err
 			}
 			if err := e.ensureSubtasksDone(ctx, tx, t.ID, opts.Force); err != nil {
 				return t, err
 			}
-			if err := e.ensureNoRejectedValidation(ctx, t.ProjectID, t.ID); err != nil {
+			if err := e.ensureNoRejectedValidation(ctx, tx, t.ProjectID, t.ID); err != nil {
 				return t, err
 			}
 			ok, err := e.isTaskValidationSatisfied(ctx, tx, t, opts.ActorID)
@@ -472,10 +717,28 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 				return t, errors.New("validation policy not satisfied")
 			}
 		}
+		if completing {
+			outcomes := ""
+			if t.WorkOutcomesJSON != nil {
+				outcomes = *t.WorkOutcomesJSON
+			}
+			if err := e.validateWorkOutcomes(t.Type, outcomes); err != nil {
+				return t, err
+			}
+		}
+		warning, err := e.checkWIPLimit(ctx, tx, t, opts.Status, opts.ActorID, opts.Force)
+		if err != nil {
+			return t, err
+		}
+		if warning != "" {
+			t.Warnings = append(t.Warnings, warning)
+		}
 		t.Status = opts.Status
-		if opts.Status == "done" {
+		if completing {
 			now := e.now().UTC().Format(time.RFC3339)
 			t.CompletedAt = &now
+		} else {
+			t.CompletedAt = nil
 		}
 	}
 	t.UpdatedAt = e.now().UTC().Format(time.RFC3339)
@@ -511,48 +774,70 @@ func (e Engine) UpdateTask(ctx context.Context, opts TaskUpdateOptions) (domain.
 			return t, err
 		}
 	}
-	if err := e.Events.Append(ctx, tx, "task.updated", t.ProjectID, "task", t.ID, opts.ActorID, events.EventPayload{
+	updatedPayload := events.EventPayload{
 		"from_status": original.Status,
 		"to_status":   t.Status,
-	}); err != nil {
-		return t, err
 	}
-	if err := tx.Commit(); err != nil {
+	if opts.Force {
+		updatedPayload["forced"] = true
+	}
+	if changed := changedTaskFields(original, t, opts); len(changed) > 0 {
+		updatedPayload["changed"] = changed
+		if slices.Contains(changed, "assignee_id") {
+			updatedPayload["assignee_id"] = t.AssigneeID
+		}
+		if slices.Contains(changed, "due_at") {
+			updatedPayload["due_at"] = t.DueAt
+		}
+	}
+	if err := e.Events.Append(ctx, tx, "task.updated", t.ProjectID, "task", t.ID, opts.ActorID, updatedPayload); err != nil {
 		return t, err
 	}
-	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
+	if original.Status != t.Status {
+		if err := e.runTransitionHooks(ctx, tx, Transition{EntityKind: "task", EntityID: t.ID, ProjectID: t.ProjectID, From: original.Status, To: t.Status, ActorID: opts.ActorID}); err != nil {
+			return t, err
+		}
+		warnings := t.Warnings
+		if t, err = e.Repo.GetTaskTx(ctx, tx, t.ID); err != nil {
+			return t, err
+		}
+		t.Warnings = warnings
+	}
 	return t, nil
 }
 
-func ensureTaskTransition(oldStatus, newStatus string, force bool) error {
-	if force {
-		return nil
+// ensureTaskTransition checks a status change against the task type's
+// workflow. Force skips the transition rules but never allows unknown states.
+func ensureTaskTransition(workflow config.WorkflowConfig, taskType, oldStatus, newStatus string, force bool) error {
+	if !workflow.HasState(newStatus) {
+		return fmt.Errorf("invalid task status %s for task type %s", newStatus, taskType)
 	}
-	switch oldStatus {
-	case "planned":
-		if newStatus == "ready" || newStatus == "in_progress" || newStatus == "canceled" || newStatus == "review" || newStatus == "done" {
-			return nil
-		}
-	case "ready":
-		if newStatus == "in_progress" || newStatus == "canceled" || newStatus == "review" || newStatus == "done" {
-			return nil
-		}
-	case "in_progress":
-		if newStatus == "rejected" || newStatus == "canceled" || newStatus == "review" || newStatus == "done" {
-			return nil
-		}
-	case "review":
-		if newStatus == "done" || newStatus == "rejected" {
-			return nil
-		}
-	case "rejected":
-		if newStatus == "planned" {
-			return nil
-		}
+	if force || workflow.Allows(oldStatus, newStatus) {
+		return nil
 	}
 	return fmt.Errorf("invalid task status transition %s -> %s", oldStatus, newStatus)
 }
 
+// workflow returns the status state machine for a task type.
+func (e Engine) workflow(taskType string) config.WorkflowConfig {
+	if e.Config == nil {
+		return config.DefaultWorkflow()
+	}
+	return e.Config.TaskWorkflow(taskType)
+}
+
+// doneStates maps each configured task type to its workflow's done state.
+func (e Engine) doneStates() map[string]string {
+	states := map[string]string{}
+	if e.Config == nil {
+		return states
+	}
+	for taskType := range e.Config.Project.TaskTypes {
+		states[taskType] = e.Config.TaskWorkflow(taskType).Done
+	}
+	return states
+}
+
 func validateJSON(in string) error {
 	var tmp any
 	if err := json.Unmarshal([]byte(in), &tmp); err != nil {
@@ -595,8 +880,15 @@ func (e Engine) TaskDone(ctx context.Context, taskID, workOutcomesJSON, actorID
 	if err != nil {
 		return t, err
 	}
+	if err := e.validateWorkOutcomes(t.Type, workOutcomesJSON); err != nil {
+		return t, err
+	}
+	if force && e.forceNeedsApproval(ctx) {
+		return t, e.requestForce(ctx, t.ProjectID, "task.done", "task", t.ID, taskDoneParams{WorkOutcomesJSON: workOutcomesJSON}, actorID)
+	}
+	workflow := e.workflow(t.Type)
 	if t.Status == "" {
-		t.Status = "planned"
+		t.Status = workflow.Initial
 	}
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -606,6 +898,9 @@ func (e Engine) TaskDone(ctx context.Context, taskID, workOutcomesJSON, actorID
 	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.done"); err != nil {
 		return t, err
 	}
+	if err := e.requireDoneRole(ctx, tx, t, actorID); err != nil {
+		return t, err
+	}
 	if force {
 		if err := e.requireForcePermission(ctx, tx, t.ProjectID, actorID); err != nil {
 			return t, err
@@ -613,7 +908,7 @@ func (e Engine) TaskDone(ctx context.Context, taskID, workOutcomesJSON, actorID
 	}
 
 	t.WorkOutcomesJSON = &workOutcomesJSON
-	targetStatus := "done"
+	targetStatus := workflow.Done
 	if !force {
 		// gating checks
 		if err := e.requireLeaseOrForce(ctx, tx, t.ID, actorID, force); err != nil {
@@ -625,7 +920,7 @@ func (e Engine) TaskDone(ctx context.Context, taskID, workOutcomesJSON, actorID
 		if err := e.ensureSubtasksDone(ctx, tx, t.ID, force); err != nil {
 			return t, err
 		}
-		if err := e.ensureNoRejectedValidation(ctx, t.ProjectID, t.ID); err != nil {
+		if err := e.ensureNoRejectedValidation(ctx, tx, t.ProjectID, t.ID); err != nil {
 			return t, err
 		}
 		satisfied, err := e.isTaskValidationSatisfied(ctx, tx, t, actorID)
@@ -636,24 +931,41 @@ func (e Engine) TaskDone(ctx context.Context, taskID, workOutcomesJSON, actorID
 			return t, errors.New("validation policy not satisfied")
 		}
 	}
-	if err := ensureTaskTransition(t.Status, targetStatus, force); err != nil {
+	if err := ensureTaskTransition(workflow, t.Type, t.Status, targetStatus, force); err != nil {
+		return t, err
+	}
+	warning, err := e.checkWIPLimit(ctx, tx, t, targetStatus, actorID, force)
+	if err != nil {
 		return t, err
 	}
+	fromStatus := t.Status
 	t.Status = targetStatus
 	nowStr := e.now().UTC().Format(time.RFC3339)
 	t.UpdatedAt = nowStr
-	if t.Status == "done" {
-		t.CompletedAt = &nowStr
-	}
+	t.CompletedAt = &nowStr
 	if err := e.Repo.UpdateTask(ctx, tx, t); err != nil {
 		return t, err
 	}
-	if err := e.Events.Append(ctx, tx, "task.done", t.ProjectID, "task", t.ID, actorID, events.EventPayload{"status": t.Status}); err != nil {
+	donePayload := events.EventPayload{"status": t.Status, "from_status": fromStatus}
+	if force {
+		donePayload["forced"] = true
+	}
+	if err := e.Events.Append(ctx, tx, "task.done", t.ProjectID, "task", t.ID, actorID, donePayload); err != nil {
+		return t, err
+	}
+	if err := e.runTransitionHooks(ctx, tx, Transition{EntityKind: "task", EntityID: t.ID, ProjectID: t.ProjectID, From: fromStatus, To: t.Status, ActorID: actorID}); err != nil {
 		return t, err
 	}
+	if t, err = e.Repo.GetTaskTx(ctx, tx, t.ID); err != nil {
+		return t, err
+	}
+	if warning != "" {
+		t.Warnings = append(t.Warnings, warning)
+	}
 	if err := tx.Commit(); err != nil {
 		return t, err
 	}
+	e.sendHookDeliveries(ctx)
 	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
 	return t, nil
 }
@@ -674,10 +986,23 @@ func (e Engine) ensureDependenciesDone(ctx context.Context, tx *sql.Tx, taskID,
 		if t.ProjectID != projectID {
 			return fmt.Errorf("dependency %s not in project", d)
 		}
-		if t.Status != "done" {
+		if t.Status != e.workflow(t.Type).Done {
 			return fmt.Errorf("dependency %s not done", d)
 		}
 	}
+	blockers, err := e.Repo.BlockersTx(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	for _, b := range blockers {
+		t, err := e.Repo.GetTaskTx(ctx, tx, b)
+		if err != nil {
+			return err
+		}
+		if t.Status != e.workflow(t.Type).Done {
+			return fmt.Errorf("blocked by %s, not done", b)
+		}
+	}
 	return nil
 }
 
@@ -694,7 +1019,7 @@ func (e Engine) ensureSubtasksDone(ctx context.Context, tx *sql.Tx, taskID strin
 		if err != nil {
 			return err
 		}
-		if t.Status != "done" {
+		if t.Status != e.workflow(t.Type).Done {
 			return fmt.Errorf("subtask %s not done", c)
 		}
 		if err := e.ensureSubtasksDone(ctx, tx, t.ID, force); err != nil {
@@ -704,8 +1029,8 @@ func (e Engine) ensureSubtasksDone(ctx context.Context, tx *sql.Tx, taskID strin
 	return nil
 }
 
-func (e Engine) ensureNoRejectedValidation(ctx context.Context, projectID, taskID string) error {
-	rejected, err := e.Repo.HasRejectedValidation(ctx, projectID, taskID)
+func (e Engine) ensureNoRejectedValidation(ctx context.Context, tx *sql.Tx, projectID, taskID string) error {
+	rejected, err := e.Repo.HasRejectedValidationTx(ctx, tx, projectID, taskID)
 	if err != nil {
 		return err
 	}
@@ -716,6 +1041,12 @@ func (e Engine) ensureNoRejectedValidation(ctx context.Context, projectID, taskI
 }
 
 func (e Engine) isTaskValidationSatisfied(ctx context.Context, tx *sql.Tx, t domain.Task, actorID string) (bool, error) {
+	return e.taskAttestationsSatisfied(ctx, tx, t, e.now())
+}
+
+// taskAttestationsSatisfied checks the task's required attestations, counting
+// only those still valid at asOf.
+func (e Engine) taskAttestationsSatisfied(ctx context.Context, tx *sql.Tx, t domain.Task, asOf time.Time) (bool, error) {
 	if t.RequiredAttestationsJSON == nil {
 		return true, nil
 	}
@@ -726,20 +1057,23 @@ func (e Engine) isTaskValidationSatisfied(ctx context.Context, tx *sql.Tx, t dom
 	if len(required) == 0 {
 		return true, nil
 	}
-	rows, err := tx.QueryContext(ctx, `SELECT kind FROM attestations WHERE entity_kind='task' AND entity_id=?`, t.ID)
+	rows, err := tx.QueryContext(ctx, `SELECT kind,ts FROM attestations WHERE entity_kind='task' AND entity_id=?`, t.ID)
 	if err != nil {
 		return false, err
 	}
 	defer rows.Close()
 	found := map[string]bool{}
 	for rows.Next() {
-		var kind string
-		if err := rows.Scan(&kind); err != nil {
+		var att domain.Attestation
+		if err := rows.Scan(&att.Kind, &att.TS); err != nil {
 			return false, err
 		}
+		if e.AttestationExpired(att, asOf) {
+			continue
+		}
 		for _, req := range required {
-			if kind == req {
-				found[kind] = true
+			if att.Kind == req {
+				found[att.Kind] = true
 			}
 		}
 	}
@@ -788,6 +1122,16 @@ func (e Engine) ClaimLease(ctx context.Context, taskID, actorID string, leaseSec
 			return domain.Lease{}, errors.New("lease already held")
 		}
 	}
+	unlock, err := e.lockLease(ctx, newLease, expires.Sub(now))
+	if err != nil {
+		return domain.Lease{}, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			unlock()
+		}
+	}()
 	if err := e.Repo.UpsertLease(ctx, tx, newLease); err != nil {
 		return domain.Lease{}, err
 	}
@@ -797,6 +1141,7 @@ func (e Engine) ClaimLease(ctx context.Context, taskID, actorID string, leaseSec
 	if err := tx.Commit(); err != nil {
 		return domain.Lease{}, err
 	}
+	committed = true
 	return newLease, nil
 }
 
@@ -816,13 +1161,21 @@ func (e Engine) ReleaseLease(ctx context.Context, taskID, actorID string) error
 	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.release"); err != nil {
 		return err
 	}
+	existing, err := e.Repo.GetLeaseTx(ctx, tx, taskID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
 	if err := e.Repo.DeleteLease(ctx, tx, taskID); err != nil {
 		return err
 	}
 	if err := e.Events.Append(ctx, tx, "lease.released", t.ProjectID, "task", taskID, actorID, events.EventPayload{}); err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.unlockLeases(ctx, existing)
+	return nil
 }
 
 func (e Engine) CreateIteration(ctx context.Context, it domain.Iteration, actorID string) (domain.Iteration, error) {
@@ -835,6 +1188,9 @@ func (e Engine) CreateIteration(ctx context.Context, it domain.Iteration, actorI
 	if it.Status == "" {
 		it.Status = "pending"
 	}
+	if err := validateEstimate(it.Capacity, "capacity"); err != nil {
+		return it, err
+	}
 	it.CreatedAt = e.now().UTC().Format(time.RFC3339)
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -844,6 +1200,11 @@ func (e Engine) CreateIteration(ctx context.Context, it domain.Iteration, actorI
 	if err := e.requirePermission(ctx, tx, it.ProjectID, actorID, "iteration.create"); err != nil {
 		return it, err
 	}
+	if it.ID == "" {
+		if it.ID, err = e.newEntityID(ctx, tx, e.Config, it.ProjectID, "iteration", "iteration|"+it.Goal, it.CreatedAt); err != nil {
+			return it, err
+		}
+	}
 	if err := e.Repo.InsertIterationTx(ctx, tx, it); err != nil {
 		return it, err
 	}
@@ -885,6 +1246,9 @@ func (e Engine) SetIterationStatus(ctx context.Context, id, status, actorID stri
 	if err != nil {
 		return it, err
 	}
+	if force && e.forceNeedsApproval(ctx) {
+		return it, e.requestForce(ctx, it.ProjectID, "iteration.set_status", "iteration", it.ID, iterationStatusParams{Status: status}, actorID)
+	}
 	if err := ensureIterationTransition(it.Status, status, force); err != nil {
 		return it, err
 	}
@@ -909,16 +1273,18 @@ func (e Engine) SetIterationStatus(ctx context.Context, id, status, actorID stri
 	if e.Config != nil {
 		requiredKinds = e.Config.IterationValidationPolicy()
 	}
-	if status == "validated" && !force {
-		if len(requiredKinds) > 0 {
-			ok, err := e.iterationValidated(ctx, id, requiredKinds)
-			if err != nil {
-				return it, err
-			}
-			if !ok {
-				return it, errors.New("iteration validation policy not satisfied")
-			}
+	// The check runs before the transaction: with a single connection,
+	// iterationValidated cannot query while the transaction holds it.
+	validationResult := true
+	if status == "validated" && len(requiredKinds) > 0 {
+		ok, err := e.iterationValidated(ctx, id, requiredKinds)
+		if err != nil {
+			return it, err
+		}
+		if !ok && !force {
+			return it, errors.New("iteration validation policy not satisfied")
 		}
+		validationResult = ok || force
 	}
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -937,17 +1303,9 @@ func (e Engine) SetIterationStatus(ctx context.Context, id, status, actorID stri
 		return it, err
 	}
 	if status == "validated" {
-		result := true
-		if !force && len(requiredKinds) > 0 {
-			ok, err := e.iterationValidated(ctx, id, requiredKinds)
-			if err != nil {
-				return it, err
-			}
-			result = ok
-		}
 		if err := e.Events.Append(ctx, tx, "iteration.validation.checked", it.ProjectID, "iteration", id, actorID, events.EventPayload{
 			"required_kinds": requiredKinds,
-			"result":         result,
+			"result":         validationResult,
 		}); err != nil {
 			return it, err
 		}
@@ -955,9 +1313,13 @@ func (e Engine) SetIterationStatus(ctx context.Context, id, status, actorID stri
 	if err := e.Events.Append(ctx, tx, "iteration.updated", it.ProjectID, "iteration", id, actorID, events.EventPayload{"from": it.Status, "to": status}); err != nil {
 		return it, err
 	}
+	if err := e.runTransitionHooks(ctx, tx, Transition{EntityKind: "iteration", EntityID: id, ProjectID: it.ProjectID, From: it.Status, To: status, ActorID: actorID}); err != nil {
+		return it, err
+	}
 	if err := tx.Commit(); err != nil {
 		return it, err
 	}
+	e.sendHookDeliveries(ctx)
 	it.Status = status
 	return it, nil
 }
@@ -966,14 +1328,26 @@ func (e Engine) iterationValidated(ctx context.Context, iterationID string, kind
 	if len(kinds) == 0 {
 		return true, nil
 	}
+	now := e.now()
 	for _, kind := range kinds {
-		rows, err := e.DB.QueryContext(ctx, `SELECT 1 FROM attestations WHERE entity_kind='iteration' AND entity_id=? AND kind=? LIMIT 1`, iterationID, kind)
+		rows, err := e.DB.QueryContext(ctx, `SELECT ts FROM attestations WHERE entity_kind='iteration' AND entity_id=? AND kind=?`, iterationID, kind)
 		if err != nil {
 			return false, err
 		}
-		hasRow := rows.Next()
+		valid := false
+		for rows.Next() {
+			att := domain.Attestation{Kind: kind}
+			if err := rows.Scan(&att.TS); err != nil {
+				rows.Close()
+				return false, err
+			}
+			if !e.AttestationExpired(att, now) {
+				valid = true
+				break
+			}
+		}
 		rows.Close()
-		if !hasRow {
+		if !valid {
 			return false, nil
 		}
 	}
@@ -996,6 +1370,14 @@ func (e Engine) CreateDecision(ctx context.Context, d domain.Decision, actorID s
 	if err := e.requirePermission(ctx, tx, d.ProjectID, actorID, "decision.create"); err != nil {
 		return d, err
 	}
+	if d.ID == "" {
+		if d.ID, err = e.newEntityID(ctx, tx, e.Config, d.ProjectID, "decision", "decision|"+d.Title, d.CreatedAt); err != nil {
+			return d, err
+		}
+	}
+	if d.Number, err = e.Repo.NextDecisionNumberTx(ctx, tx, d.ProjectID); err != nil {
+		return d, err
+	}
 	if err := e.Repo.InsertDecisionTx(ctx, tx, d); err != nil {
 		return d, err
 	}
@@ -1016,7 +1398,9 @@ func (e Engine) AddAttestation(ctx context.Context, att domain.Attestation, acto
 	if att.EntityKind == "" || att.EntityID == "" || att.Kind == "" {
 		return att, errors.New("entity-kind, entity-id and kind required")
 	}
-	att.ID = uuid.New().String()
+	if att.ID == "" {
+		att.ID = uuid.New().String()
+	}
 	if att.TS == "" {
 		att.TS = e.now().UTC().Format(time.RFC3339)
 	}
@@ -1037,6 +1421,9 @@ func (e Engine) AddAttestation(ctx context.Context, att domain.Attestation, acto
 	if err := e.requireAttestationAuthority(ctx, tx, att.ProjectID, actorID, att.Kind); err != nil {
 		return att, err
 	}
+	if err := e.validateAttestationPayload(att); err != nil {
+		return att, err
+	}
 	if err := e.Repo.InsertAttestationTx(ctx, tx, att); err != nil {
 		return att, err
 	}
@@ -1053,6 +1440,84 @@ func (e Engine) AddAttestation(ctx context.Context, att domain.Attestation, acto
 	return att, nil
 }
 
+// AttestationPayloadError lists the schema violations of an attestation payload.
+type AttestationPayloadError struct {
+	Kind       string
+	Violations []jsonschema.Violation
+}
+
+func (e AttestationPayloadError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("invalid payload for attestation kind %s: %s", e.Kind, strings.Join(parts, "; "))
+}
+
+func (e Engine) validateAttestationPayload(att domain.Attestation) error {
+	if att.Kind == ProvenanceKind {
+		if _, err := ParseProvenance([]byte(att.PayloadJSON)); err != nil {
+			return err
+		}
+	}
+	schema := e.Config.AttestationSchema(att.Kind)
+	if schema == nil {
+		return nil
+	}
+	var doc any
+	if att.PayloadJSON != "" {
+		if err := json.Unmarshal([]byte(att.PayloadJSON), &doc); err != nil {
+			return AttestationPayloadError{Kind: att.Kind, Violations: []jsonschema.Violation{{Path: "/", Message: "payload is not valid JSON"}}}
+		}
+	}
+	if violations := jsonschema.Validate(schema, doc); len(violations) > 0 {
+		return AttestationPayloadError{Kind: att.Kind, Violations: violations}
+	}
+	return nil
+}
+
+// WorkOutcomesError reports work outcomes over the configured size limit or
+// not matching their task type's schema.
+type WorkOutcomesError struct {
+	TaskType   string
+	Size       int
+	MaxBytes   int
+	Violations []jsonschema.Violation
+}
+
+func (e WorkOutcomesError) Error() string {
+	if e.MaxBytes > 0 && e.Size > e.MaxBytes {
+		return fmt.Sprintf("work outcomes too large: %d bytes (max %d)", e.Size, e.MaxBytes)
+	}
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("work outcomes do not match the schema for task type %s: %s", e.TaskType, strings.Join(parts, "; "))
+}
+
+// validateWorkOutcomes checks serialized work outcomes against the size limit
+// and the task type's schema. Empty outcomes are checked as an empty object.
+func (e Engine) validateWorkOutcomes(taskType, raw string) error {
+	if limit := e.Config.Project.WorkOutcomes.MaxBytes; limit > 0 && len(raw) > limit {
+		return WorkOutcomesError{TaskType: taskType, Size: len(raw), MaxBytes: limit}
+	}
+	schema := e.Config.WorkOutcomesSchema(taskType)
+	if schema == nil {
+		return nil
+	}
+	var doc any = map[string]any{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+			return fmt.Errorf("work outcomes JSON: %w", err)
+		}
+	}
+	if violations := jsonschema.Validate(schema, doc); len(violations) > 0 {
+		return WorkOutcomesError{TaskType: taskType, Size: len(raw), MaxBytes: e.Config.Project.WorkOutcomes.MaxBytes, Violations: violations}
+	}
+	return nil
+}
+
 func (e Engine) ensureTaskPolicySatisfied(ctx context.Context, t domain.Task) (bool, error) {
 	tx, err := e.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
@@ -1065,9 +1530,11 @@ func (e Engine) ensureTaskPolicySatisfied(ctx context.Context, t domain.Task) (b
 // RBAC operations
 
 type WhoAmI struct {
-	ActorID     string   `json:"actor_id"`
-	Roles       []string `json:"roles"`
-	Permissions []string `json:"permissions"`
+	ActorID string   `json:"actor_id"`
+	Roles   []string `json:"roles"`
+	// RoleExpiresAt tells when each time-boxed role lapses.
+	RoleExpiresAt map[string]string `json:"role_expires_at,omitempty"`
+	Permissions   []string          `json:"permissions"`
 }
 
 func (e Engine) WhoAmI(ctx context.Context, projectID, actorID string) (WhoAmI, error) {
@@ -1087,13 +1554,34 @@ func (e Engine) WhoAmI(ctx context.Context, projectID, actorID string) (WhoAmI,
 	if err != nil {
 		return WhoAmI{}, err
 	}
+	expiries, err := e.Auth.ActorRoleExpiries(ctx, tx, projectID, actorID)
+	if err != nil {
+		return WhoAmI{}, err
+	}
+	if len(expiries) == 0 {
+		expiries = nil
+	}
 	if err := tx.Commit(); err != nil {
 		return WhoAmI{}, err
 	}
-	return WhoAmI{ActorID: actorID, Roles: roles, Permissions: perms}, nil
+	return WhoAmI{ActorID: actorID, Roles: roles, RoleExpiresAt: expiries, Permissions: perms}, nil
 }
 
 func (e Engine) GrantRole(ctx context.Context, projectID, actorID, targetActor, roleID string) error {
+	return e.GrantRoleUntil(ctx, projectID, actorID, targetActor, roleID, "")
+}
+
+// GrantRoleUntil grants a project role that lapses at expiresAt (RFC3339, or
+// YYYY-MM-DD for the end of that day in UTC); empty grants it for good.
+// Granting a role the actor already holds replaces its expiry.
+func (e Engine) GrantRoleUntil(ctx context.Context, projectID, actorID, targetActor, roleID, expiresAt string) error {
+	until, err := parseDeadline("expires_at", expiresAt)
+	if err != nil {
+		return err
+	}
+	if until != nil && *until <= e.now().UTC().Format(time.RFC3339) {
+		return fmt.Errorf("invalid expires_at %s: already past", *until)
+	}
 	tx, err := e.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -1102,16 +1590,27 @@ func (e Engine) GrantRole(ctx context.Context, projectID, actorID, targetActor,
 	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
 		return err
 	}
+	if _, err := e.projectRole(ctx, tx, projectID, roleID); err != nil {
+		return err
+	}
 	if err := e.ensureActor(ctx, tx, targetActor); err != nil {
 		return err
 	}
-	if err := e.Repo.AssignRole(ctx, tx, projectID, targetActor, roleID); err != nil {
+	if err := e.Repo.AssignRoleUntil(ctx, tx, projectID, targetActor, roleID, until); err != nil {
 		return err
 	}
-	if err := e.Events.Append(ctx, tx, "rbac.role_granted", projectID, "rbac", projectID, actorID, events.EventPayload{"actor_id": targetActor, "role_id": roleID}); err != nil {
+	payload := events.EventPayload{"actor_id": targetActor, "role_id": roleID}
+	if until != nil {
+		payload["expires_at"] = *until
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.role_granted", projectID, "rbac", projectID, actorID, payload); err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
 }
 
 func (e Engine) RevokeRole(ctx context.Context, projectID, actorID, targetActor, roleID string) error {
@@ -1129,7 +1628,11 @@ func (e Engine) RevokeRole(ctx context.Context, projectID, actorID, targetActor,
 	if err := e.Events.Append(ctx, tx, "rbac.role_revoked", projectID, "rbac", projectID, actorID, events.EventPayload{"actor_id": targetActor, "role_id": roleID}); err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
 }
 
 func (e Engine) AllowAttestationRole(ctx context.Context, projectID, actorID, kind, roleID string) error {
@@ -1141,6 +1644,9 @@ func (e Engine) AllowAttestationRole(ctx context.Context, projectID, actorID, ki
 	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
 		return err
 	}
+	if _, err := e.projectRole(ctx, tx, projectID, roleID); err != nil {
+		return err
+	}
 	if err := e.Repo.AllowAttestationRole(ctx, tx, projectID, kind, roleID); err != nil {
 		return err
 	}
@@ -1507,46 +2013,9 @@ func (e Engine) seedRBAC(ctx context.Context, tx *sql.Tx, projectID, actorID str
 	if err := e.Auth.EnsureActor(ctx, tx, actorID); err != nil {
 		return err
 	}
-	permDescs := map[string]string{
-		"project.create":       "Create project",
-		"project.list":         "List projects",
-		"project.read":         "Read project",
-		"project.update":       "Update project",
-		"project.delete":       "Delete project",
-		"project.config.read":  "Read project config",
-		"project.status.read":  "Read project status",
-		"project.events.read":  "Read project events",
-		"actor.mission.read":   "Read actor mission",
-		"actor.mission.list":   "List actor missions",
-		"actor.mission.write":  "Update actor mission",
-		"actor.mission.delete": "Delete actor mission",
-		"validation.create":    "Create validation",
-		"validation.read":      "Read validation",
-		"validation.list":      "List validations",
-		"validation.update":    "Update validation",
-		"task.create":          "Create task",
-		"task.list":            "List tasks",
-		"task.read":            "Read task",
-		"task.next":            "Read next task",
-		"task.tree":            "Read task tree",
-		"task.validation.read": "Read task validation",
-		"task.update":          "Update task",
-		"task.done":            "Complete task",
-		"task.claim":           "Claim task",
-		"task.release":         "Release task",
-		"iteration.create":     "Create iteration",
-		"iteration.list":       "List iterations",
-		"iteration.set_status": "Update iteration status",
-		"decision.create":      "Create decision",
-		"attestation.add":      "Add attestation",
-		"attestation.list":     "List attestations",
-		"rbac.manage":          "Manage RBAC",
-		"force.use":            "Use force flag",
-	}
-	for perm, desc := range permDescs {
-		if err := e.Repo.InsertPermission(ctx, tx, perm, desc); err != nil {
-			return err
-		}
+	permDescs := config.Permissions()
+	if err := e.Repo.InsertPermissions(ctx, tx, permDescs); err != nil {
+		return err
 	}
 	roleDescs := map[string]string{
 		"owner":    "Project owner",
@@ -1575,13 +2044,15 @@ func (e Engine) seedRBAC(ctx context.Context, tx *sql.Tx, projectID, actorID str
 		"task.tree",
 		"task.validation.read",
 		"iteration.list",
+		"milestone.list",
 		"attestation.list",
+		"decision.list",
 	}
 	rolePerms := map[string][]string{
 		"owner":    keys(permDescs),
-		"pm":       append(append([]string{}, readPerms...), "task.create", "task.update", "iteration.create", "iteration.set_status", "decision.create", "attestation.add"),
+		"pm":       append(append([]string{}, readPerms...), "task.create", "task.update", "iteration.create", "iteration.set_status", "milestone.create", "decision.create", "attestation.add"),
 		"po":       append(append([]string{}, readPerms...), "task.create", "task.update", "attestation.add"),
-		"dev":      append(append([]string{}, readPerms...), "task.claim", "task.update", "task.done", "task.release"),
+		"dev":      append(append([]string{}, readPerms...), "task.claim", "task.update", "task.done", "task.release", "task.time.log"),
 		"reviewer": append(append([]string{}, readPerms...), "attestation.add"),
 		"qa":       append(append([]string{}, readPerms...), "attestation.add"),
 		"security": append(append([]string{}, readPerms...), "attestation.add"),
@@ -1600,19 +2071,26 @@ func (e Engine) seedRBAC(ctx context.Context, tx *sql.Tx, projectID, actorID str
 			rolePerms[roleID] = uniqueStrings(perms)
 		}
 	}
-	for role, desc := range roleDescs {
-		if err := e.Repo.InsertRole(ctx, tx, role, desc); err != nil {
+	for roleID := range roleDescs {
+		role, err := e.Repo.GetRoleTx(ctx, tx, roleID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
 			return err
 		}
+		if err == nil && role.ProjectID != "" {
+			return fmt.Errorf("invalid role %s: already a custom role of project %s", roleID, role.ProjectID)
+		}
+	}
+	if err := e.Repo.InsertRoles(ctx, tx, roleDescs); err != nil {
+		return err
 	}
 	for role, perms := range rolePerms {
 		for _, p := range perms {
 			if _, ok := permDescs[p]; !ok {
 				return fmt.Errorf("unknown permission %s for role %s", p, role)
 			}
-			if err := e.Repo.AddRolePermission(ctx, tx, role, p); err != nil {
-				return err
-			}
+		}
+		if err := e.Repo.AddRolePermissions(ctx, tx, role, perms); err != nil {
+			return err
 		}
 	}
 	if err := e.Repo.EnsureActor(ctx, tx, actorID, now); err != nil {
@@ -1,15 +1,42 @@
 package engine_test
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+
+	"workline/internal/blob"
 	"workline/internal/config"
 	"workline/internal/db"
 	"workline/internal/domain"
 	"workline/internal/engine"
+	"workline/internal/engine/auth"
+	"workline/internal/fieldcrypt"
+	"workline/internal/mail"
 	"workline/internal/migrate"
+	"workline/internal/outbox"
+	"workline/internal/repo"
 )
 
 type testEnv struct {
@@ -17,7 +44,7 @@ type testEnv struct {
 	Ctx    context.Context
 }
 
-func newTestEnv(t *testing.T) testEnv {
+func newTestEnv(t testing.TB) testEnv {
 	t.Helper()
 	dir := t.TempDir()
 	conn, err := db.Open(db.Config{Workspace: dir})
@@ -31,7 +58,7 @@ func newTestEnv(t *testing.T) testEnv {
 	eng := engine.New(conn, cfg)
 	eng.Now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
 	ctx := context.Background()
-	if _, err := eng.InitProject(ctx, "proj-1", "org-1", "test", "tester"); err != nil {
+	if _, err := eng.InitProject(ctx, "proj-1", "org-1", "", "test", "tester"); err != nil {
 		t.Fatalf("init project: %v", err)
 	}
 	if err := eng.Repo.UpsertProjectConfig(ctx, "proj-1", cfg); err != nil {
@@ -233,7 +260,7 @@ func TestSeedRBACFromConfig(t *testing.T) {
 	eng := engine.New(conn, cfg)
 	eng.Now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
 	ctx := context.Background()
-	if _, err := eng.InitProject(ctx, "proj-1", "org-1", "test", "tester"); err != nil {
+	if _, err := eng.InitProject(ctx, "proj-1", "org-1", "", "test", "tester"); err != nil {
 		t.Fatalf("init project: %v", err)
 	}
 	tx, err := conn.BeginTx(ctx, nil)
@@ -317,3 +344,4088 @@ func TestEventAppendOnStateChanges(t *testing.T) {
 		t.Fatalf("expected multiple events, got %d", count)
 	}
 }
+
+func TestOrgMembership(t *testing.T) {
+	env := newTestEnv(t)
+	org, err := env.Engine.CreateOrg(env.Ctx, "acme", "Acme", "alice")
+	if err != nil {
+		t.Fatalf("create org: %v", err)
+	}
+	if _, err := env.Engine.CreateOrg(env.Ctx, org.ID, "", "alice"); err == nil {
+		t.Fatalf("expected duplicate org error")
+	}
+	if _, err := env.Engine.SetOrgMember(env.Ctx, "acme", "alice", "bob", "member"); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	if _, err := env.Engine.SetOrgMember(env.Ctx, "acme", "bob", "carol", "member"); err == nil {
+		t.Fatalf("expected member to be denied org management")
+	}
+	if _, err := env.Engine.SetOrgMember(env.Ctx, "acme", "alice", "bob", "root"); err == nil {
+		t.Fatalf("expected invalid org role error")
+	}
+	if err := env.Engine.RemoveOrgMember(env.Ctx, "acme", "alice", "alice"); err == nil {
+		t.Fatalf("expected last owner removal to fail")
+	}
+	if err := env.Engine.RemoveOrgMember(env.Ctx, "acme", "alice", "bob"); err != nil {
+		t.Fatalf("remove member: %v", err)
+	}
+	members, err := env.Engine.Repo.ListOrgMembers(env.Ctx, "acme")
+	if err != nil {
+		t.Fatalf("list members: %v", err)
+	}
+	if len(members) != 1 || members[0].ActorID != "alice" || members[0].Role != "owner" {
+		t.Fatalf("unexpected members: %v", members)
+	}
+}
+
+func TestOrgRoleCascadesToProjects(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.Engine.InitProject(env.Ctx, "proj-2", "org-1", "", "second", "tester"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	if err := env.Engine.GrantOrgRole(env.Ctx, "org-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant org role: %v", err)
+	}
+	tx, err := env.Engine.DB.BeginTx(env.Ctx, nil)
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+	for _, projectID := range []string{"proj-1", "proj-2"} {
+		ok, err := env.Engine.Auth.ActorHasPermission(env.Ctx, tx, projectID, "dana", "task.claim")
+		if err != nil {
+			t.Fatalf("permission check: %v", err)
+		}
+		if !ok {
+			t.Fatalf("expected org-level dev role to apply in %s", projectID)
+		}
+	}
+	roles, err := env.Engine.Auth.ActorRoles(env.Ctx, tx, "proj-2", "tester")
+	if err != nil {
+		t.Fatalf("roles: %v", err)
+	}
+	if len(roles) != 1 || roles[0] != "owner" {
+		t.Fatalf("expected org owner to hold project owner once, got %v", roles)
+	}
+	tx.Rollback()
+	if err := env.Engine.GrantOrgRole(env.Ctx, "org-1", "dana", "erin", "dev"); err == nil {
+		t.Fatalf("expected non-admin org role grant to fail")
+	}
+}
+
+func TestCustomRoleLifecycle(t *testing.T) {
+	env := newTestEnv(t)
+	role, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{
+		ProjectID: "proj-1",
+		RoleID:    "triager",
+		Grants:    []string{"task.claim"},
+		ActorID:   "tester",
+	})
+	if err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if len(role.Permissions) != 1 || role.Permissions[0] != "task.claim" {
+		t.Fatalf("unexpected permissions %v", role.Permissions)
+	}
+	if _, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{ProjectID: "proj-1", RoleID: "triager", ActorID: "tester"}); err == nil {
+		t.Fatalf("expected duplicate role to fail")
+	}
+	if _, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{ProjectID: "proj-1", RoleID: "bogus", Grants: []string{"no.such"}, ActorID: "tester"}); err == nil {
+		t.Fatalf("expected unknown permission to fail")
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "triager"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	grants := []string{"task.claim", "task.update"}
+	role, err = env.Engine.UpdateRole(env.Ctx, engine.RoleUpdateOptions{ProjectID: "proj-1", RoleID: "triager", Grants: &grants, ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("update role: %v", err)
+	}
+	if len(role.Permissions) != 2 {
+		t.Fatalf("expected replaced permissions, got %v", role.Permissions)
+	}
+	who, err := env.Engine.WhoAmI(env.Ctx, "proj-1", "dana")
+	if err != nil {
+		t.Fatalf("whoami: %v", err)
+	}
+	if len(who.Permissions) != 2 {
+		t.Fatalf("expected role permissions to apply, got %v", who.Permissions)
+	}
+	if err := env.Engine.DeleteRole(env.Ctx, "proj-1", "dana", "triager"); err == nil {
+		t.Fatalf("expected delete without rbac.manage to fail")
+	}
+	if err := env.Engine.DeleteRole(env.Ctx, "proj-1", "tester", "owner"); err == nil {
+		t.Fatalf("expected owner role to be protected")
+	}
+	if _, err := env.Engine.UpdateRole(env.Ctx, engine.RoleUpdateOptions{ProjectID: "proj-1", RoleID: "dev", Grants: &grants, ActorID: "tester"}); err == nil {
+		t.Fatalf("expected built-in role to be protected")
+	}
+	if _, err := env.Engine.InitProject(env.Ctx, "proj-2", "org-2", "", "other", "mallory"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	if err := env.Engine.DeleteRole(env.Ctx, "proj-2", "mallory", "triager"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected another project's role to be hidden, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-2", "mallory", "erin", "triager"); err == nil {
+		t.Fatalf("expected another project's role grant to fail")
+	}
+	if err := env.Engine.GrantOrgRole(env.Ctx, "org-1", "tester", "erin", "triager"); err == nil {
+		t.Fatalf("expected custom role org grant to fail")
+	}
+	if err := env.Engine.DeleteRole(env.Ctx, "proj-1", "tester", "triager"); err != nil {
+		t.Fatalf("delete role: %v", err)
+	}
+	who, err = env.Engine.WhoAmI(env.Ctx, "proj-1", "dana")
+	if err != nil {
+		t.Fatalf("whoami: %v", err)
+	}
+	if len(who.Roles) != 0 {
+		t.Fatalf("expected grants removed with role, got %v", who.Roles)
+	}
+}
+
+func TestInspectRole(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	if err := env.Engine.GrantOrgRole(env.Ctx, "org-1", "tester", "erin", "dev"); err != nil {
+		t.Fatalf("grant org role: %v", err)
+	}
+	roles, err := env.Engine.ListRoles(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("list roles: %v", err)
+	}
+	if len(roles) == 0 {
+		t.Fatalf("expected seeded roles")
+	}
+	detail, err := env.Engine.InspectRole(env.Ctx, "proj-1", "tester", "dev")
+	if err != nil {
+		t.Fatalf("inspect role: %v", err)
+	}
+	if len(detail.Members) != 2 || detail.Members[0].ActorID != "dana" || detail.Members[1].Scope != "org" {
+		t.Fatalf("unexpected members %v", detail.Members)
+	}
+	if len(detail.Permissions) == 0 {
+		t.Fatalf("expected dev permissions")
+	}
+	if _, err := env.Engine.InspectRole(env.Ctx, "proj-1", "dana", "dev"); err == nil {
+		t.Fatalf("expected inspect without rbac.manage to fail")
+	}
+}
+
+func TestDeactivateActorReleasesLeases(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "leak", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "dana", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	released, err := env.Engine.DeactivateActor(env.Ctx, "proj-1", "tester", "dana", "credential leak")
+	if err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if len(released) != 1 || released[0] != task.ID {
+		t.Fatalf("expected lease release, got %v", released)
+	}
+	if _, err := env.Engine.Repo.GetLease(env.Ctx, task.ID); err != repo.ErrNotFound {
+		t.Fatalf("expected lease removed, got %v", err)
+	}
+	_, err = env.Engine.ClaimLease(env.Ctx, task.ID, "dana", 3600)
+	var suspended auth.SuspendedActorError
+	if !errors.As(err, &suspended) {
+		t.Fatalf("expected suspended actor error, got %v", err)
+	}
+	if err := env.Engine.ReactivateActor(env.Ctx, "proj-1", "tester", "dana"); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "dana", 3600); err != nil {
+		t.Fatalf("claim after reactivation: %v", err)
+	}
+
+	// dana also works for org-2: neither org's admin alone may suspend them.
+	if _, err := env.Engine.InitProject(env.Ctx, "proj-2", "org-2", "", "other", "mallory"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-2", "mallory", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	var forbidden auth.ForbiddenError
+	if _, err := env.Engine.DeactivateActor(env.Ctx, "proj-1", "tester", "dana", ""); !errors.As(err, &forbidden) {
+		t.Fatalf("expected deactivation across orgs to be forbidden, got %v", err)
+	}
+	if _, err := env.Engine.DeactivateActor(env.Ctx, "proj-2", "mallory", "dana", ""); !errors.As(err, &forbidden) {
+		t.Fatalf("expected deactivation across orgs to be forbidden, got %v", err)
+	}
+	if _, err := env.Engine.Repo.GetLease(env.Ctx, task.ID); err != nil {
+		t.Fatalf("expected lease kept, got %v", err)
+	}
+	if _, err := env.Engine.SetOrgMember(env.Ctx, "org-2", "mallory", "tester", engine.OrgRoleAdmin); err != nil {
+		t.Fatalf("add org admin: %v", err)
+	}
+	if _, err := env.Engine.DeactivateActor(env.Ctx, "proj-1", "tester", "dana", ""); err != nil {
+		t.Fatalf("deactivate as admin of both orgs: %v", err)
+	}
+}
+
+func TestApplyRoster(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	roster := config.Roster{
+		Actors: []config.RosterActor{
+			{ID: "tester", Roles: []string{"owner"}},
+			{ID: "erin", Roles: []string{"dev", "reviewer"}},
+		},
+		AttestationAuthorities: map[string][]string{"review.approved": {"reviewer"}},
+	}
+	dry, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "tester", roster, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(dry.Changes) < 4 || dry.Changes[0] != (domain.RosterChange{Op: "add", Type: "actor", ActorID: "erin"}) {
+		t.Fatalf("unexpected dry run changes %+v", dry.Changes)
+	}
+	if _, err := env.Engine.Repo.ActorStatus(env.Ctx, "erin"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected a dry run to register nobody, got %v", err)
+	}
+
+	res, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "tester", roster, false)
+	if err != nil || len(res.Changes) != len(dry.Changes) {
+		t.Fatalf("apply: %+v, %v", res, err)
+	}
+	if !slices.Contains(res.Changes, domain.RosterChange{Op: "remove", Type: "role", ActorID: "dana", RoleID: "dev"}) {
+		t.Fatalf("expected dana's role revoked, got %+v", res.Changes)
+	}
+	detail, err := env.Engine.InspectRole(env.Ctx, "proj-1", "tester", "reviewer")
+	if err != nil || len(detail.Members) != 1 || detail.Members[0].ActorID != "erin" || !slices.Equal(detail.AttestationKinds, []string{"review.approved"}) {
+		t.Fatalf("unexpected reviewer role %+v, %v", detail, err)
+	}
+	if again, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "tester", roster, false); err != nil || len(again.Changes) != 0 {
+		t.Fatalf("expected applying twice to change nothing, got %+v, %v", again, err)
+	}
+
+	// erin may manage rbac only through the owner role the roster would take away.
+	roster.Actors[1].Roles = []string{"owner"}
+	if _, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "tester", roster, false); err != nil {
+		t.Fatalf("promote erin: %v", err)
+	}
+	roster.Actors[1].Roles = []string{"dev"}
+	if _, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "erin", roster, false); err == nil || !strings.Contains(err.Error(), "rbac.manage") {
+		t.Fatalf("expected self-demotion to be rejected, got %v", err)
+	}
+	roster.Actors[1].Roles = []string{"ghost"}
+	if _, err := env.Engine.ApplyRoster(env.Ctx, "proj-1", "tester", roster, true); err == nil || !strings.Contains(err.Error(), "unknown role ghost") {
+		t.Fatalf("expected unknown role to be rejected, got %v", err)
+	}
+}
+
+func TestTimeBoxedRoleGrant(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRoleUntil(env.Ctx, "proj-1", "tester", "dana", "dev", "2023-12-31"); err == nil || !strings.Contains(err.Error(), "already past") {
+		t.Fatalf("expected a past expiry to be rejected, got %v", err)
+	}
+	if err := env.Engine.GrantRoleUntil(env.Ctx, "proj-1", "tester", "dana", "dev", "2099-01-01T00:00:00Z"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	who, err := env.Engine.WhoAmI(env.Ctx, "proj-1", "dana")
+	if err != nil || who.RoleExpiresAt["dev"] != "2099-01-01T00:00:00Z" {
+		t.Fatalf("unexpected whoami %+v, %v", who, err)
+	}
+	if ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "dana", "task.claim"); err != nil || !ok {
+		t.Fatalf("expected task.claim before expiry, got %v, %v", ok, err)
+	}
+
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `UPDATE actor_roles SET expires_at='2020-01-01T00:00:00Z' WHERE actor_id='dana'`); err != nil {
+		t.Fatalf("backdate grant: %v", err)
+	}
+	if ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "dana", "task.claim"); err != nil || ok {
+		t.Fatalf("expected an expired grant to be ignored, got %v, %v", ok, err)
+	}
+	detail, err := env.Engine.InspectRole(env.Ctx, "proj-1", "tester", "dev")
+	if err != nil || len(detail.Members) != 0 {
+		t.Fatalf("expected no dev members, got %+v, %v", detail, err)
+	}
+	expired, err := env.Engine.ExpireRoleGrants(env.Ctx, "proj-1", "system")
+	if err != nil || len(expired) != 1 || expired[0].ActorID != "dana" || expired[0].RoleID != "dev" {
+		t.Fatalf("unexpected expired grants %+v, %v", expired, err)
+	}
+	var count int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM actor_roles WHERE actor_id='dana'`).Scan(&count); err != nil || count != 0 {
+		t.Fatalf("expected the grant deleted, got %d, %v", count, err)
+	}
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='rbac.role_expired'`).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("expected one rbac.role_expired event, got %d, %v", count, err)
+	}
+	if again, err := env.Engine.ExpireRoleGrants(env.Ctx, "proj-1", "system"); err != nil || len(again) != 0 {
+		t.Fatalf("expected a second sweep to expire nothing, got %+v, %v", again, err)
+	}
+}
+
+func TestSimulatePermission(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	sim, err := env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "tester", TargetActorID: "dana", Action: "task.claim"})
+	if err != nil || !sim.Allowed || len(sim.Checks) != 3 {
+		t.Fatalf("unexpected simulation %+v, %v", sim, err)
+	}
+	if got := sim.Checks[1].GrantedBy; len(got) != 1 || got[0] != (domain.RoleSource{RoleID: "dev", Scope: "project"}) {
+		t.Fatalf("expected task.claim granted by dev, got %+v", got)
+	}
+
+	sim, err = env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "tester", TargetActorID: "dana", Action: "attestation.add", Kind: "review.approved"})
+	if err != nil || sim.Allowed {
+		t.Fatalf("expected attestation.add denied, got %+v, %v", sim, err)
+	}
+	for _, c := range sim.Checks {
+		if (c.Check == "permission" || c.Check == "attestation_authority") && (c.Passed || !slices.Contains(c.Candidates, "reviewer")) {
+			t.Fatalf("expected %s to fail with reviewer as candidate, got %+v", c.Check, c)
+		}
+	}
+	var count int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='auth.denied'`).Scan(&count); err != nil || count != 0 {
+		t.Fatalf("expected a simulation to record nothing, got %d, %v", count, err)
+	}
+
+	if _, err := env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "dana", TargetActorID: "tester", Action: "task.claim"}); err == nil {
+		t.Fatalf("expected explaining another actor to need rbac.manage")
+	}
+	if sim, err := env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "dana", Action: "task.claim"}); err != nil || !sim.Allowed {
+		t.Fatalf("expected dana to explain its own permissions, got %+v, %v", sim, err)
+	}
+	if _, err := env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "tester", Action: "task.fly"}); err == nil || !strings.Contains(err.Error(), "unknown permission") {
+		t.Fatalf("expected unknown permission, got %v", err)
+	}
+	if _, err := env.Engine.SimulatePermission(env.Ctx, engine.PermissionSimulationOptions{ProjectID: "proj-1", ActorID: "tester", Action: "task.claim", Kind: "feature"}); err == nil || !strings.Contains(err.Error(), "invalid kind") {
+		t.Fatalf("expected kind to be rejected for task.claim, got %v", err)
+	}
+}
+
+func TestReadAudit(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.RecordRead(env.Ctx, "proj-1", "tester", "events", "GET /events"); err != nil {
+		t.Fatalf("record read while off: %v", err)
+	}
+	env.Engine.Config.Project.ReadAudit = config.ReadAuditConfig{Mode: "sampled", SampleRate: 1.5}
+	if err := env.Engine.Config.Validate(); err == nil || !strings.Contains(err.Error(), "sample_rate") {
+		t.Fatalf("expected sample_rate to be rejected, got %v", err)
+	}
+	env.Engine.Config.Project.ReadAudit = config.ReadAuditConfig{Mode: "full", Resources: []string{"config", "attestations"}}
+	if err := env.Engine.Config.Validate(); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+	for _, r := range []struct{ resource, target string }{{"config", "GET /config"}, {"events", "GET /events"}, {"attestations", "GET /attestations"}} {
+		if err := env.Engine.RecordRead(env.Ctx, "proj-1", "dana", r.resource, r.target); err != nil {
+			t.Fatalf("record read of %s: %v", r.resource, err)
+		}
+	}
+	entries, err := env.Engine.ReadAudit(env.Ctx, "tester", repo.ReadAuditFilters{ProjectID: "proj-1"})
+	if err != nil || len(entries) != 2 || entries[0].Resource != "attestations" || entries[1].Target != "GET /config" || entries[1].ActorID != "dana" {
+		t.Fatalf("unexpected read audit %+v, %v", entries, err)
+	}
+	if entries, err := env.Engine.ReadAudit(env.Ctx, "tester", repo.ReadAuditFilters{ProjectID: "proj-1", Resource: "config", Since: "2024-01-01"}); err != nil || len(entries) != 1 {
+		t.Fatalf("expected one config read since 2024-01-01, got %+v, %v", entries, err)
+	}
+	if _, err := env.Engine.ReadAudit(env.Ctx, "tester", repo.ReadAuditFilters{ProjectID: "proj-1", Since: "yesterday"}); err == nil || !strings.Contains(err.Error(), "invalid since") {
+		t.Fatalf("expected invalid since, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	if _, err := env.Engine.ReadAudit(env.Ctx, "dana", repo.ReadAuditFilters{ProjectID: "proj-1"}); err == nil {
+		t.Fatalf("expected the read audit to need events.audit.read")
+	}
+}
+
+func TestAttestationPayloadSchema(t *testing.T) {
+	env := newTestEnv(t)
+	for i := range env.Engine.Config.Project.Attestations {
+		if env.Engine.Config.Project.Attestations[i].ID == "init.check" {
+			env.Engine.Config.Project.Attestations[i].Schema = map[string]any{
+				"type":     "object",
+				"required": []any{"coverage"},
+				"properties": map[string]any{
+					"coverage": map[string]any{"type": "number", "minimum": 80},
+				},
+			}
+		}
+	}
+	if err := env.Engine.Config.Validate(); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+	att := domain.Attestation{ProjectID: "proj-1", EntityKind: "project", EntityID: "proj-1", Kind: "init.check"}
+	att.PayloadJSON = `{"coverage": 42}`
+	_, err := env.Engine.AddAttestation(env.Ctx, att, "tester")
+	var payloadErr engine.AttestationPayloadError
+	if !errors.As(err, &payloadErr) {
+		t.Fatalf("expected payload error, got %v", err)
+	}
+	if len(payloadErr.Violations) != 1 || payloadErr.Violations[0].Path != "/coverage" {
+		t.Fatalf("unexpected violations %v", payloadErr.Violations)
+	}
+	att.PayloadJSON = ""
+	if _, err := env.Engine.AddAttestation(env.Ctx, att, "tester"); !errors.As(err, &payloadErr) {
+		t.Fatalf("expected missing payload to fail, got %v", err)
+	}
+	att.PayloadJSON = `{"coverage": 91.5}`
+	if _, err := env.Engine.AddAttestation(env.Ctx, att, "tester"); err != nil {
+		t.Fatalf("valid payload rejected: %v", err)
+	}
+}
+
+func TestAttestProvenance(t *testing.T) {
+	env := newTestEnv(t)
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Release 1.0", Type: "release", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if task.RequiredAttestationsJSON == nil || !strings.Contains(*task.RequiredAttestationsJSON, engine.ProvenanceKind) {
+		t.Fatalf("release tasks should require %s, got %v", engine.ProvenanceKind, task.RequiredAttestationsJSON)
+	}
+
+	var payloadErr engine.AttestationPayloadError
+	bad := `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"wl.tgz","digest":{"sha256":"not-hex"}}],"predicateType":"https://slsa.dev/provenance/v1","predicate":{"buildDefinition":{"buildType":"https://example.com/build"}}}`
+	if _, err := env.Engine.AttestProvenance(env.Ctx, task.ID, []byte(bad), "tester"); !errors.As(err, &payloadErr) {
+		t.Fatalf("expected payload error, got %v", err)
+	}
+	var paths []string
+	for _, v := range payloadErr.Violations {
+		paths = append(paths, v.Path)
+	}
+	if !slices.Equal(paths, []string{"/subject/0/digest/sha256", "/predicate/runDetails/builder/id"}) {
+		t.Fatalf("unexpected violations %v", payloadErr.Violations)
+	}
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: task.ID, Kind: engine.ProvenanceKind}, "tester"); !errors.As(err, &payloadErr) {
+		t.Fatalf("expected provenance without payload to fail, got %v", err)
+	}
+
+	stmt := `{"_type":"https://in-toto.io/Statement/v0.1","subject":[{"name":"wl.tgz","digest":{"sha256":"9f86d081884c7d65"}}],"predicateType":"https://slsa.dev/provenance/v0.2","predicate":{"builder":{"id":"https://github.com/actions/runner"},"buildType":"https://github.com/slsa-framework/slsa-github-generator/generic@v1"}}`
+	envelope, _ := json.Marshal(map[string]any{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString([]byte(stmt)),
+		"signatures":  []any{map[string]any{"keyid": "", "sig": "MEUCIQ"}},
+	})
+	att, err := env.Engine.AttestProvenance(env.Ctx, task.ID, envelope, "tester")
+	if err != nil {
+		t.Fatalf("attest provenance: %v", err)
+	}
+	if att.Kind != engine.ProvenanceKind || !strings.Contains(att.PayloadJSON, `"predicateType":"https://slsa.dev/provenance/v0.2"`) || strings.Contains(att.PayloadJSON, "signatures") {
+		t.Fatalf("expected the unwrapped statement as payload, got %+v", att)
+	}
+}
+
+func TestAttestationEvidence(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Blobs = blob.Local{Dir: t.TempDir()}
+	env.Engine.Config.Evidence.MaxBytes = 16
+	att, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{
+		ProjectID:  "proj-1",
+		EntityKind: "project",
+		EntityID:   "proj-1",
+		Kind:       "init.check",
+	}, "tester")
+	if err != nil {
+		t.Fatalf("add attestation: %v", err)
+	}
+	ev, err := env.Engine.AddEvidence(env.Ctx, engine.EvidenceOptions{
+		ProjectID:     "proj-1",
+		AttestationID: att.ID,
+		Name:          "../report.txt",
+		ContentType:   "text/plain",
+		Body:          strings.NewReader("all green"),
+		ActorID:       "tester",
+	})
+	if err != nil {
+		t.Fatalf("add evidence: %v", err)
+	}
+	if ev.Name != "report.txt" || ev.Size != 9 {
+		t.Fatalf("unexpected evidence %+v", ev)
+	}
+	_, err = env.Engine.AddEvidence(env.Ctx, engine.EvidenceOptions{
+		ProjectID:     "proj-1",
+		AttestationID: att.ID,
+		Name:          "big.log",
+		Body:          strings.NewReader(strings.Repeat("x", 17)),
+		ActorID:       "tester",
+	})
+	var tooLarge engine.EvidenceTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+	items, err := env.Engine.ListEvidence(env.Ctx, "proj-1", att.ID, "tester")
+	if err != nil {
+		t.Fatalf("list evidence: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected one evidence, got %d", len(items))
+	}
+	_, rc, err := env.Engine.OpenEvidence(env.Ctx, "proj-1", att.ID, ev.ID, "tester")
+	if err != nil {
+		t.Fatalf("open evidence: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read evidence: %v", err)
+	}
+	if string(data) != "all green" {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
+
+func TestDashboardCoverage(t *testing.T) {
+	env := newTestEnv(t)
+	advance := func(id string) {
+		t.Helper()
+		for _, status := range []string{"ready", "in_progress", "review"} {
+			if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: id, Status: status, ActorID: "tester", Force: true}); err != nil {
+				t.Fatalf("to %s: %v", status, err)
+			}
+		}
+	}
+	gated, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "gated", ActorID: "tester", RequiredKinds: []string{"init.check"}, PolicyOverride: true})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	forced, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "forced", ActorID: "tester", RequiredKinds: []string{"init.check"}, PolicyOverride: true})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	advance(gated.ID)
+	advance(forced.ID)
+	if _, err := env.Engine.ClaimLease(env.Ctx, gated.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: gated.ID, Kind: "init.check"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	if _, err := env.Engine.TaskDone(env.Ctx, gated.ID, `{}`, "tester", false); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if _, err := env.Engine.TaskDone(env.Ctx, forced.ID, `{}`, "tester", true); err != nil {
+		t.Fatalf("forced done: %v", err)
+	}
+	d, err := env.Engine.Dashboard(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("dashboard: %v", err)
+	}
+	if d.DoneTasks != 2 || d.CoveredTasks != 1 || d.AttestationCoverage != 50 {
+		t.Fatalf("unexpected coverage %+v", d)
+	}
+}
+
+func TestTaskTimeTracking(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g", Status: "pending"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	inIter, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "planned", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	loose, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "loose", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.LogTime(env.Ctx, engine.TimeLogOptions{TaskID: inIter.ID, Minutes: 0, ActorID: "tester"}); err == nil {
+		t.Fatalf("expected zero minutes to be rejected")
+	}
+	for _, entry := range []engine.TimeLogOptions{
+		{TaskID: inIter.ID, Minutes: 90, Note: "spike", ActorID: "tester"},
+		{TaskID: inIter.ID, Minutes: 30, ActorID: "tester"},
+		{TaskID: loose.ID, Minutes: 15, ActorID: "tester"},
+	} {
+		if _, err := env.Engine.LogTime(env.Ctx, entry); err != nil {
+			t.Fatalf("log time: %v", err)
+		}
+	}
+	entries, err := env.Engine.ListTimeEntries(env.Ctx, inIter.ID, "tester")
+	if err != nil || len(entries) != 2 || entries[0].Note != "spike" {
+		t.Fatalf("entries: %v %+v", err, entries)
+	}
+	total, err := env.Engine.Repo.TaskTimeTotal(env.Ctx, inIter.ID)
+	if err != nil || total != 120 {
+		t.Fatalf("task total: %v %d", err, total)
+	}
+	totals, err := env.Engine.IterationTimeTotals(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("iteration totals: %v", err)
+	}
+	want := []domain.IterationTime{{IterationID: "", Minutes: 15}, {IterationID: "iter-1", Minutes: 120}}
+	if len(totals) != len(want) || totals[0] != want[0] || totals[1] != want[1] {
+		t.Fatalf("iteration totals: %+v", totals)
+	}
+}
+
+func TestIterationCapacity(t *testing.T) {
+	env := newTestEnv(t)
+	capacity := 5.0
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g", Capacity: &capacity}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	est := func(v float64) *float64 { return &v }
+	first, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "first", ActorID: "tester", Estimate: est(3)})
+	if err != nil || len(first.Warnings) != 0 {
+		t.Fatalf("first: %v %v", err, first.Warnings)
+	}
+	second, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "second", ActorID: "tester", Estimate: est(3)})
+	if err != nil || len(second.Warnings) != 1 {
+		t.Fatalf("expected over-capacity warning: %v %v", err, second.Warnings)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: first.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	progress, err := env.Engine.IterationProgress(env.Ctx, "iter-1", "tester")
+	if err != nil {
+		t.Fatalf("progress: %v", err)
+	}
+	if progress.PlannedPoints != 6 || progress.CompletedPoints != 3 || !progress.OverCapacity || progress.Unit != "points" {
+		t.Fatalf("progress: %+v", progress)
+	}
+
+	env.Engine.Config.Project.Planning.CapacityCheck = "block"
+	_, err = env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "third", ActorID: "tester", Estimate: est(1)})
+	var ce engine.CapacityExceededError
+	if !errors.As(err, &ce) || ce.Planned != 7 {
+		t.Fatalf("expected capacity error, got %v", err)
+	}
+	loose, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "loose", ActorID: "tester", Estimate: est(2)})
+	if err != nil {
+		t.Fatalf("create loose: %v", err)
+	}
+	iter := "iter-1"
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: loose.ID, IterationProvided: true, SetIteration: &iter, ActorID: "tester"}); !errors.As(err, &ce) {
+		t.Fatalf("expected capacity error when planning into iteration, got %v", err)
+	}
+	if _, err := env.Engine.SetIterationCapacity(env.Ctx, "iter-1", est(10), "tester"); err != nil {
+		t.Fatalf("set capacity: %v", err)
+	}
+	moved, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: loose.ID, IterationProvided: true, SetIteration: &iter, ActorID: "tester"})
+	if err != nil || moved.IterationID == nil || *moved.IterationID != "iter-1" {
+		t.Fatalf("move: %v %+v", err, moved.IterationID)
+	}
+}
+
+func TestWIPLimits(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Config.Project.Board.WIPLimits = map[string]int{"in_progress": 1}
+	var tasks []domain.Task
+	for _, title := range []string{"first", "second", "third"} {
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "tester", 60); err != nil {
+			t.Fatalf("claim %s: %v", title, err)
+		}
+		tasks = append(tasks, task)
+	}
+	first, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tasks[0].ID, Status: "in_progress", ActorID: "tester"})
+	if err != nil || len(first.Warnings) != 0 {
+		t.Fatalf("first: %v %v", err, first.Warnings)
+	}
+	second, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tasks[1].ID, Status: "in_progress", ActorID: "tester"})
+	if err != nil || len(second.Warnings) != 1 {
+		t.Fatalf("expected wip warning: %v %v", err, second.Warnings)
+	}
+	evts, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "wip.exceeded", "task", tasks[1].ID)
+	if err != nil || len(evts) != 1 {
+		t.Fatalf("expected wip.exceeded event: %v %d", err, len(evts))
+	}
+
+	env.Engine.Config.Project.Board.StrictWIP = true
+	_, err = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tasks[2].ID, Status: "in_progress", ActorID: "tester"})
+	var we engine.WIPExceededError
+	if !errors.As(err, &we) || we.Status != "in_progress" || we.Limit != 1 || we.Count != 3 {
+		t.Fatalf("expected wip error, got %v", err)
+	}
+	forced, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tasks[2].ID, Status: "in_progress", ActorID: "tester", Force: true})
+	if err != nil || len(forced.Warnings) != 1 {
+		t.Fatalf("forced move: %v %v", err, forced.Warnings)
+	}
+
+	board, err := env.Engine.Board(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	if !board.StrictWIP || len(board.Columns) == 0 || board.Columns[0].Status != "planned" {
+		t.Fatalf("board: %+v", board)
+	}
+	for _, col := range board.Columns {
+		if col.Status != "in_progress" {
+			if col.WIPLimit != nil || col.OverLimit {
+				t.Fatalf("unexpected limit on %s: %+v", col.Status, col)
+			}
+			continue
+		}
+		if col.Count != 3 || len(col.Tasks) != 3 || col.WIPLimit == nil || *col.WIPLimit != 1 || !col.OverLimit {
+			t.Fatalf("in_progress column: %+v", col)
+		}
+	}
+
+	env.Engine.Config.Project.Board.WIPLimits = map[string]int{"nowhere": 0}
+	if err := env.Engine.Config.Validate(); err == nil || !strings.Contains(err.Error(), "unknown status nowhere") || !strings.Contains(err.Error(), "must be positive") {
+		t.Fatalf("expected wip_limits validation errors, got %v", err)
+	}
+}
+
+func TestTransitionHooks(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Config.Project.Hooks = []config.HookConfig{
+		{Name: "pick-reviewer", On: "task", To: "review", Run: "assign_reviewer", With: map[string]any{"pool": []any{"tester", "alice", "bob"}}},
+		{On: "task", To: "review", Run: "no_such_hook"},
+		{Name: "release-task", On: "iteration", From: "running", To: "delivered", Run: "create_task", With: map[string]any{"title": "Release {entity_id}"}},
+	}
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	first, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "first", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	second, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "second", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	toReview := func(id string) domain.Task {
+		t.Helper()
+		task, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: id, Status: "review", ActorID: "tester", Force: true})
+		if err != nil {
+			t.Fatalf("to review: %v", err)
+		}
+		return task
+	}
+	if got := toReview(first.ID); got.AssigneeID == nil || *got.AssigneeID != "alice" {
+		t.Fatalf("expected alice assigned, got %v", got.AssigneeID)
+	}
+	if got := toReview(second.ID); got.AssigneeID == nil || *got.AssigneeID != "bob" {
+		t.Fatalf("expected least-loaded bob assigned, got %v", got.AssigneeID)
+	}
+	failed, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "hook.failed", "task", first.ID)
+	if err != nil || len(failed) != 1 {
+		t.Fatalf("expected recorded hook failure: %v %d", err, len(failed))
+	}
+
+	for _, status := range []string{"running", "delivered"} {
+		if _, err := env.Engine.SetIterationStatus(env.Ctx, "iter-1", status, "tester", true); err != nil {
+			t.Fatalf("iteration to %s: %v", status, err)
+		}
+	}
+	tasks, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1", Iteration: "iter-1"})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	var release bool
+	for _, task := range tasks {
+		release = release || task.Title == "Release iter-1"
+	}
+	if !release {
+		t.Fatalf("expected release task to be created")
+	}
+
+	env.Engine.Config.Project.Hooks = []config.HookConfig{{On: "task", To: "in_progress", Run: "no_such_hook", Required: true}}
+	third, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "third", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: third.ID, Status: "in_progress", ActorID: "tester", Force: true}); err == nil {
+		t.Fatalf("expected required hook failure to abort the transition")
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, third.ID); got.Status != "planned" {
+		t.Fatalf("transition should have been rolled back, status %s", got.Status)
+	}
+}
+
+func TestWebhookHookPostedAfterCommit(t *testing.T) {
+	env := newTestEnv(t)
+	var posts []string
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts = append(posts, r.Header.Get("X-Workline-Delivery"))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	env.Engine.Config.Project.Hooks = []config.HookConfig{{Name: "notify", On: "task", To: "in_progress", Run: "webhook", URL: srv.URL}}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "hooked", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "in_progress", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("a failing webhook must not abort the transition: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected one post after commit, got %v", posts)
+	}
+	if sent, err := env.Engine.SendHookDeliveries(env.Ctx); err != nil || sent != 0 {
+		t.Fatalf("failed delivery should wait for its backoff: %d %v", sent, err)
+	}
+
+	status = http.StatusNoContent
+	env.Engine.Now = func() time.Time { return time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC) }
+	if sent, err := env.Engine.SendHookDeliveries(env.Ctx); err != nil || sent != 1 {
+		t.Fatalf("expected the retry to deliver: %d %v", sent, err)
+	}
+	if len(posts) != 2 || posts[0] != posts[1] {
+		t.Fatalf("retry should reuse the delivery id: %v", posts)
+	}
+	if sent, err := env.Engine.SendHookDeliveries(env.Ctx); err != nil || sent != 0 {
+		t.Fatalf("delivered hook should leave the queue: %d %v", sent, err)
+	}
+
+	env.Engine.Config.Project.Hooks[0].Required = true
+	if err := env.Engine.Config.Validate(); err == nil {
+		t.Fatalf("expected required webhook hooks to be rejected")
+	}
+}
+
+func TestCustomTaskWorkflow(t *testing.T) {
+	env := newTestEnv(t)
+	tt := env.Engine.Config.Project.TaskTypes["technical"]
+	tt.Workflow = &config.WorkflowConfig{
+		Initial: "todo",
+		States:  []string{"todo", "doing", "blocked", "shipped", "dropped"},
+		Transitions: map[string][]string{
+			"todo":    {"doing", "dropped"},
+			"doing":   {"blocked", "shipped"},
+			"blocked": {"doing"},
+		},
+		Terminal: []string{"shipped", "dropped"},
+		Done:     "shipped",
+	}
+	env.Engine.Config.Project.TaskTypes["technical"] = tt
+	if err := env.Engine.Config.Validate(); err != nil {
+		t.Fatalf("validate workflow: %v", err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "custom", ActorID: "tester"})
+	if err != nil || task.Status != "todo" {
+		t.Fatalf("create: %v %s", err, task.Status)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "review", ActorID: "tester", Force: true}); err == nil {
+		t.Fatalf("expected unknown state to be rejected even with force")
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "tester", 600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "blocked", ActorID: "tester"}); err == nil {
+		t.Fatalf("expected todo -> blocked to be rejected")
+	}
+	if task, err = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "doing", ActorID: "tester"}); err != nil || task.Status != "doing" {
+		t.Fatalf("to doing: %v", err)
+	}
+	task, err = env.Engine.TaskDone(env.Ctx, task.ID, `{}`, "tester", true)
+	if err != nil || task.Status != "shipped" || task.CompletedAt == nil {
+		t.Fatalf("done: %v %s", err, task.Status)
+	}
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	forced, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "forced", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: forced.ID, Status: "shipped", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("force to shipped: %v", err)
+	}
+	d, err := env.Engine.Dashboard(env.Ctx, "proj-1", "tester")
+	if err != nil || len(d.Iterations) != 1 || d.Iterations[0].ForcedCompletions != 1 {
+		t.Fatalf("expected forced move into the done state to be counted: %v %+v", err, d.Iterations)
+	}
+
+	tt.Workflow.Done = "dropped"
+	tt.Workflow.Terminal = []string{"shipped"}
+	if err := env.Engine.Config.Validate(); err == nil {
+		t.Fatalf("expected non-terminal done state to fail validation")
+	}
+}
+
+func TestProjectConfigVersions(t *testing.T) {
+	env := newTestEnv(t)
+	versions, err := env.Engine.ProjectConfigVersions(env.Ctx, "proj-1", "tester")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("initial versions: %v %d", err, len(versions))
+	}
+	base := versions[0].Version
+	cfg := config.Default("proj-1")
+	cfg.Project.Planning.EstimateUnit = "hours"
+	updated, err := env.Engine.UpdateProjectConfig(env.Ctx, "proj-1", "tester", cfg)
+	if err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+	if updated.Version != base+1 || len(updated.Changes) != 1 || updated.Changes[0].Path != "project.planning.estimate_unit" || updated.Changes[0].To != "hours" {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+	if same, err := env.Engine.UpdateProjectConfig(env.Ctx, "proj-1", "tester", cfg); err != nil || same.Version != updated.Version {
+		t.Fatalf("unchanged config should not add a version: %v %d", err, same.Version)
+	}
+	restored, err := env.Engine.RollbackProjectConfig(env.Ctx, "proj-1", "tester", base)
+	if err != nil || restored.Version != base+2 {
+		t.Fatalf("rollback: %v %+v", err, restored)
+	}
+	stored, err := env.Engine.Repo.GetProjectConfig(env.Ctx, "proj-1")
+	if err != nil || stored.EstimateUnit() != "points" {
+		t.Fatalf("rolled back config: %v %s", err, stored.EstimateUnit())
+	}
+	changes, err := env.Engine.DiffProjectConfigVersions(env.Ctx, "proj-1", "tester", base, 0)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("rolled back config should match base: %v %+v", err, changes)
+	}
+	evs, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "config.updated", "project", "proj-1")
+	if err != nil || len(evs) != 2 {
+		t.Fatalf("expected config.updated events: %v %d", err, len(evs))
+	}
+	if _, err := env.Engine.RollbackProjectConfig(env.Ctx, "proj-1", "tester", 99); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected missing version error, got %v", err)
+	}
+}
+
+func TestConfigImportPlan(t *testing.T) {
+	env := newTestEnv(t)
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "needs check", ActorID: "tester", PolicyOverride: true, RequiredKinds: []string{"scope.groomed"}})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	cfg := config.Default("proj-1")
+	var kept []config.AttestationConfig
+	for _, att := range cfg.Project.Attestations {
+		if att.ID != "scope.groomed" {
+			kept = append(kept, att)
+		}
+	}
+	cfg.Project.Attestations = append(kept, config.AttestationConfig{ID: "perf.checked", Category: "quality", Description: "Performance checked"})
+	delete(cfg.Project.TaskTypes["feature"].Policies, "ready")
+	role := cfg.Project.RBAC.Roles["dev"]
+	role.CanAttest = append(role.CanAttest, "perf.checked")
+	cfg.Project.RBAC.Roles["dev"] = role
+
+	plan, err := env.Engine.PlanProjectConfigImport(env.Ctx, "proj-1", "tester", cfg)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(plan.AddedAttestations) != 1 || plan.AddedAttestations[0] != "perf.checked" {
+		t.Fatalf("added: %v", plan.AddedAttestations)
+	}
+	if len(plan.RemovedAttestations) != 1 || plan.RemovedAttestations[0] != "scope.groomed" {
+		t.Fatalf("removed: %v", plan.RemovedAttestations)
+	}
+	if len(plan.PolicyChanges) == 0 || len(plan.RBACChanges) == 0 {
+		t.Fatalf("expected policy and rbac changes: %+v", plan)
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], task.ID) {
+		t.Fatalf("expected warning naming %s: %v", task.ID, plan.Warnings)
+	}
+	versions, err := env.Engine.ProjectConfigVersions(env.Ctx, "proj-1", "tester")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("dry run must not store a version: %v %d", err, len(versions))
+	}
+}
+
+func TestConfigValidationReportsAllProblems(t *testing.T) {
+	data := `project:
+  id: proj-1
+  task_types:
+    bug:
+      policies:
+        done:
+          all: [ci.passed, ci.flaky]
+  attestations:
+    - id: ci.passed
+    - id: ci.passed
+  rbac:
+    permissions:
+      writer: [task.update, task.teleport]
+    roles:
+      owner:
+        grants: [writer]
+`
+	_, err := config.FromYAML([]byte(data))
+	var problems config.ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 3 {
+		t.Fatalf("expected three problems, got %v", err)
+	}
+	lines := map[int]bool{}
+	for _, p := range problems {
+		lines[p.Line] = true
+	}
+	if !lines[7] || !lines[10] || !lines[13] {
+		t.Fatalf("expected problems on lines 7, 10 and 13: %+v", problems)
+	}
+}
+
+func TestConfigSecretInterpolation(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	t.Setenv("WL_TEST_HOOK_SECRET", "from-env")
+	cfg := config.Default("proj-1")
+	cfg.Webhooks = []config.WebhookConfig{
+		{URL: "https://hooks.example.com/${WL_TEST_HOOK_SECRET}", Secret: "${WL_TEST_HOOK_SECRET}"},
+		{URL: "https://hooks.example.com/b", Secret: "file://" + secretFile},
+	}
+	resolved, err := cfg.ResolveSecrets()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if resolved.Webhooks[0].Secret != "from-env" || resolved.Webhooks[0].URL != "https://hooks.example.com/from-env" || resolved.Webhooks[1].Secret != "from-file" {
+		t.Fatalf("unexpected resolution: %+v", resolved.Webhooks)
+	}
+	if cfg.Webhooks[0].Secret != "${WL_TEST_HOOK_SECRET}" {
+		t.Fatalf("original config must keep the reference, got %s", cfg.Webhooks[0].Secret)
+	}
+
+	data := `project:
+  id: proj-1
+  task_types:
+    bug:
+      policies:
+        done:
+          all: []
+webhooks:
+  - url: https://hooks.example.com
+    secret: ${WL_TEST_MISSING_SECRET}
+`
+	_, err = config.FromYAML([]byte(data))
+	var problems config.ValidationErrors
+	if !errors.As(err, &problems) || len(problems) != 1 || problems[0].Line != 10 || !strings.Contains(problems[0].Message, "WL_TEST_MISSING_SECRET") {
+		t.Fatalf("expected missing secret on line 10, got %v", err)
+	}
+}
+
+func TestInitProjectFromPresets(t *testing.T) {
+	for _, preset := range config.Presets() {
+		data, err := config.PresetYAML(preset, "proj-"+preset)
+		if err != nil {
+			t.Fatalf("%s: %v", preset, err)
+		}
+		cfg, err := config.FromYAML([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: parse: %v", preset, err)
+		}
+		conn, err := db.Open(db.Config{Workspace: t.TempDir()})
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		if err := migrate.Migrate(conn); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		eng := engine.New(conn, cfg)
+		ctx := context.Background()
+		if _, err := eng.InitProject(ctx, "proj-"+preset, "org-1", preset, preset, "founder"); err != nil {
+			t.Fatalf("%s: init: %v", preset, err)
+		}
+		if _, err := eng.CreateTask(ctx, engine.TaskCreateOptions{ProjectID: "proj-" + preset, Title: "first", ActorID: "founder"}); err != nil {
+			t.Fatalf("%s: create task as owner: %v", preset, err)
+		}
+		conn.Close()
+	}
+	if _, err := config.PresetYAML("gardening", "p"); err == nil {
+		t.Fatalf("expected unknown preset error")
+	}
+}
+
+func TestInitProjectKinds(t *testing.T) {
+	conn, err := db.Open(db.Config{Workspace: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	if err := migrate.Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	eng := engine.New(conn, nil)
+	p, err := eng.InitProject(ctx, "notes", "org-1", "content", "", "founder")
+	if err != nil {
+		t.Fatalf("init content project: %v", err)
+	}
+	if p.Kind != "content" {
+		t.Fatalf("expected content kind, got %s", p.Kind)
+	}
+	cfg, err := eng.Repo.GetProjectConfig(ctx, "notes")
+	if err != nil {
+		t.Fatalf("get config: %v", err)
+	}
+	if _, ok := cfg.Project.TaskTypes["article"]; !ok {
+		t.Fatalf("expected content starter config, got task types %v", cfg.Project.TaskTypes)
+	}
+	p, err = eng.InitProject(ctx, "app", "org-1", "", "", "founder")
+	if err != nil || p.Kind != config.DefaultKind {
+		t.Fatalf("expected default kind, got %+v %v", p, err)
+	}
+
+	if _, err := eng.InitProject(ctx, "garden", "org-1", "gardening", "", "founder"); err == nil || !strings.Contains(err.Error(), "invalid project kind") {
+		t.Fatalf("expected invalid kind error, got %v", err)
+	}
+	mismatched := engine.New(conn, config.Default("infra"))
+	if _, err := mismatched.InitProject(ctx, "infra", "org-1", "ops", "", "founder"); err == nil || !strings.Contains(err.Error(), "incident") {
+		t.Fatalf("expected ops policy defaults to be enforced, got %v", err)
+	}
+	if _, err := eng.Repo.GetProject(ctx, "infra"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected rejected project to be rolled back, got %v", err)
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	env := newTestEnv(t)
+	conn := env.Engine.DB
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Keep me", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	latest, err := migrate.LatestVersion()
+	if err != nil {
+		t.Fatalf("latest version: %v", err)
+	}
+	if err := migrate.Check(conn); err != nil {
+		t.Fatalf("check at latest: %v", err)
+	}
+	steps, err := migrate.Plan(conn, 5)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(steps) != latest-5 || steps[0].Version != latest || steps[0].Direction != migrate.DirectionDown {
+		t.Fatalf("unexpected down plan: %+v", steps)
+	}
+	if err := migrate.MigrateTo(conn, 5); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	var verr migrate.VersionError
+	if err := migrate.Check(conn); !errors.As(err, &verr) || verr.Current != 5 || verr.Supported != latest {
+		t.Fatalf("expected outdated schema error, got %v", err)
+	}
+	statuses, current, err := migrate.Status(conn)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if current != 5 || len(statuses) != latest || !statuses[4].Applied || statuses[5].Applied {
+		t.Fatalf("unexpected status at %d: %+v", current, statuses)
+	}
+	if err := migrate.Migrate(conn); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	got, err := env.Engine.Repo.GetTask(env.Ctx, task.ID)
+	if err != nil {
+		t.Fatalf("task lost across down/up: %v", err)
+	}
+	if got.Title != "Keep me" {
+		t.Fatalf("unexpected task after round trip: %+v", got)
+	}
+	if _, err := conn.Exec(`UPDATE schema_version SET version=?`, latest+1); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	if err := migrate.Migrate(conn); !errors.As(err, &verr) || verr.Current != latest+1 {
+		t.Fatalf("expected newer schema to be refused, got %v", err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	conn, err := db.Open(db.Config{Workspace: dir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := migrate.Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx := context.Background()
+	eng := engine.New(conn, config.Default("proj-1"))
+	if _, err := eng.InitProject(ctx, "proj-1", "org-1", "", "test", "tester"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	kept, err := eng.CreateTask(ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Before backup", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "snap.db")
+	if err := db.Backup(ctx, conn, out); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := db.Backup(ctx, conn, out); err == nil {
+		t.Fatalf("expected backup to refuse an existing file")
+	}
+	lost, err := eng.CreateTask(ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "After backup", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	conn.Close()
+
+	junk := filepath.Join(t.TempDir(), "junk.db")
+	if err := os.WriteFile(junk, []byte("not sqlite"), 0o644); err != nil {
+		t.Fatalf("write junk: %v", err)
+	}
+	if err := db.Restore(dir, junk); err == nil {
+		t.Fatalf("expected restore of a non-database to fail")
+	}
+	if err := db.Restore(dir, out); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	conn, err = db.Open(db.Config{Workspace: dir})
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer conn.Close()
+	r := repo.Repo{DB: conn}
+	if _, err := r.GetTask(ctx, kept.ID); err != nil {
+		t.Fatalf("expected task from backup: %v", err)
+	}
+	if _, err := r.GetTask(ctx, lost.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected task created after backup to be gone, got %v", err)
+	}
+
+	backups := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if err := os.WriteFile(filepath.Join(backups, db.BackupName(base.Add(time.Duration(i)*time.Hour))), nil, 0o644); err != nil {
+			t.Fatalf("write backup: %v", err)
+		}
+	}
+	if err := db.PruneBackups(backups, 2); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	entries, _ := os.ReadDir(backups)
+	if len(entries) != 2 || entries[0].Name() != db.BackupName(base.Add(2*time.Hour)) {
+		t.Fatalf("expected the two newest backups, got %v", entries)
+	}
+}
+
+func TestEventCompaction(t *testing.T) {
+	env := newTestEnv(t)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return clock }
+	env.Engine.Events.Now = env.Engine.Now
+	old, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Old", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{ProjectID: "proj-1", RoleID: "auditor", Grants: []string{"project.read"}, ActorID: "tester"}); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	clock = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	recent, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Recent", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "events.ndjson.gz")
+	opts := engine.CompactEventsOptions{ProjectID: "proj-1", ActorID: "tester", ArchivePath: archive}
+	if _, err := env.Engine.CompactEvents(env.Ctx, opts); err == nil {
+		t.Fatalf("expected compaction without retention config to fail")
+	}
+	env.Engine.Config.Project.EventRetention = config.EventRetentionConfig{MaxAgeDays: 30}
+
+	opts.DryRun = true
+	plan, err := env.Engine.CompactEvents(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if plan.Count == 0 || plan.Before != "2024-01-31T00:00:00Z" {
+		t.Fatalf("unexpected dry run: %+v", plan)
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not write an archive")
+	}
+
+	opts.DryRun = false
+	res, err := env.Engine.CompactEvents(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if res.Count != plan.Count {
+		t.Fatalf("expected %d events compacted, got %d", plan.Count, res.Count)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	dec := json.NewDecoder(zr)
+	archived := map[string]bool{}
+	n := 0
+	for dec.More() {
+		var evt domain.Event
+		if err := dec.Decode(&evt); err != nil {
+			t.Fatalf("decode archive: %v", err)
+		}
+		archived[evt.Type+":"+evt.EntityID] = true
+		n++
+	}
+	if n != res.Count || !archived["task.created:"+old.ID] {
+		t.Fatalf("archive holds %d events %v, expected %d including old task", n, archived, res.Count)
+	}
+
+	remaining, err := env.Engine.Repo.LatestEvents(env.Ctx, 100, "proj-1", "", "", "")
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	kept := map[string]bool{}
+	for _, evt := range remaining {
+		kept[evt.Type+":"+evt.EntityID] = true
+		if evt.TS < res.Before && !strings.HasPrefix(evt.Type, "rbac.") && evt.Type != "force.used" {
+			t.Fatalf("event %s from %s survived compaction", evt.Type, evt.TS)
+		}
+	}
+	if !kept["rbac.role_created:proj-1"] || !kept["task.created:"+recent.ID] || !kept["events.compacted:proj-1"] {
+		t.Fatalf("expected exempt, recent and compaction events to remain, got %v", kept)
+	}
+
+	env.Engine.Config.Project.EventRetention = config.EventRetentionConfig{MaxRows: 1, Exempt: []string{}}
+	opts.ArchivePath = filepath.Join(t.TempDir(), "rows.ndjson.gz")
+	if _, err := env.Engine.CompactEvents(env.Ctx, opts); err != nil {
+		t.Fatalf("compact by rows: %v", err)
+	}
+	remaining, err = env.Engine.Repo.LatestEvents(env.Ctx, 100, "proj-1", "", "", "")
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0].Type != "events.compacted" {
+		t.Fatalf("expected the newest event plus the compaction record, got %+v", remaining)
+	}
+}
+
+func TestRebuildProjection(t *testing.T) {
+	env := newTestEnv(t)
+	var ids []string
+	for _, title := range []string{"One", "Two", "Three"} {
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: ids[0], Status: "ready", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	if _, err := env.Engine.BulkUpdateTasks(env.Ctx, engine.BulkTaskUpdateOptions{ProjectID: "proj-1", TaskIDs: ids[1:], Status: "canceled", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("bulk update: %v", err)
+	}
+
+	states, err := env.Engine.ListProjections(env.Ctx, "proj-1", "tester")
+	if err != nil || len(states) == 0 || states[0].Name != "task_status" || states[0].Lag != -1 {
+		t.Fatalf("expected unbuilt task_status projection, got %+v %v", states, err)
+	}
+	if _, err := env.Engine.RebuildProjection(env.Ctx, "nope", "proj-1", "tester"); err == nil {
+		t.Fatalf("expected unknown projection to fail")
+	}
+	if _, err := env.Engine.RebuildProjection(env.Ctx, "task_status", "proj-1", "intruder"); err == nil {
+		t.Fatalf("expected rebuild without projection.rebuild to fail")
+	}
+	res, err := env.Engine.RebuildProjection(env.Ctx, "task_status", "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if res.Events == 0 || res.LastEventID == 0 || len(res.Warnings) != 0 {
+		t.Fatalf("unexpected rebuild %+v", res)
+	}
+	counts, err := env.Engine.ReadProjection(env.Ctx, "task_status", "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("read projection: %v", err)
+	}
+	want := map[string]int{"ready": 1, "canceled": 2}
+	if got, _ := counts.(map[string]int); !maps.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	// Rebuilding again replaces the rows rather than adding to them.
+	if _, err := env.Engine.RebuildProjection(env.Ctx, "task_status", "proj-1", "tester"); err != nil {
+		t.Fatalf("second rebuild: %v", err)
+	}
+	if counts, _ = env.Engine.ReadProjection(env.Ctx, "task_status", "proj-1", "tester"); !maps.Equal(counts.(map[string]int), want) {
+		t.Fatalf("expected %v after second rebuild, got %v", want, counts)
+	}
+}
+
+func TestEventChain(t *testing.T) {
+	env := newTestEnv(t)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return clock }
+	env.Engine.Events.Now = env.Engine.Now
+	for _, title := range []string{"One", "Two", "Three"} {
+		if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"}); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+	opts := engine.EventChainOptions{ProjectID: "proj-1", ActorID: "tester"}
+	report, err := env.Engine.VerifyEventChain(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	head, err := env.Engine.EventChainHead(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	if !report.Valid || report.Checked == 0 || report.Unhashed != 0 || report.Head != head {
+		t.Fatalf("unexpected report %+v (head %+v)", report, head)
+	}
+
+	// Compacted events leave a gap the chain accepts.
+	clock = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Recent", ActorID: "tester"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	env.Engine.Config.Project.EventRetention = config.EventRetentionConfig{MaxAgeDays: 30, Exempt: []string{}}
+	if _, err := env.Engine.CompactEvents(env.Ctx, engine.CompactEventsOptions{ProjectID: "proj-1", ActorID: "tester", ArchivePath: filepath.Join(t.TempDir(), "events.ndjson.gz")}); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	opts.Anchor = head.Hash
+	report, err = env.Engine.VerifyEventChain(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("verify after compaction: %v", err)
+	}
+	if report.Valid || report.Compacted != 1 || len(report.Breaks) != 1 || report.Breaks[0].EventID != 0 {
+		t.Fatalf("expected only the compacted anchor to be missing, got %+v", report)
+	}
+	opts.Anchor = report.Head.Hash
+
+	evts, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "", "", "")
+	if err != nil || len(evts) < 2 {
+		t.Fatalf("list events: %v %d", err, len(evts))
+	}
+	tampered := evts[1]
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `UPDATE events SET payload_json='{}' WHERE id=?`, tampered.ID); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	report, err = env.Engine.VerifyEventChain(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("verify tampered: %v", err)
+	}
+	if report.Valid || len(report.Breaks) != 1 || report.Breaks[0].EventID != tampered.ID {
+		t.Fatalf("expected event %d to break the chain, got %+v", tampered.ID, report)
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Blobs = blob.Local{Dir: t.TempDir()}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Doomed", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "tester", 60); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	att, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: task.ID, Kind: "ci.passed"}, "tester")
+	if err != nil {
+		t.Fatalf("add attestation: %v", err)
+	}
+	ev, err := env.Engine.AddEvidence(env.Ctx, engine.EvidenceOptions{ProjectID: "proj-1", AttestationID: att.ID, Name: "ci.log", Body: strings.NewReader("ok"), ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("add evidence: %v", err)
+	}
+
+	if _, err := env.Engine.DeleteProject(env.Ctx, engine.DeleteProjectOptions{ProjectID: "proj-1", ActorID: "intruder"}); err == nil {
+		t.Fatalf("expected delete without project.delete to fail")
+	}
+	archive := filepath.Join(t.TempDir(), "project.ndjson.gz")
+	res, err := env.Engine.DeleteProject(env.Ctx, engine.DeleteProjectOptions{ProjectID: "proj-1", ActorID: "tester", ArchivePath: archive})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if res.Rows["tasks"] != 1 || res.Rows["leases"] != 1 || res.Rows["attestations"] != 1 || res.Rows["events"] == 0 || res.Archive != archive || len(res.Warnings) != 0 {
+		t.Fatalf("unexpected deletion %+v", res)
+	}
+	for query, arg := range map[string]string{
+		`SELECT count(*) FROM tasks WHERE project_id=?`:                "proj-1",
+		`SELECT count(*) FROM leases WHERE task_id=?`:                  task.ID,
+		`SELECT count(*) FROM attestation_evidence WHERE project_id=?`: "proj-1",
+		`SELECT count(*) FROM project_configs WHERE project_id=?`:      "proj-1",
+		`SELECT count(*) FROM events WHERE project_id=?`:               "proj-1",
+	} {
+		var n int
+		if err := env.Engine.DB.QueryRowContext(env.Ctx, query, arg).Scan(&n); err != nil || n != 0 {
+			t.Fatalf("expected no rows left for %q, got %d %v", query, n, err)
+		}
+	}
+	if _, err := env.Engine.Blobs.Get(env.Ctx, ev.StorageKey); err == nil {
+		t.Fatalf("expected evidence file to be removed")
+	}
+	var deleted int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='project.deleted' AND project_id IS NULL AND entity_id='proj-1'`).Scan(&deleted); err != nil || deleted != 1 {
+		t.Fatalf("expected one global project.deleted event, got %d %v", deleted, err)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tables := map[string]int{}
+	dec := json.NewDecoder(zr)
+	for dec.More() {
+		var rec struct {
+			Table string         `json:"table"`
+			Row   map[string]any `json:"row"`
+		}
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("decode archive: %v", err)
+		}
+		tables[rec.Table]++
+	}
+	if !maps.Equal(tables, res.Rows) {
+		t.Fatalf("expected archive rows %v, got %v", res.Rows, tables)
+	}
+}
+
+func TestRenameAndDeleteProjectWithCustomRole(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{ProjectID: "proj-1", RoleID: "triager", Grants: []string{"task.claim"}, ActorID: "tester"}); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "triager"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	if _, err := env.Engine.RenameProject(env.Ctx, "proj-1", "proj-renamed", "tester"); err != nil {
+		t.Fatalf("rename project owning a custom role: %v", err)
+	}
+	role, err := env.Engine.InspectRole(env.Ctx, "proj-renamed", "tester", "triager")
+	if err != nil || role.ProjectID != "proj-renamed" || len(role.Members) != 1 {
+		t.Fatalf("custom role should follow the rename: %v %+v", err, role)
+	}
+
+	archive := filepath.Join(t.TempDir(), "project.ndjson.gz")
+	res, err := env.Engine.DeleteProject(env.Ctx, engine.DeleteProjectOptions{ProjectID: "proj-renamed", ActorID: "tester", ArchivePath: archive})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if res.Rows["roles"] != 1 || res.Rows["role_permissions"] != 1 {
+		t.Fatalf("expected the custom role in the deletion counts, got %v", res.Rows)
+	}
+	var left int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM roles WHERE id='triager'`).Scan(&left); err != nil || left != 0 {
+		t.Fatalf("expected the custom role to be deleted, got %d %v", left, err)
+	}
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil || !strings.Contains(string(data), `"table":"roles"`) || !strings.Contains(string(data), `"table":"role_permissions"`) {
+		t.Fatalf("expected the custom role in the archive: %v", err)
+	}
+}
+
+func TestIDStrategies(t *testing.T) {
+	env := newTestEnv(t)
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Default", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := uuid.Parse(task.ID); err != nil {
+		t.Fatalf("expected a uuid by default, got %s", task.ID)
+	}
+
+	env.Engine.Config.Project.IDs = config.IDsConfig{Strategy: "sequence", Prefix: "WL"}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", ID: "WL-2", Title: "By hand", ActorID: "tester"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	var ids []string
+	for _, title := range []string{"One", "Two"} {
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	if !slices.Equal(ids, []string{"WL-1", "WL-3"}) {
+		t.Fatalf("expected WL-1 and WL-3 skipping the id taken by hand, got %v", ids)
+	}
+	it, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ProjectID: "proj-1", Goal: "g"}, "tester")
+	if err != nil || it.ID != "WL-IT-1" {
+		t.Fatalf("expected iteration WL-IT-1, got %q %v", it.ID, err)
+	}
+	d, err := env.Engine.CreateDecision(env.Ctx, domain.Decision{ProjectID: "proj-1", Title: "t", Decision: "d", DeciderID: "tester"}, "tester")
+	if err != nil || d.ID != "WL-DEC-1" {
+		t.Fatalf("expected decision WL-DEC-1, got %q %v", d.ID, err)
+	}
+
+	env.Engine.Config.Project.IDs = config.IDsConfig{Strategy: "ulid"}
+	first, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Sortable", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if len(first.ID) != 26 || strings.Trim(first.ID, "0123456789ABCDEFGHJKMNPQRSTVWXYZ") != "" || first.ID[0] > '7' {
+		t.Fatalf("expected a ulid, got %s", first.ID)
+	}
+
+	env.Engine.Config.Project.IDs = config.IDsConfig{Strategy: "serial"}
+	if err := env.Engine.Config.Validate(); err == nil {
+		t.Fatalf("expected unknown id strategy to be rejected")
+	}
+}
+
+func TestReadOnlyEngine(t *testing.T) {
+	env := newTestEnv(t)
+	ro := env.Engine
+	ro.ReadOnly = true
+	before, err := env.Engine.Repo.LatestEventID(env.Ctx, "proj-1")
+	if err != nil {
+		t.Fatalf("latest event: %v", err)
+	}
+
+	var roErr engine.ReadOnlyError
+	if _, err := ro.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Nope", ActorID: "tester"}); !errors.As(err, &roErr) || roErr.Permission != "task.create" {
+		t.Fatalf("expected read-only error for task.create, got %v", err)
+	}
+	if _, err := ro.CreateOrg(env.Ctx, "org-2", "", "tester"); !errors.As(err, &roErr) {
+		t.Fatalf("expected read-only error for org creation, got %v", err)
+	}
+	if _, err := ro.ProjectConfigVersions(env.Ctx, "proj-1", "tester"); err != nil {
+		t.Fatalf("expected reads to work in read-only mode: %v", err)
+	}
+	// Unknown actors are neither registered nor audited as denied.
+	if _, err := ro.ProjectConfigVersions(env.Ctx, "proj-1", "stranger"); err == nil {
+		t.Fatalf("expected stranger to be forbidden")
+	}
+	after, err := env.Engine.Repo.LatestEventID(env.Ctx, "proj-1")
+	if err != nil {
+		t.Fatalf("latest event: %v", err)
+	}
+	if after != before {
+		t.Fatalf("read-only engine wrote events: %d -> %d", before, after)
+	}
+	if _, err := env.Engine.Repo.ActorStatus(env.Ctx, "stranger"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected stranger not to be registered, got %v", err)
+	}
+}
+
+func TestAddAttestationsBatch(t *testing.T) {
+	env := newTestEnv(t)
+	tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "batch", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := env.Engine.AddAttestations(env.Ctx, "proj-1", []domain.Attestation{
+		{EntityKind: "task", EntityID: tk.ID, Kind: "ci.passed"},
+		{EntityKind: "task", EntityID: tk.ID, Kind: "unknown.kind"},
+		{EntityKind: "task", Kind: "ci.passed"},
+		{EntityKind: "task", EntityID: tk.ID, Kind: "review.approved"},
+	}, "tester")
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[0].Err != nil || results[3].Err != nil {
+		t.Fatalf("expected valid items to succeed: %v, %v", results[0].Err, results[3].Err)
+	}
+	var forbidden auth.ForbiddenAttestationError
+	if !errors.As(results[1].Err, &forbidden) {
+		t.Fatalf("expected authority error, got %v", results[1].Err)
+	}
+	if results[2].Err == nil {
+		t.Fatalf("expected missing entity id to fail")
+	}
+	atts, err := env.Engine.Repo.ListAttestations(env.Ctx, repo.AttestationFilters{ProjectID: "proj-1", EntityID: tk.ID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atts) != 2 {
+		t.Fatalf("expected 2 stored attestations, got %d", len(atts))
+	}
+	for _, att := range atts {
+		if att.ActorID != "tester" {
+			t.Fatalf("expected actor recorded, got %q", att.ActorID)
+		}
+	}
+
+	tooMany := make([]domain.Attestation, engine.MaxAttestationBatch+1)
+	if _, err := env.Engine.AddAttestations(env.Ctx, "proj-1", tooMany, "tester"); err == nil {
+		t.Fatalf("expected oversized batch to be rejected")
+	}
+}
+
+func TestAttestationExpiry(t *testing.T) {
+	env := newTestEnv(t)
+	for i := range env.Engine.Config.Project.Attestations {
+		if env.Engine.Config.Project.Attestations[i].ID == "security.ok" {
+			env.Engine.Config.Project.Attestations[i].ValidDays = 30
+		}
+	}
+	env.Engine.Events.Now = env.Engine.Now
+	tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{
+		ProjectID:      "proj-1",
+		Title:          "expiry",
+		ActorID:        "tester",
+		RequiredKinds:  []string{"security.ok"},
+		PolicyOverride: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{
+		ProjectID: "proj-1", EntityKind: "task", EntityID: tk.ID, Kind: "security.ok", TS: "2023-11-15T00:00:00Z",
+	}, "tester")
+	if err != nil {
+		t.Fatalf("add stale attestation: %v", err)
+	}
+	if !env.Engine.AttestationExpired(stale, env.Engine.Now()) {
+		t.Fatalf("expected attestation older than 30 days to be expired")
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, tk.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	_, _ = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "in_progress", ActorID: "tester", Force: true})
+	_, _ = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "review", ActorID: "tester", Force: true})
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "done", ActorID: "tester"}); err == nil || strings.Contains(err.Error(), "lease") {
+		t.Fatalf("expected expired attestation not to satisfy policy, got %v", err)
+	}
+
+	expired, err := env.Engine.ExpireAttestations(env.Ctx, "proj-1", "system")
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != stale.ID || expired[0].ExpiredAt == nil {
+		t.Fatalf("unexpected sweep result %+v", expired)
+	}
+	if again, err := env.Engine.ExpireAttestations(env.Ctx, "proj-1", "system"); err != nil || len(again) != 0 {
+		t.Fatalf("expected second sweep to be a no-op, got %d, %v", len(again), err)
+	}
+	var count int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='attestation.expired' AND entity_id=?`, tk.ID).Scan(&count); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one attestation.expired event, got %d", count)
+	}
+
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{
+		ProjectID: "proj-1", EntityKind: "task", EntityID: tk.ID, Kind: "security.ok",
+	}, "tester"); err != nil {
+		t.Fatalf("add fresh attestation: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "done", ActorID: "tester"}); err != nil {
+		t.Fatalf("expected fresh attestation to satisfy policy: %v", err)
+	}
+}
+
+func TestBulkUpdateTasks(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g", Status: "pending"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	var ids []string
+	for _, title := range []string{"a", "b"} {
+		tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, tk.ID)
+	}
+	iteration, assignee := "iter-1", "alice"
+	results, err := env.Engine.BulkUpdateTasks(env.Ctx, engine.BulkTaskUpdateOptions{
+		ProjectID:         "proj-1",
+		TaskIDs:           append([]string{"missing"}, ids...),
+		SetIteration:      &iteration,
+		IterationProvided: true,
+		Assign:            &assignee,
+		AssignProvided:    true,
+		ActorID:           "tester",
+	})
+	if err != nil {
+		t.Fatalf("bulk update: %v", err)
+	}
+	if !errors.Is(results[0].Err, repo.ErrNotFound) {
+		t.Fatalf("expected missing task to fail with not found, got %v", results[0].Err)
+	}
+	for _, res := range results[1:] {
+		if res.Err != nil || res.Task.IterationID == nil || *res.Task.IterationID != "iter-1" || res.Task.AssigneeID == nil || *res.Task.AssigneeID != "alice" {
+			t.Fatalf("unexpected result %+v", res)
+		}
+	}
+
+	// Status changes keep the single-update checks: only the leased task moves.
+	if _, err := env.Engine.ClaimLease(env.Ctx, ids[0], "tester", 300); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	results, err = env.Engine.BulkUpdateTasks(env.Ctx, engine.BulkTaskUpdateOptions{ProjectID: "proj-1", TaskIDs: ids, Status: "in_progress", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("bulk status: %v", err)
+	}
+	if results[0].Err != nil || results[0].Task.Status != "in_progress" {
+		t.Fatalf("expected leased task to move, got %+v", results[0])
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "lease") {
+		t.Fatalf("expected lease error, got %v", results[1].Err)
+	}
+
+	// Atomic updates apply nothing when one task fails.
+	if _, err := env.Engine.BulkUpdateTasks(env.Ctx, engine.BulkTaskUpdateOptions{ProjectID: "proj-1", TaskIDs: ids, Status: "review", ActorID: "tester", Atomic: true}); err == nil {
+		t.Fatalf("expected atomic bulk update to fail")
+	}
+	first, err := env.Engine.Repo.GetTask(env.Ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != "in_progress" {
+		t.Fatalf("expected atomic failure to leave task unchanged, got %s", first.Status)
+	}
+
+	var count int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='task.bulk_updated' AND project_id='proj-1'`).Scan(&count); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected one bulk event per committed bulk update, got %d", count)
+	}
+}
+
+func TestListTasksFilters(t *testing.T) {
+	env := newTestEnv(t)
+	create := func(title, taskType string) domain.Task {
+		tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, Type: taskType, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return tk
+	}
+	login := create("Login page", "feature")
+	create("Fix 100% CPU", "bug")
+	docs := create("Write docs", "chore")
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: login.ID, Kind: "ci.passed"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	titles := func(f repo.TaskFilters) []string {
+		t.Helper()
+		f.ProjectID = "proj-1"
+		tasks, err := env.Engine.Repo.ListTasks(env.Ctx, f)
+		if err != nil {
+			t.Fatalf("list %+v: %v", f, err)
+		}
+		var out []string
+		for _, tk := range tasks {
+			out = append(out, tk.Title)
+		}
+		sort.Strings(out)
+		return out
+	}
+	check := func(name string, got []string, want ...string) {
+		t.Helper()
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	check("types", titles(repo.TaskFilters{Types: []string{"feature", "chore"}}), "Login page", "Write docs")
+	check("query", titles(repo.TaskFilters{Query: "LOGIN"}), "Login page")
+	check("query escapes wildcards", titles(repo.TaskFilters{Query: "0%"}), "Fix 100% CPU")
+	check("missing attestation", titles(repo.TaskFilters{MissingAttestations: []string{"ci.passed"}}), "Fix 100% CPU", "Write docs")
+	check("created range", titles(repo.TaskFilters{CreatedAfter: "2024-01-01T00:00:00Z", CreatedBefore: "2024-01-02T00:00:00+01:00"}), "Fix 100% CPU", "Login page", "Write docs")
+	check("created before start", titles(repo.TaskFilters{CreatedBefore: "2024-01-01T00:00:00Z"}))
+
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: docs.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	check("statuses", titles(repo.TaskFilters{Statuses: []string{"done", "review"}}), "Write docs")
+	check("completed range", titles(repo.TaskFilters{CompletedAfter: "2023-12-31T00:00:00Z"}), "Write docs")
+	if _, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1", CompletedBefore: "yesterday"}); err == nil {
+		t.Fatalf("expected invalid bound to fail")
+	}
+}
+
+func TestListTasksSortPagination(t *testing.T) {
+	env := newTestEnv(t)
+	prio := func(p int) *int { return &p }
+	for _, tc := range []struct {
+		title    string
+		priority *int
+	}{{"c", prio(2)}, {"a", nil}, {"d", prio(1)}, {"b", prio(2)}, {"e", nil}} {
+		if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: tc.title, Type: "chore", Priority: tc.priority, ActorID: "tester"}); err != nil {
+			t.Fatalf("create %s: %v", tc.title, err)
+		}
+	}
+	pages := func(sortValue string) []string {
+		t.Helper()
+		sortBy, err := repo.ParseTaskSort(sortValue)
+		if err != nil {
+			t.Fatalf("parse %s: %v", sortValue, err)
+		}
+		f := repo.TaskFilters{ProjectID: "proj-1", Sort: sortBy, Limit: 2}
+		var titles []string
+		for {
+			tasks, err := env.Engine.Repo.ListTasks(env.Ctx, f)
+			if err != nil {
+				t.Fatalf("list %s: %v", sortValue, err)
+			}
+			for _, tk := range tasks {
+				titles = append(titles, tk.Title)
+			}
+			if len(tasks) < f.Limit {
+				return titles
+			}
+			last := tasks[len(tasks)-1]
+			f.CursorValue, f.CursorID = sortBy.Key(last), last.ID
+		}
+	}
+	ids := map[string]string{}
+	all, _ := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1"})
+	for _, tk := range all {
+		ids[tk.Title] = tk.ID
+	}
+	// Ties are broken by id in the same direction as the sort.
+	tie := func(x, y string, desc bool) []string {
+		if (ids[x] < ids[y]) == desc {
+			return []string{y, x}
+		}
+		return []string{x, y}
+	}
+	check := func(sortValue string, want []string) {
+		t.Helper()
+		if got := pages(sortValue); strings.Join(got, "") != strings.Join(want, "") {
+			t.Fatalf("%s: got %v, want %v", sortValue, got, want)
+		}
+	}
+	check("title", []string{"a", "b", "c", "d", "e"})
+	check("title:desc", []string{"e", "d", "c", "b", "a"})
+	check("priority", append(append([]string{"d"}, tie("b", "c", false)...), tie("a", "e", false)...))
+	check("priority:desc", append(append(tie("a", "e", true), tie("b", "c", true)...), "d"))
+
+	for _, bad := range []string{"estimate", "title:up"} {
+		if _, err := repo.ParseTaskSort(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	env := newTestEnv(t)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	env.Engine.Events.Now = env.Engine.Now
+	tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "history", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "other", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, tk.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	outcomes := `{"summary":"done"}`
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, SetWorkOutcomes: &outcomes, WorkOutcomesSet: true, ActorID: "tester"}); err != nil {
+		t.Fatalf("outcomes: %v", err)
+	}
+	assignee := "tester"
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "in_progress", Assign: &assignee, AssignProvided: true, ActorID: "tester"}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	att, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: tk.ID, Kind: "ci.passed"}, "tester")
+	if err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	if err := env.Engine.ReleaseLease(env.Ctx, tk.ID, "tester"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := env.Engine.BulkUpdateTasks(env.Ctx, engine.BulkTaskUpdateOptions{ProjectID: "proj-1", TaskIDs: []string{other.ID}, Status: "ready", ActorID: "tester"}); err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+	// The attestation outlives its event once the log is compacted.
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `DELETE FROM events WHERE type='attestation.added' AND entity_id=?`, tk.ID); err != nil {
+		t.Fatalf("drop event: %v", err)
+	}
+
+	history, err := env.Engine.TaskHistory(env.Ctx, tk.ID, "tester")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var categories []string
+	for _, h := range history {
+		categories = append(categories, h.Category)
+	}
+	want := "policy,created,lease,outcomes,status,attestation,lease"
+	if got := strings.Join(categories, ","); got != want {
+		t.Fatalf("categories: got %s, want %s (%+v)", got, want, history)
+	}
+	if s := history[4].Summary; !strings.Contains(s, "-> in_progress") || !strings.Contains(s, "assigned to tester") {
+		t.Fatalf("unexpected status summary %q", s)
+	}
+	if h := history[5]; h.EventID != 0 || h.Payload["attestation_id"] != att.ID {
+		t.Fatalf("expected attestation from table, got %+v", h)
+	}
+	if _, err := env.Engine.TaskHistory(env.Ctx, "missing", "tester"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestRevertTask(t *testing.T) {
+	env := newTestEnv(t)
+	tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "revert", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unavailable engine.RevertUnavailableError
+	if _, err := env.Engine.RevertTask(env.Ctx, tk.ID, "tester"); !errors.As(err, &unavailable) {
+		t.Fatalf("expected nothing to revert, got %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, tk.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, Status: "in_progress", ActorID: "tester"}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	done, err := env.Engine.TaskDone(env.Ctx, tk.ID, `{"summary":"shipped"}`, "tester", true)
+	if err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if done.CompletedAt == nil {
+		t.Fatalf("expected completed_at on done task")
+	}
+
+	reverted, err := env.Engine.RevertTask(env.Ctx, tk.ID, "tester")
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if reverted.Status != "in_progress" || reverted.CompletedAt != nil {
+		t.Fatalf("expected in_progress without completed_at, got %s %v", reverted.Status, reverted.CompletedAt)
+	}
+	var payload string
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT payload_json FROM events WHERE type='task.reverted' AND entity_id=?`, tk.ID).Scan(&payload); err != nil {
+		t.Fatalf("reverted event: %v", err)
+	}
+	if !strings.Contains(payload, `"to_status":"in_progress"`) {
+		t.Fatalf("unexpected payload %s", payload)
+	}
+	// Reverting again undoes the revert.
+	if again, err := env.Engine.RevertTask(env.Ctx, tk.ID, "tester"); err != nil || again.Status != "done" || again.CompletedAt == nil {
+		t.Fatalf("expected second revert to restore done, got %+v, %v", again, err)
+	}
+
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `DELETE FROM role_permissions WHERE permission_id='task.revert'`); err != nil {
+		t.Fatal(err)
+	}
+	var forbidden auth.ForbiddenError
+	if _, err := env.Engine.RevertTask(env.Ctx, tk.ID, "tester"); !errors.As(err, &forbidden) {
+		t.Fatalf("expected forbidden without task.revert, got %v", err)
+	}
+}
+
+func TestWorkOutcomesSchemaAndLimit(t *testing.T) {
+	env := newTestEnv(t)
+	cfg := env.Engine.Config
+	tt := cfg.Project.TaskTypes["chore"]
+	tt.WorkOutcomesSchema = map[string]any{
+		"type":     "object",
+		"required": []any{"summary"},
+		"properties": map[string]any{
+			"summary": map[string]any{"type": "string"},
+		},
+	}
+	cfg.Project.TaskTypes["chore"] = tt
+	cfg.Project.WorkOutcomes.MaxBytes = 64
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var outcomesErr engine.WorkOutcomesError
+	bad := `{"summary":42}`
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "schema", Type: "chore", ActorID: "tester", WorkOutcomesJSON: &bad}); !errors.As(err, &outcomesErr) || len(outcomesErr.Violations) == 0 {
+		t.Fatalf("expected schema violation on create, got %v", err)
+	}
+	tk, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "schema", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create without outcomes: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, tk.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	large := `{"summary":"` + strings.Repeat("x", 80) + `"}`
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: tk.ID, SetWorkOutcomes: &large, WorkOutcomesSet: true, ActorID: "tester"}); !errors.As(err, &outcomesErr) || outcomesErr.MaxBytes != 64 || outcomesErr.Size != len(large) {
+		t.Fatalf("expected size limit on update, got %v", err)
+	}
+	if _, err := env.Engine.TaskDone(env.Ctx, tk.ID, `{"notes":"no summary"}`, "tester", true); !errors.As(err, &outcomesErr) {
+		t.Fatalf("expected schema violation at done, got %v", err)
+	}
+	if _, err := env.Engine.TaskDone(env.Ctx, tk.ID, `{"summary":"shipped"}`, "tester", true); err != nil {
+		t.Fatalf("done with valid outcomes: %v", err)
+	}
+
+	tt.WorkOutcomesSchema = map[string]any{"type": "nonsense"}
+	cfg.Project.TaskTypes["chore"] = tt
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "task type chore") {
+		t.Fatalf("expected invalid schema to be rejected, got %v", err)
+	}
+}
+
+func TestUpdateWorkOutcomesLeaveLeasesAlone(t *testing.T) {
+	env := newTestEnv(t)
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "outcomes", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	setKey := func(key string, value any) func(map[string]any) error {
+		return func(wo map[string]any) error {
+			wo[key] = value
+			return nil
+		}
+	}
+
+	// No lease is needed, and none is left behind.
+	updated, err := env.Engine.UpdateWorkOutcomes(env.Ctx, task.ID, "tester", setKey("summary", "draft"))
+	if err != nil {
+		t.Fatalf("update work outcomes: %v", err)
+	}
+	if updated.WorkOutcomesJSON == nil || *updated.WorkOutcomesJSON != `{"summary":"draft"}` {
+		t.Fatalf("unexpected work outcomes %v", updated.WorkOutcomesJSON)
+	}
+	if _, err := env.Engine.Repo.GetLease(env.Ctx, task.ID); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected no lease, got %v", err)
+	}
+
+	// Another actor's active lease blocks the update and is kept as is.
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "other", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	lease, err := env.Engine.ClaimLease(env.Ctx, task.ID, "other", 300)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateWorkOutcomes(env.Ctx, task.ID, "tester", setKey("summary", "mine")); err == nil || !strings.Contains(err.Error(), "lease") {
+		t.Fatalf("expected lease conflict, got %v", err)
+	}
+	updated, err = env.Engine.UpdateWorkOutcomes(env.Ctx, task.ID, "other", setKey("tests", []any{"unit"}))
+	if err != nil {
+		t.Fatalf("lease holder update: %v", err)
+	}
+	if *updated.WorkOutcomesJSON != `{"summary":"draft","tests":["unit"]}` {
+		t.Fatalf("unexpected work outcomes %s", *updated.WorkOutcomesJSON)
+	}
+	got, err := env.Engine.Repo.GetLease(env.Ctx, task.ID)
+	if err != nil || got.OwnerID != "other" || got.ExpiresAt != lease.ExpiresAt {
+		t.Fatalf("expected lease untouched, got %+v %v", got, err)
+	}
+
+	// Once that lease expires the update goes through without taking it over.
+	env.Engine.Now = func() time.Time { return time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC) }
+	if _, err := env.Engine.UpdateWorkOutcomes(env.Ctx, task.ID, "tester", setKey("summary", "final")); err != nil {
+		t.Fatalf("update after expiry: %v", err)
+	}
+	if got, err := env.Engine.Repo.GetLease(env.Ctx, task.ID); err != nil || got.OwnerID != "other" {
+		t.Fatalf("expected expired lease left in place, got %+v %v", got, err)
+	}
+}
+
+func TestSuggestAssignees(t *testing.T) {
+	env := newTestEnv(t)
+	for actor, role := range map[string]string{"dana": "dev", "erin": "dev", "olga": "observer"} {
+		if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", actor, role); err != nil {
+			t.Fatalf("grant %s: %v", actor, err)
+		}
+	}
+	busy, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "busy", ActorID: "tester", AssigneeID: "dana"})
+	if err != nil {
+		t.Fatalf("create busy task: %v", err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{
+		ProjectID:      "proj-1",
+		Title:          "pick me",
+		ActorID:        "tester",
+		RequiredKinds:  []string{"security.ok"},
+		PolicyOverride: true,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	ids := func(items []domain.AssigneeSuggestion) []string {
+		var out []string
+		for _, s := range items {
+			out = append(out, s.ActorID)
+		}
+		return out
+	}
+
+	// Observers cannot claim; the busy dev ranks last and the active owner
+	// wins the tie with the idle dev.
+	got, err := env.Engine.SuggestAssignees(env.Ctx, task.ID, "tester")
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	if strings.Join(ids(got), ",") != "tester,erin,dana" {
+		t.Fatalf("unexpected ranking %v", ids(got))
+	}
+	if got[2].OpenTasks != 1 || got[1].OpenTasks != 0 || len(got[1].CanAttest) != 0 || got[0].LastActiveAt == "" {
+		t.Fatalf("unexpected suggestions %+v", got)
+	}
+
+	// Finished tasks no longer count as load, and suspended actors drop out.
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: busy.ID, ActorID: "tester", Status: "canceled", Force: true}); err != nil {
+		t.Fatalf("cancel busy task: %v", err)
+	}
+	if _, err := env.Engine.DeactivateActor(env.Ctx, "proj-1", "tester", "erin", "left"); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	got, err = env.Engine.SuggestAssignees(env.Ctx, task.ID, "tester")
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	if strings.Join(ids(got), ",") != "tester,dana" {
+		t.Fatalf("unexpected ranking %v", ids(got))
+	}
+
+	assigned, err := env.Engine.AutoAssignTask(env.Ctx, task.ID, "tester")
+	if err != nil {
+		t.Fatalf("auto assign: %v", err)
+	}
+	if assigned.AssigneeID == nil || *assigned.AssigneeID != "tester" {
+		t.Fatalf("expected tester assigned, got %v", assigned.AssigneeID)
+	}
+}
+
+func TestClaimNextTask(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "other", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	create := func(title, taskType string, priority *int) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: title, Type: taskType, Priority: priority, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	one, two := 1, 2
+	first := create("first", "chore", &one)
+	fix := create("fix", "bug", nil)
+	second := create("second", "chore", &two)
+	claim := func(actor string, types ...string) (domain.Task, error) {
+		task, lease, err := env.Engine.ClaimNextTask(env.Ctx, engine.ClaimNextOptions{ProjectID: "proj-1", Types: types, IncludeUnassigned: true, ActorID: actor, LeaseSeconds: 300})
+		if err == nil && (lease.TaskID != task.ID || lease.OwnerID != actor) {
+			t.Fatalf("lease %+v does not match claimed task %s", lease, task.ID)
+		}
+		return task, err
+	}
+
+	// Nothing to claim without a running iteration.
+	if _, err := claim("tester"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected not found without running iteration, got %v", err)
+	}
+	if _, err := env.Engine.SetIterationStatus(env.Ctx, "iter-1", "running", "tester", true); err != nil {
+		t.Fatalf("start iteration: %v", err)
+	}
+
+	// The second agent skips the task the first one holds.
+	got, err := claim("other")
+	if err != nil || got.ID != first.ID {
+		t.Fatalf("expected first task, got %s %v", got.ID, err)
+	}
+	got, err = claim("tester")
+	if err != nil || got.ID != second.ID {
+		t.Fatalf("expected second task, got %s %v", got.ID, err)
+	}
+	got, err = claim("tester", "bug")
+	if err != nil || got.ID != fix.ID {
+		t.Fatalf("expected bug task, got %s %v", got.ID, err)
+	}
+	if _, err := claim("other", "bug"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected nothing left for other, got %v", err)
+	}
+	lease, err := env.Engine.Repo.GetLease(env.Ctx, fix.ID)
+	if err != nil || lease.OwnerID != "tester" {
+		t.Fatalf("expected tester to keep the bug lease, got %+v %v", lease, err)
+	}
+}
+
+func TestTaskComponents(t *testing.T) {
+	env := newTestEnv(t)
+	cfg := env.Engine.Config
+	cfg.Project.Components = map[string]config.ComponentConfig{
+		"api": {
+			Description: "HTTP API",
+			Policies: map[string]map[string]config.PolicyRule{
+				"bug": {"done": {All: []string{"ci.passed"}}},
+			},
+		},
+		"web": {},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	apiBug, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "api bug", Type: "bug", Component: "api", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create api bug: %v", err)
+	}
+	if apiBug.Component != "api" || *apiBug.RequiredAttestationsJSON != `["ci.passed"]` {
+		t.Fatalf("expected component policy override, got %s %v", apiBug.Component, *apiBug.RequiredAttestationsJSON)
+	}
+	webBug, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "web bug", Type: "bug", Component: "web", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create web bug: %v", err)
+	}
+	if *webBug.RequiredAttestationsJSON == `["ci.passed"]` {
+		t.Fatalf("expected task type policy without override, got %s", *webBug.RequiredAttestationsJSON)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "lost", Type: "bug", Component: "mobile", ActorID: "tester"}); err == nil || !strings.Contains(err.Error(), "unknown component mobile") {
+		t.Fatalf("expected unknown component error, got %v", err)
+	}
+
+	api := "api"
+	moved, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: webBug.ID, SetComponent: &api, ActorID: "tester"})
+	if err != nil || moved.Component != "api" {
+		t.Fatalf("move component: %+v %v", moved, err)
+	}
+	tasks, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1", Component: "api"})
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("expected 2 api tasks, got %d %v", len(tasks), err)
+	}
+	none := ""
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: webBug.ID, SetComponent: &none, ActorID: "tester"}); err != nil {
+		t.Fatalf("clear component: %v", err)
+	}
+	counts, err := env.Engine.Repo.CountTasksByComponent(env.Ctx, "proj-1")
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if len(counts) != 1 || counts["api"]["planned"] != 1 {
+		t.Fatalf("unexpected component counts %v", counts)
+	}
+
+	cfg.Project.Components["api"].Policies["spike"] = map[string]config.PolicyRule{"done": {All: []string{"ci.passed"}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown task type spike") {
+		t.Fatalf("expected unknown task type in component override, got %v", err)
+	}
+}
+
+func TestMilestoneProgress(t *testing.T) {
+	env := newTestEnv(t)
+	for _, id := range []string{"iter-1", "iter-2"} {
+		if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: id, ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+			t.Fatalf("create iteration: %v", err)
+		}
+	}
+	est := func(v float64) *float64 { return &v }
+	a, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "a", ActorID: "tester", Estimate: est(3)})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-2", Title: "b", ActorID: "tester", Estimate: est(2)}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	loose, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "loose", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "unlinked", ActorID: "tester"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	if _, err := env.Engine.CreateMilestone(env.Ctx, engine.MilestoneOptions{ID: "m-1", ProjectID: "proj-1", Goal: "beta", TargetDate: "01/02/2024", ActorID: "tester"}); err == nil {
+		t.Fatalf("expected invalid target date error")
+	}
+	if _, err := env.Engine.CreateMilestone(env.Ctx, engine.MilestoneOptions{ID: "m-1", ProjectID: "proj-1", Goal: "beta", TargetDate: "2024-03-01", IterationIDs: []string{"iter-9"}, ActorID: "tester"}); err == nil || !strings.Contains(err.Error(), "unknown iteration") {
+		t.Fatalf("expected unknown iteration error, got %v", err)
+	}
+	m, err := env.Engine.CreateMilestone(env.Ctx, engine.MilestoneOptions{ID: "m-1", ProjectID: "proj-1", Goal: "beta", TargetDate: "2024-01-11", IterationIDs: []string{"iter-1"}, ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create milestone: %v", err)
+	}
+	if len(m.IterationIDs) != 1 || len(m.TaskIDs) != 0 {
+		t.Fatalf("unexpected links %+v", m)
+	}
+	m, err = env.Engine.LinkMilestone(env.Ctx, "m-1", []string{"iter-2"}, []string{loose.ID}, "tester")
+	if err != nil || len(m.IterationIDs) != 2 || len(m.TaskIDs) != 1 {
+		t.Fatalf("link milestone: %v %+v", err, m)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: a.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+
+	p, err := env.Engine.MilestoneStatus(env.Ctx, "m-1", "tester")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if p.TotalTasks != 3 || p.DoneTasks != 1 || p.PlannedPoints != 5 || p.CompletedPoints != 3 || p.Iterations != 2 {
+		t.Fatalf("progress: %+v", p)
+	}
+	if p.Percent != 33.3 || p.DaysLeft != 10 || p.Overdue {
+		t.Fatalf("progress: %+v", p)
+	}
+	env.Engine.Now = func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) }
+	if p, err = env.Engine.MilestoneStatus(env.Ctx, "m-1", "tester"); err != nil || !p.Overdue || p.DaysLeft != -4 {
+		t.Fatalf("expected overdue: %v %+v", err, p)
+	}
+	items, err := env.Engine.ListMilestones(env.Ctx, "proj-1", "tester")
+	if err != nil || len(items) != 1 || items[0].ID != "m-1" {
+		t.Fatalf("list: %v %+v", err, items)
+	}
+}
+
+func TestCalendarEntries(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	day := func(d int) { current = time.Date(2024, 1, d, 9, 0, 0, 0, time.UTC) }
+	for _, id := range []string{"iter-1", "iter-2", "iter-3"} {
+		if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: id, ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+			t.Fatalf("create iteration: %v", err)
+		}
+	}
+	setStatus := func(id, status string) {
+		t.Helper()
+		if _, err := env.Engine.SetIterationStatus(env.Ctx, id, status, "tester", true); err != nil {
+			t.Fatalf("set %s %s: %v", id, status, err)
+		}
+	}
+	day(2)
+	setStatus("iter-1", "running")
+	day(12)
+	setStatus("iter-1", "delivered")
+	setStatus("iter-2", "running")
+	if _, err := env.Engine.CreateMilestone(env.Ctx, engine.MilestoneOptions{ID: "m-1", ProjectID: "proj-1", Goal: "beta", TargetDate: "2024-03-01", ActorID: "tester"}); err != nil {
+		t.Fatalf("create milestone: %v", err)
+	}
+	day(20)
+
+	entries, err := env.Engine.Calendar(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("calendar: %v", err)
+	}
+	got := map[string]domain.CalendarEntry{}
+	for _, en := range entries {
+		got[en.EntityID] = en
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 2 iterations and 1 milestone, got %+v", entries)
+	}
+	if en := got["iter-1"]; en.Start != "2024-01-02" || en.End != "2024-01-12" || en.Ongoing {
+		t.Fatalf("iter-1: %+v", en)
+	}
+	if en := got["iter-2"]; en.Start != "2024-01-12" || en.End != "2024-01-20" || !en.Ongoing {
+		t.Fatalf("iter-2: %+v", en)
+	}
+	if en := got["m-1"]; en.Kind != "milestone" || en.Start != "2024-03-01" || en.End != "2024-03-01" {
+		t.Fatalf("m-1: %+v", en)
+	}
+}
+
+func TestStatusReport(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	for _, id := range []string{"iter-1", "iter-2", "iter-3"} {
+		if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: id, ProjectID: "proj-1", Goal: "goal " + id}, "tester"); err != nil {
+			t.Fatalf("create iteration: %v", err)
+		}
+	}
+	create := func(title, iteration string, deps ...string) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: iteration, Title: title, ActorID: "tester", DependsOn: deps})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	done := func(id string) {
+		t.Helper()
+		if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: id, Status: "done", ActorID: "tester", Force: true}); err != nil {
+			t.Fatalf("done %s: %v", id, err)
+		}
+	}
+	old := create("old", "iter-2")
+	shipped := create("shipped", "iter-1")
+	pending := create("pending", "iter-1")
+	blocked := create("blocked", "", pending.ID)
+	done(old.ID)
+	if _, err := env.Engine.SetIterationStatus(env.Ctx, "iter-2", "validated", "tester", true); err != nil {
+		t.Fatalf("validate iter-2: %v", err)
+	}
+
+	current = time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	if _, err := env.Engine.SetIterationStatus(env.Ctx, "iter-1", "running", "tester", true); err != nil {
+		t.Fatalf("start iter-1: %v", err)
+	}
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: shipped.ID, Kind: "ci.passed"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	done(shipped.ID)
+
+	current = time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	r, err := env.Engine.StatusReport(env.Ctx, "proj-1", "tester", 0)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if r.Since != "2024-01-09T09:00:00Z" || r.TaskCounts["done"] != 2 {
+		t.Fatalf("report header: %+v", r)
+	}
+	if len(r.Iterations) != 2 || r.Iterations[0].ID != "iter-1" || r.Iterations[0].TotalTasks != 2 || r.Iterations[0].DoneTasks != 1 || r.Iterations[1].ID != "iter-3" {
+		t.Fatalf("iterations: %+v", r.Iterations)
+	}
+	if len(r.DoneTasks) != 1 || r.DoneTasks[0].ID != shipped.ID || len(r.DoneTasks[0].Attestations) != 1 || r.DoneTasks[0].Attestations[0] != "ci.passed" {
+		t.Fatalf("done tasks: %+v", r.DoneTasks)
+	}
+	if len(r.Blockers) != 1 || r.Blockers[0].ID != blocked.ID || len(r.Blockers[0].BlockedBy) != 1 || r.Blockers[0].BlockedBy[0] != pending.ID {
+		t.Fatalf("blockers: %+v", r.Blockers)
+	}
+	if _, err := env.Engine.StatusReport(env.Ctx, "proj-1", "tester", 400); err == nil {
+		t.Fatalf("expected invalid days error")
+	}
+}
+
+type fakeMailer struct {
+	err  error
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestDigestSchedule(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	mailer := &fakeMailer{}
+	env.Engine.Mail = mailer
+	env.Engine.Config.Digest = config.DigestConfig{
+		Schedule:   "weekly",
+		Recipients: []string{"team@example.com"},
+		SMTP:       config.SMTPConfig{From: "workline@example.com"},
+	}
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "beta work"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	for _, m := range []engine.MilestoneOptions{
+		{ID: "m-soon", Goal: "beta", TargetDate: "2024-03-10", IterationIDs: []string{"iter-1"}},
+		{ID: "m-later", Goal: "ga", TargetDate: "2024-06-01"},
+	} {
+		m.ProjectID, m.ActorID = "proj-1", "tester"
+		if _, err := env.Engine.CreateMilestone(env.Ctx, m); err != nil {
+			t.Fatalf("create milestone: %v", err)
+		}
+	}
+	create := func(title string, deps ...string) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: title, ActorID: "tester", DependsOn: deps})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	pending := create("pending")
+	blocked := create("blocked", pending.ID)
+	shipped := create("shipped")
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: shipped.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("force done: %v", err)
+	}
+
+	sent, err := env.Engine.SendDueDigest(env.Ctx, "proj-1", "system")
+	if err != nil || !sent || len(mailer.sent) != 1 {
+		t.Fatalf("expected the first digest to go out: sent=%v err=%v mails=%d", sent, err, len(mailer.sent))
+	}
+	msg := mailer.sent[0]
+	if msg.From != "workline@example.com" || len(msg.To) != 1 || !strings.HasPrefix(msg.Subject, "[proj-1] Digest 2024-02-26 to 2024-03-04") {
+		t.Fatalf("unexpected message header: %+v", msg)
+	}
+	for _, want := range []string{"Completed (1)", "shipped", "Newly blocked (1)", "blocked (" + blocked.ID, "(forced)", "2024-03-10: beta (m-soon)", "iter-1 pending, 1/3 tasks done"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Fatalf("digest body missing %q:\n%s", want, msg.Body)
+		}
+	}
+	if strings.Contains(msg.Body, "m-later") {
+		t.Fatalf("expected milestones past the lookahead to be left out:\n%s", msg.Body)
+	}
+	if sent, err := env.Engine.SendDueDigest(env.Ctx, "proj-1", "system"); err != nil || sent {
+		t.Fatalf("expected no second digest in the same slot: sent=%v err=%v", sent, err)
+	}
+
+	current = time.Date(2024, 3, 11, 7, 30, 0, 0, time.UTC)
+	late := create("late", pending.ID)
+	d, err := env.Engine.Digest(env.Ctx, "proj-1", "tester")
+	if err != nil {
+		t.Fatalf("preview digest: %v", err)
+	}
+	if d.Since != "2024-03-04T09:00:00Z" || len(d.DoneTasks) != 0 || len(d.Overrides) != 0 || len(d.NewlyBlocked) != 1 || d.NewlyBlocked[0].ID != late.ID {
+		t.Fatalf("expected only the newly blocked task since the last digest: %+v", d)
+	}
+	if sent, err := env.Engine.SendDueDigest(env.Ctx, "proj-1", "system"); err != nil || sent {
+		t.Fatalf("expected no digest before monday's 08:00 slot: sent=%v err=%v", sent, err)
+	}
+
+	if _, err := env.Engine.SendDigest(env.Ctx, "proj-1", "outsider"); err == nil {
+		t.Fatalf("expected digest.send to be required")
+	}
+	mailer.err = errors.New("connection refused")
+	if _, err := env.Engine.SendDigest(env.Ctx, "proj-1", "tester"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected the mail error, got %v", err)
+	}
+	mailer.err = nil
+	if _, err := env.Engine.SendDigest(env.Ctx, "proj-1", "tester"); err != nil {
+		t.Fatalf("send digest: %v", err)
+	}
+	if len(mailer.sent) != 2 || !strings.Contains(mailer.sent[1].Body, "Newly blocked (1)") {
+		t.Fatalf("expected the failed send to be retried with the same window: %+v", mailer.sent)
+	}
+}
+
+type fakePublisher struct {
+	err  error
+	sent []domain.OutboxMessage
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msgs []domain.OutboxMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msgs...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestOutboxRelay(t *testing.T) {
+	env := newTestEnv(t)
+	outboxCfg := config.OutboxConfig{Broker: "nats", Servers: []string{"nats://localhost:4222"}, Topic: "wl.{project}.{type}", Events: []string{"task.created"}}
+	env.Engine.Events.Outbox = outboxCfg.Route
+
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Publish me", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Type: "nope", Title: "Rejected", ActorID: "tester"}); err == nil {
+		t.Fatalf("expected invalid type to fail")
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "ready", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	if n, _, err := env.Engine.Repo.OutboxBacklog(env.Ctx); err != nil || n != 1 {
+		t.Fatalf("expected only the task.created event queued, got %d (%v)", n, err)
+	}
+
+	now := time.Now().UTC()
+	pub := &fakePublisher{err: errors.New("broker down")}
+	relay := outbox.Relay{Repo: env.Engine.Repo, Publisher: pub, Now: func() time.Time { return now }}
+	if _, err := relay.RunOnce(env.Ctx); err == nil {
+		t.Fatalf("expected publish failure")
+	}
+	pub.err = nil
+	if n, err := relay.RunOnce(env.Ctx); err != nil || n != 0 {
+		t.Fatalf("expected failed message to back off, published %d (%v)", n, err)
+	}
+	now = now.Add(time.Minute)
+	if n, err := relay.RunOnce(env.Ctx); err != nil || n != 1 {
+		t.Fatalf("expected retry to publish, got %d (%v)", n, err)
+	}
+	msg := pub.sent[0]
+	if msg.Topic != "wl.proj-1.task.created" || msg.Key != task.ID || msg.Attempts != 1 || msg.LastError != "broker down" {
+		t.Fatalf("unexpected outbox message: %+v", msg)
+	}
+	var body struct {
+		ID      int64          `json:"id"`
+		Type    string         `json:"type"`
+		Payload map[string]any `json:"payload"`
+	}
+	if err := json.Unmarshal([]byte(msg.Payload), &body); err != nil || body.ID != msg.EventID || body.Type != "task.created" || body.Payload == nil {
+		t.Fatalf("unexpected message body %s (%v)", msg.Payload, err)
+	}
+	if n, _, _ := env.Engine.Repo.OutboxBacklog(env.Ctx); n != 0 {
+		t.Fatalf("expected published messages to be deleted, %d left", n)
+	}
+
+	cfg := config.Default("proj-1")
+	cfg.Outbox = config.OutboxConfig{Broker: "kafka", Topic: "wl.{team}"}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "outbox.servers") || !strings.Contains(err.Error(), "{team}") {
+		t.Fatalf("expected outbox validation errors, got %v", err)
+	}
+}
+
+// fakeCluster stands in for Redis shared by replicas.
+type fakeCluster struct {
+	locks map[string]string
+	cache map[string][]byte
+}
+
+func (c *fakeCluster) Acquire(ctx context.Context, taskID, owner string, ttl time.Duration) (bool, error) {
+	if held, ok := c.locks[taskID]; ok && held != owner {
+		return false, nil
+	}
+	c.locks[taskID] = owner
+	return true, nil
+}
+
+func (c *fakeCluster) Release(ctx context.Context, taskID, owner string) error {
+	if c.locks[taskID] == owner {
+		delete(c.locks, taskID)
+	}
+	return nil
+}
+
+func (c *fakeCluster) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, ok := c.cache[key]
+	return v, ok, nil
+}
+
+func (c *fakeCluster) Set(ctx context.Context, key string, value []byte) error {
+	c.cache[key] = value
+	return nil
+}
+
+func (c *fakeCluster) Delete(ctx context.Context, keys ...string) error {
+	for _, k := range keys {
+		delete(c.cache, k)
+	}
+	return nil
+}
+
+func TestClusterLocksAndCache(t *testing.T) {
+	env := newTestEnv(t)
+	shared := &fakeCluster{locks: map[string]string{}, cache: map[string][]byte{}}
+	env.Engine.Locks, env.Engine.Cache = shared, shared
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "g"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	if _, err := env.Engine.SetIterationStatus(env.Ctx, "iter-1", "running", "tester", true); err != nil {
+		t.Fatalf("start iteration: %v", err)
+	}
+	one, two := 1, 2
+	first, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "first", Priority: &one, ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create first: %v", err)
+	}
+	second, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "second", Priority: &two, ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create second: %v", err)
+	}
+
+	// Another replica is claiming the first task and has not written its lease yet.
+	shared.locks[first.ID] = "elsewhere"
+	if _, err := env.Engine.ClaimLease(env.Ctx, first.ID, "tester", 300); err == nil || err.Error() != "lease already held" {
+		t.Fatalf("expected locked lease to be refused, got %v", err)
+	}
+	task, _, err := env.Engine.ClaimNextTask(env.Ctx, engine.ClaimNextOptions{ProjectID: "proj-1", IncludeUnassigned: true, ActorID: "tester", LeaseSeconds: 300})
+	if err != nil || task.ID != second.ID || shared.locks[second.ID] != "tester" {
+		t.Fatalf("expected claim-next to skip the locked task, got %s %v (locks %v)", task.ID, err, shared.locks)
+	}
+	if err := env.Engine.ReleaseLease(env.Ctx, second.ID, "tester"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, ok := shared.locks[second.ID]; ok {
+		t.Fatalf("expected release to drop the lock")
+	}
+
+	// Sets read inside a write's transaction may be rolled back, so only
+	// HasPermission, reading committed data, caches them; a grant
+	// invalidates them.
+	delete(shared.locks, first.ID)
+	var forbidden auth.ForbiddenError
+	if _, err := env.Engine.ClaimLease(env.Ctx, first.ID, "dana", 300); !errors.As(err, &forbidden) {
+		t.Fatalf("expected dana to be forbidden, got %v", err)
+	}
+	cachedSets := func() int {
+		n := 0
+		for key := range shared.cache {
+			if strings.HasPrefix(key, "perms:") && strings.HasSuffix(key, ":proj-1:dana") {
+				n++
+			}
+		}
+		return n
+	}
+	if n := cachedSets(); n != 0 {
+		t.Fatalf("expected a set read in a write transaction not to be cached, got %v", shared.cache)
+	}
+	if ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "dana", "task.claim"); err != nil || ok {
+		t.Fatalf("expected dana to lack task.claim, got %v %v", ok, err)
+	}
+	if n := cachedSets(); n != 1 {
+		t.Fatalf("expected dana's permissions cached, got %v", shared.cache)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, first.ID, "dana", 300); !errors.As(err, &forbidden) {
+		t.Fatalf("expected the cached set to still forbid dana, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, first.ID, "dana", 300); err != nil {
+		t.Fatalf("expected grant to take effect despite the cache: %v", err)
+	}
+}
+
+// failingRepo wraps the SQLite repository and fails task inserts.
+type failingRepo struct {
+	repo.Repository
+	err error
+}
+
+func (r failingRepo) InsertTask(ctx context.Context, tx *sql.Tx, t domain.Task) error {
+	return r.err
+}
+
+func TestEngineRepositoryDouble(t *testing.T) {
+	env := newTestEnv(t)
+	diskFull := errors.New("disk full")
+	env.Engine.Repo = failingRepo{Repository: env.Engine.Repo, err: diskFull}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Lost", ActorID: "tester"}); !errors.Is(err, diskFull) {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+	tasks, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1"})
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("expected no task after failed insert, got %d (%v)", len(tasks), err)
+	}
+	events, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "task.created", "", "")
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected the failed create to roll back its events, got %d (%v)", len(events), err)
+	}
+}
+
+func TestPolicySimulationAndCheck(t *testing.T) {
+	env := newTestEnv(t)
+	sim, err := env.Engine.SimulatePolicy(env.Ctx, "proj-1", engine.PolicySimulation{Type: "feature", Attestations: []string{"ci.passed"}}, "tester")
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if sim.Policy == "" || len(sim.Required) == 0 || !slices.Contains(sim.Present, "ci.passed") || sim.Satisfied {
+		t.Fatalf("unexpected simulation %+v", sim)
+	}
+	if _, err := env.Engine.SimulatePolicy(env.Ctx, "proj-1", engine.PolicySimulation{Type: "feature", Policy: "nope"}, "tester"); err == nil {
+		t.Fatalf("expected an unknown policy to be rejected")
+	}
+
+	dep, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "dep", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "feature", Type: "feature", DependsOn: []string{dep.ID}, ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: task.ID, Kind: "ci.passed"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	check, err := env.Engine.CheckTaskPolicy(env.Ctx, task.ID, "tester")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if check.Policy != sim.Policy || !slices.Equal(check.Required, sim.Required) || !slices.Equal(check.Missing, sim.Missing) || check.Override {
+		t.Fatalf("expected the check to match the simulation, got %+v vs %+v", check, sim)
+	}
+	if len(check.Blockers) != 1 || !strings.Contains(check.Blockers[0], dep.ID) {
+		t.Fatalf("expected the unfinished dependency as blocker, got %v", check.Blockers)
+	}
+
+	manual, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "manual", Type: "feature", PolicyOverride: true, RequiredKinds: []string{"ci.passed"}, ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check, err = env.Engine.CheckTaskPolicy(env.Ctx, manual.ID, "tester")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !check.Override || !slices.Equal(check.Missing, []string{"ci.passed"}) {
+		t.Fatalf("expected an overridden policy missing ci.passed, got %+v", check)
+	}
+}
+
+func TestGate(t *testing.T) {
+	env := newTestEnv(t)
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "feature", Type: "feature", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := engine.GateOptions{TaskID: task.ID, Statuses: []string{"review"}, Require: []string{"ci.passed"}, ActorID: "tester"}
+	res, err := env.Engine.Gate(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("gate: %v", err)
+	}
+	if res.Passed || len(res.Failures) != 2 {
+		t.Fatalf("expected the status and attestation to fail, got %+v", res)
+	}
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: task.ID, Kind: "ci.passed"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	opts.Statuses = append(opts.Statuses, task.Status)
+	if res, err = env.Engine.Gate(env.Ctx, opts); err != nil || !res.Passed {
+		t.Fatalf("expected the gate to pass, got %+v %v", res, err)
+	}
+	opts.Policy = true
+	if res, err = env.Engine.Gate(env.Ctx, opts); err != nil || res.Passed || !strings.Contains(strings.Join(res.Failures, ";"), "review.approved missing") {
+		t.Fatalf("expected the task policy to fail the gate, got %+v %v", res, err)
+	}
+	if _, err := env.Engine.Gate(env.Ctx, engine.GateOptions{TaskID: task.ID, Statuses: []string{"shipped"}, ActorID: "tester"}); err == nil {
+		t.Fatalf("expected an unknown status to be rejected")
+	}
+}
+
+func TestVerifyProject(t *testing.T) {
+	env := newTestEnv(t)
+	create := func(opts engine.TaskCreateOptions) domain.Task {
+		t.Helper()
+		opts.ProjectID, opts.ActorID = "proj-1", "tester"
+		task, err := env.Engine.CreateTask(env.Ctx, opts)
+		if err != nil {
+			t.Fatalf("create %s: %v", opts.Title, err)
+		}
+		if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+			t.Fatalf("complete %s: %v", opts.Title, err)
+		}
+		return task
+	}
+	forced := create(engine.TaskCreateOptions{Title: "forced", Type: "feature"})
+	// Required only ci.passed when created; the feature policy asks for more.
+	loose := create(engine.TaskCreateOptions{Title: "loose", Type: "feature", PolicyOverride: true, RequiredKinds: []string{"ci.passed"}})
+	if _, err := env.Engine.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: loose.ID, Kind: "ci.passed"}, "tester"); err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "open", Type: "feature", ActorID: "tester"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := env.Engine.VerifyProject(env.Ctx, "proj-1", "tester", false)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if report.Checked != 2 || len(report.NonCompliant) != 2 {
+		t.Fatalf("expected 2 checked and 2 non-compliant, got %+v", report)
+	}
+	byID := map[string]domain.ComplianceIssue{}
+	for _, i := range report.NonCompliant {
+		byID[i.TaskID] = i
+	}
+	if i := byID[forced.ID]; !i.Forced || len(i.Missing) == 0 || len(i.Tightened) != 0 {
+		t.Fatalf("expected the forced task to miss its own requirements, got %+v", i)
+	}
+	if i := byID[loose.ID]; len(i.Missing) != 0 || len(i.Tightened) == 0 || slices.Contains(i.Tightened, "ci.passed") {
+		t.Fatalf("expected the loose task to fall short of the current policy only, got %+v", i)
+	}
+
+	if _, err := env.Engine.VerifyProject(env.Ctx, "proj-1", "stranger", true); err == nil {
+		t.Fatalf("expected flagging without project.verify to fail")
+	}
+	if _, err := env.Engine.VerifyProject(env.Ctx, "proj-1", "tester", true); err != nil {
+		t.Fatalf("verify with flag: %v", err)
+	}
+	var flagged int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT COUNT(*) FROM events WHERE type='compliance.flagged'`).Scan(&flagged); err != nil {
+		t.Fatal(err)
+	}
+	if flagged != 2 {
+		t.Fatalf("expected 2 compliance.flagged events, got %d", flagged)
+	}
+}
+
+func TestDoneRoles(t *testing.T) {
+	env := newTestEnv(t)
+	tt := env.Engine.Config.Project.TaskTypes["chore"]
+	tt.DoneRoles = []string{"release"}
+	env.Engine.Config.Project.TaskTypes["chore"] = tt
+	if err := env.Engine.Config.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "ship", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"ready", "in_progress", "review"} {
+		if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: status, ActorID: "tester", Force: true}); err != nil {
+			t.Fatalf("to %s: %v", status, err)
+		}
+	}
+	_, err = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "done", ActorID: "tester", Force: true})
+	var re auth.ForbiddenRoleError
+	if !errors.As(err, &re) || re.TaskType != "chore" || !slices.Equal(re.Roles, []string{"release"}) {
+		t.Fatalf("expected a forbidden role error even with force, got %v", err)
+	}
+	if err.Error() != "completing chore tasks requires role release" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if _, err := env.Engine.CreateRole(env.Ctx, engine.RoleCreateOptions{ProjectID: "proj-1", RoleID: "release", Grants: []string{"task.done"}, ActorID: "tester"}); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "tester", "release"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("expected the release role to complete the task: %v", err)
+	}
+}
+
+func TestForceApproval(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Config.Project.Force.RequireApproval = true
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "hotfix", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.Engine.TaskDone(env.Ctx, task.ID, `{"note":"shipped"}`, "tester", true)
+	var fp engine.ForcePendingError
+	if !errors.As(err, &fp) || fp.Operation != "task.done" {
+		t.Fatalf("expected a pending force request, got %v", err)
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, task.ID); got.Status != "planned" {
+		t.Fatalf("forced done ran before approval: %s", got.Status)
+	}
+	pending, err := env.Engine.ListForceRequests(env.Ctx, "proj-1", "pending", "tester")
+	if err != nil || len(pending) != 1 || pending[0].ID != fp.RequestID || pending[0].RequestedBy != "tester" {
+		t.Fatalf("expected one pending request: %v %+v", err, pending)
+	}
+
+	var fa engine.ForceApprovalError
+	if _, err := env.Engine.ApproveForce(env.Ctx, "proj-1", fp.RequestID, "tester"); !errors.As(err, &fa) || fa.Reason != "self_approval" {
+		t.Fatalf("expected the requester to be refused, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "other", "dev"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	var fe auth.ForbiddenError
+	if _, err := env.Engine.ApproveForce(env.Ctx, "proj-1", fp.RequestID, "other"); !errors.As(err, &fe) || fe.Permission != "force.approve" {
+		t.Fatalf("expected force.approve to be required, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "lead", "owner"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	fr, err := env.Engine.ApproveForce(env.Ctx, "proj-1", fp.RequestID, "lead")
+	if err != nil || fr.Status != "approved" || fr.ApprovedBy == nil || *fr.ApprovedBy != "lead" {
+		t.Fatalf("approve: %v %+v", err, fr)
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, task.ID); got.Status != "done" {
+		t.Fatalf("expected the approved request to complete the task, got %s", got.Status)
+	}
+	used, err := env.Engine.Repo.LatestEvents(env.Ctx, 10, "proj-1", "force.used", "rbac", "proj-1")
+	if err != nil || len(used) != 1 || used[0].ActorID != "tester" || !strings.Contains(used[0].Payload, fp.RequestID) {
+		t.Fatalf("expected force.used by the requester with the request id: %v %+v", err, used)
+	}
+	if _, err := env.Engine.ApproveForce(env.Ctx, "proj-1", fp.RequestID, "lead"); !errors.As(err, &fa) || fa.Reason != "not_pending" {
+		t.Fatalf("expected a second approval to be refused, got %v", err)
+	}
+}
+
+func TestPermissionCache(t *testing.T) {
+	env := newTestEnv(t)
+	env.Engine.Perms = engine.NewPermissionCache()
+	for i := 0; i < 2; i++ {
+		ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "bob", "task.create")
+		if err != nil || ok {
+			t.Fatalf("bob before grant: ok=%v err=%v", ok, err)
+		}
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "bob", "dev"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "bob", "task.create")
+	if err != nil || !ok {
+		t.Fatalf("bob after grant: ok=%v err=%v", ok, err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "cached", ActorID: "bob"}); err != nil {
+		t.Fatalf("create as bob: %v", err)
+	}
+	if err := env.Engine.RevokeRole(env.Ctx, "proj-1", "tester", "bob", "dev"); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	ok, err = env.Engine.HasPermission(env.Ctx, "proj-1", "bob", "task.create")
+	if err != nil || ok {
+		t.Fatalf("bob after revoke: ok=%v err=%v", ok, err)
+	}
+}
+
+func BenchmarkHasPermission(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		if cached {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			env := newTestEnv(b)
+			if cached {
+				env.Engine.Perms = engine.NewPermissionCache()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if ok, err := env.Engine.HasPermission(env.Ctx, "proj-1", "tester", "task.create"); err != nil || !ok {
+					b.Fatalf("has permission: ok=%v err=%v", ok, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListDependencies(b *testing.B) {
+	env := newTestEnv(b)
+	tx, err := env.Engine.DB.BeginTx(env.Ctx, nil)
+	if err != nil {
+		b.Fatalf("begin: %v", err)
+	}
+	const n = 10000
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("bench-%05d", i)
+		if _, err := tx.ExecContext(env.Ctx, `INSERT INTO tasks(id,project_id,type,title,status,created_at,updated_at) VALUES (?,?,?,?,?,?,?)`,
+			ids[i], "proj-1", "technical", ids[i], "planned", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z"); err != nil {
+			b.Fatalf("insert task: %v", err)
+		}
+		if i > 0 {
+			if err := env.Engine.Repo.AddDependencies(env.Ctx, tx, ids[i], []string{ids[i-1]}); err != nil {
+				b.Fatalf("add dependency: %v", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit: %v", err)
+	}
+	b.Run("per_task", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, id := range ids {
+				if _, err := env.Engine.Repo.ListTaskDependencies(env.Ctx, id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			deps, err := env.Engine.Repo.ListDependenciesForTasks(env.Ctx, ids)
+			if err != nil || len(deps) != n-1 {
+				b.Fatalf("batched: %d %v", len(deps), err)
+			}
+		}
+	})
+}
+
+func TestHotQueriesUseIndexes(t *testing.T) {
+	env := newTestEnv(t)
+	plans, err := db.Analyze(env.Ctx, env.Engine.DB)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(plans) != len(db.HotQueries) {
+		t.Fatalf("expected %d plans, got %d", len(db.HotQueries), len(plans))
+	}
+	for _, p := range plans {
+		if len(p.FullScans) > 0 {
+			t.Fatalf("%s scans a whole table: %v", p.Name, p.Steps)
+		}
+	}
+}
+
+func BenchmarkInitProject(b *testing.B) {
+	env := newTestEnv(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := env.Engine.InitProject(env.Ctx, fmt.Sprintf("bench-%d", i), "org-1", "", "bench", "tester"); err != nil {
+			b.Fatalf("init project: %v", err)
+		}
+	}
+}
+
+func BenchmarkAddDependencies(b *testing.B) {
+	env := newTestEnv(b)
+	const n, width = 1000, 10
+	ids := make([]string, n)
+	tx, err := env.Engine.DB.BeginTx(env.Ctx, nil)
+	if err != nil {
+		b.Fatalf("begin: %v", err)
+	}
+	for i := range ids {
+		ids[i] = fmt.Sprintf("bench-%04d", i)
+		if _, err := tx.ExecContext(env.Ctx, `INSERT INTO tasks(id,project_id,type,title,status,created_at,updated_at) VALUES (?,?,?,?,?,?,?)`,
+			ids[i], "proj-1", "technical", ids[i], "planned", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z"); err != nil {
+			b.Fatalf("insert task: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := env.Engine.DB.BeginTx(env.Ctx, nil)
+		if err != nil {
+			b.Fatalf("begin: %v", err)
+		}
+		for j := width; j < n; j++ {
+			if err := env.Engine.Repo.AddDependencies(env.Ctx, tx, ids[j], ids[j-width:j]); err != nil {
+				b.Fatalf("add dependencies: %v", err)
+			}
+		}
+		tx.Rollback()
+	}
+}
+
+// fakeSyncRemote is an in-memory server for Sync; changes lists the ids of
+// the tasks it changed, its cursor being their count.
+type fakeSyncRemote struct {
+	tasks        map[string]engine.SyncTask
+	changes      []string
+	rejectStatus bool
+	down         bool
+}
+
+func (f *fakeSyncRemote) change(t engine.SyncTask) {
+	f.tasks[t.ID] = t
+	f.changes = append(f.changes, t.ID)
+}
+
+func (f *fakeSyncRemote) ChangedTasks(ctx context.Context, since int64) ([]string, bool, int64, error) {
+	return f.changes[since:], false, int64(len(f.changes)), nil
+}
+
+func (f *fakeSyncRemote) GetTask(ctx context.Context, id string) (engine.SyncTask, error) {
+	if f.down {
+		return engine.SyncTask{}, engine.ErrRemoteUnreachable
+	}
+	t, ok := f.tasks[id]
+	if !ok {
+		return t, repo.ErrNotFound
+	}
+	return t, nil
+}
+
+func (f *fakeSyncRemote) CreateTask(ctx context.Context, t engine.SyncTask) (engine.SyncTask, error) {
+	if f.down {
+		return engine.SyncTask{}, engine.ErrRemoteUnreachable
+	}
+	t.Status = "planned"
+	f.change(t)
+	return t, nil
+}
+
+func (f *fakeSyncRemote) UpdateTask(ctx context.Context, id string, fields []string, t engine.SyncTask) error {
+	if f.down {
+		return engine.ErrRemoteUnreachable
+	}
+	cur := f.tasks[id]
+	for _, field := range fields {
+		switch field {
+		case "status":
+			if f.rejectStatus {
+				return engine.SyncRejectedError{Reason: "invalid task status transition"}
+			}
+			cur.Status = t.Status
+		case "assignee_id":
+			cur.AssigneeID = t.AssigneeID
+		case "priority":
+			cur.Priority = t.Priority
+		case "work_outcomes":
+			cur.WorkOutcomes = t.WorkOutcomes
+		}
+	}
+	f.change(cur)
+	return nil
+}
+
+func TestSyncPushesPullsAndMerges(t *testing.T) {
+	env := newTestEnv(t)
+	remote := &fakeSyncRemote{tasks: map[string]engine.SyncTask{}}
+	opts := engine.SyncOptions{ProjectID: "proj-1", ActorID: "tester", Remote: "hub", Client: remote}
+	local, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ID: "task-local", ProjectID: "proj-1", Title: "offline", Type: "chore", ActorID: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, local.ID, "tester", 3600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: local.ID, Status: "in_progress", ActorID: "tester"}); err != nil {
+		t.Fatal(err)
+	}
+	remote.change(engine.SyncTask{ID: "task-hub", Type: "chore", Title: "from hub", Status: "planned"})
+
+	report, err := env.Engine.Sync(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !slices.Equal(report.Pushed, []string{"task-local"}) || !slices.Equal(report.Pulled, []string{"task-hub"}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if got := remote.tasks["task-local"].Status; got != "in_progress" {
+		t.Fatalf("expected the local status pushed after creation, got %s", got)
+	}
+	if hub, err := env.Engine.Repo.GetTask(env.Ctx, "task-hub"); err != nil || hub.Title != "from hub" || hub.ProjectID != "proj-1" {
+		t.Fatalf("expected the hub task pulled, got %+v, %v", hub, err)
+	}
+
+	// Both sides change task-local: the server's status wins, outcomes merge
+	// and the local priority goes out.
+	hubSide := remote.tasks["task-local"]
+	hubSide.Status = "review"
+	hubSide.WorkOutcomes = map[string]any{"pr": 1, "ci": "green"}
+	remote.change(hubSide)
+	priority, outcomesJSON := 3, `{"pr":2,"notes":"local"}`
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: local.ID, Status: "rejected", SetWorkOutcomes: &outcomesJSON, WorkOutcomesSet: true, SetPriority: &priority, PriorityProvided: true, ActorID: "tester"}); err != nil {
+		t.Fatal(err)
+	}
+	report, err = env.Engine.Sync(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	got, err := env.Engine.Repo.GetTask(env.Ctx, local.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outcomes map[string]any
+	_ = json.Unmarshal([]byte(*got.WorkOutcomesJSON), &outcomes)
+	if got.Status != "review" || outcomes["pr"] != float64(1) || outcomes["ci"] != "green" || outcomes["notes"] != "local" {
+		t.Fatalf("expected server status and merged outcomes, got %s %v", got.Status, outcomes)
+	}
+	if p := remote.tasks[local.ID].Priority; p == nil || *p != 3 {
+		t.Fatalf("expected the local priority pushed, got %v", p)
+	}
+	if notes := remote.tasks[local.ID].WorkOutcomes["notes"]; notes != "local" {
+		t.Fatalf("expected the merged outcomes pushed, got %v", remote.tasks[local.ID].WorkOutcomes)
+	}
+	resolutions := map[string]string{}
+	for _, c := range report.Conflicts {
+		resolutions[c.Field] = c.Resolution
+	}
+	if resolutions["status"] != "remote" || resolutions["work_outcomes"] != "merged" {
+		t.Fatalf("unexpected conflicts %+v", report.Conflicts)
+	}
+	var projected string
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT status FROM projection_task_status WHERE task_id=?`, local.ID).Scan(&projected); err == nil && projected != "review" {
+		t.Fatalf("expected the status projection to follow task.synced, got %s", projected)
+	}
+
+	// A status the server refuses gives way to the server's.
+	remote.rejectStatus = true
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: local.ID, Status: "rejected", ActorID: "tester"}); err != nil {
+		t.Fatal(err)
+	}
+	report, err = env.Engine.Sync(env.Ctx, opts)
+	if err != nil {
+		t.Fatalf("third sync: %v", err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Reason == "" || report.Conflicts[0].Resolution != "remote" {
+		t.Fatalf("expected a rejected status conflict, got %+v", report.Conflicts)
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, local.ID); got.Status != "review" {
+		t.Fatalf("expected the server status restored, got %s", got.Status)
+	}
+
+	// Nothing changed since: the pulled changes are not pushed back.
+	report, err = env.Engine.Sync(env.Ctx, opts)
+	if err != nil || len(report.Pushed)+len(report.Pulled)+len(report.Conflicts) != 0 {
+		t.Fatalf("expected an empty sync, got %+v, %v", report, err)
+	}
+}
+
+func TestOfflineQueueReplay(t *testing.T) {
+	env := newTestEnv(t)
+	remote := &fakeSyncRemote{tasks: map[string]engine.SyncTask{}}
+	remote.change(engine.SyncTask{ID: "task-a", Type: "chore", Title: "a", Status: "planned"})
+	remote.change(engine.SyncTask{ID: "task-b", Type: "chore", Title: "b", Status: "planned"})
+	if _, err := env.Engine.Sync(env.Ctx, engine.SyncOptions{ProjectID: "proj-1", ActorID: "tester", Remote: "hub", Client: remote}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	opts := engine.RemoteOptions{ProjectID: "proj-1", ActorID: "tester", Remote: "hub", Client: remote, Key: []byte("0123456789abcdef0123456789abcdef")}
+	status := func(id, s string) engine.RemoteCommand {
+		return engine.RemoteCommand{Op: engine.RemoteTaskUpdate, Task: engine.SyncTask{ID: id, Status: s}, Fields: []string{"status"}}
+	}
+	run := func(cmd engine.RemoteCommand) domain.QueuedCommand {
+		t.Helper()
+		_, queued, err := env.Engine.RunRemoteCommand(env.Ctx, opts, cmd)
+		if err != nil {
+			t.Fatalf("run %s: %v", cmd.Op, err)
+		}
+		return queued
+	}
+
+	// The server is unreachable: commands are queued in order.
+	remote.down = true
+	created := run(engine.RemoteCommand{Op: engine.RemoteTaskCreate, Task: engine.SyncTask{Type: "chore", Title: "offline"}})
+	if created.Seq == 0 || created.TaskID == "" || created.Signature == "" {
+		t.Fatalf("expected a signed create with an id, got %+v", created)
+	}
+	first := run(status("task-a", "in_progress"))
+	conflicting := run(status("task-b", "in_progress"))
+	behind := run(status("task-b", "review"))
+	if first.Base == "" || !(created.Seq < first.Seq && first.Seq < conflicting.Seq && conflicting.Seq < behind.Seq) {
+		t.Fatalf("expected ordered commands with a sync base, got %+v %+v", created, first)
+	}
+
+	// Back online, a new command waits behind the pending ones.
+	remote.down = false
+	if later := run(status("task-a", "review")); later.Seq <= behind.Seq {
+		t.Fatalf("expected a command queued behind pending ones, got %+v", later)
+	}
+	if remote.tasks["task-a"].Status != "planned" {
+		t.Fatalf("expected nothing sent out of order")
+	}
+
+	// The server moved task-b meanwhile, and a queued command was tampered with.
+	changed := remote.tasks["task-b"]
+	changed.Status = "canceled"
+	remote.change(changed)
+	tampered := run(engine.RemoteCommand{Op: engine.RemoteTaskUpdate, Task: engine.SyncTask{ID: "task-a", Priority: new(int)}, Fields: []string{"priority"}})
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `UPDATE offline_queue SET payload_json=replace(payload_json, '"priority":0', '"priority":9') WHERE seq=?`, tampered.Seq); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := env.Engine.ReplayQueue(env.Ctx, opts, false)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(report.Applied) != 3 || remote.tasks["task-a"].Status != "review" || remote.tasks[created.TaskID].Title != "offline" {
+		t.Fatalf("expected the create and task-a updates applied in order, got %+v %+v", report, remote.tasks["task-a"])
+	}
+	held := map[int64]string{}
+	for _, c := range report.Held {
+		held[c.Seq] = c.Status
+	}
+	if held[conflicting.Seq] != "conflict" || held[behind.Seq] != "conflict" || held[tampered.Seq] != "rejected" || remote.tasks["task-b"].Status != "canceled" {
+		t.Fatalf("expected the conflict, the command behind it and the tampered one held, got %+v", report.Held)
+	}
+	if p := remote.tasks["task-a"].Priority; p != nil {
+		t.Fatalf("expected the tampered command not sent, got priority %d", *p)
+	}
+	queued, err := env.Engine.QueuedCommands(env.Ctx, "proj-1", "tester", "hub")
+	if err != nil || len(queued) != 3 {
+		t.Fatalf("expected the held commands to stay queued, got %+v, %v", queued, err)
+	}
+
+	// Dropping the tampered command and forcing the conflict sends the rest.
+	if err := env.Engine.DropQueuedCommand(env.Ctx, "proj-1", "tester", tampered.Seq); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = env.Engine.ReplayQueue(env.Ctx, opts, true); err != nil || len(report.Applied) != 2 || len(report.Held) != 0 {
+		t.Fatalf("expected the forced replay to send both task-b commands, got %+v, %v", report, err)
+	}
+	if remote.tasks["task-b"].Status != "review" {
+		t.Fatalf("expected task-b updated in order, got %s", remote.tasks["task-b"].Status)
+	}
+
+	// Replay stops while the server is unreachable and keeps the command.
+	remote.down = true
+	run(status("task-a", "done"))
+	report, err = env.Engine.ReplayQueue(env.Ctx, opts, false)
+	if !errors.Is(err, engine.ErrRemoteUnreachable) || report.Pending != 1 {
+		t.Fatalf("expected replay to stop with the command queued, got %+v, %v", report, err)
+	}
+}
+
+func TestMarkStaleTasks(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	mailer := &fakeMailer{}
+	env.Engine.Mail = mailer
+	env.Engine.Config.Project.Staleness = config.StalenessConfig{Days: 3, Notify: map[string]string{"tester": "tester@example.com"}}
+	env.Engine.Config.Digest.SMTP = config.SMTPConfig{From: "workline@example.com"}
+
+	idle, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "idle", ActorID: "tester", AssigneeID: "tester"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "planned", ActorID: "tester"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, idle.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: idle.ID, Status: "in_progress", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	sweep := func() []domain.Task {
+		t.Helper()
+		stale, err := env.Engine.MarkStaleTasks(env.Ctx, "proj-1", "system")
+		if err != nil {
+			t.Fatalf("sweep: %v", err)
+		}
+		return stale
+	}
+
+	current = current.Add(2 * 24 * time.Hour)
+	if stale := sweep(); len(stale) != 0 {
+		t.Fatalf("expected nothing stale after 2 days, got %+v", stale)
+	}
+	current = current.Add(2 * 24 * time.Hour)
+	if stale := sweep(); len(stale) != 1 || stale[0].ID != idle.ID || !stale[0].Stale {
+		t.Fatalf("expected only the in_progress task to go stale, got %+v", stale)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To[0] != "tester@example.com" || !strings.Contains(mailer.sent[0].Body, idle.ID) {
+		t.Fatalf("unexpected notifications %+v", mailer.sent)
+	}
+	if stale := sweep(); len(stale) != 0 || len(mailer.sent) != 1 {
+		t.Fatalf("expected second sweep to be a no-op, got %+v", stale)
+	}
+	listed, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1", Stale: true})
+	if err != nil || len(listed) != 1 || listed[0].ID != idle.ID || !listed[0].Stale {
+		t.Fatalf("stale filter: %+v, %v", listed, err)
+	}
+
+	// An update clears the flag; the task goes stale again after another
+	// idle spell, and a failed notification leaves it unmarked.
+	priority := 1
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: idle.ID, SetPriority: &priority, PriorityProvided: true, ActorID: "tester"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, err := env.Engine.Repo.GetTask(env.Ctx, idle.ID); err != nil || got.Stale {
+		t.Fatalf("expected update to clear stale, got %+v, %v", got, err)
+	}
+	current = current.Add(4 * 24 * time.Hour)
+	mailer.err = errors.New("smtp down")
+	if _, err := env.Engine.MarkStaleTasks(env.Ctx, "proj-1", "system"); err == nil {
+		t.Fatalf("expected failed notification to fail the sweep")
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, idle.ID); got.Stale {
+		t.Fatalf("expected failed sweep to leave the task unmarked")
+	}
+	mailer.err = nil
+	if stale := sweep(); len(stale) != 1 || len(mailer.sent) != 2 {
+		t.Fatalf("expected retry to mark and notify, got %+v", stale)
+	}
+}
+
+func TestTaskRelations(t *testing.T) {
+	env := newTestEnv(t)
+	create := func(title string) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: title, ActorID: "tester"})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	blocker, blocked, dup := create("blocker"), create("blocked"), create("dup")
+	relate := func(from, relType, to string) (domain.TaskRelation, error) {
+		return env.Engine.AddTaskRelation(env.Ctx, engine.TaskRelationOptions{TaskID: from, Type: relType, RelatedTaskID: to, ActorID: "tester"})
+	}
+	if _, err := relate(blocker.ID, "blocks", blocked.ID); err != nil {
+		t.Fatalf("blocks: %v", err)
+	}
+	if _, err := relate(blocked.ID, "blocks", blocker.ID); err == nil || !strings.Contains(err.Error(), "inverse relation already exists") {
+		t.Fatalf("expected inverse blocks to be rejected, got %v", err)
+	}
+	if _, err := relate(blocked.ID, "supersedes", blocker.ID); err == nil {
+		t.Fatalf("expected unknown relation type to be rejected")
+	}
+	if _, err := relate(blocked.ID, "relates_to", blocked.ID); err == nil {
+		t.Fatalf("expected self relation to be rejected")
+	}
+
+	_, _ = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: blocked.ID, Status: "in_progress", ActorID: "tester", Force: true})
+	_, _ = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: blocked.ID, Status: "review", ActorID: "tester", Force: true})
+	if _, err := env.Engine.ClaimLease(env.Ctx, blocked.ID, "tester", 300); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: blocked.ID, Status: "done", ActorID: "tester"}); err == nil || !strings.Contains(err.Error(), "blocked by "+blocker.ID) {
+		t.Fatalf("expected blocker to gate done, got %v", err)
+	}
+
+	rel, err := relate(dup.ID, "duplicates", blocked.ID)
+	if err != nil || rel.Closed != "canceled" {
+		t.Fatalf("duplicates: %+v, %v", rel, err)
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, dup.ID); got.Status != "canceled" || len(got.Relations) != 1 {
+		t.Fatalf("expected duplicate canceled with its relation, got %+v", got)
+	}
+	rels, err := env.Engine.ListTaskRelations(env.Ctx, blocked.ID, "tester")
+	if err != nil || len(rels) != 2 {
+		t.Fatalf("expected relations from both sides, got %+v, %v", rels, err)
+	}
+	graph, err := env.Engine.TaskGraph(env.Ctx, "proj-1", "tester")
+	if err != nil || len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+		t.Fatalf("unexpected graph %+v, %v", graph, err)
+	}
+
+	if err := env.Engine.RemoveTaskRelation(env.Ctx, engine.TaskRelationOptions{TaskID: blocker.ID, Type: "blocks", RelatedTaskID: blocked.ID, ActorID: "tester"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := env.Engine.RemoveTaskRelation(env.Ctx, engine.TaskRelationOptions{TaskID: blocker.ID, Type: "blocks", RelatedTaskID: blocked.ID, ActorID: "tester"}); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected not found on second remove, got %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: blocked.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("expected done once unblocked: %v", err)
+	}
+}
+
+func TestEscalatePriorities(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	env.Engine.Config.Project.Escalation = []config.EscalationRule{
+		{Type: "bug", AfterDays: 7},
+		{AfterDays: 14, Highest: 3},
+	}
+	create := func(title, taskType string, priority *int) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", ActorID: "tester", Title: title, Type: taskType, Priority: priority})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	two, four := 2, 4
+	bug := create("bug", "bug", &two)
+	chore := create("chore", "technical", &four)
+	create("unprioritized", "bug", nil)
+	done := create("done bug", "bug", &four)
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: done.ID, Status: "canceled", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	current = current.AddDate(0, 0, 8)
+	preview, err := env.Engine.PreviewEscalations(env.Ctx, "proj-1", "tester")
+	if err != nil || len(preview) != 1 || preview[0].TaskID != bug.ID || preview[0].From != 2 || preview[0].To != 1 {
+		t.Fatalf("preview: %+v, %v", preview, err)
+	}
+	if got, _ := env.Engine.Repo.GetTask(env.Ctx, bug.ID); *got.Priority != 2 {
+		t.Fatalf("expected preview to leave priority alone, got %d", *got.Priority)
+	}
+	escalated, err := env.Engine.EscalatePriorities(env.Ctx, "proj-1", "system")
+	if err != nil || len(escalated) != 1 || escalated[0].TaskID != bug.ID {
+		t.Fatalf("sweep: %+v, %v", escalated, err)
+	}
+	if again, err := env.Engine.EscalatePriorities(env.Ctx, "proj-1", "system"); err != nil || len(again) != 0 {
+		t.Fatalf("expected second sweep to wait for after_days, got %+v, %v", again, err)
+	}
+
+	// The fallback rule catches the technical task; the bug is at priority 1.
+	current = current.AddDate(0, 0, 8)
+	escalated, err = env.Engine.EscalatePriorities(env.Ctx, "proj-1", "system")
+	if err != nil || len(escalated) != 1 || escalated[0].TaskID != chore.ID || escalated[0].To != 3 {
+		t.Fatalf("expected fallback escalation, got %+v, %v", escalated, err)
+	}
+	current = current.AddDate(0, 0, 30)
+	if escalated, err := env.Engine.EscalatePriorities(env.Ctx, "proj-1", "system"); err != nil || len(escalated) != 0 {
+		t.Fatalf("expected rule ceilings to stop escalation, got %+v, %v", escalated, err)
+	}
+	history, err := env.Engine.TaskHistory(env.Ctx, bug.ID, "tester")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if last := history[len(history)-1]; last.Summary != "priority escalated 2 -> 1 after 7 days" {
+		t.Fatalf("unexpected history entry %+v", last)
+	}
+}
+
+func TestSLADueDatesAndBreaches(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	urgent := 1
+	env.Engine.Config.Project.SLA = []config.SLARule{
+		{Type: "technical", Priority: &urgent, Hours: 8},
+		{Hours: 72},
+	}
+	create := func(opts engine.TaskCreateOptions) domain.Task {
+		t.Helper()
+		opts.ProjectID, opts.ActorID = "proj-1", "tester"
+		task, err := env.Engine.CreateTask(env.Ctx, opts)
+		if err != nil {
+			t.Fatalf("create %s: %v", opts.Title, err)
+		}
+		return task
+	}
+	hot := create(engine.TaskCreateOptions{Title: "hot", Priority: &urgent})
+	normal := create(engine.TaskCreateOptions{Title: "normal"})
+	dated := create(engine.TaskCreateOptions{Title: "dated", DueAt: "2024-03-02"})
+	if hot.DueAt == nil || *hot.DueAt != "2024-03-01T17:00:00Z" {
+		t.Fatalf("expected priority rule due date, got %v", hot.DueAt)
+	}
+	if normal.DueAt == nil || *normal.DueAt != "2024-03-04T09:00:00Z" {
+		t.Fatalf("expected fallback rule due date, got %v", normal.DueAt)
+	}
+	if dated.DueAt == nil || *dated.DueAt != "2024-03-02T23:59:59Z" {
+		t.Fatalf("expected explicit due date at end of day, got %v", dated.DueAt)
+	}
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", ActorID: "tester", Title: "bad", DueAt: "soon"}); err == nil {
+		t.Fatalf("expected invalid due date to be rejected")
+	}
+
+	current = time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: dated.ID, Status: "canceled", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	overdue, err := env.Engine.Repo.ListTasks(env.Ctx, repo.TaskFilters{ProjectID: "proj-1", OverdueAt: current.Format(time.RFC3339)})
+	if err != nil || len(overdue) != 1 || overdue[0].ID != hot.ID {
+		t.Fatalf("overdue filter: %+v, %v", overdue, err)
+	}
+	breached, err := env.Engine.BreachSLAs(env.Ctx, "proj-1", "system")
+	if err != nil || len(breached) != 1 || breached[0].ID != hot.ID {
+		t.Fatalf("sweep: %+v, %v", breached, err)
+	}
+	if again, err := env.Engine.BreachSLAs(env.Ctx, "proj-1", "system"); err != nil || len(again) != 0 {
+		t.Fatalf("expected second sweep to be a no-op, got %+v, %v", again, err)
+	}
+
+	// Moving the due date later re-arms the breach; clearing it drops the task.
+	later := "2024-03-03T12:00:00Z"
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: hot.ID, SetDueAt: &later, DueAtProvided: true, ActorID: "tester"}); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	current = time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	breached, err = env.Engine.BreachSLAs(env.Ctx, "proj-1", "system")
+	if err != nil || len(breached) != 2 || breached[0].ID != hot.ID || breached[1].ID != normal.ID {
+		t.Fatalf("expected re-armed and new breaches, got %+v, %v", breached, err)
+	}
+	cleared, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: normal.ID, DueAtProvided: true, ActorID: "tester"})
+	if err != nil || cleared.DueAt != nil {
+		t.Fatalf("clear due date: %+v, %v", cleared.DueAt, err)
+	}
+	var count int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT count(*) FROM events WHERE type='sla.breached' AND entity_id=?`, hot.ID).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("expected two sla.breached events, got %d, %v", count, err)
+	}
+}
+
+func TestFieldEncryption(t *testing.T) {
+	env := newTestEnv(t)
+	outcomes := `{"result":"shipped"}`
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Secret work", ActorID: "tester", WorkOutcomesJSON: &outcomes})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.EncryptFields(env.Ctx); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("expected encryption not configured, got %v", err)
+	}
+
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	cfg := *env.Engine.Config
+	cfg.Encryption = config.EncryptionConfig{Key: key}
+	enc := engine.New(env.Engine.DB, &cfg)
+	enc.Now = env.Engine.Now
+	att, err := enc.AddAttestation(env.Ctx, domain.Attestation{ProjectID: "proj-1", EntityKind: "task", EntityID: task.ID, Kind: "ci.passed", PayloadJSON: `{"token":"s3cret"}`}, "tester")
+	if err != nil {
+		t.Fatalf("add attestation: %v", err)
+	}
+	var stored string
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT payload_json FROM attestations WHERE id=?`, att.ID).Scan(&stored); err != nil {
+		t.Fatalf("read raw payload: %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:v2:") || strings.Contains(stored, "s3cret") {
+		t.Fatalf("expected sealed payload, got %s", stored)
+	}
+	atts, err := enc.Repo.ListAttestations(env.Ctx, repo.AttestationFilters{EntityKind: "task", EntityID: task.ID})
+	if err != nil || len(atts) != 1 || atts[0].PayloadJSON != `{"token":"s3cret"}` {
+		t.Fatalf("expected decrypted payload, got %+v, %v", atts, err)
+	}
+	if _, err := env.Engine.Repo.ListAttestations(env.Ctx, repo.AttestationFilters{EntityKind: "task", EntityID: task.ID}); err == nil || !strings.Contains(err.Error(), "no encryption key") {
+		t.Fatalf("expected reads without the key to fail, got %v", err)
+	}
+
+	if n, err := enc.EncryptFields(env.Ctx); err != nil || n != 1 {
+		t.Fatalf("expected the plaintext work outcomes to be sealed, got %d, %v", n, err)
+	}
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT work_outcomes_json FROM tasks WHERE id=?`, task.ID).Scan(&stored); err != nil || !strings.HasPrefix(stored, "enc:v2:") {
+		t.Fatalf("expected sealed work outcomes, got %s, %v", stored, err)
+	}
+	got, err := enc.Repo.GetTask(env.Ctx, task.ID)
+	if err != nil || got.WorkOutcomesJSON == nil || *got.WorkOutcomesJSON != outcomes {
+		t.Fatalf("expected decrypted work outcomes, got %+v, %v", got.WorkOutcomesJSON, err)
+	}
+
+	rotated := cfg
+	rotated.Encryption = config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("r", 32))), PreviousKeys: []string{key}}
+	if err := rotated.Validate(); err != nil {
+		t.Fatalf("validate rotated config: %v", err)
+	}
+	next := engine.New(env.Engine.DB, &rotated)
+	if n, err := next.EncryptFields(env.Ctx); err != nil || n != 2 {
+		t.Fatalf("expected both values re-sealed with the new key, got %d, %v", n, err)
+	}
+	if _, err := enc.Repo.GetTask(env.Ctx, task.ID); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Fatalf("expected the old key alone to fail after rotation, got %v", err)
+	}
+}
+
+func TestFieldEncryptionBindsValuesToTheirRow(t *testing.T) {
+	env := newTestEnv(t)
+	rawKey := []byte(strings.Repeat("k", 32))
+	key := base64.StdEncoding.EncodeToString(rawKey)
+	cfg := *env.Engine.Config
+	// The key comes from a program run with its arguments, not a shell.
+	cfg.Encryption = config.EncryptionConfig{KMSCommand: []string{"printf", "%s", key}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	enc := engine.New(env.Engine.DB, &cfg)
+	enc.Now = env.Engine.Now
+
+	// A value that looks sealed is sealed all the same.
+	ring := fieldcrypt.New(cfg.Encryption)
+	field := fieldcrypt.Field{Table: "tasks", Column: "work_outcomes_json", RowID: "t1"}
+	lookalike := fieldcrypt.Prefix + "00000000:AAAA"
+	sealed, err := ring.Seal(field, lookalike)
+	if err != nil || sealed == lookalike {
+		t.Fatalf("expected the lookalike sealed, got %s, %v", sealed, err)
+	}
+	if plain, err := ring.Open(field, sealed); err != nil || plain != lookalike {
+		t.Fatalf("expected the lookalike back, got %s, %v", plain, err)
+	}
+
+	outcomes := `{"result":"shipped"}`
+	a, err := enc.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "a", ActorID: "tester", WorkOutcomesJSON: &outcomes})
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	b, err := enc.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "b", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `UPDATE tasks SET work_outcomes_json=(SELECT work_outcomes_json FROM tasks WHERE id=?) WHERE id=?`, a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enc.Repo.GetTask(env.Ctx, b.ID); err == nil {
+		t.Fatalf("expected a value copied from another row not to open")
+	}
+
+	// Values sealed before they were bound to their row still open, and
+	// re-encryption binds them.
+	block, _ := aes.NewCipher(rawKey)
+	aead, _ := cipher.NewGCM(block)
+	nonce := make([]byte, aead.NonceSize())
+	sum := sha256.Sum256(rawKey)
+	legacy := "enc:v1:" + hex.EncodeToString(sum[:4]) + ":" + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(outcomes), nil))
+	if _, err := env.Engine.DB.ExecContext(env.Ctx, `UPDATE tasks SET work_outcomes_json=? WHERE id=?`, legacy, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := enc.Repo.GetTask(env.Ctx, b.ID)
+	if err != nil || got.WorkOutcomesJSON == nil || *got.WorkOutcomesJSON != outcomes {
+		t.Fatalf("expected the legacy value to open, got %+v, %v", got.WorkOutcomesJSON, err)
+	}
+	if n, err := enc.EncryptFields(env.Ctx); err != nil || n != 1 {
+		t.Fatalf("expected the legacy value re-sealed, got %d, %v", n, err)
+	}
+	var stored string
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT work_outcomes_json FROM tasks WHERE id=?`, b.ID).Scan(&stored); err != nil || !strings.HasPrefix(stored, fieldcrypt.Prefix) {
+		t.Fatalf("expected the value bound to its row, got %s, %v", stored, err)
+	}
+	if got, err := enc.Repo.GetTask(env.Ctx, b.ID); err != nil || *got.WorkOutcomesJSON != outcomes {
+		t.Fatalf("expected the re-sealed value to open, got %v", err)
+	}
+}
+
+func TestScrubActor(t *testing.T) {
+	env := newTestEnv(t)
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Dana's task", ActorID: "dana", AssigneeID: "dana"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "dana", 300); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.ScrubActor(env.Ctx, "proj-1", "tester", "tester", ""); err == nil || !strings.Contains(err.Error(), "themselves") {
+		t.Fatalf("expected self scrub to be rejected, got %v", err)
+	}
+	if _, err := env.Engine.ScrubActor(env.Ctx, "proj-1", "tester", "nobody", ""); err == nil || !strings.Contains(err.Error(), "unknown actor") {
+		t.Fatalf("expected unknown actor, got %v", err)
+	}
+	if _, err := env.Engine.InitProject(env.Ctx, "proj-2", "org-2", "", "other", "mallory"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	var forbidden auth.ForbiddenError
+	if _, err := env.Engine.ScrubActor(env.Ctx, "proj-2", "mallory", "dana", ""); !errors.As(err, &forbidden) {
+		t.Fatalf("expected scrub of another org's actor to be forbidden, got %v", err)
+	}
+
+	scrub, err := env.Engine.ScrubActor(env.Ctx, "proj-1", "tester", "dana", "request 42")
+	if err != nil {
+		t.Fatalf("scrub: %v", err)
+	}
+	if !strings.HasPrefix(scrub.Pseudonym, "anon-") || scrub.RowsRewritten == 0 || !slices.Equal(scrub.ReleasedTasks, []string{task.ID}) || !slices.Equal(scrub.RechainedProjects, []string{"proj-1"}) {
+		t.Fatalf("unexpected scrub %+v", scrub)
+	}
+	var left int
+	if err := env.Engine.DB.QueryRowContext(env.Ctx, `SELECT
+  (SELECT count(*) FROM events WHERE actor_id='dana' OR instr(payload_json, '"dana"')>0) +
+  (SELECT count(*) FROM tasks WHERE assignee_id='dana') +
+  (SELECT count(*) FROM leases WHERE owner_id='dana') +
+  (SELECT count(*) FROM actor_roles WHERE actor_id='dana') +
+  (SELECT count(*) FROM actors WHERE id='dana')`).Scan(&left); err != nil || left != 0 {
+		t.Fatalf("expected no trace of dana, got %d, %v", left, err)
+	}
+	got, err := env.Engine.Repo.GetTask(env.Ctx, task.ID)
+	if err != nil || got.AssigneeID == nil || *got.AssigneeID != scrub.Pseudonym {
+		t.Fatalf("expected the task assigned to the pseudonym, got %+v, %v", got.AssigneeID, err)
+	}
+	report, err := env.Engine.VerifyEventChain(env.Ctx, engine.EventChainOptions{ProjectID: "proj-1", ActorID: "tester"})
+	if err != nil || !report.Valid {
+		t.Fatalf("expected the rehashed chain to verify, got %+v, %v", report, err)
+	}
+	evts, err := env.Engine.Repo.LatestEventsFrom(env.Ctx, 10, 0, repo.EventFilters{ProjectID: "proj-1", Type: engine.ActorScrubbedEvent})
+	if err != nil || len(evts) != 1 || !strings.Contains(evts[0].Payload, scrub.Pseudonym) || !strings.Contains(evts[0].Payload, "request 42") {
+		t.Fatalf("expected an actor.scrubbed event, got %+v, %v", evts, err)
+	}
+
+	var suspended auth.SuspendedActorError
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "Back again", ActorID: "dana"}); !errors.As(err, &suspended) {
+		t.Fatalf("expected the scrubbed id to be refused, got %v", err)
+	}
+	if err := env.Engine.GrantRole(env.Ctx, "proj-1", "tester", "dana", "dev"); !errors.As(err, &suspended) {
+		t.Fatalf("expected the scrubbed id not to be registered again, got %v", err)
+	}
+}
+
+func TestWorkingCalendar(t *testing.T) {
+	env := newTestEnv(t)
+	// Friday 16:00 in Paris; Monday is a holiday.
+	current := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	env.Engine.Config.Project.Calendar = config.CalendarConfig{
+		Timezone:     "Europe/Paris",
+		WorkingDays:  []string{"mon", "tue", "wed", "thu", "fri"},
+		WorkingHours: "09:00-17:00",
+		Holidays:     []string{"2024-03-04"},
+	}
+	env.Engine.Config.Project.SLA = []config.SLARule{{Hours: 8}}
+	env.Engine.Config.Project.Staleness = config.StalenessConfig{Days: 2}
+	if err := env.Engine.Config.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", Title: "calendar", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// One hour on Friday, then seven on Tuesday.
+	if task.DueAt == nil || *task.DueAt != "2024-03-05T15:00:00Z" {
+		t.Fatalf("expected due date in working hours, got %v", task.DueAt)
+	}
+	if _, err := env.Engine.ClaimLease(env.Ctx, task.ID, "tester", 3600); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "in_progress", ActorID: "tester", Force: true}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	current = time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)
+	if stale, err := env.Engine.MarkStaleTasks(env.Ctx, "proj-1", "system"); err != nil || len(stale) != 0 {
+		t.Fatalf("expected weekend and holiday not to count, got %+v, %v", stale, err)
+	}
+	current = time.Date(2024, 3, 6, 16, 0, 0, 0, time.UTC)
+	if stale, err := env.Engine.MarkStaleTasks(env.Ctx, "proj-1", "system"); err != nil || len(stale) != 1 {
+		t.Fatalf("expected stale after two working days, got %+v, %v", stale, err)
+	}
+
+	env.Engine.Config.Project.Calendar = config.CalendarConfig{Timezone: "Mars/Olympus", WorkingDays: []string{"funday"}, WorkingHours: "17:00-09:00"}
+	err = env.Engine.Config.Validate()
+	for _, want := range []string{"not a known timezone", "unknown day funday", "must end after it starts"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	env := newTestEnv(t)
+	current := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	env.Engine.Now = func() time.Time { return current }
+	env.Engine.Events.Now = env.Engine.Now
+	if _, err := env.Engine.CreateIteration(env.Ctx, domain.Iteration{ID: "iter-1", ProjectID: "proj-1", Goal: "Beta"}, "tester"); err != nil {
+		t.Fatalf("create iteration: %v", err)
+	}
+	done := func(title, typ, outcomes string) domain.Task {
+		t.Helper()
+		task, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: title, Type: typ, ActorID: "tester", WorkOutcomesJSON: &outcomes})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		current = current.Add(time.Hour)
+		if task, err = env.Engine.UpdateTask(env.Ctx, engine.TaskUpdateOptions{ID: task.ID, Status: "done", ActorID: "tester", Force: true}); err != nil {
+			t.Fatalf("done %s: %v", title, err)
+		}
+		return task
+	}
+	login := done("Add login", "technical", `{"summary":"OAuth login","pr":"https://git.example.com/pr/12","commits":[{"url":"https://git.example.com/c/ab12"}]}`)
+	cleanup := done("Drop old tables", "chore", `{"notes":"no user impact"}`)
+	search := done("Search", "technical", `{}`)
+	if _, err := env.Engine.CreateTask(env.Ctx, engine.TaskCreateOptions{ProjectID: "proj-1", IterationID: "iter-1", Title: "open", ActorID: "tester"}); err != nil {
+		t.Fatalf("create open: %v", err)
+	}
+
+	c, err := env.Engine.Changelog(env.Ctx, "iter-1", "tester")
+	if err != nil {
+		t.Fatalf("changelog: %v", err)
+	}
+	if len(c.Sections) != 2 || c.Sections[0].Type != "chore" || c.Sections[1].Type != "technical" {
+		t.Fatalf("expected chore and technical sections, got %+v", c.Sections)
+	}
+	tech := c.Sections[1].Entries
+	if len(tech) != 2 || tech[0].TaskID != login.ID || tech[1].TaskID != search.ID {
+		t.Fatalf("expected technical tasks in completion order, got %+v", tech)
+	}
+	if tech[0].Summary != "OAuth login" || !slices.Equal(tech[0].Links, []string{"https://git.example.com/c/ab12", "https://git.example.com/pr/12"}) {
+		t.Fatalf("unexpected entry %+v", tech[0])
+	}
+	if c.Sections[0].Entries[0].TaskID != cleanup.ID || c.Sections[0].Entries[0].Summary != "no user impact" {
+		t.Fatalf("expected notes as summary, got %+v", c.Sections[0].Entries)
+	}
+	md := engine.ChangelogMarkdown(c)
+	for _, want := range []string{"# Changelog: iter-1", "## chore", "- Add login (`" + login.ID + "`): OAuth login <https://git.example.com/c/ab12> <https://git.example.com/pr/12>"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("expected %q in:\n%s", want, md)
+		}
+	}
+	if _, err := env.Engine.Changelog(env.Ctx, "iter-1", "outsider"); err == nil {
+		t.Fatalf("expected outsider to be refused")
+	}
+}
+
+func TestDecisionADRExport(t *testing.T) {
+	env := newTestEnv(t)
+	first, err := env.Engine.CreateDecision(env.Ctx, domain.Decision{
+		ProjectID:        "proj-1",
+		Title:            "Adopt Go for the backend",
+		Decision:         "Services are written in Go.",
+		ContextJSON:      `{"problem":"pick a language"}`,
+		RationaleJSON:    `["fast builds","team knows it"]`,
+		AlternativesJSON: `["Rust"]`,
+		DeciderID:        "tester",
+	}, "tester")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	second, err := env.Engine.CreateDecision(env.Ctx, domain.Decision{ID: "dec-a", ProjectID: "proj-1", Title: "Use SQLite", Decision: "Embedded database.", DeciderID: "tester"}, "tester")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if first.Number != 1 || second.Number != 2 {
+		t.Fatalf("expected numbers 1 and 2, got %d and %d", first.Number, second.Number)
+	}
+
+	decisions, err := env.Engine.ListDecisions(env.Ctx, "proj-1", "tester")
+	if err != nil || len(decisions) != 2 || decisions[0].ID != first.ID || decisions[1].ID != "dec-a" {
+		t.Fatalf("list: %+v, %v", decisions, err)
+	}
+	if name := engine.ADRFileName(decisions[0]); name != "0001-adopt-go-for-the-backend.md" {
+		t.Fatalf("unexpected file name %s", name)
+	}
+	adr := engine.DecisionADR(decisions[0])
+	for _, want := range []string{"# 1. Adopt Go for the backend", "## Status\n\nAccepted", "- problem: pick a language", "## Decision\n\nServices are written in Go.", "## Consequences\n\n- fast builds\n- team knows it", "### Alternatives considered\n\n- Rust"} {
+		if !strings.Contains(adr, want) {
+			t.Fatalf("expected %q in:\n%s", want, adr)
+		}
+	}
+	if adr := engine.DecisionADR(decisions[1]); strings.Contains(adr, "Alternatives") || !strings.Contains(adr, "## Context\n\nNone recorded.") {
+		t.Fatalf("unexpected ADR without context or alternatives:\n%s", adr)
+	}
+	if _, err := env.Engine.ListDecisions(env.Ctx, "proj-1", "outsider"); err == nil {
+		t.Fatalf("expected outsider to be refused")
+	}
+}
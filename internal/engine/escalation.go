@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// TaskEscalatedEvent records a task's priority raised by a
+// project.escalation rule.
+const TaskEscalatedEvent = "task.escalated"
+
+// PreviewEscalations lists the escalations the next sweep would make, without
+// making them.
+func (e Engine) PreviewEscalations(ctx context.Context, projectID, actorID string) ([]domain.Escalation, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "task.list"); err != nil {
+		return nil, err
+	}
+	return e.dueEscalationsTx(ctx, tx, projectID, e.now().UTC())
+}
+
+// EscalatePriorities raises each open task of the project one priority level
+// once it has waited its project.escalation rule's after_days since creation
+// or its last escalation, recording a task.escalated event for each. A task
+// moves at most one level per sweep.
+func (e Engine) EscalatePriorities(ctx context.Context, projectID, actorID string) ([]domain.Escalation, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if len(e.Config.Project.Escalation) == 0 {
+		return nil, nil
+	}
+	if err := e.requireWritable("task.escalate"); err != nil {
+		return nil, err
+	}
+	now := e.now().UTC()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	due, err := e.dueEscalationsTx(ctx, tx, projectID, now)
+	if err != nil {
+		return nil, err
+	}
+	stamp := now.Format(time.RFC3339)
+	for _, esc := range due {
+		if err := e.Repo.EscalateTaskTx(ctx, tx, esc.TaskID, esc.To, stamp); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, TaskEscalatedEvent, projectID, "task", esc.TaskID, actorID, events.EventPayload{
+			"from":        esc.From,
+			"to":          esc.To,
+			"type":        esc.Type,
+			"status":      esc.Status,
+			"assignee_id": esc.AssigneeID,
+			"since":       esc.Since,
+			"after_days":  esc.AfterDays,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// dueEscalationsTx returns the escalations due at now: open tasks whose rule's
+// wait has passed and whose priority is still below the rule's ceiling.
+func (e Engine) dueEscalationsTx(ctx context.Context, tx *sql.Tx, projectID string, now time.Time) ([]domain.Escalation, error) {
+	rules := e.Config.Project.Escalation
+	if len(rules) == 0 {
+		return []domain.Escalation{}, nil
+	}
+	shortest := rules[0].After()
+	for _, rule := range rules[1:] {
+		shortest = min(shortest, rule.After())
+	}
+	candidates, err := e.Repo.EscalationCandidatesTx(ctx, tx, projectID, now.Add(-shortest).Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	due := []domain.Escalation{}
+	for _, c := range candidates {
+		rule, ok := e.Config.EscalationFor(c.Type)
+		if !ok || c.From <= rule.Ceiling() || c.Since >= now.Add(-rule.After()).Format(time.RFC3339) {
+			continue
+		}
+		c.To = c.From - 1
+		c.AfterDays = rule.AfterDays
+		due = append(due, c)
+	}
+	return due, nil
+}
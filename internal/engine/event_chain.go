@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+const eventChainPageSize = 500
+
+// EventChainOptions selects the chain to verify. Anchor is a hash published
+// earlier, such as a notarized head; verification fails unless an event of
+// the chain still has it.
+type EventChainOptions struct {
+	ProjectID string
+	ActorID   string
+	Anchor    string
+}
+
+// VerifyEventChain recomputes the hash of every event of a project and
+// checks that each links to the one before it. Events removed by a
+// compaction are the only gaps allowed; events written before hashing was
+// introduced are counted but cannot be checked.
+func (e Engine) VerifyEventChain(ctx context.Context, opts EventChainOptions) (domain.EventChainReport, error) {
+	report := domain.EventChainReport{ProjectID: opts.ProjectID, Head: domain.EventChainHead{ProjectID: opts.ProjectID}, Breaks: []domain.EventChainBreak{}}
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
+		return report, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.events.read"); err != nil {
+		return report, err
+	}
+	compacted, err := e.Repo.CompactedRangesTx(ctx, tx, opts.ProjectID)
+	if err != nil {
+		return report, err
+	}
+	// gap reports whether a compaction removed events between two ids.
+	gap := func(afterID, beforeID int64) bool {
+		for _, r := range compacted {
+			if r[0] < beforeID && r[1] > afterID {
+				return true
+			}
+		}
+		return false
+	}
+	hashedAs, err := e.chainHashedAs(ctx, tx, opts.ProjectID)
+	if err != nil {
+		return report, err
+	}
+	var last domain.Event
+	anchored := false
+	for afterID := int64(0); ; {
+		page, err := e.Repo.EventChainPageTx(ctx, tx, opts.ProjectID, afterID, eventChainPageSize)
+		if err != nil {
+			return report, err
+		}
+		for _, evt := range page {
+			afterID = evt.ID
+			if evt.Hash == "" {
+				if last.Hash != "" {
+					report.Breaks = append(report.Breaks, domain.EventChainBreak{EventID: evt.ID, Reason: "unhashed event after the chain started"})
+				} else {
+					report.Unhashed++
+				}
+				continue
+			}
+			report.Checked++
+			if events.ChainHash(evt.PrevHash, hashedAs(evt)) != evt.Hash {
+				report.Breaks = append(report.Breaks, domain.EventChainBreak{EventID: evt.ID, Reason: "hash does not match the event's content"})
+			}
+			if evt.PrevHash != last.Hash {
+				if gap(last.ID, evt.ID) {
+					report.Compacted++
+				} else if last.Hash == "" {
+					report.Breaks = append(report.Breaks, domain.EventChainBreak{EventID: evt.ID, Reason: "links to an event missing from the log"})
+				} else {
+					report.Breaks = append(report.Breaks, domain.EventChainBreak{EventID: evt.ID, Reason: fmt.Sprintf("does not link to event %d", last.ID)})
+				}
+			}
+			if opts.Anchor != "" && evt.Hash == opts.Anchor {
+				anchored = true
+			}
+			last = evt
+		}
+		if len(page) < eventChainPageSize {
+			break
+		}
+	}
+	if last.Hash != "" {
+		report.Head = domain.EventChainHead{ProjectID: opts.ProjectID, EventID: last.ID, TS: last.TS, Hash: last.Hash}
+	}
+	if opts.Anchor != "" && !anchored {
+		report.Breaks = append(report.Breaks, domain.EventChainBreak{Reason: fmt.Sprintf("anchor %s is not in the chain", opts.Anchor)})
+	}
+	report.Valid = len(report.Breaks) == 0
+	return report, nil
+}
+
+// chainHashedAs returns how a project's events were hashed: a renamed
+// project's older events under its former ids.
+func (e Engine) chainHashedAs(ctx context.Context, tx *sql.Tx, projectID string) (func(domain.Event) domain.Event, error) {
+	if projectID == "" {
+		return func(evt domain.Event) domain.Event { return evt }, nil
+	}
+	aliases, err := e.Repo.ProjectAliasesTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return func(evt domain.Event) domain.Event {
+		for _, a := range aliases {
+			if evt.ID <= a.LastEventID {
+				evt.ProjectID = a.Alias
+				break
+			}
+		}
+		return evt
+	}, nil
+}
+
+// rechainEventsTx recomputes the hashes of a project's events from fromID on,
+// after their content was rewritten. Links into events removed by a
+// compaction are kept as they are.
+func (e Engine) rechainEventsTx(ctx context.Context, tx *sql.Tx, projectID string, fromID int64) error {
+	hashedAs, err := e.chainHashedAs(ctx, tx, projectID)
+	if err != nil {
+		return err
+	}
+	var oldLast, newLast string
+	for afterID := fromID - 1; ; {
+		page, err := e.Repo.EventChainPageTx(ctx, tx, projectID, afterID, eventChainPageSize)
+		if err != nil {
+			return err
+		}
+		for _, evt := range page {
+			afterID = evt.ID
+			if evt.Hash == "" {
+				continue
+			}
+			prev := evt.PrevHash
+			if oldLast != "" && prev == oldLast {
+				prev = newLast
+			}
+			hash := events.ChainHash(prev, hashedAs(evt))
+			if prev != evt.PrevHash || hash != evt.Hash {
+				if err := e.Repo.SetEventHashTx(ctx, tx, evt.ID, prev, hash); err != nil {
+					return err
+				}
+			}
+			oldLast, newLast = evt.Hash, hash
+		}
+		if len(page) < eventChainPageSize {
+			return nil
+		}
+	}
+}
+
+// EventChainHead returns the latest hashed event of a project, for an
+// auditor to record. It needs project.events.read.
+func (e Engine) EventChainHead(ctx context.Context, projectID, actorID string) (domain.EventChainHead, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.EventChainHead{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.EventChainHead{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.events.read"); err != nil {
+		return domain.EventChainHead{}, err
+	}
+	head, err := e.Repo.EventChainHeadTx(ctx, tx, projectID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return head, fmt.Errorf("project %s has no hashed events: %w", projectID, repo.ErrNotFound)
+	}
+	return head, err
+}
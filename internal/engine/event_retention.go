@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// CompactEventsOptions selects the project whose event log is compacted.
+type CompactEventsOptions struct {
+	ProjectID string
+	ActorID   string
+	// ArchivePath receives the removed events as gzip-compressed NDJSON. It
+	// must not exist yet; required unless DryRun.
+	ArchivePath string
+	DryRun      bool
+}
+
+// CompactEvents applies project.event_retention: events outside it are
+// written to the archive and then deleted. Exempt types are kept whatever
+// their age. The compaction itself is recorded as an events.compacted event.
+func (e Engine) CompactEvents(ctx context.Context, opts CompactEventsOptions) (domain.EventCompaction, error) {
+	if e.Config == nil || !e.Config.Project.EventRetention.Enabled() {
+		return domain.EventCompaction{}, errors.New("no event retention configured; set project.event_retention.max_age_days or max_rows")
+	}
+	if !opts.DryRun && opts.ArchivePath == "" {
+		return domain.EventCompaction{}, errors.New("archive path is required")
+	}
+	retention := e.Config.Project.EventRetention
+	res := domain.EventCompaction{
+		ProjectID: opts.ProjectID,
+		MaxRows:   retention.MaxRows,
+		Exempt:    retention.ExemptTypes(),
+		DryRun:    opts.DryRun,
+	}
+	if retention.MaxAgeDays > 0 {
+		res.Before = e.now().UTC().Add(-time.Duration(retention.MaxAgeDays) * 24 * time.Hour).Format(time.RFC3339)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.EventCompaction{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.events.compact"); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	evts, err := e.Repo.CompactableEventsTx(ctx, tx, opts.ProjectID, res.Before, retention.MaxRows, res.Exempt)
+	if err != nil {
+		return domain.EventCompaction{}, err
+	}
+	res.Count = len(evts)
+	if len(evts) > 0 {
+		res.FirstID = evts[0].ID
+		res.LastID = evts[len(evts)-1].ID
+	}
+	if opts.DryRun || len(evts) == 0 {
+		return res, nil
+	}
+	// The archive is complete and synced before anything is deleted; if the
+	// delete does not commit, the archive is removed again.
+	if err := writeEventArchive(opts.ArchivePath, evts); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			os.Remove(opts.ArchivePath)
+		}
+	}()
+	res.Archive = opts.ArchivePath
+	ids := make([]int64, len(evts))
+	for i, evt := range evts {
+		ids[i] = evt.ID
+	}
+	if err := e.Repo.DeleteEventsTx(ctx, tx, ids); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	if err := e.Repo.InsertEventCompactionTx(ctx, tx, opts.ProjectID, res.FirstID, res.LastID, e.now().UTC().Format(time.RFC3339)); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "events.compacted", opts.ProjectID, "project", opts.ProjectID, opts.ActorID, events.EventPayload{
+		"count":    res.Count,
+		"first_id": res.FirstID,
+		"last_id":  res.LastID,
+		"before":   res.Before,
+		"max_rows": res.MaxRows,
+		"archive":  res.Archive,
+	}); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.EventCompaction{}, err
+	}
+	committed = true
+	return res, nil
+}
+
+func writeEventArchive(path string, evts []domain.Event) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, evt := range evts {
+		if err = enc.Encode(evt); err != nil {
+			break
+		}
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/blob"
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// EvidenceTooLargeError is returned when an upload exceeds evidence.max_bytes.
+type EvidenceTooLargeError struct {
+	Limit int64
+}
+
+func (e EvidenceTooLargeError) Error() string {
+	return fmt.Sprintf("evidence too large: limit is %d bytes", e.Limit)
+}
+
+// EvidenceOptions describes a file attached to an attestation.
+type EvidenceOptions struct {
+	ProjectID     string
+	AttestationID string
+	Name          string
+	ContentType   string
+	Body          io.Reader
+	ActorID       string
+}
+
+// AddEvidence stores a file in the blob store and records it against the attestation.
+func (e Engine) AddEvidence(ctx context.Context, opts EvidenceOptions) (domain.Evidence, error) {
+	if e.Blobs == nil {
+		return domain.Evidence{}, errors.New("evidence store not configured")
+	}
+	if opts.Body == nil {
+		return domain.Evidence{}, errors.New("evidence file is required")
+	}
+	name := path.Base(strings.ReplaceAll(strings.TrimSpace(opts.Name), "\\", "/"))
+	if name == "" || name == "." || name == "/" {
+		name = "evidence"
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	limit := e.Config.EvidenceMaxBytes()
+	data, err := io.ReadAll(io.LimitReader(opts.Body, limit+1))
+	if err != nil {
+		return domain.Evidence{}, err
+	}
+	if int64(len(data)) > limit {
+		return domain.Evidence{}, EvidenceTooLargeError{Limit: limit}
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Evidence{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "attestation.add"); err != nil {
+		return domain.Evidence{}, err
+	}
+	att, err := e.Repo.GetAttestationTx(ctx, tx, opts.AttestationID)
+	if err != nil {
+		return domain.Evidence{}, err
+	}
+	if att.ProjectID != opts.ProjectID {
+		return domain.Evidence{}, repo.ErrNotFound
+	}
+	sum := sha256.Sum256(data)
+	ev := domain.Evidence{
+		ID:            uuid.NewString(),
+		AttestationID: att.ID,
+		ProjectID:     att.ProjectID,
+		Name:          name,
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		SHA256:        hex.EncodeToString(sum[:]),
+		CreatedBy:     opts.ActorID,
+		CreatedAt:     e.now().UTC().Format(time.RFC3339),
+	}
+	ev.StorageKey = path.Join(att.ProjectID, att.ID, ev.ID)
+	if err := e.Repo.InsertEvidenceTx(ctx, tx, ev); err != nil {
+		return domain.Evidence{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "attestation.evidence_added", att.ProjectID, "attestation", att.ID, opts.ActorID, events.EventPayload{
+		"evidence_id": ev.ID,
+		"name":        ev.Name,
+		"size":        ev.Size,
+		"sha256":      ev.SHA256,
+	}); err != nil {
+		return domain.Evidence{}, err
+	}
+	if err := e.Blobs.Put(ctx, ev.StorageKey, bytes.NewReader(data), ev.Size, ev.ContentType); err != nil {
+		return domain.Evidence{}, fmt.Errorf("store evidence: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		_ = e.Blobs.Delete(ctx, ev.StorageKey)
+		return domain.Evidence{}, err
+	}
+	return ev, nil
+}
+
+// ListEvidence returns the files attached to an attestation.
+func (e Engine) ListEvidence(ctx context.Context, projectID, attestationID, actorID string) ([]domain.Evidence, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "attestation.list"); err != nil {
+		return nil, err
+	}
+	att, err := e.Repo.GetAttestationTx(ctx, tx, attestationID)
+	if err != nil {
+		return nil, err
+	}
+	if att.ProjectID != projectID {
+		return nil, repo.ErrNotFound
+	}
+	return e.Repo.ListEvidenceTx(ctx, tx, attestationID)
+}
+
+// OpenEvidence returns the evidence metadata and a reader over its content.
+// The caller must close the reader.
+func (e Engine) OpenEvidence(ctx context.Context, projectID, attestationID, evidenceID, actorID string) (domain.Evidence, io.ReadCloser, error) {
+	if e.Blobs == nil {
+		return domain.Evidence{}, nil, errors.New("evidence store not configured")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Evidence{}, nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "attestation.list"); err != nil {
+		return domain.Evidence{}, nil, err
+	}
+	ev, err := e.Repo.GetEvidenceTx(ctx, tx, evidenceID)
+	if err != nil {
+		return domain.Evidence{}, nil, err
+	}
+	if ev.ProjectID != projectID || ev.AttestationID != attestationID {
+		return domain.Evidence{}, nil, repo.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Evidence{}, nil, err
+	}
+	rc, err := e.Blobs.Get(ctx, ev.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		return domain.Evidence{}, nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return domain.Evidence{}, nil, err
+	}
+	return ev, rc, nil
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+var externalSystemPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// validateExternalRefs checks system names and ids. Updates pass allowEmpty,
+// where an empty id removes the system's id.
+func validateExternalRefs(refs map[string]string, allowEmpty bool) error {
+	for system, id := range refs {
+		if !externalSystemPattern.MatchString(system) {
+			return fmt.Errorf("invalid external system %q: use lowercase letters, digits, '.', '_' or '-'", system)
+		}
+		if strings.TrimSpace(id) != id {
+			return fmt.Errorf("invalid external id %q for %s: surrounding spaces", id, system)
+		}
+		if id == "" && !allowEmpty {
+			return fmt.Errorf("external id for %s is required", system)
+		}
+	}
+	return nil
+}
+
+// setExternalRefsTx links an entity to its ids in external systems; an empty
+// id unlinks the system. An id already linked to another entity of the
+// project is rejected.
+func (e Engine) setExternalRefsTx(ctx context.Context, tx *sql.Tx, projectID, entityKind, entityID string, refs map[string]string) error {
+	now := e.now().UTC().Format(time.RFC3339)
+	for _, system := range slices.Sorted(maps.Keys(refs)) {
+		id := refs[system]
+		if id == "" {
+			if err := e.Repo.DeleteExternalRefTx(ctx, tx, projectID, system, entityKind, entityID); err != nil {
+				return err
+			}
+			continue
+		}
+		ref, err := e.Repo.GetExternalRefTx(ctx, tx, projectID, system, id)
+		switch {
+		case err == nil && ref.EntityKind == entityKind && ref.EntityID == entityID:
+			continue
+		case err == nil:
+			return fmt.Errorf("external ref %s %s already exists on %s %s", system, id, ref.EntityKind, ref.EntityID)
+		case !errors.Is(err, repo.ErrNotFound):
+			return err
+		}
+		if err := e.Repo.SetExternalRefTx(ctx, tx, domain.ExternalRef{
+			ProjectID:  projectID,
+			Source:     system,
+			ExternalID: id,
+			EntityKind: entityKind,
+			EntityID:   entityID,
+			ImportedAt: now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeExternalRefs applies an update to an entity's current refs.
+func mergeExternalRefs(current, update map[string]string) map[string]string {
+	merged := maps.Clone(current)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for system, id := range update {
+		if id == "" {
+			delete(merged, system)
+		} else {
+			merged[system] = id
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// TaskByExternalRef returns the project's task linked to an id in an
+// external system.
+func (e Engine) TaskByExternalRef(ctx context.Context, projectID, system, externalID string) (domain.Task, error) {
+	ref, err := e.Repo.GetExternalRef(ctx, projectID, system, externalID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if ref.EntityKind != "task" {
+		return domain.Task{}, repo.ErrNotFound
+	}
+	return e.Repo.GetTask(ctx, ref.EntityID)
+}
@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"context"
+	"errors"
+)
+
+// EncryptFields seals the attestation payloads, work outcomes and decision
+// context stored in the clear or with a previous key, using the current
+// encryption key. It returns how many values it rewrote.
+func (e Engine) EncryptFields(ctx context.Context) (int, error) {
+	if err := e.requireWritable("db.encrypt_fields"); err != nil {
+		return 0, err
+	}
+	if e.Config == nil || !e.Config.Encryption.Enabled() {
+		return 0, errors.New("encryption is not configured; set encryption.key or encryption.kms_command")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	n, err := e.Repo.ResealFieldsTx(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ForcePendingError is returned instead of running a forced operation when
+// force.require_approval is set; the operation waits as a force request.
+type ForcePendingError struct {
+	RequestID string
+	Operation string
+}
+
+func (e ForcePendingError) Error() string {
+	return fmt.Sprintf("%s with force needs approval: force request %s is pending", e.Operation, e.RequestID)
+}
+
+// ForceApprovalError rejects an approval: Reason is "self_approval" when the
+// requester tries to approve, or "not_pending" when the request was already
+// approved.
+type ForceApprovalError struct {
+	RequestID string
+	Reason    string
+}
+
+func (e ForceApprovalError) Error() string {
+	if e.Reason == "self_approval" {
+		return fmt.Sprintf("force request %s must be approved by another actor", e.RequestID)
+	}
+	return fmt.Sprintf("force request %s is not pending", e.RequestID)
+}
+
+// forceApprovedKey marks the context of an approved force request being run,
+// so the operation proceeds instead of asking for approval again.
+type forceApprovedKey struct{}
+
+type taskDoneParams struct {
+	WorkOutcomesJSON string `json:"work_outcomes_json"`
+}
+
+type iterationStatusParams struct {
+	Status string `json:"status"`
+}
+
+// forceNeedsApproval reports whether a forced operation must be recorded as a
+// request rather than run.
+func (e Engine) forceNeedsApproval(ctx context.Context) bool {
+	return e.Config != nil && e.Config.Project.Force.RequireApproval && ctx.Value(forceApprovedKey{}) == nil
+}
+
+// requestForce records a pending force request for operation and returns the
+// ForcePendingError the caller hands back. The requester needs force.use.
+func (e Engine) requestForce(ctx context.Context, projectID, operation, entityKind, entityID string, params any, actorID string) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	fr := domain.ForceRequest{
+		ID:          uuid.NewString(),
+		ProjectID:   projectID,
+		Operation:   operation,
+		EntityKind:  entityKind,
+		EntityID:    entityID,
+		ParamsJSON:  string(raw),
+		Status:      "pending",
+		RequestedBy: actorID,
+		RequestedAt: e.now().UTC().Format(time.RFC3339),
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "force.use"); err != nil {
+		return err
+	}
+	if err := e.Repo.InsertForceRequestTx(ctx, tx, fr); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "force.requested", projectID, "rbac", fr.ID, actorID, events.EventPayload{
+		"operation":   operation,
+		"entity_kind": entityKind,
+		"entity_id":   entityID,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return ForcePendingError{RequestID: fr.ID, Operation: operation}
+}
+
+// ListForceRequests returns a project's force requests, oldest first, limited
+// to status when it is set.
+func (e Engine) ListForceRequests(ctx context.Context, projectID, status, actorID string) ([]domain.ForceRequest, error) {
+	if status != "" && status != "pending" && status != "approved" {
+		return nil, fmt.Errorf("invalid force request status %s", status)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "force.approve"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListForceRequestsTx(ctx, tx, projectID, status)
+}
+
+// ApproveForce runs a pending force request of the project as its requester,
+// then marks it approved by actorID. The approver needs force.approve and must
+// not be the requester. A request whose operation fails stays pending.
+func (e Engine) ApproveForce(ctx context.Context, projectID, requestID, actorID string) (domain.ForceRequest, error) {
+	fr, err := e.Repo.GetForceRequest(ctx, requestID)
+	if err != nil {
+		return fr, err
+	}
+	if fr.ProjectID != projectID {
+		return domain.ForceRequest{}, repo.ErrNotFound
+	}
+	if err := e.checkForceApprover(ctx, fr, actorID); err != nil {
+		return fr, err
+	}
+	if err := e.runForceRequest(context.WithValue(ctx, forceApprovedKey{}, fr.ID), fr); err != nil {
+		return fr, fmt.Errorf("force request %s: %w", fr.ID, err)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fr, err
+	}
+	defer tx.Rollback()
+	ok, err := e.Repo.ApproveForceRequestTx(ctx, tx, fr.ID, actorID, e.now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fr, err
+	}
+	if !ok {
+		return fr, ForceApprovalError{RequestID: fr.ID, Reason: "not_pending"}
+	}
+	if err := e.Events.Append(ctx, tx, "force.approved", fr.ProjectID, "rbac", fr.ID, actorID, events.EventPayload{
+		"operation":    fr.Operation,
+		"entity_kind":  fr.EntityKind,
+		"entity_id":    fr.EntityID,
+		"requested_by": fr.RequestedBy,
+	}); err != nil {
+		return fr, err
+	}
+	if err := tx.Commit(); err != nil {
+		return fr, err
+	}
+	return e.Repo.GetForceRequest(ctx, fr.ID)
+}
+
+func (e Engine) checkForceApprover(ctx context.Context, fr domain.ForceRequest, actorID string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, fr.ProjectID, actorID, "force.approve"); err != nil {
+		return err
+	}
+	if fr.Status != "pending" {
+		return ForceApprovalError{RequestID: fr.ID, Reason: "not_pending"}
+	}
+	if fr.RequestedBy == actorID {
+		return ForceApprovalError{RequestID: fr.ID, Reason: "self_approval"}
+	}
+	return nil
+}
+
+// runForceRequest replays the recorded operation with force, as the actor who
+// requested it.
+func (e Engine) runForceRequest(ctx context.Context, fr domain.ForceRequest) error {
+	switch fr.Operation {
+	case "task.update":
+		var opts TaskUpdateOptions
+		if err := json.Unmarshal([]byte(fr.ParamsJSON), &opts); err != nil {
+			return err
+		}
+		opts.ID, opts.ActorID, opts.Force = fr.EntityID, fr.RequestedBy, true
+		_, err := e.UpdateTask(ctx, opts)
+		return err
+	case "task.bulk_update":
+		var opts BulkTaskUpdateOptions
+		if err := json.Unmarshal([]byte(fr.ParamsJSON), &opts); err != nil {
+			return err
+		}
+		opts.ProjectID, opts.ActorID, opts.Force = fr.ProjectID, fr.RequestedBy, true
+		_, err := e.BulkUpdateTasks(ctx, opts)
+		return err
+	case "task.done":
+		var p taskDoneParams
+		if err := json.Unmarshal([]byte(fr.ParamsJSON), &p); err != nil {
+			return err
+		}
+		_, err := e.TaskDone(ctx, fr.EntityID, p.WorkOutcomesJSON, fr.RequestedBy, true)
+		return err
+	case "iteration.set_status":
+		var p iterationStatusParams
+		if err := json.Unmarshal([]byte(fr.ParamsJSON), &p); err != nil {
+			return err
+		}
+		_, err := e.SetIterationStatus(ctx, fr.EntityID, p.Status, fr.RequestedBy, true)
+		return err
+	default:
+		return fmt.Errorf("unknown force operation %s", fr.Operation)
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// GateOptions are the conditions Gate checks on a task.
+type GateOptions struct {
+	TaskID string
+	// Statuses passes when the task is in any of them; empty skips the check.
+	Statuses []string
+	// Require lists attestation kinds the task needs an unexpired
+	// attestation of.
+	Require []string
+	// Policy also requires every attestation of the task's own policy.
+	Policy  bool
+	ActorID string
+}
+
+// Gate checks a task against the statuses and attestations a pipeline
+// requires, without changing anything. Unmet conditions are reported in the
+// result, not as an error. Needs task.validation.read.
+func (e Engine) Gate(ctx context.Context, opts GateOptions) (domain.GateResult, error) {
+	if e.Config == nil {
+		return domain.GateResult{}, errors.New("config not loaded")
+	}
+	t, err := e.Repo.GetTask(ctx, opts.TaskID)
+	if err != nil {
+		return domain.GateResult{}, err
+	}
+	workflow := e.workflow(t.Type)
+	for _, status := range opts.Statuses {
+		if !workflow.HasState(status) {
+			return domain.GateResult{}, fmt.Errorf("invalid task status %s for task type %s", status, t.Type)
+		}
+	}
+	atts, err := e.Repo.ListAttestations(ctx, repo.AttestationFilters{EntityKind: "task", EntityID: t.ID, ProjectID: t.ProjectID})
+	if err != nil {
+		return domain.GateResult{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.GateResult{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.validation.read"); err != nil {
+		return domain.GateResult{}, err
+	}
+	res := domain.GateResult{TaskID: t.ID, Title: t.Title, Status: t.Status, Failures: []string{}}
+	if len(opts.Statuses) > 0 && !slices.Contains(opts.Statuses, t.Status) {
+		res.Failures = append(res.Failures, fmt.Sprintf("status is %s, want %s", t.Status, strings.Join(opts.Statuses, " or ")))
+	}
+	required := slices.Clone(opts.Require)
+	if opts.Policy && t.RequiredAttestationsJSON != nil {
+		var policy []string
+		if err := json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &policy); err != nil {
+			return domain.GateResult{}, err
+		}
+		for _, kind := range policy {
+			if !slices.Contains(required, kind) {
+				required = append(required, kind)
+			}
+		}
+	}
+	now := e.now()
+	found, expired := map[string]bool{}, map[string]bool{}
+	for _, att := range atts {
+		if e.AttestationExpired(att, now) {
+			expired[att.Kind] = true
+		} else {
+			found[att.Kind] = true
+		}
+	}
+	for _, kind := range required {
+		switch {
+		case found[kind]:
+		case expired[kind]:
+			res.Failures = append(res.Failures, fmt.Sprintf("attestation %s expired", kind))
+		default:
+			res.Failures = append(res.Failures, fmt.Sprintf("attestation %s missing", kind))
+		}
+	}
+	res.Passed = len(res.Failures) == 0
+	return res, nil
+}
@@ -0,0 +1,339 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// Transition describes a status change that hooks react to.
+type Transition struct {
+	EntityKind string `json:"entity_kind"`
+	EntityID   string `json:"entity_id"`
+	ProjectID  string `json:"project_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ActorID    string `json:"actor_id"`
+}
+
+// HookFunc implements a hook. It runs inside the transition's transaction and
+// returns details recorded on the hook.executed event. Hooks reaching outside
+// the database, such as webhook, only queue work there for after the commit.
+type HookFunc func(ctx context.Context, tx *sql.Tx, e Engine, tr Transition, hook config.HookConfig) (events.EventPayload, error)
+
+// hookLibrary maps the `run` value of project.hooks entries to implementations.
+var hookLibrary = map[string]HookFunc{
+	"assign_reviewer": assignReviewerHook,
+	"create_task":     createTaskHook,
+	"webhook":         webhookHook,
+}
+
+// defaultHookTimeout bounds a webhook hook post.
+const defaultHookTimeout = 5 * time.Second
+
+// runTransitionHooks runs the configured hooks matching a transition. Each hook
+// runs in a savepoint: a failing hook is rolled back and recorded as
+// hook.failed, unless it is required, in which case the transition fails.
+func (e Engine) runTransitionHooks(ctx context.Context, tx *sql.Tx, tr Transition) error {
+	if e.Config == nil || tr.From == tr.To {
+		return nil
+	}
+	for i, hook := range e.Config.Project.Hooks {
+		if hook.On != tr.EntityKind || (hook.From != "" && hook.From != tr.From) || (hook.To != "" && hook.To != tr.To) {
+			continue
+		}
+		label := hook.HookLabel(i)
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT hook`); err != nil {
+			return err
+		}
+		result, err := runHook(ctx, tx, e, tr, hook)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO hook`); rbErr != nil {
+				return rbErr
+			}
+			if _, relErr := tx.ExecContext(ctx, `RELEASE hook`); relErr != nil {
+				return relErr
+			}
+			if hook.Required {
+				return fmt.Errorf("hook %s failed: %w", label, err)
+			}
+			if err := e.Events.Append(ctx, tx, "hook.failed", tr.ProjectID, tr.EntityKind, tr.EntityID, tr.ActorID, events.EventPayload{
+				"hook":  label,
+				"error": err.Error(),
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE hook`); err != nil {
+			return err
+		}
+		payload := events.EventPayload{"hook": label, "from": tr.From, "to": tr.To}
+		for k, v := range result {
+			payload[k] = v
+		}
+		if err := e.Events.Append(ctx, tx, "hook.executed", tr.ProjectID, tr.EntityKind, tr.EntityID, tr.ActorID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runHook(ctx context.Context, tx *sql.Tx, e Engine, tr Transition, hook config.HookConfig) (events.EventPayload, error) {
+	fn, ok := hookLibrary[hook.Run]
+	if !ok {
+		return nil, fmt.Errorf("unknown hook %s", hook.Run)
+	}
+	return fn(ctx, tx, e, tr, hook)
+}
+
+// assignReviewerHook assigns the task to the pool member with the fewest tasks
+// in review, skipping the actor who made the transition.
+func assignReviewerHook(ctx context.Context, tx *sql.Tx, e Engine, tr Transition, hook config.HookConfig) (events.EventPayload, error) {
+	if tr.EntityKind != "task" {
+		return nil, errors.New("assign_reviewer only applies to tasks")
+	}
+	pool := hookStrings(hook.With["pool"])
+	candidates := make([]string, 0, len(pool))
+	for _, actor := range pool {
+		if actor != tr.ActorID {
+			candidates = append(candidates, actor)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("assign_reviewer requires with.pool with someone other than the actor")
+	}
+	load, err := e.Repo.ReviewLoadTx(ctx, tx, tr.ProjectID, tr.EntityID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	chosen := candidates[0]
+	for _, actor := range candidates[1:] {
+		if load[actor] < load[chosen] {
+			chosen = actor
+		}
+	}
+	if err := e.ensureActor(ctx, tx, chosen); err != nil {
+		return nil, err
+	}
+	if err := e.Repo.SetTaskAssigneeTx(ctx, tx, tr.EntityID, chosen, e.now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return events.EventPayload{"assignee_id": chosen}, nil
+}
+
+// createTaskHook creates a follow-up task in the iteration of the entity.
+// with.title may reference {entity_id} and {to}; with.type defaults to technical.
+func createTaskHook(ctx context.Context, tx *sql.Tx, e Engine, tr Transition, hook config.HookConfig) (events.EventPayload, error) {
+	title, _ := hook.With["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		return nil, errors.New("create_task requires with.title")
+	}
+	title = strings.NewReplacer("{entity_id}", tr.EntityID, "{to}", tr.To).Replace(title)
+	taskType, _ := hook.With["type"].(string)
+	if taskType == "" {
+		taskType = "technical"
+	}
+	if !e.Config.AllowedTaskTypes()[taskType] {
+		return nil, fmt.Errorf("unknown task type %s", taskType)
+	}
+	var iterationID *string
+	switch tr.EntityKind {
+	case "iteration":
+		iterationID = &tr.EntityID
+	case "task":
+		source, err := e.Repo.GetTaskTx(ctx, tx, tr.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		iterationID = source.IterationID
+	}
+	var reqJSON *string
+	if policyName := e.Config.DefaultTaskPolicyName(taskType); policyName != "" {
+		if policy, ok := e.Config.TaskPolicy(taskType, policyName); ok {
+			var err error
+			if reqJSON, err = marshalStringSlice(policy.All); err != nil {
+				return nil, err
+			}
+		}
+	}
+	now := e.now().UTC().Format(time.RFC3339)
+	id, err := e.newEntityID(ctx, tx, e.Config, tr.ProjectID, "task", title+"|"+tr.EntityID, now)
+	if err != nil {
+		return nil, err
+	}
+	t := domain.Task{
+		ID:                       id,
+		ProjectID:                tr.ProjectID,
+		IterationID:              iterationID,
+		Type:                     taskType,
+		Title:                    title,
+		Status:                   e.workflow(taskType).Initial,
+		RequiredAttestationsJSON: reqJSON,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+	if err := e.Repo.InsertTask(ctx, tx, t); err != nil {
+		return nil, err
+	}
+	if err := e.Events.Append(ctx, tx, "task.created", t.ProjectID, "task", t.ID, tr.ActorID, events.EventPayload{"title": t.Title, "status": t.Status}); err != nil {
+		return nil, err
+	}
+	return events.EventPayload{"task_id": t.ID}, nil
+}
+
+// webhookHook queues the transition for hook.url. The post waits for the
+// transaction to commit, so a slow receiver does not hold the write lock and
+// a transition that rolls back is never announced; see SendHookDeliveries.
+func webhookHook(ctx context.Context, tx *sql.Tx, e Engine, tr Transition, hook config.HookConfig) (events.EventPayload, error) {
+	data, err := json.Marshal(map[string]any{"hook": hook.Name, "transition": tr, "with": hook.With})
+	if err != nil {
+		return nil, err
+	}
+	now := e.now().UTC().Format(time.RFC3339)
+	id, err := e.Repo.InsertHookDeliveryTx(ctx, tx, domain.HookDelivery{
+		ProjectID:     tr.ProjectID,
+		Hook:          hook.Name,
+		URL:           hook.URL,
+		Event:         tr.EntityKind + "." + tr.To,
+		Body:          string(data),
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events.EventPayload{"delivery_id": id, "queued": true}, nil
+}
+
+const (
+	// hookDeliveryBatch is how many queued deliveries one send posts.
+	hookDeliveryBatch = 50
+	// hookDeliveryClaim is how long a send holds deliveries it posts.
+	hookDeliveryClaim = 10 * time.Minute
+	// hookDeliveryMaxBackoff caps the delay between retries.
+	hookDeliveryMaxBackoff = time.Hour
+)
+
+// SendHookDeliveries posts the webhook hooks queued for the engine's project
+// whose next attempt is due, and returns how many were accepted. A failed
+// post is retried later with exponential backoff; a delivery whose hook is
+// no longer configured is dropped. Both are reported in the error.
+func (e Engine) SendHookDeliveries(ctx context.Context) (int, error) {
+	if e.Config == nil || e.ReadOnly {
+		return 0, nil
+	}
+	now := e.now().UTC()
+	queued, err := e.Repo.ClaimHookDeliveries(ctx, e.Config.Project.ID, now.Format(time.RFC3339), now.Add(hookDeliveryClaim).Format(time.RFC3339), hookDeliveryBatch)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, d := range queued {
+		hook, ok := e.webhookHookFor(d.URL)
+		if !ok {
+			if err := e.Repo.DeleteHookDelivery(ctx, d.ID); err != nil {
+				return sent, err
+			}
+			errs = append(errs, fmt.Errorf("delivery %d dropped: no webhook hook posts to %s any more", d.ID, d.URL))
+			continue
+		}
+		if err := postHook(ctx, hook, d); err != nil {
+			backoff := min(time.Duration(1<<min(d.Attempts, 16))*time.Minute, hookDeliveryMaxBackoff)
+			if derr := e.Repo.DeferHookDelivery(ctx, d.ID, err.Error(), now.Add(backoff).Format(time.RFC3339)); derr != nil {
+				return sent, derr
+			}
+			errs = append(errs, fmt.Errorf("delivery %d to %s: %w", d.ID, d.URL, err))
+			continue
+		}
+		if err := e.Repo.DeleteHookDelivery(ctx, d.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// sendHookDeliveries posts what a committed transition queued. Failures stay
+// queued for the next SendHookDeliveries, from wl serve or the next transition.
+func (e Engine) sendHookDeliveries(ctx context.Context) {
+	if e.Config == nil || !slices.ContainsFunc(e.Config.Project.Hooks, func(h config.HookConfig) bool { return h.Run == "webhook" }) {
+		return
+	}
+	_, _ = e.SendHookDeliveries(context.WithoutCancel(ctx))
+}
+
+// webhookHookFor returns the webhook hook posting to url, which lends a queued
+// delivery its current secret and timeout.
+func (e Engine) webhookHookFor(url string) (config.HookConfig, bool) {
+	for _, h := range e.Config.Project.Hooks {
+		if h.Run == "webhook" && h.URL == url {
+			return h, true
+		}
+	}
+	return config.HookConfig{}, false
+}
+
+// postHook posts a queued delivery and fails on a non-2xx reply. Receivers
+// may see a delivery twice and dedupe on X-Workline-Delivery.
+func postHook(ctx context.Context, hook config.HookConfig, d domain.HookDelivery) error {
+	timeout := defaultHookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, strings.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Workline-Hook", d.Event)
+	req.Header.Set("X-Workline-Project", d.ProjectID)
+	req.Header.Set("X-Workline-Delivery", "hook-"+strconv.FormatInt(d.ID, 10))
+	if strings.TrimSpace(hook.Secret) != "" {
+		req.Header.Set("X-Workline-Secret", hook.Secret)
+	}
+	res, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func hookStrings(v any) []string {
+	switch vals := v.(type) {
+	case []string:
+		return vals
+	case []any:
+		out := make([]string, 0, len(vals))
+		for _, item := range vals {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+		return out
+	case string:
+		if strings.TrimSpace(vals) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(vals)}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/config"
+)
+
+// sequenceInfixes separate the kinds sharing a project's sequence prefix.
+var sequenceInfixes = map[string]string{"task": "", "iteration": "IT-", "decision": "DEC-"}
+
+// newEntityID makes the id of a new task, iteration or decision following
+// the project's id strategy. The uuid strategy derives it from the project,
+// name and creation time.
+func (e Engine) newEntityID(ctx context.Context, tx *sql.Tx, cfg *config.Config, projectID, kind, name, now string) (string, error) {
+	var ids config.IDsConfig
+	if cfg != nil {
+		ids = cfg.Project.IDs
+	}
+	switch ids.Strategy {
+	case "ulid":
+		return newULID(e.now())
+	case "sequence":
+		prefix := ids.SequencePrefix(projectID) + "-" + sequenceInfixes[kind]
+		// Ids taken by hand are skipped rather than reused.
+		for {
+			n, err := e.Repo.NextIDSequenceTx(ctx, tx, projectID, kind)
+			if err != nil {
+				return "", err
+			}
+			id := fmt.Sprintf("%s%d", prefix, n)
+			taken, err := e.Repo.EntityIDExistsTx(ctx, tx, kind, id)
+			if err != nil {
+				return "", err
+			}
+			if !taken {
+				return id, nil
+			}
+		}
+	default:
+		return uuid.NewSHA1(uuid.NameSpaceOID, []byte(projectID+"|"+name+"|"+now)).String(), nil
+	}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID: 48 bits of milliseconds then 80 random bits, as 26
+// Crockford base32 characters, so ids sort by creation time.
+func newULID(t time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	// 130 bits of output for 128 of input: the first character holds the
+	// top 3 bits.
+	out := make([]byte, 26)
+	var acc uint64
+	bits := 2
+	pos := 0
+	for _, c := range b {
+		acc = acc<<8 | uint64(c)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockford[(acc>>bits)&31]
+			pos++
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// MilestoneOptions creates a milestone. TargetDate is a YYYY-MM-DD date.
+type MilestoneOptions struct {
+	ID           string
+	ProjectID    string
+	Goal         string
+	TargetDate   string
+	IterationIDs []string
+	TaskIDs      []string
+	ActorID      string
+}
+
+// CreateMilestone creates a milestone and links the given iterations and tasks.
+func (e Engine) CreateMilestone(ctx context.Context, opts MilestoneOptions) (domain.Milestone, error) {
+	m := domain.Milestone{
+		ID:         strings.TrimSpace(opts.ID),
+		ProjectID:  opts.ProjectID,
+		Goal:       strings.TrimSpace(opts.Goal),
+		TargetDate: strings.TrimSpace(opts.TargetDate),
+	}
+	if m.ID == "" || m.Goal == "" {
+		return m, errors.New("milestone id and goal are required")
+	}
+	if _, err := time.Parse(time.DateOnly, m.TargetDate); err != nil {
+		return m, fmt.Errorf("invalid target date %q: expected YYYY-MM-DD", opts.TargetDate)
+	}
+	if _, err := e.Repo.GetProject(ctx, m.ProjectID); err != nil {
+		return m, err
+	}
+	m.CreatedAt = e.now().UTC().Format(time.RFC3339)
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return m, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, m.ProjectID, opts.ActorID, "milestone.create"); err != nil {
+		return m, err
+	}
+	if _, err := e.Repo.GetMilestoneTx(ctx, tx, m.ID); err == nil {
+		return m, fmt.Errorf("milestone %s already exists", m.ID)
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return m, err
+	}
+	if err := e.Repo.InsertMilestoneTx(ctx, tx, m); err != nil {
+		return m, err
+	}
+	if err := e.linkMilestone(ctx, tx, m, opts.IterationIDs, opts.TaskIDs); err != nil {
+		return m, err
+	}
+	if err := e.Events.Append(ctx, tx, "milestone.created", m.ProjectID, "milestone", m.ID, opts.ActorID, events.EventPayload{
+		"target_date":   m.TargetDate,
+		"iteration_ids": opts.IterationIDs,
+		"task_ids":      opts.TaskIDs,
+	}); err != nil {
+		return m, err
+	}
+	if m, err = e.Repo.GetMilestoneTx(ctx, tx, m.ID); err != nil {
+		return m, err
+	}
+	if err := tx.Commit(); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// LinkMilestone adds iterations and tasks to an existing milestone.
+func (e Engine) LinkMilestone(ctx context.Context, milestoneID string, iterationIDs, taskIDs []string, actorID string) (domain.Milestone, error) {
+	if len(iterationIDs) == 0 && len(taskIDs) == 0 {
+		return domain.Milestone{}, errors.New("iteration or task ids required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Milestone{}, err
+	}
+	defer tx.Rollback()
+	m, err := e.Repo.GetMilestoneTx(ctx, tx, milestoneID)
+	if err != nil {
+		return m, err
+	}
+	if err := e.requirePermission(ctx, tx, m.ProjectID, actorID, "milestone.create"); err != nil {
+		return m, err
+	}
+	if err := e.linkMilestone(ctx, tx, m, iterationIDs, taskIDs); err != nil {
+		return m, err
+	}
+	if err := e.Events.Append(ctx, tx, "milestone.linked", m.ProjectID, "milestone", m.ID, actorID, events.EventPayload{
+		"iteration_ids": iterationIDs,
+		"task_ids":      taskIDs,
+	}); err != nil {
+		return m, err
+	}
+	if m, err = e.Repo.GetMilestoneTx(ctx, tx, m.ID); err != nil {
+		return m, err
+	}
+	if err := tx.Commit(); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// linkMilestone checks that the iterations and tasks belong to the
+// milestone's project before linking them.
+func (e Engine) linkMilestone(ctx context.Context, tx *sql.Tx, m domain.Milestone, iterationIDs, taskIDs []string) error {
+	for _, id := range iterationIDs {
+		it, err := e.Repo.GetIterationTx(ctx, tx, id)
+		if errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("unknown iteration %q", id)
+		}
+		if err != nil {
+			return err
+		}
+		if it.ProjectID != m.ProjectID {
+			return fmt.Errorf("invalid iteration %q: belongs to project %s", id, it.ProjectID)
+		}
+	}
+	for _, id := range taskIDs {
+		t, err := e.Repo.GetTaskTx(ctx, tx, id)
+		if errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("unknown task %q", id)
+		}
+		if err != nil {
+			return err
+		}
+		if t.ProjectID != m.ProjectID {
+			return fmt.Errorf("invalid task %q: belongs to project %s", id, t.ProjectID)
+		}
+	}
+	return e.Repo.AddMilestoneLinksTx(ctx, tx, m.ID, iterationIDs, taskIDs)
+}
+
+// ListMilestones returns a project's milestones, soonest target date first.
+func (e Engine) ListMilestones(ctx context.Context, projectID, actorID string) ([]domain.Milestone, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "milestone.list"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListMilestonesTx(ctx, tx, projectID)
+}
+
+// MilestoneStatus reports a milestone's progress: done over total tasks, the
+// estimates behind them, and the days left until the target date.
+func (e Engine) MilestoneStatus(ctx context.Context, milestoneID, actorID string) (domain.MilestoneProgress, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.MilestoneProgress{}, err
+	}
+	defer tx.Rollback()
+	m, err := e.Repo.GetMilestoneTx(ctx, tx, milestoneID)
+	if err != nil {
+		return domain.MilestoneProgress{}, err
+	}
+	if err := e.requirePermission(ctx, tx, m.ProjectID, actorID, "milestone.list"); err != nil {
+		return domain.MilestoneProgress{}, err
+	}
+	p, err := e.Repo.MilestoneProgressTx(ctx, tx, m.ID)
+	if err != nil {
+		return p, err
+	}
+	p.MilestoneID = m.ID
+	p.Goal = m.Goal
+	p.TargetDate = m.TargetDate
+	if p.TotalTasks > 0 {
+		p.Percent = math.Round(float64(p.DoneTasks)*1000/float64(p.TotalTasks)) / 10
+	}
+	target, err := time.Parse(time.DateOnly, m.TargetDate)
+	if err != nil {
+		return p, err
+	}
+	now := e.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	p.DaysLeft = int(target.Sub(today).Hours() / 24)
+	p.Overdue = p.DaysLeft < 0 && (p.TotalTasks == 0 || p.DoneTasks < p.TotalTasks)
+	return p, nil
+}
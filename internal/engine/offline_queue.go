@@ -0,0 +1,417 @@
+package engine
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// ErrRemoteUnreachable is wrapped by SyncRemote errors for a server that
+// could not be reached or is temporarily down; remote-mode commands are
+// queued instead of failing.
+var ErrRemoteUnreachable = errors.New("remote unreachable")
+
+// Remote-mode commands.
+const (
+	RemoteTaskCreate = "task.create"
+	RemoteTaskUpdate = "task.update"
+)
+
+// RemoteCommand is a mutating command run against a remote server. Task is
+// the task to create, or carries the values of Fields, a subset of the
+// fields sync exchanges, for an update.
+type RemoteCommand struct {
+	Op     string
+	Task   SyncTask
+	Fields []string
+}
+
+type RemoteOptions struct {
+	ProjectID string
+	ActorID   string
+	// Remote names the server, as in SyncOptions.
+	Remote string
+	Client SyncRemote
+	// Key signs queued commands; replay refuses those it does not verify.
+	Key []byte
+}
+
+// RunRemoteCommand runs cmd on the server, or queues it when the server is
+// unreachable, when earlier commands for it are still pending or when one
+// for the same task is held, so commands reach the server in the order they
+// were issued. It returns the task as the server has it after cmd ran, or
+// the queued command, whose Seq is zero when cmd ran.
+func (e Engine) RunRemoteCommand(ctx context.Context, opts RemoteOptions, cmd RemoteCommand) (SyncTask, domain.QueuedCommand, error) {
+	if err := validateRemoteCommand(&cmd); err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	if opts.Client == nil || opts.Remote == "" {
+		return SyncTask{}, domain.QueuedCommand{}, errors.New("remote required")
+	}
+	if len(opts.Key) == 0 {
+		return SyncTask{}, domain.QueuedCommand{}, errors.New("queue signing key required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.sync"); err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	waiting, err := e.Repo.ListQueuedCommandsTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	for _, c := range waiting {
+		if c.Status == "pending" || c.TaskID == cmd.Task.ID {
+			queued, err := e.queueRemoteCommandTx(ctx, tx, opts, cmd)
+			return SyncTask{}, queued, err
+		}
+	}
+	// The remote call runs without a transaction held.
+	if err := tx.Rollback(); err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	task, err := applyRemoteCommand(ctx, opts.Client, cmd)
+	if !errors.Is(err, ErrRemoteUnreachable) {
+		return task, domain.QueuedCommand{}, err
+	}
+	if tx, err = e.DB.BeginTx(ctx, nil); err != nil {
+		return SyncTask{}, domain.QueuedCommand{}, err
+	}
+	defer tx.Rollback()
+	queued, err := e.queueRemoteCommandTx(ctx, tx, opts, cmd)
+	return SyncTask{}, queued, err
+}
+
+func validateRemoteCommand(cmd *RemoteCommand) error {
+	switch cmd.Op {
+	case RemoteTaskCreate:
+		if cmd.Task.Title == "" || cmd.Task.Type == "" {
+			return errors.New("title and type required")
+		}
+		cmd.Fields = nil
+	case RemoteTaskUpdate:
+		if cmd.Task.ID == "" {
+			return errors.New("task id required")
+		}
+		if len(cmd.Fields) == 0 {
+			return errors.New("nothing to update")
+		}
+		for _, f := range cmd.Fields {
+			if !slices.Contains(syncFields, f) {
+				return fmt.Errorf("field %s cannot be updated remotely", f)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown remote command %q", cmd.Op)
+	}
+	return nil
+}
+
+// queueRemoteCommandTx appends cmd to the remote's queue, updates with the
+// base replay checks them against, signs it and commits. Queued creates get
+// an id, so later commands can name the task.
+func (e Engine) queueRemoteCommandTx(ctx context.Context, tx *sql.Tx, opts RemoteOptions, cmd RemoteCommand) (domain.QueuedCommand, error) {
+	if cmd.Op == RemoteTaskCreate && cmd.Task.ID == "" {
+		cmd.Task.ID = uuid.NewString()
+	}
+	payload, err := json.Marshal(cmd.Task)
+	if err != nil {
+		return domain.QueuedCommand{}, err
+	}
+	c := domain.QueuedCommand{
+		ProjectID: opts.ProjectID,
+		Remote:    opts.Remote,
+		ActorID:   opts.ActorID,
+		Op:        cmd.Op,
+		TaskID:    cmd.Task.ID,
+		Fields:    cmd.Fields,
+		Payload:   string(payload),
+		Status:    "pending",
+		QueuedAt:  e.now().UTC().Format(time.RFC3339),
+	}
+	if cmd.Op == RemoteTaskUpdate {
+		if c.Base, err = e.remoteBaseTx(ctx, tx, opts, cmd.Task.ID); err != nil {
+			return domain.QueuedCommand{}, err
+		}
+	}
+	if c.Seq, err = e.Repo.InsertQueuedCommandTx(ctx, tx, c); err != nil {
+		return domain.QueuedCommand{}, err
+	}
+	c.Signature = signQueuedCommand(opts.Key, c)
+	if err := e.Repo.SignQueuedCommandTx(ctx, tx, c.Seq, c.Signature); err != nil {
+		return domain.QueuedCommand{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.QueuedCommand{}, err
+	}
+	return c, nil
+}
+
+// remoteBaseTx returns the task as the server will have it when the
+// command now queued reaches it: as last agreed with the server, from the
+// sync base or else the local copy, with the commands queued before for the
+// task applied. It returns "" when the task was never seen.
+func (e Engine) remoteBaseTx(ctx context.Context, tx *sql.Tx, opts RemoteOptions, taskID string) (string, error) {
+	var base *SyncTask
+	bases, err := e.Repo.SyncBasesTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return "", err
+	}
+	if raw, ok := bases[taskID]; ok {
+		base = &SyncTask{}
+		if err := json.Unmarshal([]byte(raw), base); err != nil {
+			return "", fmt.Errorf("sync base of task %s: %w", taskID, err)
+		}
+	} else {
+		t, err := e.Repo.GetTaskTx(ctx, tx, taskID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return "", err
+		}
+		if err == nil && t.ProjectID == opts.ProjectID {
+			base = &SyncTask{ID: t.ID, Type: t.Type, Title: t.Title, Description: t.Description, Status: t.Status, AssigneeID: t.AssigneeID, Priority: t.Priority}
+			if t.WorkOutcomesJSON != nil && *t.WorkOutcomesJSON != "" {
+				_ = json.Unmarshal([]byte(*t.WorkOutcomesJSON), &base.WorkOutcomes)
+			}
+		}
+	}
+	queued, err := e.Repo.ListQueuedCommandsTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range queued {
+		if c.TaskID != taskID {
+			continue
+		}
+		var t SyncTask
+		if err := json.Unmarshal([]byte(c.Payload), &t); err != nil {
+			return "", fmt.Errorf("queued command %d: %w", c.Seq, err)
+		}
+		if c.Op == RemoteTaskCreate {
+			base = &t
+			continue
+		}
+		if base == nil {
+			continue
+		}
+		for _, f := range c.Fields {
+			setSyncField(base, t, f)
+		}
+	}
+	if base == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(base)
+	return string(b), err
+}
+
+// signQueuedCommand returns the hex HMAC-SHA256 of a queued command,
+// including its seq, so commands cannot be altered, reordered or replayed
+// once dropped without the key.
+func signQueuedCommand(key []byte, c domain.QueuedCommand) string {
+	msg, _ := json.Marshal(struct {
+		Seq       int64    `json:"seq"`
+		ProjectID string   `json:"project_id"`
+		Remote    string   `json:"remote"`
+		ActorID   string   `json:"actor_id"`
+		Op        string   `json:"op"`
+		TaskID    string   `json:"task_id"`
+		Fields    []string `json:"fields"`
+		Payload   string   `json:"payload"`
+		Base      string   `json:"base"`
+		QueuedAt  string   `json:"queued_at"`
+	}{c.Seq, c.ProjectID, c.Remote, c.ActorID, c.Op, c.TaskID, c.Fields, c.Payload, c.Base, c.QueuedAt})
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// applyRemoteCommand runs cmd on the server and returns the task it
+// created, or cmd's task for an update.
+func applyRemoteCommand(ctx context.Context, client SyncRemote, cmd RemoteCommand) (SyncTask, error) {
+	if cmd.Op == RemoteTaskCreate {
+		return client.CreateTask(ctx, cmd.Task)
+	}
+	return cmd.Task, client.UpdateTask(ctx, cmd.Task.ID, cmd.Fields, cmd.Task)
+}
+
+// QueuedCommands lists the project's offline queue in seq order, optionally
+// only that of one remote.
+func (e Engine) QueuedCommands(ctx context.Context, projectID, actorID, remote string) ([]domain.QueuedCommand, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.sync"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListQueuedCommandsTx(ctx, tx, projectID, remote)
+}
+
+// DropQueuedCommand removes a command from the offline queue without
+// running it, such as one replay held back as a conflict.
+func (e Engine) DropQueuedCommand(ctx context.Context, projectID, actorID string, seq int64) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.sync"); err != nil {
+		return err
+	}
+	if _, err := e.Repo.GetQueuedCommandTx(ctx, tx, projectID, seq); err != nil {
+		return err
+	}
+	if err := e.Repo.DeleteQueuedCommandTx(ctx, tx, seq); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplayQueue runs the remote's queued commands in seq order and drops those
+// the server takes. A command whose signature does not verify, or that the
+// server refuses, is held as rejected and not retried. An update is held as a conflict when
+// the server changed one of its fields since the queued base, a create when
+// the task already exists there; force runs conflicts anyway. Later commands
+// for a held task are held behind it. Replay stops at the first command the
+// server is unreachable for, leaving it and the rest queued.
+func (e Engine) ReplayQueue(ctx context.Context, opts RemoteOptions, force bool) (domain.QueueReplayReport, error) {
+	report := domain.QueueReplayReport{ProjectID: opts.ProjectID, Remote: opts.Remote, Applied: []int64{}, Held: []domain.QueuedCommand{}}
+	if opts.Client == nil || opts.Remote == "" {
+		return report, errors.New("remote required")
+	}
+	if len(opts.Key) == 0 {
+		return report, errors.New("queue signing key required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.sync"); err != nil {
+		return report, err
+	}
+	queued, err := e.Repo.ListQueuedCommandsTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return report, err
+	}
+	// The remote calls below run without a transaction held.
+	if err := tx.Rollback(); err != nil {
+		return report, err
+	}
+	heldTasks := map[string]int64{}
+	for i, c := range queued {
+		status, reason := "", ""
+		if !hmac.Equal([]byte(c.Signature), []byte(signQueuedCommand(opts.Key, c))) {
+			status, reason = "rejected", "signature does not verify"
+		} else if c.Status == "rejected" {
+			status, reason = c.Status, c.Reason
+		} else if seq, ok := heldTasks[c.TaskID]; ok {
+			status, reason = "conflict", fmt.Sprintf("behind held command %d", seq)
+		} else {
+			cmd := RemoteCommand{Op: c.Op, Fields: c.Fields}
+			if err := json.Unmarshal([]byte(c.Payload), &cmd.Task); err != nil {
+				return report, fmt.Errorf("queued command %d: %w", c.Seq, err)
+			}
+			if c.Status != "conflict" || !force {
+				reason, err = queueConflict(ctx, opts.Client, c, cmd)
+				if err != nil {
+					report.Pending = len(queued) - i
+					return report, err
+				}
+			}
+			if reason != "" {
+				status = "conflict"
+			} else {
+				_, err = applyRemoteCommand(ctx, opts.Client, cmd)
+				var rejected SyncRejectedError
+				switch {
+				case errors.As(err, &rejected):
+					status, reason = "rejected", rejected.Reason
+				case err != nil:
+					report.Pending = len(queued) - i
+					return report, err
+				}
+			}
+		}
+		if err := e.settleQueuedCommand(ctx, c.Seq, status, reason); err != nil {
+			return report, err
+		}
+		if status == "" {
+			report.Applied = append(report.Applied, c.Seq)
+			continue
+		}
+		if _, ok := heldTasks[c.TaskID]; !ok {
+			heldTasks[c.TaskID] = c.Seq
+		}
+		c.Status, c.Reason = status, reason
+		report.Held = append(report.Held, c)
+	}
+	return report, nil
+}
+
+// queueConflict reports why a queued command conflicts with the server's
+// current state, "" when it does not.
+func queueConflict(ctx context.Context, client SyncRemote, c domain.QueuedCommand, cmd RemoteCommand) (string, error) {
+	current, err := client.GetTask(ctx, c.TaskID)
+	if errors.Is(err, repo.ErrNotFound) {
+		if c.Op == RemoteTaskUpdate {
+			return "task not found on remote", nil
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if c.Op == RemoteTaskCreate {
+		return "task already exists on remote", nil
+	}
+	if c.Base == "" {
+		return "", nil
+	}
+	var base SyncTask
+	if err := json.Unmarshal([]byte(c.Base), &base); err != nil {
+		return "", fmt.Errorf("queued command %d base: %w", c.Seq, err)
+	}
+	for _, f := range c.Fields {
+		now := syncValue(current, f)
+		if !sameSyncValue(now, syncValue(base, f)) && !sameSyncValue(now, syncValue(cmd.Task, f)) {
+			return fmt.Sprintf("%s changed on remote since the command was queued", f), nil
+		}
+	}
+	return "", nil
+}
+
+// settleQueuedCommand drops a command the server took (empty status) or
+// records why it was held.
+func (e Engine) settleQueuedCommand(ctx context.Context, seq int64, status, reason string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if status == "" {
+		err = e.Repo.DeleteQueuedCommandTx(ctx, tx, seq)
+	} else {
+		err = e.Repo.SetQueuedCommandStatusTx(ctx, tx, seq, status, reason)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
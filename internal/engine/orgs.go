@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/engine/auth"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// Org roles, from most to least privileged.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+func validOrgRole(role string) bool {
+	switch role {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return true
+	}
+	return false
+}
+
+// CreateOrg creates an organization and makes the creator its owner. Like
+// project creation, it is gated by the instance-level org.create permission
+// at the API; the CLI works on a local database it already owns.
+func (e Engine) CreateOrg(ctx context.Context, orgID, name, actorID string) (domain.Org, error) {
+	orgID = strings.TrimSpace(orgID)
+	if orgID == "" {
+		return domain.Org{}, errors.New("org id is required")
+	}
+	if name == "" {
+		name = orgID
+	}
+	if err := e.requireWritable("org.manage"); err != nil {
+		return domain.Org{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Org{}, err
+	}
+	defer tx.Rollback()
+	if err := e.ensureActor(ctx, tx, actorID); err != nil {
+		return domain.Org{}, err
+	}
+	if _, err := e.Repo.GetOrgTx(ctx, tx, orgID); err == nil {
+		return domain.Org{}, fmt.Errorf("org %s already exists", orgID)
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return domain.Org{}, err
+	}
+	org := domain.Org{ID: orgID, Name: name, CreatedAt: e.now().UTC().Format(time.RFC3339)}
+	if err := e.Repo.InsertOrgTx(ctx, tx, org); err != nil {
+		return domain.Org{}, fmt.Errorf("insert org: %w", err)
+	}
+	if err := e.Repo.SetOrgRole(ctx, tx, orgID, actorID, OrgRoleOwner); err != nil {
+		return domain.Org{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "org.created", "", "rbac", orgID, actorID, events.EventPayload{"name": name}); err != nil {
+		return domain.Org{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Org{}, err
+	}
+	e.forgetPermissions(ctx)
+	return org, nil
+}
+
+// OrgRole returns the actor's role in the org, or repo.ErrNotFound when not a member.
+func (e Engine) OrgRole(ctx context.Context, orgID, actorID string) (string, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	return e.Repo.OrgRoleTx(ctx, tx, orgID, actorID)
+}
+
+// SetOrgMember adds an actor to the org or changes their org role.
+func (e Engine) SetOrgMember(ctx context.Context, orgID, actorID, targetActor, role string) (domain.OrgMember, error) {
+	if strings.TrimSpace(targetActor) == "" {
+		return domain.OrgMember{}, errors.New("actor_id is required")
+	}
+	if !validOrgRole(role) {
+		return domain.OrgMember{}, fmt.Errorf("invalid org role %s", role)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.OrgMember{}, err
+	}
+	defer tx.Rollback()
+	if _, err := e.Repo.GetOrgTx(ctx, tx, orgID); err != nil {
+		return domain.OrgMember{}, err
+	}
+	if err := e.requireOrgAdmin(ctx, tx, orgID, actorID); err != nil {
+		return domain.OrgMember{}, err
+	}
+	if err := e.ensureActor(ctx, tx, targetActor); err != nil {
+		return domain.OrgMember{}, err
+	}
+	if current, err := e.Repo.OrgRoleTx(ctx, tx, orgID, targetActor); err == nil && current == OrgRoleOwner && role != OrgRoleOwner {
+		if err := e.ensureAnotherOwner(ctx, tx, orgID); err != nil {
+			return domain.OrgMember{}, err
+		}
+	}
+	if err := e.Repo.SetOrgRole(ctx, tx, orgID, targetActor, role); err != nil {
+		return domain.OrgMember{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "org.member_set", "", "rbac", orgID, actorID, events.EventPayload{"actor_id": targetActor, "role": role}); err != nil {
+		return domain.OrgMember{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.OrgMember{}, err
+	}
+	e.forgetPermissions(ctx)
+	return domain.OrgMember{OrgID: orgID, ActorID: targetActor, Role: role}, nil
+}
+
+// RemoveOrgMember removes an actor from the org. The last owner cannot be removed.
+func (e Engine) RemoveOrgMember(ctx context.Context, orgID, actorID, targetActor string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requireOrgAdmin(ctx, tx, orgID, actorID); err != nil {
+		return err
+	}
+	current, err := e.Repo.OrgRoleTx(ctx, tx, orgID, targetActor)
+	if err != nil {
+		return err
+	}
+	if current == OrgRoleOwner {
+		if err := e.ensureAnotherOwner(ctx, tx, orgID); err != nil {
+			return err
+		}
+	}
+	if err := e.Repo.RemoveOrgMember(ctx, tx, orgID, targetActor); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "org.member_removed", "", "rbac", orgID, actorID, events.EventPayload{"actor_id": targetActor}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
+}
+
+// GrantOrgRole grants a project role to an actor in every project of the org.
+func (e Engine) GrantOrgRole(ctx context.Context, orgID, actorID, targetActor, roleID string) error {
+	if strings.TrimSpace(targetActor) == "" || strings.TrimSpace(roleID) == "" {
+		return errors.New("actor_id and role_id are required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := e.Repo.GetOrgTx(ctx, tx, orgID); err != nil {
+		return err
+	}
+	if err := e.requireOrgAdmin(ctx, tx, orgID, actorID); err != nil {
+		return err
+	}
+	role, err := e.Repo.GetRoleTx(ctx, tx, roleID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return fmt.Errorf("invalid role %s", roleID)
+	}
+	if err != nil {
+		return err
+	}
+	if role.ProjectID != "" {
+		return fmt.Errorf("invalid role %s: custom roles of project %s cannot be granted org-wide", roleID, role.ProjectID)
+	}
+	if err := e.ensureActor(ctx, tx, targetActor); err != nil {
+		return err
+	}
+	if err := e.Repo.AssignOrgActorRole(ctx, tx, orgID, targetActor, roleID); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.org_role_granted", "", "rbac", orgID, actorID, events.EventPayload{"actor_id": targetActor, "role_id": roleID}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
+}
+
+// RevokeOrgRole removes an org-level role grant. Project-level grants are untouched.
+func (e Engine) RevokeOrgRole(ctx context.Context, orgID, actorID, targetActor, roleID string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requireOrgAdmin(ctx, tx, orgID, actorID); err != nil {
+		return err
+	}
+	if err := e.Repo.RevokeOrgActorRole(ctx, tx, orgID, targetActor, roleID); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.org_role_revoked", "", "rbac", orgID, actorID, events.EventPayload{"actor_id": targetActor, "role_id": roleID}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
+}
+
+func (e Engine) requireOrgAdmin(ctx context.Context, tx *sql.Tx, orgID, actorID string) error {
+	if err := e.requireWritable("org.manage"); err != nil {
+		return err
+	}
+	role, err := e.Repo.OrgRoleTx(ctx, tx, orgID, actorID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
+	if role != OrgRoleOwner && role != OrgRoleAdmin {
+		return auth.ForbiddenError{Permission: "org.manage"}
+	}
+	return nil
+}
+
+func (e Engine) ensureAnotherOwner(ctx context.Context, tx *sql.Tx, orgID string) error {
+	owners, err := e.Repo.CountOrgOwners(ctx, tx, orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New("invalid change: org must keep at least one owner")
+	}
+	return nil
+}
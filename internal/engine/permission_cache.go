@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+)
+
+// PermissionCache keeps actors' permission sets in process, keyed by project
+// and actor. Role, grant and org membership changes all append rbac.* or
+// org.* events, so the cache is versioned by the latest such event id and
+// starts over whenever it moves, including after changes made by another
+// process sharing the database. Actors holding time-boxed grants are not
+// cached.
+type PermissionCache struct {
+	mu      sync.Mutex
+	version int64
+	sets    map[permissionKey][]string
+}
+
+type permissionKey struct {
+	projectID, actorID string
+}
+
+// NewPermissionCache returns an empty cache to attach as Engine.Perms.
+func NewPermissionCache() *PermissionCache {
+	return &PermissionCache{sets: map[permissionKey][]string{}}
+}
+
+// get returns the cached set for version, dropping every entry when version
+// differs from the one they were loaded at.
+func (c *PermissionCache) get(version int64, projectID, actorID string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version != c.version {
+		c.version = version
+		clear(c.sets)
+		return nil, false
+	}
+	perms, ok := c.sets[permissionKey{projectID, actorID}]
+	return perms, ok
+}
+
+// put stores a set loaded from committed data at version.
+func (c *PermissionCache) put(version int64, projectID, actorID string, perms []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version == c.version {
+		c.sets[permissionKey{projectID, actorID}] = perms
+	}
+}
+
+// HasPermission reports whether actorID holds perm in the project. With a
+// PermissionCache attached, a hit costs one indexed query and no transaction.
+// Misses are loaded in a transaction of their own, so only committed sets
+// reach the PermissionCache or the shared cache.
+func (e Engine) HasPermission(ctx context.Context, projectID, actorID, perm string) (bool, error) {
+	if e.Perms != nil {
+		version, err := e.Repo.RBACVersion(ctx)
+		if err != nil {
+			return false, err
+		}
+		if perms, ok := e.Perms.get(version, projectID, actorID); ok {
+			return slices.Contains(perms, perm), nil
+		}
+	}
+	gen, shared := e.permissionGen(ctx)
+	if shared {
+		if perms, ok := e.cachedPermissions(ctx, gen, projectID, actorID); ok {
+			return slices.Contains(perms, perm), nil
+		}
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if e.Perms == nil && !shared {
+		return e.Auth.ActorHasPermission(ctx, tx, projectID, actorID, perm)
+	}
+	// Read the version and the set in one snapshot, so the entry matches
+	// the version it is stored under.
+	var version int64
+	if e.Perms != nil {
+		if version, err = e.Repo.RBACVersionTx(ctx, tx); err != nil {
+			return false, err
+		}
+	}
+	perms, err := e.Auth.ActorPermissions(ctx, tx, projectID, actorID)
+	if err != nil {
+		return false, err
+	}
+	cacheable, err := e.permissionsCacheable(ctx, tx, projectID, actorID)
+	if err != nil {
+		return false, err
+	}
+	if cacheable {
+		if e.Perms != nil {
+			e.Perms.put(version, projectID, actorID, perms)
+		}
+		if shared {
+			e.storePermissions(ctx, gen, projectID, actorID, perms)
+		}
+	}
+	return slices.Contains(perms, perm), nil
+}
+
+// permissionsCacheable reports whether the actor's permission set can be
+// cached: a time-boxed grant lapses without an rbac event to invalidate it.
+func (e Engine) permissionsCacheable(ctx context.Context, tx *sql.Tx, projectID, actorID string) (bool, error) {
+	expiries, err := e.Auth.ActorRoleExpiries(ctx, tx, projectID, actorID)
+	return len(expiries) == 0, err
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// CapacityExceededError is returned when planning.capacity_check is "block"
+// and a task's estimate would push an iteration past its capacity.
+type CapacityExceededError struct {
+	IterationID string
+	Capacity    float64
+	Planned     float64
+	Unit        string
+}
+
+func (e CapacityExceededError) Error() string {
+	return fmt.Sprintf("iteration %s over capacity: %s of %s %s planned", e.IterationID, formatPoints(e.Planned), formatPoints(e.Capacity), e.Unit)
+}
+
+func formatPoints(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func validateEstimate(v *float64, field string) error {
+	if v != nil && *v < 0 {
+		return fmt.Errorf("invalid %s: must not be negative", field)
+	}
+	return nil
+}
+
+// checkIterationCapacity compares the iteration's planned estimates, with the
+// task counted at estimate, against its capacity. Over capacity it either
+// fails with CapacityExceededError or records an event and returns a warning.
+func (e Engine) checkIterationCapacity(ctx context.Context, tx *sql.Tx, iterationID, taskID string, estimate *float64, actorID string) (string, error) {
+	if iterationID == "" || estimate == nil || *estimate == 0 {
+		return "", nil
+	}
+	it, err := e.Repo.GetIterationTx(ctx, tx, iterationID)
+	if err != nil {
+		return "", err
+	}
+	if it.Capacity == nil {
+		return "", nil
+	}
+	planned, _, err := e.Repo.IterationPointsTx(ctx, tx, iterationID, taskID)
+	if err != nil {
+		return "", err
+	}
+	planned += *estimate
+	if planned <= *it.Capacity {
+		return "", nil
+	}
+	unit := "points"
+	if e.Config != nil {
+		unit = e.Config.EstimateUnit()
+	}
+	over := CapacityExceededError{IterationID: iterationID, Capacity: *it.Capacity, Planned: planned, Unit: unit}
+	if e.Config != nil && e.Config.BlockOverCapacity() {
+		return "", over
+	}
+	if err := e.Events.Append(ctx, tx, "iteration.over_capacity", it.ProjectID, "iteration", it.ID, actorID, events.EventPayload{
+		"task_id":  taskID,
+		"capacity": *it.Capacity,
+		"planned":  planned,
+	}); err != nil {
+		return "", err
+	}
+	return over.Error(), nil
+}
+
+// SetIterationCapacity sets or clears (nil) an iteration's capacity.
+func (e Engine) SetIterationCapacity(ctx context.Context, iterationID string, capacity *float64, actorID string) (domain.Iteration, error) {
+	if err := validateEstimate(capacity, "capacity"); err != nil {
+		return domain.Iteration{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Iteration{}, err
+	}
+	defer tx.Rollback()
+	it, err := e.Repo.GetIterationTx(ctx, tx, iterationID)
+	if err != nil {
+		return domain.Iteration{}, err
+	}
+	if err := e.requirePermission(ctx, tx, it.ProjectID, actorID, "iteration.create"); err != nil {
+		return domain.Iteration{}, err
+	}
+	if err := e.Repo.UpdateIterationCapacity(ctx, tx, it.ID, capacity); err != nil {
+		return domain.Iteration{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "iteration.capacity_set", it.ProjectID, "iteration", it.ID, actorID, events.EventPayload{
+		"from": it.Capacity,
+		"to":   capacity,
+	}); err != nil {
+		return domain.Iteration{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Iteration{}, err
+	}
+	it.Capacity = capacity
+	return it, nil
+}
+
+// IterationProgress reports planned and completed estimates for an iteration.
+func (e Engine) IterationProgress(ctx context.Context, iterationID, actorID string) (domain.IterationProgress, error) {
+	if e.Config == nil {
+		return domain.IterationProgress{}, errors.New("config not loaded")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.IterationProgress{}, err
+	}
+	defer tx.Rollback()
+	it, err := e.Repo.GetIterationTx(ctx, tx, iterationID)
+	if err != nil {
+		return domain.IterationProgress{}, err
+	}
+	if err := e.requirePermission(ctx, tx, it.ProjectID, actorID, "iteration.list"); err != nil {
+		return domain.IterationProgress{}, err
+	}
+	planned, completed, err := e.Repo.IterationPointsTx(ctx, tx, it.ID, "")
+	if err != nil {
+		return domain.IterationProgress{}, err
+	}
+	return domain.IterationProgress{
+		IterationID:     it.ID,
+		Unit:            e.Config.EstimateUnit(),
+		Capacity:        it.Capacity,
+		PlannedPoints:   planned,
+		CompletedPoints: completed,
+		OverCapacity:    it.Capacity != nil && planned > *it.Capacity,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// PolicySimulation describes a hypothetical task for SimulatePolicy.
+type PolicySimulation struct {
+	Type      string
+	Component string
+	// Policy is the preset the task would be created with; empty uses the
+	// type's default, as CreateTask does.
+	Policy string
+	// Attestations are the kinds assumed to be recorded already.
+	Attestations []string
+}
+
+// resolveTaskPolicy picks the policy CreateTask applies: preset, or the task
+// type's default, looked up in the component's policies first. It returns an
+// empty name when the type has no policies.
+func resolveTaskPolicy(cfg *config.Config, component, taskType, preset string) (string, []string, error) {
+	name := preset
+	if name == "" {
+		name = cfg.DefaultTaskPolicyName(taskType)
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+	policy, ok := cfg.ComponentTaskPolicy(component, taskType, name)
+	if !ok {
+		return "", nil, fmt.Errorf("unknown policy %s for task type %s", name, taskType)
+	}
+	return name, policy.All, nil
+}
+
+// SimulatePolicy reports the policy a task like sim would get and what done
+// would still need, without creating anything.
+func (e Engine) SimulatePolicy(ctx context.Context, projectID string, sim PolicySimulation, actorID string) (domain.PolicyCheck, error) {
+	if e.Config == nil {
+		return domain.PolicyCheck{}, errors.New("config not loaded")
+	}
+	if sim.Type == "" {
+		sim.Type = "technical"
+	}
+	if !e.Config.AllowedTaskTypes()[sim.Type] {
+		return domain.PolicyCheck{}, fmt.Errorf("unknown task type %s", sim.Type)
+	}
+	if !e.Config.HasComponent(sim.Component) {
+		return domain.PolicyCheck{}, fmt.Errorf("unknown component %s", sim.Component)
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "task.validation.read"); err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	name, required, err := resolveTaskPolicy(e.Config, sim.Component, sim.Type, sim.Policy)
+	if err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	check := newPolicyCheck(sim.Type, sim.Component, name, required)
+	for _, kind := range required {
+		if slices.Contains(sim.Attestations, kind) {
+			check.Present = append(check.Present, kind)
+		} else {
+			check.Missing = append(check.Missing, kind)
+		}
+	}
+	check.Satisfied = len(check.Missing) == 0
+	return check, nil
+}
+
+// CheckTaskPolicy reports what an existing task still needs for done: its
+// required attestations that are missing or expired, and any unfinished
+// dependencies, unfinished subtasks or rejected validation. Policy is what
+// the current config would apply to the task; Override is set when the
+// task's requirements differ from it.
+func (e Engine) CheckTaskPolicy(ctx context.Context, taskID, actorID string) (domain.PolicyCheck, error) {
+	if e.Config == nil {
+		return domain.PolicyCheck{}, errors.New("config not loaded")
+	}
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	atts, err := e.Repo.ListAttestations(ctx, repo.AttestationFilters{EntityKind: "task", EntityID: t.ID, ProjectID: t.ProjectID})
+	if err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.validation.read"); err != nil {
+		return domain.PolicyCheck{}, err
+	}
+	var required []string
+	if t.RequiredAttestationsJSON != nil {
+		if err := json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &required); err != nil {
+			return domain.PolicyCheck{}, err
+		}
+	}
+	// A policy since removed from the config leaves the name empty and the
+	// task's own requirements in charge.
+	name, configured, _ := resolveTaskPolicy(e.Config, t.Component, t.Type, "")
+	check := newPolicyCheck(t.Type, t.Component, name, required)
+	check.Override = !slices.Equal(slices.Sorted(slices.Values(required)), slices.Sorted(slices.Values(configured)))
+
+	now := e.now()
+	found, expired := map[string]bool{}, map[string]bool{}
+	for _, att := range atts {
+		if e.AttestationExpired(att, now) {
+			expired[att.Kind] = true
+			continue
+		}
+		found[att.Kind] = true
+	}
+	for _, kind := range required {
+		switch {
+		case found[kind]:
+			check.Present = append(check.Present, kind)
+		case expired[kind]:
+			check.Missing = append(check.Missing, kind)
+			check.Expired = append(check.Expired, kind)
+		default:
+			check.Missing = append(check.Missing, kind)
+		}
+	}
+	check.Satisfied = len(check.Missing) == 0
+
+	if t.Status != e.workflow(t.Type).Done {
+		for _, gate := range []func() error{
+			func() error { return e.ensureDependenciesDone(ctx, tx, t.ID, t.ProjectID, false) },
+			func() error { return e.ensureSubtasksDone(ctx, tx, t.ID, false) },
+			func() error { return e.ensureNoRejectedValidation(ctx, tx, t.ProjectID, t.ID) },
+		} {
+			if err := gate(); err != nil {
+				check.Blockers = append(check.Blockers, err.Error())
+			}
+		}
+	}
+	return check, nil
+}
+
+func newPolicyCheck(taskType, component, policy string, required []string) domain.PolicyCheck {
+	if required == nil {
+		required = []string{}
+	}
+	return domain.PolicyCheck{
+		TaskType:  taskType,
+		Component: component,
+		Policy:    policy,
+		Required:  required,
+		Present:   []string{},
+		Missing:   []string{},
+		Expired:   []string{},
+		Blockers:  []string{},
+	}
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// DeleteProjectOptions selects the project to delete.
+type DeleteProjectOptions struct {
+	ProjectID string
+	ActorID   string
+	// ArchivePath receives every row of the project as gzip-compressed NDJSON
+	// before anything is deleted. It must not exist yet; empty skips the
+	// archive.
+	ArchivePath string
+}
+
+// projectArchivePage is how many rows are read per query while archiving.
+const projectArchivePage = 500
+
+// DeleteProject deletes a project with its tasks, iterations, attestations,
+// leases, configs and events in one transaction. Evidence files are removed
+// once it commits. The deletion is recorded as a project.deleted event in the
+// global log, outside the deleted project.
+func (e Engine) DeleteProject(ctx context.Context, opts DeleteProjectOptions) (domain.ProjectDeletion, error) {
+	if opts.ProjectID == "" {
+		return domain.ProjectDeletion{}, fmt.Errorf("project id is required")
+	}
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	res := domain.ProjectDeletion{ProjectID: opts.ProjectID, Warnings: []string{}}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.delete"); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	if res.Rows, err = e.Repo.ProjectRowCountsTx(ctx, tx, opts.ProjectID); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	keys, err := e.Repo.EvidenceStorageKeysTx(ctx, tx, opts.ProjectID)
+	if err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	// As with event compaction, the archive is complete and synced before
+	// anything is deleted, and removed again if the delete does not commit.
+	committed := false
+	if opts.ArchivePath != "" {
+		if err := e.writeProjectArchive(ctx, tx, opts.ArchivePath, opts.ProjectID); err != nil {
+			return domain.ProjectDeletion{}, err
+		}
+		defer func() {
+			if !committed {
+				os.Remove(opts.ArchivePath)
+			}
+		}()
+		res.Archive = opts.ArchivePath
+	}
+	if err := e.Repo.DeleteProjectTx(ctx, tx, opts.ProjectID); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "project.deleted", "", "project", opts.ProjectID, opts.ActorID, events.EventPayload{
+		"rows":    res.Rows,
+		"archive": res.Archive,
+	}); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.ProjectDeletion{}, err
+	}
+	committed = true
+	if len(keys) > 0 && e.Blobs == nil {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%d evidence files not removed: no evidence store configured", len(keys)))
+	}
+	for _, key := range keys {
+		if e.Blobs == nil {
+			break
+		}
+		if derr := e.Blobs.Delete(ctx, key); derr != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("evidence %s not removed: %v", key, derr))
+		}
+	}
+	return res, nil
+}
+
+// projectArchiveRecord is one line of a project archive.
+type projectArchiveRecord struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+func (e Engine) writeProjectArchive(ctx context.Context, tx *sql.Tx, path, projectID string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	err = func() error {
+		for _, table := range e.Repo.ProjectTables() {
+			for offset := 0; ; offset += projectArchivePage {
+				rows, err := e.Repo.ProjectRowsTx(ctx, tx, table, projectID, offset, projectArchivePage)
+				if err != nil {
+					return err
+				}
+				for _, row := range rows {
+					if err := enc.Encode(projectArchiveRecord{Table: table, Row: row}); err != nil {
+						return err
+					}
+				}
+				if len(rows) < projectArchivePage {
+					break
+				}
+			}
+		}
+		return nil
+	}()
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// RenameProject changes a project's id in every table referencing it, in one
+// transaction. The former id stays an alias the API resolves, and the
+// rename is logged as project.renamed under the new id.
+func (e Engine) RenameProject(ctx context.Context, oldID, newID, actorID string) (domain.Project, error) {
+	newID = strings.TrimSpace(newID)
+	if newID == "" || strings.ContainsAny(newID, "/ \t\n") {
+		return domain.Project{}, fmt.Errorf("invalid project id %q", newID)
+	}
+	if newID == oldID {
+		return domain.Project{}, fmt.Errorf("invalid project id %q: project already has it", newID)
+	}
+	if _, err := e.Repo.GetProject(ctx, oldID); err != nil {
+		return domain.Project{}, err
+	}
+	if _, err := e.Repo.GetProject(ctx, newID); err == nil {
+		return domain.Project{}, fmt.Errorf("project %s already exists", newID)
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return domain.Project{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, oldID, actorID, "project.rename"); err != nil {
+		return domain.Project{}, err
+	}
+	if err := e.Repo.RenameProjectTx(ctx, tx, oldID, newID, e.now().UTC().Format(time.RFC3339)); err != nil {
+		return domain.Project{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "project.renamed", newID, "project", newID, actorID, events.EventPayload{
+		"from": oldID,
+		"to":   newID,
+	}); err != nil {
+		return domain.Project{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Project{}, err
+	}
+	return e.Repo.GetProject(ctx, newID)
+}
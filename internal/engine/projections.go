@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// Projection is a read model derived from the event log alone, so it can be
+// dropped and rebuilt by replaying events instead of migrating its tables.
+type Projection struct {
+	Description string
+	// Reset deletes the projection's rows for a project.
+	Reset func(ctx context.Context, tx *sql.Tx, e Engine, projectID string) error
+	// Apply folds one event into the projection, ignoring events it does
+	// not use. Events come oldest first.
+	Apply func(ctx context.Context, tx *sql.Tx, e Engine, evt domain.Event) error
+	// Read returns the projection's content for a project.
+	Read func(ctx context.Context, tx *sql.Tx, e Engine, projectID string) (any, error)
+}
+
+// projectionLibrary maps projection names to implementations.
+var projectionLibrary = map[string]Projection{
+	"task_status": {
+		Description: "Status of each task, for task counts per status",
+		Reset: func(ctx context.Context, tx *sql.Tx, e Engine, projectID string) error {
+			return e.Repo.ResetTaskStatusProjectionTx(ctx, tx, projectID)
+		},
+		Apply: applyTaskStatus,
+		Read: func(ctx context.Context, tx *sql.Tx, e Engine, projectID string) (any, error) {
+			return e.Repo.ProjectedTaskStatusCountsTx(ctx, tx, projectID)
+		},
+	},
+}
+
+const projectionReplayPageSize = 500
+
+// Projections lists the projection names.
+func Projections() []string {
+	names := make([]string, 0, len(projectionLibrary))
+	for name := range projectionLibrary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RebuildProjection clears a projection for a project and replays every
+// event of the project into it, in one transaction so readers never see it
+// half built. It needs projection.rebuild.
+func (e Engine) RebuildProjection(ctx context.Context, name, projectID, actorID string) (domain.ProjectionRebuild, error) {
+	res := domain.ProjectionRebuild{Name: name, ProjectID: projectID, Warnings: []string{}}
+	p, ok := projectionLibrary[name]
+	if !ok {
+		return res, fmt.Errorf("unknown projection %q (available: %v)", name, Projections())
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return res, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "projection.rebuild"); err != nil {
+		return res, err
+	}
+	compacted, err := e.Repo.EventCompactedTx(ctx, tx, projectID)
+	if err != nil {
+		return res, err
+	}
+	if compacted {
+		res.Warnings = append(res.Warnings, "events of this project were compacted; entities whose events were removed are missing or stale")
+	}
+	if err := p.Reset(ctx, tx, e, projectID); err != nil {
+		return res, err
+	}
+	for {
+		page, err := e.Repo.EventChainPageTx(ctx, tx, projectID, res.LastEventID, projectionReplayPageSize)
+		if err != nil {
+			return res, err
+		}
+		for _, evt := range page {
+			if err := p.Apply(ctx, tx, e, evt); err != nil {
+				return res, fmt.Errorf("projection %s: event %d: %w", name, evt.ID, err)
+			}
+			res.LastEventID = evt.ID
+			res.Events++
+		}
+		if len(page) < projectionReplayPageSize {
+			break
+		}
+	}
+	res.RebuiltAt = e.now().UTC().Format(time.RFC3339)
+	if err := e.Repo.SetProjectionStateTx(ctx, tx, name, projectID, res.LastEventID, res.RebuiltAt); err != nil {
+		return res, err
+	}
+	if err := e.Events.Append(ctx, tx, "projection.rebuilt", projectID, "project", projectID, actorID, events.EventPayload{
+		"projection":    name,
+		"events":        res.Events,
+		"last_event_id": res.LastEventID,
+	}); err != nil {
+		return res, err
+	}
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ListProjections returns every projection with how far it has read the
+// project's events; never rebuilt ones have no rebuilt_at.
+func (e Engine) ListProjections(ctx context.Context, projectID, actorID string) ([]domain.ProjectionState, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.status.read"); err != nil {
+		return nil, err
+	}
+	states, err := e.Repo.ProjectionStatesTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ProjectionState, 0, len(projectionLibrary))
+	for _, name := range Projections() {
+		st, ok := states[name]
+		if !ok {
+			st = domain.ProjectionState{Name: name, ProjectID: projectID, Lag: -1}
+		}
+		st.Description = projectionLibrary[name].Description
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// ReadProjection returns a projection's content for a project as of its
+// last rebuild.
+func (e Engine) ReadProjection(ctx context.Context, name, projectID, actorID string) (any, error) {
+	p, ok := projectionLibrary[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown projection %q (available: %v)", name, Projections())
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.status.read"); err != nil {
+		return nil, err
+	}
+	return p.Read(ctx, tx, e, projectID)
+}
+
+// applyTaskStatus tracks task statuses through the events that set them.
+func applyTaskStatus(ctx context.Context, tx *sql.Tx, e Engine, evt domain.Event) error {
+	var payload struct {
+		Status   string   `json:"status"`
+		ToStatus string   `json:"to_status"`
+		Updated  []string `json:"updated"`
+	}
+	switch evt.Type {
+	case "task.created", "task.updated", "task.done", "task.reverted", "task.synced", "task.bulk_updated":
+	default:
+		return nil
+	}
+	if err := json.Unmarshal([]byte(evt.Payload), &payload); err != nil {
+		return err
+	}
+	switch evt.Type {
+	case "task.created", "task.done":
+		if payload.Status == "" {
+			return nil
+		}
+		return e.Repo.SetProjectedTaskStatusTx(ctx, tx, evt.ProjectID, evt.EntityID, payload.Status)
+	case "task.updated", "task.reverted", "task.synced":
+		if payload.ToStatus == "" {
+			return nil
+		}
+		return e.Repo.SetProjectedTaskStatusTx(ctx, tx, evt.ProjectID, evt.EntityID, payload.ToStatus)
+	case "task.bulk_updated":
+		if payload.Status == "" {
+			return nil
+		}
+		for _, id := range payload.Updated {
+			if err := e.Repo.SetProjectedTaskStatusTx(ctx, tx, evt.ProjectID, id, payload.Status); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/jsonschema"
+)
+
+// ProvenanceKind is the attestation kind holding the SLSA build provenance of
+// a task's artifacts; its payload is an in-toto statement, bare or in its DSSE
+// envelope.
+const ProvenanceKind = "provenance.verified"
+
+const dssePayloadType = "application/vnd.in-toto+json"
+
+var (
+	inTotoStatementTypes = []string{"https://in-toto.io/Statement/v0.1", "https://in-toto.io/Statement/v1"}
+	slsaPredicateTypes   = []string{"https://slsa.dev/provenance/v0.2", "https://slsa.dev/provenance/v1"}
+	hexDigestPattern     = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// ParseProvenance reads an in-toto statement with a SLSA provenance
+// predicate, bare or wrapped in the DSSE envelope signing tools produce, and
+// returns the statement. Signatures are not verified: the attestation records
+// that the provenance was supplied and well formed.
+func ParseProvenance(data []byte) ([]byte, error) {
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, AttestationPayloadError{Kind: ProvenanceKind, Violations: []jsonschema.Violation{{Path: "/", Message: "provenance is not valid JSON"}}}
+	}
+	if envelope.Payload != "" {
+		if envelope.PayloadType != dssePayloadType {
+			return nil, AttestationPayloadError{Kind: ProvenanceKind, Violations: []jsonschema.Violation{{Path: "/payloadType", Message: fmt.Sprintf("must be %s", dssePayloadType)}}}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			if decoded, err = base64.URLEncoding.DecodeString(envelope.Payload); err != nil {
+				return nil, AttestationPayloadError{Kind: ProvenanceKind, Violations: []jsonschema.Violation{{Path: "/payload", Message: "not base64"}}}
+			}
+		}
+		data = decoded
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, AttestationPayloadError{Kind: ProvenanceKind, Violations: []jsonschema.Violation{{Path: "/payload", Message: "statement is not valid JSON"}}}
+	}
+	if violations := provenanceViolations(doc); len(violations) > 0 {
+		return nil, AttestationPayloadError{Kind: ProvenanceKind, Violations: violations}
+	}
+	return json.Marshal(doc)
+}
+
+// provenanceViolations checks the fields of an in-toto statement that tie a
+// SLSA provenance to its artifacts and builder.
+func provenanceViolations(doc any) []jsonschema.Violation {
+	var out []jsonschema.Violation
+	add := func(path, format string, args ...any) {
+		out = append(out, jsonschema.Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	stmt, ok := doc.(map[string]any)
+	if !ok {
+		add("/", "expected an in-toto statement object")
+		return out
+	}
+	if typ, _ := stmt["_type"].(string); !slices.Contains(inTotoStatementTypes, typ) {
+		add("/_type", "must be one of %v", inTotoStatementTypes)
+	}
+	subjects, _ := stmt["subject"].([]any)
+	if len(subjects) == 0 {
+		add("/subject", "at least one subject is required")
+	}
+	for i, item := range subjects {
+		path := fmt.Sprintf("/subject/%d", i)
+		subject, _ := item.(map[string]any)
+		if name, _ := subject["name"].(string); name == "" {
+			add(path+"/name", "required")
+		}
+		digest, _ := subject["digest"].(map[string]any)
+		if len(digest) == 0 {
+			add(path+"/digest", "at least one digest is required")
+		}
+		for algo, value := range digest {
+			if s, _ := value.(string); !hexDigestPattern.MatchString(s) {
+				add(path+"/digest/"+algo, "must be a hex string")
+			}
+		}
+	}
+	predicateType, _ := stmt["predicateType"].(string)
+	if !slices.Contains(slsaPredicateTypes, predicateType) {
+		add("/predicateType", "must be one of %v", slsaPredicateTypes)
+		return out
+	}
+	predicate, ok := stmt["predicate"].(map[string]any)
+	if !ok {
+		add("/predicate", "required")
+		return out
+	}
+	required := [][]string{{"builder", "id"}, {"buildType"}}
+	if predicateType == "https://slsa.dev/provenance/v1" {
+		required = [][]string{{"buildDefinition", "buildType"}, {"runDetails", "builder", "id"}}
+	}
+	for _, path := range required {
+		if s, _ := lookupPath(predicate, path).(string); s == "" {
+			add("/predicate/"+strings.Join(path, "/"), "required")
+		}
+	}
+	return out
+}
+
+func lookupPath(m map[string]any, path []string) any {
+	var v any = m
+	for _, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// AttestProvenance records SLSA provenance on a task as a provenance.verified
+// attestation whose payload is the in-toto statement.
+func (e Engine) AttestProvenance(ctx context.Context, taskID string, provenance []byte, actorID string) (domain.Attestation, error) {
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return domain.Attestation{}, err
+	}
+	stmt, err := ParseProvenance(provenance)
+	if err != nil {
+		return domain.Attestation{}, err
+	}
+	return e.AddAttestation(ctx, domain.Attestation{
+		ProjectID:   t.ProjectID,
+		EntityKind:  "task",
+		EntityID:    t.ID,
+		Kind:        ProvenanceKind,
+		PayloadJSON: string(stmt),
+	}, actorID)
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// RecordRead notes that actorID read resource (config, events or
+// attestations) of the project, when its project.read_audit asks for it;
+// sampled mode records that share of reads at random. target says what was
+// read, such as the endpoint and its filters. Read-only engines record
+// nothing.
+func (e Engine) RecordRead(ctx context.Context, projectID, actorID, resource, target string) error {
+	if e.ReadOnly {
+		return nil
+	}
+	cfg := e.Config
+	if cfg == nil || cfg.Project.ID != projectID {
+		var err error
+		cfg, err = e.ProjectConfig(ctx, projectID)
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	rate := cfg.Project.ReadAudit.Rate(resource)
+	if rate <= 0 || (rate < 1 && rand.Float64() >= rate) {
+		return nil
+	}
+	return e.Repo.InsertReadAudit(ctx, domain.ReadAuditEntry{
+		ProjectID: projectID,
+		TS:        e.now().UTC().Format(time.RFC3339),
+		ActorID:   actorID,
+		Resource:  resource,
+		Target:    target,
+	})
+}
+
+// ReadAudit returns the project's recorded reads newest first. Since and
+// Until take RFC3339 or YYYY-MM-DD, the start of that day in UTC. It needs
+// events.audit.read.
+func (e Engine) ReadAudit(ctx context.Context, actorID string, f repo.ReadAuditFilters) ([]domain.ReadAuditEntry, error) {
+	if f.Resource != "" && !slices.Contains(config.ReadAuditResources, f.Resource) {
+		return nil, fmt.Errorf("invalid resource %s: must be one of %s", f.Resource, strings.Join(config.ReadAuditResources, ", "))
+	}
+	var err error
+	if f.Since, err = parseAuditBound("since", f.Since); err != nil {
+		return nil, err
+	}
+	if f.Until, err = parseAuditBound("until", f.Until); err != nil {
+		return nil, err
+	}
+	if _, err := e.Repo.GetProject(ctx, f.ProjectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, f.ProjectID, actorID, "events.audit.read"); err != nil {
+		return nil, err
+	}
+	entries, err := e.Repo.ReadAuditTx(ctx, tx, f)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []domain.ReadAuditEntry{}
+	}
+	return entries, nil
+}
+
+func parseAuditBound(field, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("invalid %s %q: use RFC3339 or YYYY-MM-DD", field, s)
+}
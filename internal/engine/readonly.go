@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReadOnlyError is returned for any write while the engine is read-only.
+type ReadOnlyError struct {
+	Permission string
+}
+
+func (e ReadOnlyError) Error() string {
+	if e.Permission == "" {
+		return "read-only mode: writes are disabled"
+	}
+	return fmt.Sprintf("read-only mode: %s is not allowed", e.Permission)
+}
+
+// isReadPermission reports whether a permission only guards reads.
+func isReadPermission(perm string) bool {
+	for _, suffix := range []string{".read", ".list", ".next", ".tree"} {
+		if strings.HasSuffix(perm, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Engine) requireWritable(perm string) error {
+	if e.ReadOnly && !isReadPermission(perm) {
+		return ReadOnlyError{Permission: perm}
+	}
+	return nil
+}
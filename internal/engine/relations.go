@@ -0,0 +1,277 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// TaskRelationTypes are the relations tasks can have besides depends_on.
+// "A blocks B" keeps B from completing, and from claim-next, until A is done;
+// "A duplicates B" closes A; relates_to and caused_by are informational.
+var TaskRelationTypes = []string{"blocks", "relates_to", "duplicates", "caused_by"}
+
+const (
+	TaskRelationAddedEvent   = "task.relation_added"
+	TaskRelationRemovedEvent = "task.relation_removed"
+)
+
+// TaskRelationOptions names a relation "TaskID Type RelatedTaskID".
+type TaskRelationOptions struct {
+	TaskID        string
+	Type          string
+	RelatedTaskID string
+	ActorID       string
+	// Force closes a duplicate leased by another actor.
+	Force bool
+}
+
+// AddTaskRelation records a typed relation between two tasks of a project.
+// Adding "A duplicates B" also closes A, when still open, into its
+// workflow's first terminal state other than done (canceled by default),
+// whatever the workflow's transitions. Adding an existing relation, or
+// relates_to between tasks already related so, changes nothing.
+func (e Engine) AddTaskRelation(ctx context.Context, opts TaskRelationOptions) (domain.TaskRelation, error) {
+	if e.Config == nil {
+		return domain.TaskRelation{}, errors.New("config not loaded")
+	}
+	if !slices.Contains(TaskRelationTypes, opts.Type) {
+		return domain.TaskRelation{}, fmt.Errorf("invalid relation type %q: must be one of %s", opts.Type, strings.Join(TaskRelationTypes, ", "))
+	}
+	if opts.TaskID == opts.RelatedTaskID {
+		return domain.TaskRelation{}, errors.New("invalid relation: a task cannot be related to itself")
+	}
+	t, err := e.Repo.GetTask(ctx, opts.TaskID)
+	if err != nil {
+		return domain.TaskRelation{}, err
+	}
+	related, err := e.Repo.GetTask(ctx, opts.RelatedTaskID)
+	if err != nil {
+		return domain.TaskRelation{}, err
+	}
+	if related.ProjectID != t.ProjectID {
+		return domain.TaskRelation{}, fmt.Errorf("invalid relation: task %s not in project %s", related.ID, t.ProjectID)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TaskRelation{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.update"); err != nil {
+		return domain.TaskRelation{}, err
+	}
+	existing, err := e.Repo.TaskRelationsTx(ctx, tx, t.ID)
+	if err != nil {
+		return domain.TaskRelation{}, err
+	}
+	for _, rel := range existing {
+		if rel.Type != opts.Type {
+			continue
+		}
+		if rel.TaskID == t.ID && rel.RelatedTaskID == related.ID {
+			return rel, nil
+		}
+		if rel.TaskID == related.ID && rel.RelatedTaskID == t.ID {
+			switch opts.Type {
+			case "relates_to":
+				return rel, nil
+			case "blocks", "duplicates":
+				return domain.TaskRelation{}, fmt.Errorf("inverse relation already exists: %s %s %s", related.ID, opts.Type, t.ID)
+			}
+		}
+	}
+	rel := domain.TaskRelation{
+		TaskID:        t.ID,
+		Type:          opts.Type,
+		RelatedTaskID: related.ID,
+		CreatedBy:     opts.ActorID,
+		CreatedAt:     e.now().UTC().Format(time.RFC3339),
+	}
+	if _, err := e.Repo.AddTaskRelationTx(ctx, tx, t.ProjectID, rel); err != nil {
+		return domain.TaskRelation{}, err
+	}
+	if err := e.Events.Append(ctx, tx, TaskRelationAddedEvent, t.ProjectID, "task", t.ID, opts.ActorID, events.EventPayload{
+		"type":            rel.Type,
+		"related_task_id": rel.RelatedTaskID,
+	}); err != nil {
+		return domain.TaskRelation{}, err
+	}
+	if opts.Type == "duplicates" {
+		if rel.Closed, err = e.closeDuplicateTx(ctx, tx, t, related.ID, opts); err != nil {
+			return domain.TaskRelation{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.TaskRelation{}, err
+	}
+	e.sendHookDeliveries(ctx)
+	return rel, nil
+}
+
+// closeDuplicateTx closes the open task t as a duplicate of originalID,
+// returning the status it was closed into, or "" when it was already closed.
+func (e Engine) closeDuplicateTx(ctx context.Context, tx *sql.Tx, t domain.Task, originalID string, opts TaskRelationOptions) (string, error) {
+	workflow := e.workflow(t.Type)
+	if t.CompletedAt != nil || workflow.IsTerminal(t.Status) {
+		return "", nil
+	}
+	closing := duplicateClosingState(workflow)
+	if closing == "" {
+		return "", fmt.Errorf("invalid relation: task type %s has no terminal state besides %s to close duplicates into", t.Type, workflow.Done)
+	}
+	lease, err := e.Repo.GetLeaseTx(ctx, tx, t.ID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return "", err
+	}
+	if err == nil && lease.OwnerID != opts.ActorID && lease.ExpiresAt > e.now().UTC().Format(time.RFC3339) {
+		if !opts.Force {
+			return "", errors.New("lease owned by different actor")
+		}
+		if err := e.requireForcePermission(ctx, tx, t.ProjectID, opts.ActorID); err != nil {
+			return "", err
+		}
+	}
+	from := t.Status
+	t.Status = closing
+	t.UpdatedAt = e.now().UTC().Format(time.RFC3339)
+	if err := e.Repo.UpdateTask(ctx, tx, t); err != nil {
+		return "", err
+	}
+	payload := events.EventPayload{
+		"from_status":  from,
+		"to_status":    closing,
+		"duplicate_of": originalID,
+	}
+	if opts.Force {
+		payload["forced"] = true
+	}
+	if err := e.Events.Append(ctx, tx, "task.updated", t.ProjectID, "task", t.ID, opts.ActorID, payload); err != nil {
+		return "", err
+	}
+	if err := e.runTransitionHooks(ctx, tx, Transition{EntityKind: "task", EntityID: t.ID, ProjectID: t.ProjectID, From: from, To: closing, ActorID: opts.ActorID}); err != nil {
+		return "", err
+	}
+	return closing, nil
+}
+
+// duplicateClosingState is the first terminal state of the workflow that does
+// not complete the task.
+func duplicateClosingState(workflow config.WorkflowConfig) string {
+	for _, state := range workflow.Terminal {
+		if state != workflow.Done {
+			return state
+		}
+	}
+	return ""
+}
+
+// RemoveTaskRelation deletes a relation. A duplicate closed when the relation
+// was added stays closed.
+func (e Engine) RemoveTaskRelation(ctx context.Context, opts TaskRelationOptions) error {
+	t, err := e.Repo.GetTask(ctx, opts.TaskID)
+	if err != nil {
+		return err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.update"); err != nil {
+		return err
+	}
+	removed, err := e.Repo.RemoveTaskRelationTx(ctx, tx, t.ID, opts.Type, opts.RelatedTaskID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return repo.ErrNotFound
+	}
+	if err := e.Events.Append(ctx, tx, TaskRelationRemovedEvent, t.ProjectID, "task", t.ID, opts.ActorID, events.EventPayload{
+		"type":            opts.Type,
+		"related_task_id": opts.RelatedTaskID,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTaskRelations returns the relations a task takes part in, from either
+// side, oldest first.
+func (e Engine) ListTaskRelations(ctx context.Context, taskID, actorID string) ([]domain.TaskRelation, error) {
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.read"); err != nil {
+		return nil, err
+	}
+	rels, err := e.Repo.TaskRelationsTx(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if rels == nil {
+		rels = []domain.TaskRelation{}
+	}
+	return rels, nil
+}
+
+// TaskGraph returns a project's tasks and every link between them: parents
+// (subtask_of), depends_on and typed relations, oldest task first.
+func (e Engine) TaskGraph(ctx context.Context, projectID, actorID string) (domain.TaskGraph, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.TaskGraph{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TaskGraph{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "task.tree"); err != nil {
+		return domain.TaskGraph{}, err
+	}
+	tasks, err := e.Repo.ListTasksTx(ctx, tx, repo.TaskFilters{ProjectID: projectID, Sort: repo.TaskSort{Field: "created_at"}})
+	if err != nil {
+		return domain.TaskGraph{}, err
+	}
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	deps, err := e.Repo.ListDependenciesForTasksTx(ctx, tx, ids)
+	if err != nil {
+		return domain.TaskGraph{}, err
+	}
+	rels, err := e.Repo.RelationsForTasksTx(ctx, tx, ids)
+	if err != nil {
+		return domain.TaskGraph{}, err
+	}
+	graph := domain.TaskGraph{ProjectID: projectID, Nodes: []domain.TaskGraphNode{}, Edges: []domain.TaskGraphEdge{}}
+	for _, t := range tasks {
+		graph.Nodes = append(graph.Nodes, domain.TaskGraphNode{ID: t.ID, Title: t.Title, Type: t.Type, Status: t.Status})
+		if t.ParentID != nil {
+			graph.Edges = append(graph.Edges, domain.TaskGraphEdge{From: t.ID, To: *t.ParentID, Type: "subtask_of"})
+		}
+		for _, d := range deps[t.ID] {
+			graph.Edges = append(graph.Edges, domain.TaskGraphEdge{From: t.ID, To: d, Type: "depends_on"})
+		}
+		for _, rel := range rels[t.ID] {
+			graph.Edges = append(graph.Edges, domain.TaskGraphEdge{From: t.ID, To: rel.RelatedTaskID, Type: rel.Type})
+		}
+	}
+	return graph, nil
+}
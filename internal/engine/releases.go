@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ReleaseApprovalKind is the iteration attestation a release needs.
+const ReleaseApprovalKind = "iteration.approved"
+
+var releaseChecksumPattern = regexp.MustCompile(`^[a-z0-9]+:[0-9a-fA-F]+$`)
+
+// ReleaseOptions records an artifact released from an iteration. Checksum is
+// "<algorithm>:<hex digest>", such as sha256:ab12..., and URL where the
+// artifact can be downloaded; both are optional.
+type ReleaseOptions struct {
+	IterationID string
+	Name        string
+	Version     string
+	Checksum    string
+	URL         string
+	ActorID     string
+}
+
+// RecordRelease records an artifact shipped from a validated iteration that
+// holds a valid iteration.approved attestation. A name and version can be
+// released once per project.
+func (e Engine) RecordRelease(ctx context.Context, opts ReleaseOptions) (domain.Release, error) {
+	rel := domain.Release{
+		IterationID: opts.IterationID,
+		Name:        strings.TrimSpace(opts.Name),
+		Version:     strings.TrimSpace(opts.Version),
+		Checksum:    strings.TrimSpace(opts.Checksum),
+		URL:         strings.TrimSpace(opts.URL),
+		CreatedBy:   opts.ActorID,
+	}
+	if rel.Name == "" || rel.Version == "" {
+		return rel, errors.New("release name and version are required")
+	}
+	if rel.Checksum != "" && !releaseChecksumPattern.MatchString(rel.Checksum) {
+		return rel, fmt.Errorf("invalid checksum %q: expected <algorithm>:<hex digest>", opts.Checksum)
+	}
+	if rel.URL != "" {
+		u, err := url.Parse(rel.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return rel, fmt.Errorf("invalid release url %q: expected http or https", opts.URL)
+		}
+	}
+	it, err := e.Repo.GetIteration(ctx, opts.IterationID)
+	if err != nil {
+		return rel, err
+	}
+	rel.ProjectID = it.ProjectID
+	if it.Status != "validated" {
+		return rel, fmt.Errorf("iteration %s is %s: releases need its validation first", it.ID, it.Status)
+	}
+	approved, err := e.iterationValidated(ctx, it.ID, []string{ReleaseApprovalKind})
+	if err != nil {
+		return rel, err
+	}
+	if !approved {
+		return rel, fmt.Errorf("release validation failed: iteration %s has no valid %s attestation", it.ID, ReleaseApprovalKind)
+	}
+	rel.ID = uuid.NewString()
+	rel.CreatedAt = e.now().UTC().Format(time.RFC3339)
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return rel, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, rel.ProjectID, opts.ActorID, "release.create"); err != nil {
+		return rel, err
+	}
+	if existing, err := e.Repo.GetReleaseByVersionTx(ctx, tx, rel.ProjectID, rel.Name, rel.Version); err == nil {
+		return rel, fmt.Errorf("release %s %s already exists in iteration %s", rel.Name, rel.Version, existing.IterationID)
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return rel, err
+	}
+	if err := e.Repo.InsertReleaseTx(ctx, tx, rel); err != nil {
+		return rel, err
+	}
+	if err := e.Events.Append(ctx, tx, "release.recorded", rel.ProjectID, "iteration", it.ID, opts.ActorID, events.EventPayload{
+		"release_id": rel.ID,
+		"name":       rel.Name,
+		"version":    rel.Version,
+		"checksum":   rel.Checksum,
+		"url":        rel.URL,
+	}); err != nil {
+		return rel, err
+	}
+	if err := tx.Commit(); err != nil {
+		return rel, err
+	}
+	return rel, nil
+}
+
+// ListReleases returns a project's releases, newest first, only those of one
+// iteration when iterationID is set.
+func (e Engine) ListReleases(ctx context.Context, projectID, iterationID, actorID string) ([]domain.Release, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if iterationID != "" {
+		it, err := e.Repo.GetIteration(ctx, iterationID)
+		if err != nil {
+			return nil, err
+		}
+		if it.ProjectID != projectID {
+			return nil, repo.ErrNotFound
+		}
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "release.list"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListReleasesTx(ctx, tx, projectID, iterationID)
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/engine/auth"
+)
+
+// DefaultReportDays is the window of a status report when none is given.
+const DefaultReportDays = 7
+
+// StatusReport summarizes the last days of a project: iterations still open or
+// whose status changed in the window, tasks completed in the window with the
+// kinds of their unexpired attestations, and tasks blocked by unfinished
+// dependencies.
+func (e Engine) StatusReport(ctx context.Context, projectID, actorID string, days int) (domain.StatusReport, error) {
+	if days == 0 {
+		days = DefaultReportDays
+	}
+	if days < 0 || days > 366 {
+		return domain.StatusReport{}, fmt.Errorf("invalid days %d: must be between 1 and 366", days)
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.StatusReport{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.StatusReport{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "project.status.read"); err != nil {
+		return domain.StatusReport{}, err
+	}
+	until := e.now().UTC()
+	since := until.AddDate(0, 0, -days)
+	r := domain.StatusReport{
+		ProjectID: projectID,
+		Since:     since.Format(time.RFC3339),
+		Until:     until.Format(time.RFC3339),
+	}
+	if r.TaskCounts, err = e.Repo.CountTasksByStatusTx(ctx, tx, projectID); err != nil {
+		return r, err
+	}
+
+	iterations, err := e.Repo.ListIterationsTx(ctx, tx, projectID)
+	if err != nil {
+		return r, err
+	}
+	counts, err := e.Repo.IterationTaskCountsTx(ctx, tx, projectID)
+	if err != nil {
+		return r, err
+	}
+	evts, err := e.Repo.IterationStatusEventsTx(ctx, tx, projectID)
+	if err != nil {
+		return r, err
+	}
+	changed := map[string]bool{}
+	for _, ev := range evts {
+		if ts, err := time.Parse(time.RFC3339, ev.TS); err == nil && !ts.Before(since) {
+			changed[ev.EntityID] = true
+		}
+	}
+	for _, it := range iterations {
+		closed := it.Status == "validated" || it.Status == "rejected"
+		if closed && !changed[it.ID] {
+			continue
+		}
+		ri := counts[it.ID]
+		ri.ID, ri.Goal, ri.Status = it.ID, it.Goal, it.Status
+		r.Iterations = append(r.Iterations, ri)
+	}
+
+	if r.DoneTasks, err = e.Repo.CompletedTasksTx(ctx, tx, projectID, r.Since, r.Until); err != nil {
+		return r, err
+	}
+	for i := range r.DoneTasks {
+		atts, err := e.Repo.EntityAttestationsTx(ctx, tx, "task", r.DoneTasks[i].ID)
+		if err != nil {
+			return r, err
+		}
+		kinds := []string{}
+		for _, a := range atts {
+			if a.ExpiredAt == nil && !slices.Contains(kinds, a.Kind) {
+				kinds = append(kinds, a.Kind)
+			}
+		}
+		r.DoneTasks[i].Attestations = kinds
+	}
+	if r.Blockers, err = e.Repo.BlockedTasksTx(ctx, tx, projectID); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// ProjectOverviews returns an overview of each active project of an org, or
+// of every org when orgID is empty, by project id. Projects the actor lacks
+// project.status.read on are left out rather than failing the whole list.
+func (e Engine) ProjectOverviews(ctx context.Context, orgID, actorID string) ([]domain.ProjectOverview, error) {
+	var projects []domain.Project
+	var err error
+	if orgID == "" {
+		projects, err = e.Repo.ListProjects(ctx, false)
+	} else {
+		projects, err = e.Repo.ListProjectsByOrg(ctx, orgID, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(projects, func(a, b domain.Project) int { return strings.Compare(a.ID, b.ID) })
+	res := []domain.ProjectOverview{}
+	for _, p := range projects {
+		o, err := e.projectOverview(ctx, p, actorID)
+		var forbidden auth.ForbiddenError
+		if errors.As(err, &forbidden) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+func (e Engine) projectOverview(ctx context.Context, p domain.Project, actorID string) (domain.ProjectOverview, error) {
+	o := domain.ProjectOverview{OrgID: p.OrgID, ProjectID: p.ID, Status: p.Status}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return o, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, p.ID, actorID, "project.status.read"); err != nil {
+		return o, err
+	}
+	if o.TaskCounts, err = e.Repo.CountTasksByStatusTx(ctx, tx, p.ID); err != nil {
+		return o, err
+	}
+	for _, n := range o.TaskCounts {
+		o.Tasks += n
+	}
+	iterations, err := e.Repo.ListIterationsTx(ctx, tx, p.ID)
+	if err != nil {
+		return o, err
+	}
+	// The latest running iteration, as wl status shows.
+	for _, it := range iterations {
+		if it.Status == "running" {
+			o.Iteration, o.IterationGoal = &it.ID, it.Goal
+		}
+	}
+	blockers, err := e.Repo.BlockedTasksTx(ctx, tx, p.ID)
+	if err != nil {
+		return o, err
+	}
+	o.Blocked = len(blockers)
+	return o, nil
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// RoleExpiredEvent records a time-boxed role grant deleted by the sweep.
+const RoleExpiredEvent = "rbac.role_expired"
+
+// ExpireRoleGrants deletes the project's role grants past their expires_at and
+// records an rbac.role_expired event for each. Permission checks ignore such
+// grants already; the sweep keeps them out of role listings and the audit
+// trail complete.
+func (e Engine) ExpireRoleGrants(ctx context.Context, projectID, actorID string) ([]domain.RoleGrant, error) {
+	if err := e.requireWritable("rbac.expire"); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	expired, err := e.Repo.ExpiredRoleGrantsTx(ctx, tx, projectID, e.now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range expired {
+		if err := e.Repo.RevokeRole(ctx, tx, projectID, g.ActorID, g.RoleID); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, RoleExpiredEvent, projectID, "rbac", projectID, actorID, events.EventPayload{
+			"actor_id":   g.ActorID,
+			"role_id":    g.RoleID,
+			"expires_at": *g.ExpiresAt,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	e.forgetPermissions(ctx)
+	return expired, nil
+}
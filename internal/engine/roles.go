@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// RoleCreateOptions defines a custom role at runtime.
+type RoleCreateOptions struct {
+	ProjectID   string
+	RoleID      string
+	Description string
+	Grants      []string
+	ActorID     string
+}
+
+// RoleUpdateOptions changes a role; nil fields are left untouched.
+type RoleUpdateOptions struct {
+	ProjectID   string
+	RoleID      string
+	Description *string
+	Grants      *[]string
+	ActorID     string
+}
+
+// CreateRole defines a custom role owned by the project, with its
+// permissions. Grants may name permissions or permission sets from the
+// project config.
+func (e Engine) CreateRole(ctx context.Context, opts RoleCreateOptions) (domain.Role, error) {
+	opts.RoleID = strings.TrimSpace(opts.RoleID)
+	if opts.RoleID == "" {
+		return domain.Role{}, errors.New("role id is required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "rbac.manage"); err != nil {
+		return domain.Role{}, err
+	}
+	exists, err := e.Repo.RoleExistsTx(ctx, tx, opts.RoleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if exists {
+		return domain.Role{}, fmt.Errorf("role %s already exists", opts.RoleID)
+	}
+	perms, err := e.expandGrants(ctx, tx, opts.Grants)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if err := e.Repo.InsertProjectRole(ctx, tx, opts.ProjectID, opts.RoleID, opts.Description); err != nil {
+		return domain.Role{}, err
+	}
+	if err := e.Repo.AddRolePermissions(ctx, tx, opts.RoleID, perms); err != nil {
+		return domain.Role{}, err
+	}
+	role, err := e.Repo.GetRoleTx(ctx, tx, opts.RoleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.role_created", opts.ProjectID, "rbac", opts.ProjectID, opts.ActorID, events.EventPayload{
+		"role_id":     role.ID,
+		"permissions": role.Permissions,
+	}); err != nil {
+		return domain.Role{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Role{}, err
+	}
+	e.forgetPermissions(ctx)
+	return role, nil
+}
+
+// UpdateRole changes a custom role description and/or replaces its
+// permissions.
+func (e Engine) UpdateRole(ctx context.Context, opts RoleUpdateOptions) (domain.Role, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "rbac.manage"); err != nil {
+		return domain.Role{}, err
+	}
+	before, err := e.customRole(ctx, tx, opts.ProjectID, opts.RoleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if opts.Description != nil {
+		if err := e.Repo.UpdateRoleDescription(ctx, tx, opts.RoleID, *opts.Description); err != nil {
+			return domain.Role{}, err
+		}
+	}
+	if opts.Grants != nil {
+		perms, err := e.expandGrants(ctx, tx, *opts.Grants)
+		if err != nil {
+			return domain.Role{}, err
+		}
+		if err := e.Repo.ClearRolePermissions(ctx, tx, opts.RoleID); err != nil {
+			return domain.Role{}, err
+		}
+		if err := e.Repo.AddRolePermissions(ctx, tx, opts.RoleID, perms); err != nil {
+			return domain.Role{}, err
+		}
+	}
+	role, err := e.Repo.GetRoleTx(ctx, tx, opts.RoleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.role_updated", opts.ProjectID, "rbac", opts.ProjectID, opts.ActorID, events.EventPayload{
+		"role_id": role.ID,
+		"from":    before.Permissions,
+		"to":      role.Permissions,
+	}); err != nil {
+		return domain.Role{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Role{}, err
+	}
+	e.forgetPermissions(ctx)
+	return role, nil
+}
+
+// DeleteRole removes a custom role along with its grants and attestation
+// authorities.
+func (e Engine) DeleteRole(ctx context.Context, projectID, actorID, roleID string) error {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return err
+	}
+	if _, err := e.customRole(ctx, tx, projectID, roleID); err != nil {
+		return err
+	}
+	if err := e.Repo.DeleteRole(ctx, tx, projectID, roleID); err != nil {
+		return err
+	}
+	if err := e.Events.Append(ctx, tx, "rbac.role_deleted", projectID, "rbac", projectID, actorID, events.EventPayload{"role_id": roleID}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.forgetPermissions(ctx)
+	return nil
+}
+
+// ListRoles returns the shared roles and the project's custom roles with
+// their permissions.
+func (e Engine) ListRoles(ctx context.Context, projectID, actorID string) ([]domain.Role, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListRolesTx(ctx, tx, projectID)
+}
+
+// ListPermissions returns the permission catalog.
+func (e Engine) ListPermissions(ctx context.Context, projectID, actorID string) ([]domain.Permission, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListPermissionsTx(ctx, tx)
+}
+
+// InspectRole returns a role with its attestation authorities and members in the project.
+func (e Engine) InspectRole(ctx context.Context, projectID, actorID, roleID string) (domain.RoleDetail, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.RoleDetail{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return domain.RoleDetail{}, err
+	}
+	role, err := e.projectRole(ctx, tx, projectID, roleID)
+	if err != nil {
+		return domain.RoleDetail{}, err
+	}
+	kinds, err := e.Repo.RoleAttestationKindsTx(ctx, tx, projectID, roleID)
+	if err != nil {
+		return domain.RoleDetail{}, err
+	}
+	members, err := e.Repo.RoleMembersTx(ctx, tx, projectID, roleID)
+	if err != nil {
+		return domain.RoleDetail{}, err
+	}
+	return domain.RoleDetail{Role: role, AttestationKinds: kinds, Members: members}, nil
+}
+
+// projectRole returns a role the project may use: a shared role or one of
+// its custom roles. Other projects' custom roles are not found.
+func (e Engine) projectRole(ctx context.Context, tx *sql.Tx, projectID, roleID string) (domain.Role, error) {
+	role, err := e.Repo.GetRoleTx(ctx, tx, roleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if role.ProjectID != "" && role.ProjectID != projectID {
+		return domain.Role{}, repo.ErrNotFound
+	}
+	return role, nil
+}
+
+// customRole returns one of the project's custom roles. Shared roles are
+// seeded for every project and cannot be changed from one of them.
+func (e Engine) customRole(ctx context.Context, tx *sql.Tx, projectID, roleID string) (domain.Role, error) {
+	role, err := e.projectRole(ctx, tx, projectID, roleID)
+	if err != nil {
+		return domain.Role{}, err
+	}
+	if role.ProjectID == "" {
+		return domain.Role{}, fmt.Errorf("invalid role: %s is a built-in role shared by every project", roleID)
+	}
+	return role, nil
+}
+
+func (e Engine) expandGrants(ctx context.Context, tx *sql.Tx, grants []string) ([]string, error) {
+	var perms []string
+	for _, grant := range grants {
+		grant = strings.TrimSpace(grant)
+		if grant == "" {
+			continue
+		}
+		if e.Config != nil {
+			if set, ok := e.Config.Project.RBAC.Permissions[grant]; ok {
+				perms = append(perms, set...)
+				continue
+			}
+		}
+		perms = append(perms, grant)
+	}
+	perms = uniqueStrings(perms)
+	for _, perm := range perms {
+		ok, err := e.Repo.PermissionExistsTx(ctx, tx, perm)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid permission %s", perm)
+		}
+	}
+	return perms, nil
+}
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ApplyRoster reconciles the project's grants with a team roster: actors it
+// lists are registered and hold exactly their roles on the project, actors it
+// omits lose their project roles, and when the roster has
+// attestation_authorities each kind is issued by exactly the roles listed.
+// Org-level grants are left alone. Every grant and revocation is recorded as
+// the matching rbac event. A dry run reports the changes without keeping
+// them. A roster that would take rbac.manage away from the actor applying it
+// is rejected.
+func (e Engine) ApplyRoster(ctx context.Context, projectID, actorID string, roster config.Roster, dryRun bool) (domain.RosterResult, error) {
+	if err := roster.Validate(); err != nil {
+		return domain.RosterResult{}, err
+	}
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return domain.RosterResult{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.RosterResult{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "rbac.manage"); err != nil {
+		return domain.RosterResult{}, err
+	}
+	roles := map[string]bool{}
+	for _, a := range roster.Actors {
+		for _, role := range a.Roles {
+			roles[role] = true
+		}
+	}
+	for _, rs := range roster.AttestationAuthorities {
+		for _, role := range rs {
+			roles[role] = true
+		}
+	}
+	for _, role := range slices.Sorted(maps.Keys(roles)) {
+		if _, err := e.projectRole(ctx, tx, projectID, role); errors.Is(err, repo.ErrNotFound) {
+			return domain.RosterResult{}, fmt.Errorf("unknown role %s in roster", role)
+		} else if err != nil {
+			return domain.RosterResult{}, err
+		}
+	}
+
+	res := domain.RosterResult{ProjectID: projectID, DryRun: dryRun, Changes: []domain.RosterChange{}}
+	grants, err := e.Repo.ProjectRoleGrantsTx(ctx, tx, projectID)
+	if err != nil {
+		return domain.RosterResult{}, err
+	}
+	listed := map[string]bool{}
+	for _, a := range roster.Actors {
+		id := strings.TrimSpace(a.ID)
+		listed[id] = true
+		exists, err := e.Repo.ActorExistsTx(ctx, tx, id)
+		if err != nil {
+			return domain.RosterResult{}, err
+		}
+		if !exists {
+			if err := e.Auth.EnsureActor(ctx, tx, id); err != nil {
+				return domain.RosterResult{}, err
+			}
+			res.Changes = append(res.Changes, domain.RosterChange{Op: "add", Type: "actor", ActorID: id})
+		}
+		changes, err := e.reconcileActorRolesTx(ctx, tx, projectID, actorID, id, grants[id], a.Roles)
+		if err != nil {
+			return domain.RosterResult{}, err
+		}
+		res.Changes = append(res.Changes, changes...)
+	}
+	for _, id := range slices.Sorted(maps.Keys(grants)) {
+		if listed[id] {
+			continue
+		}
+		changes, err := e.reconcileActorRolesTx(ctx, tx, projectID, actorID, id, grants[id], nil)
+		if err != nil {
+			return domain.RosterResult{}, err
+		}
+		res.Changes = append(res.Changes, changes...)
+	}
+	if roster.AttestationAuthorities != nil {
+		changes, err := e.reconcileAttestationAuthoritiesTx(ctx, tx, projectID, actorID, roster.AttestationAuthorities)
+		if err != nil {
+			return domain.RosterResult{}, err
+		}
+		res.Changes = append(res.Changes, changes...)
+	}
+
+	ok, err := e.Auth.ActorHasPermission(ctx, tx, projectID, actorID, "rbac.manage")
+	if err != nil {
+		return domain.RosterResult{}, err
+	}
+	if !ok {
+		return domain.RosterResult{}, fmt.Errorf("invalid roster: applying it would take rbac.manage away from %s", actorID)
+	}
+	if dryRun || len(res.Changes) == 0 {
+		return res, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.RosterResult{}, err
+	}
+	e.forgetPermissions(ctx)
+	return res, nil
+}
+
+// reconcileActorRolesTx grants the wanted roles target lacks and revokes the
+// current ones not wanted.
+func (e Engine) reconcileActorRolesTx(ctx context.Context, tx *sql.Tx, projectID, actorID, target string, current, wanted []string) ([]domain.RosterChange, error) {
+	var changes []domain.RosterChange
+	for _, role := range uniqueStrings(wanted) {
+		if slices.Contains(current, role) {
+			continue
+		}
+		if err := e.Repo.AssignRole(ctx, tx, projectID, target, role); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, "rbac.role_granted", projectID, "rbac", projectID, actorID, events.EventPayload{"actor_id": target, "role_id": role}); err != nil {
+			return nil, err
+		}
+		changes = append(changes, domain.RosterChange{Op: "add", Type: "role", ActorID: target, RoleID: role})
+	}
+	for _, role := range current {
+		if slices.Contains(wanted, role) {
+			continue
+		}
+		if err := e.Repo.RevokeRole(ctx, tx, projectID, target, role); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, "rbac.role_revoked", projectID, "rbac", projectID, actorID, events.EventPayload{"actor_id": target, "role_id": role}); err != nil {
+			return nil, err
+		}
+		changes = append(changes, domain.RosterChange{Op: "remove", Type: "role", ActorID: target, RoleID: role})
+	}
+	return changes, nil
+}
+
+// reconcileAttestationAuthoritiesTx makes every attestation kind issuable by
+// exactly the roles wanted lists for it; kinds absent from wanted lose all
+// their roles.
+func (e Engine) reconcileAttestationAuthoritiesTx(ctx context.Context, tx *sql.Tx, projectID, actorID string, wanted map[string][]string) ([]domain.RosterChange, error) {
+	current, err := e.Repo.AttestationAuthoritiesTx(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	kinds := slices.Sorted(maps.Keys(current))
+	for kind := range wanted {
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	slices.Sort(kinds)
+	var changes []domain.RosterChange
+	for _, kind := range kinds {
+		for _, role := range uniqueStrings(wanted[kind]) {
+			if slices.Contains(current[kind], role) {
+				continue
+			}
+			if err := e.Repo.AllowAttestationRole(ctx, tx, projectID, kind, role); err != nil {
+				return nil, err
+			}
+			if err := e.Events.Append(ctx, tx, "rbac.attestation_allowed", projectID, "rbac", projectID, actorID, events.EventPayload{"kind": kind, "role_id": role}); err != nil {
+				return nil, err
+			}
+			changes = append(changes, domain.RosterChange{Op: "add", Type: "attestation_authority", RoleID: role, AttestationKind: kind})
+		}
+		for _, role := range current[kind] {
+			if slices.Contains(wanted[kind], role) {
+				continue
+			}
+			if err := e.Repo.DenyAttestationRole(ctx, tx, projectID, kind, role); err != nil {
+				return nil, err
+			}
+			if err := e.Events.Append(ctx, tx, "rbac.attestation_denied", projectID, "rbac", projectID, actorID, events.EventPayload{"kind": kind, "role_id": role}); err != nil {
+				return nil, err
+			}
+			changes = append(changes, domain.RosterChange{Op: "remove", Type: "attestation_authority", RoleID: role, AttestationKind: kind})
+		}
+	}
+	return changes, nil
+}
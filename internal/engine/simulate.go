@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// PermissionSimulationOptions names the action to explain. Kind qualifies it:
+// the attestation kind for attestation.add, the task type for task.done.
+type PermissionSimulationOptions struct {
+	ProjectID string
+	// ActorID asks; TargetActorID, defaulting to ActorID, is simulated.
+	ActorID       string
+	TargetActorID string
+	Action        string
+	Kind          string
+}
+
+// SimulatePermission explains whether the target actor may perform an action
+// in the project, running the checks the engine would without recording
+// anything: the actor is active, one of its roles grants the permission, a
+// role is an authority for the attestation kind or among the task type's
+// done_roles, and the project is not archived. Explaining another actor's
+// permissions needs rbac.manage.
+func (e Engine) SimulatePermission(ctx context.Context, opts PermissionSimulationOptions) (domain.PermissionSimulation, error) {
+	if opts.Action == "" {
+		return domain.PermissionSimulation{}, errors.New("action required")
+	}
+	if opts.Kind != "" && opts.Action != "attestation.add" && opts.Action != "task.done" {
+		return domain.PermissionSimulation{}, fmt.Errorf("invalid kind for %s: only attestation.add and task.done take one", opts.Action)
+	}
+	if opts.Kind != "" && opts.Action == "task.done" && e.Config != nil && !e.Config.AllowedTaskTypes()[opts.Kind] {
+		return domain.PermissionSimulation{}, fmt.Errorf("unknown task type %s", opts.Kind)
+	}
+	target := opts.TargetActorID
+	if target == "" {
+		target = opts.ActorID
+	}
+	if _, err := e.Repo.GetProject(ctx, opts.ProjectID); err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	defer tx.Rollback()
+	if target != opts.ActorID {
+		if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "rbac.manage"); err != nil {
+			return domain.PermissionSimulation{}, err
+		}
+	}
+	known, err := e.Repo.PermissionExistsTx(ctx, tx, opts.Action)
+	if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	if !known {
+		return domain.PermissionSimulation{}, fmt.Errorf("unknown permission %s", opts.Action)
+	}
+	sources, err := e.Auth.ActorRoleSources(ctx, tx, opts.ProjectID, target)
+	if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+
+	sim := domain.PermissionSimulation{ProjectID: opts.ProjectID, ActorID: target, Action: opts.Action, Kind: opts.Kind}
+	suspended, err := e.Auth.ActorSuspended(ctx, tx, target)
+	if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	active := domain.PermissionCheck{Check: "actor_active", Passed: !suspended, Detail: fmt.Sprintf("actor %s is active", target)}
+	if suspended {
+		active.Detail = fmt.Sprintf("actor %s is suspended", target)
+	}
+	sim.Checks = append(sim.Checks, active)
+
+	roles, err := e.Repo.ListRolesTx(ctx, tx, opts.ProjectID)
+	if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	var granting []string
+	for _, role := range roles {
+		if slices.Contains(role.Permissions, opts.Action) {
+			granting = append(granting, role.ID)
+		}
+	}
+	sim.Checks = append(sim.Checks, roleCheck("permission", "permission "+opts.Action, sources, granting))
+
+	switch {
+	case opts.Action == "attestation.add" && opts.Kind != "":
+		authorities, err := e.Repo.AttestationAuthoritiesTx(ctx, tx, opts.ProjectID)
+		if err != nil {
+			return domain.PermissionSimulation{}, err
+		}
+		sim.Checks = append(sim.Checks, roleCheck("attestation_authority", "authority for attestation kind "+opts.Kind, sources, authorities[opts.Kind]))
+	case opts.Action == "task.done" && opts.Kind != "" && e.Config != nil:
+		allowed := e.Config.DoneRoles(opts.Kind)
+		if len(allowed) == 0 {
+			sim.Checks = append(sim.Checks, domain.PermissionCheck{Check: "done_role", Passed: true, Detail: fmt.Sprintf("task type %s has no done_roles", opts.Kind)})
+		} else {
+			sim.Checks = append(sim.Checks, roleCheck("done_role", "done_roles of task type "+opts.Kind, sources, allowed))
+		}
+	}
+
+	archived := domain.PermissionCheck{Check: "project_active", Passed: true, Detail: fmt.Sprintf("project %s accepts %s", opts.ProjectID, opts.Action)}
+	var archivedErr ProjectArchivedError
+	if err := e.requireNotArchived(ctx, tx, opts.ProjectID, opts.Action); errors.As(err, &archivedErr) {
+		archived.Passed = false
+		archived.Detail = fmt.Sprintf("project %s is archived", opts.ProjectID)
+	} else if err != nil {
+		return domain.PermissionSimulation{}, err
+	}
+	sim.Checks = append(sim.Checks, archived)
+
+	sim.Allowed = true
+	for _, c := range sim.Checks {
+		sim.Allowed = sim.Allowed && c.Passed
+	}
+	return sim, nil
+}
+
+// roleCheck passes when one of the actor's grants is among the roles
+// satisfying what; it lists those grants, or the roles to grant instead.
+func roleCheck(check, what string, sources []domain.RoleSource, satisfying []string) domain.PermissionCheck {
+	c := domain.PermissionCheck{Check: check}
+	for _, src := range sources {
+		if slices.Contains(satisfying, src.RoleID) {
+			c.GrantedBy = append(c.GrantedBy, src)
+		}
+	}
+	if len(c.GrantedBy) > 0 {
+		c.Passed = true
+		var via []string
+		for _, src := range c.GrantedBy {
+			via = append(via, src.RoleID+" ("+src.Scope+")")
+		}
+		c.Detail = what + " granted by " + strings.Join(via, ", ")
+		return c
+	}
+	c.Candidates = append([]string{}, satisfying...)
+	slices.Sort(c.Candidates)
+	if len(c.Candidates) == 0 {
+		c.Detail = "no role grants " + what
+	} else {
+		c.Detail = "missing " + what + ": grant one of " + strings.Join(c.Candidates, ", ")
+	}
+	return c
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// SLABreachedEvent records an open task found past its due date.
+const SLABreachedEvent = "sla.breached"
+
+// parseDueAt reads a due date given as RFC3339 or as a day (YYYY-MM-DD),
+// which means the end of that day in UTC. Empty means no due date.
+func parseDueAt(s string) (*string, error) {
+	return parseDeadline("due_at", s)
+}
+
+// parseDeadline reads field as RFC3339 or as a day (YYYY-MM-DD) meaning the
+// end of that day in UTC, returning nil when empty.
+func parseDeadline(field, s string) (*string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	due, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		day, dayErr := time.Parse(time.DateOnly, s)
+		if dayErr != nil {
+			return nil, fmt.Errorf("invalid %s %q: must be RFC3339 or YYYY-MM-DD", field, s)
+		}
+		due = day.Add(24*time.Hour - time.Second)
+	}
+	v := due.UTC().Format(time.RFC3339)
+	return &v, nil
+}
+
+// slaDueAt returns the due date project.sla gives a task of taskType and
+// priority created at created, counting only project.calendar working time,
+// or nil when no rule matches.
+func slaDueAt(cfg *config.Config, taskType string, priority *int, created time.Time) *string {
+	d, ok := cfg.SLAFor(taskType, priority)
+	if !ok {
+		return nil
+	}
+	v := cfg.Project.Calendar.AddWorkingTime(created, d).UTC().Format(time.RFC3339)
+	return &v
+}
+
+// BreachSLAs records an sla.breached event for each open task of the project
+// past its due date. A task is reported once per due date, so the sweep can
+// run repeatedly; moving the due date later re-arms it.
+func (e Engine) BreachSLAs(ctx context.Context, projectID, actorID string) ([]domain.Task, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if err := e.requireWritable("sla.breach"); err != nil {
+		return nil, err
+	}
+	now := e.now().UTC()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	stamp := now.Format(time.RFC3339)
+	candidates, err := e.Repo.SLABreachCandidatesTx(ctx, tx, projectID, stamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range candidates {
+		if err := e.Repo.MarkSLABreachedTx(ctx, tx, t.ID, stamp); err != nil {
+			return nil, err
+		}
+		payload := events.EventPayload{
+			"due_at":      *t.DueAt,
+			"status":      t.Status,
+			"type":        t.Type,
+			"assignee_id": t.AssigneeID,
+		}
+		if t.Priority != nil {
+			payload["priority"] = *t.Priority
+		}
+		if err := e.Events.Append(ctx, tx, SLABreachedEvent, projectID, "task", t.ID, actorID, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
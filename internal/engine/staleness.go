@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/mail"
+)
+
+// TaskStaleEvent records a task found without an update for
+// project.staleness.days.
+const TaskStaleEvent = "task.stale"
+
+// MarkStaleTasks marks the project's tasks idle for project.staleness.days,
+// counted in project.calendar working days, in one of its statuses and
+// records a task.stale event for each. Tasks already marked since their last
+// update are skipped, so the sweep can run repeatedly. Assignees listed in project.staleness.notify are emailed their
+// newly stale tasks; the marks are committed only once every mail was
+// accepted, so a failed send is retried by the next sweep.
+func (e Engine) MarkStaleTasks(ctx context.Context, projectID, actorID string) ([]domain.Task, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	staleness := e.Config.Project.Staleness
+	if staleness.Days <= 0 {
+		return nil, nil
+	}
+	if err := e.requireWritable("task.stale"); err != nil {
+		return nil, err
+	}
+	now := e.now().UTC()
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	candidates, err := e.Repo.StaleCandidatesTx(ctx, tx, projectID, staleness.StaleStatuses(), e.Config.Project.Calendar.WorkingDaysBefore(now, staleness.Days).UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	stamp := now.Format(time.RFC3339)
+	var stale []domain.Task
+	for _, t := range candidates {
+		if err := e.Repo.MarkTaskStaleTx(ctx, tx, t.ID, stamp); err != nil {
+			return nil, err
+		}
+		if err := e.Events.Append(ctx, tx, TaskStaleEvent, projectID, "task", t.ID, actorID, events.EventPayload{
+			"status":      t.Status,
+			"assignee_id": t.AssigneeID,
+			"updated_at":  t.UpdatedAt,
+			"days":        staleness.Days,
+		}); err != nil {
+			return nil, err
+		}
+		t.Stale = true
+		stale = append(stale, t)
+	}
+	if err := e.notifyStale(ctx, projectID, stale); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
+
+// notifyStale emails each assignee with an address in
+// project.staleness.notify the list of their newly stale tasks.
+func (e Engine) notifyStale(ctx context.Context, projectID string, stale []domain.Task) error {
+	notify := e.Config.Project.Staleness.Notify
+	byAssignee := map[string][]domain.Task{}
+	for _, t := range stale {
+		if t.AssigneeID != nil && notify[*t.AssigneeID] != "" {
+			byAssignee[*t.AssigneeID] = append(byAssignee[*t.AssigneeID], t)
+		}
+	}
+	if len(byAssignee) == 0 {
+		return nil
+	}
+	sender := e.Mail
+	if sender == nil {
+		sender = mail.SMTP{Config: e.Config.Digest.SMTP}
+	}
+	assignees := make([]string, 0, len(byAssignee))
+	for assignee := range byAssignee {
+		assignees = append(assignees, assignee)
+	}
+	sort.Strings(assignees)
+	for _, assignee := range assignees {
+		subject, body := StaleMessage(projectID, e.Config.Project.Staleness.Days, byAssignee[assignee])
+		msg := mail.Message{From: e.Config.Digest.SMTP.From, To: []string{notify[assignee]}, Subject: subject, Body: body}
+		if err := sender.Send(ctx, msg); err != nil {
+			return fmt.Errorf("notify %s of stale tasks: %w", assignee, err)
+		}
+	}
+	return nil
+}
+
+// StaleMessage renders the subject and plain-text body telling an assignee
+// which of their tasks went stale.
+func StaleMessage(projectID string, days int, tasks []domain.Task) (string, string) {
+	subject := fmt.Sprintf("[%s] %d task(s) without an update for %d days", projectID, len(tasks), days)
+	var b strings.Builder
+	fmt.Fprintf(&b, "These tasks assigned to you in %s have not been updated for %d days:\n\n", projectID, days)
+	for _, t := range tasks {
+		fmt.Fprintf(&b, "- %s (%s, %s, last updated %s)\n", t.Title, t.ID, t.Status, digestDay(t.UpdatedAt))
+	}
+	b.WriteString("\nUpdate them, or move them out of their status, to clear the flag.\n")
+	return subject, b.String()
+}
@@ -0,0 +1,507 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// SyncTask is the part of a task that sync exchanges with a remote server.
+// Only the fields in syncFields are merged; the others are copied when a
+// task is created on the other side.
+type SyncTask struct {
+	ID                   string         `json:"id"`
+	Type                 string         `json:"type"`
+	Title                string         `json:"title"`
+	Description          string         `json:"description,omitempty"`
+	Status               string         `json:"status"`
+	AssigneeID           *string        `json:"assignee_id,omitempty"`
+	Priority             *int           `json:"priority,omitempty"`
+	WorkOutcomes         map[string]any `json:"work_outcomes,omitempty"`
+	RequiredAttestations []string       `json:"required_attestations,omitempty"`
+}
+
+// syncFields are the task fields sync merges, in the order they are pushed:
+// work outcomes go before the status that may require them.
+var syncFields = []string{"assignee_id", "priority", "work_outcomes", "status"}
+
+// SyncRemote is the server a project syncs with.
+type SyncRemote interface {
+	// ChangedTasks returns the tasks the server's events after the since
+	// cursor touched and the cursor to pass next time. bulk reports a bulk
+	// update, whose tasks the server's change feed does not name.
+	ChangedTasks(ctx context.Context, since int64) (ids []string, bulk bool, cursor int64, err error)
+	// GetTask returns repo.ErrNotFound when the server has no such task.
+	GetTask(ctx context.Context, id string) (SyncTask, error)
+	// CreateTask creates t with its id and returns the task as the server
+	// created it, which may differ from t (a server creates tasks in their
+	// initial status).
+	CreateTask(ctx context.Context, t SyncTask) (SyncTask, error)
+	// UpdateTask sets the named fields of the server's task to those of t.
+	UpdateTask(ctx context.Context, id string, fields []string, t SyncTask) error
+}
+
+// SyncRejectedError is a change the server refused, as opposed to a server
+// that could not be reached. Sync resolves it in the server's favour and
+// carries on.
+type SyncRejectedError struct {
+	Reason string
+}
+
+func (e SyncRejectedError) Error() string { return "rejected by remote: " + e.Reason }
+
+type SyncOptions struct {
+	ProjectID string
+	ActorID   string
+	// Remote names the server in the sync state, usually its URL.
+	Remote string
+	Client SyncRemote
+}
+
+// syncWrite is a task to create or update locally with a server's values.
+type syncWrite struct {
+	task   SyncTask
+	fields []string
+	create bool
+}
+
+// Sync pushes the tasks changed locally since the last sync with a remote
+// server and pulls those changed there, following event cursors on both
+// sides. A field changed on one side only takes that side's value. When both
+// sides changed it, the server wins, except for work outcomes, which are
+// merged key by key (the server still winning keys changed on both sides).
+// Tasks missing on one side are created there. Pulled changes are recorded
+// as task.synced events, which the next sync does not push back.
+func (e Engine) Sync(ctx context.Context, opts SyncOptions) (domain.SyncReport, error) {
+	report := domain.SyncReport{ProjectID: opts.ProjectID, Remote: opts.Remote, Pushed: []string{}, Pulled: []string{}, Conflicts: []domain.SyncConflict{}}
+	if e.Config == nil {
+		return report, errors.New("config not loaded")
+	}
+	if opts.Client == nil || opts.Remote == "" {
+		return report, errors.New("remote required")
+	}
+	upto, err := e.Repo.LatestEventID(ctx, opts.ProjectID)
+	if err != nil {
+		return report, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "project.sync"); err != nil {
+		return report, err
+	}
+	localCursor, remoteCursor, err := e.Repo.SyncCursorsTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return report, err
+	}
+	writes, err := e.Repo.TaskWritesBetweenTx(ctx, tx, opts.ProjectID, localCursor, upto, "task.synced")
+	if err != nil {
+		return report, err
+	}
+	rawBases, err := e.Repo.SyncBasesTx(ctx, tx, opts.ProjectID, opts.Remote)
+	if err != nil {
+		return report, err
+	}
+	// The remote calls below run without a transaction held.
+	if err := tx.Rollback(); err != nil {
+		return report, err
+	}
+	bases := map[string]SyncTask{}
+	for id, raw := range rawBases {
+		var base SyncTask
+		if err := json.Unmarshal([]byte(raw), &base); err != nil {
+			return report, fmt.Errorf("sync base of task %s: %w", id, err)
+		}
+		bases[id] = base
+	}
+	candidates := map[string]bool{}
+	for _, ev := range writes {
+		if ev.Type != "task.bulk_updated" {
+			candidates[ev.EntityID] = true
+			continue
+		}
+		var payload struct {
+			Updated []string `json:"updated"`
+		}
+		_ = json.Unmarshal([]byte(ev.Payload), &payload)
+		for _, id := range payload.Updated {
+			candidates[id] = true
+		}
+	}
+	remoteIDs, bulk, nextRemote, err := opts.Client.ChangedTasks(ctx, remoteCursor)
+	if err != nil {
+		return report, err
+	}
+	for _, id := range remoteIDs {
+		candidates[id] = true
+	}
+	if bulk {
+		for id := range bases {
+			candidates[id] = true
+		}
+	}
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var pending []syncWrite
+	agreed := map[string]SyncTask{}
+	for _, id := range ids {
+		local, hasLocal, err := e.syncLocalTask(ctx, opts.ProjectID, id)
+		if err != nil {
+			return report, err
+		}
+		remote, err := opts.Client.GetTask(ctx, id)
+		hasRemote := err == nil
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return report, err
+		}
+		switch {
+		case !hasLocal && !hasRemote:
+		case !hasRemote:
+			created, err := opts.Client.CreateTask(ctx, local)
+			if err != nil {
+				var rejected SyncRejectedError
+				if !errors.As(err, &rejected) {
+					return report, err
+				}
+				report.Conflicts = append(report.Conflicts, domain.SyncConflict{TaskID: id, Field: "id", Local: id, Resolution: "skipped", Reason: rejected.Reason})
+				continue
+			}
+			report.Pushed = append(report.Pushed, id)
+			// Fields the server did not take at creation, such as the
+			// status, are pushed as changes from what it created.
+			merged, pull, _, err := e.syncFields(ctx, opts, &report, &created, local, created)
+			if err != nil {
+				return report, err
+			}
+			if len(pull) > 0 {
+				pending = append(pending, syncWrite{task: merged, fields: pull})
+				report.Pulled = append(report.Pulled, id)
+			}
+			agreed[id] = merged
+		case !hasLocal:
+			pending = append(pending, syncWrite{task: remote, create: true})
+			report.Pulled = append(report.Pulled, id)
+			agreed[id] = remote
+		default:
+			var base *SyncTask
+			if b, ok := bases[id]; ok {
+				base = &b
+			}
+			merged, pull, pushed, err := e.syncFields(ctx, opts, &report, base, local, remote)
+			if err != nil {
+				return report, err
+			}
+			if pushed {
+				report.Pushed = append(report.Pushed, id)
+			}
+			if len(pull) > 0 {
+				pending = append(pending, syncWrite{task: merged, fields: pull})
+				report.Pulled = append(report.Pulled, id)
+			}
+			agreed[id] = merged
+		}
+	}
+
+	tx, err = e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+	now := e.now().UTC().Format(time.RFC3339)
+	for _, w := range pending {
+		if err := e.applySyncWriteTx(ctx, tx, opts, w, now); err != nil {
+			return report, err
+		}
+	}
+	for id, t := range agreed {
+		snapshot, err := json.Marshal(t)
+		if err != nil {
+			return report, err
+		}
+		if err := e.Repo.SaveSyncBaseTx(ctx, tx, opts.ProjectID, opts.Remote, id, string(snapshot)); err != nil {
+			return report, err
+		}
+	}
+	if nextRemote < remoteCursor {
+		nextRemote = remoteCursor
+	}
+	if err := e.Repo.SaveSyncCursorsTx(ctx, tx, opts.ProjectID, opts.Remote, upto, nextRemote, now); err != nil {
+		return report, err
+	}
+	if err := tx.Commit(); err != nil {
+		return report, err
+	}
+	report.LocalCursor, report.RemoteCursor = upto, nextRemote
+	return report, nil
+}
+
+// syncFields merges a task present on both sides and pushes the fields the
+// server lacks. A push the server rejects leaves the server's value. It
+// returns the merged task, the fields to pull and whether anything was
+// pushed.
+func (e Engine) syncFields(ctx context.Context, opts SyncOptions, report *domain.SyncReport, base *SyncTask, local, remote SyncTask) (SyncTask, []string, bool, error) {
+	merged, push, conflicts := mergeSyncTask(base, local, remote)
+	report.Conflicts = append(report.Conflicts, conflicts...)
+	pushed := false
+	for _, group := range splitStatus(push) {
+		err := opts.Client.UpdateTask(ctx, local.ID, group, merged)
+		var rejected SyncRejectedError
+		switch {
+		case err == nil:
+			pushed = true
+		case errors.As(err, &rejected):
+			for _, f := range group {
+				report.Conflicts = append(report.Conflicts, domain.SyncConflict{TaskID: local.ID, Field: f, Local: syncValue(merged, f), Remote: syncValue(remote, f), Resolution: "remote", Reason: rejected.Reason})
+				setSyncField(&merged, remote, f)
+			}
+		default:
+			return merged, nil, pushed, err
+		}
+	}
+	var pull []string
+	for _, f := range syncFields {
+		if !sameSyncValue(syncValue(merged, f), syncValue(local, f)) {
+			pull = append(pull, f)
+		}
+	}
+	return merged, pull, pushed, nil
+}
+
+// syncLocalTask loads a task of the project as sync exchanges it. A task of
+// another project is reported missing, and its id left alone.
+func (e Engine) syncLocalTask(ctx context.Context, projectID, id string) (SyncTask, bool, error) {
+	t, err := e.Repo.GetTask(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) || (err == nil && t.ProjectID != projectID) {
+		return SyncTask{}, false, nil
+	}
+	if err != nil {
+		return SyncTask{}, false, err
+	}
+	st := SyncTask{ID: t.ID, Type: t.Type, Title: t.Title, Description: t.Description, Status: t.Status, AssigneeID: t.AssigneeID, Priority: t.Priority}
+	if t.WorkOutcomesJSON != nil && *t.WorkOutcomesJSON != "" {
+		if err := json.Unmarshal([]byte(*t.WorkOutcomesJSON), &st.WorkOutcomes); err != nil {
+			return st, false, fmt.Errorf("work outcomes of task %s: %w", t.ID, err)
+		}
+	}
+	if t.RequiredAttestationsJSON != nil && *t.RequiredAttestationsJSON != "" {
+		_ = json.Unmarshal([]byte(*t.RequiredAttestationsJSON), &st.RequiredAttestations)
+	}
+	return st, true, nil
+}
+
+// applySyncWriteTx stores a server's values locally and records them as a
+// task.synced event.
+func (e Engine) applySyncWriteTx(ctx context.Context, tx *sql.Tx, opts SyncOptions, w syncWrite, now string) error {
+	st := w.task
+	var t domain.Task
+	var from string
+	if w.create {
+		t = domain.Task{ID: st.ID, ProjectID: opts.ProjectID, Type: st.Type, Title: st.Title, Description: st.Description, CreatedAt: now}
+		reqJSON, err := marshalStringSlice(st.RequiredAttestations)
+		if err != nil {
+			return err
+		}
+		t.RequiredAttestationsJSON = reqJSON
+		w.fields = syncFields
+	} else {
+		var err error
+		if t, err = e.Repo.GetTaskTx(ctx, tx, st.ID); err != nil {
+			return err
+		}
+		from = t.Status
+	}
+	for _, f := range w.fields {
+		switch f {
+		case "status":
+			t.Status = st.Status
+		case "assignee_id":
+			t.AssigneeID = st.AssigneeID
+		case "priority":
+			t.Priority = st.Priority
+		case "work_outcomes":
+			t.WorkOutcomesJSON = nil
+			if len(st.WorkOutcomes) > 0 {
+				b, err := json.Marshal(st.WorkOutcomes)
+				if err != nil {
+					return err
+				}
+				outcomes := string(b)
+				t.WorkOutcomesJSON = &outcomes
+			}
+		}
+	}
+	if t.Status != from {
+		if t.Status == e.workflow(t.Type).Done {
+			t.CompletedAt = &now
+		} else {
+			t.CompletedAt = nil
+		}
+	}
+	t.UpdatedAt = now
+	payload := events.EventPayload{
+		"remote":      opts.Remote,
+		"from_status": from,
+		"to_status":   t.Status,
+	}
+	if w.create {
+		if err := e.Repo.InsertTask(ctx, tx, t); err != nil {
+			return err
+		}
+		payload["created"] = true
+	} else {
+		if err := e.Repo.UpdateTask(ctx, tx, t); err != nil {
+			return err
+		}
+		payload["changed"] = w.fields
+	}
+	return e.Events.Append(ctx, tx, "task.synced", opts.ProjectID, "task", t.ID, opts.ActorID, payload)
+}
+
+// mergeSyncTask merges the sync fields of a task changed locally, remotely
+// or both since base, the snapshot both sides last agreed on (nil for a
+// task never synced, where any difference counts as changed on both sides).
+// It returns the merged task, the fields to push and the conflicts.
+func mergeSyncTask(base *SyncTask, local, remote SyncTask) (SyncTask, []string, []domain.SyncConflict) {
+	merged := local
+	var push []string
+	var conflicts []domain.SyncConflict
+	for _, f := range syncFields {
+		l, r := syncValue(local, f), syncValue(remote, f)
+		if sameSyncValue(l, r) {
+			continue
+		}
+		switch {
+		case base != nil && sameSyncValue(l, syncValue(*base, f)):
+			setSyncField(&merged, remote, f)
+		case base != nil && sameSyncValue(r, syncValue(*base, f)):
+			push = append(push, f)
+		case f == "work_outcomes":
+			var b map[string]any
+			if base != nil {
+				b = base.WorkOutcomes
+			}
+			merged.WorkOutcomes = mergeOutcomes(b, local.WorkOutcomes, remote.WorkOutcomes)
+			if !sameSyncValue(syncValue(merged, f), r) {
+				push = append(push, f)
+			}
+			conflicts = append(conflicts, domain.SyncConflict{TaskID: local.ID, Field: f, Local: l, Remote: r, Resolution: "merged"})
+		default:
+			setSyncField(&merged, remote, f)
+			conflicts = append(conflicts, domain.SyncConflict{TaskID: local.ID, Field: f, Local: l, Remote: r, Resolution: "remote"})
+		}
+	}
+	return merged, push, conflicts
+}
+
+// mergeOutcomes merges work outcomes key by key: a key changed on one side
+// takes that side's value, nested objects changed on both sides are merged
+// the same way, and other keys changed on both sides take the remote value.
+func mergeOutcomes(base, local, remote map[string]any) map[string]any {
+	keys := map[string]bool{}
+	for _, m := range []map[string]any{base, local, remote} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	out := map[string]any{}
+	for k := range keys {
+		b, inBase := base[k]
+		l, inLocal := local[k]
+		r, inRemote := remote[k]
+		var v any
+		var ok bool
+		switch {
+		case inLocal == inRemote && sameSyncValue(l, r):
+			v, ok = l, inLocal
+		case inLocal == inBase && sameSyncValue(l, b):
+			v, ok = r, inRemote
+		case inRemote == inBase && sameSyncValue(r, b):
+			v, ok = l, inLocal
+		default:
+			lm, lok := l.(map[string]any)
+			rm, rok := r.(map[string]any)
+			if lok && rok {
+				bm, _ := b.(map[string]any)
+				v, ok = mergeOutcomes(bm, lm, rm), true
+			} else {
+				v, ok = r, inRemote
+			}
+		}
+		if ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// splitStatus splits fields to push into the status and the others, so a
+// rejected status transition does not take the other fields down with it.
+func splitStatus(fields []string) [][]string {
+	var groups [][]string
+	if others := slices.DeleteFunc(slices.Clone(fields), func(f string) bool { return f == "status" }); len(others) > 0 {
+		groups = append(groups, others)
+	}
+	if slices.Contains(fields, "status") {
+		groups = append(groups, []string{"status"})
+	}
+	return groups
+}
+
+func syncValue(t SyncTask, field string) any {
+	switch field {
+	case "status":
+		return t.Status
+	case "assignee_id":
+		if t.AssigneeID == nil || *t.AssigneeID == "" {
+			return nil
+		}
+		return *t.AssigneeID
+	case "priority":
+		if t.Priority == nil {
+			return nil
+		}
+		return *t.Priority
+	case "work_outcomes":
+		if len(t.WorkOutcomes) == 0 {
+			return nil
+		}
+		return t.WorkOutcomes
+	}
+	return nil
+}
+
+func setSyncField(t *SyncTask, from SyncTask, field string) {
+	switch field {
+	case "status":
+		t.Status = from.Status
+	case "assignee_id":
+		t.AssigneeID = from.AssigneeID
+	case "priority":
+		t.Priority = from.Priority
+	case "work_outcomes":
+		t.WorkOutcomes = from.WorkOutcomes
+	}
+}
+
+// sameSyncValue compares values by their JSON form, so numbers decoded from
+// either side compare equal.
+func sameSyncValue(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// MaxBulkTaskUpdate caps the tasks accepted by BulkUpdateTasks.
+const MaxBulkTaskUpdate = 200
+
+// BulkTaskUpdateOptions applies one change set to many tasks of a project.
+type BulkTaskUpdateOptions struct {
+	ProjectID         string
+	TaskIDs           []string
+	Status            string
+	SetIteration      *string
+	IterationProvided bool
+	Assign            *string
+	AssignProvided    bool
+	ActorID           string
+	Force             bool
+	// Atomic rolls back every task when any of them fails; otherwise failed
+	// tasks are reported and the rest are applied.
+	Atomic bool
+}
+
+// TaskUpdateResult is the outcome for one task of a bulk update; Err is set
+// when the task was left unchanged.
+type TaskUpdateResult struct {
+	TaskID string
+	Task   domain.Task
+	Err    error
+}
+
+// BulkUpdateTasks applies the change set to each task in one transaction,
+// with the same checks as UpdateTask. Each task runs in a savepoint, and the
+// whole operation is recorded as a single task.bulk_updated event.
+func (e Engine) BulkUpdateTasks(ctx context.Context, opts BulkTaskUpdateOptions) ([]TaskUpdateResult, error) {
+	if e.Config == nil {
+		return nil, errors.New("config not loaded")
+	}
+	if len(opts.TaskIDs) == 0 {
+		return nil, errors.New("at least one task id required")
+	}
+	if len(opts.TaskIDs) > MaxBulkTaskUpdate {
+		return nil, fmt.Errorf("invalid bulk update: %d tasks (max %d)", len(opts.TaskIDs), MaxBulkTaskUpdate)
+	}
+	if opts.Status == "" && !opts.IterationProvided && !opts.AssignProvided {
+		return nil, errors.New("status, iteration or assignee required")
+	}
+	seen := map[string]bool{}
+	for _, id := range opts.TaskIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("invalid bulk update: duplicate task id %s", id)
+		}
+		seen[id] = true
+	}
+	if opts.Force && e.forceNeedsApproval(ctx) {
+		return nil, e.requestForce(ctx, opts.ProjectID, "task.bulk_update", "project", opts.ProjectID, opts, opts.ActorID)
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, opts.ProjectID, opts.ActorID, "task.update"); err != nil {
+		return nil, err
+	}
+	results := make([]TaskUpdateResult, len(opts.TaskIDs))
+	updated, failed := []string{}, []string{}
+	for i, id := range opts.TaskIDs {
+		results[i].TaskID = id
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_task`); err != nil {
+			return nil, err
+		}
+		task, err := e.bulkUpdateOneTx(ctx, tx, id, opts)
+		if err != nil {
+			if opts.Atomic {
+				return nil, fmt.Errorf("task %s: %w", id, err)
+			}
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO bulk_task`); rbErr != nil {
+				return nil, rbErr
+			}
+			results[i].Err = err
+			failed = append(failed, id)
+		} else {
+			results[i].Task = task
+			updated = append(updated, id)
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE bulk_task`); err != nil {
+			return nil, err
+		}
+	}
+	payload := events.EventPayload{
+		"task_ids": opts.TaskIDs,
+		"updated":  updated,
+		"failed":   failed,
+	}
+	if opts.Status != "" {
+		payload["status"] = opts.Status
+	}
+	if opts.IterationProvided {
+		payload["iteration_id"] = opts.SetIteration
+	}
+	if opts.AssignProvided {
+		payload["assignee_id"] = opts.Assign
+	}
+	if opts.Force {
+		payload["forced"] = true
+	}
+	if err := e.Events.Append(ctx, tx, "task.bulk_updated", opts.ProjectID, "project", opts.ProjectID, opts.ActorID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	e.sendHookDeliveries(ctx)
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Task.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, results[i].TaskID)
+		}
+	}
+	return results, nil
+}
+
+func (e Engine) bulkUpdateOneTx(ctx context.Context, tx *sql.Tx, taskID string, opts BulkTaskUpdateOptions) (domain.Task, error) {
+	t, err := e.Repo.GetTaskTx(ctx, tx, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if t.ProjectID != opts.ProjectID {
+		return domain.Task{}, repo.ErrNotFound
+	}
+	return e.updateTaskTx(ctx, tx, t, TaskUpdateOptions{
+		ID:                taskID,
+		Status:            opts.Status,
+		SetIteration:      opts.SetIteration,
+		IterationProvided: opts.IterationProvided,
+		Assign:            opts.Assign,
+		AssignProvided:    opts.AssignProvided,
+		ActorID:           opts.ActorID,
+		Force:             opts.Force,
+	})
+}
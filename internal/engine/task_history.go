@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// TaskHistory returns the lifecycle of a task, oldest first: status and
+// policy changes, leases, attestations, work outcome edits, validations and
+// logged time.
+func (e Engine) TaskHistory(ctx context.Context, taskID, actorID string) ([]domain.TaskHistoryEntry, error) {
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.read"); err != nil {
+		return nil, err
+	}
+	atts, err := e.Repo.EntityAttestationsTx(ctx, tx, "task", t.ID)
+	if err != nil {
+		return nil, err
+	}
+	attIDs := make([]string, 0, len(atts))
+	for _, att := range atts {
+		attIDs = append(attIDs, att.ID)
+	}
+	evts, err := e.Repo.TaskHistoryEventsTx(ctx, tx, t.ProjectID, t.ID, attIDs)
+	if err != nil {
+		return nil, err
+	}
+	entries := []domain.TaskHistoryEntry{}
+	added, expired := map[string]bool{}, map[string]bool{}
+	for _, ev := range evts {
+		payload := map[string]any{}
+		if ev.Payload != "" {
+			_ = json.Unmarshal([]byte(ev.Payload), &payload)
+		}
+		entry, ok := taskHistoryEntry(ev, payload, t.ID)
+		if !ok {
+			continue
+		}
+		attID, _ := payload["attestation_id"].(string)
+		switch ev.Type {
+		case "attestation.added":
+			added[attID] = true
+		case "attestation.expired":
+			expired[attID] = true
+		}
+		entries = append(entries, entry)
+	}
+	// Attestations whose events were compacted away are still in their table.
+	for _, att := range atts {
+		if !added[att.ID] {
+			entries = append(entries, domain.TaskHistoryEntry{
+				TS:       att.TS,
+				Category: "attestation",
+				Type:     "attestation.added",
+				ActorID:  att.ActorID,
+				Summary:  fmt.Sprintf("attestation %s added", att.Kind),
+				Payload:  map[string]any{"attestation_id": att.ID, "kind": att.Kind},
+			})
+		}
+		if att.ExpiredAt != nil && !expired[att.ID] {
+			entries = append(entries, domain.TaskHistoryEntry{
+				TS:       *att.ExpiredAt,
+				Category: "attestation",
+				Type:     "attestation.expired",
+				Summary:  fmt.Sprintf("attestation %s expired", att.Kind),
+				Payload:  map[string]any{"attestation_id": att.ID, "kind": att.Kind},
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].TS < entries[j].TS })
+	return entries, nil
+}
+
+// taskHistoryEntry describes one event of a task's history; ok is false for
+// project-level events that do not concern taskID.
+func taskHistoryEntry(ev domain.Event, payload map[string]any, taskID string) (domain.TaskHistoryEntry, bool) {
+	entry := domain.TaskHistoryEntry{TS: ev.TS, Type: ev.Type, ActorID: ev.ActorID, EventID: ev.ID, Category: "update", Summary: ev.Type}
+	if len(payload) > 0 {
+		entry.Payload = payload
+	}
+	str := func(key string) string {
+		v, _ := payload[key].(string)
+		return v
+	}
+	forced := ""
+	if payload["forced"] == true {
+		forced = " (forced)"
+	}
+	switch ev.Type {
+	case "task.created":
+		entry.Category = "created"
+		entry.Summary = fmt.Sprintf("created %q as %s", str("title"), str("status"))
+	case "task.updated":
+		from, to := str("from_status"), str("to_status")
+		changed := payloadStrings(payload["changed"])
+		var parts []string
+		if from != to {
+			entry.Category = "status"
+			if original := str("duplicate_of"); original != "" {
+				to += " as duplicate of " + original
+			}
+			parts = append(parts, fmt.Sprintf("status %s -> %s%s", from, to, forced))
+		}
+		if slices.Contains(changed, "work_outcomes") {
+			if entry.Category == "update" {
+				entry.Category = "outcomes"
+			}
+			parts = append(parts, "work outcomes edited")
+		}
+		if slices.Contains(changed, "assignee_id") {
+			if entry.Category == "update" {
+				entry.Category = "assignment"
+			}
+			if assignee := str("assignee_id"); assignee != "" {
+				parts = append(parts, "assigned to "+assignee)
+			} else {
+				parts = append(parts, "unassigned")
+			}
+		}
+		var other []string
+		for _, field := range changed {
+			if field != "work_outcomes" && field != "assignee_id" {
+				other = append(other, field)
+			}
+		}
+		if len(other) > 0 {
+			parts = append(parts, "updated "+strings.Join(other, ", "))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "updated")
+		}
+		entry.Summary = strings.Join(parts, "; ")
+	case "task.done":
+		entry.Category = "status"
+		entry.Summary = fmt.Sprintf("completed as %s%s", str("status"), forced)
+	case "task.reverted":
+		entry.Category = "status"
+		entry.Summary = fmt.Sprintf("reverted status %s -> %s", str("from_status"), str("to_status"))
+	case "task.synced":
+		from, to := str("from_status"), str("to_status")
+		switch {
+		case payload["created"] == true:
+			entry.Summary = fmt.Sprintf("pulled from %s as %s", str("remote"), to)
+		case from != to:
+			entry.Category = "status"
+			entry.Summary = fmt.Sprintf("synced from %s: status %s -> %s", str("remote"), from, to)
+		default:
+			entry.Summary = fmt.Sprintf("synced from %s: updated %s", str("remote"), strings.Join(payloadStrings(payload["changed"]), ", "))
+		}
+	case SLABreachedEvent:
+		entry.Category = "sla"
+		entry.Summary = "SLA breached: due " + str("due_at")
+	case TaskRelationAddedEvent:
+		entry.Summary = fmt.Sprintf("related: %s %s", str("type"), str("related_task_id"))
+	case TaskRelationRemovedEvent:
+		entry.Summary = fmt.Sprintf("relation removed: %s %s", str("type"), str("related_task_id"))
+	case TaskEscalatedEvent:
+		entry.Summary = fmt.Sprintf("priority escalated %v -> %v after %v days", payload["from"], payload["to"], payload["after_days"])
+	case TaskStaleEvent:
+		entry.Summary = fmt.Sprintf("stale: no update for %v days in %s", payload["days"], str("status"))
+	case "task.bulk_updated":
+		if !slices.Contains(payloadStrings(payload["updated"]), taskID) {
+			return entry, false
+		}
+		if status := str("status"); status != "" {
+			entry.Category = "status"
+			entry.Summary = "bulk update: status -> " + status + forced
+		} else {
+			entry.Summary = "bulk update"
+		}
+	case "task.policy.applied":
+		entry.Category = "policy"
+		entry.Summary = fmt.Sprintf("policy %s applied", str("policy_name"))
+	case "task.policy.updated":
+		entry.Category = "policy"
+		entry.Summary = fmt.Sprintf("policy changed to %s", str("policy_name"))
+	case "policy.override":
+		entry.Category = "policy"
+		require := payloadStrings(payload["new_require"])
+		if require == nil {
+			require = payloadStrings(payload["require"])
+		}
+		entry.Summary = "policy overridden, requires " + strings.Join(require, ", ")
+	case "compliance.flagged":
+		entry.Category = "policy"
+		entry.Summary = "flagged non-compliant, lacks " + strings.Join(append(payloadStrings(payload["missing"]), payloadStrings(payload["tightened"])...), ", ")
+	case "lease.claimed":
+		entry.Category = "lease"
+		entry.Summary = "lease claimed until " + str("expires_at")
+	case "lease.released":
+		entry.Category = "lease"
+		entry.Summary = "lease released"
+		if reason := str("reason"); reason != "" {
+			entry.Summary += " (" + reason + ")"
+		}
+	case "attestation.added":
+		entry.Category = "attestation"
+		entry.Summary = fmt.Sprintf("attestation %s added", str("kind"))
+	case "attestation.expired":
+		entry.Category = "attestation"
+		entry.Summary = fmt.Sprintf("attestation %s expired", str("kind"))
+	case "attestation.evidence_added":
+		entry.Category = "attestation"
+		entry.Summary = fmt.Sprintf("evidence %s attached to attestation %s", str("name"), ev.EntityID)
+	case "validation.created", "validation.updated":
+		if str("task_id") != taskID {
+			return entry, false
+		}
+		entry.Category = "validation"
+		if ev.Type == "validation.created" {
+			entry.Summary = fmt.Sprintf("validation %s opened as %s", str("kind"), str("status"))
+		} else {
+			entry.Summary = "validation " + str("status")
+		}
+	case "task.time_logged":
+		entry.Category = "time"
+		if minutes, ok := payload["minutes"].(float64); ok {
+			entry.Summary = fmt.Sprintf("logged %d minutes", int(minutes))
+		}
+	}
+	return entry, true
+}
+
+func payloadStrings(v any) []string {
+	items, _ := v.([]any)
+	var res []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// changedTaskFields names the fields, other than status, that an update
+// changed; task.updated events record them for the task history.
+func changedTaskFields(before, after domain.Task, opts TaskUpdateOptions) []string {
+	var changed []string
+	if !equalPtr(before.AssigneeID, after.AssigneeID) {
+		changed = append(changed, "assignee_id")
+	}
+	if !equalPtr(before.IterationID, after.IterationID) {
+		changed = append(changed, "iteration_id")
+	}
+	if !equalPtr(before.ParentID, after.ParentID) {
+		changed = append(changed, "parent_id")
+	}
+	if before.Component != after.Component {
+		changed = append(changed, "component")
+	}
+	if !equalPtr(before.Priority, after.Priority) {
+		changed = append(changed, "priority")
+	}
+	if !equalPtr(before.Estimate, after.Estimate) {
+		changed = append(changed, "estimate")
+	}
+	if !equalPtr(before.DueAt, after.DueAt) {
+		changed = append(changed, "due_at")
+	}
+	if !equalPtr(before.WorkOutcomesJSON, after.WorkOutcomesJSON) {
+		changed = append(changed, "work_outcomes")
+	}
+	if len(opts.AddDeps) > 0 || len(opts.RemoveDeps) > 0 {
+		changed = append(changed, "depends_on")
+	}
+	if !maps.Equal(before.ExternalRefs, after.ExternalRefs) {
+		changed = append(changed, "external_refs")
+	}
+	return changed
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// RevertUnavailableError reports why a task has no transition to revert.
+type RevertUnavailableError struct {
+	TaskID string
+	Reason string
+}
+
+func (e RevertUnavailableError) Error() string {
+	return fmt.Sprintf("cannot revert task %s: %s", e.TaskID, e.Reason)
+}
+
+// RevertTask undoes the last status transition of a task, restoring the status
+// recorded as its origin. It needs task.revert, skips the lease, workflow and
+// policy checks, and does not run transition hooks. completed_at is cleared
+// unless the restored status is the workflow's done state.
+func (e Engine) RevertTask(ctx context.Context, taskID, actorID string) (domain.Task, error) {
+	if e.Config == nil {
+		return domain.Task{}, errors.New("config not loaded")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer tx.Rollback()
+	// Read the task in the transaction the revert is written in, so it
+	// reverts the status it checked.
+	t, err := e.Repo.GetTaskTx(ctx, tx, taskID)
+	if err != nil {
+		return t, err
+	}
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.revert"); err != nil {
+		return t, err
+	}
+	evts, err := e.Repo.TaskStatusEventsTx(ctx, tx, t.ID)
+	if err != nil {
+		return t, err
+	}
+	var last domain.Event
+	var from, to string
+	for _, ev := range evts {
+		var payload struct {
+			From   string `json:"from_status"`
+			To     string `json:"to_status"`
+			Status string `json:"status"`
+		}
+		if ev.Payload != "" {
+			_ = json.Unmarshal([]byte(ev.Payload), &payload)
+		}
+		if ev.Type == "task.done" {
+			payload.To = payload.Status
+			if payload.From == "" {
+				return t, RevertUnavailableError{TaskID: t.ID, Reason: fmt.Sprintf("previous status of event %d not recorded", ev.ID)}
+			}
+		}
+		if payload.From != "" && payload.From != payload.To {
+			last, from, to = ev, payload.From, payload.To
+			break
+		}
+	}
+	if last.ID == 0 {
+		return t, RevertUnavailableError{TaskID: t.ID, Reason: "no status transition recorded"}
+	}
+	if to != t.Status {
+		return t, RevertUnavailableError{TaskID: t.ID, Reason: fmt.Sprintf("status is %s but the last transition was to %s", t.Status, to)}
+	}
+	now := e.now().UTC().Format(time.RFC3339)
+	t.Status = from
+	t.UpdatedAt = now
+	if from == e.workflow(t.Type).Done {
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+	}
+	if err := e.Repo.UpdateTask(ctx, tx, t); err != nil {
+		return t, err
+	}
+	if err := e.Events.Append(ctx, tx, "task.reverted", t.ProjectID, "task", t.ID, actorID, events.EventPayload{
+		"from_status":       to,
+		"to_status":         from,
+		"reverted_event_id": last.ID,
+	}); err != nil {
+		return t, err
+	}
+	if err := tx.Commit(); err != nil {
+		return t, err
+	}
+	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
+	return t, nil
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+)
+
+// TimeLogOptions records time spent on a task.
+type TimeLogOptions struct {
+	TaskID  string
+	Minutes int
+	Note    string
+	ActorID string
+}
+
+// LogTime adds a time entry to a task.
+func (e Engine) LogTime(ctx context.Context, opts TimeLogOptions) (domain.TimeEntry, error) {
+	if opts.Minutes <= 0 {
+		return domain.TimeEntry{}, errors.New("invalid minutes: must be positive")
+	}
+	t, err := e.Repo.GetTask(ctx, opts.TaskID)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, opts.ActorID, "task.time.log"); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	te := domain.TimeEntry{
+		ID:        uuid.NewString(),
+		TaskID:    t.ID,
+		ProjectID: t.ProjectID,
+		ActorID:   opts.ActorID,
+		Minutes:   opts.Minutes,
+		Note:      strings.TrimSpace(opts.Note),
+		CreatedAt: e.now().UTC().Format(time.RFC3339),
+	}
+	if err := e.Repo.InsertTimeEntryTx(ctx, tx, te); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if err := e.Events.Append(ctx, tx, "task.time_logged", t.ProjectID, "task", t.ID, opts.ActorID, events.EventPayload{
+		"entry_id": te.ID,
+		"minutes":  te.Minutes,
+	}); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	return te, nil
+}
+
+// ListTimeEntries returns the time logged on a task, oldest first.
+func (e Engine) ListTimeEntries(ctx context.Context, taskID, actorID string) ([]domain.TimeEntry, error) {
+	t, err := e.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.read"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListTimeEntriesTx(ctx, tx, t.ID)
+}
+
+// IterationTimeTotals returns the time logged per iteration of a project.
+func (e Engine) IterationTimeTotals(ctx context.Context, projectID, actorID string) ([]domain.IterationTime, error) {
+	if _, err := e.Repo.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "iteration.list"); err != nil {
+		return nil, err
+	}
+	return e.Repo.IterationTimeTotalsTx(ctx, tx, projectID)
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+const defaultWebhookTimeout = 5 * time.Second
+
+// WebhookTestEvent is the event type of sample deliveries sent by TestWebhook.
+const WebhookTestEvent = "webhook.test"
+
+type webhookEvent struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	ProjectID  string          `json:"project_id"`
+	EntityKind string          `json:"entity_kind"`
+	EntityID   string          `json:"entity_id,omitempty"`
+	ActorID    string          `json:"actor_id"`
+	TS         string          `json:"ts"`
+	Payload    json.RawMessage `json:"payload"`
+	PayloadRaw string          `json:"payload_raw,omitempty"`
+	// ExternalRefs are the entity's ids in external systems, so receivers
+	// can correlate it with their own records.
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+}
+
+func webhookBody(evt domain.Event, refs map[string]string) ([]byte, error) {
+	payload := json.RawMessage([]byte("{}"))
+	var raw string
+	if evt.Payload != "" {
+		if json.Valid([]byte(evt.Payload)) {
+			payload = json.RawMessage([]byte(evt.Payload))
+		} else {
+			raw = evt.Payload
+		}
+	}
+	return json.Marshal(webhookEvent{
+		ID:           evt.ID,
+		Type:         evt.Type,
+		ProjectID:    evt.ProjectID,
+		EntityKind:   evt.EntityKind,
+		EntityID:     evt.EntityID,
+		ActorID:      evt.ActorID,
+		TS:           evt.TS,
+		Payload:      payload,
+		PayloadRaw:   raw,
+		ExternalRefs: refs,
+	})
+}
+
+// SignWebhook returns the X-Workline-Signature header of body: "sha256=" and
+// the hex HMAC-SHA256 of the body keyed with the webhook secret.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverWebhookEvent posts evt to hook and logs the attempt. A failed
+// delivery is reported through the returned delivery's Error; the error
+// result is only set when the attempt could not be logged.
+func (e Engine) DeliverWebhookEvent(ctx context.Context, projectID string, hook config.WebhookConfig, evt domain.Event) (domain.WebhookDelivery, error) {
+	var refs map[string]string
+	if evt.EntityID != "" {
+		byEntity, err := e.Repo.ExternalRefsFor(ctx, evt.EntityKind, []string{evt.EntityID})
+		if err != nil {
+			return domain.WebhookDelivery{}, err
+		}
+		refs = byEntity[evt.EntityID]
+	}
+	body, err := webhookBody(evt, refs)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return e.deliverWebhook(ctx, projectID, hook, evt.ID, evt.Type, body, nil)
+}
+
+func (e Engine) deliverWebhook(ctx context.Context, projectID string, hook config.WebhookConfig, eventID int64, eventType string, body []byte, replayOf *string) (domain.WebhookDelivery, error) {
+	d := domain.WebhookDelivery{
+		ID:          uuid.NewString(),
+		ProjectID:   projectID,
+		URL:         hook.URL,
+		EventID:     eventID,
+		EventType:   eventType,
+		Body:        string(body),
+		AttemptedAt: e.now().UTC().Format(time.RFC3339),
+		ReplayOf:    replayOf,
+	}
+	deliveryID := d.ID
+	if eventID > 0 {
+		// Receivers dedupe on the event id, replays included.
+		deliveryID = strconv.FormatInt(eventID, 10)
+	}
+	start := time.Now()
+	code, err := postWebhook(ctx, hook, projectID, eventType, deliveryID, body)
+	d.StatusCode, d.DurationMS = code, time.Since(start).Milliseconds()
+	if err != nil {
+		d.Error = err.Error()
+	}
+	return d, e.Repo.InsertWebhookDelivery(context.WithoutCancel(ctx), d)
+}
+
+func postWebhook(ctx context.Context, hook config.WebhookConfig, projectID, eventType, deliveryID string, body []byte) (int, error) {
+	timeout := defaultWebhookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Workline-Event", eventType)
+	req.Header.Set("X-Workline-Delivery", deliveryID)
+	req.Header.Set("X-Workline-Project", projectID)
+	if strings.TrimSpace(hook.Secret) != "" {
+		req.Header.Set("X-Workline-Secret", hook.Secret)
+		req.Header.Set("X-Workline-Signature", SignWebhook(hook.Secret, body))
+	}
+	res, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return res.StatusCode, fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return res.StatusCode, nil
+}
+
+// ListWebhookDeliveries returns a project's latest webhook delivery attempts,
+// newest first. Needs webhook.manage.
+func (e Engine) ListWebhookDeliveries(ctx context.Context, projectID string, failed bool, limit int, actorID string) ([]domain.WebhookDelivery, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "webhook.manage"); err != nil {
+		return nil, err
+	}
+	return e.Repo.ListWebhookDeliveriesTx(ctx, tx, projectID, failed, limit)
+}
+
+// ReplayWebhookDelivery resends the body of a logged delivery to its webhook,
+// signed with the webhook's current secret, and returns the new attempt.
+// Needs webhook.manage; the webhook must still be configured.
+func (e Engine) ReplayWebhookDelivery(ctx context.Context, projectID, deliveryID, actorID string) (domain.WebhookDelivery, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "webhook.manage"); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	d, err := e.Repo.GetWebhookDeliveryTx(ctx, tx, deliveryID)
+	if err != nil {
+		return d, err
+	}
+	if d.ProjectID != projectID {
+		return domain.WebhookDelivery{}, repo.ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return d, err
+	}
+	hook, ok := e.webhook(d.URL)
+	if !ok {
+		return d, fmt.Errorf("invalid delivery %s: webhook %s is no longer configured", d.ID, d.URL)
+	}
+	return e.deliverWebhook(ctx, projectID, hook, d.EventID, d.EventType, []byte(d.Body), &d.ID)
+}
+
+// TestWebhook sends a sample webhook.test event to url and logs the attempt.
+// A configured webhook with that url lends its secret and timeout; secret,
+// when set, takes precedence. Needs webhook.manage.
+func (e Engine) TestWebhook(ctx context.Context, projectID, url, secret, actorID string) (domain.WebhookDelivery, error) {
+	if strings.TrimSpace(url) == "" {
+		return domain.WebhookDelivery{}, fmt.Errorf("webhook url is required")
+	}
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	defer tx.Rollback()
+	if err := e.requirePermission(ctx, tx, projectID, actorID, "webhook.manage"); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	hook, ok := e.webhook(url)
+	if !ok {
+		hook = config.WebhookConfig{URL: url}
+	}
+	if secret != "" {
+		hook.Secret = secret
+	}
+	body, err := webhookBody(domain.Event{
+		Type:       WebhookTestEvent,
+		ProjectID:  projectID,
+		EntityKind: "project",
+		EntityID:   projectID,
+		ActorID:    actorID,
+		TS:         e.now().UTC().Format(time.RFC3339),
+		Payload:    `{"test":true}`,
+	}, nil)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return e.deliverWebhook(ctx, projectID, hook, 0, WebhookTestEvent, body, nil)
+}
+
+// webhook returns the configured webhook posting to url.
+func (e Engine) webhook(url string) (config.WebhookConfig, bool) {
+	if e.Config == nil {
+		return config.WebhookConfig{}, false
+	}
+	for _, hook := range e.Config.Webhooks {
+		if hook.URL == url {
+			return hook, true
+		}
+	}
+	return config.WebhookConfig{}, false
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// ErrWorkOutcomesConflict is returned when a task's work outcomes kept
+// changing underneath a read-modify-write update.
+var ErrWorkOutcomesConflict = errors.New("work outcomes changed concurrently; retry")
+
+// workOutcomesAttempts bounds how often UpdateWorkOutcomes re-reads a task
+// whose row version moved on.
+const workOutcomesAttempts = 3
+
+// UpdateWorkOutcomes applies mutate to a task's work outcomes and stores the
+// result. The read, mutation and write share one transaction and the write
+// only lands if the task's row version is unchanged; otherwise the update is
+// retried from a fresh read. mutate may therefore run more than once and must
+// only touch the map it is given.
+//
+// Leases are never claimed or released: the update needs task.update and is
+// refused while another actor holds an active lease on the task.
+func (e Engine) UpdateWorkOutcomes(ctx context.Context, taskID, actorID string, mutate func(map[string]any) error) (domain.Task, error) {
+	if e.Config == nil {
+		return domain.Task{}, errors.New("config not loaded")
+	}
+	for attempt := 0; attempt < workOutcomesAttempts; attempt++ {
+		t, ok, err := e.updateWorkOutcomesOnce(ctx, taskID, actorID, mutate)
+		if err != nil || ok {
+			return t, err
+		}
+	}
+	return domain.Task{}, ErrWorkOutcomesConflict
+}
+
+func (e Engine) updateWorkOutcomesOnce(ctx context.Context, taskID, actorID string, mutate func(map[string]any) error) (domain.Task, bool, error) {
+	tx, err := e.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, false, err
+	}
+	defer tx.Rollback()
+	t, err := e.Repo.GetTaskTx(ctx, tx, taskID)
+	if err != nil {
+		return t, false, err
+	}
+	if err := e.requirePermission(ctx, tx, t.ProjectID, actorID, "task.update"); err != nil {
+		return t, false, err
+	}
+	if err := e.ensureNoForeignLease(ctx, tx, t.ID, actorID); err != nil {
+		return t, false, err
+	}
+	version, err := e.Repo.TaskVersionTx(ctx, tx, t.ID)
+	if err != nil {
+		return t, false, err
+	}
+	workOutcomes := map[string]any{}
+	if t.WorkOutcomesJSON != nil && strings.TrimSpace(*t.WorkOutcomesJSON) != "" {
+		var current any
+		if err := json.Unmarshal([]byte(*t.WorkOutcomesJSON), &current); err != nil {
+			return t, false, fmt.Errorf("invalid work_outcomes: %w", err)
+		}
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return t, false, errors.New("invalid work_outcomes: must be object")
+		}
+		workOutcomes = obj
+	}
+	if err := mutate(workOutcomes); err != nil {
+		return t, false, err
+	}
+	data, err := json.Marshal(workOutcomes)
+	if err != nil {
+		return t, false, fmt.Errorf("invalid work_outcomes: %w", err)
+	}
+	encoded := string(data)
+	if err := e.validateWorkOutcomes(t.Type, encoded); err != nil {
+		return t, false, err
+	}
+	// Last point to give up cleanly: past here the update commits or not as a
+	// whole.
+	if err := ctx.Err(); err != nil {
+		return t, false, err
+	}
+	t.WorkOutcomesJSON = &encoded
+	t.UpdatedAt = e.now().UTC().Format(time.RFC3339)
+	ok, err := e.Repo.SetTaskWorkOutcomesTx(ctx, tx, t.ID, t.WorkOutcomesJSON, t.UpdatedAt, version)
+	if err != nil || !ok {
+		return t, false, err
+	}
+	if err := e.Events.Append(ctx, tx, "task.updated", t.ProjectID, "task", t.ID, actorID, events.EventPayload{
+		"from_status": t.Status,
+		"to_status":   t.Status,
+		"changed":     []string{"work_outcomes"},
+	}); err != nil {
+		return t, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return t, false, err
+	}
+	t.DependsOn, _ = e.Repo.ListTaskDependencies(ctx, t.ID)
+	return t, true, nil
+}
+
+// ensureNoForeignLease fails when another actor holds an unexpired lease on
+// the task. It only reads the lease table.
+func (e Engine) ensureNoForeignLease(ctx context.Context, tx *sql.Tx, taskID, actorID string) error {
+	l, err := e.Repo.GetLeaseTx(ctx, tx, taskID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if l.OwnerID == actorID {
+		return nil
+	}
+	exp, err := time.Parse(time.RFC3339, l.ExpiresAt)
+	if err != nil || e.now().After(exp) {
+		return nil
+	}
+	return errors.New("lease already held")
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+
+	"workline/internal/domain"
+)
+
+// ChainHash returns the hash that links an event to its project's previous
+// event: the SHA-256 of the previous hash and the event's fields. The first
+// hashed event of a project has an empty previous hash.
+func ChainHash(prevHash string, evt domain.Event) string {
+	// A JSON array keeps the fields unambiguous whatever they contain.
+	data, _ := json.Marshal([]string{prevHash, evt.TS, evt.Type, evt.ProjectID, evt.EntityKind, evt.EntityID, evt.ActorID, evt.Payload})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chainHead returns the hash of the project's latest event, empty when it
+// has none or its events predate hashing. Project-less events form their
+// own chain.
+func chainHead(ctx context.Context, tx *sql.Tx, projectID string) (string, error) {
+	var hash sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT hash FROM events WHERE project_id IS ? ORDER BY id DESC LIMIT 1`, nullable(projectID)).Scan(&hash)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	return hash.String, nil
+}
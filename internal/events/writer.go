@@ -6,11 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"workline/internal/domain"
 )
 
+// AuditEntityKind marks audit entries: RBAC changes, denied requests, actor
+// and org membership changes and force use. Reading them needs
+// events.audit.read on top of project.events.read.
+const AuditEntityKind = "rbac"
+
 type Writer struct {
 	DB  *sql.DB
 	Now func() time.Time
+	// Outbox returns the broker topic for an event, or "" to skip it. When
+	// set, published events are queued in the outbox in the same transaction.
+	Outbox func(projectID, evtType, entityKind string) string
 }
 
 type EventPayload map[string]any
@@ -27,11 +37,59 @@ func (w Writer) Append(ctx context.Context, tx *sql.Tx, evtType, projectID, enti
 	if err != nil {
 		return fmt.Errorf("marshal event payload: %w", err)
 	}
-	_, err = tx.ExecContext(ctx, `INSERT INTO events(ts,type,project_id,entity_kind,entity_id,actor_id,payload_json) VALUES (?,?,?,?,?,?,?)`,
-		ts, evtType, nullable(projectID), entityKind, nullable(entityID), actorID, string(data))
+	prevHash, err := chainHead(ctx, tx, projectID)
+	if err != nil {
+		return err
+	}
+	hash := ChainHash(prevHash, domain.Event{TS: ts, Type: evtType, ProjectID: projectID, EntityKind: entityKind, EntityID: entityID, ActorID: actorID, Payload: string(data)})
+	res, err := tx.ExecContext(ctx, `INSERT INTO events(ts,type,project_id,entity_kind,entity_id,actor_id,payload_json,prev_hash,hash) VALUES (?,?,?,?,?,?,?,?,?)`,
+		ts, evtType, nullable(projectID), entityKind, nullable(entityID), actorID, string(data), nullable(prevHash), hash)
+	if err != nil || w.Outbox == nil {
+		return err
+	}
+	topic := w.Outbox(projectID, evtType, entityKind)
+	if topic == "" {
+		return nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	msg, err := json.Marshal(outboxMessage{
+		ID:         id,
+		TS:         ts,
+		Type:       evtType,
+		ProjectID:  projectID,
+		EntityKind: entityKind,
+		EntityID:   entityID,
+		ActorID:    actorID,
+		Payload:    data,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal outbox message: %w", err)
+	}
+	key := entityID
+	if key == "" {
+		key = projectID
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox(event_id,project_id,topic,msg_key,payload_json,created_at,next_attempt_at) VALUES (?,?,?,?,?,?,?)`,
+		id, nullable(projectID), topic, key, string(msg), ts, ts)
 	return err
 }
 
+// outboxMessage is the body published to the broker; it matches the API's
+// event representation so consumers can share decoding with webhooks.
+type outboxMessage struct {
+	ID         int64           `json:"id"`
+	TS         string          `json:"ts"`
+	Type       string          `json:"type"`
+	ProjectID  string          `json:"project_id,omitempty"`
+	EntityKind string          `json:"entity_kind"`
+	EntityID   string          `json:"entity_id,omitempty"`
+	ActorID    string          `json:"actor_id"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
 func nullable(v string) any {
 	if v == "" {
 		return nil
@@ -0,0 +1,196 @@
+// Package fieldcrypt seals sensitive column values with AES-GCM so they are
+// stored encrypted and opened again when the repo reads them back. Each value
+// is bound to the table, column and row it is stored in.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"workline/internal/config"
+)
+
+// Prefix marks a sealed value: enc:v2:<key id>:<base64 nonce and ciphertext>,
+// sealed with its Field as additional data.
+const Prefix = "enc:v2:"
+
+// legacyPrefix marks values sealed without additional data, opened as they
+// are until re-encryption moves them to Prefix.
+const legacyPrefix = "enc:v1:"
+
+// ErrNoKey is returned when opening a sealed value without a configured key.
+var ErrNoKey = errors.New("encrypted field: no encryption key configured")
+
+// Keyring holds the key new values are sealed with and the previous keys
+// older values may still be sealed with. Keys are loaded on first use, so a
+// failing kms_command surfaces on the first read or write that needs it. A nil
+// Keyring stores values as they are.
+type Keyring struct {
+	cfg     config.EncryptionConfig
+	once    sync.Once
+	err     error
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// New returns a keyring for cfg, or nil when encryption is not configured.
+func New(cfg config.EncryptionConfig) *Keyring {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Keyring{cfg: cfg}
+}
+
+// GenerateKey returns a random base64 key suitable for encryption.key.
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Field names where a value is stored. Sealing binds the value to it, so a
+// sealed value copied to another row or column does not open.
+type Field struct {
+	Table, Column, RowID string
+}
+
+func (f Field) additionalData() []byte {
+	return []byte(f.Table + "\x00" + f.Column + "\x00" + f.RowID)
+}
+
+// IsSealed reports whether a stored value was sealed by a Keyring.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, Prefix) || strings.HasPrefix(s, legacyPrefix)
+}
+
+// Seal encrypts plain, stored at f, with the current key. Every value is
+// sealed, including one that happens to look sealed already.
+func (k *Keyring) Seal(f Field, plain string) (string, error) {
+	if k == nil {
+		return plain, nil
+	}
+	if err := k.load(); err != nil {
+		return "", err
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plain), f.additionalData())
+	return Prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// SealPtr seals a nullable value.
+func (k *Keyring) SealPtr(f Field, plain *string) (*string, error) {
+	if plain == nil {
+		return nil, nil
+	}
+	sealed, err := k.Seal(f, *plain)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// Open decrypts a value read from f with whichever key sealed it; values
+// stored before encryption was turned on are returned as they are.
+func (k *Keyring) Open(f Field, stored string) (string, error) {
+	if !IsSealed(stored) {
+		return stored, nil
+	}
+	if k == nil {
+		return "", ErrNoKey
+	}
+	if err := k.load(); err != nil {
+		return "", err
+	}
+	rest, ad := strings.TrimPrefix(stored, Prefix), f.additionalData()
+	if strings.HasPrefix(stored, legacyPrefix) {
+		rest, ad = strings.TrimPrefix(stored, legacyPrefix), nil
+	}
+	id, data, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("encrypted field: malformed value")
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("encrypted field: unknown key %s", id)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return "", errors.New("encrypted field: malformed value")
+	}
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], ad)
+	if err != nil {
+		return "", fmt.Errorf("encrypted field: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Current reports whether stored is sealed with the current key and bound
+// to its field, so re-encryption can skip it.
+func (k *Keyring) Current(stored string) (bool, error) {
+	if k == nil {
+		return !IsSealed(stored), nil
+	}
+	if err := k.load(); err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(stored, Prefix+k.primary+":"), nil
+}
+
+func (k *Keyring) load() error {
+	k.once.Do(func() {
+		key := k.cfg.Key
+		if len(k.cfg.KMSCommand) > 0 {
+			// Run as an argument list, never through a shell.
+			out, err := exec.Command(k.cfg.KMSCommand[0], k.cfg.KMSCommand[1:]...).Output()
+			if err != nil {
+				k.err = fmt.Errorf("encryption.kms_command: %w", err)
+				return
+			}
+			key = string(out)
+		}
+		k.aeads = map[string]cipher.AEAD{}
+		if k.primary, k.err = k.add("encryption.key", key); k.err != nil {
+			return
+		}
+		for i, prev := range k.cfg.PreviousKeys {
+			if _, k.err = k.add(fmt.Sprintf("encryption.previous_keys[%d]", i), prev); k.err != nil {
+				return
+			}
+		}
+	})
+	return k.err
+}
+
+func (k *Keyring) add(field, encoded string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return "", fmt.Errorf("%s must be 32 bytes, base64 encoded", field)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(key)
+	id := hex.EncodeToString(sum[:4])
+	k.aeads[id] = aead
+	return id, nil
+}
@@ -0,0 +1,337 @@
+// Package jsonschema validates JSON documents against a practical subset of
+// JSON Schema: type, enum, const, properties, required, additionalProperties,
+// items, string length and pattern, numeric bounds and array length.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Violation describes one place where a document does not match its schema.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Check reports whether a schema only uses supported keywords with valid values.
+func Check(schema map[string]any) error {
+	return check(schema, "")
+}
+
+// Validate returns every violation of doc against schema. Doc is expected to be
+// the result of decoding JSON into an any.
+func Validate(schema map[string]any, doc any) []Violation {
+	var out []Violation
+	validate(schema, doc, "", &out)
+	return out
+}
+
+var supportedTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+func check(schema map[string]any, path string) error {
+	at := path
+	if at == "" {
+		at = "/"
+	}
+	for key, val := range schema {
+		switch key {
+		case "$schema", "$id", "title", "description", "default", "examples", "enum", "const":
+		case "type":
+			for _, t := range typeList(val) {
+				if !supportedTypes[t] {
+					return fmt.Errorf("schema %s: unsupported type %v", at, t)
+				}
+			}
+			if len(typeList(val)) == 0 {
+				return fmt.Errorf("schema %s: type must be a string or list of strings", at)
+			}
+		case "properties":
+			props, ok := asObject(val)
+			if !ok {
+				return fmt.Errorf("schema %s: properties must be an object", at)
+			}
+			for name, sub := range props {
+				subSchema, ok := asObject(sub)
+				if !ok {
+					return fmt.Errorf("schema %s/properties/%s: must be an object", path, name)
+				}
+				if err := check(subSchema, path+"/properties/"+name); err != nil {
+					return err
+				}
+			}
+		case "items":
+			sub, ok := asObject(val)
+			if !ok {
+				return fmt.Errorf("schema %s: items must be an object", at)
+			}
+			if err := check(sub, path+"/items"); err != nil {
+				return err
+			}
+		case "additionalProperties":
+			if _, ok := val.(bool); ok {
+				continue
+			}
+			sub, ok := asObject(val)
+			if !ok {
+				return fmt.Errorf("schema %s: additionalProperties must be a boolean or object", at)
+			}
+			if err := check(sub, path+"/additionalProperties"); err != nil {
+				return err
+			}
+		case "required":
+			if _, ok := stringList(val); !ok {
+				return fmt.Errorf("schema %s: required must be a list of strings", at)
+			}
+		case "minLength", "maxLength", "minItems", "maxItems", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			if _, ok := asNumber(val); !ok {
+				return fmt.Errorf("schema %s: %s must be a number", at, key)
+			}
+		case "pattern":
+			s, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("schema %s: pattern must be a string", at)
+			}
+			if _, err := regexp.Compile(s); err != nil {
+				return fmt.Errorf("schema %s: invalid pattern: %v", at, err)
+			}
+		default:
+			return fmt.Errorf("schema %s: unsupported keyword %s", at, key)
+		}
+	}
+	return nil
+}
+
+func validate(schema map[string]any, doc any, path string, out *[]Violation) {
+	at := path
+	if at == "" {
+		at = "/"
+	}
+	add := func(format string, args ...any) {
+		*out = append(*out, Violation{Path: at, Message: fmt.Sprintf(format, args...)})
+	}
+	if types := typeList(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if hasType(doc, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("expected %s, got %s", strings.Join(types, " or "), typeOf(doc))
+			return
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			if equal(candidate, doc) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("value is not one of the allowed values")
+		}
+	}
+	if c, ok := schema["const"]; ok && !equal(c, doc) {
+		add("value must equal %v", c)
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		validateObject(schema, v, path, out)
+	case []any:
+		if n, ok := asNumber(schema["minItems"]); ok && float64(len(v)) < n {
+			add("expected at least %v items", n)
+		}
+		if n, ok := asNumber(schema["maxItems"]); ok && float64(len(v)) > n {
+			add("expected at most %v items", n)
+		}
+		if items, ok := asObject(schema["items"]); ok {
+			for i, item := range v {
+				validate(items, item, path+"/"+strconv.Itoa(i), out)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if n, ok := asNumber(schema["minLength"]); ok && float64(length) < n {
+			add("expected at least %v characters", n)
+		}
+		if n, ok := asNumber(schema["maxLength"]); ok && float64(length) > n {
+			add("expected at most %v characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				add("does not match pattern %s", pattern)
+			}
+		}
+	case float64:
+		if n, ok := asNumber(schema["minimum"]); ok && v < n {
+			add("must be >= %v", n)
+		}
+		if n, ok := asNumber(schema["maximum"]); ok && v > n {
+			add("must be <= %v", n)
+		}
+		if n, ok := asNumber(schema["exclusiveMinimum"]); ok && v <= n {
+			add("must be > %v", n)
+		}
+		if n, ok := asNumber(schema["exclusiveMaximum"]); ok && v >= n {
+			add("must be < %v", n)
+		}
+	}
+}
+
+func validateObject(schema map[string]any, obj map[string]any, path string, out *[]Violation) {
+	if required, ok := stringList(schema["required"]); ok {
+		for _, name := range required {
+			if _, present := obj[name]; !present {
+				*out = append(*out, Violation{Path: path + "/" + name, Message: "is required"})
+			}
+		}
+	}
+	props, _ := asObject(schema["properties"])
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if sub, ok := asObject(props[name]); ok {
+			validate(sub, obj[name], path+"/"+name, out)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				*out = append(*out, Violation{Path: path + "/" + name, Message: "is not allowed"})
+			}
+		default:
+			if sub, ok := asObject(extra); ok {
+				validate(sub, obj[name], path+"/"+name, out)
+			}
+		}
+	}
+}
+
+func typeList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	default:
+		list, _ := stringList(v)
+		return list
+	}
+}
+
+func stringList(v any) ([]string, bool) {
+	switch items := v.(type) {
+	case []string:
+		return items, true
+	case []any:
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// asObject accepts both JSON-decoded and YAML-decoded maps.
+func asObject(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			out[fmt.Sprint(k)] = val
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func asNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func hasType(doc any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := doc.(map[string]any)
+		return ok
+	case "array":
+		_, ok := doc.([]any)
+		return ok
+	case "string":
+		_, ok := doc.(string)
+		return ok
+	case "number":
+		_, ok := doc.(float64)
+		return ok
+	case "integer":
+		n, ok := doc.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := doc.(bool)
+		return ok
+	case "null":
+		return doc == nil
+	}
+	return false
+}
+
+func typeOf(doc any) string {
+	switch doc.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", doc)
+}
+
+func equal(a, b any) bool {
+	if an, ok := asNumber(a); ok {
+		bn, ok := asNumber(b)
+		return ok && an == bn
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
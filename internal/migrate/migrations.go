@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"sort"
+	"strings"
 )
 
 //go:embed sql/*.sql
@@ -15,14 +16,18 @@ type Migration struct {
 	Version int
 	Name    string
 	UpSQL   string
+	// DownSQL reverts the migration; empty when it cannot be undone.
+	DownSQL string
 }
 
+const downSuffix = ".down.sql"
+
 func loadMigrations() ([]Migration, error) {
 	files, err := fs.ReadDir(migrationsFS, "sql")
 	if err != nil {
 		return nil, err
 	}
-	var migrations []Migration
+	byVersion := map[int]*Migration{}
 	for _, f := range files {
 		if f.IsDir() {
 			continue
@@ -36,22 +41,78 @@ func loadMigrations() ([]Migration, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid migration filename %s: %w", f.Name(), err)
 		}
-		migrations = append(migrations, Migration{
-			Version: v,
-			Name:    f.Name(),
-			UpSQL:   string(data),
-		})
+		m, ok := byVersion[v]
+		if !ok {
+			m = &Migration{Version: v}
+			byVersion[v] = m
+		}
+		if strings.HasSuffix(f.Name(), downSuffix) {
+			m.DownSQL = string(data)
+			continue
+		}
+		if m.Name != "" {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", v, m.Name, f.Name())
+		}
+		m.Name = f.Name()
+		m.UpSQL = string(data)
+	}
+	var migrations []Migration
+	for v, m := range byVersion {
+		if m.Name == "" {
+			return nil, fmt.Errorf("down migration for version %d has no up migration", v)
+		}
+		migrations = append(migrations, *m)
 	}
 	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
 	return migrations, nil
 }
 
-// Migrate applies embedded migrations in order.
+func latestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate applies embedded migrations in order. It refuses a database whose
+// schema is newer than the embedded migrations.
 func Migrate(db *sql.DB) error {
 	migrations, err := loadMigrations()
 	if err != nil {
 		return err
 	}
+	return migrateTo(db, migrations, latestVersion(migrations))
+}
+
+// MigrateTo moves the schema to the target version, applying up migrations
+// or reverting down migrations as needed.
+func MigrateTo(db *sql.DB, target int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	return migrateTo(db, migrations, target)
+}
+
+func migrateTo(db *sql.DB, migrations []Migration, target int) error {
+	latest := latestVersion(migrations)
+	if target < 0 || target > latest {
+		return fmt.Errorf("unknown schema version %d (latest is %d)", target, latest)
+	}
+	// Table rebuilds drop and recreate tables; with foreign keys enforced the
+	// drop would cascade into child rows. The pragma is a no-op inside a
+	// transaction, so it is toggled around it and integrity is checked before
+	// commit instead.
+	var foreignKeys int
+	if err := db.QueryRow(`PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil {
+		return fmt.Errorf("read foreign_keys: %w", err)
+	}
+	if foreignKeys == 1 {
+		if _, err := db.Exec(`PRAGMA foreign_keys=OFF`); err != nil {
+			return fmt.Errorf("disable foreign_keys: %w", err)
+		}
+		defer db.Exec(`PRAGMA foreign_keys=ON`)
+	}
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -72,18 +133,25 @@ func Migrate(db *sql.DB) error {
 	} else if err != nil {
 		return fmt.Errorf("read schema_version: %w", err)
 	}
+	if currentVersion > latest {
+		return VersionError{Current: currentVersion, Supported: latest}
+	}
 
-	for _, m := range migrations {
-		if m.Version <= currentVersion {
-			continue
+	for _, step := range plan(migrations, currentVersion, target) {
+		m := step.migration
+		query := m.UpSQL
+		if step.Direction == DirectionDown {
+			if m.DownSQL == "" {
+				return fmt.Errorf("migration %s cannot be reverted", m.Name)
+			}
+			query = m.DownSQL
 		}
-		if _, err := tx.Exec(m.UpSQL); err != nil {
-			return fmt.Errorf("migration %s: %w", m.Name, err)
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("migration %s (%s): %w", m.Name, step.Direction, err)
 		}
-		if _, err := tx.Exec(`UPDATE schema_version SET version=?`, m.Version); err != nil {
+		if _, err := tx.Exec(`UPDATE schema_version SET version=?`, step.to); err != nil {
 			return fmt.Errorf("update schema_version: %w", err)
 		}
-		currentVersion = m.Version
 	}
 	if exists, err := tableExists(tx, "organizations"); err != nil {
 		return fmt.Errorf("check organizations table: %w", err)
@@ -92,6 +160,11 @@ func Migrate(db *sql.DB) error {
 			return fmt.Errorf("seed default org: %w", err)
 		}
 	}
+	if foreignKeys == 1 {
+		if err := checkForeignKeys(tx); err != nil {
+			return err
+		}
+	}
 	return tx.Commit()
 }
 
@@ -105,3 +178,22 @@ func tableExists(tx *sql.Tx, table string) (bool, error) {
 	}
 	return name.Valid, nil
 }
+
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		return fmt.Errorf("foreign_key_check: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var table string
+		var rowid sql.NullInt64
+		var parent string
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return fmt.Errorf("foreign_key_check: %w", err)
+		}
+		return fmt.Errorf("foreign key violation in %s referencing %s", table, parent)
+	}
+	return rows.Err()
+}
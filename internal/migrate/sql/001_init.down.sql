@@ -0,0 +1,21 @@
+-- Drops the whole schema; everything in the workspace database is lost.
+DROP TABLE IF EXISTS actor_missions;
+DROP TABLE IF EXISTS org_roles;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS attestation_authorities;
+DROP TABLE IF EXISTS actor_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS attestations;
+DROP TABLE IF EXISTS leases;
+DROP TABLE IF EXISTS decisions;
+DROP TABLE IF EXISTS task_deps;
+DROP TABLE IF EXISTS validations;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS iterations;
+DROP TABLE IF EXISTS project_configs;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS actors;
+DROP TABLE IF EXISTS organizations;
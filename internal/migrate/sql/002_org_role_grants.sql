@@ -0,0 +1,8 @@
+-- Project roles granted at org level apply to every project in the org.
+CREATE TABLE IF NOT EXISTS org_actor_roles(
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  actor_id TEXT NOT NULL REFERENCES actors(id) ON DELETE CASCADE,
+  role_id TEXT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  PRIMARY KEY(org_id, actor_id, role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_org_actor_roles_actor ON org_actor_roles(actor_id);
@@ -0,0 +1,2 @@
+ALTER TABLE actors DROP COLUMN deactivated_at;
+ALTER TABLE actors DROP COLUMN status;
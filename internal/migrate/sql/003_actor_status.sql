@@ -0,0 +1,3 @@
+-- Suspended actors keep their history but can no longer authenticate or act.
+ALTER TABLE actors ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK(status IN ('active','suspended'));
+ALTER TABLE actors ADD COLUMN deactivated_at TEXT;
@@ -0,0 +1,2 @@
+-- Evidence rows go; blobs already written to the evidence store are left in place.
+DROP TABLE IF EXISTS attestation_evidence;
@@ -0,0 +1,14 @@
+-- Evidence files (test reports, screenshots) attached to attestations.
+CREATE TABLE IF NOT EXISTS attestation_evidence(
+  id TEXT PRIMARY KEY,
+  attestation_id TEXT NOT NULL REFERENCES attestations(id) ON DELETE CASCADE,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  sha256 TEXT NOT NULL,
+  storage_key TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_attestation_evidence_att ON attestation_evidence(attestation_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_time_entries;
+DELETE FROM role_permissions WHERE permission_id='task.time.log';
+DELETE FROM permissions WHERE id='task.time.log';
@@ -0,0 +1,17 @@
+-- Time logged against tasks, in whole minutes.
+CREATE TABLE IF NOT EXISTS task_time_entries(
+  id TEXT PRIMARY KEY,
+  task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  actor_id TEXT NOT NULL,
+  minutes INTEGER NOT NULL CHECK(minutes > 0),
+  note TEXT,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_task_time_entries_task ON task_time_entries(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_time_entries_project ON task_time_entries(project_id);
+
+-- Existing databases: owners can log time without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('task.time.log', 'Log time on task');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'task.time.log' FROM roles WHERE id='owner';
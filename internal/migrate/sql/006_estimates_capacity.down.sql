@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN estimate;
+ALTER TABLE iterations DROP COLUMN capacity;
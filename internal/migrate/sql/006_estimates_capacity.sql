@@ -0,0 +1,3 @@
+-- Task estimates (points or hours, per planning.estimate_unit) and iteration capacity.
+ALTER TABLE tasks ADD COLUMN estimate REAL CHECK(estimate IS NULL OR estimate >= 0);
+ALTER TABLE iterations ADD COLUMN capacity REAL CHECK(capacity IS NULL OR capacity >= 0);
@@ -0,0 +1,36 @@
+-- Restores the fixed status CHECK. Tasks in custom workflow states violate it,
+-- so the rebuild fails until they are moved back to a built-in status.
+CREATE TABLE tasks_old(
+  id TEXT PRIMARY KEY,
+  project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+  iteration_id TEXT REFERENCES iterations(id) ON DELETE SET NULL,
+  parent_id TEXT REFERENCES tasks(id) ON DELETE SET NULL,
+  type TEXT NOT NULL,
+  title TEXT NOT NULL,
+  description TEXT,
+  status TEXT CHECK(status IN ('planned','ready','in_progress','review','done','rejected','canceled')) NOT NULL,
+  assignee_id TEXT,
+  priority INTEGER,
+  work_outcomes_json TEXT,
+  required_attestations_json TEXT,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  completed_at TEXT,
+  work_proof_json TEXT,
+  validation_mode TEXT,
+  required_threshold INTEGER,
+  estimate REAL CHECK(estimate IS NULL OR estimate >= 0)
+);
+INSERT INTO tasks_old(id, project_id, iteration_id, parent_id, type, title, description, status, assignee_id, priority,
+  work_outcomes_json, required_attestations_json, created_at, updated_at, completed_at, work_proof_json,
+  validation_mode, required_threshold, estimate)
+SELECT id, project_id, iteration_id, parent_id, type, title, description, status, assignee_id, priority,
+  work_outcomes_json, required_attestations_json, created_at, updated_at, completed_at, work_proof_json,
+  validation_mode, required_threshold, estimate
+FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_iteration ON tasks(iteration_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id);
@@ -0,0 +1,37 @@
+-- Task statuses come from the per-type workflow in config, so the fixed
+-- status CHECK is dropped. SQLite cannot alter a CHECK in place; the table
+-- is rebuilt with foreign keys disabled by Migrate.
+CREATE TABLE tasks_new(
+  id TEXT PRIMARY KEY,
+  project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+  iteration_id TEXT REFERENCES iterations(id) ON DELETE SET NULL,
+  parent_id TEXT REFERENCES tasks(id) ON DELETE SET NULL,
+  type TEXT NOT NULL,
+  title TEXT NOT NULL,
+  description TEXT,
+  status TEXT NOT NULL CHECK(status <> ''),
+  assignee_id TEXT,
+  priority INTEGER,
+  work_outcomes_json TEXT,
+  required_attestations_json TEXT,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  completed_at TEXT,
+  work_proof_json TEXT,
+  validation_mode TEXT,
+  required_threshold INTEGER,
+  estimate REAL CHECK(estimate IS NULL OR estimate >= 0)
+);
+INSERT INTO tasks_new(id, project_id, iteration_id, parent_id, type, title, description, status, assignee_id, priority,
+  work_outcomes_json, required_attestations_json, created_at, updated_at, completed_at, work_proof_json,
+  validation_mode, required_threshold, estimate)
+SELECT id, project_id, iteration_id, parent_id, type, title, description, status, assignee_id, priority,
+  work_outcomes_json, required_attestations_json, created_at, updated_at, completed_at, work_proof_json,
+  validation_mode, required_threshold, estimate
+FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_iteration ON tasks(iteration_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id);
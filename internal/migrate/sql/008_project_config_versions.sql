@@ -0,0 +1,13 @@
+-- Every stored project config is kept as a numbered version for diff and rollback.
+CREATE TABLE IF NOT EXISTS project_config_versions(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  config_json TEXT NOT NULL,
+  actor_id TEXT,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY(project_id, version)
+);
+
+INSERT INTO project_config_versions(project_id, version, config_json, actor_id, created_at)
+SELECT project_id, 1, config_json, NULL, updated_at FROM project_configs
+WHERE project_id IN (SELECT id FROM projects);
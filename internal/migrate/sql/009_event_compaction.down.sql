@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id='project.events.compact';
+DELETE FROM permissions WHERE id='project.events.compact';
@@ -0,0 +1,4 @@
+-- Existing databases: owners can compact the event log without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('project.events.compact', 'Compact project events');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'project.events.compact' FROM roles WHERE id='owner';
@@ -0,0 +1 @@
+ALTER TABLE attestations DROP COLUMN expired_at;
@@ -0,0 +1,3 @@
+-- Set by the sweeper once an attestation outlives its kind's valid_days, so
+-- attestation.expired is emitted only once.
+ALTER TABLE attestations ADD COLUMN expired_at TEXT;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id='task.revert';
+DELETE FROM permissions WHERE id='task.revert';
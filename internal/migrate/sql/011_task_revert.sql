@@ -0,0 +1,4 @@
+-- Existing databases: owners can revert task transitions without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('task.revert', 'Revert task transition');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'task.revert' FROM roles WHERE id='owner';
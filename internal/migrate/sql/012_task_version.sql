@@ -0,0 +1,3 @@
+-- Bumped on every task write so read-modify-write updates can detect that
+-- the row changed underneath them.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Restores the fixed 'software-project' kind. Projects of any other kind
+-- violate it, so the rebuild fails until they are removed.
+CREATE TABLE projects_old(
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  kind TEXT CHECK(kind='software-project') NOT NULL,
+  status TEXT CHECK(status IN ('active','paused','archived')) NOT NULL,
+  description TEXT,
+  created_at TEXT NOT NULL
+);
+INSERT INTO projects_old(id, org_id, kind, status, description, created_at)
+SELECT id, org_id, CASE kind WHEN 'software' THEN 'software-project' ELSE kind END, status, description, created_at
+FROM projects;
+DROP TABLE projects;
+ALTER TABLE projects_old RENAME TO projects;
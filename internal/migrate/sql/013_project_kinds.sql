@@ -0,0 +1,16 @@
+-- Projects get one of the built-in kinds instead of the fixed
+-- 'software-project', which becomes 'software'. The table is rebuilt with
+-- foreign keys disabled by Migrate.
+CREATE TABLE projects_new(
+  id TEXT PRIMARY KEY,
+  org_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  kind TEXT CHECK(kind IN ('software','research','ops','content')) NOT NULL,
+  status TEXT CHECK(status IN ('active','paused','archived')) NOT NULL,
+  description TEXT,
+  created_at TEXT NOT NULL
+);
+INSERT INTO projects_new(id, org_id, kind, status, description, created_at)
+SELECT id, org_id, CASE kind WHEN 'software-project' THEN 'software' ELSE kind END, status, description, created_at
+FROM projects;
+DROP TABLE projects;
+ALTER TABLE projects_new RENAME TO projects;
@@ -0,0 +1,3 @@
+-- Tasks can belong to a component from the project's config registry.
+ALTER TABLE tasks ADD COLUMN component TEXT;
+CREATE INDEX IF NOT EXISTS idx_tasks_component ON tasks(project_id, component);
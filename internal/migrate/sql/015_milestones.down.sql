@@ -0,0 +1,21 @@
+CREATE TABLE events_new(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  ts TEXT NOT NULL,
+  type TEXT NOT NULL,
+  project_id TEXT,
+  entity_kind TEXT CHECK(entity_kind IN ('project','iteration','task','decision','lease','attestation','rbac')) NOT NULL,
+  entity_id TEXT,
+  actor_id TEXT NOT NULL,
+  payload_json TEXT NOT NULL
+);
+INSERT INTO events_new(id, ts, type, project_id, entity_kind, entity_id, actor_id, payload_json)
+SELECT id, ts, type, project_id, entity_kind, entity_id, actor_id, payload_json FROM events WHERE entity_kind<>'milestone';
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+CREATE INDEX IF NOT EXISTS idx_events_project ON events(project_id);
+CREATE INDEX IF NOT EXISTS idx_events_ts ON events(ts);
+DROP TABLE IF EXISTS milestone_tasks;
+DROP TABLE IF EXISTS milestone_iterations;
+DROP TABLE IF EXISTS milestones;
+DELETE FROM role_permissions WHERE permission_id IN ('milestone.create','milestone.list');
+DELETE FROM permissions WHERE id IN ('milestone.create','milestone.list');
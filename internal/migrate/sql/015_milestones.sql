@@ -0,0 +1,48 @@
+-- Milestones group iterations and tasks under a goal with a target date.
+CREATE TABLE IF NOT EXISTS milestones(
+  id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  goal TEXT NOT NULL,
+  target_date TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_milestones_project ON milestones(project_id);
+
+CREATE TABLE IF NOT EXISTS milestone_iterations(
+  milestone_id TEXT NOT NULL REFERENCES milestones(id) ON DELETE CASCADE,
+  iteration_id TEXT NOT NULL REFERENCES iterations(id) ON DELETE CASCADE,
+  PRIMARY KEY(milestone_id, iteration_id)
+);
+
+CREATE TABLE IF NOT EXISTS milestone_tasks(
+  milestone_id TEXT NOT NULL REFERENCES milestones(id) ON DELETE CASCADE,
+  task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  PRIMARY KEY(milestone_id, task_id)
+);
+
+-- Events can target milestones. The table is rebuilt with foreign keys
+-- disabled by Migrate.
+CREATE TABLE events_new(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  ts TEXT NOT NULL,
+  type TEXT NOT NULL,
+  project_id TEXT,
+  entity_kind TEXT CHECK(entity_kind IN ('project','iteration','task','decision','lease','attestation','rbac','milestone')) NOT NULL,
+  entity_id TEXT,
+  actor_id TEXT NOT NULL,
+  payload_json TEXT NOT NULL
+);
+INSERT INTO events_new(id, ts, type, project_id, entity_kind, entity_id, actor_id, payload_json)
+SELECT id, ts, type, project_id, entity_kind, entity_id, actor_id, payload_json FROM events;
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+CREATE INDEX IF NOT EXISTS idx_events_project ON events(project_id);
+CREATE INDEX IF NOT EXISTS idx_events_ts ON events(ts);
+
+-- Existing databases: owners can manage milestones without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('milestone.create', 'Create milestone');
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('milestone.list', 'List milestones');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'milestone.create' FROM roles WHERE id='owner';
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'milestone.list' FROM roles WHERE id='owner';
@@ -0,0 +1,17 @@
+-- Outbox of events waiting to be published to a message broker. Rows are
+-- written in the same transaction as the event and deleted once the relay
+-- has delivered them; they carry the full message, so event compaction does
+-- not affect them.
+CREATE TABLE IF NOT EXISTS outbox(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  event_id INTEGER NOT NULL,
+  project_id TEXT,
+  topic TEXT NOT NULL,
+  msg_key TEXT NOT NULL,
+  payload_json TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(next_attempt_at, id);
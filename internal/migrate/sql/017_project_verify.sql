@@ -0,0 +1,4 @@
+-- Existing databases: owners can flag non-compliant tasks without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('project.verify', 'Flag non-compliant tasks');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'project.verify' FROM roles WHERE id='owner';
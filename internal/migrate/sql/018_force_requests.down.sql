@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission_id='force.approve';
+DELETE FROM permissions WHERE id='force.approve';
+DROP TABLE IF EXISTS force_requests;
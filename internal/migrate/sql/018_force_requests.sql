@@ -0,0 +1,21 @@
+-- Forced operations waiting for a second actor when force.require_approval
+-- is set. params_json holds what the operation needs to run on approval.
+CREATE TABLE IF NOT EXISTS force_requests(
+  id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  operation TEXT NOT NULL,
+  entity_kind TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  params_json TEXT NOT NULL,
+  status TEXT CHECK(status IN ('pending','approved')) NOT NULL,
+  requested_by TEXT NOT NULL,
+  requested_at TEXT NOT NULL,
+  approved_by TEXT,
+  approved_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_force_requests_project ON force_requests(project_id, status);
+
+-- Existing databases: owners can approve force requests without re-seeding RBAC.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('force.approve', 'Approve force requests');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'force.approve' FROM roles WHERE id='owner';
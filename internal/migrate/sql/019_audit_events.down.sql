@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id='events.audit.read';
+DELETE FROM permissions WHERE id='events.audit.read';
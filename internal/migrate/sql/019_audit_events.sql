@@ -0,0 +1,5 @@
+-- Existing databases: audit events (entity_kind rbac) stay visible to owners
+-- only, under their own read permission.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('events.audit.read', 'Read audit events');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'events.audit.read' FROM roles WHERE id='owner';
@@ -0,0 +1,3 @@
+-- Lets the permission cache find the latest RBAC change without scanning
+-- the whole event log.
+CREATE INDEX IF NOT EXISTS idx_events_kind ON events(entity_kind, id);
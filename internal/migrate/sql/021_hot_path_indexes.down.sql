@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_events_project ON events(project_id);
+DROP INDEX IF EXISTS idx_events_project_id;
+CREATE INDEX IF NOT EXISTS idx_attestations_entity ON attestations(entity_kind, entity_id);
+DROP INDEX IF EXISTS idx_attestations_entity_kind;
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+DROP INDEX IF EXISTS idx_tasks_project_status;
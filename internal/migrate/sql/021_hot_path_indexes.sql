@@ -0,0 +1,10 @@
+-- Indexes for the hot read paths (see wl db analyze): task lists by status in
+-- the default newest-first order, attestation lookups by entity and kind and
+-- project event pages. The composite indexes replace the ones they start
+-- with. Dependency loads by task_id already use task_deps' primary key.
+CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks(project_id, status, created_at, id);
+DROP INDEX IF EXISTS idx_tasks_project;
+CREATE INDEX IF NOT EXISTS idx_attestations_entity_kind ON attestations(entity_kind, entity_id, kind);
+DROP INDEX IF EXISTS idx_attestations_entity;
+CREATE INDEX IF NOT EXISTS idx_events_project_id ON events(project_id, id);
+DROP INDEX IF EXISTS idx_events_project;
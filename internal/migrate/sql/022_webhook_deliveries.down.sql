@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission_id='webhook.manage';
+DELETE FROM permissions WHERE id='webhook.manage';
+DROP TABLE IF EXISTS webhook_deliveries;
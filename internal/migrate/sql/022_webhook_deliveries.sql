@@ -0,0 +1,20 @@
+-- Every webhook delivery attempt, with the exact body sent so failed ones can
+-- be replayed. event_id is 0 for test deliveries.
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+  id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  event_id INTEGER NOT NULL,
+  event_type TEXT NOT NULL,
+  body TEXT NOT NULL,
+  status_code INTEGER NOT NULL,
+  error TEXT,
+  duration_ms INTEGER NOT NULL,
+  attempted_at TEXT NOT NULL,
+  replay_of TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_project ON webhook_deliveries(project_id, attempted_at);
+
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('webhook.manage', 'Inspect, test and replay webhook deliveries');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'webhook.manage' FROM roles WHERE id='owner';
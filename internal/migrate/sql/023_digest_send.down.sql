@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id='digest.send';
+DELETE FROM permissions WHERE id='digest.send';
@@ -0,0 +1,3 @@
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('digest.send', 'Email the project digest');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT id, 'digest.send' FROM roles WHERE id='owner';
@@ -0,0 +1,11 @@
+-- Entities imported from another tracker, keyed by the tracker's own id so a
+-- re-import updates them instead of creating duplicates.
+CREATE TABLE IF NOT EXISTS external_refs(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  source TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  entity_kind TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  imported_at TEXT NOT NULL,
+  PRIMARY KEY(project_id, source, external_id)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_external_refs_entity;
@@ -0,0 +1,6 @@
+-- An entity holds at most one id per external system, so lookups work both
+-- ways. Keep the latest ref where an import left several.
+DELETE FROM external_refs WHERE rowid NOT IN (
+  SELECT MAX(rowid) FROM external_refs GROUP BY project_id, source, entity_kind, entity_id
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_external_refs_entity ON external_refs(project_id, source, entity_kind, entity_id);
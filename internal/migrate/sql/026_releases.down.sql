@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS releases;
+DELETE FROM role_permissions WHERE permission_id IN ('release.create','release.list');
+DELETE FROM permissions WHERE id IN ('release.create','release.list');
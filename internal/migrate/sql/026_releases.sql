@@ -0,0 +1,23 @@
+-- Artifacts shipped from a validated iteration, for traceability from a
+-- deployed version back to the work and approvals behind it.
+CREATE TABLE IF NOT EXISTS releases(
+  id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  iteration_id TEXT NOT NULL REFERENCES iterations(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  version TEXT NOT NULL,
+  checksum TEXT,
+  url TEXT,
+  created_by TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  UNIQUE(project_id, name, version)
+);
+CREATE INDEX IF NOT EXISTS idx_releases_iteration ON releases(iteration_id);
+
+-- Existing databases: roles that manage iterations manage releases too.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('release.create', 'Record iteration release');
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('release.list', 'List releases');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'release.create' FROM role_permissions WHERE permission_id='iteration.set_status';
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'release.list' FROM role_permissions WHERE permission_id='iteration.list';
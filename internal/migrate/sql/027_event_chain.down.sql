@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS event_compactions;
+ALTER TABLE events DROP COLUMN hash;
+ALTER TABLE events DROP COLUMN prev_hash;
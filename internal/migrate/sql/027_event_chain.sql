@@ -0,0 +1,13 @@
+-- Each event links to the previous event of its project by hash; events
+-- written before this migration stay unhashed.
+ALTER TABLE events ADD COLUMN prev_hash TEXT;
+ALTER TABLE events ADD COLUMN hash TEXT;
+
+-- Id ranges removed by event compaction, where the chain may skip events.
+CREATE TABLE IF NOT EXISTS event_compactions(
+  project_id TEXT NOT NULL,
+  first_id INTEGER NOT NULL,
+  last_id INTEGER NOT NULL,
+  compacted_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_event_compactions_project ON event_compactions(project_id);
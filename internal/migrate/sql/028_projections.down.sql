@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS projection_task_status;
+DROP TABLE IF EXISTS projection_state;
+DELETE FROM role_permissions WHERE permission_id='projection.rebuild';
+DELETE FROM permissions WHERE id='projection.rebuild';
@@ -0,0 +1,21 @@
+-- Read models derived from the event log; wl projection rebuild replays the
+-- events into them. projection_state records how far each one has read.
+CREATE TABLE IF NOT EXISTS projection_state(
+  name TEXT NOT NULL,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  last_event_id INTEGER NOT NULL,
+  rebuilt_at TEXT NOT NULL,
+  PRIMARY KEY(name, project_id)
+);
+
+CREATE TABLE IF NOT EXISTS projection_task_status(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  task_id TEXT NOT NULL,
+  status TEXT NOT NULL,
+  PRIMARY KEY(project_id, task_id)
+);
+
+-- Existing databases: roles that compact events rebuild projections too.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('projection.rebuild', 'Rebuild projections');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'projection.rebuild' FROM role_permissions WHERE permission_id='project.events.compact';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS project_aliases;
+DELETE FROM role_permissions WHERE permission_id='project.rename';
+DELETE FROM permissions WHERE id='project.rename';
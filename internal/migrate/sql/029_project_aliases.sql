@@ -0,0 +1,17 @@
+-- Former ids of renamed projects, which the API keeps resolving. The event
+-- chain hashes each event with the project id it was written under:
+-- last_event_id is the newest event hashed under the alias.
+CREATE TABLE IF NOT EXISTS project_aliases(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  alias TEXT NOT NULL,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  last_event_id INTEGER NOT NULL,
+  renamed_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_project_aliases_alias ON project_aliases(alias);
+CREATE INDEX IF NOT EXISTS idx_project_aliases_project ON project_aliases(project_id);
+
+-- Existing databases: roles that delete projects rename them too.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('project.rename', 'Rename project');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'project.rename' FROM role_permissions WHERE permission_id='project.delete';
@@ -0,0 +1,7 @@
+-- Counters behind the sequence id strategy, one per project and entity kind.
+CREATE TABLE IF NOT EXISTS id_sequences(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  last INTEGER NOT NULL,
+  PRIMARY KEY(project_id, kind)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sync_base;
+DROP TABLE IF EXISTS sync_remotes;
+DELETE FROM role_permissions WHERE permission_id='project.sync';
+DELETE FROM permissions WHERE id='project.sync';
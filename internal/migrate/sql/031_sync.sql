@@ -0,0 +1,23 @@
+-- State of `wl sync` per project and remote server: the local and remote
+-- event cursors synced up to, and each shared task as last agreed, the
+-- base of the three-way merge.
+CREATE TABLE IF NOT EXISTS sync_remotes(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  remote TEXT NOT NULL,
+  local_cursor INTEGER NOT NULL DEFAULT 0,
+  remote_cursor INTEGER NOT NULL DEFAULT 0,
+  synced_at TEXT NOT NULL,
+  PRIMARY KEY(project_id, remote)
+);
+CREATE TABLE IF NOT EXISTS sync_base(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  remote TEXT NOT NULL,
+  task_id TEXT NOT NULL,
+  snapshot_json TEXT NOT NULL,
+  PRIMARY KEY(project_id, remote, task_id)
+);
+
+-- Existing databases: roles that update projects sync them too.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('project.sync', 'Sync project with a remote server');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'project.sync' FROM role_permissions WHERE permission_id='project.update';
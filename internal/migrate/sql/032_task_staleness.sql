@@ -0,0 +1,5 @@
+-- Set by the staleness sweep when a task goes without an update for
+-- project.staleness.days. The task is stale while it is newer than
+-- updated_at, so any update since clears it and task.stale is emitted once
+-- per idle spell.
+ALTER TABLE tasks ADD COLUMN stale_since TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_project_due;
+ALTER TABLE tasks DROP COLUMN sla_breached_at;
+ALTER TABLE tasks DROP COLUMN due_at;
@@ -0,0 +1,6 @@
+-- When a task is due, set explicitly or from project.sla at creation.
+ALTER TABLE tasks ADD COLUMN due_at TEXT;
+-- Set by the SLA sweep once an open task passes due_at, so sla.breached is
+-- emitted once per due date; moving due_at later re-arms it.
+ALTER TABLE tasks ADD COLUMN sla_breached_at TEXT;
+CREATE INDEX IF NOT EXISTS idx_tasks_project_due ON tasks(project_id, due_at);
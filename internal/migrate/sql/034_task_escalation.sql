@@ -0,0 +1,3 @@
+-- Set by the escalation sweep when it raises a task's priority; the next
+-- escalation under project.escalation counts from here instead of created_at.
+ALTER TABLE tasks ADD COLUMN escalated_at TEXT;
@@ -0,0 +1,13 @@
+-- Typed links between tasks, read as "task_id <type> related_task_id":
+-- blocks, relates_to, duplicates or caused_by. depends_on stays in task_deps.
+CREATE TABLE IF NOT EXISTS task_relations(
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  related_task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  created_by TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  PRIMARY KEY(task_id, type, related_task_id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_relations_related ON task_relations(related_task_id, type);
+CREATE INDEX IF NOT EXISTS idx_task_relations_project ON task_relations(project_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_actor_roles_expires;
+ALTER TABLE actor_roles DROP COLUMN expires_at;
@@ -0,0 +1,4 @@
+-- Time-boxed role grants: a grant stops counting once expires_at (RFC3339,
+-- UTC) is past, and the role expiry sweep deletes it. NULL never expires.
+ALTER TABLE actor_roles ADD COLUMN expires_at TEXT;
+CREATE INDEX IF NOT EXISTS idx_actor_roles_expires ON actor_roles(expires_at);
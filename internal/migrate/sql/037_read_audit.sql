@@ -0,0 +1,12 @@
+-- Reads of sensitive data (config, events, attestations) recorded under
+-- project.read_audit, kept apart from the event log so auditing reads does
+-- not change what readers see.
+CREATE TABLE IF NOT EXISTS read_audit(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  ts TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  resource TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_read_audit_project_ts ON read_audit(project_id, ts);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS scrubbed_actors;
+DELETE FROM role_permissions WHERE permission_id='actor.scrub';
+DELETE FROM permissions WHERE id='actor.scrub';
@@ -0,0 +1,12 @@
+-- Actors scrubbed for a data-deletion request. Only a hash of the former id
+-- is kept, so that id can neither sign in nor be registered again.
+CREATE TABLE IF NOT EXISTS scrubbed_actors(
+  id_hash TEXT PRIMARY KEY,
+  pseudonym TEXT NOT NULL,
+  scrubbed_at TEXT NOT NULL
+);
+
+-- Existing databases: roles that delete projects scrub actors too.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('actor.scrub', 'Scrub actor personal data');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'actor.scrub' FROM role_permissions WHERE permission_id='project.delete';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_decisions_number;
+ALTER TABLE decisions DROP COLUMN number;
+DELETE FROM role_permissions WHERE permission_id='decision.list';
+DELETE FROM permissions WHERE id='decision.list';
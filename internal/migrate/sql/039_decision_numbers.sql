@@ -0,0 +1,15 @@
+-- Decisions are numbered per project in creation order, so exported ADR
+-- files keep their names across exports.
+ALTER TABLE decisions ADD COLUMN number INTEGER;
+UPDATE decisions SET number=(
+  SELECT COUNT(*) FROM decisions d
+  WHERE d.project_id IS decisions.project_id
+    AND (d.created_at < decisions.created_at OR (d.created_at = decisions.created_at AND d.id <= decisions.id))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_decisions_number ON decisions(project_id, number);
+
+-- Existing databases: anyone reading the project or recording decisions
+-- lists them.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('decision.list', 'List decisions');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'decision.list' FROM role_permissions WHERE permission_id IN ('project.read','decision.create');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_roles_project;
+ALTER TABLE roles DROP COLUMN project_id;
@@ -0,0 +1,13 @@
+-- Custom roles belong to the project that created them; seeded roles keep
+-- a NULL project_id and are shared by every project.
+ALTER TABLE roles ADD COLUMN project_id TEXT REFERENCES projects(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_roles_project ON roles(project_id);
+
+-- Existing databases: roles created at runtime go to the project that
+-- created them, when it still exists.
+UPDATE roles SET project_id=(
+  SELECT e.project_id FROM events e
+  JOIN projects p ON p.id=e.project_id
+  WHERE e.type='rbac.role_created' AND json_extract(e.payload_json,'$.role_id')=roles.id
+  ORDER BY e.id DESC LIMIT 1
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_hook_deliveries_due;
+DROP TABLE IF EXISTS hook_deliveries;
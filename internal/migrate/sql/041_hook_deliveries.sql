@@ -0,0 +1,15 @@
+-- Webhook hooks queued by a status transition inside its transaction and
+-- posted once it commits, retried with backoff until the receiver accepts.
+CREATE TABLE IF NOT EXISTS hook_deliveries(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  hook TEXT NOT NULL,
+  url TEXT NOT NULL,
+  event TEXT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_hook_deliveries_due ON hook_deliveries(project_id, next_attempt_at);
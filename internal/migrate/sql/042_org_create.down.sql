@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id='org.create';
+DELETE FROM permissions WHERE id='org.create';
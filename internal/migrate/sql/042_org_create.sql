@@ -0,0 +1,5 @@
+-- Creating an org is an instance-level action, held by roles that create
+-- projects.
+INSERT OR IGNORE INTO permissions(id, description) VALUES ('org.create', 'Create organization');
+INSERT OR IGNORE INTO role_permissions(role_id, permission_id)
+SELECT DISTINCT role_id, 'org.create' FROM role_permissions WHERE permission_id='project.create';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_calendar_feeds_project;
+DROP INDEX IF EXISTS idx_calendar_feeds_token;
+DROP TABLE IF EXISTS calendar_feeds;
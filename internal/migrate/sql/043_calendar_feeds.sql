@@ -0,0 +1,12 @@
+-- Calendar feed tokens: read-only credentials for one project's calendar.ics,
+-- passed in the subscription URL instead of an API key. Only a hash is kept.
+CREATE TABLE IF NOT EXISTS calendar_feeds(
+  id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  actor_id TEXT NOT NULL REFERENCES actors(id) ON DELETE CASCADE,
+  name TEXT,
+  token_hash TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_token ON calendar_feeds(token_hash);
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_project ON calendar_feeds(project_id, actor_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_offline_queue_remote;
+DROP TABLE IF EXISTS offline_queue;
@@ -0,0 +1,19 @@
+-- Remote-mode commands kept while their server was unreachable, replayed in
+-- seq order by `wl queue replay`. signature is an HMAC over the command and
+-- its seq, checked before replay.
+CREATE TABLE IF NOT EXISTS offline_queue(
+  seq INTEGER PRIMARY KEY AUTOINCREMENT,
+  project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  remote TEXT NOT NULL,
+  actor_id TEXT NOT NULL,
+  op TEXT NOT NULL,
+  task_id TEXT NOT NULL,
+  fields_json TEXT,
+  payload_json TEXT NOT NULL,
+  base_json TEXT,
+  status TEXT NOT NULL DEFAULT 'pending',
+  reason TEXT,
+  signature TEXT NOT NULL DEFAULT '',
+  queued_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_offline_queue_remote ON offline_queue(project_id, remote, seq);
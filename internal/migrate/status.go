@@ -0,0 +1,158 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Step directions.
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// Step is one migration to apply or revert.
+type Step struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+
+	migration Migration
+	to        int
+}
+
+// MigrationStatus reports whether an embedded migration is applied.
+type MigrationStatus struct {
+	Version    int    `json:"version"`
+	Name       string `json:"name"`
+	Applied    bool   `json:"applied"`
+	Reversible bool   `json:"reversible"`
+}
+
+// VersionError reports a database schema that does not match the embedded
+// migrations.
+type VersionError struct {
+	Current   int
+	Supported int
+}
+
+func (e VersionError) Error() string {
+	if e.Current > e.Supported {
+		return fmt.Sprintf("database schema version %d is newer than this wl supports (%d); upgrade wl", e.Current, e.Supported)
+	}
+	return fmt.Sprintf("database schema version %d is older than this wl requires (%d); run `wl db migrate`", e.Current, e.Supported)
+}
+
+// LatestVersion returns the newest embedded schema version.
+func LatestVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	return latestVersion(migrations), nil
+}
+
+// CurrentVersion returns the schema version recorded in the database, 0 for
+// a database that was never migrated.
+func CurrentVersion(db *sql.DB) (int, error) {
+	var name sql.NullString
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type='table' AND name='schema_version'`).Scan(&name)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var v int
+	err = db.QueryRow(`SELECT version FROM schema_version LIMIT 1`).Scan(&v)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return v, err
+}
+
+// Check returns a VersionError unless the database is at the latest schema.
+func Check(db *sql.DB) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return fmt.Errorf("read schema_version: %w", err)
+	}
+	if current != latest {
+		return VersionError{Current: current, Supported: latest}
+	}
+	return nil
+}
+
+// Status lists every embedded migration against the database schema version.
+func Status(db *sql.DB) ([]MigrationStatus, int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, 0, err
+	}
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read schema_version: %w", err)
+	}
+	res := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		res = append(res, MigrationStatus{
+			Version:    m.Version,
+			Name:       m.Name,
+			Applied:    m.Version <= current,
+			Reversible: m.DownSQL != "",
+		})
+	}
+	return res, current, nil
+}
+
+// Plan returns the steps MigrateTo would run to reach target, without
+// touching the database.
+func Plan(db *sql.DB, target int) ([]Step, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	latest := latestVersion(migrations)
+	if target < 0 || target > latest {
+		return nil, fmt.Errorf("unknown schema version %d (latest is %d)", target, latest)
+	}
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_version: %w", err)
+	}
+	if current > latest {
+		return nil, VersionError{Current: current, Supported: latest}
+	}
+	return plan(migrations, current, target), nil
+}
+
+// plan orders the steps between two versions: ascending up migrations, or
+// descending down migrations that each leave the schema at the previous
+// migration's version.
+func plan(migrations []Migration, current, target int) []Step {
+	var steps []Step
+	if target >= current {
+		for _, m := range migrations {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, Step{Version: m.Version, Name: m.Name, Direction: DirectionUp, migration: m, to: m.Version})
+			}
+		}
+		return steps
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= target || m.Version > current {
+			continue
+		}
+		to := 0
+		if i > 0 {
+			to = migrations[i-1].Version
+		}
+		steps = append(steps, Step{Version: m.Version, Name: m.Name, Direction: DirectionDown, migration: m, to: to})
+	}
+	return steps
+}
@@ -0,0 +1,192 @@
+// Package outbox relays events queued in the outbox table to a message broker.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+	"workline/internal/repo"
+)
+
+// Publisher delivers outbox messages to a broker. Publish returns only once
+// the broker has acknowledged every message, or an error if any may be lost.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []domain.OutboxMessage) error
+	Close() error
+}
+
+// Open connects to the broker described by the outbox config.
+func Open(cfg config.OutboxConfig) (Publisher, error) {
+	switch cfg.Broker {
+	case "nats":
+		conn, err := nats.Connect(strings.Join(cfg.Servers, ","),
+			nats.Name("workline-outbox"),
+			nats.RetryOnFailedConnect(true),
+			nats.MaxReconnects(-1))
+		if err != nil {
+			return nil, fmt.Errorf("connect nats: %w", err)
+		}
+		return natsPublisher{conn: conn}, nil
+	case "kafka":
+		return kafkaPublisher{w: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Servers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			BatchTimeout:           10 * time.Millisecond,
+		}}, nil
+	default:
+		return nil, fmt.Errorf("invalid outbox broker %s", cfg.Broker)
+	}
+}
+
+// MessageID identifies an event across redeliveries, for consumers that
+// deduplicate.
+func MessageID(m domain.OutboxMessage) string {
+	return "workline-" + strconv.FormatInt(m.EventID, 10)
+}
+
+type natsPublisher struct {
+	conn *nats.Conn
+}
+
+func (p natsPublisher) Publish(ctx context.Context, msgs []domain.OutboxMessage) error {
+	if !p.conn.IsConnected() {
+		return fmt.Errorf("nats not connected (%s)", p.conn.Status())
+	}
+	for _, m := range msgs {
+		msg := nats.NewMsg(m.Topic)
+		msg.Data = []byte(m.Payload)
+		msg.Header.Set(nats.MsgIdHdr, MessageID(m))
+		if err := p.conn.PublishMsg(msg); err != nil {
+			return err
+		}
+	}
+	// A flush round trip confirms the server has received everything above.
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p natsPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+type kafkaPublisher struct {
+	w *kafka.Writer
+}
+
+func (p kafkaPublisher) Publish(ctx context.Context, msgs []domain.OutboxMessage) error {
+	out := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, kafka.Message{
+			Topic:   m.Topic,
+			Key:     []byte(m.Key),
+			Value:   []byte(m.Payload),
+			Headers: []kafka.Header{{Key: "workline-event-id", Value: []byte(MessageID(m))}},
+		})
+	}
+	return p.w.WriteMessages(ctx, out...)
+}
+
+func (p kafkaPublisher) Close() error {
+	return p.w.Close()
+}
+
+const (
+	// DefaultBatch is how many messages the relay publishes at once.
+	DefaultBatch = 100
+	// DefaultInterval is how often an idle relay polls the outbox.
+	DefaultInterval = time.Second
+	// publishTimeout bounds how long a batch may wait for broker acks.
+	publishTimeout = 30 * time.Second
+	// maxBackoff caps the delay between retries of a failing batch.
+	maxBackoff = 5 * time.Minute
+)
+
+// Relay moves messages from the outbox to a Publisher. Messages are deleted
+// only after the broker acknowledged them, so delivery is at least once:
+// a crash between publish and delete sends them again.
+type Relay struct {
+	Repo      repo.Repository
+	Publisher Publisher
+	Batch     int
+	Now       func() time.Time
+}
+
+// RunOnce publishes one batch of due messages in outbox order and returns how
+// many were delivered. On failure the whole batch is retried later with
+// exponential backoff, keeping messages in order.
+func (r Relay) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	batch := r.Batch
+	if batch <= 0 {
+		batch = DefaultBatch
+	}
+	ts := now().UTC()
+	msgs, err := r.Repo.DueOutbox(ctx, ts.Format(time.RFC3339), batch)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+	ids := make([]int64, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	pubCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	err = r.Publisher.Publish(pubCtx, msgs)
+	cancel()
+	if err != nil {
+		next := ts.Add(backoff(msgs[0].Attempts)).Format(time.RFC3339)
+		if derr := r.Repo.DeferOutbox(ctx, ids, err.Error(), next); derr != nil {
+			return 0, derr
+		}
+		return 0, fmt.Errorf("publish outbox: %w", err)
+	}
+	if err := r.Repo.DeleteOutbox(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(msgs), nil
+}
+
+// Run relays messages until ctx is done, polling every interval while the
+// outbox is empty.
+func (r Relay) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	batch := r.Batch
+	if batch <= 0 {
+		batch = DefaultBatch
+	}
+	for {
+		n, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		if n == batch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func backoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return maxBackoff
+	}
+	return min(time.Second<<attempts, maxBackoff)
+}
@@ -0,0 +1,193 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// HashActorID returns the SHA-256 hex digest kept for a scrubbed actor id.
+func HashActorID(actorID string) string {
+	sum := sha256.Sum256([]byte(actorID))
+	return hex.EncodeToString(sum[:])
+}
+
+// actorColumns hold actor ids outright; scrubbing replaces them.
+var actorColumns = []struct{ table, column string }{
+	{"tasks", "assignee_id"},
+	{"leases", "owner_id"},
+	{"attestations", "actor_id"},
+	{"attestation_evidence", "created_by"},
+	{"decisions", "decider_id"},
+	{"validations", "created_by"},
+	{"task_time_entries", "actor_id"},
+	{"task_relations", "created_by"},
+	{"releases", "created_by"},
+	{"force_requests", "requested_by"},
+	{"force_requests", "approved_by"},
+	{"project_config_versions", "actor_id"},
+	{"read_audit", "actor_id"},
+	{"offline_queue", "actor_id"},
+	{"events", "actor_id"},
+}
+
+// actorJSONColumns hold JSON that may name an actor in any string value.
+var actorJSONColumns = []struct{ table, key, column string }{
+	{"events", "id", "payload_json"},
+	{"outbox", "id", "payload_json"},
+	{"webhook_deliveries", "id", "body"},
+	{"hook_deliveries", "id", "body"},
+	{"sync_base", "rowid", "snapshot_json"},
+	{"offline_queue", "seq", "payload_json"},
+	{"force_requests", "id", "params_json"},
+}
+
+// actorGrantTables give an actor access; scrubbing deletes its rows.
+var actorGrantTables = []string{"actor_roles", "org_actor_roles", "org_roles", "api_keys", "calendar_feeds", "actor_missions"}
+
+// ScrubbedEventsTx returns, per project id ("" for project-less events), the
+// first event that names actorID, as actor or anywhere in its payload.
+func (r Repo) ScrubbedEventsTx(ctx context.Context, tx *sql.Tx, actorID string) (map[string]int64, error) {
+	quoted, _ := json.Marshal(actorID)
+	rows, err := tx.QueryContext(ctx, `SELECT IFNULL(project_id,''), MIN(id) FROM events WHERE actor_id=? OR instr(payload_json, ?)>0 GROUP BY project_id`, actorID, string(quoted))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]int64{}
+	for rows.Next() {
+		var projectID string
+		var id int64
+		if err := rows.Scan(&projectID, &id); err != nil {
+			return nil, err
+		}
+		res[projectID] = id
+	}
+	return res, rows.Err()
+}
+
+// ScrubActorTx replaces actorID with pseudonym wherever it is stored, drops
+// its grants, API keys and missions, and keeps only a hash of the former id.
+// The actor row moves to the pseudonym, suspended and without display name.
+// Event hashes are left for the caller to recompute. It returns how many
+// rows it rewrote.
+func (r Repo) ScrubActorTx(ctx context.Context, tx *sql.Tx, actorID, pseudonym, scrubbedAt string) (int, error) {
+	n := 0
+	if _, err := tx.ExecContext(ctx, `INSERT INTO actors(id, display_name, kind, created_at, status, deactivated_at)
+SELECT ?, NULL, kind, created_at, 'suspended', ? FROM actors WHERE id=?`, pseudonym, scrubbedAt, actorID); err != nil {
+		return n, err
+	}
+	for _, c := range actorColumns {
+		res, err := tx.ExecContext(ctx, `UPDATE `+c.table+` SET `+c.column+`=? WHERE `+c.column+`=?`, pseudonym, actorID)
+		if err != nil {
+			return n, err
+		}
+		changed, err := res.RowsAffected()
+		if err != nil {
+			return n, err
+		}
+		n += int(changed)
+	}
+	for _, c := range actorJSONColumns {
+		changed, err := scrubJSONColumnTx(ctx, tx, c.table, c.key, c.column, actorID, pseudonym)
+		if err != nil {
+			return n, err
+		}
+		n += changed
+	}
+	for _, table := range actorGrantTables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE actor_id=?`, actorID); err != nil {
+			return n, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM actors WHERE id=?`, actorID); err != nil {
+		return n, err
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO scrubbed_actors(id_hash, pseudonym, scrubbed_at) VALUES (?,?,?)`, HashActorID(actorID), pseudonym, scrubbedAt)
+	return n, err
+}
+
+// scrubJSONColumnTx rewrites the JSON values of a column whose string values
+// (or object keys) equal actorID.
+func scrubJSONColumnTx(ctx context.Context, tx *sql.Tx, table, key, column, actorID, pseudonym string) (int, error) {
+	quoted, _ := json.Marshal(actorID)
+	rows, err := tx.QueryContext(ctx, `SELECT `+key+`, `+column+` FROM `+table+` WHERE instr(`+column+`, ?)>0`, string(quoted))
+	if err != nil {
+		return 0, err
+	}
+	type change struct {
+		key   any
+		value string
+	}
+	var changes []change
+	for rows.Next() {
+		var k any
+		var raw string
+		if err := rows.Scan(&k, &raw); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			// Not JSON, such as an encrypted value: nothing to rewrite.
+			continue
+		}
+		v, replaced := replaceJSONString(v, actorID, pseudonym)
+		if !replaced {
+			continue
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		changes = append(changes, change{key: k, value: string(out)})
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range changes {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET `+column+`=? WHERE `+key+`=?`, c.value, c.key); err != nil {
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
+func replaceJSONString(v any, from, to string) (any, bool) {
+	switch t := v.(type) {
+	case string:
+		if t == from {
+			return to, true
+		}
+	case []any:
+		replaced := false
+		for i, item := range t {
+			var ok bool
+			if t[i], ok = replaceJSONString(item, from, to); ok {
+				replaced = true
+			}
+		}
+		return t, replaced
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		replaced := false
+		for k, item := range t {
+			item, ok := replaceJSONString(item, from, to)
+			if k == from {
+				k, ok = to, true
+			}
+			out[k] = item
+			replaced = replaced || ok
+		}
+		return out, replaced
+	}
+	return v, false
+}
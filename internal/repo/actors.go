@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// ActorStatus returns the lifecycle status of an actor, "scrubbed" for a
+// scrubbed id, or ErrNotFound when unknown.
+func (r Repo) ActorStatus(ctx context.Context, actorID string) (string, error) {
+	var status string
+	err := r.DB.QueryRowContext(ctx, `SELECT status FROM actors WHERE id=?`, actorID).Scan(&status)
+	if err == sql.ErrNoRows {
+		err = r.DB.QueryRowContext(ctx, `SELECT 'scrubbed' FROM scrubbed_actors WHERE id_hash=?`, HashActorID(actorID)).Scan(&status)
+	}
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return status, err
+}
+
+func (r Repo) ActorExistsTx(ctx context.Context, tx *sql.Tx, actorID string) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM actors WHERE id=?`, actorID).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r Repo) SetActorStatusTx(ctx context.Context, tx *sql.Tx, actorID, status string, deactivatedAt *string) error {
+	res, err := tx.ExecContext(ctx, `UPDATE actors SET status=?, deactivated_at=? WHERE id=?`, status, nullableStringPtr(deactivatedAt), actorID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r Repo) ListLeasesByOwnerTx(ctx context.Context, tx *sql.Tx, ownerID string) ([]domain.Lease, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT task_id,owner_id,acquired_at,expires_at FROM leases WHERE owner_id=? ORDER BY task_id`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Lease
+	for rows.Next() {
+		var l domain.Lease
+		if err := rows.Scan(&l.TaskID, &l.OwnerID, &l.AcquiredAt, &l.ExpiresAt); err != nil {
+			return nil, err
+		}
+		res = append(res, l)
+	}
+	return res, rows.Err()
+}
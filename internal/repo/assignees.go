@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// ProjectActorsTx returns every actor holding a role in the project, directly
+// or through the project's org, ordered by id.
+func (r Repo) ProjectActorsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT actor_id FROM actor_roles WHERE project_id=?1 AND (expires_at IS NULL OR expires_at > strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+UNION
+SELECT oar.actor_id FROM org_actor_roles oar JOIN projects p ON p.org_id=oar.org_id WHERE p.id=?1
+UNION
+SELECT om.actor_id FROM org_roles om JOIN projects p ON p.org_id=om.org_id WHERE p.id=?1 AND om.role='owner'
+ORDER BY 1`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
+// AssignedTasksTx returns the id, type, status and assignee of a project's
+// assigned tasks.
+func (r Repo) AssignedTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, type, status, assignee_id FROM tasks WHERE project_id=? AND assignee_id IS NOT NULL`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Task
+	for rows.Next() {
+		var t domain.Task
+		var assignee string
+		if err := rows.Scan(&t.ID, &t.Type, &t.Status, &assignee); err != nil {
+			return nil, err
+		}
+		t.ProjectID = projectID
+		t.AssigneeID = &assignee
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// LastActivityTx returns the timestamp of each actor's latest event in the
+// project.
+func (r Repo) LastActivityTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT actor_id, MAX(ts) FROM events WHERE project_id=? GROUP BY actor_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]string{}
+	for rows.Next() {
+		var actorID, ts string
+		if err := rows.Scan(&actorID, &ts); err != nil {
+			return nil, err
+		}
+		res[actorID] = ts
+	}
+	return res, rows.Err()
+}
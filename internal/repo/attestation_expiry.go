@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// UnexpiredAttestationsTx returns a project's attestations of the given kinds
+// not yet marked expired, oldest first.
+func (r Repo) UnexpiredAttestationsTx(ctx context.Context, tx *sql.Tx, projectID string, kinds []string) ([]domain.Attestation, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	args := appendStrings([]any{projectID}, kinds)
+	rows, err := tx.QueryContext(ctx, `SELECT id,project_id,entity_kind,entity_id,kind,actor_id,ts FROM attestations
+WHERE project_id=? AND expired_at IS NULL AND kind IN (`+placeholders(len(kinds))+`) ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Attestation
+	for rows.Next() {
+		var a domain.Attestation
+		if err := rows.Scan(&a.ID, &a.ProjectID, &a.EntityKind, &a.EntityID, &a.Kind, &a.ActorID, &a.TS); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+// MarkAttestationExpiredTx records when the sweeper expired an attestation.
+func (r Repo) MarkAttestationExpiredTx(ctx context.Context, tx *sql.Tx, id, expiredAt string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE attestations SET expired_at=? WHERE id=?`, expiredAt, id)
+	return err
+}
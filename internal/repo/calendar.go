@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// IterationStatusEventsTx returns a project's iteration status changes,
+// oldest first.
+func (r Repo) IterationStatusEventsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Event, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json FROM events
+WHERE project_id=? AND entity_kind='iteration' AND type='iteration.updated' ORDER BY id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var payload sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &e.EntityID, &e.ActorID, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Valid {
+			e.Payload = payload.String
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
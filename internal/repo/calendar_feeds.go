@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"workline/internal/domain"
+)
+
+const calendarFeedColumns = `id, project_id, actor_id, COALESCE(name,''), created_at`
+
+func scanCalendarFeed(row interface{ Scan(...any) error }) (domain.CalendarFeed, error) {
+	var f domain.CalendarFeed
+	err := row.Scan(&f.ID, &f.ProjectID, &f.ActorID, &f.Name, &f.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.CalendarFeed{}, ErrNotFound
+	}
+	return f, err
+}
+
+// InsertCalendarFeedTx stores a calendar feed under the hash of its token.
+func (r Repo) InsertCalendarFeedTx(ctx context.Context, tx *sql.Tx, feed domain.CalendarFeed, tokenHash string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO calendar_feeds(id, project_id, actor_id, name, token_hash, created_at) VALUES (?,?,?,?,?,?)`,
+		feed.ID, feed.ProjectID, feed.ActorID, nullable(feed.Name), tokenHash, feed.CreatedAt)
+	return err
+}
+
+// GetCalendarFeedByHash returns the calendar feed whose token hashes to hash.
+func (r Repo) GetCalendarFeedByHash(ctx context.Context, hash string) (domain.CalendarFeed, error) {
+	return scanCalendarFeed(r.DB.QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE token_hash=?`, hash))
+}
+
+// GetCalendarFeedTx returns a project's calendar feed by id.
+func (r Repo) GetCalendarFeedTx(ctx context.Context, tx *sql.Tx, projectID, id string) (domain.CalendarFeed, error) {
+	return scanCalendarFeed(tx.QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE project_id=? AND id=?`, projectID, id))
+}
+
+// ListCalendarFeedsTx lists a project's calendar feeds, newest first,
+// optionally only those of one actor.
+func (r Repo) ListCalendarFeedsTx(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]domain.CalendarFeed, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE project_id=? AND (?='' OR actor_id=?) ORDER BY created_at DESC, id`, projectID, actorID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.CalendarFeed
+	for rows.Next() {
+		f, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, rows.Err()
+}
+
+// DeleteCalendarFeedTx revokes a calendar feed.
+func (r Repo) DeleteCalendarFeedTx(ctx context.Context, tx *sql.Tx, id string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE id=?`, id)
+	return err
+}
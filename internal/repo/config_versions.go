@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// ListProjectConfigVersionsTx returns the stored config versions, newest first.
+func (r Repo) ListProjectConfigVersionsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ConfigVersion, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT project_id, version, config_json, COALESCE(actor_id,''), created_at
+FROM project_config_versions WHERE project_id=? ORDER BY version DESC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ConfigVersion
+	for rows.Next() {
+		var v domain.ConfigVersion
+		if err := rows.Scan(&v.ProjectID, &v.Version, &v.ConfigJSON, &v.ActorID, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, rows.Err()
+}
+
+// GetProjectConfigVersionTx returns one config version; version 0 selects the latest.
+func (r Repo) GetProjectConfigVersionTx(ctx context.Context, tx *sql.Tx, projectID string, version int) (domain.ConfigVersion, error) {
+	var v domain.ConfigVersion
+	err := tx.QueryRowContext(ctx, `SELECT project_id, version, config_json, COALESCE(actor_id,''), created_at
+FROM project_config_versions WHERE project_id=? AND (?=0 OR version=?) ORDER BY version DESC LIMIT 1`, projectID, version, version).
+		Scan(&v.ProjectID, &v.Version, &v.ConfigJSON, &v.ActorID, &v.CreatedAt)
+	if err == sql.ErrNoRows {
+		return v, ErrNotFound
+	}
+	return v, err
+}
+
+// OpenTaskRequirementsTx returns the id, type, status and required attestations
+// of the project's tasks that are not completed.
+func (r Repo) OpenTaskRequirementsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, type, status, required_attestations_json FROM tasks
+WHERE project_id=? AND completed_at IS NULL AND required_attestations_json IS NOT NULL ORDER BY id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Task
+	for rows.Next() {
+		var t domain.Task
+		var required string
+		if err := rows.Scan(&t.ID, &t.Type, &t.Status, &required); err != nil {
+			return nil, err
+		}
+		t.ProjectID = projectID
+		t.RequiredAttestationsJSON = &required
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// DoneTasksTx returns the done tasks of a project with the fields needed for metrics.
+func (r Repo) DoneTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, status, iteration_id, required_attestations_json, created_at, completed_at FROM tasks WHERE project_id=? AND completed_at IS NOT NULL ORDER BY id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Task
+	for rows.Next() {
+		var t domain.Task
+		var iterationID, required, completedAt sql.NullString
+		if err := rows.Scan(&t.ID, &t.Status, &iterationID, &required, &t.CreatedAt, &completedAt); err != nil {
+			return nil, err
+		}
+		t.ProjectID = projectID
+		if iterationID.Valid {
+			t.IterationID = &iterationID.String
+		}
+		if required.Valid {
+			t.RequiredAttestationsJSON = &required.String
+		}
+		if completedAt.Valid {
+			t.CompletedAt = &completedAt.String
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// ForcedCompletionsTx returns ids of tasks moved to their done state with the
+// force flag. doneStates maps task types to the done state of their workflow;
+// types it does not list use "done".
+func (r Repo) ForcedCompletionsTx(ctx context.Context, tx *sql.Tx, projectID string, doneStates map[string]string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT ev.entity_id, ev.type, COALESCE(json_extract(ev.payload_json, '$.to_status'),''), COALESCE(t.type,'')
+FROM events ev LEFT JOIN tasks t ON t.id=ev.entity_id
+WHERE ev.project_id=? AND ev.entity_kind='task'
+  AND json_extract(ev.payload_json, '$.forced')=1
+  AND (ev.type='task.done' OR json_extract(ev.payload_json, '$.to_status') IS NOT NULL)`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]bool{}
+	for rows.Next() {
+		var id, eventType, toStatus, taskType string
+		if err := rows.Scan(&id, &eventType, &toStatus, &taskType); err != nil {
+			return nil, err
+		}
+		done, ok := doneStates[taskType]
+		if !ok {
+			done = "done"
+		}
+		if eventType == "task.done" || toStatus == done {
+			res[id] = true
+		}
+	}
+	return res, rows.Err()
+}
+
+// PolicyOverridesByIterationTx counts policy.override events per task iteration.
+func (r Repo) PolicyOverridesByIterationTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT t.iteration_id, count(*) FROM events ev
+JOIN tasks t ON t.id=ev.entity_id
+WHERE ev.project_id=? AND ev.entity_kind='task' AND ev.type='policy.override' AND t.iteration_id IS NOT NULL
+GROUP BY t.iteration_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]int{}
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		res[id] = n
+	}
+	return res, rows.Err()
+}
+
+// ValidationStatusCountsTx counts validations by status.
+func (r Repo) ValidationStatusCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT status, count(*) FROM validations WHERE project_id=? GROUP BY status`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		res[status] = n
+	}
+	return res, rows.Err()
+}
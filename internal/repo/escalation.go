@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// EscalationCandidatesTx returns a project's open tasks with a priority that
+// were created, or last escalated, before sinceBefore, longest waiting first.
+// From is the current priority; To and AfterDays are left for the caller.
+func (r Repo) EscalationCandidatesTx(ctx context.Context, tx *sql.Tx, projectID, sinceBefore string) ([]domain.Escalation, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,title,type,status,assignee_id,priority,COALESCE(escalated_at, created_at) AS since FROM tasks
+WHERE project_id=? AND priority IS NOT NULL AND COALESCE(escalated_at, created_at)<? AND `+openTaskClause+` ORDER BY since, id`, projectID, sinceBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Escalation
+	for rows.Next() {
+		var c domain.Escalation
+		var assigneeID sql.NullString
+		if err := rows.Scan(&c.TaskID, &c.Title, &c.Type, &c.Status, &assigneeID, &c.From, &c.Since); err != nil {
+			return nil, err
+		}
+		if assigneeID.Valid {
+			c.AssigneeID = &assigneeID.String
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// EscalateTaskTx sets a task's priority as raised by the escalation sweep. It
+// leaves updated_at alone, so escalation does not count as activity.
+func (r Repo) EscalateTaskTx(ctx context.Context, tx *sql.Tx, id string, priority int, escalatedAt string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE tasks SET priority=?, escalated_at=?, version=version+1 WHERE id=?`, priority, escalatedAt, id)
+	return err
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// EventChainPageTx returns up to limit events of a project after afterID,
+// oldest first, with their chain hashes. An empty projectID pages the chain
+// of project-less events.
+func (r Repo) EventChainPageTx(ctx context.Context, tx *sql.Tx, projectID string, afterID int64, limit int) ([]domain.Event, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,ts,type,IFNULL(project_id,''),entity_kind,entity_id,actor_id,payload_json,prev_hash,hash
+FROM events WHERE project_id IS ? AND id>? ORDER BY id LIMIT ?`, nullable(projectID), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var entityID, prevHash, hash sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &entityID, &e.ActorID, &e.Payload, &prevHash, &hash); err != nil {
+			return nil, err
+		}
+		e.EntityID, e.PrevHash, e.Hash = entityID.String, prevHash.String, hash.String
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// SetEventHashTx replaces an event's chain hashes.
+func (r Repo) SetEventHashTx(ctx context.Context, tx *sql.Tx, id int64, prevHash, hash string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE events SET prev_hash=?, hash=? WHERE id=?`, nullable(prevHash), hash, id)
+	return err
+}
+
+// EventChainHeadTx returns the project's latest hashed event; ErrNotFound
+// when it has none.
+func (r Repo) EventChainHeadTx(ctx context.Context, tx *sql.Tx, projectID string) (domain.EventChainHead, error) {
+	head := domain.EventChainHead{ProjectID: projectID}
+	err := tx.QueryRowContext(ctx, `SELECT id,ts,hash FROM events WHERE project_id=? AND hash IS NOT NULL ORDER BY id DESC LIMIT 1`, projectID).
+		Scan(&head.EventID, &head.TS, &head.Hash)
+	if err == sql.ErrNoRows {
+		return head, ErrNotFound
+	}
+	return head, err
+}
+
+// InsertEventCompactionTx records the id range a compaction removed.
+func (r Repo) InsertEventCompactionTx(ctx context.Context, tx *sql.Tx, projectID string, firstID, lastID int64, compactedAt string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO event_compactions(project_id, first_id, last_id, compacted_at) VALUES (?,?,?,?)`, projectID, firstID, lastID, compactedAt)
+	return err
+}
+
+// CompactedRangesTx returns the id ranges, first and last, that compactions
+// of the project removed.
+func (r Repo) CompactedRangesTx(ctx context.Context, tx *sql.Tx, projectID string) ([][2]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT first_id, last_id FROM event_compactions WHERE project_id=?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res [][2]int64
+	for rows.Next() {
+		var r [2]int64
+		if err := rows.Scan(&r[0], &r[1]); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
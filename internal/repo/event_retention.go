@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// CompactableEventsTx returns a project's events outside retention, oldest
+// first: those older than before (RFC3339, empty to skip) and those past the
+// newest keep rows (0 to skip). Events whose type matches exempt are never
+// returned and do not count toward keep; a pattern ending in "*" matches a prefix.
+func (r Repo) CompactableEventsTx(ctx context.Context, tx *sql.Tx, projectID, before string, keep int, exempt []string) ([]domain.Event, error) {
+	clauses := []string{"project_id=?"}
+	args := []any{projectID}
+	for _, pattern := range exempt {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			clauses = append(clauses, "substr(type,1,?)<>?")
+			args = append(args, len(prefix), prefix)
+			continue
+		}
+		clauses = append(clauses, "type<>?")
+		args = append(args, pattern)
+	}
+	query := fmt.Sprintf(`
+WITH candidates AS (
+  SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json,prev_hash,hash,
+    ROW_NUMBER() OVER (ORDER BY id DESC) AS rn
+  FROM events WHERE %s
+)
+SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json,prev_hash,hash
+FROM candidates
+WHERE (?<>'' AND ts<?) OR (?>0 AND rn>?)
+ORDER BY id`, strings.Join(clauses, " AND "))
+	args = append(args, before, before, keep, keep)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var entityID, prevHash, hash sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &entityID, &e.ActorID, &e.Payload, &prevHash, &hash); err != nil {
+			return nil, err
+		}
+		e.EntityID, e.PrevHash, e.Hash = entityID.String, prevHash.String, hash.String
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// DeleteEventsTx removes events by id.
+func (r Repo) DeleteEventsTx(ctx context.Context, tx *sql.Tx, ids []int64) error {
+	const chunk = 500
+	for start := 0; start < len(ids); start += chunk {
+		end := min(start+chunk, len(ids))
+		args := make([]any, 0, end-start)
+		for _, id := range ids[start:end] {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+		if _, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id IN (`+placeholders+`)`, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+const evidenceColumns = `id,attestation_id,project_id,name,content_type,size,sha256,storage_key,created_by,created_at`
+
+func (r Repo) GetAttestationTx(ctx context.Context, tx *sql.Tx, id string) (domain.Attestation, error) {
+	var a domain.Attestation
+	var payload, expiredAt sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json,expired_at FROM attestations WHERE id=?`, id).
+		Scan(&a.ID, &a.ProjectID, &a.EntityKind, &a.EntityID, &a.Kind, &a.ActorID, &a.TS, &payload, &expiredAt)
+	if err == sql.ErrNoRows {
+		return a, ErrNotFound
+	}
+	if err != nil {
+		return a, err
+	}
+	if expiredAt.Valid {
+		a.ExpiredAt = &expiredAt.String
+	}
+	a.PayloadJSON, err = r.Fields.Open(attestationPayloadField(a.ID), payload.String)
+	return a, err
+}
+
+func (r Repo) InsertEvidenceTx(ctx context.Context, tx *sql.Tx, ev domain.Evidence) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO attestation_evidence(`+evidenceColumns+`) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+		ev.ID, ev.AttestationID, ev.ProjectID, ev.Name, ev.ContentType, ev.Size, ev.SHA256, ev.StorageKey, ev.CreatedBy, ev.CreatedAt)
+	return err
+}
+
+func (r Repo) GetEvidenceTx(ctx context.Context, tx *sql.Tx, id string) (domain.Evidence, error) {
+	var ev domain.Evidence
+	err := tx.QueryRowContext(ctx, `SELECT `+evidenceColumns+` FROM attestation_evidence WHERE id=?`, id).
+		Scan(&ev.ID, &ev.AttestationID, &ev.ProjectID, &ev.Name, &ev.ContentType, &ev.Size, &ev.SHA256, &ev.StorageKey, &ev.CreatedBy, &ev.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ev, ErrNotFound
+	}
+	return ev, err
+}
+
+func (r Repo) ListEvidenceTx(ctx context.Context, tx *sql.Tx, attestationID string) ([]domain.Evidence, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+evidenceColumns+` FROM attestation_evidence WHERE attestation_id=? ORDER BY created_at, id`, attestationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Evidence
+	for rows.Next() {
+		var ev domain.Evidence
+		if err := rows.Scan(&ev.ID, &ev.AttestationID, &ev.ProjectID, &ev.Name, &ev.ContentType, &ev.Size, &ev.SHA256, &ev.StorageKey, &ev.CreatedBy, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, ev)
+	}
+	return res, rows.Err()
+}
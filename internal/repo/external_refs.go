@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// ListExternalRefs returns a project's refs from one source, keyed by
+// external id.
+func (r Repo) ListExternalRefs(ctx context.Context, projectID, source string) (map[string]domain.ExternalRef, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT project_id, source, external_id, entity_kind, entity_id, imported_at FROM external_refs WHERE project_id=? AND source=?`, projectID, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	refs := map[string]domain.ExternalRef{}
+	for rows.Next() {
+		var ref domain.ExternalRef
+		if err := rows.Scan(&ref.ProjectID, &ref.Source, &ref.ExternalID, &ref.EntityKind, &ref.EntityID, &ref.ImportedAt); err != nil {
+			return nil, err
+		}
+		refs[ref.ExternalID] = ref
+	}
+	return refs, rows.Err()
+}
+
+func (r Repo) UpsertExternalRef(ctx context.Context, ref domain.ExternalRef) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO external_refs(project_id, source, external_id, entity_kind, entity_id, imported_at) VALUES (?,?,?,?,?,?)
+ON CONFLICT(project_id, source, external_id) DO UPDATE SET entity_kind=excluded.entity_kind, entity_id=excluded.entity_id, imported_at=excluded.imported_at`,
+		ref.ProjectID, ref.Source, ref.ExternalID, ref.EntityKind, ref.EntityID, ref.ImportedAt)
+	return err
+}
+
+// GetExternalRef returns the ref a project holds for an external id.
+func (r Repo) GetExternalRef(ctx context.Context, projectID, source, externalID string) (domain.ExternalRef, error) {
+	return getExternalRef(ctx, r.DB, projectID, source, externalID)
+}
+
+func (r Repo) GetExternalRefTx(ctx context.Context, tx *sql.Tx, projectID, source, externalID string) (domain.ExternalRef, error) {
+	return getExternalRef(ctx, tx, projectID, source, externalID)
+}
+
+func getExternalRef(ctx context.Context, q queryer, projectID, source, externalID string) (domain.ExternalRef, error) {
+	var ref domain.ExternalRef
+	err := q.QueryRowContext(ctx, `SELECT project_id, source, external_id, entity_kind, entity_id, imported_at FROM external_refs WHERE project_id=? AND source=? AND external_id=?`, projectID, source, externalID).
+		Scan(&ref.ProjectID, &ref.Source, &ref.ExternalID, &ref.EntityKind, &ref.EntityID, &ref.ImportedAt)
+	if err == sql.ErrNoRows {
+		return ref, ErrNotFound
+	}
+	return ref, err
+}
+
+// SetExternalRefTx links an entity to an external id, replacing the entity's
+// previous id in that source.
+func (r Repo) SetExternalRefTx(ctx context.Context, tx *sql.Tx, ref domain.ExternalRef) error {
+	if err := r.DeleteExternalRefTx(ctx, tx, ref.ProjectID, ref.Source, ref.EntityKind, ref.EntityID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO external_refs(project_id, source, external_id, entity_kind, entity_id, imported_at) VALUES (?,?,?,?,?,?)`,
+		ref.ProjectID, ref.Source, ref.ExternalID, ref.EntityKind, ref.EntityID, ref.ImportedAt)
+	return err
+}
+
+// DeleteExternalRefTx unlinks an entity from its id in one source.
+func (r Repo) DeleteExternalRefTx(ctx context.Context, tx *sql.Tx, projectID, source, entityKind, entityID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM external_refs WHERE project_id=? AND source=? AND entity_kind=? AND entity_id=?`, projectID, source, entityKind, entityID)
+	return err
+}
+
+// ExternalRefsFor returns the external ids of entities of one kind, keyed by
+// entity id and then source.
+func (r Repo) ExternalRefsFor(ctx context.Context, entityKind string, ids []string) (map[string]map[string]string, error) {
+	return externalRefsFor(ctx, r.DB, entityKind, ids)
+}
+
+func (r Repo) ExternalRefsForTx(ctx context.Context, tx *sql.Tx, entityKind string, ids []string) (map[string]map[string]string, error) {
+	return externalRefsFor(ctx, tx, entityKind, ids)
+}
+
+func externalRefsFor(ctx context.Context, q queryer, entityKind string, ids []string) (map[string]map[string]string, error) {
+	refs := map[string]map[string]string{}
+	for start := 0; start < len(ids); start += batchSize {
+		chunk := ids[start:min(start+batchSize, len(ids))]
+		rows, err := q.QueryContext(ctx, `SELECT entity_id, source, external_id FROM external_refs WHERE entity_kind=? AND entity_id IN (`+placeholders(len(chunk))+`)`, appendStrings([]any{entityKind}, chunk)...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var entityID, source, externalID string
+			if err := rows.Scan(&entityID, &source, &externalID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if refs[entityID] == nil {
+				refs[entityID] = map[string]string{}
+			}
+			refs[entityID][source] = externalID
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
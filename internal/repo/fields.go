@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"workline/internal/fieldcrypt"
+)
+
+// sealedColumns are the columns Fields seals, each value bound to its row id.
+var sealedColumns = []struct{ table, column string }{
+	{"tasks", "work_outcomes_json"},
+	{"attestations", "payload_json"},
+	{"decisions", "context_json"},
+}
+
+func workOutcomesField(taskID string) fieldcrypt.Field {
+	return fieldcrypt.Field{Table: "tasks", Column: "work_outcomes_json", RowID: taskID}
+}
+
+func attestationPayloadField(id string) fieldcrypt.Field {
+	return fieldcrypt.Field{Table: "attestations", Column: "payload_json", RowID: id}
+}
+
+func decisionContextField(id string) fieldcrypt.Field {
+	return fieldcrypt.Field{Table: "decisions", Column: "context_json", RowID: id}
+}
+
+// sealField seals a sensitive column value with r.Fields; empty stays NULL.
+func (r Repo) sealField(f fieldcrypt.Field, v string) (any, error) {
+	if v == "" {
+		return nil, nil
+	}
+	return r.Fields.Seal(f, v)
+}
+
+func (r Repo) sealFieldPtr(f fieldcrypt.Field, v *string) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return r.sealField(f, *v)
+}
+
+// openField opens a nullable sensitive column read back from the database.
+func (r Repo) openField(f fieldcrypt.Field, v sql.NullString) (*string, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	plain, err := r.Fields.Open(f, v.String)
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
+
+// ResealFieldsTx seals every sensitive value not sealed with the current key
+// yet: plaintext written before encryption was turned on, values sealed with
+// a previous key and values sealed before they were bound to their row. It
+// returns how many values it rewrote.
+func (r Repo) ResealFieldsTx(ctx context.Context, tx *sql.Tx) (int, error) {
+	type stale struct {
+		id    string
+		value string
+	}
+	n := 0
+	for _, c := range sealedColumns {
+		rows, err := tx.QueryContext(ctx, `SELECT id,`+c.column+` FROM `+c.table+` WHERE `+c.column+` IS NOT NULL AND `+c.column+` != ''`)
+		if err != nil {
+			return n, err
+		}
+		var todo []stale
+		for rows.Next() {
+			var s stale
+			if err := rows.Scan(&s.id, &s.value); err != nil {
+				rows.Close()
+				return n, err
+			}
+			current, err := r.Fields.Current(s.value)
+			if err != nil {
+				rows.Close()
+				return n, err
+			}
+			if !current {
+				todo = append(todo, s)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return n, err
+		}
+		for _, s := range todo {
+			f := fieldcrypt.Field{Table: c.table, Column: c.column, RowID: s.id}
+			plain, err := r.Fields.Open(f, s.value)
+			if err != nil {
+				return n, fmt.Errorf("%s.%s row %s: %w", c.table, c.column, s.id, err)
+			}
+			sealed, err := r.Fields.Seal(f, plain)
+			if err != nil {
+				return n, err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE `+c.table+` SET `+c.column+`=? WHERE id=?`, sealed, s.id); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
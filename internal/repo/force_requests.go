@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+const forceRequestColumns = `id, project_id, operation, entity_kind, entity_id, params_json, status, requested_by, requested_at, approved_by, approved_at`
+
+func (r Repo) InsertForceRequestTx(ctx context.Context, tx *sql.Tx, fr domain.ForceRequest) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO force_requests(`+forceRequestColumns+`) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		fr.ID, fr.ProjectID, fr.Operation, fr.EntityKind, fr.EntityID, fr.ParamsJSON, fr.Status, fr.RequestedBy, fr.RequestedAt, fr.ApprovedBy, fr.ApprovedAt)
+	return err
+}
+
+func (r Repo) GetForceRequest(ctx context.Context, id string) (domain.ForceRequest, error) {
+	fr, err := scanForceRequest(r.DB.QueryRowContext(ctx, `SELECT `+forceRequestColumns+` FROM force_requests WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return fr, ErrNotFound
+	}
+	return fr, err
+}
+
+// ListForceRequestsTx returns a project's force requests in status, oldest
+// first; an empty status returns all of them.
+func (r Repo) ListForceRequestsTx(ctx context.Context, tx *sql.Tx, projectID, status string) ([]domain.ForceRequest, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+forceRequestColumns+` FROM force_requests WHERE project_id=? AND (?='' OR status=?) ORDER BY requested_at, id`, projectID, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.ForceRequest{}
+	for rows.Next() {
+		fr, err := scanForceRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, fr)
+	}
+	return res, rows.Err()
+}
+
+// ApproveForceRequestTx marks a pending request approved. It reports false
+// when the request was no longer pending.
+func (r Repo) ApproveForceRequestTx(ctx context.Context, tx *sql.Tx, id, actorID, approvedAt string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `UPDATE force_requests SET status='approved', approved_by=?, approved_at=? WHERE id=? AND status='pending'`, actorID, approvedAt, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func scanForceRequest(row interface{ Scan(...any) error }) (domain.ForceRequest, error) {
+	var fr domain.ForceRequest
+	var approvedBy, approvedAt sql.NullString
+	if err := row.Scan(&fr.ID, &fr.ProjectID, &fr.Operation, &fr.EntityKind, &fr.EntityID, &fr.ParamsJSON, &fr.Status, &fr.RequestedBy, &fr.RequestedAt, &approvedBy, &approvedAt); err != nil {
+		return fr, err
+	}
+	if approvedBy.Valid {
+		fr.ApprovedBy = &approvedBy.String
+	}
+	if approvedAt.Valid {
+		fr.ApprovedAt = &approvedAt.String
+	}
+	return fr, nil
+}
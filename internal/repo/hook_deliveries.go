@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// InsertHookDeliveryTx queues a webhook hook delivery and returns its id.
+func (r Repo) InsertHookDeliveryTx(ctx context.Context, tx *sql.Tx, d domain.HookDelivery) (int64, error) {
+	res, err := tx.ExecContext(ctx, `INSERT INTO hook_deliveries(project_id,hook,url,event,body,created_at,next_attempt_at) VALUES (?,?,?,?,?,?,?)`,
+		d.ProjectID, d.Hook, d.URL, d.Event, d.Body, d.CreatedAt, d.NextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ClaimHookDeliveries returns up to limit of the project's deliveries due at
+// now (RFC3339), oldest first, and moves their next attempt to until so that
+// another sender skips them meanwhile.
+func (r Repo) ClaimHookDeliveries(ctx context.Context, projectID, now, until string, limit int) ([]domain.HookDelivery, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, `
+SELECT id,project_id,hook,url,event,body,created_at,attempts,last_error,next_attempt_at
+FROM hook_deliveries WHERE project_id=? AND next_attempt_at<=? ORDER BY id LIMIT ?`, projectID, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.HookDelivery
+	for rows.Next() {
+		var d domain.HookDelivery
+		var lastError sql.NullString
+		if err := rows.Scan(&d.ID, &d.ProjectID, &d.Hook, &d.URL, &d.Event, &d.Body, &d.CreatedAt, &d.Attempts, &lastError, &d.NextAttemptAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.LastError = lastError.String
+		res = append(res, d)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	for _, d := range res {
+		if _, err := tx.ExecContext(ctx, `UPDATE hook_deliveries SET next_attempt_at=? WHERE id=?`, until, d.ID); err != nil {
+			return nil, err
+		}
+	}
+	return res, tx.Commit()
+}
+
+// DeleteHookDelivery removes a delivered or abandoned hook delivery.
+func (r Repo) DeleteHookDelivery(ctx context.Context, id int64) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM hook_deliveries WHERE id=?`, id)
+	return err
+}
+
+// DeferHookDelivery records a failed attempt and schedules the next one at
+// nextAttemptAt (RFC3339).
+func (r Repo) DeferHookDelivery(ctx context.Context, id int64, lastError, nextAttemptAt string) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE hook_deliveries SET attempts=attempts+1,last_error=?,next_attempt_at=? WHERE id=?`, lastError, nextAttemptAt, id)
+	return err
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ReviewLoadTx counts tasks in review assigned to each actor, ignoring excludeTaskID.
+func (r Repo) ReviewLoadTx(ctx context.Context, tx *sql.Tx, projectID, excludeTaskID string, actors []string) (map[string]int, error) {
+	res := make(map[string]int, len(actors))
+	if len(actors) == 0 {
+		return res, nil
+	}
+	args := []any{projectID, excludeTaskID}
+	for _, a := range actors {
+		args = append(args, a)
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT assignee_id, count(*) FROM tasks
+WHERE project_id=? AND status='review' AND id<>? AND assignee_id IN (?`+strings.Repeat(",?", len(actors)-1)+`)
+GROUP BY assignee_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var actor string
+		var n int
+		if err := rows.Scan(&actor, &n); err != nil {
+			return nil, err
+		}
+		res[actor] = n
+	}
+	return res, rows.Err()
+}
+
+func (r Repo) SetTaskAssigneeTx(ctx context.Context, tx *sql.Tx, taskID, assigneeID, updatedAt string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE tasks SET assignee_id=?, updated_at=?, version=version+1 WHERE id=?`, nullable(assigneeID), updatedAt, taskID)
+	return err
+}
@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// NextIDSequenceTx advances and returns a project's counter for an entity kind.
+func (r Repo) NextIDSequenceTx(ctx context.Context, tx *sql.Tx, projectID, kind string) (int64, error) {
+	var n int64
+	err := tx.QueryRowContext(ctx, `INSERT INTO id_sequences(project_id, kind, last) VALUES (?,?,1)
+ON CONFLICT(project_id, kind) DO UPDATE SET last=last+1 RETURNING last`, projectID, kind).Scan(&n)
+	return n, err
+}
+
+// EntityIDExistsTx reports whether a task, iteration or decision has the id.
+func (r Repo) EntityIDExistsTx(ctx context.Context, tx *sql.Tx, kind, id string) (bool, error) {
+	tables := map[string]string{"task": "tasks", "iteration": "iterations", "decision": "decisions"}
+	table, ok := tables[kind]
+	if !ok {
+		return false, fmt.Errorf("unknown entity kind %s", kind)
+	}
+	var n int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM `+table+` WHERE id=?`, id).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
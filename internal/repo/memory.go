@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"workline/internal/db"
+	"workline/internal/migrate"
+)
+
+// NewMemorySQLite returns a Repo on a fresh, fully migrated in-memory SQLite
+// database, for tests, demos and `wl serve --ephemeral`. It is not a
+// separate storage backend: the Repo runs the same SQL, migrations and
+// single-connection pool as an on-disk workspace, only nothing touches the
+// disk. Closing r.DB discards everything.
+func NewMemorySQLite() (Repo, error) {
+	conn, err := db.OpenMemory()
+	if err != nil {
+		return Repo{}, err
+	}
+	if err := migrate.Migrate(conn); err != nil {
+		conn.Close()
+		return Repo{}, err
+	}
+	return Repo{DB: conn}, nil
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r Repo) InsertMilestoneTx(ctx context.Context, tx *sql.Tx, m domain.Milestone) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO milestones(id, project_id, goal, target_date, created_at) VALUES (?,?,?,?,?)`,
+		m.ID, m.ProjectID, m.Goal, m.TargetDate, m.CreatedAt)
+	return err
+}
+
+// AddMilestoneLinksTx links iterations and tasks to a milestone; existing
+// links are kept.
+func (r Repo) AddMilestoneLinksTx(ctx context.Context, tx *sql.Tx, milestoneID string, iterationIDs, taskIDs []string) error {
+	for _, id := range iterationIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO milestone_iterations(milestone_id, iteration_id) VALUES (?,?)`, milestoneID, id); err != nil {
+			return err
+		}
+	}
+	for _, id := range taskIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO milestone_tasks(milestone_id, task_id) VALUES (?,?)`, milestoneID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repo) GetMilestone(ctx context.Context, id string) (domain.Milestone, error) {
+	return getMilestone(ctx, r.DB, id)
+}
+
+func (r Repo) GetMilestoneTx(ctx context.Context, tx *sql.Tx, id string) (domain.Milestone, error) {
+	return getMilestone(ctx, tx, id)
+}
+
+func getMilestone(ctx context.Context, q queryer, id string) (domain.Milestone, error) {
+	var m domain.Milestone
+	err := q.QueryRowContext(ctx, `SELECT id, project_id, goal, target_date, created_at FROM milestones WHERE id=?`, id).
+		Scan(&m.ID, &m.ProjectID, &m.Goal, &m.TargetDate, &m.CreatedAt)
+	if err == sql.ErrNoRows {
+		return m, ErrNotFound
+	}
+	if err != nil {
+		return m, err
+	}
+	return m, loadMilestoneLinks(ctx, q, &m)
+}
+
+// ListMilestonesTx returns a project's milestones, soonest target date first.
+func (r Repo) ListMilestonesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Milestone, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, project_id, goal, target_date, created_at FROM milestones WHERE project_id=? ORDER BY target_date, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Milestone
+	for rows.Next() {
+		var m domain.Milestone
+		if err := rows.Scan(&m.ID, &m.ProjectID, &m.Goal, &m.TargetDate, &m.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if err := loadMilestoneLinks(ctx, tx, &res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func loadMilestoneLinks(ctx context.Context, q queryer, m *domain.Milestone) error {
+	var err error
+	if m.IterationIDs, err = queryIDs(ctx, q, `SELECT iteration_id FROM milestone_iterations WHERE milestone_id=? ORDER BY iteration_id`, m.ID); err != nil {
+		return err
+	}
+	m.TaskIDs, err = queryIDs(ctx, q, `SELECT task_id FROM milestone_tasks WHERE milestone_id=? ORDER BY task_id`, m.ID)
+	return err
+}
+
+func queryIDs(ctx context.Context, q queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
+// MilestoneProgressTx counts the tasks linked to a milestone directly or
+// through its iterations, ignoring canceled and rejected tasks, and the linked
+// iterations that are validated.
+func (r Repo) MilestoneProgressTx(ctx context.Context, tx *sql.Tx, milestoneID string) (domain.MilestoneProgress, error) {
+	var p domain.MilestoneProgress
+	err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*),
+       COALESCE(SUM(CASE WHEN completed_at IS NOT NULL THEN 1 END),0),
+       COALESCE(SUM(estimate),0),
+       COALESCE(SUM(CASE WHEN completed_at IS NOT NULL THEN estimate END),0)
+FROM tasks
+WHERE status NOT IN ('canceled','rejected')
+  AND (id IN (SELECT task_id FROM milestone_tasks WHERE milestone_id=?)
+       OR iteration_id IN (SELECT iteration_id FROM milestone_iterations WHERE milestone_id=?))`,
+		milestoneID, milestoneID).Scan(&p.TotalTasks, &p.DoneTasks, &p.PlannedPoints, &p.CompletedPoints)
+	if err != nil {
+		return p, err
+	}
+	err = tx.QueryRowContext(ctx, `
+SELECT COUNT(*), COALESCE(SUM(CASE WHEN i.status='validated' THEN 1 END),0)
+FROM milestone_iterations mi
+JOIN iterations i ON i.id=mi.iteration_id
+WHERE mi.milestone_id=?`, milestoneID).Scan(&p.Iterations, &p.ValidatedIterations)
+	return p, err
+}
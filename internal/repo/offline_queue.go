@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"workline/internal/domain"
+)
+
+const queuedCommandColumns = `seq, project_id, remote, actor_id, op, task_id, COALESCE(fields_json,''), payload_json, COALESCE(base_json,''), status, COALESCE(reason,''), signature, queued_at`
+
+func scanQueuedCommand(row interface{ Scan(...any) error }) (domain.QueuedCommand, error) {
+	var c domain.QueuedCommand
+	var fields string
+	err := row.Scan(&c.Seq, &c.ProjectID, &c.Remote, &c.ActorID, &c.Op, &c.TaskID, &fields, &c.Payload, &c.Base, &c.Status, &c.Reason, &c.Signature, &c.QueuedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.QueuedCommand{}, ErrNotFound
+	}
+	if err == nil && fields != "" {
+		err = json.Unmarshal([]byte(fields), &c.Fields)
+	}
+	return c, err
+}
+
+// InsertQueuedCommandTx appends a command to the offline queue, unsigned,
+// and returns its seq. Seqs are never reused.
+func (r Repo) InsertQueuedCommandTx(ctx context.Context, tx *sql.Tx, c domain.QueuedCommand) (int64, error) {
+	var fields any
+	if len(c.Fields) > 0 {
+		b, err := json.Marshal(c.Fields)
+		if err != nil {
+			return 0, err
+		}
+		fields = string(b)
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO offline_queue(project_id, remote, actor_id, op, task_id, fields_json, payload_json, base_json, status, queued_at) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+		c.ProjectID, c.Remote, c.ActorID, c.Op, c.TaskID, fields, c.Payload, nullable(c.Base), c.Status, c.QueuedAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// SignQueuedCommandTx stores the signature of a queued command.
+func (r Repo) SignQueuedCommandTx(ctx context.Context, tx *sql.Tx, seq int64, signature string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE offline_queue SET signature=? WHERE seq=?`, signature, seq)
+	return err
+}
+
+// GetQueuedCommandTx returns a project's queued command by seq.
+func (r Repo) GetQueuedCommandTx(ctx context.Context, tx *sql.Tx, projectID string, seq int64) (domain.QueuedCommand, error) {
+	return scanQueuedCommand(tx.QueryRowContext(ctx, `SELECT `+queuedCommandColumns+` FROM offline_queue WHERE project_id=? AND seq=?`, projectID, seq))
+}
+
+// ListQueuedCommandsTx lists a project's queued commands in seq order,
+// optionally only those for one remote.
+func (r Repo) ListQueuedCommandsTx(ctx context.Context, tx *sql.Tx, projectID, remote string) ([]domain.QueuedCommand, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+queuedCommandColumns+` FROM offline_queue WHERE project_id=? AND (?='' OR remote=?) ORDER BY seq`, projectID, remote, remote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.QueuedCommand
+	for rows.Next() {
+		c, err := scanQueuedCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// SetQueuedCommandStatusTx records why replay held a queued command back.
+func (r Repo) SetQueuedCommandStatusTx(ctx context.Context, tx *sql.Tx, seq int64, status, reason string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE offline_queue SET status=?, reason=? WHERE seq=?`, status, nullable(reason), seq)
+	return err
+}
+
+// DeleteQueuedCommandTx drops a command from the offline queue.
+func (r Repo) DeleteQueuedCommandTx(ctx context.Context, tx *sql.Tx, seq int64) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM offline_queue WHERE seq=?`, seq)
+	return err
+}
@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"workline/internal/domain"
+)
+
+func (r Repo) InsertOrgTx(ctx context.Context, tx *sql.Tx, org domain.Org) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO organizations(id, name, created_at) VALUES (?,?,?)`, org.ID, org.Name, org.CreatedAt)
+	return err
+}
+
+func (r Repo) GetOrg(ctx context.Context, id string) (domain.Org, error) {
+	var org domain.Org
+	var name sql.NullString
+	err := r.DB.QueryRowContext(ctx, `SELECT id, name, created_at FROM organizations WHERE id=?`, id).Scan(&org.ID, &name, &org.CreatedAt)
+	if err == sql.ErrNoRows {
+		return org, ErrNotFound
+	}
+	if name.Valid {
+		org.Name = name.String
+	}
+	return org, err
+}
+
+func (r Repo) GetOrgTx(ctx context.Context, tx *sql.Tx, id string) (domain.Org, error) {
+	var org domain.Org
+	var name sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT id, name, created_at FROM organizations WHERE id=?`, id).Scan(&org.ID, &name, &org.CreatedAt)
+	if err == sql.ErrNoRows {
+		return org, ErrNotFound
+	}
+	if name.Valid {
+		org.Name = name.String
+	}
+	return org, err
+}
+
+// ListOrgsForActor returns orgs where the actor holds an org role.
+func (r Repo) ListOrgsForActor(ctx context.Context, actorID string) ([]domain.Org, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+SELECT o.id, COALESCE(o.name,''), o.created_at
+FROM organizations o
+JOIN org_roles m ON m.org_id=o.id
+WHERE m.actor_id=?
+ORDER BY o.created_at, o.id`, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Org
+	for rows.Next() {
+		var org domain.Org
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, org)
+	}
+	return res, rows.Err()
+}
+
+func (r Repo) ListOrgMembers(ctx context.Context, orgID string) ([]domain.OrgMember, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT org_id, actor_id, role FROM org_roles WHERE org_id=? ORDER BY actor_id`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.OrgMember
+	for rows.Next() {
+		var m domain.OrgMember
+		if err := rows.Scan(&m.OrgID, &m.ActorID, &m.Role); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// OrgRoleTx returns the actor's org role, or ErrNotFound when the actor is not a member.
+func (r Repo) OrgRoleTx(ctx context.Context, tx *sql.Tx, orgID, actorID string) (string, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, `SELECT role FROM org_roles WHERE org_id=? AND actor_id=?`, orgID, actorID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return role, err
+}
+
+// ProjectOrgTx returns the org owning the project.
+func (r Repo) ProjectOrgTx(ctx context.Context, tx *sql.Tx, projectID string) (string, error) {
+	var orgID string
+	err := tx.QueryRowContext(ctx, `SELECT org_id FROM projects WHERE id=?`, projectID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return orgID, err
+}
+
+// ActorOrgsTx returns the orgs, sorted, where the actor is a member, holds a
+// project or org grant, a lease or an assignment, or is named by an event,
+// as actor or anywhere in its payload.
+func (r Repo) ActorOrgsTx(ctx context.Context, tx *sql.Tx, actorID string) ([]string, error) {
+	quoted, _ := json.Marshal(actorID)
+	rows, err := tx.QueryContext(ctx, `
+SELECT org_id FROM org_roles WHERE actor_id=?1
+UNION SELECT org_id FROM org_actor_roles WHERE actor_id=?1
+UNION SELECT p.org_id FROM actor_roles ar JOIN projects p ON p.id=ar.project_id WHERE ar.actor_id=?1
+UNION SELECT p.org_id FROM leases l JOIN tasks t ON t.id=l.task_id JOIN projects p ON p.id=t.project_id WHERE l.owner_id=?1
+UNION SELECT p.org_id FROM tasks t JOIN projects p ON p.id=t.project_id WHERE t.assignee_id=?1
+UNION SELECT p.org_id FROM events e JOIN projects p ON p.id=e.project_id WHERE e.actor_id=?1 OR instr(e.payload_json, ?2)>0
+UNION SELECT o.id FROM events e JOIN organizations o ON o.id=e.entity_id
+WHERE IFNULL(e.project_id,'')='' AND (e.actor_id=?1 OR instr(e.payload_json, ?2)>0)
+ORDER BY 1`, actorID, string(quoted))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var orgID string
+		if err := rows.Scan(&orgID); err != nil {
+			return nil, err
+		}
+		res = append(res, orgID)
+	}
+	return res, rows.Err()
+}
+
+func (r Repo) SetOrgRole(ctx context.Context, tx *sql.Tx, orgID, actorID, role string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO org_roles(org_id, actor_id, role) VALUES (?,?,?)
+ON CONFLICT(org_id, actor_id) DO UPDATE SET role=excluded.role`, orgID, actorID, role)
+	return err
+}
+
+func (r Repo) RemoveOrgMember(ctx context.Context, tx *sql.Tx, orgID, actorID string) error {
+	res, err := tx.ExecContext(ctx, `DELETE FROM org_roles WHERE org_id=? AND actor_id=?`, orgID, actorID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r Repo) CountOrgOwners(ctx context.Context, tx *sql.Tx, orgID string) (int, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT count(*) FROM org_roles WHERE org_id=? AND role='owner'`, orgID).Scan(&n)
+	return n, err
+}
+
+func (r Repo) ListProjectsByOrg(ctx context.Context, orgID string, includeArchived bool) ([]domain.Project, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id,org_id,kind,status,COALESCE(description,'') AS description,created_at FROM projects WHERE org_id=? AND (? OR status<>'archived') ORDER BY created_at DESC`, orgID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Project
+	for rows.Next() {
+		var p domain.Project
+		if err := rows.Scan(&p.ID, &p.OrgID, &p.Kind, &p.Status, &p.Description, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+func (r Repo) AssignOrgActorRole(ctx context.Context, tx *sql.Tx, orgID, actorID, roleID string) error {
+	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO org_actor_roles(org_id, actor_id, role_id) VALUES (?,?,?)`, orgID, actorID, roleID)
+	return err
+}
+
+func (r Repo) RevokeOrgActorRole(ctx context.Context, tx *sql.Tx, orgID, actorID, roleID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM org_actor_roles WHERE org_id=? AND actor_id=? AND role_id=?`, orgID, actorID, roleID)
+	return err
+}
@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// DueOutbox returns up to limit outbox messages whose next attempt is at or
+// before now (RFC3339), oldest first.
+func (r Repo) DueOutbox(ctx context.Context, now string, limit int) ([]domain.OutboxMessage, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+SELECT id,event_id,project_id,topic,msg_key,payload_json,created_at,attempts,last_error,next_attempt_at
+FROM outbox WHERE next_attempt_at<=? ORDER BY id LIMIT ?`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var projectID, lastError sql.NullString
+		if err := rows.Scan(&m.ID, &m.EventID, &projectID, &m.Topic, &m.Key, &m.Payload, &m.CreatedAt, &m.Attempts, &lastError, &m.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		m.ProjectID = projectID.String
+		m.LastError = lastError.String
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// DeleteOutbox removes delivered outbox messages.
+func (r Repo) DeleteOutbox(ctx context.Context, ids []int64) error {
+	return r.updateOutbox(ctx, `DELETE FROM outbox WHERE id IN (%s)`, nil, ids)
+}
+
+// DeferOutbox records a failed delivery attempt and schedules the messages
+// for retry at nextAttemptAt (RFC3339).
+func (r Repo) DeferOutbox(ctx context.Context, ids []int64, lastError, nextAttemptAt string) error {
+	return r.updateOutbox(ctx, `UPDATE outbox SET attempts=attempts+1,last_error=?,next_attempt_at=? WHERE id IN (%s)`,
+		[]any{lastError, nextAttemptAt}, ids)
+}
+
+// OutboxBacklog returns how many messages are waiting and the creation time
+// of the oldest one.
+func (r Repo) OutboxBacklog(ctx context.Context) (int, string, error) {
+	var n int
+	var oldest sql.NullString
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*),MIN(created_at) FROM outbox`).Scan(&n, &oldest)
+	return n, oldest.String, err
+}
+
+func (r Repo) updateOutbox(ctx context.Context, query string, args []any, ids []int64) error {
+	const chunk = 500
+	for start := 0; start < len(ids); start += chunk {
+		end := min(start+chunk, len(ids))
+		batch := append([]any{}, args...)
+		for _, id := range ids[start:end] {
+			batch = append(batch, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", end-start), ",")
+		if _, err := r.DB.ExecContext(ctx, strings.Replace(query, "%s", placeholders, 1), batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"workline/internal/domain"
+)
+
+// RenameProjectTx moves a project and every row referencing it to newID and
+// records oldID as an alias. Foreign keys are checked at commit, once all
+// references point to the new id. Events keep their hashes: the alias marks
+// which of them were hashed under oldID.
+func (r Repo) RenameProjectTx(ctx context.Context, tx *sql.Tx, oldID, newID, renamedAt string) error {
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO project_aliases(alias, project_id, last_event_id, renamed_at)
+SELECT ?, ?, COALESCE(MAX(id), 0), ? FROM events WHERE project_id=?`, oldID, oldID, renamedAt, oldID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE projects SET id=? WHERE id=?`, newID, oldID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	for _, t := range projectTables {
+		if t.where != "project_id=?" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE `+t.name+` SET project_id=? WHERE project_id=?`, newID, oldID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE attestations SET entity_id=? WHERE project_id=? AND entity_kind='project' AND entity_id=?`, newID, newID, oldID); err != nil {
+		return err
+	}
+	for _, table := range []string{"project_configs", "project_config_versions"} {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET config_json=json_set(config_json, '$.Project.ID', ?) WHERE project_id=?`, newID, newID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveProjectAlias returns the current id of a renamed project. An id
+// taken again by another project resolves to that project, not the alias.
+func (r Repo) ResolveProjectAlias(ctx context.Context, alias string) (string, error) {
+	var id string
+	err := r.DB.QueryRowContext(ctx, `SELECT project_id FROM project_aliases WHERE alias=? AND NOT EXISTS (SELECT 1 FROM projects WHERE id=?) ORDER BY id DESC LIMIT 1`, alias, alias).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return id, err
+}
+
+// ProjectAliasesTx lists a project's former ids, oldest first.
+func (r Repo) ProjectAliasesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ProjectAlias, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT alias, project_id, last_event_id, renamed_at FROM project_aliases WHERE project_id=? ORDER BY last_event_id, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ProjectAlias
+	for rows.Next() {
+		var a domain.ProjectAlias
+		if err := rows.Scan(&a.Alias, &a.ProjectID, &a.LastEventID, &a.RenamedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+)
+
+// projectTables are the tables holding a project's rows, with the condition
+// selecting them. Tables without a project_id column are reached through
+// their parent.
+var projectTables = []struct{ name, where string }{
+	{"projects", "id=?"},
+	{"project_aliases", "project_id=?"},
+	{"project_configs", "project_id=?"},
+	{"project_config_versions", "project_id=?"},
+	{"roles", "project_id=?"},
+	{"role_permissions", "role_id IN (SELECT id FROM roles WHERE project_id=?)"},
+	{"actor_roles", "project_id=?"},
+	{"actor_missions", "project_id=?"},
+	{"calendar_feeds", "project_id=?"},
+	{"attestation_authorities", "project_id=?"},
+	{"iterations", "project_id=?"},
+	{"milestones", "project_id=?"},
+	{"milestone_iterations", "milestone_id IN (SELECT id FROM milestones WHERE project_id=?)"},
+	{"milestone_tasks", "milestone_id IN (SELECT id FROM milestones WHERE project_id=?)"},
+	{"tasks", "project_id=?"},
+	{"task_deps", "task_id IN (SELECT id FROM tasks WHERE project_id=?)"},
+	{"task_relations", "project_id=?"},
+	{"leases", "task_id IN (SELECT id FROM tasks WHERE project_id=?)"},
+	{"task_time_entries", "project_id=?"},
+	{"validations", "project_id=?"},
+	{"decisions", "project_id=?"},
+	{"attestations", "project_id=?"},
+	{"attestation_evidence", "project_id=?"},
+	{"external_refs", "project_id=?"},
+	{"force_requests", "project_id=?"},
+	{"releases", "project_id=?"},
+	{"webhook_deliveries", "project_id=?"},
+	{"hook_deliveries", "project_id=?"},
+	{"projection_state", "project_id=?"},
+	{"projection_task_status", "project_id=?"},
+	{"id_sequences", "project_id=?"},
+	{"sync_remotes", "project_id=?"},
+	{"sync_base", "project_id=?"},
+	{"offline_queue", "project_id=?"},
+	{"events", "project_id=?"},
+	{"event_compactions", "project_id=?"},
+	{"read_audit", "project_id=?"},
+	{"outbox", "project_id=?"},
+}
+
+// ProjectTables lists the tables holding a project's rows.
+func (r Repo) ProjectTables() []string {
+	names := make([]string, len(projectTables))
+	for i, t := range projectTables {
+		names[i] = t.name
+	}
+	return names
+}
+
+// ProjectRowCountsTx counts a project's rows per table, leaving out empty
+// tables.
+func (r Repo) ProjectRowCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, t := range projectTables {
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM `+t.name+` WHERE `+t.where, projectID).Scan(&n); err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			counts[t.name] = n
+		}
+	}
+	return counts, nil
+}
+
+// ProjectRowsTx returns a project's rows of one of ProjectTables as column
+// maps, at most limit rows after offset.
+func (r Repo) ProjectRowsTx(ctx context.Context, tx *sql.Tx, table, projectID string, offset, limit int) ([]map[string]any, error) {
+	where := ""
+	for _, t := range projectTables {
+		if t.name == table {
+			where = t.where
+		}
+	}
+	if where == "" {
+		return nil, ErrNotFound
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT * FROM `+table+` WHERE `+where+` ORDER BY rowid LIMIT ? OFFSET ?`, projectID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var res []map[string]any
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[col] = values[i]
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
+
+// EvidenceStorageKeysTx returns the blob keys of a project's evidence files.
+func (r Repo) EvidenceStorageKeysTx(ctx context.Context, tx *sql.Tx, projectID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT storage_key FROM attestation_evidence WHERE project_id=?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteProjectTx deletes a project with all its rows: foreign keys cascade
+// from the project row, and the tables keyed by project id alone, such as
+// events, are cleared first.
+func (r Repo) DeleteProjectTx(ctx context.Context, tx *sql.Tx, id string) error {
+	for _, table := range []string{"events", "event_compactions", "outbox"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE project_id=?`, id); err != nil {
+			return err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
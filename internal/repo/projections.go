@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// ProjectionStatesTx returns the projections that have read a project's
+// events, by name, with the number of events recorded since.
+func (r Repo) ProjectionStatesTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]domain.ProjectionState, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT ps.name, ps.last_event_id, ps.rebuilt_at,
+  (SELECT count(*) FROM events ev WHERE ev.project_id=ps.project_id AND ev.id>ps.last_event_id)
+FROM projection_state ps WHERE ps.project_id=?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]domain.ProjectionState{}
+	for rows.Next() {
+		st := domain.ProjectionState{ProjectID: projectID}
+		if err := rows.Scan(&st.Name, &st.LastEventID, &st.RebuiltAt, &st.Lag); err != nil {
+			return nil, err
+		}
+		res[st.Name] = st
+	}
+	return res, rows.Err()
+}
+
+// SetProjectionStateTx records the last event a projection has read.
+func (r Repo) SetProjectionStateTx(ctx context.Context, tx *sql.Tx, name, projectID string, lastEventID int64, rebuiltAt string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO projection_state(name, project_id, last_event_id, rebuilt_at) VALUES (?,?,?,?)
+ON CONFLICT(name, project_id) DO UPDATE SET last_event_id=excluded.last_event_id, rebuilt_at=excluded.rebuilt_at`, name, projectID, lastEventID, rebuiltAt)
+	return err
+}
+
+// EventCompactedTx reports whether events of the project were compacted.
+func (r Repo) EventCompactedTx(ctx context.Context, tx *sql.Tx, projectID string) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT count(*) FROM event_compactions WHERE project_id=?`, projectID).Scan(&n)
+	return n > 0, err
+}
+
+// ResetTaskStatusProjectionTx clears the task_status projection of a project.
+func (r Repo) ResetTaskStatusProjectionTx(ctx context.Context, tx *sql.Tx, projectID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM projection_task_status WHERE project_id=?`, projectID)
+	return err
+}
+
+// SetProjectedTaskStatusTx records a task's status in the task_status
+// projection.
+func (r Repo) SetProjectedTaskStatusTx(ctx context.Context, tx *sql.Tx, projectID, taskID, status string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO projection_task_status(project_id, task_id, status) VALUES (?,?,?)
+ON CONFLICT(project_id, task_id) DO UPDATE SET status=excluded.status`, projectID, taskID, status)
+	return err
+}
+
+// ProjectedTaskStatusCountsTx counts a project's tasks per status as the
+// task_status projection has them.
+func (r Repo) ProjectedTaskStatusCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT status, count(*) FROM projection_task_status WHERE project_id=? GROUP BY status`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		res[status] = n
+	}
+	return res, rows.Err()
+}
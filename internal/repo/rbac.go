@@ -3,6 +3,9 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"sort"
+
+	"workline/internal/domain"
 )
 
 func (r Repo) EnsureActor(ctx context.Context, tx *sql.Tx, actorID string, now string) error {
@@ -28,21 +31,273 @@ func (r Repo) InsertRole(ctx context.Context, tx *sql.Tx, id, desc string) error
 	return err
 }
 
-func (r Repo) InsertPermission(ctx context.Context, tx *sql.Tx, id, desc string) error {
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO permissions(id, description) VALUES (?,?)`, id, desc)
+// InsertProjectRole adds a custom role owned by the project.
+func (r Repo) InsertProjectRole(ctx context.Context, tx *sql.Tx, projectID, id, desc string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO roles(id, description, project_id) VALUES (?,?,?)`, id, desc, projectID)
 	return err
 }
 
-func (r Repo) AddRolePermission(ctx context.Context, tx *sql.Tx, roleID, permID string) error {
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO role_permissions(role_id, permission_id) VALUES (?,?)`, roleID, permID)
+// InsertRoles adds the roles in descs (id to description) that do not exist yet.
+func (r Repo) InsertRoles(ctx context.Context, tx *sql.Tx, descs map[string]string) error {
+	return insertRows(ctx, tx, `INSERT OR IGNORE INTO roles(id, description) VALUES `, sortedPairs(descs))
+}
+
+// InsertPermissions adds the permissions in descs (id to description) that do
+// not exist yet.
+func (r Repo) InsertPermissions(ctx context.Context, tx *sql.Tx, descs map[string]string) error {
+	return insertRows(ctx, tx, `INSERT OR IGNORE INTO permissions(id, description) VALUES `, sortedPairs(descs))
+}
+
+// sortedPairs turns m into key/value rows in key order, for stable inserts.
+func sortedPairs(m map[string]string) [][]any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rows := make([][]any, len(keys))
+	for i, k := range keys {
+		rows[i] = []any{k, m[k]}
+	}
+	return rows
+}
+
+func (r Repo) RoleExistsTx(ctx context.Context, tx *sql.Tx, roleID string) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM roles WHERE id=?`, roleID).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r Repo) PermissionExistsTx(ctx context.Context, tx *sql.Tx, permID string) (bool, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM permissions WHERE id=?`, permID).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r Repo) GetRoleTx(ctx context.Context, tx *sql.Tx, roleID string) (domain.Role, error) {
+	var role domain.Role
+	var desc, projectID sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT id, description, project_id FROM roles WHERE id=?`, roleID).Scan(&role.ID, &desc, &projectID)
+	if err == sql.ErrNoRows {
+		return role, ErrNotFound
+	}
+	if err != nil {
+		return role, err
+	}
+	role.Description = desc.String
+	role.ProjectID = projectID.String
+	perms, err := r.rolePermissions(ctx, tx, roleID)
+	if err != nil {
+		return role, err
+	}
+	sort.Strings(perms)
+	role.Permissions = perms
+	return role, nil
+}
+
+// ListRolesTx returns the shared roles and the project's custom roles.
+func (r Repo) ListRolesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Role, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, COALESCE(description,''), COALESCE(project_id,'') FROM roles
+WHERE project_id IS NULL OR project_id=? ORDER BY id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Role
+	for rows.Next() {
+		var role domain.Role
+		if err := rows.Scan(&role.ID, &role.Description, &role.ProjectID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		res = append(res, role)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		perms, err := r.rolePermissions(ctx, tx, res[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(perms)
+		res[i].Permissions = perms
+	}
+	return res, nil
+}
+
+func (r Repo) ListPermissionsTx(ctx context.Context, tx *sql.Tx) ([]domain.Permission, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, COALESCE(description,'') FROM permissions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.ID, &p.Description); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// RoleAttestationKindsTx returns the attestation kinds the role may issue in the project.
+func (r Repo) RoleAttestationKindsTx(ctx context.Context, tx *sql.Tx, projectID, roleID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT kind FROM attestation_authorities WHERE project_id=? AND role_id=? ORDER BY kind`, projectID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var kind string
+		if err := rows.Scan(&kind); err != nil {
+			return nil, err
+		}
+		res = append(res, kind)
+	}
+	return res, rows.Err()
+}
+
+// RoleMembersTx returns actors holding the role in the project, directly or via an org grant.
+// Org owners are reported as holders of the owner role. Expired project grants are left out.
+func (r Repo) RoleMembersTx(ctx context.Context, tx *sql.Tx, projectID, roleID string) ([]domain.RoleMember, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT actor_id, 'project', expires_at FROM actor_roles
+WHERE project_id=?1 AND role_id=?2 AND (expires_at IS NULL OR expires_at > strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+UNION
+SELECT oar.actor_id, 'org', NULL FROM org_actor_roles oar
+JOIN projects p ON p.org_id=oar.org_id
+WHERE p.id=?1 AND oar.role_id=?2
+UNION
+SELECT o.actor_id, 'org', NULL FROM org_roles o
+JOIN projects p ON p.org_id=o.org_id
+WHERE p.id=?1 AND o.role='owner' AND ?2='owner'
+ORDER BY 1, 2`, projectID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.RoleMember
+	for rows.Next() {
+		var m domain.RoleMember
+		var expiresAt sql.NullString
+		if err := rows.Scan(&m.ActorID, &m.Scope, &expiresAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			m.ExpiresAt = &expiresAt.String
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// ProjectRoleGrantsTx returns the roles granted directly in the project,
+// keyed by actor, sorted; org-level grants are not included.
+func (r Repo) ProjectRoleGrantsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string][]string, error) {
+	return pairsByKey(ctx, tx, `SELECT actor_id, role_id FROM actor_roles WHERE project_id=? ORDER BY actor_id, role_id`, projectID)
+}
+
+// AttestationAuthoritiesTx returns the roles allowed to issue each
+// attestation kind in the project, keyed by kind, sorted.
+func (r Repo) AttestationAuthoritiesTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string][]string, error) {
+	return pairsByKey(ctx, tx, `SELECT kind, role_id FROM attestation_authorities WHERE project_id=? ORDER BY kind, role_id`, projectID)
+}
+
+func pairsByKey(ctx context.Context, tx *sql.Tx, query string, args ...any) (map[string][]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string][]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		res[key] = append(res[key], value)
+	}
+	return res, rows.Err()
+}
+
+func (r Repo) UpdateRoleDescription(ctx context.Context, tx *sql.Tx, roleID, desc string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE roles SET description=? WHERE id=?`, desc, roleID)
 	return err
 }
 
+func (r Repo) ClearRolePermissions(ctx context.Context, tx *sql.Tx, roleID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id=?`, roleID)
+	return err
+}
+
+// DeleteRole removes a project's custom role with its permissions and the
+// grants and attestation authorities referencing it in the project and the
+// project's org.
+func (r Repo) DeleteRole(ctx context.Context, tx *sql.Tx, projectID, roleID string) error {
+	for _, stmt := range []string{
+		`DELETE FROM actor_roles WHERE project_id=?1 AND role_id=?2`,
+		`DELETE FROM org_actor_roles WHERE org_id=(SELECT org_id FROM projects WHERE id=?1) AND role_id=?2`,
+		`DELETE FROM attestation_authorities WHERE project_id=?1 AND role_id=?2`,
+		`DELETE FROM role_permissions WHERE role_id=?2 AND EXISTS (SELECT 1 FROM roles WHERE id=?2 AND project_id=?1)`,
+		`DELETE FROM roles WHERE id=?2 AND project_id=?1`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, projectID, roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddRolePermissions grants every permission in permIDs to roleID.
+func (r Repo) AddRolePermissions(ctx context.Context, tx *sql.Tx, roleID string, permIDs []string) error {
+	rows := make([][]any, len(permIDs))
+	for i, p := range permIDs {
+		rows[i] = []any{roleID, p}
+	}
+	return insertRows(ctx, tx, `INSERT OR IGNORE INTO role_permissions(role_id, permission_id) VALUES `, rows)
+}
+
 func (r Repo) AssignRole(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string) error {
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO actor_roles(project_id, actor_id, role_id) VALUES (?,?,?)`, projectID, actorID, roleID)
+	return r.AssignRoleUntil(ctx, tx, projectID, actorID, roleID, nil)
+}
+
+// AssignRoleUntil grants a role until expiresAt, or for good when nil.
+// Granting a role the actor already holds replaces its expiry.
+func (r Repo) AssignRoleUntil(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string, expiresAt *string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO actor_roles(project_id, actor_id, role_id, expires_at) VALUES (?,?,?,?)
+ON CONFLICT(project_id, actor_id, role_id) DO UPDATE SET expires_at=excluded.expires_at`, projectID, actorID, roleID, nullableStringPtr(expiresAt))
 	return err
 }
 
+// ExpiredRoleGrantsTx returns the project's time-boxed grants whose
+// expires_at is at or before now.
+func (r Repo) ExpiredRoleGrantsTx(ctx context.Context, tx *sql.Tx, projectID, now string) ([]domain.RoleGrant, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT actor_id, role_id, expires_at FROM actor_roles
+WHERE project_id=? AND expires_at IS NOT NULL AND expires_at<=? ORDER BY expires_at, actor_id, role_id`, projectID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.RoleGrant
+	for rows.Next() {
+		var g domain.RoleGrant
+		if err := rows.Scan(&g.ActorID, &g.RoleID, &g.ExpiresAt); err != nil {
+			return nil, err
+		}
+		res = append(res, g)
+	}
+	return res, rows.Err()
+}
+
 func (r Repo) RevokeRole(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string) error {
 	_, err := tx.ExecContext(ctx, `DELETE FROM actor_roles WHERE project_id=? AND actor_id=? AND role_id=?`, projectID, actorID, roleID)
 	return err
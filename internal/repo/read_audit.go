@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"workline/internal/domain"
+)
+
+// ReadAuditFilters narrows ReadAuditTx; empty fields match everything.
+type ReadAuditFilters struct {
+	ProjectID string
+	ActorID   string
+	Resource  string
+	// Since and Until bound ts, inclusive and exclusive.
+	Since string
+	Until string
+	// Cursor returns entries with a smaller id, for paging newest first.
+	Cursor int64
+	Limit  int
+}
+
+func (r Repo) InsertReadAudit(ctx context.Context, entry domain.ReadAuditEntry) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO read_audit(project_id, ts, actor_id, resource, target) VALUES (?,?,?,?,?)`,
+		entry.ProjectID, entry.TS, entry.ActorID, entry.Resource, entry.Target)
+	return err
+}
+
+// ReadAuditTx returns recorded reads newest first.
+func (r Repo) ReadAuditTx(ctx context.Context, tx *sql.Tx, f ReadAuditFilters) ([]domain.ReadAuditEntry, error) {
+	clauses := []string{"project_id=?"}
+	args := []any{f.ProjectID}
+	if f.ActorID != "" {
+		clauses = append(clauses, "actor_id=?")
+		args = append(args, f.ActorID)
+	}
+	if f.Resource != "" {
+		clauses = append(clauses, "resource=?")
+		args = append(args, f.Resource)
+	}
+	if f.Since != "" {
+		clauses = append(clauses, "ts>=?")
+		args = append(args, f.Since)
+	}
+	if f.Until != "" {
+		clauses = append(clauses, "ts<?")
+		args = append(args, f.Until)
+	}
+	if f.Cursor > 0 {
+		clauses = append(clauses, "id<?")
+		args = append(args, f.Cursor)
+	}
+	query := `SELECT id, project_id, ts, actor_id, resource, target FROM read_audit WHERE ` + strings.Join(clauses, " AND ") + ` ORDER BY id DESC`
+	if f.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ReadAuditEntry
+	for rows.Next() {
+		var entry domain.ReadAuditEntry
+		if err := rows.Scan(&entry.ID, &entry.ProjectID, &entry.TS, &entry.ActorID, &entry.Resource, &entry.Target); err != nil {
+			return nil, err
+		}
+		res = append(res, entry)
+	}
+	return res, rows.Err()
+}
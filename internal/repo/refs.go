@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"workline/internal/domain"
+)
+
+// refTables maps entity kinds to their table and title column.
+var refTables = map[string][2]string{
+	"task":      {"tasks", "title"},
+	"iteration": {"iterations", "goal"},
+	"decision":  {"decisions", "title"},
+}
+
+// FindEntityRefs returns a project's tasks, iterations or decisions whose id
+// is ref, starts with ref, or whose title contains ref ignoring case; exact
+// matches first, then id prefixes, then titles.
+func (r Repo) FindEntityRefs(ctx context.Context, kind, projectID, ref string, limit int) ([]domain.EntityRef, error) {
+	t, ok := refTables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity kind %s", kind)
+	}
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, `+t[1]+`,
+  CASE WHEN id=? THEN 'id' WHEN substr(id, 1, length(?))=? THEN 'prefix' ELSE 'title' END AS match
+FROM `+t[0]+`
+WHERE project_id=? AND (substr(id, 1, length(?))=? OR instr(lower(`+t[1]+`), lower(?)) > 0)
+ORDER BY CASE match WHEN 'id' THEN 0 WHEN 'prefix' THEN 1 ELSE 2 END, created_at, id
+LIMIT ?`, ref, ref, ref, projectID, ref, ref, ref, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.EntityRef
+	for rows.Next() {
+		var ref domain.EntityRef
+		if err := rows.Scan(&ref.ID, &ref.Title, &ref.Match); err != nil {
+			return nil, err
+		}
+		res = append(res, ref)
+	}
+	return res, rows.Err()
+}
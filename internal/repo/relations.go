@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// AddTaskRelationTx records a relation, reporting false when it already
+// existed.
+func (r Repo) AddTaskRelationTx(ctx context.Context, tx *sql.Tx, projectID string, rel domain.TaskRelation) (bool, error) {
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO task_relations(project_id, task_id, type, related_task_id, created_by, created_at) VALUES (?,?,?,?,?,?)`,
+		projectID, rel.TaskID, rel.Type, rel.RelatedTaskID, rel.CreatedBy, rel.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RemoveTaskRelationTx deletes a relation, reporting false when there was none.
+func (r Repo) RemoveTaskRelationTx(ctx context.Context, tx *sql.Tx, taskID, relType, relatedTaskID string) (bool, error) {
+	res, err := tx.ExecContext(ctx, `DELETE FROM task_relations WHERE task_id=? AND type=? AND related_task_id=?`, taskID, relType, relatedTaskID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// TaskRelationsTx returns the relations a task takes part in, from either
+// side, oldest first.
+func (r Repo) TaskRelationsTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.TaskRelation, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT task_id, type, related_task_id, created_by, created_at FROM task_relations
+WHERE task_id=? OR related_task_id=? ORDER BY created_at, task_id, type, related_task_id`, taskID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.TaskRelation
+	for rows.Next() {
+		var rel domain.TaskRelation
+		if err := rows.Scan(&rel.TaskID, &rel.Type, &rel.RelatedTaskID, &rel.CreatedBy, &rel.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, rel)
+	}
+	return res, rows.Err()
+}
+
+// RelationsForTasks loads the relations many tasks are the subject of, one
+// query per batchSize ids, keyed by task id. Tasks without relations are
+// absent.
+func (r Repo) RelationsForTasks(ctx context.Context, ids []string) (map[string][]domain.TaskRelation, error) {
+	return relationsFor(ctx, r.DB, ids)
+}
+
+func (r Repo) RelationsForTasksTx(ctx context.Context, tx *sql.Tx, ids []string) (map[string][]domain.TaskRelation, error) {
+	return relationsFor(ctx, tx, ids)
+}
+
+func relationsFor(ctx context.Context, q queryer, ids []string) (map[string][]domain.TaskRelation, error) {
+	rels := map[string][]domain.TaskRelation{}
+	for start := 0; start < len(ids); start += batchSize {
+		chunk := ids[start:min(start+batchSize, len(ids))]
+		rows, err := q.QueryContext(ctx, `SELECT task_id, type, related_task_id, created_by, created_at FROM task_relations
+WHERE task_id IN (`+placeholders(len(chunk))+`) ORDER BY created_at, type, related_task_id`, appendStrings(nil, chunk)...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var rel domain.TaskRelation
+			if err := rows.Scan(&rel.TaskID, &rel.Type, &rel.RelatedTaskID, &rel.CreatedBy, &rel.CreatedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			rels[rel.TaskID] = append(rels[rel.TaskID], rel)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rels, nil
+}
+
+// BlockersTx returns the ids of the tasks recorded as blocking taskID.
+func (r Repo) BlockersTx(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT task_id FROM task_relations WHERE related_task_id=? AND type='blocks' ORDER BY task_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
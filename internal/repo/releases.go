@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+const releaseColumns = `id, project_id, iteration_id, name, version, checksum, url, created_by, created_at`
+
+func (r Repo) InsertReleaseTx(ctx context.Context, tx *sql.Tx, rel domain.Release) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO releases(`+releaseColumns+`) VALUES (?,?,?,?,?,?,?,?,?)`,
+		rel.ID, rel.ProjectID, rel.IterationID, rel.Name, rel.Version, nullable(rel.Checksum), nullable(rel.URL), rel.CreatedBy, rel.CreatedAt)
+	return err
+}
+
+// GetReleaseByVersionTx returns the project's release of a name and version.
+func (r Repo) GetReleaseByVersionTx(ctx context.Context, tx *sql.Tx, projectID, name, version string) (domain.Release, error) {
+	rel, err := scanRelease(tx.QueryRowContext(ctx, `SELECT `+releaseColumns+` FROM releases WHERE project_id=? AND name=? AND version=?`, projectID, name, version))
+	if err == sql.ErrNoRows {
+		return rel, ErrNotFound
+	}
+	return rel, err
+}
+
+// ListReleasesTx returns a project's releases, newest first, only those of
+// one iteration when iterationID is set.
+func (r Repo) ListReleasesTx(ctx context.Context, tx *sql.Tx, projectID, iterationID string) ([]domain.Release, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+releaseColumns+` FROM releases WHERE project_id=? AND (?='' OR iteration_id=?) ORDER BY created_at DESC, rowid DESC`, projectID, iterationID, iterationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.Release{}
+	for rows.Next() {
+		rel, err := scanRelease(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rel)
+	}
+	return res, rows.Err()
+}
+
+func scanRelease(row interface{ Scan(...any) error }) (domain.Release, error) {
+	var rel domain.Release
+	var checksum, url sql.NullString
+	if err := row.Scan(&rel.ID, &rel.ProjectID, &rel.IterationID, &rel.Name, &rel.Version, &checksum, &url, &rel.CreatedBy, &rel.CreatedAt); err != nil {
+		return rel, err
+	}
+	rel.Checksum, rel.URL = checksum.String, url.String
+	return rel, nil
+}
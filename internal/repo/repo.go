@@ -6,15 +6,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"workline/internal/config"
 	"workline/internal/domain"
+	"workline/internal/fieldcrypt"
 )
 
 type Repo struct {
 	DB *sql.DB
+	// Fields seals attestation payloads, work outcomes and decision context
+	// at rest; nil stores them in the clear.
+	Fields *fieldcrypt.Keyring
 }
 
 var ErrNotFound = errors.New("not found")
@@ -65,8 +72,9 @@ func (r Repo) SingleProject(ctx context.Context) (domain.Project, error) {
 	return projects[0], nil
 }
 
-func (r Repo) ListProjects(ctx context.Context) ([]domain.Project, error) {
-	rows, err := r.DB.QueryContext(ctx, `SELECT id,org_id,kind,status,COALESCE(description,'') AS description,created_at FROM projects ORDER BY created_at DESC`)
+// ListProjects lists projects, archived ones only with includeArchived.
+func (r Repo) ListProjects(ctx context.Context, includeArchived bool) ([]domain.Project, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id,org_id,kind,status,COALESCE(description,'') AS description,created_at FROM projects WHERE ? OR status<>'archived' ORDER BY created_at DESC`, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -83,17 +91,27 @@ func (r Repo) ListProjects(ctx context.Context) ([]domain.Project, error) {
 }
 
 func (r Repo) InsertIteration(ctx context.Context, it domain.Iteration) error {
-	_, err := r.DB.ExecContext(ctx, `INSERT INTO iterations(id,project_id,goal,status,created_at) VALUES (?,?,?,?,?)`,
-		it.ID, it.ProjectID, it.Goal, it.Status, it.CreatedAt)
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO iterations(id,project_id,goal,status,capacity,created_at) VALUES (?,?,?,?,?,?)`,
+		it.ID, it.ProjectID, it.Goal, it.Status, nullableFloatPtr(it.Capacity), it.CreatedAt)
 	return err
 }
 
 func (r Repo) InsertIterationTx(ctx context.Context, tx *sql.Tx, it domain.Iteration) error {
-	_, err := tx.ExecContext(ctx, `INSERT INTO iterations(id,project_id,goal,status,created_at) VALUES (?,?,?,?,?)`,
-		it.ID, it.ProjectID, it.Goal, it.Status, it.CreatedAt)
+	_, err := tx.ExecContext(ctx, `INSERT INTO iterations(id,project_id,goal,status,capacity,created_at) VALUES (?,?,?,?,?,?)`,
+		it.ID, it.ProjectID, it.Goal, it.Status, nullableFloatPtr(it.Capacity), it.CreatedAt)
 	return err
 }
 
+// ProjectStatusTx returns a project's status.
+func (r Repo) ProjectStatusTx(ctx context.Context, tx *sql.Tx, id string) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM projects WHERE id=?`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return status, err
+}
+
 func (r Repo) UpdateProject(ctx context.Context, id, status string, description *string) error {
 	var (
 		fields []string
@@ -122,19 +140,8 @@ func (r Repo) UpdateProject(ctx context.Context, id, status string, description
 	return nil
 }
 
-func (r Repo) DeleteProject(ctx context.Context, id string) error {
-	res, err := r.DB.ExecContext(ctx, `DELETE FROM projects WHERE id=?`, id)
-	if err != nil {
-		return err
-	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		return ErrNotFound
-	}
-	return nil
-}
-
 func (r Repo) UpsertProjectConfig(ctx context.Context, projectID string, cfg *config.Config) error {
-	if err := upsertProjectConfig(ctx, r.DB, nil, projectID, cfg); err != nil {
+	if err := upsertProjectConfig(ctx, r.DB, nil, projectID, "", cfg); err != nil {
 		return err
 	}
 	if cfg != nil && cfg.Project.ActorMissions != nil {
@@ -144,7 +151,12 @@ func (r Repo) UpsertProjectConfig(ctx context.Context, projectID string, cfg *co
 }
 
 func (r Repo) UpsertProjectConfigTx(ctx context.Context, tx *sql.Tx, projectID string, cfg *config.Config) error {
-	if err := upsertProjectConfig(ctx, nil, tx, projectID, cfg); err != nil {
+	return r.SaveProjectConfigTx(ctx, tx, projectID, "", cfg)
+}
+
+// SaveProjectConfigTx stores the config and records a new version attributed to actorID.
+func (r Repo) SaveProjectConfigTx(ctx context.Context, tx *sql.Tx, projectID, actorID string, cfg *config.Config) error {
+	if err := upsertProjectConfig(ctx, nil, tx, projectID, actorID, cfg); err != nil {
 		return err
 	}
 	if cfg != nil && cfg.Project.ActorMissions != nil {
@@ -153,7 +165,7 @@ func (r Repo) UpsertProjectConfigTx(ctx context.Context, tx *sql.Tx, projectID s
 	return nil
 }
 
-func upsertProjectConfig(ctx context.Context, db *sql.DB, tx *sql.Tx, projectID string, cfg *config.Config) error {
+func upsertProjectConfig(ctx context.Context, db *sql.DB, tx *sql.Tx, projectID, actorID string, cfg *config.Config) error {
 	if cfg == nil {
 		return fmt.Errorf("config nil")
 	}
@@ -174,6 +186,14 @@ func upsertProjectConfig(ctx context.Context, db *sql.DB, tx *sql.Tx, projectID
 	}
 	_, err = exec(`INSERT INTO project_configs(project_id,config_json,created_at,updated_at) VALUES (?,?,?,?)
 ON CONFLICT(project_id) DO UPDATE SET config_json=excluded.config_json, updated_at=excluded.updated_at`, projectID, string(payload), now, now)
+	if err != nil {
+		return err
+	}
+	// A new version is recorded only when the config differs from the latest one.
+	_, err = exec(`INSERT INTO project_config_versions(project_id,version,config_json,actor_id,created_at)
+SELECT ?, COALESCE(MAX(version),0)+1, ?, ?, ? FROM project_config_versions WHERE project_id=?
+HAVING COALESCE((SELECT config_json FROM project_config_versions WHERE project_id=? ORDER BY version DESC LIMIT 1),'') <> ?`,
+		projectID, string(payload), nullable(actorID), now, projectID, projectID, string(payload))
 	return err
 }
 
@@ -208,7 +228,7 @@ func (r Repo) ListIterationsWithCursor(ctx context.Context, projectID string, li
 		args = append(args, cursorCreatedAt, cursorCreatedAt, cursorID)
 	}
 	where := "WHERE " + strings.Join(clauses, " AND ")
-	query := `SELECT id,project_id,goal,status,created_at FROM iterations ` + where + ` ORDER BY created_at DESC, id DESC`
+	query := `SELECT id,project_id,goal,status,capacity,created_at FROM iterations ` + where + ` ORDER BY created_at DESC, id DESC`
 	if limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, limit)
@@ -221,24 +241,76 @@ func (r Repo) ListIterationsWithCursor(ctx context.Context, projectID string, li
 	var res []domain.Iteration
 	for rows.Next() {
 		var it domain.Iteration
-		if err := rows.Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &it.CreatedAt); err != nil {
+		var capacity sql.NullFloat64
+		if err := rows.Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &capacity, &it.CreatedAt); err != nil {
 			return nil, err
 		}
+		if capacity.Valid {
+			it.Capacity = &capacity.Float64
+		}
 		res = append(res, it)
 	}
 	return res, nil
 }
 
-func (r Repo) GetIteration(ctx context.Context, id string) (domain.Iteration, error) {
+// ListIterationsTx returns a project's iterations, oldest first.
+func (r Repo) ListIterationsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Iteration, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,project_id,goal,status,capacity,created_at FROM iterations WHERE project_id=? ORDER BY created_at, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Iteration
+	for rows.Next() {
+		var it domain.Iteration
+		var capacity sql.NullFloat64
+		if err := rows.Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &capacity, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		if capacity.Valid {
+			it.Capacity = &capacity.Float64
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
+
+func scanIteration(row *sql.Row) (domain.Iteration, error) {
 	var it domain.Iteration
-	err := r.DB.QueryRowContext(ctx, `SELECT id,project_id,goal,status,created_at FROM iterations WHERE id=?`, id).
-		Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &it.CreatedAt)
+	var capacity sql.NullFloat64
+	err := row.Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &capacity, &it.CreatedAt)
 	if err == sql.ErrNoRows {
 		return it, ErrNotFound
 	}
+	if capacity.Valid {
+		it.Capacity = &capacity.Float64
+	}
 	return it, err
 }
 
+func (r Repo) GetIteration(ctx context.Context, id string) (domain.Iteration, error) {
+	return scanIteration(r.DB.QueryRowContext(ctx, `SELECT id,project_id,goal,status,capacity,created_at FROM iterations WHERE id=?`, id))
+}
+
+func (r Repo) GetIterationTx(ctx context.Context, tx *sql.Tx, id string) (domain.Iteration, error) {
+	return scanIteration(tx.QueryRowContext(ctx, `SELECT id,project_id,goal,status,capacity,created_at FROM iterations WHERE id=?`, id))
+}
+
+func (r Repo) UpdateIterationCapacity(ctx context.Context, tx *sql.Tx, id string, capacity *float64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE iterations SET capacity=? WHERE id=?`, nullableFloatPtr(capacity), id)
+	return err
+}
+
+// IterationPointsTx sums task estimates in an iteration, ignoring canceled and
+// rejected tasks and the excluded task id.
+func (r Repo) IterationPointsTx(ctx context.Context, tx *sql.Tx, iterationID, excludeTaskID string) (planned, completed float64, err error) {
+	err = tx.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(estimate),0), COALESCE(SUM(CASE WHEN completed_at IS NOT NULL THEN estimate END),0)
+FROM tasks
+WHERE iteration_id=? AND id<>? AND status NOT IN ('canceled','rejected')`, iterationID, excludeTaskID).Scan(&planned, &completed)
+	return planned, completed, err
+}
+
 func (r Repo) UpdateIterationStatus(ctx context.Context, tx *sql.Tx, id, status string) error {
 	_, err := tx.ExecContext(ctx, `UPDATE iterations SET status=? WHERE id=?`, status, id)
 	return err
@@ -262,28 +334,37 @@ func nullableStringPtr(v *string) any {
 }
 
 func (r Repo) InsertTask(ctx context.Context, tx *sql.Tx, t domain.Task) error {
-	_, err := tx.ExecContext(ctx, `INSERT INTO tasks(id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at)
-VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+	workOutcomes, err := r.sealFieldPtr(workOutcomesField(t.ID), t.WorkOutcomesJSON)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO tasks(id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,estimate,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at,component,due_at)
+VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
 		t.ID, t.ProjectID, nullableStringPtr(t.IterationID), nullableStringPtr(t.ParentID), t.Type, t.Title, nullable(t.Description),
-		t.Status, nullableStringPtr(t.AssigneeID), nullableIntPtr(t.Priority), nullableStringPtr(t.WorkOutcomesJSON), nullableStringPtr(t.RequiredAttestationsJSON),
-		t.CreatedAt, t.UpdatedAt, nullableStringPtr(t.CompletedAt))
+		t.Status, nullableStringPtr(t.AssigneeID), nullableIntPtr(t.Priority), nullableFloatPtr(t.Estimate), workOutcomes, nullableStringPtr(t.RequiredAttestationsJSON),
+		t.CreatedAt, t.UpdatedAt, nullableStringPtr(t.CompletedAt), nullable(t.Component), nullableStringPtr(t.DueAt))
 	return err
 }
 
 func (r Repo) UpdateTask(ctx context.Context, tx *sql.Tx, t domain.Task) error {
-	_, err := tx.ExecContext(ctx, `UPDATE tasks SET iteration_id=?, parent_id=?, type=?, title=?, description=?, status=?, assignee_id=?, priority=?, work_outcomes_json=?, required_attestations_json=?, updated_at=?, completed_at=? WHERE id=?`,
+	workOutcomes, err := r.sealFieldPtr(workOutcomesField(t.ID), t.WorkOutcomesJSON)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE tasks SET iteration_id=?, parent_id=?, type=?, title=?, description=?, status=?, assignee_id=?, priority=?, estimate=?, work_outcomes_json=?, required_attestations_json=?, updated_at=?, completed_at=?, component=?, due_at=?, version=version+1 WHERE id=?`,
 		nullableStringPtr(t.IterationID), nullableStringPtr(t.ParentID), t.Type, t.Title, nullable(t.Description), t.Status,
-		nullableStringPtr(t.AssigneeID), nullableIntPtr(t.Priority), nullableStringPtr(t.WorkOutcomesJSON), nullableStringPtr(t.RequiredAttestationsJSON),
-		t.UpdatedAt, nullableStringPtr(t.CompletedAt), t.ID)
+		nullableStringPtr(t.AssigneeID), nullableIntPtr(t.Priority), nullableFloatPtr(t.Estimate), workOutcomes, nullableStringPtr(t.RequiredAttestationsJSON),
+		t.UpdatedAt, nullableStringPtr(t.CompletedAt), nullable(t.Component), nullableStringPtr(t.DueAt), t.ID)
 	return err
 }
 
 func (r Repo) GetTask(ctx context.Context, id string) (domain.Task, error) {
 	var t domain.Task
-	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description sql.NullString
+	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description, component, dueAt sql.NullString
 	var priority sql.NullInt64
-	err := r.DB.QueryRowContext(ctx, `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at FROM tasks WHERE id=?`, id).
-		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt)
+	var estimate sql.NullFloat64
+	err := r.DB.QueryRowContext(ctx, `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,estimate,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at,component,due_at,stale_since IS NOT NULL AND stale_since>updated_at FROM tasks WHERE id=?`, id).
+		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &estimate, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt, &component, &dueAt, &t.Stale)
 	if err == sql.ErrNoRows {
 		return t, ErrNotFound
 	}
@@ -293,6 +374,9 @@ func (r Repo) GetTask(ctx context.Context, id string) (domain.Task, error) {
 	if description.Valid {
 		t.Description = description.String
 	}
+	if component.Valid {
+		t.Component = component.String
+	}
 	if iterationID.Valid {
 		t.IterationID = &iterationID.String
 	}
@@ -306,8 +390,11 @@ func (r Repo) GetTask(ctx context.Context, id string) (domain.Task, error) {
 		p := int(priority.Int64)
 		t.Priority = &p
 	}
-	if workOutcomes.Valid {
-		t.WorkOutcomesJSON = &workOutcomes.String
+	if estimate.Valid {
+		t.Estimate = &estimate.Float64
+	}
+	if t.WorkOutcomesJSON, err = r.openField(workOutcomesField(t.ID), workOutcomes); err != nil {
+		return t, err
 	}
 	if requiredAtt.Valid {
 		t.RequiredAttestationsJSON = &requiredAtt.String
@@ -315,20 +402,34 @@ func (r Repo) GetTask(ctx context.Context, id string) (domain.Task, error) {
 	if completedAt.Valid {
 		t.CompletedAt = &completedAt.String
 	}
+	if dueAt.Valid {
+		t.DueAt = &dueAt.String
+	}
 	deps, err := r.ListTaskDependencies(ctx, t.ID)
 	if err != nil {
 		return t, err
 	}
 	t.DependsOn = deps
+	refs, err := r.ExternalRefsFor(ctx, "task", []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.ExternalRefs = refs[t.ID]
+	rels, err := r.RelationsForTasks(ctx, []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.Relations = rels[t.ID]
 	return t, err
 }
 
 func (r Repo) GetTaskTx(ctx context.Context, tx *sql.Tx, id string) (domain.Task, error) {
 	var t domain.Task
-	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description sql.NullString
+	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description, component, dueAt sql.NullString
 	var priority sql.NullInt64
-	err := tx.QueryRowContext(ctx, `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at FROM tasks WHERE id=?`, id).
-		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt)
+	var estimate sql.NullFloat64
+	err := tx.QueryRowContext(ctx, `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,estimate,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at,component,due_at,stale_since IS NOT NULL AND stale_since>updated_at FROM tasks WHERE id=?`, id).
+		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &estimate, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt, &component, &dueAt, &t.Stale)
 	if err == sql.ErrNoRows {
 		return t, ErrNotFound
 	}
@@ -338,6 +439,9 @@ func (r Repo) GetTaskTx(ctx context.Context, tx *sql.Tx, id string) (domain.Task
 	if description.Valid {
 		t.Description = description.String
 	}
+	if component.Valid {
+		t.Component = component.String
+	}
 	if iterationID.Valid {
 		t.IterationID = &iterationID.String
 	}
@@ -351,8 +455,11 @@ func (r Repo) GetTaskTx(ctx context.Context, tx *sql.Tx, id string) (domain.Task
 		p := int(priority.Int64)
 		t.Priority = &p
 	}
-	if workOutcomes.Valid {
-		t.WorkOutcomesJSON = &workOutcomes.String
+	if estimate.Valid {
+		t.Estimate = &estimate.Float64
+	}
+	if t.WorkOutcomesJSON, err = r.openField(workOutcomesField(t.ID), workOutcomes); err != nil {
+		return t, err
 	}
 	if requiredAtt.Valid {
 		t.RequiredAttestationsJSON = &requiredAtt.String
@@ -360,23 +467,154 @@ func (r Repo) GetTaskTx(ctx context.Context, tx *sql.Tx, id string) (domain.Task
 	if completedAt.Valid {
 		t.CompletedAt = &completedAt.String
 	}
+	if dueAt.Valid {
+		t.DueAt = &dueAt.String
+	}
 	deps, err := r.ListTaskDependenciesTx(ctx, tx, t.ID)
 	if err != nil {
 		return t, err
 	}
 	t.DependsOn = deps
+	refs, err := r.ExternalRefsForTx(ctx, tx, "task", []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.ExternalRefs = refs[t.ID]
+	rels, err := r.RelationsForTasksTx(ctx, tx, []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.Relations = rels[t.ID]
 	return t, nil
 }
 
 type TaskFilters struct {
-	ProjectID       string
-	Status          string
-	Iteration       string
-	Parent          string
-	AssigneeID      string
-	Limit           int
-	CursorCreatedAt string
-	CursorID        string
+	ProjectID  string
+	Status     string
+	Iteration  string
+	Parent     string
+	AssigneeID string
+	Component  string
+	// Statuses and Types match any of the listed values; Status is added to
+	// Statuses when set.
+	Statuses []string
+	Types    []string
+	// Query matches tasks whose title contains it, ignoring case.
+	Query string
+	// MissingAttestations matches tasks lacking an unexpired attestation of
+	// every listed kind.
+	MissingAttestations []string
+	// Stale matches tasks the staleness sweep marked and that were not
+	// updated since.
+	Stale bool
+	// OverdueAt matches open tasks due before this RFC3339 time.
+	OverdueAt string
+	// Created and completed bounds are RFC3339 timestamps; After is
+	// inclusive, Before exclusive.
+	CreatedAfter    string
+	CreatedBefore   string
+	CompletedAfter  string
+	CompletedBefore string
+	// Sort orders the results; the zero value is newest first.
+	Sort  TaskSort
+	Limit int
+	// CursorValue and CursorID resume after the row with that sort key and
+	// id, as returned by Sort.Key.
+	CursorValue string
+	CursorID    string
+}
+
+// TaskSort orders ListTasks by one field, with the task id as tie-breaker.
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// TaskSortFields lists the fields ListTasks can sort by.
+var TaskSortFields = []string{"created_at", "updated_at", "priority", "status", "title"}
+
+// DefaultTaskSort is the order used when none is requested.
+var DefaultTaskSort = TaskSort{Field: "created_at", Desc: true}
+
+// noPriority stands in for a missing priority so unprioritized tasks sort
+// after every prioritized one (lower is higher) in both SQL and cursors.
+const noPriority = math.MaxInt32
+
+// ParseTaskSort parses "field" or "field:asc|desc". Timestamps default to
+// newest first, the other fields to ascending.
+func ParseTaskSort(value string) (TaskSort, error) {
+	if value == "" {
+		return DefaultTaskSort, nil
+	}
+	field, dir, hasDir := strings.Cut(value, ":")
+	if !slices.Contains(TaskSortFields, field) {
+		return TaskSort{}, fmt.Errorf("invalid sort field %q: must be one of %s", field, strings.Join(TaskSortFields, ", "))
+	}
+	s := TaskSort{Field: field, Desc: field == "created_at" || field == "updated_at"}
+	if hasDir {
+		switch dir {
+		case "asc":
+			s.Desc = false
+		case "desc":
+			s.Desc = true
+		default:
+			return TaskSort{}, fmt.Errorf("invalid sort direction %q: must be asc or desc", dir)
+		}
+	}
+	return s, nil
+}
+
+func (s TaskSort) String() string {
+	if s.Field == "" {
+		return DefaultTaskSort.String()
+	}
+	if s.Desc {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+// Key returns the value of t's sort field as used in cursors.
+func (s TaskSort) Key(t domain.Task) string {
+	switch s.Field {
+	case "updated_at":
+		return t.UpdatedAt
+	case "priority":
+		if t.Priority == nil {
+			return strconv.Itoa(noPriority)
+		}
+		return strconv.Itoa(*t.Priority)
+	case "status":
+		return t.Status
+	case "title":
+		return t.Title
+	default:
+		return t.CreatedAt
+	}
+}
+
+func (s TaskSort) column() string {
+	switch s.Field {
+	case "updated_at", "status", "title":
+		return s.Field
+	case "priority":
+		return fmt.Sprintf("COALESCE(priority, %d)", noPriority)
+	default:
+		return "created_at"
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
 }
 
 type NextTaskFilters struct {
@@ -384,18 +622,42 @@ type NextTaskFilters struct {
 	IterationID       string
 	AssigneeID        string
 	IncludeUnassigned bool
+	// Types limits the pick to these task types.
+	Types []string
+	// FreeAt, when set, skips tasks another actor holds a lease on that is
+	// still valid at this RFC 3339 time.
+	FreeAt string
+	// Exclude skips these task ids.
+	Exclude []string
 }
 
 func (r Repo) ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, error) {
+	return r.listTasks(ctx, r.DB, f)
+}
+
+// ListTasksTx is ListTasks inside tx.
+func (r Repo) ListTasksTx(ctx context.Context, tx *sql.Tx, f TaskFilters) ([]domain.Task, error) {
+	return r.listTasks(ctx, tx, f)
+}
+
+func (r Repo) listTasks(ctx context.Context, q queryer, f TaskFilters) ([]domain.Task, error) {
 	var clauses []string
 	var args []any
 	if f.ProjectID != "" {
 		clauses = append(clauses, "project_id=?")
 		args = append(args, f.ProjectID)
 	}
+	statuses := f.Statuses
 	if f.Status != "" {
-		clauses = append(clauses, "status=?")
-		args = append(args, f.Status)
+		statuses = append(statuses, f.Status)
+	}
+	if len(statuses) > 0 {
+		clauses = append(clauses, "status IN ("+placeholders(len(statuses))+")")
+		args = appendStrings(args, statuses)
+	}
+	if len(f.Types) > 0 {
+		clauses = append(clauses, "type IN ("+placeholders(len(f.Types))+")")
+		args = appendStrings(args, f.Types)
 	}
 	if f.Iteration != "" {
 		clauses = append(clauses, "iteration_id=?")
@@ -409,20 +671,77 @@ func (r Repo) ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, erro
 		clauses = append(clauses, "assignee_id=?")
 		args = append(args, f.AssigneeID)
 	}
-	if f.CursorCreatedAt != "" && f.CursorID != "" {
-		clauses = append(clauses, "(created_at < ? OR (created_at = ? AND id < ?))")
-		args = append(args, f.CursorCreatedAt, f.CursorCreatedAt, f.CursorID)
+	if f.Component != "" {
+		clauses = append(clauses, "component=?")
+		args = append(args, f.Component)
+	}
+	if f.Query != "" {
+		clauses = append(clauses, `title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(f.Query)+"%")
+	}
+	if f.Stale {
+		clauses = append(clauses, "stale_since IS NOT NULL AND stale_since>updated_at")
+	}
+	for _, kind := range f.MissingAttestations {
+		clauses = append(clauses, `NOT EXISTS (SELECT 1 FROM attestations a WHERE a.entity_kind='task' AND a.entity_id=tasks.id AND a.kind=? AND a.expired_at IS NULL)`)
+		args = append(args, kind)
+	}
+	if f.OverdueAt != "" {
+		ts, err := time.Parse(time.RFC3339, f.OverdueAt)
+		if err != nil {
+			return nil, errors.New("invalid overdue_at: must be RFC3339")
+		}
+		clauses = append(clauses, "due_at < ? AND "+openTaskClause)
+		args = append(args, ts.UTC().Format(time.RFC3339))
+	}
+	for _, bound := range []struct{ name, clause, value string }{
+		{"created_after", "created_at >= ?", f.CreatedAfter},
+		{"created_before", "created_at < ?", f.CreatedBefore},
+		{"completed_after", "completed_at >= ?", f.CompletedAfter},
+		{"completed_before", "completed_at < ?", f.CompletedBefore},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		// Stored timestamps are UTC RFC3339, so bounds are normalized to
+		// compare correctly as text.
+		ts, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be RFC3339", bound.name)
+		}
+		clauses = append(clauses, bound.clause)
+		args = append(args, ts.UTC().Format(time.RFC3339))
+	}
+	sortBy := f.Sort
+	if sortBy.Field == "" {
+		sortBy = DefaultTaskSort
+	}
+	col, cmp, dir := sortBy.column(), ">", "ASC"
+	if sortBy.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	if f.CursorValue != "" && f.CursorID != "" {
+		var cursor any = f.CursorValue
+		if sortBy.Field == "priority" {
+			p, err := strconv.Atoi(f.CursorValue)
+			if err != nil {
+				return nil, errors.New("invalid cursor")
+			}
+			cursor = p
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", col, cmp, col, cmp))
+		args = append(args, cursor, cursor, f.CursorID)
 	}
 	where := ""
 	if len(clauses) > 0 {
 		where = "WHERE " + strings.Join(clauses, " AND ")
 	}
-	query := `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at FROM tasks ` + where + ` ORDER BY created_at DESC, id DESC`
+	query := `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,estimate,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at,component,due_at,stale_since IS NOT NULL AND stale_since>updated_at FROM tasks ` + where + ` ORDER BY ` + col + ` ` + dir + `, id ` + dir
 	if f.Limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, f.Limit)
 	}
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -430,14 +749,18 @@ func (r Repo) ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, erro
 	var res []domain.Task
 	for rows.Next() {
 		var t domain.Task
-		var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description sql.NullString
+		var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description, component, dueAt sql.NullString
 		var priority sql.NullInt64
-		if err := rows.Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt); err != nil {
+		var estimate sql.NullFloat64
+		if err := rows.Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &estimate, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt, &component, &dueAt, &t.Stale); err != nil {
 			return nil, err
 		}
 		if description.Valid {
 			t.Description = description.String
 		}
+		if component.Valid {
+			t.Component = component.String
+		}
 		if iterationID.Valid {
 			t.IterationID = &iterationID.String
 		}
@@ -451,8 +774,11 @@ func (r Repo) ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, erro
 			p := int(priority.Int64)
 			t.Priority = &p
 		}
-		if workOutcomes.Valid {
-			t.WorkOutcomesJSON = &workOutcomes.String
+		if estimate.Valid {
+			t.Estimate = &estimate.Float64
+		}
+		if t.WorkOutcomesJSON, err = r.openField(workOutcomesField(t.ID), workOutcomes); err != nil {
+			return nil, err
 		}
 		if requiredAtt.Valid {
 			t.RequiredAttestationsJSON = &requiredAtt.String
@@ -460,6 +786,9 @@ func (r Repo) ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, erro
 		if completedAt.Valid {
 			t.CompletedAt = &completedAt.String
 		}
+		if dueAt.Valid {
+			t.DueAt = &dueAt.String
+		}
 		res = append(res, t)
 	}
 	return res, nil
@@ -470,47 +799,13 @@ func (r Repo) NextTask(ctx context.Context, f NextTaskFilters) (domain.Task, err
 	if f.ProjectID == "" || f.IterationID == "" {
 		return t, ErrNotFound
 	}
-	clauses := []string{"project_id=?", "iteration_id=?", "status IN (?,?)"}
-	args := []any{f.ProjectID, f.IterationID, "ready", "planned"}
-	if f.AssigneeID != "" {
-		if f.IncludeUnassigned {
-			clauses = append(clauses, "(assignee_id=? OR assignee_id IS NULL)")
-			args = append(args, f.AssigneeID)
-		} else {
-			clauses = append(clauses, "assignee_id=?")
-			args = append(args, f.AssigneeID)
-		}
-	} else if !f.IncludeUnassigned {
-		clauses = append(clauses, "assignee_id IS NOT NULL")
-	}
-	clauses = append(clauses, `NOT EXISTS (
-		SELECT 1 FROM task_deps d
-		JOIN tasks dep ON dep.id=d.depends_on_task_id
-		WHERE d.task_id=tasks.id AND dep.status != 'done'
-	)`)
-	where := "WHERE " + strings.Join(clauses, " AND ")
-	order := `ORDER BY
-		CASE WHEN status = 'ready' THEN 0 ELSE 1 END,
-		CASE WHEN assignee_id = ? THEN 0 ELSE 1 END,
-		CASE WHEN priority IS NULL THEN 1 ELSE 0 END,
-		priority ASC,
-		created_at ASC,
-		id ASC`
-	if f.AssigneeID == "" {
-		order = `ORDER BY
-			CASE WHEN status = 'ready' THEN 0 ELSE 1 END,
-			CASE WHEN priority IS NULL THEN 1 ELSE 0 END,
-			priority ASC,
-			created_at ASC,
-			id ASC`
-	} else {
-		args = append(args, f.AssigneeID)
-	}
-	query := `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at FROM tasks ` + where + " " + order + " LIMIT 1"
-	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description sql.NullString
+	where, order, args := nextTaskQuery(f)
+	query := `SELECT id,project_id,iteration_id,parent_id,type,title,description,status,assignee_id,priority,estimate,work_outcomes_json,required_attestations_json,created_at,updated_at,completed_at,component,due_at,stale_since IS NOT NULL AND stale_since>updated_at FROM tasks ` + where + " " + order + " LIMIT 1"
+	var iterationID, parentID, assigneeID, workOutcomes, requiredAtt, completedAt, description, component, dueAt sql.NullString
 	var priority sql.NullInt64
+	var estimate sql.NullFloat64
 	err := r.DB.QueryRowContext(ctx, query, args...).
-		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt)
+		Scan(&t.ID, &t.ProjectID, &iterationID, &parentID, &t.Type, &t.Title, &description, &t.Status, &assigneeID, &priority, &estimate, &workOutcomes, &requiredAtt, &t.CreatedAt, &t.UpdatedAt, &completedAt, &component, &dueAt, &t.Stale)
 	if err == sql.ErrNoRows {
 		return t, ErrNotFound
 	}
@@ -520,6 +815,9 @@ func (r Repo) NextTask(ctx context.Context, f NextTaskFilters) (domain.Task, err
 	if description.Valid {
 		t.Description = description.String
 	}
+	if component.Valid {
+		t.Component = component.String
+	}
 	if iterationID.Valid {
 		t.IterationID = &iterationID.String
 	}
@@ -533,8 +831,11 @@ func (r Repo) NextTask(ctx context.Context, f NextTaskFilters) (domain.Task, err
 		p := int(priority.Int64)
 		t.Priority = &p
 	}
-	if workOutcomes.Valid {
-		t.WorkOutcomesJSON = &workOutcomes.String
+	if estimate.Valid {
+		t.Estimate = &estimate.Float64
+	}
+	if t.WorkOutcomesJSON, err = r.openField(workOutcomesField(t.ID), workOutcomes); err != nil {
+		return t, err
 	}
 	if requiredAtt.Valid {
 		t.RequiredAttestationsJSON = &requiredAtt.String
@@ -542,14 +843,99 @@ func (r Repo) NextTask(ctx context.Context, f NextTaskFilters) (domain.Task, err
 	if completedAt.Valid {
 		t.CompletedAt = &completedAt.String
 	}
+	if dueAt.Valid {
+		t.DueAt = &dueAt.String
+	}
 	deps, err := r.ListTaskDependencies(ctx, t.ID)
 	if err != nil {
 		return t, err
 	}
 	t.DependsOn = deps
+	refs, err := r.ExternalRefsFor(ctx, "task", []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.ExternalRefs = refs[t.ID]
+	rels, err := r.RelationsForTasks(ctx, []string{t.ID})
+	if err != nil {
+		return t, err
+	}
+	t.Relations = rels[t.ID]
 	return t, nil
 }
 
+// NextTaskIDTx picks the next task like NextTask, inside tx, and returns its id.
+func (r Repo) NextTaskIDTx(ctx context.Context, tx *sql.Tx, f NextTaskFilters) (string, error) {
+	if f.ProjectID == "" || f.IterationID == "" {
+		return "", ErrNotFound
+	}
+	where, order, args := nextTaskQuery(f)
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM tasks `+where+" "+order+" LIMIT 1", args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return id, err
+}
+
+// nextTaskQuery builds the WHERE and ORDER BY clauses shared by the next task
+// lookups: ready before planned, the assignee's own tasks first, then by
+// priority and age, skipping tasks with unfinished dependencies.
+func nextTaskQuery(f NextTaskFilters) (string, string, []any) {
+	clauses := []string{"project_id=?", "iteration_id=?", "status IN (?,?)"}
+	args := []any{f.ProjectID, f.IterationID, "ready", "planned"}
+	if f.AssigneeID != "" {
+		if f.IncludeUnassigned {
+			clauses = append(clauses, "(assignee_id=? OR assignee_id IS NULL)")
+			args = append(args, f.AssigneeID)
+		} else {
+			clauses = append(clauses, "assignee_id=?")
+			args = append(args, f.AssigneeID)
+		}
+	} else if !f.IncludeUnassigned {
+		clauses = append(clauses, "assignee_id IS NOT NULL")
+	}
+	if len(f.Types) > 0 {
+		clauses = append(clauses, "type IN ("+placeholders(len(f.Types))+")")
+		args = appendStrings(args, f.Types)
+	}
+	if f.FreeAt != "" {
+		clauses = append(clauses, `NOT EXISTS (
+		SELECT 1 FROM leases l WHERE l.task_id=tasks.id AND l.expires_at > ? AND l.owner_id <> ?
+	)`)
+		args = append(args, f.FreeAt, f.AssigneeID)
+	}
+	if len(f.Exclude) > 0 {
+		clauses = append(clauses, "id NOT IN ("+placeholders(len(f.Exclude))+")")
+		args = appendStrings(args, f.Exclude)
+	}
+	clauses = append(clauses, `NOT EXISTS (
+		SELECT 1 FROM task_deps d
+		JOIN tasks dep ON dep.id=d.depends_on_task_id
+		WHERE d.task_id=tasks.id AND dep.completed_at IS NULL
+	)`, `NOT EXISTS (
+		SELECT 1 FROM task_relations b
+		JOIN tasks blocker ON blocker.id=b.task_id
+		WHERE b.related_task_id=tasks.id AND b.type='blocks' AND blocker.completed_at IS NULL
+	)`)
+	where := "WHERE " + strings.Join(clauses, " AND ")
+	if f.AssigneeID == "" {
+		return where, `ORDER BY
+			CASE WHEN status = 'ready' THEN 0 ELSE 1 END,
+			CASE WHEN priority IS NULL THEN 1 ELSE 0 END,
+			priority ASC,
+			created_at ASC,
+			id ASC`, args
+	}
+	return where, `ORDER BY
+		CASE WHEN status = 'ready' THEN 0 ELSE 1 END,
+		CASE WHEN assignee_id = ? THEN 0 ELSE 1 END,
+		CASE WHEN priority IS NULL THEN 1 ELSE 0 END,
+		priority ASC,
+		created_at ASC,
+		id ASC`, append(args, f.AssigneeID)
+}
+
 func (r Repo) ListTaskDependencies(ctx context.Context, taskID string) ([]string, error) {
 	rows, err := r.DB.QueryContext(ctx, `SELECT depends_on_task_id FROM task_deps WHERE task_id=?`, taskID)
 	if err != nil {
@@ -584,18 +970,75 @@ func (r Repo) ListTaskDependenciesTx(ctx context.Context, tx *sql.Tx, taskID str
 	return deps, nil
 }
 
-func (r Repo) AddDependencies(ctx context.Context, tx *sql.Tx, taskID string, deps []string) error {
-	for _, d := range deps {
-		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO task_deps(task_id, depends_on_task_id) VALUES (?,?)`, taskID, d); err != nil {
+// batchSize bounds the ids or rows bound into one statement, well under
+// SQLite's host parameter limit.
+const batchSize = 500
+
+// insertRows runs insert (an INSERT ... VALUES prefix) for rows, batchSize
+// rows per statement.
+func insertRows(ctx context.Context, tx *sql.Tx, insert string, rows [][]any) error {
+	for start := 0; start < len(rows); start += batchSize {
+		chunk := rows[start:min(start+batchSize, len(rows))]
+		values := make([]string, len(chunk))
+		var args []any
+		for i, row := range chunk {
+			values[i] = "(" + placeholders(len(row)) + ")"
+			args = append(args, row...)
+		}
+		if _, err := tx.ExecContext(ctx, insert+strings.Join(values, ","), args...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// ListDependenciesForTasks loads the dependencies of many tasks with one query
+// per batchSize ids, keyed by task id. Tasks without dependencies are absent.
+func (r Repo) ListDependenciesForTasks(ctx context.Context, ids []string) (map[string][]string, error) {
+	return dependenciesFor(ctx, r.DB, ids)
+}
+
+func (r Repo) ListDependenciesForTasksTx(ctx context.Context, tx *sql.Tx, ids []string) (map[string][]string, error) {
+	return dependenciesFor(ctx, tx, ids)
+}
+
+func dependenciesFor(ctx context.Context, q queryer, ids []string) (map[string][]string, error) {
+	deps := map[string][]string{}
+	for start := 0; start < len(ids); start += batchSize {
+		chunk := ids[start:min(start+batchSize, len(ids))]
+		rows, err := q.QueryContext(ctx, `SELECT task_id, depends_on_task_id FROM task_deps WHERE task_id IN (`+placeholders(len(chunk))+`)`, appendStrings(nil, chunk)...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var taskID, dep string
+			if err := rows.Scan(&taskID, &dep); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			deps[taskID] = append(deps[taskID], dep)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
+
+func (r Repo) AddDependencies(ctx context.Context, tx *sql.Tx, taskID string, deps []string) error {
+	rows := make([][]any, len(deps))
+	for i, d := range deps {
+		rows[i] = []any{taskID, d}
+	}
+	return insertRows(ctx, tx, `INSERT OR IGNORE INTO task_deps(task_id, depends_on_task_id) VALUES `, rows)
+}
+
 func (r Repo) RemoveDependencies(ctx context.Context, tx *sql.Tx, taskID string, deps []string) error {
-	for _, d := range deps {
-		if _, err := tx.ExecContext(ctx, `DELETE FROM task_deps WHERE task_id=? AND depends_on_task_id=?`, taskID, d); err != nil {
+	for start := 0; start < len(deps); start += batchSize {
+		chunk := deps[start:min(start+batchSize, len(deps))]
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_deps WHERE task_id=? AND depends_on_task_id IN (`+placeholders(len(chunk))+`)`, appendStrings([]any{taskID}, chunk)...); err != nil {
 			return err
 		}
 	}
@@ -667,15 +1110,42 @@ func (r Repo) GetLease(ctx context.Context, taskID string) (domain.Lease, error)
 	return l, err
 }
 
+// ListLeases returns the leases held on the project's tasks, expired ones
+// included.
+func (r Repo) ListLeases(ctx context.Context, projectID string) ([]domain.Lease, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT l.task_id,l.owner_id,l.acquired_at,l.expires_at FROM leases l JOIN tasks t ON t.id=l.task_id WHERE t.project_id=? ORDER BY l.task_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Lease
+	for rows.Next() {
+		var l domain.Lease
+		if err := rows.Scan(&l.TaskID, &l.OwnerID, &l.AcquiredAt, &l.ExpiresAt); err != nil {
+			return nil, err
+		}
+		res = append(res, l)
+	}
+	return res, rows.Err()
+}
+
 func (r Repo) InsertAttestation(ctx context.Context, att domain.Attestation) error {
-	_, err := r.DB.ExecContext(ctx, `INSERT INTO attestations(id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json) VALUES (?,?,?,?,?,?,?,?)`,
-		att.ID, att.ProjectID, att.EntityKind, att.EntityID, att.Kind, att.ActorID, att.TS, nullable(att.PayloadJSON))
+	payload, err := r.sealField(attestationPayloadField(att.ID), att.PayloadJSON)
+	if err != nil {
+		return err
+	}
+	_, err = r.DB.ExecContext(ctx, `INSERT INTO attestations(id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json) VALUES (?,?,?,?,?,?,?,?)`,
+		att.ID, att.ProjectID, att.EntityKind, att.EntityID, att.Kind, att.ActorID, att.TS, payload)
 	return err
 }
 
 func (r Repo) InsertAttestationTx(ctx context.Context, tx *sql.Tx, att domain.Attestation) error {
-	_, err := tx.ExecContext(ctx, `INSERT INTO attestations(id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json) VALUES (?,?,?,?,?,?,?,?)`,
-		att.ID, att.ProjectID, att.EntityKind, att.EntityID, att.Kind, att.ActorID, att.TS, nullable(att.PayloadJSON))
+	payload, err := r.sealField(attestationPayloadField(att.ID), att.PayloadJSON)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO attestations(id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json) VALUES (?,?,?,?,?,?,?,?)`,
+		att.ID, att.ProjectID, att.EntityKind, att.EntityID, att.Kind, att.ActorID, att.TS, payload)
 	return err
 }
 
@@ -716,7 +1186,7 @@ func (r Repo) ListAttestations(ctx context.Context, f AttestationFilters) ([]dom
 	if len(clauses) > 0 {
 		where = "WHERE " + strings.Join(clauses, " AND ")
 	}
-	query := `SELECT id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json FROM attestations ` + where + ` ORDER BY ts DESC, id DESC`
+	query := `SELECT id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json,expired_at FROM attestations ` + where + ` ORDER BY ts DESC, id DESC`
 	if f.Limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, f.Limit)
@@ -729,12 +1199,15 @@ func (r Repo) ListAttestations(ctx context.Context, f AttestationFilters) ([]dom
 	var res []domain.Attestation
 	for rows.Next() {
 		var a domain.Attestation
-		var payload sql.NullString
-		if err := rows.Scan(&a.ID, &a.ProjectID, &a.EntityKind, &a.EntityID, &a.Kind, &a.ActorID, &a.TS, &payload); err != nil {
+		var payload, expiredAt sql.NullString
+		if err := rows.Scan(&a.ID, &a.ProjectID, &a.EntityKind, &a.EntityID, &a.Kind, &a.ActorID, &a.TS, &payload, &expiredAt); err != nil {
 			return nil, err
 		}
-		if payload.Valid {
-			a.PayloadJSON = payload.String
+		if a.PayloadJSON, err = r.Fields.Open(attestationPayloadField(a.ID), payload.String); err != nil {
+			return nil, err
+		}
+		if expiredAt.Valid {
+			a.ExpiredAt = &expiredAt.String
 		}
 		res = append(res, a)
 	}
@@ -759,11 +1232,33 @@ func (r Repo) CountTasksByStatus(ctx context.Context, projectID string) (map[str
 	return res, nil
 }
 
+// CountTasksByComponent counts a project's tasks by component and status.
+// Tasks without a component are not counted.
+func (r Repo) CountTasksByComponent(ctx context.Context, projectID string) (map[string]map[string]int, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT component, status, count(*) FROM tasks WHERE project_id=? AND component IS NOT NULL GROUP BY component, status`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]map[string]int{}
+	for rows.Next() {
+		var component, status string
+		var count int
+		if err := rows.Scan(&component, &status, &count); err != nil {
+			return nil, err
+		}
+		if res[component] == nil {
+			res[component] = map[string]int{}
+		}
+		res[component][status] = count
+	}
+	return res, rows.Err()
+}
+
 func (r Repo) LatestRunningIteration(ctx context.Context, projectID string) (*domain.Iteration, error) {
-	row := r.DB.QueryRowContext(ctx, `SELECT id,project_id,goal,status,created_at FROM iterations WHERE project_id=? AND status='running' ORDER BY created_at DESC LIMIT 1`, projectID)
-	var it domain.Iteration
-	err := row.Scan(&it.ID, &it.ProjectID, &it.Goal, &it.Status, &it.CreatedAt)
-	if err == sql.ErrNoRows {
+	row := r.DB.QueryRowContext(ctx, `SELECT id,project_id,goal,status,capacity,created_at FROM iterations WHERE project_id=? AND status='running' ORDER BY created_at DESC LIMIT 1`, projectID)
+	it, err := scanIteration(row)
+	if errors.Is(err, ErrNotFound) {
 		return nil, nil
 	}
 	if err != nil {
@@ -772,28 +1267,43 @@ func (r Repo) LatestRunningIteration(ctx context.Context, projectID string) (*do
 	return &it, nil
 }
 
+// EventFilters narrows LatestEventsFrom; empty fields match everything.
+type EventFilters struct {
+	ProjectID  string
+	Type       string
+	EntityKind string
+	EntityID   string
+	// HideEntityKinds drops events of these entity kinds, such as audit
+	// entries the reader may not see.
+	HideEntityKinds []string
+}
+
 func (r Repo) LatestEvents(ctx context.Context, limit int, projectID, evtType, entityKind, entityID string) ([]domain.Event, error) {
-	return r.LatestEventsFrom(ctx, limit, 0, projectID, evtType, entityKind, entityID)
+	return r.LatestEventsFrom(ctx, limit, 0, EventFilters{ProjectID: projectID, Type: evtType, EntityKind: entityKind, EntityID: entityID})
 }
 
-func (r Repo) LatestEventsFrom(ctx context.Context, limit int, cursor int64, projectID, evtType, entityKind, entityID string) ([]domain.Event, error) {
+func (r Repo) LatestEventsFrom(ctx context.Context, limit int, cursor int64, f EventFilters) ([]domain.Event, error) {
 	clauses := []string{"1=1"}
 	var args []any
-	if projectID != "" {
+	if f.ProjectID != "" {
 		clauses = append(clauses, "project_id=?")
-		args = append(args, projectID)
+		args = append(args, f.ProjectID)
 	}
-	if evtType != "" {
+	if f.Type != "" {
 		clauses = append(clauses, "type=?")
-		args = append(args, evtType)
+		args = append(args, f.Type)
 	}
-	if entityKind != "" {
+	if f.EntityKind != "" {
 		clauses = append(clauses, "entity_kind=?")
-		args = append(args, entityKind)
+		args = append(args, f.EntityKind)
 	}
-	if entityID != "" {
+	if f.EntityID != "" {
 		clauses = append(clauses, "entity_id=?")
-		args = append(args, entityID)
+		args = append(args, f.EntityID)
+	}
+	for _, kind := range f.HideEntityKinds {
+		clauses = append(clauses, "entity_kind<>?")
+		args = append(args, kind)
 	}
 	if cursor > 0 {
 		clauses = append(clauses, "id<?")
@@ -870,6 +1380,31 @@ func (r Repo) LatestEventID(ctx context.Context, projectID string) (int64, error
 	return id, nil
 }
 
+// RBACVersion returns the id of the latest event that may change someone's
+// permissions: role, grant and org membership changes all append rbac.* or
+// org.* events.
+func (r Repo) RBACVersion(ctx context.Context) (int64, error) {
+	return rbacVersion(ctx, r.DB)
+}
+
+// RBACVersionTx is RBACVersion inside tx.
+func (r Repo) RBACVersionTx(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return rbacVersion(ctx, tx)
+}
+
+func rbacVersion(ctx context.Context, q queryer) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(id),0) FROM events WHERE entity_kind='rbac' AND (type LIKE 'rbac.%' OR type LIKE 'org.%')`).Scan(&id)
+	return id, err
+}
+
+func nullableFloatPtr(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
 func nullableIntPtr(v *int) any {
 	if v == nil {
 		return nil
@@ -877,14 +1412,82 @@ func nullableIntPtr(v *int) any {
 	return *v
 }
 
+// nullableInt stores 0 as NULL.
+func nullableInt(v int) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// decisionInsert numbers decisions inserted without one after the project's
+// last.
+const decisionInsert = `INSERT INTO decisions(id,project_id,number,title,context_json,decision,rationale_json,alternatives_json,decider_id,created_at)
+VALUES (?,?,COALESCE(?,(SELECT IFNULL(MAX(number),0)+1 FROM decisions WHERE project_id=?)),?,?,?,?,?,?,?)`
+
 func (r Repo) InsertDecision(ctx context.Context, d domain.Decision) error {
-	_, err := r.DB.ExecContext(ctx, `INSERT INTO decisions(id,project_id,title,context_json,decision,rationale_json,alternatives_json,decider_id,created_at) VALUES (?,?,?,?,?,?,?,?,?)`,
-		d.ID, d.ProjectID, d.Title, nullable(d.ContextJSON), d.Decision, nullable(d.RationaleJSON), nullable(d.AlternativesJSON), d.DeciderID, d.CreatedAt)
+	contextJSON, err := r.sealField(decisionContextField(d.ID), d.ContextJSON)
+	if err != nil {
+		return err
+	}
+	_, err = r.DB.ExecContext(ctx, decisionInsert,
+		d.ID, d.ProjectID, nullableInt(d.Number), d.ProjectID, d.Title, contextJSON, d.Decision, nullable(d.RationaleJSON), nullable(d.AlternativesJSON), d.DeciderID, d.CreatedAt)
 	return err
 }
 
 func (r Repo) InsertDecisionTx(ctx context.Context, tx *sql.Tx, d domain.Decision) error {
-	_, err := tx.ExecContext(ctx, `INSERT INTO decisions(id,project_id,title,context_json,decision,rationale_json,alternatives_json,decider_id,created_at) VALUES (?,?,?,?,?,?,?,?,?)`,
-		d.ID, d.ProjectID, d.Title, nullable(d.ContextJSON), d.Decision, nullable(d.RationaleJSON), nullable(d.AlternativesJSON), d.DeciderID, d.CreatedAt)
+	contextJSON, err := r.sealField(decisionContextField(d.ID), d.ContextJSON)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, decisionInsert,
+		d.ID, d.ProjectID, nullableInt(d.Number), d.ProjectID, d.Title, contextJSON, d.Decision, nullable(d.RationaleJSON), nullable(d.AlternativesJSON), d.DeciderID, d.CreatedAt)
 	return err
 }
+
+// NextDecisionNumberTx returns the number the project's next decision gets.
+func (r Repo) NextDecisionNumberTx(ctx context.Context, tx *sql.Tx, projectID string) (int, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT IFNULL(MAX(number),0)+1 FROM decisions WHERE project_id=?`, projectID).Scan(&n)
+	return n, err
+}
+
+func (r Repo) GetDecision(ctx context.Context, id string) (domain.Decision, error) {
+	d, err := r.scanDecision(r.DB.QueryRowContext(ctx, `SELECT `+decisionColumns+` FROM decisions WHERE id=?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, ErrNotFound
+	}
+	return d, err
+}
+
+// ListDecisionsTx returns the project's decisions by number.
+func (r Repo) ListDecisionsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Decision, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+decisionColumns+` FROM decisions WHERE project_id=? ORDER BY number, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Decision
+	for rows.Next() {
+		d, err := r.scanDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+const decisionColumns = `id,project_id,IFNULL(number,0),title,context_json,decision,rationale_json,alternatives_json,decider_id,created_at`
+
+func (r Repo) scanDecision(row interface{ Scan(...any) error }) (domain.Decision, error) {
+	var d domain.Decision
+	var contextJSON, rationale, alternatives sql.NullString
+	if err := row.Scan(&d.ID, &d.ProjectID, &d.Number, &d.Title, &contextJSON, &d.Decision, &rationale, &alternatives, &d.DeciderID, &d.CreatedAt); err != nil {
+		return d, err
+	}
+	d.RationaleJSON, d.AlternativesJSON = rationale.String, alternatives.String
+	var err error
+	d.ContextJSON, err = r.Fields.Open(decisionContextField(d.ID), contextJSON.String)
+	return d, err
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+func (r Repo) CountTasksByStatusTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT status, count(*) FROM tasks WHERE project_id=? GROUP BY status`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		res[status] = count
+	}
+	return res, rows.Err()
+}
+
+// IterationTaskCountsTx counts total and completed tasks per iteration,
+// ignoring canceled and rejected tasks.
+func (r Repo) IterationTaskCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]domain.ReportIteration, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT iteration_id, COUNT(*), COALESCE(SUM(CASE WHEN completed_at IS NOT NULL THEN 1 END),0)
+FROM tasks
+WHERE project_id=? AND iteration_id IS NOT NULL AND status NOT IN ('canceled','rejected')
+GROUP BY iteration_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]domain.ReportIteration{}
+	for rows.Next() {
+		var it domain.ReportIteration
+		if err := rows.Scan(&it.ID, &it.TotalTasks, &it.DoneTasks); err != nil {
+			return nil, err
+		}
+		res[it.ID] = it
+	}
+	return res, rows.Err()
+}
+
+// CompletedTasksTx returns the tasks completed in [since, until], oldest
+// completion first, without their attestations.
+func (r Repo) CompletedTasksTx(ctx context.Context, tx *sql.Tx, projectID, since, until string) ([]domain.ReportTask, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT id, title, COALESCE(iteration_id,''), completed_at
+FROM tasks
+WHERE project_id=? AND completed_at IS NOT NULL AND completed_at >= ? AND completed_at <= ?
+ORDER BY completed_at, id`, projectID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ReportTask
+	for rows.Next() {
+		var t domain.ReportTask
+		if err := rows.Scan(&t.ID, &t.Title, &t.IterationID, &t.CompletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// BlockedTasksTx returns open tasks with dependencies that are not complete,
+// ordered by task id.
+func (r Repo) BlockedTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ReportBlocker, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT t.id, t.title, t.status, d.depends_on_task_id
+FROM tasks t
+JOIN task_deps d ON d.task_id=t.id
+JOIN tasks dep ON dep.id=d.depends_on_task_id
+WHERE t.project_id=? AND t.completed_at IS NULL AND t.status NOT IN ('canceled','rejected')
+  AND dep.completed_at IS NULL
+ORDER BY t.id, d.depends_on_task_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.ReportBlocker
+	for rows.Next() {
+		var b domain.ReportBlocker
+		var dep string
+		if err := rows.Scan(&b.ID, &b.Title, &b.Status, &dep); err != nil {
+			return nil, err
+		}
+		if n := len(res); n > 0 && res[n-1].ID == b.ID {
+			res[n-1].BlockedBy = append(res[n-1].BlockedBy, dep)
+			continue
+		}
+		b.BlockedBy = []string{dep}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+// OverrideEventsTx returns policy overrides and forced changes recorded on
+// the project's tasks since the given time and after event afterID, oldest
+// first.
+func (r Repo) OverrideEventsTx(ctx context.Context, tx *sql.Tx, projectID, since string, afterID int64) ([]domain.DigestOverride, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT ev.id, ev.type, ev.entity_id, COALESCE(t.title,''), ev.actor_id, ev.ts
+FROM events ev
+LEFT JOIN tasks t ON t.id=ev.entity_id
+WHERE ev.project_id=? AND ev.entity_kind='task' AND ev.ts >= ? AND ev.id > ?
+  AND (ev.type='policy.override' OR json_extract(ev.payload_json, '$.forced')=1)
+ORDER BY ev.id`, projectID, since, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.DigestOverride
+	for rows.Next() {
+		var o domain.DigestOverride
+		if err := rows.Scan(&o.EventID, &o.Type, &o.TaskID, &o.Title, &o.ActorID, &o.TS); err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, rows.Err()
+}
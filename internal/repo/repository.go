@@ -0,0 +1,326 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/config"
+	"workline/internal/domain"
+)
+
+// Repository is the storage the engine runs on. Repo implements it on
+// SQLite; other backends, or test doubles wrapping a Repo, can be swapped in
+// through engine.Engine.Repo. Methods taking a *sql.Tx run inside the
+// engine's transaction, so implementations share the engine's database/sql
+// connection. Lookups of missing rows return ErrNotFound.
+type Repository interface {
+	// Projects, iterations, tasks, leases, attestations, events and decisions
+	InsertProject(ctx context.Context, p domain.Project) error
+	GetProject(ctx context.Context, id string) (domain.Project, error)
+	SingleProject(ctx context.Context) (domain.Project, error)
+	ListProjects(ctx context.Context, includeArchived bool) ([]domain.Project, error)
+	ProjectStatusTx(ctx context.Context, tx *sql.Tx, id string) (string, error)
+	InsertIteration(ctx context.Context, it domain.Iteration) error
+	InsertIterationTx(ctx context.Context, tx *sql.Tx, it domain.Iteration) error
+	UpdateProject(ctx context.Context, id, status string, description *string) error
+	DeleteProjectTx(ctx context.Context, tx *sql.Tx, id string) error
+	NextIDSequenceTx(ctx context.Context, tx *sql.Tx, projectID, kind string) (int64, error)
+	EntityIDExistsTx(ctx context.Context, tx *sql.Tx, kind, id string) (bool, error)
+	RenameProjectTx(ctx context.Context, tx *sql.Tx, oldID, newID, renamedAt string) error
+	ResolveProjectAlias(ctx context.Context, alias string) (string, error)
+	ProjectAliasesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ProjectAlias, error)
+	ProjectTables() []string
+	ProjectRowCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error)
+	ProjectRowsTx(ctx context.Context, tx *sql.Tx, table, projectID string, offset, limit int) ([]map[string]any, error)
+	EvidenceStorageKeysTx(ctx context.Context, tx *sql.Tx, projectID string) ([]string, error)
+	UpsertProjectConfig(ctx context.Context, projectID string, cfg *config.Config) error
+	UpsertProjectConfigTx(ctx context.Context, tx *sql.Tx, projectID string, cfg *config.Config) error
+	SaveProjectConfigTx(ctx context.Context, tx *sql.Tx, projectID, actorID string, cfg *config.Config) error
+	GetProjectConfig(ctx context.Context, projectID string) (*config.Config, error)
+	ListIterations(ctx context.Context, projectID string) ([]domain.Iteration, error)
+	ListIterationsWithCursor(ctx context.Context, projectID string, limit int, cursorCreatedAt, cursorID string) ([]domain.Iteration, error)
+	ListIterationsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Iteration, error)
+	GetIteration(ctx context.Context, id string) (domain.Iteration, error)
+	GetIterationTx(ctx context.Context, tx *sql.Tx, id string) (domain.Iteration, error)
+	UpdateIterationCapacity(ctx context.Context, tx *sql.Tx, id string, capacity *float64) error
+	IterationPointsTx(ctx context.Context, tx *sql.Tx, iterationID, excludeTaskID string) (planned, completed float64, err error)
+	UpdateIterationStatus(ctx context.Context, tx *sql.Tx, id, status string) error
+	InsertTask(ctx context.Context, tx *sql.Tx, t domain.Task) error
+	UpdateTask(ctx context.Context, tx *sql.Tx, t domain.Task) error
+	GetTask(ctx context.Context, id string) (domain.Task, error)
+	GetTaskTx(ctx context.Context, tx *sql.Tx, id string) (domain.Task, error)
+	ListTasks(ctx context.Context, f TaskFilters) ([]domain.Task, error)
+	ListTasksTx(ctx context.Context, tx *sql.Tx, f TaskFilters) ([]domain.Task, error)
+	NextTask(ctx context.Context, f NextTaskFilters) (domain.Task, error)
+	NextTaskIDTx(ctx context.Context, tx *sql.Tx, f NextTaskFilters) (string, error)
+	ListTaskDependencies(ctx context.Context, taskID string) ([]string, error)
+	ListTaskDependenciesTx(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error)
+	ListDependenciesForTasks(ctx context.Context, ids []string) (map[string][]string, error)
+	ListDependenciesForTasksTx(ctx context.Context, tx *sql.Tx, ids []string) (map[string][]string, error)
+	AddDependencies(ctx context.Context, tx *sql.Tx, taskID string, deps []string) error
+	RemoveDependencies(ctx context.Context, tx *sql.Tx, taskID string, deps []string) error
+	ListChildren(ctx context.Context, taskID string) ([]string, error)
+	ListChildrenTx(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error)
+	UpsertLease(ctx context.Context, tx *sql.Tx, lease domain.Lease) error
+	DeleteLease(ctx context.Context, tx *sql.Tx, taskID string) error
+	GetLeaseTx(ctx context.Context, tx *sql.Tx, taskID string) (domain.Lease, error)
+	GetLease(ctx context.Context, taskID string) (domain.Lease, error)
+	ListLeases(ctx context.Context, projectID string) ([]domain.Lease, error)
+	InsertAttestation(ctx context.Context, att domain.Attestation) error
+	InsertAttestationTx(ctx context.Context, tx *sql.Tx, att domain.Attestation) error
+	ListAttestations(ctx context.Context, f AttestationFilters) ([]domain.Attestation, error)
+	CountTasksByStatus(ctx context.Context, projectID string) (map[string]int, error)
+	CountTasksByComponent(ctx context.Context, projectID string) (map[string]map[string]int, error)
+	LatestRunningIteration(ctx context.Context, projectID string) (*domain.Iteration, error)
+	LatestEvents(ctx context.Context, limit int, projectID, evtType, entityKind, entityID string) ([]domain.Event, error)
+	LatestEventsFrom(ctx context.Context, limit int, cursor int64, f EventFilters) ([]domain.Event, error)
+	EventsAfter(ctx context.Context, limit int, cursor int64, projectID string) ([]domain.Event, error)
+	LatestEventID(ctx context.Context, projectID string) (int64, error)
+	RBACVersion(ctx context.Context) (int64, error)
+	RBACVersionTx(ctx context.Context, tx *sql.Tx) (int64, error)
+	InsertReadAudit(ctx context.Context, entry domain.ReadAuditEntry) error
+	ReadAuditTx(ctx context.Context, tx *sql.Tx, f ReadAuditFilters) ([]domain.ReadAuditEntry, error)
+	ResealFieldsTx(ctx context.Context, tx *sql.Tx) (int, error)
+	InsertDecision(ctx context.Context, d domain.Decision) error
+	GetDecision(ctx context.Context, id string) (domain.Decision, error)
+	ListDecisionsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Decision, error)
+	NextDecisionNumberTx(ctx context.Context, tx *sql.Tx, projectID string) (int, error)
+	FindEntityRefs(ctx context.Context, kind, projectID, ref string, limit int) ([]domain.EntityRef, error)
+	InsertDecisionTx(ctx context.Context, tx *sql.Tx, d domain.Decision) error
+
+	// Actor missions
+	UpsertActorMission(ctx context.Context, projectID, actorID, mission string) (domain.ActorMission, error)
+	UpsertActorMissionTx(ctx context.Context, tx *sql.Tx, projectID, actorID, mission string) (domain.ActorMission, error)
+	GetActorMission(ctx context.Context, projectID, actorID string) (domain.ActorMission, error)
+	GetActorMissionTx(ctx context.Context, tx *sql.Tx, projectID, actorID string) (domain.ActorMission, error)
+	ListActorMissions(ctx context.Context, projectID, actorID string) ([]domain.ActorMission, error)
+	DeleteActorMission(ctx context.Context, projectID, actorID string) error
+	DeleteActorMissionTx(ctx context.Context, tx *sql.Tx, projectID, actorID string) error
+	ReplaceActorMissions(ctx context.Context, projectID string, missions []config.ActorMissionConfig) error
+	ReplaceActorMissionsTx(ctx context.Context, tx *sql.Tx, projectID string, missions []config.ActorMissionConfig) error
+
+	// Actors
+	ActorStatus(ctx context.Context, actorID string) (string, error)
+	ActorExistsTx(ctx context.Context, tx *sql.Tx, actorID string) (bool, error)
+	SetActorStatusTx(ctx context.Context, tx *sql.Tx, actorID, status string, deactivatedAt *string) error
+	ScrubbedEventsTx(ctx context.Context, tx *sql.Tx, actorID string) (map[string]int64, error)
+	ScrubActorTx(ctx context.Context, tx *sql.Tx, actorID, pseudonym, scrubbedAt string) (int, error)
+	ListLeasesByOwnerTx(ctx context.Context, tx *sql.Tx, ownerID string) ([]domain.Lease, error)
+
+	// API keys
+	InsertAPIKey(ctx context.Context, tx *sql.Tx, key domain.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error)
+	ListAPIKeys(ctx context.Context, actorID string) ([]domain.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+	InsertCalendarFeedTx(ctx context.Context, tx *sql.Tx, feed domain.CalendarFeed, tokenHash string) error
+	GetCalendarFeedByHash(ctx context.Context, hash string) (domain.CalendarFeed, error)
+	GetCalendarFeedTx(ctx context.Context, tx *sql.Tx, projectID, id string) (domain.CalendarFeed, error)
+	ListCalendarFeedsTx(ctx context.Context, tx *sql.Tx, projectID, actorID string) ([]domain.CalendarFeed, error)
+	DeleteCalendarFeedTx(ctx context.Context, tx *sql.Tx, id string) error
+
+	// Assignees
+	ProjectActorsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]string, error)
+	AssignedTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error)
+	LastActivityTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]string, error)
+
+	// Attestation expiry
+	UnexpiredAttestationsTx(ctx context.Context, tx *sql.Tx, projectID string, kinds []string) ([]domain.Attestation, error)
+	MarkAttestationExpiredTx(ctx context.Context, tx *sql.Tx, id, expiredAt string) error
+
+	// Task staleness
+	StaleCandidatesTx(ctx context.Context, tx *sql.Tx, projectID string, statuses []string, updatedBefore string) ([]domain.Task, error)
+	MarkTaskStaleTx(ctx context.Context, tx *sql.Tx, id, staleSince string) error
+
+	// SLA
+	SLABreachCandidatesTx(ctx context.Context, tx *sql.Tx, projectID, asOf string) ([]domain.Task, error)
+	MarkSLABreachedTx(ctx context.Context, tx *sql.Tx, id, breachedAt string) error
+
+	// Task relations
+	AddTaskRelationTx(ctx context.Context, tx *sql.Tx, projectID string, rel domain.TaskRelation) (bool, error)
+	RemoveTaskRelationTx(ctx context.Context, tx *sql.Tx, taskID, relType, relatedTaskID string) (bool, error)
+	TaskRelationsTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.TaskRelation, error)
+	RelationsForTasks(ctx context.Context, ids []string) (map[string][]domain.TaskRelation, error)
+	RelationsForTasksTx(ctx context.Context, tx *sql.Tx, ids []string) (map[string][]domain.TaskRelation, error)
+	BlockersTx(ctx context.Context, tx *sql.Tx, taskID string) ([]string, error)
+
+	// Priority escalation
+	EscalationCandidatesTx(ctx context.Context, tx *sql.Tx, projectID, sinceBefore string) ([]domain.Escalation, error)
+	EscalateTaskTx(ctx context.Context, tx *sql.Tx, id string, priority int, escalatedAt string) error
+
+	// Calendar
+	IterationStatusEventsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Event, error)
+
+	// Config versions
+	ListProjectConfigVersionsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ConfigVersion, error)
+	GetProjectConfigVersionTx(ctx context.Context, tx *sql.Tx, projectID string, version int) (domain.ConfigVersion, error)
+	OpenTaskRequirementsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error)
+
+	// Dashboard
+	DoneTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Task, error)
+	ForcedCompletionsTx(ctx context.Context, tx *sql.Tx, projectID string, doneStates map[string]string) (map[string]bool, error)
+	PolicyOverridesByIterationTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error)
+	ValidationStatusCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error)
+
+	// Event retention
+	CompactableEventsTx(ctx context.Context, tx *sql.Tx, projectID, before string, keep int, exempt []string) ([]domain.Event, error)
+	DeleteEventsTx(ctx context.Context, tx *sql.Tx, ids []int64) error
+	EventChainPageTx(ctx context.Context, tx *sql.Tx, projectID string, afterID int64, limit int) ([]domain.Event, error)
+	SetEventHashTx(ctx context.Context, tx *sql.Tx, id int64, prevHash, hash string) error
+	EventChainHeadTx(ctx context.Context, tx *sql.Tx, projectID string) (domain.EventChainHead, error)
+	InsertEventCompactionTx(ctx context.Context, tx *sql.Tx, projectID string, firstID, lastID int64, compactedAt string) error
+	CompactedRangesTx(ctx context.Context, tx *sql.Tx, projectID string) ([][2]int64, error)
+
+	// Projections
+	ProjectionStatesTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]domain.ProjectionState, error)
+	SetProjectionStateTx(ctx context.Context, tx *sql.Tx, name, projectID string, lastEventID int64, rebuiltAt string) error
+	EventCompactedTx(ctx context.Context, tx *sql.Tx, projectID string) (bool, error)
+	ResetTaskStatusProjectionTx(ctx context.Context, tx *sql.Tx, projectID string) error
+	SetProjectedTaskStatusTx(ctx context.Context, tx *sql.Tx, projectID, taskID, status string) error
+	ProjectedTaskStatusCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error)
+
+	// Evidence
+	GetAttestationTx(ctx context.Context, tx *sql.Tx, id string) (domain.Attestation, error)
+	InsertEvidenceTx(ctx context.Context, tx *sql.Tx, ev domain.Evidence) error
+	GetEvidenceTx(ctx context.Context, tx *sql.Tx, id string) (domain.Evidence, error)
+	ListEvidenceTx(ctx context.Context, tx *sql.Tx, attestationID string) ([]domain.Evidence, error)
+
+	// Transition hooks
+	ReviewLoadTx(ctx context.Context, tx *sql.Tx, projectID, excludeTaskID string, actors []string) (map[string]int, error)
+	SetTaskAssigneeTx(ctx context.Context, tx *sql.Tx, taskID, assigneeID, updatedAt string) error
+
+	// Milestones
+	InsertMilestoneTx(ctx context.Context, tx *sql.Tx, m domain.Milestone) error
+	AddMilestoneLinksTx(ctx context.Context, tx *sql.Tx, milestoneID string, iterationIDs, taskIDs []string) error
+	GetMilestone(ctx context.Context, id string) (domain.Milestone, error)
+	GetMilestoneTx(ctx context.Context, tx *sql.Tx, id string) (domain.Milestone, error)
+	ListMilestonesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Milestone, error)
+	MilestoneProgressTx(ctx context.Context, tx *sql.Tx, milestoneID string) (domain.MilestoneProgress, error)
+
+	// Releases
+	InsertReleaseTx(ctx context.Context, tx *sql.Tx, rel domain.Release) error
+	GetReleaseByVersionTx(ctx context.Context, tx *sql.Tx, projectID, name, version string) (domain.Release, error)
+	ListReleasesTx(ctx context.Context, tx *sql.Tx, projectID, iterationID string) ([]domain.Release, error)
+
+	// Force requests
+	InsertForceRequestTx(ctx context.Context, tx *sql.Tx, fr domain.ForceRequest) error
+	GetForceRequest(ctx context.Context, id string) (domain.ForceRequest, error)
+	ListForceRequestsTx(ctx context.Context, tx *sql.Tx, projectID, status string) ([]domain.ForceRequest, error)
+	ApproveForceRequestTx(ctx context.Context, tx *sql.Tx, id, actorID, approvedAt string) (bool, error)
+	InsertWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error
+	GetWebhookDeliveryTx(ctx context.Context, tx *sql.Tx, id string) (domain.WebhookDelivery, error)
+	ListWebhookDeliveriesTx(ctx context.Context, tx *sql.Tx, projectID string, failed bool, limit int) ([]domain.WebhookDelivery, error)
+
+	// External refs
+	ListExternalRefs(ctx context.Context, projectID, source string) (map[string]domain.ExternalRef, error)
+	UpsertExternalRef(ctx context.Context, ref domain.ExternalRef) error
+	GetExternalRef(ctx context.Context, projectID, source, externalID string) (domain.ExternalRef, error)
+	GetExternalRefTx(ctx context.Context, tx *sql.Tx, projectID, source, externalID string) (domain.ExternalRef, error)
+	SetExternalRefTx(ctx context.Context, tx *sql.Tx, ref domain.ExternalRef) error
+	DeleteExternalRefTx(ctx context.Context, tx *sql.Tx, projectID, source, entityKind, entityID string) error
+	ExternalRefsFor(ctx context.Context, entityKind string, ids []string) (map[string]map[string]string, error)
+	ExternalRefsForTx(ctx context.Context, tx *sql.Tx, entityKind string, ids []string) (map[string]map[string]string, error)
+
+	// Organizations
+	InsertOrgTx(ctx context.Context, tx *sql.Tx, org domain.Org) error
+	GetOrg(ctx context.Context, id string) (domain.Org, error)
+	GetOrgTx(ctx context.Context, tx *sql.Tx, id string) (domain.Org, error)
+	ListOrgsForActor(ctx context.Context, actorID string) ([]domain.Org, error)
+	ListOrgMembers(ctx context.Context, orgID string) ([]domain.OrgMember, error)
+	OrgRoleTx(ctx context.Context, tx *sql.Tx, orgID, actorID string) (string, error)
+	SetOrgRole(ctx context.Context, tx *sql.Tx, orgID, actorID, role string) error
+	RemoveOrgMember(ctx context.Context, tx *sql.Tx, orgID, actorID string) error
+	CountOrgOwners(ctx context.Context, tx *sql.Tx, orgID string) (int, error)
+	ProjectOrgTx(ctx context.Context, tx *sql.Tx, projectID string) (string, error)
+	ActorOrgsTx(ctx context.Context, tx *sql.Tx, actorID string) ([]string, error)
+	ListProjectsByOrg(ctx context.Context, orgID string, includeArchived bool) ([]domain.Project, error)
+	AssignOrgActorRole(ctx context.Context, tx *sql.Tx, orgID, actorID, roleID string) error
+	RevokeOrgActorRole(ctx context.Context, tx *sql.Tx, orgID, actorID, roleID string) error
+
+	// Outbox
+	DueOutbox(ctx context.Context, now string, limit int) ([]domain.OutboxMessage, error)
+	DeleteOutbox(ctx context.Context, ids []int64) error
+	DeferOutbox(ctx context.Context, ids []int64, lastError, nextAttemptAt string) error
+	OutboxBacklog(ctx context.Context) (int, string, error)
+	InsertHookDeliveryTx(ctx context.Context, tx *sql.Tx, d domain.HookDelivery) (int64, error)
+	ClaimHookDeliveries(ctx context.Context, projectID, now, until string, limit int) ([]domain.HookDelivery, error)
+	DeleteHookDelivery(ctx context.Context, id int64) error
+	DeferHookDelivery(ctx context.Context, id int64, lastError, nextAttemptAt string) error
+
+	// RBAC
+	EnsureActor(ctx context.Context, tx *sql.Tx, actorID string, now string) error
+	EnsureOrg(ctx context.Context, tx *sql.Tx, orgID, name, now string) error
+	AssignOrgRole(ctx context.Context, tx *sql.Tx, orgID, actorID, role string) error
+	InsertRole(ctx context.Context, tx *sql.Tx, id, desc string) error
+	InsertProjectRole(ctx context.Context, tx *sql.Tx, projectID, id, desc string) error
+	InsertRoles(ctx context.Context, tx *sql.Tx, descs map[string]string) error
+	InsertPermissions(ctx context.Context, tx *sql.Tx, descs map[string]string) error
+	RoleExistsTx(ctx context.Context, tx *sql.Tx, roleID string) (bool, error)
+	PermissionExistsTx(ctx context.Context, tx *sql.Tx, permID string) (bool, error)
+	GetRoleTx(ctx context.Context, tx *sql.Tx, roleID string) (domain.Role, error)
+	ListRolesTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.Role, error)
+	ListPermissionsTx(ctx context.Context, tx *sql.Tx) ([]domain.Permission, error)
+	RoleAttestationKindsTx(ctx context.Context, tx *sql.Tx, projectID, roleID string) ([]string, error)
+	RoleMembersTx(ctx context.Context, tx *sql.Tx, projectID, roleID string) ([]domain.RoleMember, error)
+	ProjectRoleGrantsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string][]string, error)
+	AttestationAuthoritiesTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string][]string, error)
+	UpdateRoleDescription(ctx context.Context, tx *sql.Tx, roleID, desc string) error
+	ClearRolePermissions(ctx context.Context, tx *sql.Tx, roleID string) error
+	DeleteRole(ctx context.Context, tx *sql.Tx, projectID, roleID string) error
+	AddRolePermissions(ctx context.Context, tx *sql.Tx, roleID string, permIDs []string) error
+	AssignRole(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string) error
+	AssignRoleUntil(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string, expiresAt *string) error
+	ExpiredRoleGrantsTx(ctx context.Context, tx *sql.Tx, projectID, now string) ([]domain.RoleGrant, error)
+	RevokeRole(ctx context.Context, tx *sql.Tx, projectID, actorID, roleID string) error
+	AllowAttestationRole(ctx context.Context, tx *sql.Tx, projectID, kind, roleID string) error
+	DenyAttestationRole(ctx context.Context, tx *sql.Tx, projectID, kind, roleID string) error
+
+	// Reports
+	CountTasksByStatusTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]int, error)
+	IterationTaskCountsTx(ctx context.Context, tx *sql.Tx, projectID string) (map[string]domain.ReportIteration, error)
+	CompletedTasksTx(ctx context.Context, tx *sql.Tx, projectID, since, until string) ([]domain.ReportTask, error)
+	BlockedTasksTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.ReportBlocker, error)
+	OverrideEventsTx(ctx context.Context, tx *sql.Tx, projectID, since string, afterID int64) ([]domain.DigestOverride, error)
+
+	// Task history
+	EntityAttestationsTx(ctx context.Context, tx *sql.Tx, entityKind, entityID string) ([]domain.Attestation, error)
+	TaskHistoryEventsTx(ctx context.Context, tx *sql.Tx, projectID, taskID string, attestationIDs []string) ([]domain.Event, error)
+	TaskStatusEventsTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.Event, error)
+
+	// Time entries
+	InsertTimeEntryTx(ctx context.Context, tx *sql.Tx, te domain.TimeEntry) error
+	ListTimeEntriesTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.TimeEntry, error)
+	TaskTimeTotal(ctx context.Context, taskID string) (int, error)
+	IterationTimeTotalsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.IterationTime, error)
+
+	// Validations
+	CreateValidation(ctx context.Context, v domain.Validation) (domain.Validation, error)
+	CreateValidationTx(ctx context.Context, tx *sql.Tx, v domain.Validation) (domain.Validation, error)
+	UpdateValidation(ctx context.Context, v domain.Validation) (domain.Validation, error)
+	UpdateValidationTx(ctx context.Context, tx *sql.Tx, v domain.Validation) (domain.Validation, error)
+	GetValidation(ctx context.Context, id string) (domain.Validation, error)
+	GetValidationTx(ctx context.Context, tx *sql.Tx, id string) (domain.Validation, error)
+	ListValidationsByTask(ctx context.Context, projectID, taskID string) ([]domain.Validation, error)
+	HasRejectedValidation(ctx context.Context, projectID, taskID string) (bool, error)
+	HasRejectedValidationTx(ctx context.Context, tx *sql.Tx, projectID, taskID string) (bool, error)
+
+	// Work outcomes
+	TaskVersionTx(ctx context.Context, tx *sql.Tx, taskID string) (int64, error)
+	SetTaskWorkOutcomesTx(ctx context.Context, tx *sql.Tx, taskID string, workOutcomes *string, updatedAt string, version int64) (bool, error)
+
+	// Sync
+	SyncCursorsTx(ctx context.Context, tx *sql.Tx, projectID, remote string) (int64, int64, error)
+	SaveSyncCursorsTx(ctx context.Context, tx *sql.Tx, projectID, remote string, local, remoteCursor int64, syncedAt string) error
+	SyncBasesTx(ctx context.Context, tx *sql.Tx, projectID, remote string) (map[string]string, error)
+	SaveSyncBaseTx(ctx context.Context, tx *sql.Tx, projectID, remote, taskID, snapshot string) error
+	TaskWritesBetweenTx(ctx context.Context, tx *sql.Tx, projectID string, after, upto int64, skipType string) ([]domain.Event, error)
+
+	// Offline queue
+	InsertQueuedCommandTx(ctx context.Context, tx *sql.Tx, c domain.QueuedCommand) (int64, error)
+	SignQueuedCommandTx(ctx context.Context, tx *sql.Tx, seq int64, signature string) error
+	GetQueuedCommandTx(ctx context.Context, tx *sql.Tx, projectID string, seq int64) (domain.QueuedCommand, error)
+	ListQueuedCommandsTx(ctx context.Context, tx *sql.Tx, projectID, remote string) ([]domain.QueuedCommand, error)
+	SetQueuedCommandStatusTx(ctx context.Context, tx *sql.Tx, seq int64, status, reason string) error
+	DeleteQueuedCommandTx(ctx context.Context, tx *sql.Tx, seq int64) error
+}
+
+var _ Repository = Repo{}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// openTaskClause matches tasks still to be worked on: not completed and not
+// canceled or rejected.
+const openTaskClause = "completed_at IS NULL AND status NOT IN ('canceled','rejected')"
+
+// SLABreachCandidatesTx returns a project's open tasks due before asOf whose
+// breach of that due date was not recorded yet, earliest due first. Only id,
+// title, status, assignee, priority, type and timestamps are set.
+func (r Repo) SLABreachCandidatesTx(ctx context.Context, tx *sql.Tx, projectID, asOf string) ([]domain.Task, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,project_id,type,title,status,assignee_id,priority,created_at,updated_at,due_at FROM tasks
+WHERE project_id=? AND due_at<? AND (sla_breached_at IS NULL OR sla_breached_at<due_at) AND `+openTaskClause+` ORDER BY due_at, id`, projectID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Task
+	for rows.Next() {
+		var t domain.Task
+		var assigneeID sql.NullString
+		var priority sql.NullInt64
+		var dueAt string
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Type, &t.Title, &t.Status, &assigneeID, &priority, &t.CreatedAt, &t.UpdatedAt, &dueAt); err != nil {
+			return nil, err
+		}
+		if assigneeID.Valid {
+			t.AssigneeID = &assigneeID.String
+		}
+		if priority.Valid {
+			p := int(priority.Int64)
+			t.Priority = &p
+		}
+		t.DueAt = &dueAt
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// MarkSLABreachedTx records when the sweep found a task past its due date.
+func (r Repo) MarkSLABreachedTx(ctx context.Context, tx *sql.Tx, id, breachedAt string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE tasks SET sla_breached_at=? WHERE id=?`, breachedAt, id)
+	return err
+}
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// StaleCandidatesTx returns a project's tasks in one of statuses, last updated
+// before updatedBefore and not yet marked stale since, oldest update first.
+// Only id, title, status, assignee and timestamps are set.
+func (r Repo) StaleCandidatesTx(ctx context.Context, tx *sql.Tx, projectID string, statuses []string, updatedBefore string) ([]domain.Task, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	args := appendStrings([]any{projectID, updatedBefore}, statuses)
+	rows, err := tx.QueryContext(ctx, `SELECT id,project_id,title,status,assignee_id,created_at,updated_at FROM tasks
+WHERE project_id=? AND updated_at<? AND (stale_since IS NULL OR stale_since<=updated_at) AND status IN (`+placeholders(len(statuses))+`) ORDER BY updated_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Task
+	for rows.Next() {
+		var t domain.Task
+		var assigneeID sql.NullString
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Title, &t.Status, &assigneeID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if assigneeID.Valid {
+			t.AssigneeID = &assigneeID.String
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// MarkTaskStaleTx records when the sweep found a task stale. It leaves
+// updated_at alone, so the next update clears the mark.
+func (r Repo) MarkTaskStaleTx(ctx context.Context, tx *sql.Tx, id, staleSince string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE tasks SET stale_since=? WHERE id=?`, staleSince, id)
+	return err
+}
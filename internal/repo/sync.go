@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"workline/internal/domain"
+)
+
+// SyncCursorsTx returns the local and remote event cursors a project was
+// last synced up to with remote, zero when it never was.
+func (r Repo) SyncCursorsTx(ctx context.Context, tx *sql.Tx, projectID, remote string) (local, remoteCursor int64, err error) {
+	err = tx.QueryRowContext(ctx, `SELECT local_cursor, remote_cursor FROM sync_remotes WHERE project_id=? AND remote=?`, projectID, remote).Scan(&local, &remoteCursor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil
+	}
+	return local, remoteCursor, err
+}
+
+// SaveSyncCursorsTx records the cursors a sync with remote reached.
+func (r Repo) SaveSyncCursorsTx(ctx context.Context, tx *sql.Tx, projectID, remote string, local, remoteCursor int64, syncedAt string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO sync_remotes(project_id, remote, local_cursor, remote_cursor, synced_at) VALUES (?,?,?,?,?)
+ON CONFLICT(project_id, remote) DO UPDATE SET local_cursor=excluded.local_cursor, remote_cursor=excluded.remote_cursor, synced_at=excluded.synced_at`,
+		projectID, remote, local, remoteCursor, syncedAt)
+	return err
+}
+
+// SyncBasesTx returns the snapshots of the tasks last agreed with remote,
+// keyed by task id.
+func (r Repo) SyncBasesTx(ctx context.Context, tx *sql.Tx, projectID, remote string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT task_id, snapshot_json FROM sync_base WHERE project_id=? AND remote=?`, projectID, remote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bases := map[string]string{}
+	for rows.Next() {
+		var id, snapshot string
+		if err := rows.Scan(&id, &snapshot); err != nil {
+			return nil, err
+		}
+		bases[id] = snapshot
+	}
+	return bases, rows.Err()
+}
+
+// SaveSyncBaseTx records a task's snapshot as agreed with remote.
+func (r Repo) SaveSyncBaseTx(ctx context.Context, tx *sql.Tx, projectID, remote, taskID, snapshot string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO sync_base(project_id, remote, task_id, snapshot_json) VALUES (?,?,?,?)
+ON CONFLICT(project_id, remote, task_id) DO UPDATE SET snapshot_json=excluded.snapshot_json`, projectID, remote, taskID, snapshot)
+	return err
+}
+
+// TaskWritesBetweenTx returns a project's events in (after, upto] that may
+// have changed tasks: those of a task, except skipType, and bulk updates.
+func (r Repo) TaskWritesBetweenTx(ctx context.Context, tx *sql.Tx, projectID string, after, upto int64, skipType string) ([]domain.Event, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json FROM events
+WHERE project_id=? AND id>? AND id<=? AND ((entity_kind='task' AND type<>?) OR type='task.bulk_updated') ORDER BY id`, projectID, after, upto, skipType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var payload sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &e.EntityID, &e.ActorID, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Valid {
+			e.Payload = payload.String
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+// EntityAttestationsTx returns the attestations recorded on an entity,
+// oldest first.
+func (r Repo) EntityAttestationsTx(ctx context.Context, tx *sql.Tx, entityKind, entityID string) ([]domain.Attestation, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,project_id,entity_kind,entity_id,kind,actor_id,ts,payload_json,expired_at FROM attestations
+WHERE entity_kind=? AND entity_id=? ORDER BY ts, id`, entityKind, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Attestation
+	for rows.Next() {
+		var a domain.Attestation
+		var payload, expiredAt sql.NullString
+		if err := rows.Scan(&a.ID, &a.ProjectID, &a.EntityKind, &a.EntityID, &a.Kind, &a.ActorID, &a.TS, &payload, &expiredAt); err != nil {
+			return nil, err
+		}
+		if a.PayloadJSON, err = r.Fields.Open(attestationPayloadField(a.ID), payload.String); err != nil {
+			return nil, err
+		}
+		if expiredAt.Valid {
+			a.ExpiredAt = &expiredAt.String
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+// TaskHistoryEventsTx returns, oldest first, the events recorded on a task or
+// on the given attestations, plus the project's bulk update and validation
+// events, whose payloads name the tasks they touched.
+func (r Repo) TaskHistoryEventsTx(ctx context.Context, tx *sql.Tx, projectID, taskID string, attestationIDs []string) ([]domain.Event, error) {
+	query := `SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json FROM events
+WHERE project_id=? AND ((entity_kind='task' AND entity_id=?) OR type IN ('task.bulk_updated','validation.created','validation.updated')`
+	args := []any{projectID, taskID}
+	if len(attestationIDs) > 0 {
+		query += ` OR (entity_kind='attestation' AND entity_id IN (` + placeholders(len(attestationIDs)) + `))`
+		args = appendStrings(args, attestationIDs)
+	}
+	query += `) ORDER BY id`
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var payload sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &e.EntityID, &e.ActorID, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Valid {
+			e.Payload = payload.String
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// TaskStatusEventsTx returns the events that may have changed a task's
+// status, newest first.
+func (r Repo) TaskStatusEventsTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.Event, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id,ts,type,project_id,entity_kind,entity_id,actor_id,payload_json FROM events
+WHERE entity_kind='task' AND entity_id=? AND type IN ('task.updated','task.done','task.reverted','task.synced') ORDER BY id DESC`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var payload sql.NullString
+		if err := rows.Scan(&e.ID, &e.TS, &e.Type, &e.ProjectID, &e.EntityKind, &e.EntityID, &e.ActorID, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Valid {
+			e.Payload = payload.String
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+func (r Repo) InsertTimeEntryTx(ctx context.Context, tx *sql.Tx, te domain.TimeEntry) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO task_time_entries(id, task_id, project_id, actor_id, minutes, note, created_at) VALUES (?,?,?,?,?,?,?)`,
+		te.ID, te.TaskID, te.ProjectID, te.ActorID, te.Minutes, nullable(te.Note), te.CreatedAt)
+	return err
+}
+
+func (r Repo) ListTimeEntriesTx(ctx context.Context, tx *sql.Tx, taskID string) ([]domain.TimeEntry, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, task_id, project_id, actor_id, minutes, COALESCE(note,''), created_at FROM task_time_entries WHERE task_id=? ORDER BY created_at, rowid`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.TimeEntry
+	for rows.Next() {
+		var te domain.TimeEntry
+		if err := rows.Scan(&te.ID, &te.TaskID, &te.ProjectID, &te.ActorID, &te.Minutes, &te.Note, &te.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, te)
+	}
+	return res, rows.Err()
+}
+
+// TaskTimeTotal returns the minutes logged on a task.
+func (r Repo) TaskTimeTotal(ctx context.Context, taskID string) (int, error) {
+	var n int
+	err := r.DB.QueryRowContext(ctx, `SELECT COALESCE(SUM(minutes),0) FROM task_time_entries WHERE task_id=?`, taskID).Scan(&n)
+	return n, err
+}
+
+// IterationTimeTotalsTx sums logged minutes per iteration; tasks without an
+// iteration are grouped under an empty id.
+func (r Repo) IterationTimeTotalsTx(ctx context.Context, tx *sql.Tx, projectID string) ([]domain.IterationTime, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT COALESCE(t.iteration_id,''), SUM(te.minutes)
+FROM task_time_entries te
+JOIN tasks t ON t.id=te.task_id
+WHERE te.project_id=?
+GROUP BY COALESCE(t.iteration_id,'')
+ORDER BY 1`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.IterationTime
+	for rows.Next() {
+		var it domain.IterationTime
+		if err := rows.Scan(&it.IterationID, &it.Minutes); err != nil {
+			return nil, err
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
@@ -149,6 +149,17 @@ func (r Repo) HasRejectedValidation(ctx context.Context, projectID, taskID strin
 	return err == nil, err
 }
 
+func (r Repo) HasRejectedValidationTx(ctx context.Context, tx *sql.Tx, projectID, taskID string) (bool, error) {
+	row := tx.QueryRowContext(ctx, `SELECT 1 FROM validations WHERE project_id=? AND task_id=? AND status='rejected' LIMIT 1`,
+		projectID, taskID)
+	var n int
+	err := row.Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func nullableString(s string) any {
 	if s == "" {
 		return nil
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"workline/internal/domain"
+)
+
+const webhookDeliveryColumns = `id, project_id, url, event_id, event_type, body, status_code, error, duration_ms, attempted_at, replay_of`
+
+func (r Repo) InsertWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO webhook_deliveries(`+webhookDeliveryColumns+`) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		d.ID, d.ProjectID, d.URL, d.EventID, d.EventType, d.Body, d.StatusCode, nullable(d.Error), d.DurationMS, d.AttemptedAt, nullableStringPtr(d.ReplayOf))
+	return err
+}
+
+func (r Repo) GetWebhookDeliveryTx(ctx context.Context, tx *sql.Tx, id string) (domain.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(tx.QueryRowContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return d, ErrNotFound
+	}
+	return d, err
+}
+
+// ListWebhookDeliveriesTx returns a project's latest delivery attempts, newest
+// first, only the failed ones when failed is set.
+func (r Repo) ListWebhookDeliveriesTx(ctx context.Context, tx *sql.Tx, projectID string, failed bool, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE project_id=? AND (?=0 OR error IS NOT NULL) ORDER BY attempted_at DESC, rowid DESC LIMIT ?`, projectID, failed, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var errText, replayOf sql.NullString
+	if err := row.Scan(&d.ID, &d.ProjectID, &d.URL, &d.EventID, &d.EventType, &d.Body, &d.StatusCode, &errText, &d.DurationMS, &d.AttemptedAt, &replayOf); err != nil {
+		return d, err
+	}
+	d.Error = errText.String
+	if replayOf.Valid {
+		d.ReplayOf = &replayOf.String
+	}
+	return d, nil
+}
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// TaskVersionTx returns a task's row version.
+func (r Repo) TaskVersionTx(ctx context.Context, tx *sql.Tx, taskID string) (int64, error) {
+	var version int64
+	err := tx.QueryRowContext(ctx, `SELECT version FROM tasks WHERE id=?`, taskID).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return version, err
+}
+
+// SetTaskWorkOutcomesTx writes a task's work outcomes if its row is still at
+// version, bumping the version. It reports false when the row has moved on.
+func (r Repo) SetTaskWorkOutcomesTx(ctx context.Context, tx *sql.Tx, taskID string, workOutcomes *string, updatedAt string, version int64) (bool, error) {
+	sealed, err := r.sealFieldPtr(workOutcomesField(taskID), workOutcomes)
+	if err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE tasks SET work_outcomes_json=?, updated_at=?, version=version+1 WHERE id=? AND version=?`,
+		sealed, updatedAt, taskID, version)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -19,6 +19,10 @@ import (
 type AuthConfig struct {
 	JWTSecret string
 	Logger    *log.Logger
+	// ClientCertActor names the verified client-certificate field (cn,
+	// email, dns or uri) that identifies the actor; empty disables
+	// certificate authentication.
+	ClientCertActor string
 }
 
 type Principal struct {
@@ -99,7 +103,7 @@ func authenticateJWT(token string, secret string) (Principal, error) {
 	}, nil
 }
 
-func authenticateAPIKey(ctx context.Context, r repo.Repo, key string) (Principal, error) {
+func authenticateAPIKey(ctx context.Context, r repo.Repository, key string) (Principal, error) {
 	if strings.TrimSpace(key) == "" {
 		return Principal{}, errors.New("api key required")
 	}
@@ -125,7 +129,7 @@ func bearerToken(authz string) (string, bool) {
 	return parts[1], true
 }
 
-func newAuthMiddleware(basePath string, cfg AuthConfig, r repo.Repo) func(http.Handler) http.Handler {
+func newAuthMiddleware(basePath string, cfg AuthConfig, r repo.Repository) func(http.Handler) http.Handler {
 	healthPath := path.Join(basePath, "health")
 	openapiPath := path.Join(basePath, "openapi.json")
 	devLoginPath := path.Join(basePath, "auth/dev/login")
@@ -151,6 +155,12 @@ func newAuthMiddleware(basePath string, cfg AuthConfig, r repo.Repo) func(http.H
 
 			authz := strings.TrimSpace(req.Header.Get("Authorization"))
 			apiKeyHeader := strings.TrimSpace(req.Header.Get("X-Api-Key"))
+			if authz == "" && apiKeyHeader == "" && isCalendarPath(req.URL.Path) && req.URL.Query().Get(calendarTokenParam) != "" {
+				// Calendar feed tokens are not API keys: the handler checks
+				// them against the project's feeds.
+				next.ServeHTTP(w, req)
+				return
+			}
 
 			if authz != "" {
 				token, ok := bearerToken(authz)
@@ -163,6 +173,14 @@ func newAuthMiddleware(basePath string, cfg AuthConfig, r repo.Repo) func(http.H
 					respondStatusError(w, newAPIError(http.StatusUnauthorized, "invalid_credentials", "invalid credentials", nil))
 					return
 				}
+				if err := checkActorActive(req.Context(), r, principal.ActorID); err != nil {
+					respondStatusError(w, err)
+					return
+				}
+				if orgScopeViolation(req.Context(), r, basePath, req.URL.Path, principal.OrgID) {
+					respondStatusError(w, newAPIError(http.StatusForbidden, "org_mismatch", "token org does not match resource org", map[string]any{"org_id": principal.OrgID}))
+					return
+				}
 				ctx := withPrincipal(req.Context(), principal)
 				next.ServeHTTP(w, req.WithContext(ctx))
 				return
@@ -174,16 +192,91 @@ func newAuthMiddleware(basePath string, cfg AuthConfig, r repo.Repo) func(http.H
 					respondStatusError(w, newAPIError(http.StatusUnauthorized, "invalid_credentials", "invalid credentials", nil))
 					return
 				}
+				if err := checkActorActive(req.Context(), r, principal.ActorID); err != nil {
+					respondStatusError(w, err)
+					return
+				}
 				ctx := withPrincipal(req.Context(), principal)
 				next.ServeHTTP(w, req.WithContext(ctx))
 				return
 			}
 
+			if actorID := certActor(req.TLS, cfg.ClientCertActor); actorID != "" {
+				if err := checkActorActive(req.Context(), r, actorID); err != nil {
+					respondStatusError(w, err)
+					return
+				}
+				ctx := withPrincipal(req.Context(), Principal{ActorID: actorID, Source: "client_cert"})
+				next.ServeHTTP(w, req.WithContext(ctx))
+				return
+			}
+
 			respondStatusError(w, newAPIError(http.StatusUnauthorized, "unauthorized", "authentication required", nil))
 		})
 	}
 }
 
+// actorSuspended reports whether a known actor has been deactivated or
+// scrubbed. Actors not yet registered are created on first use and are
+// therefore active; any other lookup error is returned so callers fail
+// closed.
+func actorSuspended(ctx context.Context, r repo.Repository, actorID string) (bool, error) {
+	status, err := r.ActorStatus(ctx, actorID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return status == "suspended" || status == "scrubbed", nil
+}
+
+// checkActorActive returns the error to answer with when actorID may not
+// authenticate: 401 when suspended, 500 when its status cannot be read.
+func checkActorActive(ctx context.Context, r repo.Repository, actorID string) huma.StatusError {
+	suspended, err := actorSuspended(ctx, r, actorID)
+	if err != nil {
+		return newAPIError(http.StatusInternalServerError, "internal_error", "internal error", nil)
+	}
+	if suspended {
+		return newAPIError(http.StatusUnauthorized, "actor_suspended", "actor is suspended", nil)
+	}
+	return nil
+}
+
+// orgScopeViolation reports whether a JWT org claim targets a project or org
+// belonging to a different org. Unknown projects are left to the handlers.
+func orgScopeViolation(ctx context.Context, r repo.Repository, basePath, urlPath, orgID string) bool {
+	if orgID == "" {
+		return false
+	}
+	rel := strings.Trim(strings.TrimPrefix(urlPath, basePath), "/")
+	segments := strings.Split(rel, "/")
+	if len(segments) < 2 || segments[1] == "" {
+		return false
+	}
+	switch segments[0] {
+	case "orgs":
+		return segments[1] != orgID
+	case "projects":
+		return projectOrgMismatch(ctx, r, segments[1], orgID)
+	}
+	return false
+}
+
+// projectOrgMismatch reports whether a known project belongs to an org other
+// than orgID.
+func projectOrgMismatch(ctx context.Context, r repo.Repository, projectID, orgID string) bool {
+	if orgID == "" {
+		return false
+	}
+	p, err := r.GetProject(ctx, projectID)
+	if err != nil {
+		return false
+	}
+	return p.OrgID != orgID
+}
+
 func respondStatusError(w http.ResponseWriter, err huma.StatusError) {
 	status := http.StatusInternalServerError
 	if e, ok := err.(interface{ GetStatus() int }); ok {
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"time"
+
+	"workline/internal/db"
+	"workline/internal/engine"
+)
+
+// BackupConfig enables periodic database snapshots while serving.
+type BackupConfig struct {
+	Dir      string
+	Interval time.Duration
+	// Keep is how many snapshots to retain; 0 keeps all.
+	Keep int
+}
+
+func startBackups(e engine.Engine, cfg BackupConfig) {
+	if cfg.Dir == "" || cfg.Interval <= 0 || e.DB == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runBackup(e, cfg)
+		}
+	}()
+}
+
+func runBackup(e engine.Engine, cfg BackupConfig) {
+	out := filepath.Join(cfg.Dir, db.BackupName(time.Now()))
+	if err := db.Backup(context.Background(), e.DB, out); err != nil {
+		log.Printf("backup: %v", err)
+		return
+	}
+	if err := db.PruneBackups(cfg.Dir, cfg.Keep); err != nil {
+		log.Printf("backup: prune %s failed: %v", cfg.Dir, err)
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+)
+
+// calendarTokenParam is the query parameter calendar clients, which cannot
+// send headers, use to pass a calendar feed token. API keys are never read
+// from it.
+const calendarTokenParam = "token"
+
+func isCalendarPath(p string) bool {
+	return strings.HasSuffix(p, "/calendar.ics")
+}
+
+func registerCalendar(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "project-calendar",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/calendar.ics",
+		Summary:     "Iteration and milestone calendar (iCalendar)",
+		Description: "Subscribe from a calendar client with ?token=<feed token>, a token from POST /projects/{project_id}/calendar/feeds that reads this calendar only. API keys are not accepted in the query string.",
+		Errors: []int{
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Token     string `query:"token" doc:"Calendar feed token, for clients that cannot send headers"`
+	}) (*struct {
+		ContentType string `header:"Content-Type"`
+		Body        []byte
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		var entries []domain.CalendarEntry
+		var err error
+		if _, ok := principalFromContext(ctx); !ok && input.Token != "" {
+			entries, err = e.FeedCalendar(ctx, projectID, input.Token)
+			if errors.Is(err, engine.ErrInvalidFeedToken) {
+				return nil, newAPIError(http.StatusUnauthorized, "invalid_credentials", "invalid credentials", nil)
+			}
+		} else {
+			actorID, authErr := actorIDFromContext(ctx)
+			if authErr != nil {
+				return nil, authErr
+			}
+			entries, err = e.Calendar(ctx, projectID, actorID)
+		}
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			ContentType string `header:"Content-Type"`
+			Body        []byte
+		}{
+			ContentType: "text/calendar; charset=utf-8",
+			Body:        renderICS(projectID, entries, time.Now()),
+		}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-calendar-feed",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/calendar/feeds",
+		Summary:       "Create calendar feed token",
+		Description:   "Returns a token that reads the calendar feed as the caller and nothing else. It is shown once; subscribe to calendar.ics?token=<token>.",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                    `path:"project_id"`
+		Body      CreateCalendarFeedRequest `json:"body"`
+	}) (*struct {
+		Body CalendarFeedResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		feed, err := e.CreateCalendarFeed(ctx, projectID, actorID, input.Body.Name)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body CalendarFeedResponse `json:"body"`
+		}{Body: calendarFeedResponse(feed)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-calendar-feeds",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/calendar/feeds",
+		Summary:     "List calendar feed tokens",
+		Description: "Lists the caller's feeds, or every feed of the project with rbac.manage. Tokens are not returned.",
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body []CalendarFeedResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		feeds, err := e.CalendarFeeds(ctx, projectID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]CalendarFeedResponse, 0, len(feeds))
+		for _, f := range feeds {
+			resp = append(resp, calendarFeedResponse(f))
+		}
+		return &struct {
+			Body []CalendarFeedResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "revoke-calendar-feed",
+		Method:      http.MethodDelete,
+		Path:        "/projects/{project_id}/calendar/feeds/{feed_id}",
+		Summary:     "Revoke calendar feed token",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		FeedID    string `path:"feed_id"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := e.RevokeCalendarFeed(ctx, projectID, actorID, input.FeedID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
+	})
+}
+
+// renderICS writes entries as an RFC 5545 calendar of all-day events. UIDs
+// are stable per entity so clients update events in place on refresh.
+func renderICS(projectID string, entries []domain.CalendarEntry, now time.Time) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldICSLine(s))
+		b.WriteString("\r\n")
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Workline//Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escapeICSText(projectID))
+	for _, en := range entries {
+		start, err := time.Parse(time.DateOnly, en.Start)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(time.DateOnly, en.End)
+		if err != nil || end.Before(start) {
+			end = start
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + escapeICSText(en.Kind+"-"+en.EntityID+"@"+projectID+".workline"))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		// DTEND is exclusive for all-day events.
+		line("DTEND;VALUE=DATE:" + end.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeICSText(en.Summary))
+		line("CATEGORIES:" + en.Kind)
+		if en.Status != "" {
+			desc := "Status: " + en.Status
+			if en.Ongoing {
+				desc += " (ongoing)"
+			}
+			line("DESCRIPTION:" + escapeICSText(desc))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine splits content lines longer than 75 octets, continuing them
+// with a leading space, without breaking UTF-8 sequences.
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/engine"
+)
+
+func registerChangelog(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "iteration-changelog",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/iterations/{id}/changelog",
+		Summary:     "Iteration changelog",
+		Description: "Done tasks of the iteration grouped by task type, with the summary (summary, notes or description) and http(s) links found in their work outcomes, as Markdown for release notes or as JSON. Needs iteration.list and task.list.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+		Format    string `query:"format" enum:"md,json" default:"md"`
+	}) (*struct {
+		ContentType string `header:"Content-Type"`
+		Body        []byte
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		it, err := e.Repo.GetIteration(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, it.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
+		}
+		c, err := e.Changelog(ctx, it.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := &struct {
+			ContentType string `header:"Content-Type"`
+			Body        []byte
+		}{ContentType: "text/markdown; charset=utf-8", Body: []byte(engine.ChangelogMarkdown(c))}
+		if input.Format == "json" {
+			body, err := json.Marshal(c)
+			if err != nil {
+				return nil, handleError(err)
+			}
+			resp.ContentType, resp.Body = "application/json", body
+		}
+		return resp, nil
+	})
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/events"
+)
+
+// changesPoll is how often a waiting changes request looks for new events.
+var changesPoll = 500 * time.Millisecond
+
+const (
+	changesBatch   = 500
+	changesMaxWait = 60 * time.Second
+)
+
+func registerChanges(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-changes",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/changes",
+		Summary:     "Wait for changes",
+		Description: "Long-polls the project's events after `since` and returns the entities they touched, one entry per entity, instead of the events themselves. With `wait`, the request is held until something changes or the wait runs out (at most 60s); pass the returned `cursor` as the next `since`. Without `since`, returns the current cursor right away. Audit events count only for callers holding events.audit.read.",
+		Errors:      []int{http.StatusBadRequest, http.StatusForbidden},
+	}, func(ctx context.Context, input *struct {
+		ProjectID  string   `path:"project_id"`
+		Since      string   `query:"since" doc:"Cursor from a previous response; events after it count"`
+		Wait       string   `query:"wait" doc:"How long to wait for a change, as a Go duration such as 30s" example:"30s"`
+		EntityKind []string `query:"entity_kind" doc:"Only changes to these entity kinds; repeat or comma-separate"`
+	}) (*struct {
+		Body ChangesResponse `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "project.events.read"); err != nil {
+			return nil, handleError(err)
+		}
+		if err := auditRead(ctx, e, projectID, "events", ""); err != nil {
+			return nil, handleError(err)
+		}
+		var wait time.Duration
+		if input.Wait != "" {
+			d, err := time.ParseDuration(input.Wait)
+			if err != nil || d < 0 {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid wait: use a duration such as 30s", map[string]any{"wait": input.Wait})
+			}
+			wait = min(d, changesMaxWait)
+		}
+		audit, err := canReadAuditEvents(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		kinds := splitQueryValues(input.EntityKind)
+		relevant := func(ev domain.Event) bool {
+			if !audit && ev.EntityKind == events.AuditEntityKind {
+				return false
+			}
+			return len(kinds) == 0 || slices.Contains(kinds, ev.EntityKind)
+		}
+		resp := ChangesResponse{Changes: []ChangeResponse{}}
+		if input.Since == "" {
+			if resp.Cursor, err = e.Repo.LatestEventID(ctx, projectID); err != nil {
+				return nil, handleError(err)
+			}
+			return &struct {
+				Body ChangesResponse `json:"body"`
+			}{Body: resp}, nil
+		}
+		if resp.Cursor, err = strconv.ParseInt(input.Since, 10, 64); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid since", map[string]any{"since": input.Since})
+		}
+		deadline := time.Now().Add(wait)
+		ticker := time.NewTicker(changesPoll)
+		defer ticker.Stop()
+		for {
+			batch, err := e.Repo.EventsAfter(ctx, changesBatch, resp.Cursor, projectID)
+			if err != nil {
+				return nil, handleError(err)
+			}
+			for _, ev := range batch {
+				resp.Cursor = ev.ID
+				if relevant(ev) {
+					resp.Changes = addChange(resp.Changes, ev)
+				}
+			}
+			if len(resp.Changes) > 0 || len(batch) == changesBatch || !time.Now().Before(deadline) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		return &struct {
+			Body ChangesResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+// addChange folds ev into the entry of the entity it touched.
+func addChange(changes []ChangeResponse, ev domain.Event) []ChangeResponse {
+	for i := range changes {
+		c := &changes[i]
+		if c.EntityKind == ev.EntityKind && c.EntityID == ev.EntityID {
+			c.EventID = ev.ID
+			if !slices.Contains(c.Types, ev.Type) {
+				c.Types = append(c.Types, ev.Type)
+			}
+			return changes
+		}
+	}
+	return append(changes, ChangeResponse{EntityKind: ev.EntityKind, EntityID: ev.EntityID, EventID: ev.ID, Types: []string{ev.Type}})
+}
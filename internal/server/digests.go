@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"workline/internal/engine"
+)
+
+func startDigests(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.Config.Digest.Schedule == "" || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runDigest(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runDigest(e engine.Engine) {
+	sent, err := e.SendDueDigest(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("digest: %v", err)
+		return
+	}
+	if sent {
+		log.Printf("digest: sent to %d recipient(s)", len(e.Config.Digest.Recipients))
+	}
+}
@@ -12,9 +12,23 @@ import (
 type CreateProjectRequest struct {
 	ID          string  `json:"id"`
 	OrgID       string  `json:"org_id"`
+	Kind        string  `json:"kind,omitempty" enum:"software,research,ops,content" doc:"Project kind; picks the starter config. Defaults to software."`
 	Description *string `json:"description,omitempty"`
 }
 
+type CreateOrgRequest struct {
+	ID   string `json:"id" example:"acme"`
+	Name string `json:"name,omitempty" example:"Acme Corp"`
+}
+
+type CreateCalendarFeedRequest struct {
+	Name string `json:"name,omitempty" example:"team calendar"`
+}
+
+type OrgMemberRequest struct {
+	Role string `json:"role" enum:"owner,admin,member" example:"member"`
+}
+
 type TaskValidationRequest struct {
 	Require []string `json:"require,omitempty" example:"[\"ci.passed\",\"review.approved\"]"`
 }
@@ -23,19 +37,32 @@ type TaskPolicyRequest struct {
 	Preset string `json:"preset,omitempty" example:"done"`
 }
 
+type SimulatePolicyRequest struct {
+	Type      string  `json:"type" example:"feature"`
+	Component *string `json:"component,omitempty" example:"api"`
+	// Policy is the preset the task would be created with; omitted uses the
+	// task type's default.
+	Policy       *string  `json:"policy,omitempty" example:"done"`
+	Attestations []string `json:"attestations,omitempty" doc:"Attestation kinds assumed recorded" example:"[\"ci.passed\"]"`
+}
+
 type CreateTaskRequest struct {
 	ID           *string                `json:"id,omitempty" example:"task-auth-1"`
 	IterationID  *string                `json:"iteration_id,omitempty" example:"iter-1"`
 	ParentID     *string                `json:"parent_id,omitempty" example:"task-epic"`
 	Type         string                 `json:"type" example:"feature"`
+	Component    *string                `json:"component,omitempty" example:"api"`
 	Title        string                 `json:"title" example:"Ship authentication"`
 	Description  *string                `json:"description,omitempty" example:"Implement login and SSO flows"`
 	AssigneeID   *string                `json:"assignee_id,omitempty" example:"dev-1"`
 	Priority     *int                   `json:"priority,omitempty" example:"1"`
+	Estimate     *float64               `json:"estimate,omitempty" example:"3"`
+	DueAt        *string                `json:"due_at,omitempty" example:"2024-05-10T17:00:00Z" doc:"RFC3339, or YYYY-MM-DD for the end of that day (UTC); defaults from project.sla"`
 	DependsOn    []string               `json:"depends_on,omitempty" example:"[\"task-seed\"]"`
 	Policy       *TaskPolicyRequest     `json:"policy,omitempty"`
 	Validation   *TaskValidationRequest `json:"validation,omitempty"`
 	WorkOutcomes map[string]any         `json:"work_outcomes,omitempty" example:"{\"pr\":123}"`
+	ExternalRefs map[string]string      `json:"external_refs,omitempty" example:"{\"jira\":\"ABC-123\"}" doc:"Ids of the task in external systems, keyed by system"`
 }
 
 type SubtaskRequest struct {
@@ -74,14 +101,32 @@ type UpdateTaskValidationRequest struct {
 }
 
 type UpdateTaskRequest struct {
-	Status          *string                      `json:"status,omitempty" enum:"planned,ready,in_progress,review,done,rejected,canceled"`
+	Status          *string                      `json:"status,omitempty"`
 	AssigneeID      *string                      `json:"assignee_id,omitempty"`
 	AddDependsOn    []string                     `json:"add_depends_on,omitempty"`
 	RemoveDependsOn []string                     `json:"remove_depends_on,omitempty"`
 	ParentID        *string                      `json:"parent_id,omitempty"`
 	Priority        *int                         `json:"priority,omitempty"`
+	Estimate        *float64                     `json:"estimate,omitempty"`
+	DueAt           *string                      `json:"due_at,omitempty" doc:"RFC3339 or YYYY-MM-DD; null or empty clears it"`
+	IterationID     *string                      `json:"iteration_id,omitempty"`
+	Component       *string                      `json:"component,omitempty" doc:"Component from the config registry; empty removes the task from its component"`
 	WorkOutcomes    *map[string]any              `json:"work_outcomes,omitempty"`
 	Validation      *UpdateTaskValidationRequest `json:"validation,omitempty"`
+	ExternalRefs    map[string]string            `json:"external_refs,omitempty" doc:"External ids to set, keyed by system; an empty id removes the system's id"`
+}
+
+// BulkUpdateTasksRequest applies one change set to several tasks. Omitted
+// fields are left unchanged; a null or empty iteration_id or assignee_id
+// clears it.
+type BulkUpdateTasksRequest struct {
+	TaskIDs     []string `json:"task_ids" minItems:"1" maxItems:"200"`
+	Status      *string  `json:"status,omitempty"`
+	IterationID *string  `json:"iteration_id,omitempty"`
+	AssigneeID  *string  `json:"assignee_id,omitempty"`
+	Force       bool     `json:"force,omitempty"`
+	// Atomic applies all tasks or none.
+	Atomic bool `json:"atomic,omitempty"`
 }
 
 type CompleteTaskRequest struct {
@@ -89,23 +134,172 @@ type CompleteTaskRequest struct {
 }
 
 type WorkOutcomesAppendRequest struct {
-	Path  string `json:"path"`
+	Path  string `json:"path" doc:"Top-level key, dotted path with indexes (results.tests[0].status) or JSON pointer"`
 	Value any    `json:"value"`
 }
 
 type WorkOutcomesPutRequest struct {
-	Path  string `json:"path"`
+	Path  string `json:"path" doc:"Top-level key, dotted path with indexes (results.tests[0].status) or JSON pointer"`
 	Value any    `json:"value"`
 }
 
 type WorkOutcomesMergeRequest struct {
-	Path  string         `json:"path"`
+	Path  string         `json:"path" doc:"Top-level key, dotted path with indexes (results.tests[0].status) or JSON pointer"`
 	Value map[string]any `json:"value"`
 }
 
 type CreateIterationRequest struct {
-	ID   string `json:"id"`
-	Goal string `json:"goal"`
+	ID       string   `json:"id,omitempty" doc:"Generated by the project's id strategy when omitted"`
+	Goal     string   `json:"goal"`
+	Capacity *float64 `json:"capacity,omitempty"`
+}
+
+type SetIterationCapacityRequest struct {
+	Capacity *float64 `json:"capacity"`
+}
+
+type IterationProgressResponse struct {
+	IterationID     string   `json:"iteration_id"`
+	Unit            string   `json:"unit" enum:"points,hours"`
+	Capacity        *float64 `json:"capacity,omitempty"`
+	PlannedPoints   float64  `json:"planned_points"`
+	CompletedPoints float64  `json:"completed_points"`
+	OverCapacity    bool     `json:"over_capacity"`
+}
+
+type CreateMilestoneRequest struct {
+	ID           string   `json:"id" example:"m-q3"`
+	Goal         string   `json:"goal" example:"Public beta"`
+	TargetDate   string   `json:"target_date" format:"date" example:"2026-09-30"`
+	IterationIDs []string `json:"iteration_ids,omitempty" example:"[\"iter-1\",\"iter-2\"]"`
+	TaskIDs      []string `json:"task_ids,omitempty"`
+}
+
+type LinkMilestoneRequest struct {
+	IterationIDs []string `json:"iteration_ids,omitempty"`
+	TaskIDs      []string `json:"task_ids,omitempty"`
+}
+
+type MilestoneResponse struct {
+	ID           string   `json:"id"`
+	ProjectID    string   `json:"project_id"`
+	Goal         string   `json:"goal"`
+	TargetDate   string   `json:"target_date" format:"date"`
+	IterationIDs []string `json:"iteration_ids"`
+	TaskIDs      []string `json:"task_ids"`
+	CreatedAt    string   `json:"created_at" format:"date-time"`
+}
+
+type MilestoneProgressResponse struct {
+	MilestoneID         string  `json:"milestone_id"`
+	Goal                string  `json:"goal"`
+	TargetDate          string  `json:"target_date" format:"date"`
+	TotalTasks          int     `json:"total_tasks"`
+	DoneTasks           int     `json:"done_tasks"`
+	Percent             float64 `json:"percent" example:"62.5"`
+	PlannedPoints       float64 `json:"planned_points"`
+	CompletedPoints     float64 `json:"completed_points"`
+	Iterations          int     `json:"iterations"`
+	ValidatedIterations int     `json:"validated_iterations"`
+	DaysLeft            int     `json:"days_left"`
+	Overdue             bool    `json:"overdue"`
+}
+
+type CreateReleaseRequest struct {
+	Name     string `json:"name" example:"workline-cli"`
+	Version  string `json:"version" example:"1.4.0"`
+	Checksum string `json:"checksum,omitempty" example:"sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" doc:"<algorithm>:<hex digest> of the artifact"`
+	URL      string `json:"url,omitempty" format:"uri" example:"https://downloads.example.com/workline-cli-1.4.0.tar.gz"`
+}
+
+type ReleaseResponse struct {
+	ID          string `json:"id"`
+	ProjectID   string `json:"project_id"`
+	IterationID string `json:"iteration_id"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Checksum    string `json:"checksum,omitempty"`
+	URL         string `json:"url,omitempty"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   string `json:"created_at" format:"date-time"`
+}
+
+type ForceRequestResponse struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	Operation   string  `json:"operation" example:"task.done"`
+	EntityKind  string  `json:"entity_kind"`
+	EntityID    string  `json:"entity_id"`
+	Status      string  `json:"status" enum:"pending,approved"`
+	RequestedBy string  `json:"requested_by"`
+	RequestedAt string  `json:"requested_at" format:"date-time"`
+	ApprovedBy  *string `json:"approved_by,omitempty"`
+	ApprovedAt  *string `json:"approved_at,omitempty" format:"date-time"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID          string  `json:"id"`
+	ProjectID   string  `json:"project_id"`
+	URL         string  `json:"url"`
+	EventID     int64   `json:"event_id" doc:"0 for test deliveries"`
+	EventType   string  `json:"event_type" example:"task.updated"`
+	StatusCode  int     `json:"status_code" doc:"HTTP status of the reply, 0 when none came back"`
+	Error       string  `json:"error,omitempty"`
+	DurationMS  int64   `json:"duration_ms"`
+	AttemptedAt string  `json:"attempted_at" format:"date-time"`
+	ReplayOf    *string `json:"replay_of,omitempty" doc:"Delivery this attempt replayed"`
+}
+
+type BoardResponse struct {
+	ProjectID string                `json:"project_id"`
+	StrictWIP bool                  `json:"strict_wip"`
+	Columns   []BoardColumnResponse `json:"columns"`
+}
+
+type BoardColumnResponse struct {
+	Status    string         `json:"status"`
+	WIPLimit  *int           `json:"wip_limit,omitempty" doc:"Most tasks the status may hold, when configured"`
+	Count     int            `json:"count"`
+	OverLimit bool           `json:"over_limit"`
+	Tasks     []TaskResponse `json:"tasks"`
+}
+
+type EscalationResponse struct {
+	TaskID     string  `json:"task_id"`
+	Title      string  `json:"title"`
+	Type       string  `json:"type"`
+	Status     string  `json:"status"`
+	AssigneeID *string `json:"assignee_id,omitempty"`
+	From       int     `json:"from" doc:"Current priority"`
+	To         int     `json:"to" doc:"Priority after escalation"`
+	Since      string  `json:"since" format:"date-time" doc:"When the task was created or last escalated"`
+	AfterDays  int     `json:"after_days" doc:"Days between escalations under the matching rule"`
+}
+
+type ConfigVersionResponse struct {
+	ProjectID string                 `json:"project_id"`
+	Version   int                    `json:"version" example:"3"`
+	ActorID   string                 `json:"actor_id,omitempty"`
+	CreatedAt string                 `json:"created_at" format:"date-time"`
+	Changes   []ConfigChangeResponse `json:"changes,omitempty"`
+}
+
+type ConfigChangeResponse struct {
+	Path string `json:"path" example:"project.task_types.bug.policies.done.all"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+	// Redacted is set when secrets, passwords or keys were left out of from and to.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+type ConfigDiffResponse struct {
+	From    int                    `json:"from"`
+	To      int                    `json:"to"`
+	Changes []ConfigChangeResponse `json:"changes"`
+}
+
+type RollbackConfigRequest struct {
+	Version int `json:"version" minimum:"1" example:"2"`
 }
 
 type SetIterationStatusRequest struct {
@@ -113,7 +307,7 @@ type SetIterationStatusRequest struct {
 }
 
 type CreateDecisionRequest struct {
-	ID           string         `json:"id" example:"dec-1"`
+	ID           string         `json:"id,omitempty" example:"dec-1" doc:"Generated by the project's id strategy when omitted"`
 	Title        string         `json:"title" example:"Choose runtime"`
 	Decision     string         `json:"decision" example:"Adopt Go for backend"`
 	DeciderID    string         `json:"decider_id" example:"cto-1"`
@@ -131,6 +325,10 @@ type CreateAttestationRequest struct {
 	Payload    map[string]any `json:"payload,omitempty" example:"{\"note\":\"LGTM\"}"`
 }
 
+type BatchAttestationRequest struct {
+	Attestations []CreateAttestationRequest `json:"attestations" minItems:"1" maxItems:"100"`
+}
+
 type ActorMissionRequest struct {
 	Mission string `json:"mission"`
 }
@@ -145,6 +343,46 @@ type ValidationRequest struct {
 
 // Response payloads
 
+// CalendarFeedResponse is a calendar feed token; Token is only set when the
+// feed is created.
+type CalendarFeedResponse struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	ActorID   string `json:"actor_id"`
+	Name      string `json:"name,omitempty"`
+	Token     string `json:"token,omitempty"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+type OrgResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+// OrgStatusResponse is the portfolio status of an org: one line per active
+// project the caller may read.
+type OrgStatusResponse struct {
+	OrgID    string                    `json:"org_id"`
+	Projects []ProjectOverviewResponse `json:"projects"`
+}
+
+type ProjectOverviewResponse struct {
+	ProjectID     string         `json:"project_id" example:"workline"`
+	Status        string         `json:"status" example:"active"`
+	Iteration     *string        `json:"iteration,omitempty" example:"iter-3"`
+	IterationGoal string         `json:"iteration_goal,omitempty" example:"Ship SSO"`
+	Tasks         int            `json:"tasks" example:"12"`
+	TaskCounts    map[string]int `json:"task_counts" example:"{\"in_progress\":4,\"done\":8}"`
+	Blocked       int            `json:"blocked" doc:"Open tasks waiting on unfinished dependencies" example:"1"`
+}
+
+type OrgMemberResponse struct {
+	OrgID   string `json:"org_id"`
+	ActorID string `json:"actor_id"`
+	Role    string `json:"role"`
+}
+
 type ProjectResponse struct {
 	ID          string `json:"id"`
 	OrgID       string `json:"org_id"`
@@ -155,35 +393,73 @@ type ProjectResponse struct {
 }
 
 type IterationResponse struct {
-	ID        string `json:"id"`
-	ProjectID string `json:"project_id"`
-	Goal      string `json:"goal"`
-	Status    string `json:"status" enum:"pending,running,delivered,validated,rejected"`
-	CreatedAt string `json:"created_at" format:"date-time"`
+	ID        string   `json:"id"`
+	ProjectID string   `json:"project_id"`
+	Goal      string   `json:"goal"`
+	Status    string   `json:"status" enum:"pending,running,delivered,validated,rejected"`
+	Capacity  *float64 `json:"capacity,omitempty"`
+	CreatedAt string   `json:"created_at" format:"date-time"`
 }
 
 type TaskResponse struct {
-	ID                   string         `json:"id" example:"task-auth-1"`
-	ProjectID            string         `json:"project_id" example:"workline"`
-	IterationID          *string        `json:"iteration_id,omitempty" example:"iter-1"`
-	ParentID             *string        `json:"parent_id,omitempty" example:"task-epic"`
-	Type                 string         `json:"type" example:"feature"`
-	Title                string         `json:"title" example:"Ship authentication"`
-	Description          string         `json:"description,omitempty" example:"Implement login and SSO flows"`
-	Status               string         `json:"status" enum:"planned,ready,in_progress,review,done,rejected,canceled" example:"planned"`
-	AssigneeID           *string        `json:"assignee_id,omitempty" example:"dev-1"`
-	Priority             *int           `json:"priority,omitempty" example:"1"`
-	WorkOutcomes         map[string]any `json:"work_outcomes,omitempty" example:"{\"pr\":123}"`
-	RequiredAttestations []string       `json:"required_attestations" example:"[\"ci.passed\",\"review.approved\"]"`
-	DependsOn            []string       `json:"depends_on" example:"[]"`
-	CreatedAt            string         `json:"created_at" format:"date-time" example:"2024-05-01T09:00:00Z"`
-	UpdatedAt            string         `json:"updated_at" format:"date-time" example:"2024-05-01T09:05:00Z"`
-	CompletedAt          *string        `json:"completed_at" format:"date-time" example:"2024-05-02T10:00:00Z"`
+	ID                   string                 `json:"id" example:"task-auth-1"`
+	ProjectID            string                 `json:"project_id" example:"workline"`
+	IterationID          *string                `json:"iteration_id,omitempty" example:"iter-1"`
+	ParentID             *string                `json:"parent_id,omitempty" example:"task-epic"`
+	Type                 string                 `json:"type" example:"feature"`
+	Component            string                 `json:"component,omitempty" example:"api"`
+	Title                string                 `json:"title" example:"Ship authentication"`
+	Description          string                 `json:"description,omitempty" example:"Implement login and SSO flows"`
+	Status               string                 `json:"status" example:"planned"`
+	AssigneeID           *string                `json:"assignee_id,omitempty" example:"dev-1"`
+	Priority             *int                   `json:"priority,omitempty" example:"1"`
+	Estimate             *float64               `json:"estimate,omitempty" example:"3"`
+	WorkOutcomes         map[string]any         `json:"work_outcomes,omitempty" example:"{\"pr\":123}"`
+	RequiredAttestations []string               `json:"required_attestations" example:"[\"ci.passed\",\"review.approved\"]"`
+	DependsOn            []string               `json:"depends_on" example:"[]"`
+	Relations            []TaskRelationResponse `json:"relations,omitempty" doc:"Typed links the task is the subject of"`
+	ExternalRefs         map[string]string      `json:"external_refs,omitempty" example:"{\"jira\":\"ABC-123\",\"github\":\"org/repo#45\"}"`
+	CreatedAt            string                 `json:"created_at" format:"date-time" example:"2024-05-01T09:00:00Z"`
+	UpdatedAt            string                 `json:"updated_at" format:"date-time" example:"2024-05-01T09:05:00Z"`
+	CompletedAt          *string                `json:"completed_at" format:"date-time" example:"2024-05-02T10:00:00Z"`
+	DueAt                *string                `json:"due_at,omitempty" format:"date-time" example:"2024-05-10T17:00:00Z"`
+	Stale                bool                   `json:"stale,omitempty" doc:"No update for project.staleness.days; cleared by the next update"`
+	TimeSpentMinutes     *int                   `json:"time_spent_minutes,omitempty" example:"90"`
+	Warnings             []string               `json:"warnings,omitempty"`
+}
+
+type TaskRelationResponse struct {
+	TaskID        string `json:"task_id"`
+	Type          string `json:"type" enum:"blocks,relates_to,duplicates,caused_by"`
+	RelatedTaskID string `json:"related_task_id"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+	Closed        string `json:"closed,omitempty" doc:"Status the duplicate task was closed into by this request"`
+}
+
+type TaskGraphResponse struct {
+	ProjectID string                  `json:"project_id"`
+	Nodes     []TaskGraphNodeResponse `json:"nodes"`
+	Edges     []TaskGraphEdgeResponse `json:"edges"`
+}
+
+type TaskGraphNodeResponse struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type TaskGraphEdgeResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type" enum:"subtask_of,depends_on,blocks,relates_to,duplicates,caused_by"`
 }
 
 type DecisionResponse struct {
 	ID           string         `json:"id"`
 	ProjectID    string         `json:"project_id"`
+	Number       int            `json:"number" doc:"Position among the project's decisions, naming its ADR file"`
 	Title        string         `json:"title"`
 	Decision     string         `json:"decision"`
 	DeciderID    string         `json:"decider_id"`
@@ -200,6 +476,11 @@ type LeaseResponse struct {
 	ExpiresAt  string `json:"expires_at" format:"date-time"`
 }
 
+type ClaimNextResponse struct {
+	Task  TaskResponse  `json:"task"`
+	Lease LeaseResponse `json:"lease"`
+}
+
 type WorkOutcomesUpdateResponse struct {
 	Path         string         `json:"path"`
 	WorkOutcomes map[string]any `json:"work_outcomes"`
@@ -215,6 +496,36 @@ type AttestationResponse struct {
 	ActorID    string         `json:"actor_id"`
 	TS         string         `json:"ts" format:"date-time"`
 	Payload    map[string]any `json:"payload,omitempty"`
+	ExpiredAt  *string        `json:"expired_at,omitempty" format:"date-time"`
+}
+
+// BatchAttestationResult is the outcome of one item of a batch, at the same
+// index as in the request.
+type BatchAttestationResult struct {
+	Index       int                  `json:"index"`
+	Status      int                  `json:"status"`
+	Attestation *AttestationResponse `json:"attestation,omitempty"`
+	Error       *apiErrorBody        `json:"error,omitempty"`
+}
+
+// BulkTaskResult is the outcome for one task of a bulk update.
+type BulkTaskResult struct {
+	TaskID string        `json:"task_id"`
+	Status int           `json:"status"`
+	Task   *TaskResponse `json:"task,omitempty"`
+	Error  *apiErrorBody `json:"error,omitempty"`
+}
+
+type BulkUpdateTasksResponse struct {
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+type BatchAttestationResponse struct {
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Results []BatchAttestationResult `json:"results"`
 }
 
 type EventResponse struct {
@@ -222,16 +533,40 @@ type EventResponse struct {
 	TS         string         `json:"ts" format:"date-time"`
 	Type       string         `json:"type"`
 	ProjectID  string         `json:"project_id,omitempty"`
-	EntityKind string         `json:"entity_kind" enum:"project,iteration,task,decision,rbac"`
+	EntityKind string         `json:"entity_kind" enum:"project,iteration,task,decision,rbac,milestone"`
 	EntityID   string         `json:"entity_id,omitempty"`
 	ActorID    string         `json:"actor_id"`
 	Payload    map[string]any `json:"payload"`
 }
 
+// EventChainHeadResponse is the latest hashed event of a project.
+type EventChainHeadResponse struct {
+	ProjectID string `json:"project_id"`
+	EventID   int64  `json:"event_id"`
+	TS        string `json:"ts,omitempty" format:"date-time"`
+	Hash      string `json:"hash" doc:"Hex SHA-256 chaining every earlier event"`
+}
+
+// ChangeResponse is one entity touched by events after the cursor.
+type ChangeResponse struct {
+	EntityKind string   `json:"entity_kind" enum:"project,iteration,task,decision,rbac,milestone"`
+	EntityID   string   `json:"entity_id,omitempty" example:"task-123"`
+	EventID    int64    `json:"event_id" doc:"Latest event touching the entity"`
+	Types      []string `json:"types" example:"[\"task.claimed\",\"task.updated\"]"`
+}
+
+type ChangesResponse struct {
+	Cursor  int64            `json:"cursor" doc:"Pass as since on the next request"`
+	Changes []ChangeResponse `json:"changes"`
+}
+
 type ValidationStatusResponse struct {
-	Required  []string `json:"required" example:"[\"ci.passed\",\"review.approved\"]"`
-	Present   []string `json:"present" example:"[\"ci.passed\"]"`
-	Missing   []string `json:"missing" example:"[\"review.approved\"]"`
+	Required []string `json:"required" example:"[\"ci.passed\",\"review.approved\"]"`
+	Present  []string `json:"present" example:"[\"ci.passed\"]"`
+	Missing  []string `json:"missing" example:"[\"review.approved\"]"`
+	// Expired lists missing kinds that were attested but whose attestations
+	// have all expired.
+	Expired   []string `json:"expired" example:"[\"security.ok\"]"`
 	Satisfied bool     `json:"satisfied" example:"false"`
 }
 
@@ -246,11 +581,18 @@ type projectConfigSection struct {
 	Attestations   []attestationConfigResponse            `json:"attestations"`
 	ActorMissions  []actorMissionConfigResponse           `json:"actor_missions,omitempty"`
 	Validation     validationConfigResponse               `json:"validation,omitempty"`
+	WorkOutcomes   workOutcomesConfigResponse             `json:"work_outcomes,omitempty"`
 	RBAC           rbacConfigResponse                     `json:"rbac"`
 }
 
 type taskTypeConfigResponse struct {
-	Policies map[string]policyRuleResponse `json:"policies"`
+	Policies           map[string]policyRuleResponse `json:"policies"`
+	WorkOutcomesSchema map[string]any                `json:"work_outcomes_schema,omitempty"`
+	DoneRoles          []string                      `json:"done_roles,omitempty" doc:"Only these roles may complete tasks of this type"`
+}
+
+type workOutcomesConfigResponse struct {
+	MaxBytes int `json:"max_bytes,omitempty"`
 }
 
 type iterationTypeConfigResponse struct {
@@ -265,6 +607,7 @@ type attestationConfigResponse struct {
 	ID          string `json:"id"`
 	Category    string `json:"category,omitempty"`
 	Description string `json:"description"`
+	ValidDays   int    `json:"valid_days,omitempty"`
 }
 
 type actorMissionConfigResponse struct {
@@ -308,9 +651,166 @@ type paginatedEvents struct {
 	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
+type ReadAuditEntryResponse struct {
+	ID       int64  `json:"id"`
+	TS       string `json:"ts" format:"date-time"`
+	ActorID  string `json:"actor_id"`
+	Resource string `json:"resource" enum:"config,events,attestations"`
+	Target   string `json:"target,omitempty" doc:"What was read, e.g. the request method and URI"`
+}
+
+type paginatedReadAudit struct {
+	Items      []ReadAuditEntryResponse `json:"items"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+}
+
 type RoleChangeRequest struct {
-	ActorID string `json:"actor_id"`
-	RoleID  string `json:"role_id"`
+	ActorID   string `json:"actor_id"`
+	RoleID    string `json:"role_id"`
+	ExpiresAt string `json:"expires_at,omitempty" doc:"Grant only: lapse the grant at this time (RFC3339, or YYYY-MM-DD for the end of that day in UTC); omit for a permanent grant"`
+}
+
+type CreateRoleRequest struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Grants      []string `json:"grants"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string  `json:"description,omitempty"`
+	Grants      []string `json:"grants,omitempty"`
+}
+
+type RoleResponse struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	ProjectID   string   `json:"project_id,omitempty" doc:"Project owning a custom role; empty for built-in roles"`
+	Permissions []string `json:"permissions"`
+}
+
+type LogTimeRequest struct {
+	Minutes int    `json:"minutes" example:"90"`
+	Note    string `json:"note,omitempty" example:"Pairing on login flow"`
+}
+
+type AddTaskRelationRequest struct {
+	Type          string `json:"type" enum:"blocks,relates_to,duplicates,caused_by" doc:"Read as \"<task> <type> <related_task_id>\""`
+	RelatedTaskID string `json:"related_task_id"`
+	Force         bool   `json:"force,omitempty" doc:"Close a duplicate leased by another actor"`
+}
+
+type PolicyCheckResponse struct {
+	TaskType  string `json:"task_type" example:"feature"`
+	Component string `json:"component,omitempty" example:"api"`
+	Policy    string `json:"policy,omitempty" example:"done"`
+	// Override is set when the task's requirements differ from the policy
+	// the config applies today.
+	Override bool     `json:"override,omitempty"`
+	Required []string `json:"required" example:"[\"ci.passed\",\"review.approved\"]"`
+	Present  []string `json:"present" example:"[\"ci.passed\"]"`
+	Missing  []string `json:"missing" example:"[\"review.approved\"]"`
+	Expired  []string `json:"expired" example:"[]"`
+	// Blockers are the other reasons done would be refused.
+	Blockers  []string `json:"blockers" example:"[\"dependency task-seed not done\"]"`
+	Satisfied bool     `json:"satisfied" example:"false"`
+}
+
+type ComplianceReportResponse struct {
+	ProjectID    string                    `json:"project_id"`
+	CheckedAt    string                    `json:"checked_at" format:"date-time"`
+	Checked      int                       `json:"checked" doc:"Completed tasks examined"`
+	Flagged      bool                      `json:"flagged" doc:"Whether a compliance.flagged event was recorded per non-compliant task"`
+	NonCompliant []ComplianceIssueResponse `json:"non_compliant"`
+}
+
+type ComplianceIssueResponse struct {
+	TaskID      string   `json:"task_id"`
+	Title       string   `json:"title"`
+	Type        string   `json:"type"`
+	CompletedAt string   `json:"completed_at" format:"date-time"`
+	Forced      bool     `json:"forced"`
+	Missing     []string `json:"missing" doc:"Required kinds without an attestation valid at completion" example:"[\"review.approved\"]"`
+	Tightened   []string `json:"tightened" doc:"Kinds the current policy adds that the task lacks" example:"[\"security.ok\"]"`
+}
+
+type TimeEntryResponse struct {
+	ID        string `json:"id"`
+	TaskID    string `json:"task_id"`
+	ActorID   string `json:"actor_id"`
+	Minutes   int    `json:"minutes"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"created_at" format:"date-time"`
+}
+
+type TaskHistoryEntryResponse struct {
+	TS       string         `json:"ts" format:"date-time"`
+	Category string         `json:"category" enum:"created,status,policy,lease,attestation,outcomes,assignment,update,validation,time,sla"`
+	Type     string         `json:"type"`
+	ActorID  string         `json:"actor_id,omitempty"`
+	Summary  string         `json:"summary"`
+	EventID  int64          `json:"event_id,omitempty"`
+	Payload  map[string]any `json:"payload,omitempty"`
+}
+
+type AssigneeSuggestionResponse struct {
+	ActorID      string   `json:"actor_id"`
+	OpenTasks    int      `json:"open_tasks" doc:"Other assigned tasks not yet in a terminal state"`
+	LastActiveAt string   `json:"last_active_at,omitempty" format:"date-time" doc:"Time of the actor's latest event in the project"`
+	Roles        []string `json:"roles"`
+	CanAttest    []string `json:"can_attest" doc:"Required attestation kinds of the task the actor may record"`
+}
+
+type IterationTimeResponse struct {
+	IterationID string `json:"iteration_id"`
+	Minutes     int    `json:"minutes"`
+}
+
+type DashboardResponse struct {
+	ProjectID           string                     `json:"project_id"`
+	DoneTasks           int                        `json:"done_tasks"`
+	CoveredTasks        int                        `json:"covered_tasks"`
+	AttestationCoverage float64                    `json:"attestation_coverage"`
+	AvgLeadTimeHours    float64                    `json:"avg_lead_time_hours"`
+	ValidationsDecided  int                        `json:"validations_decided"`
+	ValidationsRejected int                        `json:"validations_rejected"`
+	RejectionRate       float64                    `json:"rejection_rate"`
+	Iterations          []IterationQualityResponse `json:"iterations"`
+}
+
+type IterationQualityResponse struct {
+	IterationID       string `json:"iteration_id"`
+	DoneTasks         int    `json:"done_tasks"`
+	ForcedCompletions int    `json:"forced_completions"`
+	PolicyOverrides   int    `json:"policy_overrides"`
+}
+
+type EvidenceResponse struct {
+	ID            string `json:"id"`
+	AttestationID string `json:"attestation_id"`
+	Name          string `json:"name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     string `json:"created_at" format:"date-time"`
+	DownloadURL   string `json:"download_url"`
+}
+
+type PermissionResponse struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+}
+
+type RoleMemberResponse struct {
+	ActorID   string  `json:"actor_id"`
+	Scope     string  `json:"scope" enum:"project,org"`
+	ExpiresAt *string `json:"expires_at,omitempty" format:"date-time" doc:"When a time-boxed project grant lapses"`
+}
+
+type RoleDetailResponse struct {
+	RoleResponse
+	AttestationKinds []string             `json:"attestation_kinds"`
+	Members          []RoleMemberResponse `json:"members"`
 }
 
 type AttestationAuthorityRequest struct {
@@ -318,11 +818,59 @@ type AttestationAuthorityRequest struct {
 	RoleID string `json:"role_id"`
 }
 
+type ApplyRosterRequest struct {
+	Actors                 []RosterActorRequest `json:"actors" doc:"Actors holding project roles; actors left out lose theirs"`
+	AttestationAuthorities map[string][]string  `json:"attestation_authorities,omitempty" doc:"Roles allowed to issue each attestation kind; omit to leave authorities as they are"`
+}
+
+type RosterActorRequest struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles"`
+}
+
+type RosterResultResponse struct {
+	ProjectID string                 `json:"project_id"`
+	DryRun    bool                   `json:"dry_run,omitempty"`
+	Changes   []RosterChangeResponse `json:"changes"`
+}
+
+type RosterChangeResponse struct {
+	Op              string `json:"op" enum:"add,remove"`
+	Type            string `json:"type" enum:"actor,role,attestation_authority"`
+	ActorID         string `json:"actor_id,omitempty"`
+	RoleID          string `json:"role_id,omitempty"`
+	AttestationKind string `json:"attestation_kind,omitempty"`
+}
+
+type PermissionSimulationResponse struct {
+	ProjectID string                    `json:"project_id"`
+	ActorID   string                    `json:"actor_id"`
+	Action    string                    `json:"action"`
+	Kind      string                    `json:"kind,omitempty"`
+	Allowed   bool                      `json:"allowed"`
+	Checks    []PermissionCheckResponse `json:"checks"`
+}
+
+type PermissionCheckResponse struct {
+	Check      string               `json:"check" enum:"actor_active,permission,attestation_authority,done_role,project_active"`
+	Passed     bool                 `json:"passed"`
+	Detail     string               `json:"detail"`
+	GrantedBy  []RoleSourceResponse `json:"granted_by,omitempty" doc:"The actor's grants satisfying the check"`
+	Candidates []string             `json:"candidates,omitempty" doc:"Roles that would satisfy a failed check"`
+}
+
+type RoleSourceResponse struct {
+	RoleID    string  `json:"role_id"`
+	Scope     string  `json:"scope" enum:"project,org"`
+	ExpiresAt *string `json:"expires_at,omitempty" format:"date-time" doc:"When a time-boxed project grant lapses"`
+}
+
 type WhoAmIResponse struct {
-	ActorID     string   `json:"actor_id"`
-	OrgID       string   `json:"org_id"`
-	Roles       []string `json:"roles"`
-	Permissions []string `json:"permissions"`
+	ActorID       string            `json:"actor_id"`
+	OrgID         string            `json:"org_id"`
+	Roles         []string          `json:"roles"`
+	RoleExpiresAt map[string]string `json:"role_expires_at,omitempty" doc:"When each time-boxed role lapses, by role"`
+	Permissions   []string          `json:"permissions"`
 }
 
 type DevLoginRequest struct {
@@ -336,6 +884,24 @@ type DevLoginResponse struct {
 	Token string `json:"token"`
 }
 
+type ActorStatusResponse struct {
+	ActorID        string   `json:"actor_id"`
+	Status         string   `json:"status" enum:"active,suspended"`
+	ReleasedLeases []string `json:"released_leases"`
+}
+
+type ActorScrubResponse struct {
+	Pseudonym         string   `json:"pseudonym"`
+	ScrubbedAt        string   `json:"scrubbed_at" format:"date-time"`
+	RowsRewritten     int      `json:"rows_rewritten"`
+	ReleasedTasks     []string `json:"released_tasks"`
+	RechainedProjects []string `json:"rechained_projects"`
+}
+
+func actorScrubResponse(s domain.ActorScrub) ActorScrubResponse {
+	return ActorScrubResponse(s)
+}
+
 type ActorMissionResponse struct {
 	ProjectID string `json:"project_id"`
 	ActorID   string `json:"actor_id"`
@@ -377,6 +943,294 @@ type ValidationsResponse struct {
 
 // Conversion helpers
 
+func calendarFeedResponse(f domain.CalendarFeed) CalendarFeedResponse {
+	return CalendarFeedResponse{ID: f.ID, ProjectID: f.ProjectID, ActorID: f.ActorID, Name: f.Name, Token: f.Token, CreatedAt: f.CreatedAt}
+}
+
+func orgResponse(o domain.Org) OrgResponse {
+	return OrgResponse{ID: o.ID, Name: o.Name, CreatedAt: o.CreatedAt}
+}
+
+func orgStatusResponse(orgID string, overviews []domain.ProjectOverview) OrgStatusResponse {
+	res := OrgStatusResponse{OrgID: orgID, Projects: make([]ProjectOverviewResponse, 0, len(overviews))}
+	for _, o := range overviews {
+		res.Projects = append(res.Projects, ProjectOverviewResponse{
+			ProjectID:     o.ProjectID,
+			Status:        o.Status,
+			Iteration:     o.Iteration,
+			IterationGoal: o.IterationGoal,
+			Tasks:         o.Tasks,
+			TaskCounts:    o.TaskCounts,
+			Blocked:       o.Blocked,
+		})
+	}
+	return res
+}
+
+func iterationProgressResponse(p domain.IterationProgress) IterationProgressResponse {
+	return IterationProgressResponse{
+		IterationID:     p.IterationID,
+		Unit:            p.Unit,
+		Capacity:        p.Capacity,
+		PlannedPoints:   p.PlannedPoints,
+		CompletedPoints: p.CompletedPoints,
+		OverCapacity:    p.OverCapacity,
+	}
+}
+
+func milestoneResponse(m domain.Milestone) MilestoneResponse {
+	resp := MilestoneResponse{
+		ID:           m.ID,
+		ProjectID:    m.ProjectID,
+		Goal:         m.Goal,
+		TargetDate:   m.TargetDate,
+		IterationIDs: m.IterationIDs,
+		TaskIDs:      m.TaskIDs,
+		CreatedAt:    m.CreatedAt,
+	}
+	if resp.IterationIDs == nil {
+		resp.IterationIDs = []string{}
+	}
+	if resp.TaskIDs == nil {
+		resp.TaskIDs = []string{}
+	}
+	return resp
+}
+
+func releaseResponse(r domain.Release) ReleaseResponse {
+	return ReleaseResponse{
+		ID:          r.ID,
+		ProjectID:   r.ProjectID,
+		IterationID: r.IterationID,
+		Name:        r.Name,
+		Version:     r.Version,
+		Checksum:    r.Checksum,
+		URL:         r.URL,
+		CreatedBy:   r.CreatedBy,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func forceRequestResponse(fr domain.ForceRequest) ForceRequestResponse {
+	return ForceRequestResponse{
+		ID:          fr.ID,
+		ProjectID:   fr.ProjectID,
+		Operation:   fr.Operation,
+		EntityKind:  fr.EntityKind,
+		EntityID:    fr.EntityID,
+		Status:      fr.Status,
+		RequestedBy: fr.RequestedBy,
+		RequestedAt: fr.RequestedAt,
+		ApprovedBy:  fr.ApprovedBy,
+		ApprovedAt:  fr.ApprovedAt,
+	}
+}
+
+func webhookDeliveryResponse(d domain.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:          d.ID,
+		ProjectID:   d.ProjectID,
+		URL:         d.URL,
+		EventID:     d.EventID,
+		EventType:   d.EventType,
+		StatusCode:  d.StatusCode,
+		Error:       d.Error,
+		DurationMS:  d.DurationMS,
+		AttemptedAt: d.AttemptedAt,
+		ReplayOf:    d.ReplayOf,
+	}
+}
+
+func milestoneProgressResponse(p domain.MilestoneProgress) MilestoneProgressResponse {
+	return MilestoneProgressResponse{
+		MilestoneID:         p.MilestoneID,
+		Goal:                p.Goal,
+		TargetDate:          p.TargetDate,
+		TotalTasks:          p.TotalTasks,
+		DoneTasks:           p.DoneTasks,
+		Percent:             p.Percent,
+		PlannedPoints:       p.PlannedPoints,
+		CompletedPoints:     p.CompletedPoints,
+		Iterations:          p.Iterations,
+		ValidatedIterations: p.ValidatedIterations,
+		DaysLeft:            p.DaysLeft,
+		Overdue:             p.Overdue,
+	}
+}
+
+func boardResponse(b domain.Board) BoardResponse {
+	resp := BoardResponse{ProjectID: b.ProjectID, StrictWIP: b.StrictWIP, Columns: make([]BoardColumnResponse, 0, len(b.Columns))}
+	for _, c := range b.Columns {
+		resp.Columns = append(resp.Columns, BoardColumnResponse{
+			Status:    c.Status,
+			WIPLimit:  c.WIPLimit,
+			Count:     c.Count,
+			OverLimit: c.OverLimit,
+			Tasks:     mapTasks(c.Tasks),
+		})
+	}
+	return resp
+}
+
+func escalationResponse(esc domain.Escalation) EscalationResponse {
+	return EscalationResponse{
+		TaskID:     esc.TaskID,
+		Title:      esc.Title,
+		Type:       esc.Type,
+		Status:     esc.Status,
+		AssigneeID: esc.AssigneeID,
+		From:       esc.From,
+		To:         esc.To,
+		Since:      esc.Since,
+		AfterDays:  esc.AfterDays,
+	}
+}
+
+func configVersionResponse(v domain.ConfigVersion) ConfigVersionResponse {
+	return ConfigVersionResponse{
+		ProjectID: v.ProjectID,
+		Version:   v.Version,
+		ActorID:   v.ActorID,
+		CreatedAt: v.CreatedAt,
+		Changes:   configChangeResponses(v.Changes),
+	}
+}
+
+func configChangeResponses(changes []domain.ConfigChange) []ConfigChangeResponse {
+	res := make([]ConfigChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		res = append(res, ConfigChangeResponse{Path: c.Path, From: c.From, To: c.To, Redacted: c.Redacted})
+	}
+	return res
+}
+
+func policyCheckResponse(c domain.PolicyCheck) PolicyCheckResponse {
+	return PolicyCheckResponse{
+		TaskType:  c.TaskType,
+		Component: c.Component,
+		Policy:    c.Policy,
+		Override:  c.Override,
+		Required:  nonNilSlice(c.Required),
+		Present:   nonNilSlice(c.Present),
+		Missing:   nonNilSlice(c.Missing),
+		Expired:   nonNilSlice(c.Expired),
+		Blockers:  nonNilSlice(c.Blockers),
+		Satisfied: c.Satisfied,
+	}
+}
+
+func complianceReportResponse(r domain.ComplianceReport) ComplianceReportResponse {
+	res := ComplianceReportResponse{
+		ProjectID:    r.ProjectID,
+		CheckedAt:    r.CheckedAt,
+		Checked:      r.Checked,
+		Flagged:      r.Flagged,
+		NonCompliant: make([]ComplianceIssueResponse, 0, len(r.NonCompliant)),
+	}
+	for _, i := range r.NonCompliant {
+		res.NonCompliant = append(res.NonCompliant, ComplianceIssueResponse{
+			TaskID:      i.TaskID,
+			Title:       i.Title,
+			Type:        i.Type,
+			CompletedAt: i.CompletedAt,
+			Forced:      i.Forced,
+			Missing:     nonNilSlice(i.Missing),
+			Tightened:   nonNilSlice(i.Tightened),
+		})
+	}
+	return res
+}
+
+func timeEntryResponse(te domain.TimeEntry) TimeEntryResponse {
+	return TimeEntryResponse{
+		ID:        te.ID,
+		TaskID:    te.TaskID,
+		ActorID:   te.ActorID,
+		Minutes:   te.Minutes,
+		Note:      te.Note,
+		CreatedAt: te.CreatedAt,
+	}
+}
+
+func taskHistoryResponse(h domain.TaskHistoryEntry) TaskHistoryEntryResponse {
+	return TaskHistoryEntryResponse{
+		TS:       h.TS,
+		Category: h.Category,
+		Type:     h.Type,
+		ActorID:  h.ActorID,
+		Summary:  h.Summary,
+		EventID:  h.EventID,
+		Payload:  h.Payload,
+	}
+}
+
+func assigneeSuggestionResponse(a domain.AssigneeSuggestion) AssigneeSuggestionResponse {
+	return AssigneeSuggestionResponse{
+		ActorID:      a.ActorID,
+		OpenTasks:    a.OpenTasks,
+		LastActiveAt: a.LastActiveAt,
+		Roles:        a.Roles,
+		CanAttest:    a.CanAttest,
+	}
+}
+
+func dashboardResponse(d domain.Dashboard) DashboardResponse {
+	iterations := make([]IterationQualityResponse, 0, len(d.Iterations))
+	for _, q := range d.Iterations {
+		iterations = append(iterations, IterationQualityResponse{
+			IterationID:       q.IterationID,
+			DoneTasks:         q.DoneTasks,
+			ForcedCompletions: q.ForcedCompletions,
+			PolicyOverrides:   q.PolicyOverrides,
+		})
+	}
+	return DashboardResponse{
+		ProjectID:           d.ProjectID,
+		DoneTasks:           d.DoneTasks,
+		CoveredTasks:        d.CoveredTasks,
+		AttestationCoverage: d.AttestationCoverage,
+		AvgLeadTimeHours:    d.AvgLeadTimeHours,
+		ValidationsDecided:  d.ValidationsDecided,
+		ValidationsRejected: d.ValidationsRejected,
+		RejectionRate:       d.RejectionRate,
+		Iterations:          iterations,
+	}
+}
+
+func evidenceResponse(ev domain.Evidence, downloadURL string) EvidenceResponse {
+	return EvidenceResponse{
+		ID:            ev.ID,
+		AttestationID: ev.AttestationID,
+		Name:          ev.Name,
+		ContentType:   ev.ContentType,
+		Size:          ev.Size,
+		SHA256:        ev.SHA256,
+		CreatedBy:     ev.CreatedBy,
+		CreatedAt:     ev.CreatedAt,
+		DownloadURL:   downloadURL,
+	}
+}
+
+func roleResponse(r domain.Role) RoleResponse {
+	return RoleResponse{ID: r.ID, Description: r.Description, ProjectID: r.ProjectID, Permissions: nonNilSlice(r.Permissions)}
+}
+
+func roleDetailResponse(r domain.RoleDetail) RoleDetailResponse {
+	members := make([]RoleMemberResponse, 0, len(r.Members))
+	for _, m := range r.Members {
+		members = append(members, RoleMemberResponse{ActorID: m.ActorID, Scope: m.Scope, ExpiresAt: m.ExpiresAt})
+	}
+	return RoleDetailResponse{
+		RoleResponse:     roleResponse(r.Role),
+		AttestationKinds: nonNilSlice(r.AttestationKinds),
+		Members:          members,
+	}
+}
+
+func orgMemberResponse(m domain.OrgMember) OrgMemberResponse {
+	return OrgMemberResponse{OrgID: m.OrgID, ActorID: m.ActorID, Role: m.Role}
+}
+
 func projectResponse(p domain.Project) ProjectResponse {
 	return ProjectResponse{
 		ID:          p.ID,
@@ -394,6 +1248,7 @@ func iterationResponse(it domain.Iteration) IterationResponse {
 		ProjectID: it.ProjectID,
 		Goal:      it.Goal,
 		Status:    it.Status,
+		Capacity:  it.Capacity,
 		CreatedAt: it.CreatedAt,
 	}
 }
@@ -407,24 +1262,85 @@ func taskResponse(t domain.Task) TaskResponse {
 		IterationID:          t.IterationID,
 		ParentID:             t.ParentID,
 		Type:                 t.Type,
+		Component:            t.Component,
 		Title:                t.Title,
 		Description:          t.Description,
 		Status:               t.Status,
 		AssigneeID:           t.AssigneeID,
 		Priority:             t.Priority,
+		Estimate:             t.Estimate,
 		WorkOutcomes:         workOutcomes,
 		RequiredAttestations: nonNilSlice(req),
 		DependsOn:            nonNilSlice(t.DependsOn),
+		Relations:            taskRelationResponses(t.Relations),
+		ExternalRefs:         t.ExternalRefs,
 		CreatedAt:            t.CreatedAt,
 		UpdatedAt:            t.UpdatedAt,
 		CompletedAt:          t.CompletedAt,
+		DueAt:                t.DueAt,
+		Stale:                t.Stale,
+		Warnings:             t.Warnings,
+	}
+}
+
+func taskRelationResponse(rel domain.TaskRelation) TaskRelationResponse {
+	return TaskRelationResponse{
+		TaskID:        rel.TaskID,
+		Type:          rel.Type,
+		RelatedTaskID: rel.RelatedTaskID,
+		CreatedBy:     rel.CreatedBy,
+		CreatedAt:     rel.CreatedAt,
+		Closed:        rel.Closed,
+	}
+}
+
+func taskRelationResponses(rels []domain.TaskRelation) []TaskRelationResponse {
+	if len(rels) == 0 {
+		return nil
+	}
+	res := make([]TaskRelationResponse, 0, len(rels))
+	for _, rel := range rels {
+		res = append(res, taskRelationResponse(rel))
+	}
+	return res
+}
+
+func taskGraphResponse(g domain.TaskGraph) TaskGraphResponse {
+	resp := TaskGraphResponse{
+		ProjectID: g.ProjectID,
+		Nodes:     make([]TaskGraphNodeResponse, 0, len(g.Nodes)),
+		Edges:     make([]TaskGraphEdgeResponse, 0, len(g.Edges)),
+	}
+	for _, n := range g.Nodes {
+		resp.Nodes = append(resp.Nodes, TaskGraphNodeResponse{ID: n.ID, Title: n.Title, Type: n.Type, Status: n.Status})
+	}
+	for _, edge := range g.Edges {
+		resp.Edges = append(resp.Edges, TaskGraphEdgeResponse{From: edge.From, To: edge.To, Type: edge.Type})
+	}
+	return resp
+}
+
+func (r ApplyRosterRequest) roster() config.Roster {
+	roster := config.Roster{AttestationAuthorities: r.AttestationAuthorities}
+	for _, a := range r.Actors {
+		roster.Actors = append(roster.Actors, config.RosterActor{ID: a.ID, Roles: a.Roles})
+	}
+	return roster
+}
+
+func rosterResultResponse(res domain.RosterResult) RosterResultResponse {
+	resp := RosterResultResponse{ProjectID: res.ProjectID, DryRun: res.DryRun, Changes: make([]RosterChangeResponse, 0, len(res.Changes))}
+	for _, c := range res.Changes {
+		resp.Changes = append(resp.Changes, RosterChangeResponse{Op: c.Op, Type: c.Type, ActorID: c.ActorID, RoleID: c.RoleID, AttestationKind: c.AttestationKind})
 	}
+	return resp
 }
 
 func decisionResponse(d domain.Decision) DecisionResponse {
 	return DecisionResponse{
 		ID:           d.ID,
 		ProjectID:    d.ProjectID,
+		Number:       d.Number,
 		Title:        d.Title,
 		Decision:     d.Decision,
 		DeciderID:    d.DeciderID,
@@ -445,6 +1361,7 @@ func attestationResponse(a domain.Attestation) AttestationResponse {
 		ActorID:    a.ActorID,
 		TS:         a.TS,
 		Payload:    decodeJSONMap(strPtr(a.PayloadJSON)),
+		ExpiredAt:  a.ExpiredAt,
 	}
 }
 
@@ -519,6 +1436,7 @@ func configResponse(cfg *config.Config) ProjectConfigResponse {
 				Mode:             cfg.Project.Validation.Mode,
 				ChallengerPrompt: cfg.Project.Validation.ChallengerPrompt,
 			},
+			WorkOutcomes: workOutcomesConfigResponse{MaxBytes: cfg.Project.WorkOutcomes.MaxBytes},
 			RBAC: rbacConfigResponse{
 				Permissions: map[string][]string{},
 				Roles:       map[string]rbacRoleResponse{},
@@ -530,7 +1448,7 @@ func configResponse(cfg *config.Config) ProjectConfigResponse {
 		for pname, rule := range tt.Policies {
 			policies[pname] = policyRuleResponse{All: nonNilSlice(rule.All)}
 		}
-		res.Project.TaskTypes[name] = taskTypeConfigResponse{Policies: policies}
+		res.Project.TaskTypes[name] = taskTypeConfigResponse{Policies: policies, WorkOutcomesSchema: tt.WorkOutcomesSchema, DoneRoles: tt.DoneRoles}
 	}
 	for name, it := range cfg.Project.IterationTypes {
 		policies := map[string]policyRuleResponse{}
@@ -544,6 +1462,7 @@ func configResponse(cfg *config.Config) ProjectConfigResponse {
 			ID:          att.ID,
 			Category:    att.Category,
 			Description: att.Description,
+			ValidDays:   att.ValidDays,
 		})
 	}
 	for _, mission := range cfg.Project.ActorMissions {
@@ -602,3 +1521,19 @@ func nonNilSlice[T any](in []T) []T {
 func strPtr(in string) *string {
 	return &in
 }
+
+func permissionSimulationResponse(sim domain.PermissionSimulation) PermissionSimulationResponse {
+	resp := PermissionSimulationResponse{ProjectID: sim.ProjectID, ActorID: sim.ActorID, Action: sim.Action, Kind: sim.Kind, Allowed: sim.Allowed, Checks: make([]PermissionCheckResponse, 0, len(sim.Checks))}
+	for _, c := range sim.Checks {
+		check := PermissionCheckResponse{Check: c.Check, Passed: c.Passed, Detail: c.Detail, Candidates: c.Candidates}
+		for _, src := range c.GrantedBy {
+			check.GrantedBy = append(check.GrantedBy, RoleSourceResponse{RoleID: src.RoleID, Scope: src.Scope, ExpiresAt: src.ExpiresAt})
+		}
+		resp.Checks = append(resp.Checks, check)
+	}
+	return resp
+}
+
+func readAuditEntryResponse(entry domain.ReadAuditEntry) ReadAuditEntryResponse {
+	return ReadAuditEntryResponse{ID: entry.ID, TS: entry.TS, ActorID: entry.ActorID, Resource: entry.Resource, Target: entry.Target}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/conditional"
+
+	"workline/internal/engine"
+)
+
+// etagOf hashes the values that identify a representation into an ETag,
+// quoted as it goes on the wire.
+func etagOf(parts ...any) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// projectETag versions a project-scoped read by the project's latest event id:
+// every write appends an event, so the id only moves when the data may have.
+// The request URI and the caller are mixed in since they shape the response.
+func projectETag(ctx context.Context, e engine.Engine, projectID string) (string, error) {
+	latest, err := e.Repo.LatestEventID(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	var uri string
+	if r, ok := ctx.Value(requestKey{}).(*http.Request); ok {
+		uri = r.URL.RequestURI()
+	}
+	actorID, _ := actorIDFromContext(ctx)
+	return etagOf(projectID, actorID, uri, latest), nil
+}
+
+// checkNotModified answers a GET whose If-None-Match already names etag with
+// 304 Not Modified, repeating the ETag.
+func checkNotModified(cond *conditional.Params, etag string) error {
+	if err := cond.PreconditionFailed(etag[1:len(etag)-1], time.Time{}); err != nil {
+		return huma.ErrorWithHeaders(err, http.Header{"ETag": {etag}})
+	}
+	return nil
+}
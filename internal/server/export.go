@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/events"
+	"workline/internal/repo"
+)
+
+// exportBatch is how many rows an NDJSON export reads per query; each batch
+// is flushed to the client before the next one is read.
+const exportBatch = 500
+
+// exportSort orders task exports by last change, so re-running an export
+// from the previous cursor picks up tasks that changed since.
+var exportSort = repo.TaskSort{Field: "updated_at"}
+
+func registerExport(api huma.API, e engine.Engine) {
+	schemas := api.OpenAPI().Components.Schemas
+	ndjson := func(desc string, item reflect.Type) map[string]*huma.Response {
+		return map[string]*huma.Response{
+			"200": {
+				Description: desc,
+				Content: map[string]*huma.MediaType{
+					"application/x-ndjson": {Schema: schemas.Schema(item, true, "")},
+				},
+			},
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "export-events",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/export/events.ndjson",
+		Summary:     "Export events (NDJSON)",
+		Description: "Streams the project's events oldest first, one JSON object per line, flushing as it goes. Audit events are skipped without events.audit.read. Resume an interrupted or incremental export with cursor set to the id of the last line received.",
+		Responses:   ndjson("One event per line", reflect.TypeOf(EventResponse{})),
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Cursor    int64  `query:"cursor" minimum:"0" doc:"Only events with a greater id"`
+		Type      string `query:"type" doc:"Only events of this type"`
+		Limit     int    `query:"limit" minimum:"0" doc:"Stop after this many events; 0 exports everything"`
+	}) (*huma.StreamResponse, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "project.events.read"); err != nil {
+			return nil, handleError(err)
+		}
+		if err := auditRead(ctx, e, projectID, "events", ""); err != nil {
+			return nil, handleError(err)
+		}
+		audit, err := canReadAuditEvents(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &huma.StreamResponse{Body: func(hctx huma.Context) {
+			out := startNDJSON(hctx)
+			cursor, sent := input.Cursor, 0
+			for {
+				items, err := e.Repo.EventsAfter(hctx.Context(), exportBatch, cursor, projectID)
+				if err != nil {
+					out.fail("events", err)
+					return
+				}
+				for _, evt := range items {
+					cursor = evt.ID
+					if input.Type != "" && evt.Type != input.Type {
+						continue
+					}
+					if !audit && evt.EntityKind == events.AuditEntityKind {
+						continue
+					}
+					if !out.write(eventResponse(evt)) {
+						return
+					}
+					if sent++; input.Limit > 0 && sent >= input.Limit {
+						out.flush()
+						return
+					}
+				}
+				out.flush()
+				if len(items) < exportBatch {
+					return
+				}
+			}
+		}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "export-tasks",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/export/tasks.ndjson",
+		Summary:     "Export tasks (NDJSON)",
+		Description: "Streams the project's tasks least recently updated first, one JSON object per line, flushing as it goes. Pass cursor=<updated_at>|<id> of the last line received to resume, or to fetch only tasks changed since a previous export.",
+		Responses:   ndjson("One task per line", reflect.TypeOf(TaskResponse{})),
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Cursor    string `query:"cursor" doc:"updated_at|id of the last task already received"`
+		Limit     int    `query:"limit" minimum:"0" doc:"Stop after this many tasks; 0 exports everything"`
+	}) (*huma.StreamResponse, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.list"); err != nil {
+			return nil, handleError(err)
+		}
+		cursorValue, cursorID, err := parseCompositeCursor(input.Cursor)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+		}
+		return &huma.StreamResponse{Body: func(hctx huma.Context) {
+			out := startNDJSON(hctx)
+			sent := 0
+			for {
+				batch := exportBatch
+				if input.Limit > 0 && input.Limit-sent < batch {
+					batch = input.Limit - sent
+				}
+				tasks, err := e.Repo.ListTasks(hctx.Context(), repo.TaskFilters{
+					ProjectID:   projectID,
+					Sort:        exportSort,
+					Limit:       batch,
+					CursorValue: cursorValue,
+					CursorID:    cursorID,
+				})
+				if err == nil {
+					err = withTaskLinks(hctx.Context(), e.Repo, tasks)
+				}
+				if err != nil {
+					out.fail("tasks", err)
+					return
+				}
+				for _, t := range tasks {
+					cursorValue, cursorID = exportSort.Key(t), t.ID
+					if !out.write(taskResponse(t)) {
+						return
+					}
+				}
+				out.flush()
+				sent += len(tasks)
+				if len(tasks) < batch || (input.Limit > 0 && sent >= input.Limit) {
+					return
+				}
+			}
+		}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "export-read-audit",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/export/read-audit.csv",
+		Summary:     "Export audited reads (CSV)",
+		Description: "The reads recorded under project.read_audit as CSV, oldest first, with a header row: id, ts, actor_id, resource, target. Needs events.audit.read. since and until take RFC3339 or YYYY-MM-DD.",
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "One read per row",
+				Content:     map[string]*huma.MediaType{"text/csv": {Schema: &huma.Schema{Type: "string"}}},
+			},
+		},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Actor     string `query:"actor"`
+		Resource  string `query:"resource" enum:"config,events,attestations"`
+		Since     string `query:"since" doc:"Only reads at or after this time"`
+		Until     string `query:"until" doc:"Only reads before this time"`
+	}) (*huma.StreamResponse, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		entries, err := e.ReadAudit(ctx, actorID, repo.ReadAuditFilters{
+			ProjectID: projectID,
+			ActorID:   input.Actor,
+			Resource:  input.Resource,
+			Since:     input.Since,
+			Until:     input.Until,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &huma.StreamResponse{Body: func(hctx huma.Context) {
+			hctx.SetHeader("Content-Type", "text/csv")
+			hctx.SetHeader("Content-Disposition", `attachment; filename="read-audit-`+projectID+`.csv"`)
+			hctx.SetHeader("Cache-Control", "no-store")
+			hctx.SetStatus(http.StatusOK)
+			if err := writeReadAuditCSV(hctx.BodyWriter(), entries); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("export read audit: %v", err)
+			}
+		}}, nil
+	})
+}
+
+// writeReadAuditCSV writes entries oldest first under a header row.
+func writeReadAuditCSV(w io.Writer, entries []domain.ReadAuditEntry) error {
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"id", "ts", "actor_id", "resource", "target"})
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		_ = out.Write([]string{strconv.FormatInt(entry.ID, 10), entry.TS, entry.ActorID, entry.Resource, entry.Target})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ndjsonWriter encodes one value per line onto a streaming response.
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+func startNDJSON(hctx huma.Context) ndjsonWriter {
+	hctx.SetHeader("Content-Type", "application/x-ndjson")
+	hctx.SetHeader("Cache-Control", "no-store")
+	hctx.SetStatus(http.StatusOK)
+	w := hctx.BodyWriter()
+	flusher, _ := w.(http.Flusher)
+	return ndjsonWriter{enc: json.NewEncoder(w), flusher: flusher}
+}
+
+// write reports false once the client is gone.
+func (n ndjsonWriter) write(v any) bool {
+	return n.enc.Encode(v) == nil
+}
+
+func (n ndjsonWriter) flush() {
+	if n.flusher != nil {
+		n.flusher.Flush()
+	}
+}
+
+// fail ends an export that broke after the status line went out; the client
+// sees a short stream and resumes from its last line.
+func (n ndjsonWriter) fail(what string, err error) {
+	n.flush()
+	if !errors.Is(err, context.Canceled) {
+		log.Printf("export %s: %v", what, err)
+	}
+}
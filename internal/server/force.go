@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/engine"
+)
+
+func registerForce(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-force-requests",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/force-requests",
+		Summary:     "List force requests",
+		Description: "Lists forced operations recorded while force.require_approval is set, oldest first. Needs force.approve.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Status    string `query:"status" enum:"pending,approved" doc:"Only requests in this status"`
+	}) (*struct {
+		Body []ForceRequestResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		items, err := e.ListForceRequests(ctx, projectID, input.Status, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]ForceRequestResponse, 0, len(items))
+		for _, fr := range items {
+			resp = append(resp, forceRequestResponse(fr))
+		}
+		return &struct {
+			Body []ForceRequestResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "approve-force-request",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/force-requests/{request_id}/approve",
+		Summary:     "Approve a force request",
+		Description: "Runs the pending forced operation as the actor who requested it and marks the request approved. Needs force.approve, and the approver must not be the requester. If the operation fails the request stays pending.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		RequestID string `path:"request_id"`
+	}) (*struct {
+		Body ForceRequestResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		fr, err := e.ApproveForce(ctx, projectID, input.RequestID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ForceRequestResponse `json:"body"`
+		}{Body: forceRequestResponse(fr)}, nil
+	})
+}
@@ -0,0 +1,722 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"workline/internal/domain"
+	"workline/internal/engine"
+	"workline/internal/events"
+	"workline/internal/repo"
+	pb "workline/internal/server/worklinev1"
+)
+
+// grpcEventPoll is how often WatchEvents looks for new events.
+var grpcEventPoll = 500 * time.Millisecond
+
+const grpcEventBatch = 100
+
+// GRPCServer serves the gRPC API (proto/workline/v1) next to the HTTP
+// handler, with the same engine and credentials.
+type GRPCServer struct {
+	*grpc.Server
+	stopping chan struct{}
+	once     sync.Once
+}
+
+// NewGRPC returns a gRPC server for cfg.Engine authenticating with cfg.Auth.
+// The other Config fields only apply to HTTP. Pass grpc.Creds in opts to
+// serve TLS.
+func NewGRPC(cfg Config, opts ...grpc.ServerOption) *GRPCServer {
+	svc := &grpcService{engine: cfg.Engine, auth: cfg.Auth, stopping: make(chan struct{})}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(svc.unaryAuth),
+		grpc.ChainStreamInterceptor(svc.streamAuth),
+	)
+	s := &GRPCServer{Server: grpc.NewServer(opts...), stopping: svc.stopping}
+	pb.RegisterWorklineServer(s.Server, svc)
+	reflection.Register(s.Server)
+	return s
+}
+
+// Shutdown ends open event streams and waits for in-flight calls to finish,
+// closing every connection once ctx is done.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.once.Do(func() { close(s.stopping) })
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+type grpcService struct {
+	pb.UnimplementedWorklineServer
+	engine   engine.Engine
+	auth     AuthConfig
+	stopping chan struct{}
+}
+
+func (s *grpcService) unaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *grpcService) streamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context { return s.ctx }
+
+// authenticate resolves the caller the way the HTTP middleware does, from
+// "authorization" or "x-api-key" metadata or a verified client certificate.
+func (s *grpcService) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+	var principal Principal
+	if authz := first("authorization"); authz != "" {
+		token, ok := bearerToken(authz)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		p, err := authenticateJWT(token, s.auth.JWTSecret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		principal = p
+	} else if key := first("x-api-key"); key != "" {
+		p, err := authenticateAPIKey(ctx, s.engine.Repo, key)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		principal = p
+	} else if actorID := grpcCertActor(ctx, s.auth.ClientCertActor); actorID != "" {
+		principal = Principal{ActorID: actorID, Source: "client_cert"}
+	} else {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	suspended, err := actorSuspended(ctx, s.engine.Repo, principal.ActorID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if suspended {
+		return nil, status.Error(codes.Unauthenticated, "actor is suspended")
+	}
+	return withPrincipal(ctx, principal), nil
+}
+
+func grpcCertActor(ctx context.Context, field string) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	return certActor(&info.State, field)
+}
+
+// project resolves the request's project, defaulting to the served one, and
+// applies the same token org scoping as HTTP.
+func (s *grpcService) project(ctx context.Context, projectID string) (string, error) {
+	if projectID == "" && s.engine.Config != nil {
+		projectID = s.engine.Config.Project.ID
+	}
+	if projectID == "" {
+		return "", status.Error(codes.InvalidArgument, "project_id is required")
+	}
+	if p, ok := principalFromContext(ctx); ok && projectOrgMismatch(ctx, s.engine.Repo, projectID, p.OrgID) {
+		return "", grpcStatus(newAPIError(http.StatusForbidden, "org_mismatch", "token org does not match resource org", map[string]any{"org_id": p.OrgID}))
+	}
+	return projectID, nil
+}
+
+// task loads a task and checks it belongs to the project.
+func (s *grpcService) task(ctx context.Context, projectID, id string) (domain.Task, error) {
+	if id == "" {
+		return domain.Task{}, status.Error(codes.InvalidArgument, "id is required")
+	}
+	t, err := s.engine.Repo.GetTask(ctx, id)
+	if err != nil {
+		return domain.Task{}, grpcError(err)
+	}
+	if t.ProjectID != projectID {
+		return domain.Task{}, status.Error(codes.NotFound, "task not found in project")
+	}
+	return t, nil
+}
+
+func (s *grpcService) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.Task, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetTitle() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if req.GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	opts := engine.TaskCreateOptions{
+		ID:           req.GetId(),
+		ProjectID:    projectID,
+		IterationID:  req.GetIterationId(),
+		ParentID:     req.GetParentId(),
+		Type:         req.GetType(),
+		Component:    req.GetComponent(),
+		Title:        req.GetTitle(),
+		Description:  req.GetDescription(),
+		DependsOn:    req.GetDependsOn(),
+		AssigneeID:   req.GetAssigneeId(),
+		Estimate:     req.Estimate,
+		PolicyPreset: req.GetPolicyPreset(),
+		ActorID:      actorID,
+	}
+	if req.Priority != nil {
+		p := int(req.GetPriority())
+		opts.Priority = &p
+	}
+	if req.WorkOutcomes != nil {
+		raw, err := structJSON(req.WorkOutcomes)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid work_outcomes: %v", err)
+		}
+		opts.WorkOutcomesJSON = &raw
+	}
+	t, err := s.engine.CreateTask(ctx, opts)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return taskProto(t), nil
+}
+
+func (s *grpcService) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.engine, projectID, "task.read"); err != nil {
+		return nil, grpcError(err)
+	}
+	t, err := s.task(ctx, projectID, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return taskProto(t), nil
+}
+
+func (s *grpcService) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.engine, projectID, "task.list"); err != nil {
+		return nil, grpcError(err)
+	}
+	limit := normalizeLimit(int(req.GetLimit()))
+	cursorValue, cursorID, err := parseTaskCursor(req.GetCursor(), repo.DefaultTaskSort)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	tasks, err := s.engine.Repo.ListTasks(ctx, repo.TaskFilters{
+		ProjectID:   projectID,
+		Statuses:    req.GetStatuses(),
+		Types:       req.GetTypes(),
+		Iteration:   req.GetIterationId(),
+		AssigneeID:  req.GetAssigneeId(),
+		Sort:        repo.DefaultTaskSort,
+		Limit:       limit + 1,
+		CursorValue: cursorValue,
+		CursorID:    cursorID,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &pb.ListTasksResponse{}
+	if len(tasks) > limit {
+		resp.NextCursor = composeTaskCursor(repo.DefaultTaskSort, tasks[limit])
+		tasks = tasks[:limit]
+	}
+	if err := withTaskLinks(ctx, s.engine.Repo, tasks); err != nil {
+		return nil, grpcError(err)
+	}
+	for _, t := range tasks {
+		resp.Tasks = append(resp.Tasks, taskProto(t))
+	}
+	return resp, nil
+}
+
+func (s *grpcService) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.Task, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	if _, err := s.task(ctx, projectID, req.GetId()); err != nil {
+		return nil, err
+	}
+	opts := engine.TaskUpdateOptions{
+		ID:      req.GetId(),
+		Status:  req.GetStatus(),
+		ActorID: actorID,
+		Force:   req.GetForce(),
+	}
+	if req.AssigneeId != nil {
+		opts.AssignProvided = true
+		if req.GetAssigneeId() != "" {
+			opts.Assign = req.AssigneeId
+		}
+	}
+	if req.Priority != nil {
+		p := int(req.GetPriority())
+		opts.PriorityProvided = true
+		opts.SetPriority = &p
+	}
+	t, err := s.engine.UpdateTask(ctx, opts)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return taskProto(t), nil
+}
+
+func (s *grpcService) ClaimTask(ctx context.Context, req *pb.ClaimTaskRequest) (*pb.Lease, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	if _, err := s.task(ctx, projectID, req.GetId()); err != nil {
+		return nil, err
+	}
+	lease, err := s.engine.ClaimLease(ctx, req.GetId(), actorID, leaseSecondsOrDefault(req.GetLeaseSeconds()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return leaseProto(lease), nil
+}
+
+func (s *grpcService) ClaimNextTask(ctx context.Context, req *pb.ClaimNextTaskRequest) (*pb.ClaimNextTaskResponse, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	t, lease, err := s.engine.ClaimNextTask(ctx, engine.ClaimNextOptions{
+		ProjectID:         projectID,
+		IterationID:       req.GetIterationId(),
+		Types:             req.GetTypes(),
+		IncludeUnassigned: !req.GetExcludeUnassigned(),
+		ActorID:           actorID,
+		LeaseSeconds:      leaseSecondsOrDefault(req.GetLeaseSeconds()),
+	})
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "no task available")
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.ClaimNextTaskResponse{Task: taskProto(t), Lease: leaseProto(lease)}, nil
+}
+
+func (s *grpcService) ReleaseTask(ctx context.Context, req *pb.ReleaseTaskRequest) (*pb.ReleaseTaskResponse, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	if _, err := s.task(ctx, projectID, req.GetId()); err != nil {
+		return nil, err
+	}
+	if err := s.engine.ReleaseLease(ctx, req.GetId(), actorID); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.ReleaseTaskResponse{}, nil
+}
+
+func (s *grpcService) CompleteTask(ctx context.Context, req *pb.CompleteTaskRequest) (*pb.Task, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	if req.WorkOutcomes == nil {
+		return nil, status.Error(codes.InvalidArgument, "work_outcomes is required")
+	}
+	raw, err := structJSON(req.WorkOutcomes)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid work_outcomes: %v", err)
+	}
+	if _, err := s.task(ctx, projectID, req.GetId()); err != nil {
+		return nil, err
+	}
+	t, err := s.engine.TaskDone(ctx, req.GetId(), raw, actorID, req.GetForce())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return taskProto(t), nil
+}
+
+func (s *grpcService) GetTaskValidation(ctx context.Context, req *pb.GetTaskValidationRequest) (*pb.TaskValidation, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.engine, projectID, "task.validation.read"); err != nil {
+		return nil, grpcError(err)
+	}
+	t, err := s.task(ctx, projectID, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	v, err := taskValidationStatus(ctx, s.engine, t)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.TaskValidation{
+		Required:  v.Required,
+		Present:   v.Present,
+		Missing:   v.Missing,
+		Expired:   v.Expired,
+		Satisfied: v.Satisfied,
+	}, nil
+}
+
+func (s *grpcService) AddAttestation(ctx context.Context, req *pb.AddAttestationRequest) (*pb.Attestation, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return nil, grpcStatus(authErr)
+	}
+	if req.GetEntityKind() == "" || req.GetEntityId() == "" || req.GetKind() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity_kind, entity_id and kind are required")
+	}
+	att := domain.Attestation{
+		ProjectID:  projectID,
+		EntityKind: req.GetEntityKind(),
+		EntityID:   req.GetEntityId(),
+		Kind:       req.GetKind(),
+	}
+	if req.Payload != nil {
+		if att.PayloadJSON, err = structJSON(req.Payload); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid payload: %v", err)
+		}
+	}
+	res, err := s.engine.AddAttestation(ctx, att, actorID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return attestationProto(res), nil
+}
+
+func (s *grpcService) ListAttestations(ctx context.Context, req *pb.ListAttestationsRequest) (*pb.ListAttestationsResponse, error) {
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+	if err := requirePermission(ctx, s.engine, projectID, "attestation.list"); err != nil {
+		return nil, grpcError(err)
+	}
+	if err := auditRead(ctx, s.engine, projectID, "attestations", "grpc ListAttestations"); err != nil {
+		return nil, grpcError(err)
+	}
+	limit := normalizeLimit(int(req.GetLimit()))
+	cursorTS, cursorID, err := parseCompositeCursor(req.GetCursor())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	items, err := s.engine.Repo.ListAttestations(ctx, repo.AttestationFilters{
+		ProjectID:  projectID,
+		EntityKind: req.GetEntityKind(),
+		EntityID:   req.GetEntityId(),
+		Kind:       req.GetKind(),
+		Limit:      limit + 1,
+		CursorTS:   cursorTS,
+		CursorID:   cursorID,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &pb.ListAttestationsResponse{}
+	if len(items) > limit {
+		resp.NextCursor = composeCursor(items[limit].TS, items[limit].ID)
+		items = items[:limit]
+	}
+	for _, att := range items {
+		resp.Attestations = append(resp.Attestations, attestationProto(att))
+	}
+	return resp, nil
+}
+
+func (s *grpcService) WatchEvents(req *pb.WatchEventsRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	ctx := stream.Context()
+	projectID, err := s.project(ctx, req.GetProjectId())
+	if err != nil {
+		return err
+	}
+	if err := requirePermission(ctx, s.engine, projectID, "project.events.read"); err != nil {
+		return grpcError(err)
+	}
+	if err := auditRead(ctx, s.engine, projectID, "events", "grpc WatchEvents"); err != nil {
+		return grpcError(err)
+	}
+	audit, err := canReadAuditEvents(ctx, s.engine, projectID)
+	if err != nil {
+		return grpcError(err)
+	}
+	cursor := req.GetAfterId()
+	if req.AfterId == nil {
+		if cursor, err = s.engine.Repo.LatestEventID(ctx, projectID); err != nil {
+			return grpcError(err)
+		}
+	}
+	types := map[string]bool{}
+	for _, t := range req.GetTypes() {
+		types[t] = true
+	}
+	ticker := time.NewTicker(grpcEventPoll)
+	defer ticker.Stop()
+	for {
+		batch, err := s.engine.Repo.EventsAfter(ctx, grpcEventBatch, cursor, projectID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return grpcError(err)
+		}
+		for _, ev := range batch {
+			cursor = ev.ID
+			if len(types) > 0 && !types[ev.Type] {
+				continue
+			}
+			if !audit && ev.EntityKind == events.AuditEntityKind {
+				continue
+			}
+			if err := stream.Send(eventProto(ev)); err != nil {
+				return err
+			}
+		}
+		if len(batch) == grpcEventBatch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case <-ticker.C:
+		}
+	}
+}
+
+func leaseSecondsOrDefault(v int32) int {
+	if v == 0 {
+		return 900
+	}
+	return int(v)
+}
+
+// grpcError maps an engine error to a gRPC status via the HTTP error mapping,
+// so both APIs agree. The HTTP error code and details ride along as an
+// ErrorInfo detail.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return grpcStatus(handleError(err))
+}
+
+func grpcStatus(err error) error {
+	var ae *apiError
+	if !errors.As(err, &ae) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	st := status.New(grpcCode(ae.status, ae.Body.Code), ae.Body.Message)
+	info := &errdetails.ErrorInfo{Reason: ae.Body.Code, Domain: "workline", Metadata: map[string]string{}}
+	for k, v := range ae.Body.Details {
+		if s, ok := v.(string); ok {
+			info.Metadata[k] = s
+		} else if b, err := json.Marshal(v); err == nil {
+			info.Metadata[k] = string(b)
+		}
+	}
+	if withInfo, err := st.WithDetails(info); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+func grpcCode(httpStatus int, code string) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		if code == "conflict" {
+			return codes.AlreadyExists
+		}
+		return codes.FailedPrecondition
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+func structJSON(s *structpb.Struct) (string, error) {
+	b, err := json.Marshal(s.AsMap())
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// jsonStruct converts a decoded JSON object; values that came out of
+// encoding/json always convert.
+func jsonStruct(m map[string]any) *structpb.Struct {
+	if m == nil {
+		return nil
+	}
+	s, err := structpb.NewStruct(m)
+	if err != nil {
+		panic(fmt.Sprintf("json object to struct: %v", err))
+	}
+	return s
+}
+
+func taskProto(t domain.Task) *pb.Task {
+	r := taskResponse(t)
+	out := &pb.Task{
+		Id:                   r.ID,
+		ProjectId:            r.ProjectID,
+		IterationId:          r.IterationID,
+		ParentId:             r.ParentID,
+		Type:                 r.Type,
+		Component:            r.Component,
+		Title:                r.Title,
+		Description:          r.Description,
+		Status:               r.Status,
+		AssigneeId:           r.AssigneeID,
+		Estimate:             r.Estimate,
+		WorkOutcomes:         jsonStruct(r.WorkOutcomes),
+		RequiredAttestations: r.RequiredAttestations,
+		DependsOn:            r.DependsOn,
+		CreatedAt:            r.CreatedAt,
+		UpdatedAt:            r.UpdatedAt,
+		CompletedAt:          r.CompletedAt,
+		Warnings:             r.Warnings,
+	}
+	if r.Priority != nil {
+		p := int32(*r.Priority)
+		out.Priority = &p
+	}
+	return out
+}
+
+func leaseProto(l domain.Lease) *pb.Lease {
+	return &pb.Lease{
+		TaskId:     l.TaskID,
+		OwnerId:    l.OwnerID,
+		AcquiredAt: l.AcquiredAt,
+		ExpiresAt:  l.ExpiresAt,
+	}
+}
+
+func attestationProto(a domain.Attestation) *pb.Attestation {
+	r := attestationResponse(a)
+	return &pb.Attestation{
+		Id:         r.ID,
+		ProjectId:  r.ProjectID,
+		EntityKind: r.EntityKind,
+		EntityId:   r.EntityID,
+		Kind:       r.Kind,
+		ActorId:    r.ActorID,
+		Ts:         r.TS,
+		Payload:    jsonStruct(r.Payload),
+		ExpiredAt:  r.ExpiredAt,
+	}
+}
+
+func eventProto(e domain.Event) *pb.Event {
+	r := eventResponse(e)
+	return &pb.Event{
+		Id:         r.ID,
+		Ts:         r.TS,
+		Type:       r.Type,
+		ProjectId:  r.ProjectID,
+		EntityKind: r.EntityKind,
+		EntityId:   r.EntityID,
+		ActorId:    r.ActorID,
+		Payload:    jsonStruct(r.Payload),
+	}
+}
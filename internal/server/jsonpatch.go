@@ -0,0 +1,180 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOperation is one RFC 6902 operation. Only add, remove, replace and
+// test are supported.
+type JSONPatchOperation struct {
+	Op    string `json:"op" enum:"add,remove,replace,test"`
+	Path  string `json:"path" doc:"JSON Pointer (RFC 6901) into the work outcomes"`
+	Value any    `json:"value,omitempty"`
+}
+
+// jsonPatchTestError reports a failed test operation; the document is left
+// unchanged.
+type jsonPatchTestError struct {
+	Index int
+	Path  string
+}
+
+func (e jsonPatchTestError) Error() string {
+	return fmt.Sprintf("patch operation %d: test failed at %s", e.Index, e.Path)
+}
+
+// applyJSONPatch applies ops to doc in order. On error doc may be partially
+// modified, so callers apply patches to a copy they can discard.
+func applyJSONPatch(doc map[string]any, ops []JSONPatchOperation) error {
+	var root any = doc
+	for i, op := range ops {
+		tokens, err := parseJSONPointer(op.Path)
+		if err != nil {
+			return fmt.Errorf("invalid patch operation %d: %w", i, err)
+		}
+		switch op.Op {
+		case "add", "remove", "replace", "test":
+		default:
+			return fmt.Errorf("invalid patch operation %d: unsupported op %q", i, op.Op)
+		}
+		if root, err = applyJSONPatchOp(root, tokens, op); err != nil {
+			if te, ok := err.(jsonPatchTestError); ok {
+				te.Index, te.Path = i, op.Path
+				return te
+			}
+			return fmt.Errorf("invalid patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	obj, ok := root.(map[string]any)
+	if !ok {
+		return fmt.Errorf("invalid patch: work outcomes must remain an object")
+	}
+	if reflect.ValueOf(obj).Pointer() != reflect.ValueOf(doc).Pointer() {
+		clear(doc)
+		for k, v := range obj {
+			doc[k] = v
+		}
+	}
+	return nil
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must be empty or start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+	}
+	return tokens, nil
+}
+
+func applyJSONPatchOp(node any, tokens []string, op JSONPatchOperation) (any, error) {
+	if len(tokens) == 0 {
+		switch op.Op {
+		case "add", "replace":
+			return op.Value, nil
+		case "test":
+			if !jsonEqual(node, op.Value) {
+				return nil, jsonPatchTestError{}
+			}
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot remove the whole document")
+		}
+	}
+	key, rest := tokens[0], tokens[1:]
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[key]
+		if len(rest) > 0 {
+			if !exists {
+				return nil, fmt.Errorf("member %q not found", key)
+			}
+			updated, err := applyJSONPatchOp(child, rest, op)
+			if err != nil {
+				return nil, err
+			}
+			n[key] = updated
+			return n, nil
+		}
+		if !exists && op.Op != "add" {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		switch op.Op {
+		case "add", "replace":
+			n[key] = op.Value
+		case "remove":
+			delete(n, key)
+		case "test":
+			if !jsonEqual(child, op.Value) {
+				return nil, jsonPatchTestError{}
+			}
+		}
+		return n, nil
+	case []any:
+		if key == "-" && len(rest) == 0 && op.Op == "add" {
+			return append(n, op.Value), nil
+		}
+		idx, err := jsonArrayIndex(key)
+		if err != nil {
+			return nil, err
+		}
+		limit := len(n)
+		if op.Op == "add" && len(rest) == 0 {
+			limit++
+		}
+		if idx >= limit {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		if len(rest) > 0 {
+			updated, err := applyJSONPatchOp(n[idx], rest, op)
+			if err != nil {
+				return nil, err
+			}
+			n[idx] = updated
+			return n, nil
+		}
+		switch op.Op {
+		case "add":
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = op.Value
+		case "replace":
+			n[idx] = op.Value
+		case "remove":
+			n = append(n[:idx], n[idx+1:]...)
+		case "test":
+			if !jsonEqual(n[idx], op.Value) {
+				return nil, jsonPatchTestError{}
+			}
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot address %q inside a scalar value", key)
+	}
+}
+
+func jsonArrayIndex(token string) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("array index %q must be a non-negative integer without leading zeros", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("array index %q must be a non-negative integer without leading zeros", token)
+	}
+	return idx, nil
+}
+
+// jsonEqual compares decoded JSON values; both sides come from encoding/json,
+// so numbers are float64 on either side.
+func jsonEqual(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
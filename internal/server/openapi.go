@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+// OpenAPISpec renders the API description served at <base>/openapi.json
+// without a database, for client generators. The output is indented JSON with
+// sorted keys so regenerating it only produces a diff when the API changes.
+func OpenAPISpec(basePath string) ([]byte, error) {
+	if basePath == "" {
+		basePath = "/v0"
+	}
+	if !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	configureHuma()
+	api := newHumaAPI(chi.NewRouter())
+	// Handlers only touch the engine when called, so a zero Config is enough
+	// to describe every operation.
+	registerRoutes(huma.NewGroup(api, basePath), Config{})
+	spec, err := buildOpenAPISpec(api, basePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// buildOpenAPISpec finalizes the generated document: every operation gets the
+// error envelope as its default response and the auth requirements, and
+// operation IDs are checked so generated client method names stay stable.
+func buildOpenAPISpec(api huma.API, basePath string) (map[string]any, error) {
+	oas := api.OpenAPI()
+	registerErrorSchema(oas)
+	ensureDefaultErrorResponses(oas)
+	applyAuthSecurity(oas, basePath)
+	if err := checkOperationIDs(oas); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(oas)
+	if err != nil {
+		return nil, err
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// checkOperationIDs rejects operations without an explicit ID or sharing one,
+// since generators fall back to path-derived names that change with routes.
+func checkOperationIDs(oas *huma.OpenAPI) error {
+	seen := map[string]string{}
+	var problems []string
+	for route, item := range oas.Paths {
+		for method, op := range map[string]*huma.Operation{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+			"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch, "TRACE": item.Trace,
+		} {
+			if op == nil {
+				continue
+			}
+			where := method + " " + route
+			if op.OperationID == "" {
+				problems = append(problems, where+": missing operation id")
+				continue
+			}
+			if prev, ok := seen[op.OperationID]; ok {
+				problems = append(problems, fmt.Sprintf("%s: operation id %q already used by %s", where, op.OperationID, prev))
+				continue
+			}
+			seen[op.OperationID] = where
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// registerErrorSchema makes sure the ApiError component exists even when no
+// operation lists explicit error statuses.
+func registerErrorSchema(oas *huma.OpenAPI) {
+	if oas.Components == nil || oas.Components.Schemas == nil {
+		return
+	}
+	oas.Components.Schemas.Schema(reflect.TypeOf(apiError{}), true, "ApiError")
+}
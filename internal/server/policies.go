@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/engine"
+)
+
+func registerPolicies(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "simulate-policy",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/policies/simulate",
+		Summary:     "Simulate the policy of a hypothetical task",
+		Description: "Reports which policy a task of this type and component would get, and which required attestations would still be missing for done given the ones listed. Nothing is created.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Body      SimulatePolicyRequest
+	}) (*struct {
+		Body PolicyCheckResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		check, err := e.SimulatePolicy(ctx, projectID, engine.PolicySimulation{
+			Type:         input.Body.Type,
+			Component:    strPtrValue(input.Body.Component),
+			Policy:       strPtrValue(input.Body.Policy),
+			Attestations: input.Body.Attestations,
+		}, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body PolicyCheckResponse `json:"body"`
+		}{Body: policyCheckResponse(check)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "verify-project",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/verify",
+		Summary:     "Verify completed tasks against their policies",
+		Description: "Re-checks every completed task against its required attestations (valid at completion or added since) and against the policy the config applies today, catching tasks completed with force or before a policy was tightened. With flag=true, records a compliance.flagged event per non-compliant task, which needs project.verify; every flagged run records new events.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Flag      bool   `query:"flag" doc:"Record a compliance.flagged event per non-compliant task"`
+	}) (*struct {
+		Body ComplianceReportResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		report, err := e.VerifyProject(ctx, projectID, actorID, input.Flag)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ComplianceReportResponse `json:"body"`
+		}{Body: complianceReportResponse(report)}, nil
+	})
+}
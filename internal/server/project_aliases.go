@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"workline/internal/engine"
+)
+
+// newProjectAliasMiddleware rewrites the former id of a renamed project, in
+// the path or the X-Project-Id header, to its current id. Requests relying
+// on a renamed default project get its current id as X-Project-Id.
+func newProjectAliasMiddleware(basePath string, e engine.Engine) func(http.Handler) http.Handler {
+	prefix := path.Join(basePath, "projects") + "/"
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rest, inPath := strings.CutPrefix(req.URL.Path, prefix)
+			if inPath {
+				alias, tail, _ := strings.Cut(rest, "/")
+				if id, err := e.Repo.ResolveProjectAlias(req.Context(), alias); err == nil {
+					req.URL.Path = prefix + id
+					if tail != "" {
+						req.URL.Path += "/" + tail
+					}
+					req.URL.RawPath = ""
+				}
+			}
+			alias := strings.TrimSpace(req.Header.Get("X-Project-Id"))
+			if alias == "" && !inPath && e.Config != nil {
+				alias = e.Config.Project.ID
+			}
+			if alias != "" {
+				if id, err := e.Repo.ResolveProjectAlias(req.Context(), alias); err == nil {
+					req.Header.Set("X-Project-Id", id)
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig lets browser dashboards on other origins call the API.
+type CORSConfig struct {
+	// AllowedOrigins lists origins allowed to call the API; "*" allows any.
+	// Empty disables CORS.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders default to the methods and headers
+	// the API uses.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// MaxAge caches preflight responses, in seconds.
+	MaxAge int
+}
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "X-Api-Key", "If-None-Match"}
+)
+
+func (c CORSConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCORSMiddleware answers preflight requests before authentication and
+// tags responses to allowed origins.
+func newCORSMiddleware(cfg CORSConfig) func(http.Handler) http.Handler {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+			if !cfg.allowsOrigin(origin) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+				if cfg.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// externalURL returns the scheme, host and path prefix clients use to reach
+// the server: publicURL when set, otherwise derived from the request and any
+// X-Forwarded-Proto/Host/Prefix headers set by a reverse proxy. It has no
+// trailing slash.
+func externalURL(req *http.Request, publicURL string) string {
+	if publicURL != "" {
+		return strings.TrimRight(publicURL, "/")
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if proto := firstForwarded(req.Header.Get("X-Forwarded-Proto")); proto != "" {
+		scheme = proto
+	}
+	host := req.Host
+	if fwd := firstForwarded(req.Header.Get("X-Forwarded-Host")); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host + forwardedPrefix(req)
+}
+
+// externalPrefix is the path part of externalURL, used for links in pages
+// served to browsers.
+func externalPrefix(req *http.Request, publicURL string) string {
+	if publicURL != "" {
+		u, err := url.Parse(publicURL)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimRight(u.Path, "/")
+	}
+	return forwardedPrefix(req)
+}
+
+func forwardedPrefix(req *http.Request) string {
+	prefix := strings.TrimRight(firstForwarded(req.Header.Get("X-Forwarded-Prefix")), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// firstForwarded returns the first entry of a comma-separated forwarded
+// header, which is the one set by the proxy closest to the client.
+func firstForwarded(v string) string {
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// newReadOnlyMiddleware rejects every mutating request under the API base
+// path. Minting a dev token writes nothing, so it stays available.
+func newReadOnlyMiddleware(basePath string) func(http.Handler) http.Handler {
+	devLoginPath := path.Join(basePath, "auth/dev/login")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, req)
+				return
+			}
+			if !strings.HasPrefix(req.URL.Path, basePath) || req.URL.Path == devLoginPath {
+				next.ServeHTTP(w, req)
+				return
+			}
+			respondStatusError(w, newAPIError(http.StatusForbidden, "read_only_mode", "server is in read-only mode", nil))
+		})
+	}
+}
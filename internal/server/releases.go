@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/engine"
+)
+
+func registerReleases(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-release",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/iterations/{id}/releases",
+		Summary:       "Record iteration release",
+		Description:   "Records an artifact shipped from the iteration. The iteration must be validated and hold a valid iteration.approved attestation. A name and version can be released once per project. Needs release.create.",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string               `path:"project_id"`
+		ID        string               `path:"id"`
+		Body      CreateReleaseRequest `json:"body"`
+	}) (*struct {
+		Body ReleaseResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		it, err := e.Repo.GetIteration(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, it.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
+		}
+		rel, err := e.RecordRelease(ctx, engine.ReleaseOptions{
+			IterationID: it.ID,
+			Name:        input.Body.Name,
+			Version:     input.Body.Version,
+			Checksum:    input.Body.Checksum,
+			URL:         input.Body.URL,
+			ActorID:     actorID,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ReleaseResponse `json:"body"`
+		}{Body: releaseResponse(rel)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-releases",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/releases",
+		Summary:     "List releases",
+		Description: "Releases of the project, newest first. Needs release.list.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID   string `path:"project_id"`
+		IterationID string `query:"iteration" doc:"Only releases of this iteration"`
+	}) (*struct {
+		Body []ReleaseResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		items, err := e.ListReleases(ctx, projectID, input.IterationID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]ReleaseResponse, 0, len(items))
+		for _, r := range items {
+			resp = append(resp, releaseResponse(r))
+		}
+		return &struct {
+			Body []ReleaseResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"workline/internal/domain"
+)
+
+// renderReportMarkdown formats a status report for pasting into chat, wikis
+// or pull requests.
+func renderReportMarkdown(r domain.StatusReport) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Status report: %s\n\n", escapeMarkdown(r.ProjectID))
+	fmt.Fprintf(&b, "_%s to %s_\n\n", reportDay(r.Since), reportDay(r.Until))
+
+	b.WriteString("## Tasks\n\n")
+	if len(r.TaskCounts) == 0 {
+		b.WriteString("No tasks.\n\n")
+	} else {
+		b.WriteString("| Status | Tasks |\n| --- | ---: |\n")
+		for _, status := range slices.Sorted(maps.Keys(r.TaskCounts)) {
+			fmt.Fprintf(&b, "| %s | %d |\n", escapeMarkdown(status), r.TaskCounts[status])
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Iterations\n\n")
+	if len(r.Iterations) == 0 {
+		b.WriteString("No open iterations.\n\n")
+	} else {
+		b.WriteString("| Iteration | Goal | Status | Done |\n| --- | --- | --- | ---: |\n")
+		for _, it := range r.Iterations {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d/%d |\n", escapeMarkdown(it.ID), escapeMarkdown(it.Goal), it.Status, it.DoneTasks, it.TotalTasks)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Done\n\n")
+	if len(r.DoneTasks) == 0 {
+		b.WriteString("Nothing completed in this period.\n\n")
+	} else {
+		for _, t := range r.DoneTasks {
+			where := "`" + t.ID + "`"
+			if t.IterationID != "" {
+				where += ", " + escapeMarkdown(t.IterationID)
+			}
+			atts := "no attestations"
+			if len(t.Attestations) > 0 {
+				atts = "attested: " + escapeMarkdown(strings.Join(t.Attestations, ", "))
+			}
+			fmt.Fprintf(&b, "- %s (%s, %s) - %s\n", escapeMarkdown(t.Title), where, reportDay(t.CompletedAt), atts)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Blockers\n\n")
+	if len(r.Blockers) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		for _, bl := range r.Blockers {
+			deps := make([]string, 0, len(bl.BlockedBy))
+			for _, d := range bl.BlockedBy {
+				deps = append(deps, "`"+d+"`")
+			}
+			fmt.Fprintf(&b, "- %s (`%s`, %s) waiting on %s\n", escapeMarkdown(bl.Title), bl.ID, bl.Status, strings.Join(deps, ", "))
+		}
+	}
+	return []byte(b.String())
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"|", `\|`, "<", `\<`, ">", `\>`, "#", `\#`, "\n", " ",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// reportDay shortens an RFC 3339 timestamp to its date.
+func reportDay(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.UTC().Format(time.DateOnly)
+}
@@ -3,23 +3,29 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	humachi "github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/danielgtaylor/huma/v2/conditional"
 	"github.com/go-chi/chi/v5"
 
 	"workline/internal/config"
 	"workline/internal/domain"
 	"workline/internal/engine"
 	"workline/internal/engine/auth"
+	"workline/internal/events"
 	"workline/internal/repo"
 )
 
@@ -28,6 +34,38 @@ type Config struct {
 	Engine   engine.Engine
 	BasePath string
 	Auth     AuthConfig
+	Backup   BackupConfig
+	CORS     CORSConfig
+	// PublicURL is the externally visible root URL (scheme, host and any
+	// proxy prefix) advertised in the OpenAPI servers section. When empty it
+	// is derived from X-Forwarded-* headers.
+	PublicURL string
+	// AttestationSweep is how often attestations past their kind's
+	// valid_days are marked expired; 0 disables the sweeper.
+	AttestationSweep time.Duration
+	// StaleSweep is how often tasks idle for project.staleness.days are
+	// marked stale; 0 disables the sweeper.
+	StaleSweep time.Duration
+	// SLASweep is how often open tasks past their due date are reported
+	// with sla.breached; 0 disables the sweeper.
+	SLASweep time.Duration
+	// EscalationSweep is how often project.escalation rules raise the
+	// priority of waiting tasks; 0 disables the sweeper.
+	EscalationSweep time.Duration
+	// RoleExpirySweep is how often role grants past their expires_at are
+	// deleted; 0 disables the sweeper.
+	RoleExpirySweep time.Duration
+	// HookRetry is how often queued webhook hook deliveries that failed are
+	// posted again; 0 disables the retries.
+	HookRetry time.Duration
+	// DigestCheck is how often the scheduled digest is sent when due; 0
+	// disables the scheduler.
+	DigestCheck time.Duration
+	// Leases tracks leases claimed internally by multi-step handlers so the
+	// caller can release leftovers on shutdown. Optional.
+	Leases *LeaseTracker
+	// DisableUI turns off the web dashboard served at /ui.
+	DisableUI bool
 }
 
 type apiErrorBody struct {
@@ -57,8 +95,54 @@ func New(cfg Config) (http.Handler, error) {
 	if !strings.HasPrefix(basePath, "/") {
 		basePath = "/" + basePath
 	}
+	configureHuma()
+
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bodyBytes, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+			ctx := context.WithValue(r.Context(), requestKey{}, r)
+			ctx = context.WithValue(ctx, bodyBytesKey{}, bodyBytes)
+			if cfg.Leases != nil {
+				ctx = context.WithValue(ctx, leaseTrackerKey{}, cfg.Leases)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		router.Use(newCORSMiddleware(cfg.CORS))
+	}
+	if cfg.Engine.ReadOnly {
+		router.Use(newReadOnlyMiddleware(basePath))
+	}
+	router.Use(newProjectAliasMiddleware(basePath, cfg.Engine))
+	router.Use(newAuthMiddleware(basePath, cfg.Auth, cfg.Engine.Repo))
+	api := newHumaAPI(router)
+
+	registerDocs(router, basePath, cfg.PublicURL)
+	if !cfg.DisableUI {
+		registerUI(router, basePath, cfg.PublicURL)
+	}
+	registerRoutes(huma.NewGroup(api, basePath), cfg)
+	registerOpenAPI(router, api, basePath, cfg.PublicURL)
+	startWebhookDispatcher(cfg.Engine)
+	startBackups(cfg.Engine, cfg.Backup)
+	startAttestationSweeper(cfg.Engine, cfg.AttestationSweep)
+	startStaleSweeper(cfg.Engine, cfg.StaleSweep)
+	startSLASweeper(cfg.Engine, cfg.SLASweep)
+	startEscalationSweeper(cfg.Engine, cfg.EscalationSweep)
+	startRoleExpirySweeper(cfg.Engine, cfg.RoleExpirySweep)
+	startHookRetrier(cfg.Engine, cfg.HookRetry)
+	startDigests(cfg.Engine, cfg.DigestCheck)
+
+	return router, nil
+}
+
+// configureHuma points Huma's errors at the API's error envelope. It must run
+// before routes are registered so their error responses document it.
+func configureHuma() {
 	huma.DefaultArrayNullable = false
-	// Override Huma errors to use the requested envelope.
 	huma.NewError = func(status int, msg string, errs ...error) huma.StatusError {
 		return newAPIError(status, "", msg, nil)
 	}
@@ -73,42 +157,43 @@ func New(cfg Config) (http.Handler, error) {
 		}
 		return newAPIError(status, "", msg, details)
 	}
+}
 
-	router := chi.NewRouter()
-	router.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			bodyBytes, _ := io.ReadAll(r.Body)
-			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			ctx := context.WithValue(r.Context(), requestKey{}, r)
-			ctx = context.WithValue(ctx, bodyBytesKey{}, bodyBytes)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	})
-	router.Use(newAuthMiddleware(basePath, cfg.Auth, cfg.Engine.Repo))
+func newHumaAPI(router chi.Router) huma.API {
 	hcfg := huma.DefaultConfig("Workline API", "0.1.1")
 	hcfg.OpenAPIPath = "/openapi"
-	hcfg.DocsPath = "" // custom Swagger UI below
-	api := humachi.New(router, hcfg)
-	group := huma.NewGroup(api, basePath)
+	hcfg.DocsPath = "" // custom Swagger UI at /docs
+	return humachi.New(router, hcfg)
+}
 
-	registerDocs(router, basePath)
+// registerRoutes registers every API operation on group.
+func registerRoutes(group huma.API, cfg Config) {
 	registerHealth(group)
 	registerStatus(group, cfg.Engine)
+	registerOrgs(group, cfg.Engine)
 	registerProjects(group, cfg.Engine)
 	registerTasks(group, cfg.Engine)
 	registerValidations(group, cfg.Engine)
+	registerPolicies(group, cfg.Engine)
+	registerForce(group, cfg.Engine)
+	registerWebhookDeliveries(group, cfg.Engine)
 	registerIterations(group, cfg.Engine)
+	registerBoard(group, cfg.Engine)
+	registerEscalations(group, cfg.Engine)
+	registerMilestones(group, cfg.Engine)
+	registerReleases(group, cfg.Engine)
+	registerChangelog(group, cfg.Engine)
+	registerCalendar(group, cfg.Engine)
 	registerDecisions(group, cfg.Engine)
 	registerAttestations(group, cfg.Engine)
 	registerEvents(group, cfg.Engine)
+	registerChanges(group, cfg.Engine)
+	registerExport(group, cfg.Engine)
 	registerRBAC(group, cfg.Engine)
+	registerOrgRBAC(group, cfg.Engine)
 	registerActorMissions(group, cfg.Engine)
 	registerMe(group, cfg.Engine)
 	registerDevAuth(group, cfg.Engine, cfg.Auth)
-	registerOpenAPI(router, api, basePath)
-	startWebhookDispatcher(cfg.Engine)
-
-	return router, nil
 }
 
 func newAPIError(status int, code, message string, details map[string]any) huma.StatusError {
@@ -137,6 +222,72 @@ func handleError(err error) huma.StatusError {
 	if errors.As(err, &ae) {
 		return newAPIError(http.StatusForbidden, "forbidden_attestation_kind", err.Error(), map[string]any{"kind": ae.Kind})
 	}
+	var re auth.ForbiddenRoleError
+	if errors.As(err, &re) {
+		return newAPIError(http.StatusForbidden, "forbidden_role", err.Error(), map[string]any{"task_type": re.TaskType, "roles": re.Roles})
+	}
+	var tl engine.EvidenceTooLargeError
+	if errors.As(err, &tl) {
+		return newAPIError(http.StatusRequestEntityTooLarge, "payload_too_large", err.Error(), map[string]any{"limit": tl.Limit})
+	}
+	var pe engine.AttestationPayloadError
+	if errors.As(err, &pe) {
+		return newAPIError(http.StatusBadRequest, "invalid_payload", err.Error(), map[string]any{"kind": pe.Kind, "violations": pe.Violations})
+	}
+	var we engine.WorkOutcomesError
+	if errors.As(err, &we) {
+		return newAPIError(http.StatusUnprocessableEntity, "invalid_work_outcomes", err.Error(), map[string]any{"task_type": we.TaskType, "size": we.Size, "max_bytes": we.MaxBytes, "violations": we.Violations})
+	}
+	var ce engine.CapacityExceededError
+	if errors.As(err, &ce) {
+		return newAPIError(http.StatusConflict, "capacity_exceeded", err.Error(), map[string]any{"iteration_id": ce.IterationID, "capacity": ce.Capacity, "planned": ce.Planned})
+	}
+	var fp engine.ForcePendingError
+	if errors.As(err, &fp) {
+		return newAPIError(http.StatusConflict, "force_pending", err.Error(), map[string]any{"request_id": fp.RequestID, "operation": fp.Operation})
+	}
+	var fa engine.ForceApprovalError
+	if errors.As(err, &fa) {
+		status := http.StatusConflict
+		if fa.Reason == "self_approval" {
+			status = http.StatusForbidden
+		}
+		return newAPIError(status, fa.Reason, err.Error(), map[string]any{"request_id": fa.RequestID})
+	}
+	var wip engine.WIPExceededError
+	if errors.As(err, &wip) {
+		return newAPIError(http.StatusConflict, "wip_exceeded", err.Error(), map[string]any{"status": wip.Status, "limit": wip.Limit, "count": wip.Count})
+	}
+	var wp workOutcomesPathError
+	if errors.As(err, &wp) {
+		return newAPIError(http.StatusConflict, "path_conflict", err.Error(), map[string]any{"path": wp.Path, "expected": wp.Want, "found": wp.Got})
+	}
+	var pt jsonPatchTestError
+	if errors.As(err, &pt) {
+		return newAPIError(http.StatusConflict, "patch_test_failed", err.Error(), map[string]any{"index": pt.Index, "path": pt.Path})
+	}
+	var ru engine.RevertUnavailableError
+	if errors.As(err, &ru) {
+		return newAPIError(http.StatusConflict, "revert_unavailable", err.Error(), map[string]any{"task_id": ru.TaskID})
+	}
+	if errors.Is(err, engine.ErrWorkOutcomesConflict) {
+		return newAPIError(http.StatusConflict, "version_conflict", err.Error(), nil)
+	}
+	var pa engine.ProjectArchivedError
+	if errors.As(err, &pa) {
+		return newAPIError(http.StatusConflict, "project_archived", err.Error(), map[string]any{"project_id": pa.ProjectID, "permission": pa.Permission})
+	}
+	var ro engine.ReadOnlyError
+	if errors.As(err, &ro) {
+		return newAPIError(http.StatusForbidden, "read_only_mode", err.Error(), map[string]any{"permission": ro.Permission})
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return newAPIError(http.StatusServiceUnavailable, "request_cancelled", "request cancelled before completing", nil)
+	}
+	var se auth.SuspendedActorError
+	if errors.As(err, &se) {
+		return newAPIError(http.StatusForbidden, "actor_suspended", err.Error(), map[string]any{"actor_id": se.ActorID})
+	}
 	if errors.Is(err, repo.ErrNotFound) {
 		return newAPIError(http.StatusNotFound, "not_found", err.Error(), nil)
 	}
@@ -145,19 +296,47 @@ func handleError(err error) huma.StatusError {
 	switch {
 	case strings.Contains(lowered, "lease") && (strings.Contains(lowered, "held") || strings.Contains(lowered, "owned")):
 		return newAPIError(http.StatusConflict, "lease_conflict", msg, nil)
-	case strings.Contains(lowered, "lease required"):
+	case strings.Contains(lowered, "lease required"), strings.Contains(lowered, "lease expired"):
 		return newAPIError(http.StatusConflict, "lease_conflict", msg, nil)
+	case strings.Contains(lowered, "already exists"):
+		return newAPIError(http.StatusConflict, "conflict", msg, nil)
 	case strings.Contains(lowered, "not done"),
 		strings.Contains(lowered, "validation"),
 		strings.Contains(lowered, "required for iteration validation"):
 		return newAPIError(http.StatusUnprocessableEntity, "validation_failed", msg, nil)
-	case strings.Contains(lowered, "invalid") || strings.Contains(lowered, "missing") || strings.Contains(lowered, "required"):
+	case strings.Contains(lowered, "invalid") || strings.Contains(lowered, "missing") || strings.Contains(lowered, "required") || strings.Contains(lowered, "unknown"):
 		return newAPIError(http.StatusBadRequest, "bad_request", msg, nil)
 	default:
 		return newAPIError(http.StatusInternalServerError, "internal_error", "internal error", map[string]any{"error": msg})
 	}
 }
 
+// itemError maps the error of one item of a batch request to the status and
+// error body it would have had as a single request.
+func itemError(err error) (int, *apiErrorBody) {
+	if ae, ok := handleError(err).(*apiError); ok {
+		return ae.status, &ae.Body
+	}
+	return http.StatusInternalServerError, &apiErrorBody{Code: "internal_error", Message: err.Error()}
+}
+
+// DescribeError reports err the way the API would: its HTTP status, stable
+// error code and details. Unlike API responses, the message of an internal
+// error is kept.
+func DescribeError(err error) (status int, code, message string, details map[string]any) {
+	if err == nil {
+		return http.StatusOK, "", "", nil
+	}
+	var ae *apiError
+	if !errors.As(handleError(err), &ae) {
+		return http.StatusInternalServerError, "internal_error", err.Error(), nil
+	}
+	if ae.status == http.StatusInternalServerError {
+		return ae.status, ae.Body.Code, err.Error(), nil
+	}
+	return ae.status, ae.Body.Code, ae.Body.Message, ae.Body.Details
+}
+
 func defaultCodeForStatus(status int) string {
 	switch status {
 	case http.StatusBadRequest:
@@ -187,19 +366,7 @@ func hasPermission(perms []string, perm string) bool {
 }
 
 func requirePermission(ctx context.Context, e engine.Engine, projectID, perm string) error {
-	principal, authErr := principalFromRequest(ctx)
-	if authErr != nil {
-		return authErr
-	}
-	if hasPermission(principal.Permissions, perm) {
-		return nil
-	}
-	tx, err := e.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-	ok, err := e.Auth.ActorHasPermission(ctx, tx, projectID, principal.ActorID, perm)
+	ok, err := hasProjectPermission(ctx, e, projectID, perm)
 	if err != nil {
 		return err
 	}
@@ -209,6 +376,40 @@ func requirePermission(ctx context.Context, e engine.Engine, projectID, perm str
 	return nil
 }
 
+// auditRead records the caller's read of resource under the project's
+// read_audit config; target defaults to the request's method and URI.
+func auditRead(ctx context.Context, e engine.Engine, projectID, resource, target string) error {
+	principal, authErr := principalFromRequest(ctx)
+	if authErr != nil {
+		return authErr
+	}
+	if target == "" {
+		if req, ok := ctx.Value(requestKey{}).(*http.Request); ok && req != nil {
+			target = req.Method + " " + req.URL.RequestURI()
+		}
+	}
+	return e.RecordRead(ctx, projectID, principal.ActorID, resource, target)
+}
+
+// hasProjectPermission reports whether the caller holds perm, through its
+// principal or its roles in the project.
+func hasProjectPermission(ctx context.Context, e engine.Engine, projectID, perm string) (bool, error) {
+	principal, authErr := principalFromRequest(ctx)
+	if authErr != nil {
+		return false, authErr
+	}
+	if hasPermission(principal.Permissions, perm) {
+		return true, nil
+	}
+	return e.HasPermission(ctx, projectID, principal.ActorID, perm)
+}
+
+// canReadAuditEvents reports whether the caller may see audit events
+// (events.AuditEntityKind) next to the rest of the project's events.
+func canReadAuditEvents(ctx context.Context, e engine.Engine, projectID string) (bool, error) {
+	return hasProjectPermission(ctx, e, projectID, "events.audit.read")
+}
+
 func requireGlobalPermission(ctx context.Context, e engine.Engine, perm string) error {
 	principal, authErr := principalFromRequest(ctx)
 	if authErr != nil {
@@ -220,28 +421,43 @@ func requireGlobalPermission(ctx context.Context, e engine.Engine, perm string)
 	if e.Config == nil {
 		return auth.ForbiddenError{Permission: perm}
 	}
-	return requirePermission(ctx, e, e.Config.Project.ID, perm)
+	// Global permissions come from the default project, which may since have
+	// been renamed.
+	projectID := e.Config.Project.ID
+	if id, err := e.Repo.ResolveProjectAlias(ctx, projectID); err == nil {
+		projectID = id
+	}
+	return requirePermission(ctx, e, projectID, perm)
 }
 
-func registerDocs(r chi.Router, basePath string) {
+func registerDocs(r chi.Router, basePath, publicURL string) {
 	r.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
-		io.WriteString(w, swaggerHTML(basePath))
+		io.WriteString(w, swaggerHTML(externalPrefix(r, publicURL)+basePath))
 	})
 }
 
-func registerOpenAPI(r chi.Router, api huma.API, basePath string) {
-	var spec []byte
+func registerOpenAPI(r chi.Router, api huma.API, basePath, publicURL string) {
+	var spec map[string]any
 	specPath := path.Join(basePath, "openapi.json")
 	r.Get(specPath, func(w http.ResponseWriter, r *http.Request) {
 		if spec == nil {
-			oas := api.OpenAPI()
-			ensureDefaultErrorResponses(oas)
-			applyAuthSecurity(oas, basePath)
-			spec, _ = json.Marshal(oas)
+			built, err := buildOpenAPISpec(api, basePath)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			spec = built
 		}
+		// Paths carry the base path, so the server URL is just where clients
+		// reach the root, which depends on the proxy in front of us.
+		doc := make(map[string]any, len(spec)+1)
+		for k, v := range spec {
+			doc[k] = v
+		}
+		doc["servers"] = []huma.Server{{URL: externalURL(r, publicURL)}}
 		w.Header().Set("Content-Type", "application/json")
-		w.Write(spec)
+		json.NewEncoder(w).Encode(doc)
 	})
 }
 
@@ -291,10 +507,17 @@ func applyAuthSecurity(oas *huma.OpenAPI, basePath string) {
 		In:   "header",
 		Name: "X-Api-Key",
 	}
+	oas.Components.SecuritySchemes["calendarToken"] = &huma.SecurityScheme{
+		Type:        "apiKey",
+		In:          "query",
+		Name:        calendarTokenParam,
+		Description: "Calendar feed token from POST /projects/{project_id}/calendar/feeds; API keys are not accepted here.",
+	}
 	security := []map[string][]string{
 		{"bearerAuth": {}},
 		{"apiKeyAuth": {}},
 	}
+	calendarSecurity := append(append([]map[string][]string{}, security...), map[string][]string{"calendarToken": {}})
 	oas.Security = security
 	healthPath := path.Join(basePath, "health")
 	devLoginPath := path.Join(basePath, "auth/dev/login")
@@ -319,6 +542,10 @@ func applyAuthSecurity(oas *huma.OpenAPI, basePath string) {
 				op.Security = []map[string][]string{}
 				continue
 			}
+			if isCalendarPath(route) {
+				op.Security = calendarSecurity
+				continue
+			}
 			op.Security = security
 		}
 	}
@@ -391,6 +618,10 @@ func registerStatus(api huma.API, e engine.Engine) {
 		if err != nil {
 			return nil, handleError(err)
 		}
+		componentCounts, err := e.Repo.CountTasksByComponent(ctx, p.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
 		running, err := e.Repo.LatestRunningIteration(ctx, p.ID)
 		if err != nil {
 			return nil, handleError(err)
@@ -398,780 +629,2951 @@ func registerStatus(api huma.API, e engine.Engine) {
 		return &struct {
 			Body map[string]any `json:"body"`
 		}{Body: map[string]any{
-			"project_id":  p.ID,
-			"status":      p.Status,
-			"iteration":   running,
-			"task_counts": counts,
+			"project_id":       p.ID,
+			"status":           p.Status,
+			"iteration":        running,
+			"task_counts":      counts,
+			"component_counts": componentCounts,
 		}}, nil
 	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "project-dashboard",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/dashboard",
+		Summary:     "Quality gate metrics",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *projectPath) (*struct {
+		Body DashboardResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		d, err := e.Dashboard(ctx, projectID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body DashboardResponse `json:"body"`
+		}{Body: dashboardResponse(d)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "project-report",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/report",
+		Summary:     "Shareable status report",
+		Description: "Iteration summary, tasks done in the last days with their attestations, and tasks blocked by unfinished dependencies, as Markdown.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Format    string `query:"format" enum:"md" default:"md"`
+		Days      int    `query:"days" minimum:"1" maximum:"366" default:"7" doc:"Report window in days, ending now"`
+	}) (*struct {
+		ContentType string `header:"Content-Type"`
+		Body        []byte
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		r, err := e.StatusReport(ctx, projectID, actorID, input.Days)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			ContentType string `header:"Content-Type"`
+			Body        []byte
+		}{
+			ContentType: "text/markdown; charset=utf-8",
+			Body:        renderReportMarkdown(r),
+		}, nil
+	})
 }
 
-func registerProjects(api huma.API, e engine.Engine) {
+func registerOrgs(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "create-project",
+		OperationID:   "create-org",
 		Method:        http.MethodPost,
-		Path:          "/projects",
-		Summary:       "Create project",
+		Path:          "/orgs",
+		Summary:       "Create organization",
 		DefaultStatus: http.StatusCreated,
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
-			http.StatusNotFound,
 			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		Body CreateProjectRequest `json:"body"`
+		Body CreateOrgRequest `json:"body"`
 	}) (*struct {
-		Body ProjectResponse `json:"body"`
+		Body OrgResponse `json:"body"`
 	}, error) {
 		if len(bodyBytes(ctx)) == 0 {
 			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
 		}
-		if input.Body.ID == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "id is required", nil)
-		}
-		if input.Body.OrgID == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "org_id is required", nil)
-		}
-		if err := requireGlobalPermission(ctx, e, "project.create"); err != nil {
+		if err := requireGlobalPermission(ctx, e, "org.create"); err != nil {
 			return nil, handleError(err)
 		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		desc := ""
-		if input.Body.Description != nil {
-			desc = *input.Body.Description
-		}
-		p, err := e.InitProject(ctx, input.Body.ID, input.Body.OrgID, desc, actorID)
+		org, err := e.CreateOrg(ctx, input.Body.ID, input.Body.Name, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if err := e.Repo.UpsertProjectConfig(ctx, p.ID, config.Default(p.ID)); err != nil {
-			return nil, handleError(err)
-		}
 		return &struct {
-			Body ProjectResponse `json:"body"`
-		}{Body: projectResponse(p)}, nil
+			Body OrgResponse `json:"body"`
+		}{Body: orgResponse(org)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "list-projects",
+		OperationID: "list-orgs",
 		Method:      http.MethodGet,
-		Path:        "/projects",
-		Summary:     "List projects",
-		Errors:      []int{http.StatusBadRequest},
+		Path:        "/orgs",
+		Summary:     "List organizations of the caller",
+		Errors:      []int{http.StatusUnauthorized},
 	}, func(ctx context.Context, _ *struct{}) (*struct {
-		Body []ProjectResponse `json:"body"`
+		Body []OrgResponse `json:"body"`
 	}, error) {
-		if err := requireGlobalPermission(ctx, e, "project.list"); err != nil {
-			return nil, handleError(err)
+		principal, authErr := principalFromRequest(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		items, err := e.Repo.ListProjects(ctx)
+		items, err := e.Repo.ListOrgsForActor(ctx, principal.ActorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
+		res := make([]OrgResponse, 0, len(items))
+		for _, org := range items {
+			if principal.OrgID != "" && org.ID != principal.OrgID {
+				continue
+			}
+			res = append(res, orgResponse(org))
+		}
 		return &struct {
-			Body []ProjectResponse `json:"body"`
-		}{Body: mapProjects(items)}, nil
+			Body []OrgResponse `json:"body"`
+		}{Body: res}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "get-project",
+		OperationID: "get-org",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}",
-		Summary:     "Get project",
-		Errors:      []int{http.StatusNotFound},
+		Path:        "/orgs/{org_id}",
+		Summary:     "Get organization",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
+		OrgID string `path:"org_id"`
 	}) (*struct {
-		Body ProjectResponse `json:"body"`
+		Body OrgResponse `json:"body"`
 	}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "project.read"); err != nil {
+		if err := requireOrgMember(ctx, e, input.OrgID); err != nil {
 			return nil, handleError(err)
 		}
-		p, err := e.Repo.GetProject(ctx, projectID)
+		org, err := e.Repo.GetOrg(ctx, input.OrgID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body ProjectResponse `json:"body"`
-		}{Body: projectResponse(p)}, nil
+			Body OrgResponse `json:"body"`
+		}{Body: orgResponse(org)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "update-project",
-		Method:      http.MethodPatch,
-		Path:        "/projects/{project_id}",
-		Summary:     "Update project",
+		OperationID: "get-org-status",
+		Method:      http.MethodGet,
+		Path:        "/orgs/{org_id}/status",
+		Summary:     "Portfolio status of an organization",
+		Description: "Running iteration, task counts by status and blocked tasks of each active project in the org, for portfolio stand-ups. Projects the caller lacks project.status.read on are left out.",
 		Errors: []int{
-			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-		Body      struct {
-			Status      string  `json:"status,omitempty"`
-			Description *string `json:"description,omitempty"`
-		} `json:"body"`
+		OrgID string `path:"org_id"`
 	}) (*struct {
-		Body ProjectResponse `json:"body"`
+		Body OrgStatusResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		if err := requireOrgMember(ctx, e, input.OrgID); err != nil {
+			return nil, handleError(err)
 		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "project.update"); err != nil {
+		if _, err := e.Repo.GetOrg(ctx, input.OrgID); err != nil {
 			return nil, handleError(err)
 		}
-		if _, authErr := actorIDFromContext(ctx); authErr != nil {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
 			return nil, authErr
 		}
-		if err := e.Repo.UpdateProject(ctx, projectID, input.Body.Status, input.Body.Description); err != nil {
-			return nil, handleError(err)
-		}
-		p, err := e.Repo.GetProject(ctx, projectID)
+		overviews, err := e.ProjectOverviews(ctx, input.OrgID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body ProjectResponse `json:"body"`
-		}{Body: projectResponse(p)}, nil
+			Body OrgStatusResponse `json:"body"`
+		}{Body: orgStatusResponse(input.OrgID, overviews)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "delete-project",
-		Method:      http.MethodDelete,
-		Path:        "/projects/{project_id}",
-		Summary:     "Delete project",
+		OperationID: "list-org-members",
+		Method:      http.MethodGet,
+		Path:        "/orgs/{org_id}/members",
+		Summary:     "List organization members",
 		Errors: []int{
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-	}) (*struct{}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "project.delete"); err != nil {
+		OrgID string `path:"org_id"`
+	}) (*struct {
+		Body []OrgMemberResponse `json:"body"`
+	}, error) {
+		if err := requireOrgMember(ctx, e, input.OrgID); err != nil {
 			return nil, handleError(err)
 		}
-		if _, authErr := actorIDFromContext(ctx); authErr != nil {
-			return nil, authErr
-		}
-		if err := e.Repo.DeleteProject(ctx, projectID); err != nil {
+		items, err := e.Repo.ListOrgMembers(ctx, input.OrgID)
+		if err != nil {
 			return nil, handleError(err)
 		}
-		return &struct{}{}, nil
+		res := make([]OrgMemberResponse, 0, len(items))
+		for _, m := range items {
+			res = append(res, orgMemberResponse(m))
+		}
+		return &struct {
+			Body []OrgMemberResponse `json:"body"`
+		}{Body: res}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "get-project-config",
-		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/config",
-		Summary:     "Get project config",
-		Errors:      []int{http.StatusNotFound},
-	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-	}) (*struct {
-		Body ProjectConfigResponse `json:"body"`
+		OperationID: "set-org-member",
+		Method:      http.MethodPut,
+		Path:        "/orgs/{org_id}/members/{actor_id}",
+		Summary:     "Add or update organization member",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+		},
+	}, func(ctx context.Context, input *struct {
+		OrgID   string           `path:"org_id"`
+		ActorID string           `path:"actor_id"`
+		Body    OrgMemberRequest `json:"body"`
+	}) (*struct {
+		Body OrgMemberResponse `json:"body"`
 	}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "project.config.read"); err != nil {
-			return nil, handleError(err)
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		cfg, err := e.Repo.GetProjectConfig(ctx, projectID)
+		m, err := e.SetOrgMember(ctx, input.OrgID, actorID, input.ActorID, input.Body.Role)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body ProjectConfigResponse `json:"body"`
-		}{Body: configResponse(cfg)}, nil
+			Body OrgMemberResponse `json:"body"`
+		}{Body: orgMemberResponse(m)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "remove-org-member",
+		Method:      http.MethodDelete,
+		Path:        "/orgs/{org_id}/members/{actor_id}",
+		Summary:     "Remove organization member",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+		},
+	}, func(ctx context.Context, input *struct {
+		OrgID   string `path:"org_id"`
+		ActorID string `path:"actor_id"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if err := e.RemoveOrgMember(ctx, input.OrgID, actorID, input.ActorID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
 	})
 }
 
-func registerTasks(api huma.API, e engine.Engine) {
+func registerOrgRBAC(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "create-task",
+		OperationID: "grant-org-role",
+		Method:      http.MethodPost,
+		Path:        "/orgs/{org_id}/rbac/roles/grant",
+		Summary:     "Grant role in every project of the org",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		OrgID string            `path:"org_id"`
+		Body  RoleChangeRequest `json:"body"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if input.Body.ExpiresAt != "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "expires_at applies to project grants only", nil)
+		}
+		if err := e.GrantOrgRole(ctx, input.OrgID, actorID, input.Body.ActorID, input.Body.RoleID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "revoke-org-role",
+		Method:      http.MethodPost,
+		Path:        "/orgs/{org_id}/rbac/roles/revoke",
+		Summary:     "Revoke org-level role",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		OrgID string            `path:"org_id"`
+		Body  RoleChangeRequest `json:"body"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if err := e.RevokeOrgRole(ctx, input.OrgID, actorID, input.Body.ActorID, input.Body.RoleID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
+	})
+}
+
+func requireOrgMember(ctx context.Context, e engine.Engine, orgID string) error {
+	actorID, authErr := actorIDFromContext(ctx)
+	if authErr != nil {
+		return authErr
+	}
+	if _, err := e.OrgRole(ctx, orgID, actorID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return auth.ForbiddenError{Permission: "org.read"}
+		}
+		return err
+	}
+	return nil
+}
+
+func registerProjects(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-project",
 		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/tasks",
-		Summary:       "Create task",
+		Path:          "/projects",
+		Summary:       "Create project",
 		DefaultStatus: http.StatusCreated,
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusUnprocessableEntity,
 			http.StatusConflict,
+			http.StatusUnprocessableEntity,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string            `path:"project_id"`
-		Body      CreateTaskRequest `json:"body"`
+		Body CreateProjectRequest `json:"body"`
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		Body ProjectResponse `json:"body"`
 	}, error) {
-		bodyMap := rawBodyMap(ctx)
 		if len(bodyBytes(ctx)) == 0 {
 			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
 		}
-		if input.Body.Title == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "title is required", nil)
+		if input.Body.ID == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "id is required", nil)
 		}
-		if input.Body.Type == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "type is required", nil)
+		if input.Body.OrgID == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "org_id is required", nil)
 		}
-		if isNullRaw(bodyMap["depends_on"]) {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "depends_on must be array", map[string]any{"field": "depends_on", "reason": "must be array"})
+		if err := requireGlobalPermission(ctx, e, "project.create"); err != nil {
+			return nil, handleError(err)
+		}
+		if principal, ok := principalFromContext(ctx); ok && principal.OrgID != "" && principal.OrgID != input.Body.OrgID {
+			return nil, newAPIError(http.StatusForbidden, "org_mismatch", "token org does not match project org", map[string]any{"org_id": principal.OrgID})
 		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		opts := engine.TaskCreateOptions{
-			ProjectID:   projectID,
-			Type:        input.Body.Type,
-			Title:       input.Body.Title,
-			ActorID:     actorID,
-			Description: stringOrEmpty(input.Body.Description),
-			DependsOn:   input.Body.DependsOn,
-		}
-		if input.Body.ID != nil {
-			opts.ID = *input.Body.ID
-		}
-		if input.Body.IterationID != nil {
-			opts.IterationID = *input.Body.IterationID
-		}
-		if input.Body.ParentID != nil {
-			opts.ParentID = *input.Body.ParentID
-		}
-		if input.Body.AssigneeID != nil {
-			opts.AssigneeID = *input.Body.AssigneeID
-		}
-		if input.Body.Priority != nil {
-			opts.Priority = input.Body.Priority
-		}
-		if input.Body.Policy != nil {
-			opts.PolicyPreset = input.Body.Policy.Preset
-		} else if rawPolicy, ok := bodyMap["policy"]; ok {
-			var policy TaskPolicyRequest
-			if err := json.Unmarshal(rawPolicy, &policy); err == nil && policy.Preset != "" {
-				opts.PolicyPreset = policy.Preset
-			}
-		}
-		if rawValidation, ok := bodyMap["validation"]; ok {
-			var validationMap map[string]json.RawMessage
-			if len(rawValidation) > 0 {
-				_ = json.Unmarshal(rawValidation, &validationMap)
-				if isNullRaw(validationMap["require"]) {
-					return nil, newAPIError(http.StatusBadRequest, "bad_request", "validation.require must be array", map[string]any{"field": "validation.require", "reason": "must be array"})
-				}
-			}
-			if input.Body.Validation != nil {
-				opts.PolicyOverride = true
-				opts.RequiredKinds = input.Body.Validation.Require
-			}
+		desc := ""
+		if input.Body.Description != nil {
+			desc = *input.Body.Description
 		}
-		if input.Body.WorkOutcomes != nil {
-			b, err := json.Marshal(input.Body.WorkOutcomes)
-			if err != nil {
-				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
-			}
-			asStr := string(b)
-			opts.WorkOutcomesJSON = &asStr
+		// The new project starts from its kind's config, not the one served.
+		cfg, err := config.ForKind(input.Body.Kind, input.Body.ID)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", err.Error(), nil)
 		}
-		t, err := e.CreateTask(ctx, opts)
+		pe := e
+		pe.Config = cfg
+		p, err := pe.InitProject(ctx, input.Body.ID, input.Body.OrgID, input.Body.Kind, desc, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(t)}, nil
+			Body ProjectResponse `json:"body"`
+		}{Body: projectResponse(p)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "list-tasks",
+		OperationID: "list-projects",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks",
-		Summary:     "List tasks",
+		Path:        "/projects",
+		Summary:     "List projects",
 		Errors:      []int{http.StatusBadRequest},
 	}, func(ctx context.Context, input *struct {
-		ProjectID   string `path:"project_id"`
-		Status      string `query:"status"`
-		IterationID string `query:"iteration_id"`
-		ParentID    string `query:"parent_id"`
-		AssigneeID  string `query:"assignee_id"`
-		Limit       int    `query:"limit" default:"50"`
-		Cursor      string `query:"cursor"`
+		IncludeArchived bool `query:"include_archived" doc:"Also list archived projects"`
 	}) (*struct {
-		Body paginatedTasks `json:"body"`
+		Body []ProjectResponse `json:"body"`
 	}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "task.list"); err != nil {
+		if err := requireGlobalPermission(ctx, e, "project.list"); err != nil {
 			return nil, handleError(err)
 		}
-		limit := normalizeLimit(input.Limit)
-		cursorCreated, cursorID, err := parseCompositeCursor(input.Cursor)
-		if err != nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
-		}
-		filter := repo.TaskFilters{
-			ProjectID:       projectID,
-			Status:          input.Status,
-			Iteration:       input.IterationID,
-			Parent:          input.ParentID,
-			AssigneeID:      input.AssigneeID,
-			Limit:           limit + 1,
-			CursorCreatedAt: cursorCreated,
-			CursorID:        cursorID,
+		var items []domain.Project
+		var err error
+		if principal, ok := principalFromContext(ctx); ok && principal.OrgID != "" {
+			items, err = e.Repo.ListProjectsByOrg(ctx, principal.OrgID, input.IncludeArchived)
+		} else {
+			items, err = e.Repo.ListProjects(ctx, input.IncludeArchived)
 		}
-		tasks, err := e.Repo.ListTasks(ctx, filter)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := paginatedTasks{Items: []TaskResponse{}}
-		if len(tasks) > limit {
-			resp.NextCursor = composeCursor(tasks[limit].CreatedAt, tasks[limit].ID)
-			tasks = tasks[:limit]
-		}
-		resp.Items = mapTasks(tasks)
 		return &struct {
-			Body paginatedTasks `json:"body"`
-		}{Body: resp}, nil
+			Body []ProjectResponse `json:"body"`
+		}{Body: mapProjects(items)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "next-task",
+		OperationID: "get-project",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks/next",
-		Summary:     "Get next task for an actor",
-		Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+		Path:        "/projects/{project_id}",
+		Summary:     "Get project",
+		Errors:      []int{http.StatusNotFound},
 	}, func(ctx context.Context, input *struct {
-		ProjectID         string `path:"project_id"`
-		IterationID       string `query:"iteration_id"`
-		AssigneeID        string `query:"assignee_id"`
-		IncludeUnassigned bool   `query:"include_unassigned" default:"true"`
+		ProjectID string `path:"project_id"`
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		Body ProjectResponse `json:"body"`
 	}, error) {
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "task.next"); err != nil {
+		if err := requirePermission(ctx, e, projectID, "project.read"); err != nil {
 			return nil, handleError(err)
 		}
-		iterationID := input.IterationID
-		if iterationID == "" {
-			items, err := e.Repo.ListIterationsWithCursor(ctx, projectID, 50, "", "")
-			if err != nil {
-				return nil, handleError(err)
-			}
-			for _, it := range items {
-				if it.Status == "running" {
-					iterationID = it.ID
-					break
-				}
-			}
-		}
-		if iterationID == "" {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "no running iteration found", nil)
-		}
-		assigneeID := input.AssigneeID
-		if assigneeID == "" {
-			actorID, err := actorIDFromContext(ctx)
-			if err != nil {
-				return nil, err
-			}
-			assigneeID = actorID
-		}
-		t, err := e.Repo.NextTask(ctx, repo.NextTaskFilters{
-			ProjectID:         projectID,
-			IterationID:       iterationID,
-			AssigneeID:        assigneeID,
-			IncludeUnassigned: input.IncludeUnassigned,
-		})
+		p, err := e.Repo.GetProject(ctx, projectID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, t.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
-		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(t)}, nil
+			Body ProjectResponse `json:"body"`
+		}{Body: projectResponse(p)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID:   "decompose-task",
-		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/tasks/{id}/decompose",
-		Summary:       "Decompose task into subtasks",
-		DefaultStatus: http.StatusCreated,
+		OperationID: "update-project",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}",
+		Summary:     "Update project",
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
 			http.StatusConflict,
 			http.StatusUnprocessableEntity,
-			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string               `path:"project_id"`
-		ID        string               `path:"id"`
-		Body      DecomposeTaskRequest `json:"body"`
+		ProjectID string `path:"project_id"`
+		Body      struct {
+			Status      string  `json:"status,omitempty"`
+			Description *string `json:"description,omitempty"`
+		} `json:"body"`
 	}) (*struct {
-		Body DecomposeTaskResponse `json:"body"`
+		Body ProjectResponse `json:"body"`
 	}, error) {
 		if len(bodyBytes(ctx)) == 0 {
 			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
 		}
-		if len(input.Body.Subtasks) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "subtasks is required", map[string]any{"field": "subtasks"})
-		}
-		actorID, authErr := actorIDFromContext(ctx)
-		if authErr != nil {
-			return nil, authErr
-		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		parent, err := e.Repo.GetTask(ctx, input.ID)
-		if err != nil {
+		if err := requirePermission(ctx, e, projectID, "project.update"); err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, parent.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
-		}
-		localMap := make(map[string]struct{})
-		for _, st := range input.Body.Subtasks {
-			if st.LocalID != nil {
-				if _, ok := localMap[*st.LocalID]; ok {
-					return nil, newAPIError(http.StatusBadRequest, "bad_request", "duplicate local_id", map[string]any{"local_id": *st.LocalID})
-				}
-				localMap[*st.LocalID] = struct{}{}
-			}
+		if _, authErr := actorIDFromContext(ctx); authErr != nil {
+			return nil, authErr
 		}
-		created := make([]domain.Task, 0, len(input.Body.Subtasks))
-		mapping := map[string]string{}
-		type pendingDeps struct {
-			TaskID string
-			Deps   []string
+		if err := e.Repo.UpdateProject(ctx, projectID, input.Body.Status, input.Body.Description); err != nil {
+			return nil, handleError(err)
 		}
-		var pending []pendingDeps
-		for _, st := range input.Body.Subtasks {
-			if st.Title == "" {
-				return nil, newAPIError(http.StatusBadRequest, "bad_request", "title is required", map[string]any{"field": "title"})
-			}
-			taskType := st.Type
-			if taskType == "" {
-				taskType = "technical"
-			}
-			opts := engine.TaskCreateOptions{
-				ProjectID:   projectID,
-				Type:        taskType,
-				Title:       st.Title,
-				ActorID:     actorID,
-				Description: stringOrEmpty(st.Description),
-				ParentID:    parent.ID,
-			}
-			if st.ID != nil {
-				opts.ID = *st.ID
-			}
-			if st.IterationID != nil {
-				opts.IterationID = *st.IterationID
-			} else if parent.IterationID != nil {
-				opts.IterationID = *parent.IterationID
-			}
-			if st.AssigneeID != nil {
-				opts.AssigneeID = *st.AssigneeID
-			}
-			if st.Priority != nil {
-				opts.Priority = st.Priority
-			}
-			if st.Policy != nil {
-				opts.PolicyPreset = st.Policy.Preset
-			}
-			if st.Validation != nil {
-				opts.PolicyOverride = true
-				opts.RequiredKinds = st.Validation.Require
-			}
-			if st.WorkOutcomes != nil {
-				b, err := json.Marshal(st.WorkOutcomes)
-				if err != nil {
-					return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
-				}
-				asStr := string(b)
-				opts.WorkOutcomesJSON = &asStr
-			}
-			t, err := e.CreateTask(ctx, opts)
-			if err != nil {
-				return nil, handleError(err)
-			}
-			if st.LocalID != nil {
-				mapping[*st.LocalID] = t.ID
-			}
-			if len(st.DependsOn) > 0 {
-				pending = append(pending, pendingDeps{TaskID: t.ID, Deps: st.DependsOn})
-			}
-			created = append(created, t)
+		p, err := e.Repo.GetProject(ctx, projectID)
+		if err != nil {
+			return nil, handleError(err)
 		}
-		for _, entry := range pending {
-			var resolved []string
-			for _, dep := range entry.Deps {
-				if mapped, ok := mapping[dep]; ok {
-					resolved = append(resolved, mapped)
-				} else {
-					resolved = append(resolved, dep)
-				}
-			}
-			if len(resolved) == 0 {
-				continue
-			}
-			_, err := e.UpdateTask(ctx, engine.TaskUpdateOptions{
-				ID:      entry.TaskID,
-				ActorID: actorID,
-				AddDeps: resolved,
-			})
-			if err != nil {
-				return nil, handleError(err)
-			}
+		return &struct {
+			Body ProjectResponse `json:"body"`
+		}{Body: projectResponse(p)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-project",
+		Method:      http.MethodDelete,
+		Path:        "/projects/{project_id}",
+		Summary:     "Delete project",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct{}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "project.delete"); err != nil {
+			return nil, handleError(err)
 		}
-		resp := DecomposeTaskResponse{
-			Parent:   taskResponse(parent),
-			Subtasks: mapTasks(created),
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		if len(mapping) > 0 {
-			resp.Mapping = mapping
+		if _, err := e.DeleteProject(ctx, engine.DeleteProjectOptions{ProjectID: projectID, ActorID: actorID}); err != nil {
+			return nil, handleError(err)
 		}
-		return &struct {
-			Body DecomposeTaskResponse `json:"body"`
-		}{Body: resp}, nil
+		return &struct{}{}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "compose-task",
+		OperationID: "rename-project",
 		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/compose",
-		Summary:     "Compose a task result",
+		Path:        "/projects/{project_id}/rename",
+		Summary:     "Rename project",
+		Description: "Changes the project id everywhere it is referenced. The former id keeps resolving as an alias.",
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
 			http.StatusConflict,
-			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string             `path:"project_id"`
-		ID        string             `path:"id"`
-		Body      ComposeTaskRequest `json:"body"`
+		ProjectID string `path:"project_id"`
+		Body      struct {
+			ID string `json:"id" minLength:"1" doc:"New project id"`
+		} `json:"body"`
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		Body ProjectResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
-		if input.Body.Result == "" && input.Body.Summary == nil && input.Body.WorkOutcomes == nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "result or work_outcomes is required", nil)
-		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
-			if input.Body.Result != "" {
-				workOutcomes["output"] = input.Body.Result
-			}
-			if input.Body.Summary != nil {
-				workOutcomes["summary"] = *input.Body.Summary
-			}
-			for key, val := range input.Body.WorkOutcomes {
-				workOutcomes[key] = val
-			}
-			return nil, nil
-		})
+		p, err := e.RenameProject(ctx, projectID, input.Body.ID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(task)}, nil
+			Body ProjectResponse `json:"body"`
+		}{Body: projectResponse(p)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "get-task",
+		OperationID: "get-project-config",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks/{id}",
-		Summary:     "Get task",
+		Path:        "/projects/{project_id}/config",
+		Summary:     "Get project config",
+		Description: "Returns an ETag derived from the config; send it back in If-None-Match to get 304 Not Modified while the config is unchanged.",
 		Errors:      []int{http.StatusNotFound},
 	}, func(ctx context.Context, input *struct {
 		ProjectID string `path:"project_id"`
-		ID        string `path:"id"`
+		conditional.Params
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		ETag string                `header:"ETag"`
+		Body ProjectConfigResponse `json:"body"`
 	}, error) {
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "task.read"); err != nil {
+		if err := requirePermission(ctx, e, projectID, "project.config.read"); err != nil {
 			return nil, handleError(err)
 		}
-		t, err := e.Repo.GetTask(ctx, input.ID)
+		if err := auditRead(ctx, e, projectID, "config", ""); err != nil {
+			return nil, handleError(err)
+		}
+		cfg, err := e.ProjectConfig(ctx, projectID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, t.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		resp := configResponse(cfg)
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		etag := etagOf(projectID, string(data))
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
 		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(t)}, nil
+			ETag string                `header:"ETag"`
+			Body ProjectConfigResponse `json:"body"`
+		}{ETag: etag, Body: resp}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "update-task",
-		Method:      http.MethodPatch,
-		Path:        "/projects/{project_id}/tasks/{id}",
-		Summary:     "Update task",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusForbidden,
-			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
-			http.StatusInternalServerError,
-		},
+		OperationID: "list-project-config-versions",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/config/versions",
+		Summary:     "List project config versions",
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string            `path:"project_id"`
-		ID        string            `path:"id"`
-		Body      UpdateTaskRequest `json:"body"`
-		Force     bool              `query:"force"`
+		ProjectID string `path:"project_id"`
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		Body []ConfigVersionResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
-		bodyMap := rawBodyMap(ctx)
-		if input.Body.Validation == nil {
-			var parsed UpdateTaskRequest
-			if err := json.Unmarshal(bodyBytes(ctx), &parsed); err == nil && parsed.Validation != nil {
-				input.Body.Validation = parsed.Validation
-			}
-		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		opts := engine.TaskUpdateOptions{
-			ID:      input.ID,
-			ActorID: actorID,
-			Force:   input.Force,
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		versions, err := e.ProjectConfigVersions(ctx, projectID, actorID)
+		if err != nil {
+			return nil, handleError(err)
 		}
-		if input.Body.Status != nil {
-			opts.Status = *input.Body.Status
+		if err := auditRead(ctx, e, projectID, "config", ""); err != nil {
+			return nil, handleError(err)
 		}
-		if _, ok := bodyMap["assignee_id"]; ok {
-			opts.AssignProvided = true
-			opts.Assign = input.Body.AssigneeID
+		resp := make([]ConfigVersionResponse, 0, len(versions))
+		for _, v := range versions {
+			resp = append(resp, configVersionResponse(v))
 		}
-		if _, ok := bodyMap["parent_id"]; ok {
-			opts.ParentProvided = true
-			opts.SetParent = input.Body.ParentID
+		return &struct {
+			Body []ConfigVersionResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "diff-project-config",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/config/diff",
+		Summary:     "Diff two project config versions",
+		Description: "Compares version `from` with version `to`; 0 or omitted means the latest version.",
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		From      int    `query:"from" minimum:"0"`
+		To        int    `query:"to" minimum:"0"`
+	}) (*struct {
+		Body ConfigDiffResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		if rawPriority, ok := bodyMap["priority"]; ok {
-			opts.PriorityProvided = true
-			if isNullRaw(rawPriority) {
-				opts.ClearPriority = true
-			} else {
-				if input.Body.Priority == nil {
-					var parsed int
-					if err := json.Unmarshal(rawPriority, &parsed); err == nil {
-						opts.SetPriority = &parsed
-					}
-				} else {
-					opts.SetPriority = input.Body.Priority
-				}
-			}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		changes, err := e.DiffProjectConfigVersions(ctx, projectID, actorID, input.From, input.To)
+		if err != nil {
+			return nil, handleError(err)
 		}
-		if _, ok := bodyMap["work_outcomes"]; ok {
-			opts.WorkOutcomesSet = true
-			if input.Body.WorkOutcomes == nil {
-				opts.ClearWorkOutcomes = true
-			} else {
-				data, err := json.Marshal(input.Body.WorkOutcomes)
-				if err != nil {
-					return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
-				}
-				asStr := string(data)
-				opts.SetWorkOutcomes = &asStr
-			}
+		if err := auditRead(ctx, e, projectID, "config", ""); err != nil {
+			return nil, handleError(err)
 		}
-		opts.AddDeps = input.Body.AddDependsOn
-		opts.RemoveDeps = input.Body.RemoveDependsOn
-		if isNullRaw(bodyMap["add_depends_on"]) {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "add_depends_on must be array", map[string]any{"field": "add_depends_on", "reason": "must be array"})
+		return &struct {
+			Body ConfigDiffResponse `json:"body"`
+		}{Body: ConfigDiffResponse{From: input.From, To: input.To, Changes: configChangeResponses(changes)}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "rollback-project-config",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/config/rollback",
+		Summary:     "Restore an earlier project config version",
+		Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Body      RollbackConfigRequest
+	}) (*struct {
+		Body ConfigVersionResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		if isNullRaw(bodyMap["remove_depends_on"]) {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "remove_depends_on must be array", map[string]any{"field": "remove_depends_on", "reason": "must be array"})
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		v, err := e.RollbackProjectConfig(ctx, projectID, actorID, input.Body.Version)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ConfigVersionResponse `json:"body"`
+		}{Body: configVersionResponse(v)}, nil
+	})
+}
+
+func registerTasks(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-task",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/tasks",
+		Summary:       "Create task",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusUnprocessableEntity,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string            `path:"project_id"`
+		Body      CreateTaskRequest `json:"body"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		bodyMap := rawBodyMap(ctx)
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		if input.Body.Title == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "title is required", nil)
+		}
+		if input.Body.Type == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "type is required", nil)
+		}
+		if isNullRaw(bodyMap["depends_on"]) {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "depends_on must be array", map[string]any{"field": "depends_on", "reason": "must be array"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		opts := engine.TaskCreateOptions{
+			ProjectID:    projectID,
+			Type:         input.Body.Type,
+			Title:        input.Body.Title,
+			ActorID:      actorID,
+			Description:  stringOrEmpty(input.Body.Description),
+			DependsOn:    input.Body.DependsOn,
+			ExternalRefs: input.Body.ExternalRefs,
+		}
+		if input.Body.ID != nil {
+			opts.ID = *input.Body.ID
+		}
+		if input.Body.IterationID != nil {
+			opts.IterationID = *input.Body.IterationID
+		}
+		if input.Body.ParentID != nil {
+			opts.ParentID = *input.Body.ParentID
+		}
+		if input.Body.Component != nil {
+			opts.Component = *input.Body.Component
+		}
+		if input.Body.AssigneeID != nil {
+			opts.AssigneeID = *input.Body.AssigneeID
+		}
+		if input.Body.Priority != nil {
+			opts.Priority = input.Body.Priority
+		}
+		opts.Estimate = input.Body.Estimate
+		if input.Body.DueAt != nil {
+			opts.DueAt = *input.Body.DueAt
+		}
+		if input.Body.Policy != nil {
+			opts.PolicyPreset = input.Body.Policy.Preset
+		} else if rawPolicy, ok := bodyMap["policy"]; ok {
+			var policy TaskPolicyRequest
+			if err := json.Unmarshal(rawPolicy, &policy); err == nil && policy.Preset != "" {
+				opts.PolicyPreset = policy.Preset
+			}
 		}
 		if rawValidation, ok := bodyMap["validation"]; ok {
-			opts.PolicyOverride = true
-			if isNullRaw(rawValidation) {
-				opts.RequiredKindsSet = true
-				opts.RequiredKinds = nil
-			} else {
-				var validationMap map[string]json.RawMessage
+			var validationMap map[string]json.RawMessage
+			if len(rawValidation) > 0 {
 				_ = json.Unmarshal(rawValidation, &validationMap)
-				if rawReq, present := validationMap["require"]; present && isNullRaw(rawReq) {
+				if isNullRaw(validationMap["require"]) {
 					return nil, newAPIError(http.StatusBadRequest, "bad_request", "validation.require must be array", map[string]any{"field": "validation.require", "reason": "must be array"})
 				}
-				validation := input.Body.Validation
-				if validation == nil {
-					var parsed UpdateTaskValidationRequest
-					if err := json.Unmarshal(rawValidation, &parsed); err == nil {
-						validation = &parsed
-					}
-				}
-				if _, present := validationMap["require"]; present {
-					opts.RequiredKindsSet = true
-					if validation != nil {
-						opts.RequiredKinds = validation.Require
-					} else {
-						var parsedReq []string
-						if err := json.Unmarshal(validationMap["require"], &parsedReq); err == nil {
-							opts.RequiredKinds = parsedReq
-						}
-					}
-				}
 			}
-		} else if input.Body.Validation != nil {
-			opts.PolicyOverride = true
-			opts.RequiredKindsSet = true
-			opts.RequiredKinds = input.Body.Validation.Require
+			if input.Body.Validation != nil {
+				opts.PolicyOverride = true
+				opts.RequiredKinds = input.Body.Validation.Require
+			}
+		}
+		if input.Body.WorkOutcomes != nil {
+			b, err := json.Marshal(input.Body.WorkOutcomes)
+			if err != nil {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
+			}
+			asStr := string(b)
+			opts.WorkOutcomesJSON = &asStr
+		}
+		t, err := e.CreateTask(ctx, opts)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-tasks",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks",
+		Summary:     "List tasks",
+		Description: "Returns an ETag that changes with the project's latest event; send it back in If-None-Match to get 304 Not Modified while nothing changed.",
+		Errors:      []int{http.StatusBadRequest},
+	}, func(ctx context.Context, input *struct {
+		ProjectID          string   `path:"project_id"`
+		Status             []string `query:"status" doc:"Match any of these statuses; repeat or comma-separate"`
+		Type               []string `query:"type" doc:"Match any of these task types; repeat or comma-separate"`
+		Q                  string   `query:"q" doc:"Case-insensitive substring of the title"`
+		MissingAttestation []string `query:"missing_attestation" doc:"Only tasks without an unexpired attestation of each kind"`
+		Stale              bool     `query:"stale" doc:"Only tasks marked stale by the staleness sweep"`
+		Overdue            bool     `query:"overdue" doc:"Only open tasks past their due_at"`
+		CreatedAfter       string   `query:"created_after" doc:"RFC3339, inclusive"`
+		CreatedBefore      string   `query:"created_before" doc:"RFC3339, exclusive"`
+		CompletedAfter     string   `query:"completed_after" doc:"RFC3339, inclusive"`
+		CompletedBefore    string   `query:"completed_before" doc:"RFC3339, exclusive"`
+		IterationID        string   `query:"iteration_id"`
+		ParentID           string   `query:"parent_id"`
+		AssigneeID         string   `query:"assignee_id"`
+		Component          string   `query:"component"`
+		Sort               string   `query:"sort" doc:"created_at, updated_at, priority, status or title, optionally suffixed with :asc or :desc"`
+		Limit              int      `query:"limit" default:"50"`
+		Cursor             string   `query:"cursor"`
+		conditional.Params
+	}) (*struct {
+		ETag string         `header:"ETag"`
+		Body paginatedTasks `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.list"); err != nil {
+			return nil, handleError(err)
+		}
+		etag, err := projectETag(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
+		}
+		limit := normalizeLimit(input.Limit)
+		sortBy, err := repo.ParseTaskSort(input.Sort)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"sort": input.Sort})
+		}
+		cursorValue, cursorID, err := parseTaskCursor(input.Cursor, sortBy)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+		}
+		filter := repo.TaskFilters{
+			ProjectID:           projectID,
+			Statuses:            splitQueryValues(input.Status),
+			Types:               splitQueryValues(input.Type),
+			Query:               strings.TrimSpace(input.Q),
+			MissingAttestations: splitQueryValues(input.MissingAttestation),
+			Stale:               input.Stale,
+			CreatedAfter:        input.CreatedAfter,
+			CreatedBefore:       input.CreatedBefore,
+			CompletedAfter:      input.CompletedAfter,
+			CompletedBefore:     input.CompletedBefore,
+			Iteration:           input.IterationID,
+			Parent:              input.ParentID,
+			AssigneeID:          input.AssigneeID,
+			Component:           input.Component,
+			Sort:                sortBy,
+			Limit:               limit + 1,
+			CursorValue:         cursorValue,
+			CursorID:            cursorID,
+		}
+		if input.Overdue {
+			filter.OverdueAt = time.Now().UTC().Format(time.RFC3339)
+		}
+		tasks, err := e.Repo.ListTasks(ctx, filter)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := paginatedTasks{Items: []TaskResponse{}}
+		if len(tasks) > limit {
+			resp.NextCursor = composeTaskCursor(sortBy, tasks[limit])
+			tasks = tasks[:limit]
+		}
+		if err := withTaskLinks(ctx, e.Repo, tasks); err != nil {
+			return nil, handleError(err)
+		}
+		resp.Items = mapTasks(tasks)
+		return &struct {
+			ETag string         `header:"ETag"`
+			Body paginatedTasks `json:"body"`
+		}{ETag: etag, Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "next-task",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/next",
+		Summary:     "Get next task for an actor",
+		Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+	}, func(ctx context.Context, input *struct {
+		ProjectID         string `path:"project_id"`
+		IterationID       string `query:"iteration_id"`
+		AssigneeID        string `query:"assignee_id"`
+		IncludeUnassigned bool   `query:"include_unassigned" default:"true"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.next"); err != nil {
+			return nil, handleError(err)
+		}
+		iterationID := input.IterationID
+		if iterationID == "" {
+			items, err := e.Repo.ListIterationsWithCursor(ctx, projectID, 50, "", "")
+			if err != nil {
+				return nil, handleError(err)
+			}
+			for _, it := range items {
+				if it.Status == "running" {
+					iterationID = it.ID
+					break
+				}
+			}
+		}
+		if iterationID == "" {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "no running iteration found", nil)
+		}
+		assigneeID := input.AssigneeID
+		if assigneeID == "" {
+			actorID, err := actorIDFromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			assigneeID = actorID
+		}
+		t, err := e.Repo.NextTask(ctx, repo.NextTaskFilters{
+			ProjectID:         projectID,
+			IterationID:       iterationID,
+			AssigneeID:        assigneeID,
+			IncludeUnassigned: input.IncludeUnassigned,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, t.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "claim-next-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/claim-next",
+		Summary:     "Claim the next task for an actor",
+		Description: "Picks the caller's next task like GET tasks/next and claims its lease in the same transaction, skipping tasks another actor holds a live lease on. Responds 204 when nothing is available.",
+		Responses: map[string]*huma.Response{
+			"204": {Description: "No task available"},
+		},
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID         string   `path:"project_id"`
+		IterationID       string   `query:"iteration_id" doc:"Defaults to the running iteration"`
+		Type              []string `query:"type" doc:"Match any of these task types; repeat or comma-separate"`
+		IncludeUnassigned bool     `query:"include_unassigned" default:"true"`
+		LeaseSeconds      int      `query:"lease_seconds" default:"900"`
+	}) (*struct {
+		Status int
+		Body   *ClaimNextResponse
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		t, lease, err := e.ClaimNextTask(ctx, engine.ClaimNextOptions{
+			ProjectID:         projectID,
+			IterationID:       input.IterationID,
+			Types:             splitQueryValues(input.Type),
+			IncludeUnassigned: input.IncludeUnassigned,
+			ActorID:           actorID,
+			LeaseSeconds:      input.LeaseSeconds,
+		})
+		if errors.Is(err, repo.ErrNotFound) {
+			return &struct {
+				Status int
+				Body   *ClaimNextResponse
+			}{Status: http.StatusNoContent}, nil
+		}
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Status int
+			Body   *ClaimNextResponse
+		}{Status: http.StatusOK, Body: &ClaimNextResponse{Task: taskResponse(t), Lease: leaseResponse(lease)}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "decompose-task",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/tasks/{id}/decompose",
+		Summary:       "Decompose task into subtasks",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string               `path:"project_id"`
+		ID        string               `path:"id"`
+		Body      DecomposeTaskRequest `json:"body"`
+	}) (*struct {
+		Body DecomposeTaskResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		if len(input.Body.Subtasks) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "subtasks is required", map[string]any{"field": "subtasks"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		parent, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, parent.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		localMap := make(map[string]struct{})
+		for _, st := range input.Body.Subtasks {
+			if st.LocalID != nil {
+				if _, ok := localMap[*st.LocalID]; ok {
+					return nil, newAPIError(http.StatusBadRequest, "bad_request", "duplicate local_id", map[string]any{"local_id": *st.LocalID})
+				}
+				localMap[*st.LocalID] = struct{}{}
+			}
+		}
+		created := make([]domain.Task, 0, len(input.Body.Subtasks))
+		mapping := map[string]string{}
+		type pendingDeps struct {
+			TaskID string
+			Deps   []string
+		}
+		var pending []pendingDeps
+		for _, st := range input.Body.Subtasks {
+			if st.Title == "" {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "title is required", map[string]any{"field": "title"})
+			}
+			taskType := st.Type
+			if taskType == "" {
+				taskType = "technical"
+			}
+			opts := engine.TaskCreateOptions{
+				ProjectID:   projectID,
+				Type:        taskType,
+				Title:       st.Title,
+				ActorID:     actorID,
+				Description: stringOrEmpty(st.Description),
+				ParentID:    parent.ID,
+				Component:   parent.Component,
+			}
+			if st.ID != nil {
+				opts.ID = *st.ID
+			}
+			if st.IterationID != nil {
+				opts.IterationID = *st.IterationID
+			} else if parent.IterationID != nil {
+				opts.IterationID = *parent.IterationID
+			}
+			if st.AssigneeID != nil {
+				opts.AssigneeID = *st.AssigneeID
+			}
+			if st.Priority != nil {
+				opts.Priority = st.Priority
+			}
+			if st.Policy != nil {
+				opts.PolicyPreset = st.Policy.Preset
+			}
+			if st.Validation != nil {
+				opts.PolicyOverride = true
+				opts.RequiredKinds = st.Validation.Require
+			}
+			if st.WorkOutcomes != nil {
+				b, err := json.Marshal(st.WorkOutcomes)
+				if err != nil {
+					return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
+				}
+				asStr := string(b)
+				opts.WorkOutcomesJSON = &asStr
+			}
+			t, err := e.CreateTask(ctx, opts)
+			if err != nil {
+				return nil, handleError(err)
+			}
+			if st.LocalID != nil {
+				mapping[*st.LocalID] = t.ID
+			}
+			if len(st.DependsOn) > 0 {
+				pending = append(pending, pendingDeps{TaskID: t.ID, Deps: st.DependsOn})
+			}
+			created = append(created, t)
+		}
+		for _, entry := range pending {
+			var resolved []string
+			for _, dep := range entry.Deps {
+				if mapped, ok := mapping[dep]; ok {
+					resolved = append(resolved, mapped)
+				} else {
+					resolved = append(resolved, dep)
+				}
+			}
+			if len(resolved) == 0 {
+				continue
+			}
+			_, err := e.UpdateTask(ctx, engine.TaskUpdateOptions{
+				ID:      entry.TaskID,
+				ActorID: actorID,
+				AddDeps: resolved,
+			})
+			if err != nil {
+				return nil, handleError(err)
+			}
+		}
+		resp := DecomposeTaskResponse{
+			Parent:   taskResponse(parent),
+			Subtasks: mapTasks(created),
+		}
+		if len(mapping) > 0 {
+			resp.Mapping = mapping
+		}
+		return &struct {
+			Body DecomposeTaskResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "compose-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/compose",
+		Summary:     "Compose a task result",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string             `path:"project_id"`
+		ID        string             `path:"id"`
+		Body      ComposeTaskRequest `json:"body"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		if input.Body.Result == "" && input.Body.Summary == nil && input.Body.WorkOutcomes == nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "result or work_outcomes is required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
+			if input.Body.Result != "" {
+				workOutcomes["output"] = input.Body.Result
+			}
+			if input.Body.Summary != nil {
+				workOutcomes["summary"] = *input.Body.Summary
+			}
+			for key, val := range input.Body.WorkOutcomes {
+				workOutcomes[key] = val
+			}
+			return nil, nil
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(task)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-task",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}",
+		Summary:     "Get task",
+		Description: "Returns an ETag derived from the task's updated_at and latest event; send it back in If-None-Match to get 304 Not Modified while the task is unchanged.",
+		Errors:      []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+		conditional.Params
+	}) (*struct {
+		ETag string       `header:"ETag"`
+		Body TaskResponse `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.read"); err != nil {
+			return nil, handleError(err)
+		}
+		t, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, t.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		// Leases, time logs and attestations leave updated_at alone but
+		// record task events.
+		latest, err := e.Repo.LatestEvents(ctx, 1, t.ProjectID, "", "task", t.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		var latestID int64
+		if len(latest) > 0 {
+			latestID = latest[0].ID
+		}
+		etag := etagOf(t.ID, t.UpdatedAt, latestID)
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
+		}
+		spent, err := e.Repo.TaskTimeTotal(ctx, t.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := taskResponse(t)
+		resp.TimeSpentMinutes = &spent
+		return &struct {
+			ETag string       `header:"ETag"`
+			Body TaskResponse `json:"body"`
+		}{ETag: etag, Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-task-by-external-ref",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/by-external/{system}/{external_id}",
+		Summary:     "Get task by external id",
+		Description: "Finds the task linked to an id in an external system, such as jira ABC-123. Percent-encode ids holding '/' or '#', e.g. github org%2Frepo%2345.",
+		Errors:      []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ProjectID  string `path:"project_id"`
+		System     string `path:"system" example:"jira"`
+		ExternalID string `path:"external_id" example:"ABC-123"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.read"); err != nil {
+			return nil, handleError(err)
+		}
+		// The router leaves escaped slashes in path values encoded.
+		externalID := input.ExternalID
+		if decoded, err := url.PathUnescape(externalID); err == nil {
+			externalID = decoded
+		}
+		t, err := e.TaskByExternalRef(ctx, projectID, input.System, externalID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-task",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/tasks/{id}",
+		Summary:     "Update task",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string            `path:"project_id"`
+		ID        string            `path:"id"`
+		Body      UpdateTaskRequest `json:"body"`
+		Force     bool              `query:"force"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		bodyMap := rawBodyMap(ctx)
+		if input.Body.Validation == nil {
+			var parsed UpdateTaskRequest
+			if err := json.Unmarshal(bodyBytes(ctx), &parsed); err == nil && parsed.Validation != nil {
+				input.Body.Validation = parsed.Validation
+			}
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		opts := engine.TaskUpdateOptions{
+			ID:      input.ID,
+			ActorID: actorID,
+			Force:   input.Force,
+		}
+		if input.Body.Status != nil {
+			opts.Status = *input.Body.Status
+		}
+		if _, ok := bodyMap["assignee_id"]; ok {
+			opts.AssignProvided = true
+			opts.Assign = input.Body.AssigneeID
+		}
+		if _, ok := bodyMap["parent_id"]; ok {
+			opts.ParentProvided = true
+			opts.SetParent = input.Body.ParentID
+		}
+		if rawPriority, ok := bodyMap["priority"]; ok {
+			opts.PriorityProvided = true
+			if isNullRaw(rawPriority) {
+				opts.ClearPriority = true
+			} else {
+				if input.Body.Priority == nil {
+					var parsed int
+					if err := json.Unmarshal(rawPriority, &parsed); err == nil {
+						opts.SetPriority = &parsed
+					}
+				} else {
+					opts.SetPriority = input.Body.Priority
+				}
+			}
+		}
+		if rawEstimate, ok := bodyMap["estimate"]; ok {
+			opts.EstimateProvided = true
+			if isNullRaw(rawEstimate) {
+				opts.ClearEstimate = true
+			} else {
+				opts.SetEstimate = input.Body.Estimate
+			}
+		}
+		if _, ok := bodyMap["due_at"]; ok {
+			opts.DueAtProvided = true
+			opts.SetDueAt = input.Body.DueAt
+		}
+		if _, ok := bodyMap["iteration_id"]; ok {
+			opts.IterationProvided = true
+			opts.SetIteration = input.Body.IterationID
+		}
+		if raw, ok := bodyMap["component"]; ok {
+			component := ""
+			if !isNullRaw(raw) && input.Body.Component != nil {
+				component = *input.Body.Component
+			}
+			opts.SetComponent = &component
+		}
+		if _, ok := bodyMap["work_outcomes"]; ok {
+			opts.WorkOutcomesSet = true
+			if input.Body.WorkOutcomes == nil {
+				opts.ClearWorkOutcomes = true
+			} else {
+				data, err := json.Marshal(input.Body.WorkOutcomes)
+				if err != nil {
+					return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
+				}
+				asStr := string(data)
+				opts.SetWorkOutcomes = &asStr
+			}
+		}
+		opts.AddDeps = input.Body.AddDependsOn
+		opts.RemoveDeps = input.Body.RemoveDependsOn
+		opts.SetExternalRefs = input.Body.ExternalRefs
+		if isNullRaw(bodyMap["add_depends_on"]) {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "add_depends_on must be array", map[string]any{"field": "add_depends_on", "reason": "must be array"})
+		}
+		if isNullRaw(bodyMap["remove_depends_on"]) {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "remove_depends_on must be array", map[string]any{"field": "remove_depends_on", "reason": "must be array"})
+		}
+		if rawValidation, ok := bodyMap["validation"]; ok {
+			opts.PolicyOverride = true
+			if isNullRaw(rawValidation) {
+				opts.RequiredKindsSet = true
+				opts.RequiredKinds = nil
+			} else {
+				var validationMap map[string]json.RawMessage
+				_ = json.Unmarshal(rawValidation, &validationMap)
+				if rawReq, present := validationMap["require"]; present && isNullRaw(rawReq) {
+					return nil, newAPIError(http.StatusBadRequest, "bad_request", "validation.require must be array", map[string]any{"field": "validation.require", "reason": "must be array"})
+				}
+				validation := input.Body.Validation
+				if validation == nil {
+					var parsed UpdateTaskValidationRequest
+					if err := json.Unmarshal(rawValidation, &parsed); err == nil {
+						validation = &parsed
+					}
+				}
+				if _, present := validationMap["require"]; present {
+					opts.RequiredKindsSet = true
+					if validation != nil {
+						opts.RequiredKinds = validation.Require
+					} else {
+						var parsedReq []string
+						if err := json.Unmarshal(validationMap["require"], &parsedReq); err == nil {
+							opts.RequiredKinds = parsedReq
+						}
+					}
+				}
+			}
+		} else if input.Body.Validation != nil {
+			opts.PolicyOverride = true
+			opts.RequiredKindsSet = true
+			opts.RequiredKinds = input.Body.Validation.Require
+		}
+		t, err := e.UpdateTask(ctx, opts)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, t.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "bulk-update-tasks",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/bulk-update",
+		Summary:     "Bulk update tasks",
+		Description: "Applies one change set (status, iteration, assignee) to up to 200 tasks in one transaction, with the same checks as a single update. Each task reports its own status; with atomic, any failure leaves every task unchanged. The operation is audited as one task.bulk_updated event.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                 `path:"project_id"`
+		Body      BulkUpdateTasksRequest `json:"body"`
+	}) (*struct {
+		Body BulkUpdateTasksResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		bodyMap := rawBodyMap(ctx)
+		opts := engine.BulkTaskUpdateOptions{
+			ProjectID: projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID),
+			TaskIDs:   input.Body.TaskIDs,
+			ActorID:   actorID,
+			Force:     input.Body.Force,
+			Atomic:    input.Body.Atomic,
+		}
+		if input.Body.Status != nil {
+			opts.Status = *input.Body.Status
+		}
+		if _, ok := bodyMap["iteration_id"]; ok {
+			opts.IterationProvided = true
+			opts.SetIteration = input.Body.IterationID
+		}
+		if _, ok := bodyMap["assignee_id"]; ok {
+			opts.AssignProvided = true
+			opts.Assign = input.Body.AssigneeID
+		}
+		results, err := e.BulkUpdateTasks(ctx, opts)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		out := BulkUpdateTasksResponse{Results: make([]BulkTaskResult, 0, len(results))}
+		for _, res := range results {
+			item := BulkTaskResult{TaskID: res.TaskID, Status: http.StatusOK}
+			if res.Err != nil {
+				out.Failed++
+				item.Status, item.Error = itemError(res.Err)
+			} else {
+				out.Updated++
+				task := taskResponse(res.Task)
+				item.Task = &task
+			}
+			out.Results = append(out.Results, item)
+		}
+		return &struct {
+			Body BulkUpdateTasksResponse `json:"body"`
+		}{Body: out}, nil
+	})
+
+	registerWorkOutcomesUpdates(api, e)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "complete-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/done",
+		Summary:     "Complete task",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string              `path:"project_id"`
+		ID        string              `path:"id"`
+		Body      CompleteTaskRequest `json:"body"`
+		Force     bool                `query:"force"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if input.Body.WorkOutcomes == nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "work_outcomes is required", nil)
+		}
+		data, err := json.Marshal(input.Body.WorkOutcomes)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
+		}
+		workOutcomes := string(data)
+		t, err := e.TaskDone(ctx, input.ID, workOutcomes, actorID, input.Force)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, t.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "revert-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/revert",
+		Summary:     "Revert last task transition",
+		Description: "Restores the status the task had before its last transition. Requires task.revert.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body TaskResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		t, err := e.RevertTask(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskResponse `json:"body"`
+		}{Body: taskResponse(t)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "claim-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/claim",
+		Summary:     "Claim task lease",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID    string `path:"project_id"`
+		ID           string `path:"id"`
+		LeaseSeconds int    `query:"lease_seconds" default:"900"`
+	}) (*struct {
+		Body LeaseResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		lease, err := e.ClaimLease(ctx, input.ID, actorID, input.LeaseSeconds)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body LeaseResponse `json:"body"`
+		}{Body: leaseResponse(lease)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "release-task",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/release",
+		Summary:     "Release task lease",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		if err := e.ReleaseLease(ctx, input.ID, actorID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "log-task-time",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/tasks/{id}/time",
+		Summary:       "Log time on task",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string         `path:"project_id"`
+		ID        string         `path:"id"`
+		Body      LogTimeRequest `json:"body"`
+	}) (*struct {
+		Body TimeEntryResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		te, err := e.LogTime(ctx, engine.TimeLogOptions{TaskID: input.ID, Minutes: input.Body.Minutes, Note: input.Body.Note, ActorID: actorID})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TimeEntryResponse `json:"body"`
+		}{Body: timeEntryResponse(te)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-task-time",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/time",
+		Summary:     "List time logged on task",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body []TimeEntryResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		items, err := e.ListTimeEntries(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]TimeEntryResponse, 0, len(items))
+		for _, te := range items {
+			resp = append(resp, timeEntryResponse(te))
+		}
+		return &struct {
+			Body []TimeEntryResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "add-task-relation",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/tasks/{id}/relations",
+		Summary:       "Relate task",
+		Description:   "Links the task to another of the project, read as `<id> <type> <related_task_id>`: `blocks` (the related task cannot complete, nor be picked by claim-next, until this one is done), `relates_to`, `duplicates` or `caused_by`. Adding `duplicates` closes this task, when open, into its workflow's first terminal state other than done (canceled by default). Adding an existing relation changes nothing.",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                 `path:"project_id"`
+		ID        string                 `path:"id"`
+		Body      AddTaskRelationRequest `json:"body"`
+	}) (*struct {
+		Body TaskRelationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		rel, err := e.AddTaskRelation(ctx, engine.TaskRelationOptions{TaskID: input.ID, Type: input.Body.Type, RelatedTaskID: input.Body.RelatedTaskID, ActorID: actorID, Force: input.Body.Force})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskRelationResponse `json:"body"`
+		}{Body: taskRelationResponse(rel)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-task-relations",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/relations",
+		Summary:     "List task relations",
+		Description: "Relations the task takes part in from either side, oldest first.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body []TaskRelationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		rels, err := e.ListTaskRelations(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]TaskRelationResponse, 0, len(rels))
+		for _, rel := range rels {
+			resp = append(resp, taskRelationResponse(rel))
+		}
+		return &struct {
+			Body []TaskRelationResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "remove-task-relation",
+		Method:      http.MethodDelete,
+		Path:        "/projects/{project_id}/tasks/{id}/relations/{type}/{related_task_id}",
+		Summary:     "Remove task relation",
+		Description: "Deletes the relation `<id> <type> <related_task_id>`. A duplicate closed when the relation was added stays closed.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID     string `path:"project_id"`
+		ID            string `path:"id"`
+		Type          string `path:"type" enum:"blocks,relates_to,duplicates,caused_by"`
+		RelatedTaskID string `path:"related_task_id"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		if err := e.RemoveTaskRelation(ctx, engine.TaskRelationOptions{TaskID: input.ID, Type: input.Type, RelatedTaskID: input.RelatedTaskID, ActorID: actorID}); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct{}{}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-task-history",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/history",
+		Summary:     "Get task history",
+		Description: "Chronological lifecycle of a task assembled from the event log and its attestations.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body []TaskHistoryEntryResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		items, err := e.TaskHistory(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]TaskHistoryEntryResponse, 0, len(items))
+		for _, h := range items {
+			resp = append(resp, taskHistoryResponse(h))
+		}
+		return &struct {
+			Body []TaskHistoryEntryResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "suggest-task-assignee",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/suggest-assignee",
+		Summary:     "Suggest task assignees",
+		Description: "Ranks active actors allowed to claim and update the task by their open task load, then by recent activity.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body []AssigneeSuggestionResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		task, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, task.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		items, err := e.SuggestAssignees(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]AssigneeSuggestionResponse, 0, len(items))
+		for _, a := range items {
+			resp = append(resp, assigneeSuggestionResponse(a))
+		}
+		return &struct {
+			Body []AssigneeSuggestionResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	type treeInput struct {
+		ProjectID string `path:"project_id"`
+		Iteration string `query:"iteration_id"`
+		Status    string `query:"status"`
+	}
+	type treeNode struct {
+		Task     TaskResponse `json:"task"`
+		Children []treeNode   `json:"children"`
+	}
+	huma.Register(api, huma.Operation{
+		OperationID: "task-tree",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/tree",
+		Summary:     "Task tree",
+		Errors:      []int{http.StatusBadRequest},
+	}, func(ctx context.Context, input *treeInput) (*struct {
+		Body []treeNode `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.tree"); err != nil {
+			return nil, handleError(err)
+		}
+		tasks, err := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: projectID, Iteration: input.Iteration, Status: input.Status})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if err := withTaskLinks(ctx, e.Repo, tasks); err != nil {
+			return nil, handleError(err)
+		}
+		children := map[string][]domain.Task{}
+		var roots []domain.Task
+		for _, t := range tasks {
+			if t.ParentID != nil {
+				children[*t.ParentID] = append(children[*t.ParentID], t)
+			} else {
+				roots = append(roots, t)
+			}
+		}
+		var build func(domain.Task) treeNode
+		build = func(t domain.Task) treeNode {
+			kid := []treeNode{}
+			for _, c := range children[t.ID] {
+				kid = append(kid, build(c))
+			}
+			return treeNode{Task: taskResponse(t), Children: kid}
+		}
+		res := []treeNode{}
+		for _, r := range roots {
+			res = append(res, build(r))
+		}
+		return &struct {
+			Body []treeNode `json:"body"`
+		}{Body: res}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "task-graph",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/graph",
+		Summary:     "Task graph",
+		Description: "Every task of the project as a node and every link between tasks as an edge read `from type to`: `subtask_of` for parents, `depends_on`, and the relation types `blocks`, `relates_to`, `duplicates` and `caused_by`.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body TaskGraphResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		graph, err := e.TaskGraph(ctx, projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID), actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body TaskGraphResponse `json:"body"`
+		}{Body: taskGraphResponse(graph)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "task-validation-status",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/validation",
+		Summary:     "Task validation status",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusNotFound,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body ValidationStatusResponse `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "task.validation.read"); err != nil {
+			return nil, handleError(err)
+		}
+		t, err := e.Repo.GetTask(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, t.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		}
+		status, err := taskValidationStatus(ctx, e, t)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ValidationStatusResponse `json:"body"`
+		}{Body: status}, nil
+	})
+}
+
+func registerValidations(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-validation",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/tasks/{id}/validations",
+		Summary:       "Create validation",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusUnprocessableEntity,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string            `path:"project_id"`
+		ID        string            `path:"id"`
+		Body      ValidationRequest `json:"body"`
+	}) (*struct {
+		Body ValidationResponse `json:"body"`
+	}, error) {
+		if strings.TrimSpace(input.Body.Kind) == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "kind is required", map[string]any{"field": "kind"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		v, err := e.CreateValidation(ctx, engine.ValidationCreateOptions{
+			ProjectID: projectID,
+			TaskID:    input.ID,
+			Kind:      input.Body.Kind,
+			Status:    input.Body.Status,
+			Summary:   input.Body.Summary,
+			Issues:    input.Body.Issues,
+			URL:       input.Body.URL,
+			ActorID:   actorID,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ValidationResponse `json:"body"`
+		}{Body: validationResponse(v)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-validations",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/tasks/{id}/validations",
+		Summary:     "List validations for task",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body ValidationsResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		items, err := e.ListValidations(ctx, projectID, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := ValidationsResponse{Items: []ValidationResponse{}}
+		for _, v := range items {
+			resp.Items = append(resp.Items, validationResponse(v))
+		}
+		return &struct {
+			Body ValidationsResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-validation",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/validations/{id}",
+		Summary:     "Get validation",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body ValidationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		v, err := e.GetValidation(ctx, projectID, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ValidationResponse `json:"body"`
+		}{Body: validationResponse(v)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-validation",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/validations/{id}",
+		Summary:     "Update validation",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusUnprocessableEntity,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string            `path:"project_id"`
+		ID        string            `path:"id"`
+		Body      ValidationRequest `json:"body"`
+	}) (*struct {
+		Body ValidationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		updated, err := e.UpdateValidation(ctx, engine.ValidationUpdateOptions{
+			ID:      input.ID,
+			Kind:    input.Body.Kind,
+			Status:  input.Body.Status,
+			Summary: input.Body.Summary,
+			Issues:  input.Body.Issues,
+			URL:     input.Body.URL,
+			ActorID: actorID,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if updated.ProjectID != projectID {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "validation not found in project", nil)
+		}
+		return &struct {
+			Body ValidationResponse `json:"body"`
+		}{Body: validationResponse(updated)}, nil
+	})
+}
+
+func registerWorkOutcomesUpdates(api huma.API, e engine.Engine) {
+	registerWorkOutcomesAppend(api, e)
+	registerWorkOutcomesPut(api, e)
+	registerWorkOutcomesMerge(api, e)
+	registerWorkOutcomesPatch(api, e)
+}
+
+func registerWorkOutcomesPatch(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "patch-task-work-outcomes",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/patch",
+		Summary:     "Apply a JSON Patch to work outcomes",
+		Description: "Applies RFC 6902 add, remove, replace and test operations in order. The patch applies as a whole or not at all; a failed test returns 409.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string               `path:"project_id"`
+		ID        string               `path:"id"`
+		Body      []JSONPatchOperation `json:"body" minItems:"1" maxItems:"100"`
+	}) (*struct {
+		Body WorkOutcomesUpdateResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
+			return nil, applyJSONPatch(workOutcomes, input.Body)
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := WorkOutcomesUpdateResponse{
+			WorkOutcomes: taskResponse(task).WorkOutcomes,
+		}
+		return &struct {
+			Body WorkOutcomesUpdateResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+func registerWorkOutcomesAppend(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "append-task-work-outcomes",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/append",
+		Summary:     "Append work outcomes entry",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                    `path:"project_id"`
+		ID        string                    `path:"id"`
+		Body      WorkOutcomesAppendRequest `json:"body"`
+	}) (*struct {
+		Body WorkOutcomesUpdateResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		path := strings.TrimSpace(input.Body.Path)
+		if path == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		segs, err := parseWorkOutcomesPath(path)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "path"})
+		}
+		task, length, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
+			var l int
+			err := updateWorkOutcomesAt(workOutcomes, segs, func(current any, exists bool, at string) (any, error) {
+				if !exists {
+					l = 1
+					return []any{input.Body.Value}, nil
+				}
+				list, ok := current.([]any)
+				if !ok {
+					return nil, workOutcomesPathError{Path: at, Want: "an array", Got: jsonTypeName(current)}
+				}
+				l = len(list) + 1
+				return append(list, input.Body.Value), nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &l, nil
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := WorkOutcomesUpdateResponse{
+			Path:         path,
+			WorkOutcomes: taskResponse(task).WorkOutcomes,
+			Length:       length,
+		}
+		return &struct {
+			Body WorkOutcomesUpdateResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+func registerWorkOutcomesPut(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "put-task-work-outcomes",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/put",
+		Summary:     "Set a work outcomes value",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                 `path:"project_id"`
+		ID        string                 `path:"id"`
+		Body      WorkOutcomesPutRequest `json:"body"`
+	}) (*struct {
+		Body WorkOutcomesUpdateResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		path := strings.TrimSpace(input.Body.Path)
+		if path == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		segs, err := parseWorkOutcomesPath(path)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "path"})
+		}
+		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
+			return nil, updateWorkOutcomesAt(workOutcomes, segs, func(any, bool, string) (any, error) {
+				return input.Body.Value, nil
+			})
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := WorkOutcomesUpdateResponse{
+			Path:         path,
+			WorkOutcomes: taskResponse(task).WorkOutcomes,
+		}
+		return &struct {
+			Body WorkOutcomesUpdateResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+func registerWorkOutcomesMerge(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "merge-task-work-outcomes",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/merge",
+		Summary:     "Merge a work outcomes object",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                   `path:"project_id"`
+		ID        string                   `path:"id"`
+		Body      WorkOutcomesMergeRequest `json:"body"`
+	}) (*struct {
+		Body WorkOutcomesUpdateResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		path := strings.TrimSpace(input.Body.Path)
+		if path == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if input.Body.Value == nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", fmt.Sprintf("invalid work_outcomes.%s: value must be object", path), map[string]any{"field": "value"})
+		}
+		segs, err := parseWorkOutcomesPath(path)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "path"})
+		}
+		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
+			return nil, updateWorkOutcomesAt(workOutcomes, segs, func(current any, exists bool, at string) (any, error) {
+				if !exists {
+					return input.Body.Value, nil
+				}
+				obj, ok := current.(map[string]any)
+				if !ok {
+					return nil, workOutcomesPathError{Path: at, Want: "an object", Got: jsonTypeName(current)}
+				}
+				for k, v := range input.Body.Value {
+					obj[k] = v
+				}
+				return obj, nil
+			})
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := WorkOutcomesUpdateResponse{
+			Path:         path,
+			WorkOutcomes: taskResponse(task).WorkOutcomes,
+		}
+		return &struct {
+			Body WorkOutcomesUpdateResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+func registerBoard(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "project-board",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/board",
+		Summary:     "Kanban board",
+		Description: "Tasks grouped by status, one column per workflow state, with each status's WIP limit from `project.board.wip_limits`.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body BoardResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		board, err := e.Board(ctx, projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID), actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body BoardResponse `json:"body"`
+		}{Body: boardResponse(board)}, nil
+	})
+}
+
+func registerEscalations(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "preview-escalations",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/escalations/preview",
+		Summary:     "Preview priority escalations",
+		Description: "Tasks the next escalation sweep would raise one priority level under `project.escalation`, with their current and new priority. Nothing is changed.",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body []EscalationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		items, err := e.PreviewEscalations(ctx, projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID), actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]EscalationResponse, 0, len(items))
+		for _, esc := range items {
+			resp = append(resp, escalationResponse(esc))
+		}
+		return &struct {
+			Body []EscalationResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+}
+
+func registerMilestones(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-milestone",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/milestones",
+		Summary:       "Create milestone",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                 `path:"project_id"`
+		Body      CreateMilestoneRequest `json:"body"`
+	}) (*struct {
+		Body MilestoneResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		m, err := e.CreateMilestone(ctx, engine.MilestoneOptions{
+			ID:           input.Body.ID,
+			ProjectID:    projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID),
+			Goal:         input.Body.Goal,
+			TargetDate:   input.Body.TargetDate,
+			IterationIDs: input.Body.IterationIDs,
+			TaskIDs:      input.Body.TaskIDs,
+			ActorID:      actorID,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body MilestoneResponse `json:"body"`
+		}{Body: milestoneResponse(m)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-milestones",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/milestones",
+		Summary:     "List milestones",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body []MilestoneResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		items, err := e.ListMilestones(ctx, projectID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]MilestoneResponse, 0, len(items))
+		for _, m := range items {
+			resp = append(resp, milestoneResponse(m))
+		}
+		return &struct {
+			Body []MilestoneResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "link-milestone",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/milestones/{id}/links",
+		Summary:     "Link iterations and tasks to milestone",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string               `path:"project_id"`
+		ID        string               `path:"id"`
+		Body      LinkMilestoneRequest `json:"body"`
+	}) (*struct {
+		Body MilestoneResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		m, err := e.Repo.GetMilestone(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, m.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "milestone not found in project", nil)
+		}
+		m, err = e.LinkMilestone(ctx, input.ID, input.Body.IterationIDs, input.Body.TaskIDs, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body MilestoneResponse `json:"body"`
+		}{Body: milestoneResponse(m)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "milestone-status",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/milestones/{id}/status",
+		Summary:     "Milestone progress",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body MilestoneProgressResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		m, err := e.Repo.GetMilestone(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, m.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "milestone not found in project", nil)
+		}
+		p, err := e.MilestoneStatus(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body MilestoneProgressResponse `json:"body"`
+		}{Body: milestoneProgressResponse(p)}, nil
+	})
+}
+
+func registerIterations(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-iteration",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/iterations",
+		Summary:       "Create iteration",
+		DefaultStatus: http.StatusCreated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusConflict,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                 `path:"project_id"`
+		Body      CreateIterationRequest `json:"body"`
+	}) (*struct {
+		Body IterationResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if input.Body.Goal == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "goal is required", nil)
+		}
+		bodyProject := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		it := domain.Iteration{
+			ID:        input.Body.ID,
+			ProjectID: bodyProject,
+			Goal:      input.Body.Goal,
+			Capacity:  input.Body.Capacity,
+		}
+		res, err := e.CreateIteration(ctx, it, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body IterationResponse `json:"body"`
+		}{Body: iterationResponse(res)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-iterations",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/iterations",
+		Summary:     "List iterations",
+		Description: "Returns an ETag that changes with the project's latest event; send it back in If-None-Match to get 304 Not Modified while nothing changed.",
+		Errors:      []int{http.StatusBadRequest},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Limit     int    `query:"limit" default:"50"`
+		Cursor    string `query:"cursor"`
+		conditional.Params
+	}) (*struct {
+		ETag string              `header:"ETag"`
+		Body paginatedIterations `json:"body"`
+	}, error) {
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "iteration.list"); err != nil {
+			return nil, handleError(err)
+		}
+		etag, err := projectETag(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
+		}
+		limit := normalizeLimit(input.Limit)
+		cursorCreated, cursorID, err := parseCompositeCursor(input.Cursor)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+		}
+		items, err := e.Repo.ListIterationsWithCursor(ctx, projectID, limit+1, cursorCreated, cursorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := paginatedIterations{Items: []IterationResponse{}}
+		if len(items) > limit {
+			resp.NextCursor = composeCursor(items[limit].CreatedAt, items[limit].ID)
+			items = items[:limit]
+		}
+		for _, it := range items {
+			resp.Items = append(resp.Items, iterationResponse(it))
+		}
+		return &struct {
+			ETag string              `header:"ETag"`
+			Body paginatedIterations `json:"body"`
+		}{ETag: etag, Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "iteration-time",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/iterations/time",
+		Summary:     "Time logged per iteration",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+	}) (*struct {
+		Body []IterationTimeResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		totals, err := e.IterationTimeTotals(ctx, projectID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]IterationTimeResponse, 0, len(totals))
+		for _, it := range totals {
+			resp = append(resp, IterationTimeResponse{IterationID: it.IterationID, Minutes: it.Minutes})
+		}
+		return &struct {
+			Body []IterationTimeResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "set-iteration-capacity",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/iterations/{id}/capacity",
+		Summary:     "Set iteration capacity",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                      `path:"project_id"`
+		ID        string                      `path:"id"`
+		Body      SetIterationCapacityRequest `json:"body"`
+	}) (*struct {
+		Body IterationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		it, err := e.Repo.GetIteration(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, it.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
+		}
+		it, err = e.SetIterationCapacity(ctx, input.ID, input.Body.Capacity, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body IterationResponse `json:"body"`
+		}{Body: iterationResponse(it)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "iteration-progress",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/iterations/{id}/progress",
+		Summary:     "Planned vs completed estimates",
+		Errors: []int{
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+	}) (*struct {
+		Body IterationProgressResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		it, err := e.Repo.GetIteration(ctx, input.ID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !projectMatches(input.ProjectID, it.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
+		}
+		progress, err := e.IterationProgress(ctx, input.ID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body IterationProgressResponse `json:"body"`
+		}{Body: iterationProgressResponse(progress)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "set-iteration-status",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/iterations/{id}/status",
+		Summary:     "Update iteration status",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusUnprocessableEntity,
+			http.StatusConflict,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string                    `path:"project_id"`
+		ID        string                    `path:"id"`
+		Body      SetIterationStatusRequest `json:"body"`
+		Force     bool                      `query:"force"`
+	}) (*struct {
+		Body IterationResponse `json:"body"`
+	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		t, err := e.UpdateTask(ctx, opts)
+		if input.Body.Status == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "status is required", nil)
+		}
+		it, err := e.SetIterationStatus(ctx, input.ID, input.Body.Status, actorID, input.Force)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, t.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		if !projectMatches(input.ProjectID, it.ProjectID) {
+			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
 		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(t)}, nil
+			Body IterationResponse `json:"body"`
+		}{Body: iterationResponse(it)}, nil
 	})
+}
 
-	registerWorkOutcomesUpdates(api, e)
-
+func registerDecisions(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "complete-task",
-		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/done",
-		Summary:     "Complete task",
+		OperationID:   "create-decision",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/decisions",
+		Summary:       "Create decision",
+		DefaultStatus: http.StatusCreated,
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
@@ -1181,13 +3583,12 @@ func registerTasks(api huma.API, e engine.Engine) {
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string              `path:"project_id"`
-		ID        string              `path:"id"`
-		Body      CompleteTaskRequest `json:"body"`
-		Force     bool                `query:"force"`
+		ProjectID string                `path:"project_id"`
+		Body      CreateDecisionRequest `json:"body"`
 	}) (*struct {
-		Body TaskResponse `json:"body"`
+		Body DecisionResponse `json:"body"`
 	}, error) {
+		bodyMap := rawBodyMap(ctx)
 		if len(bodyBytes(ctx)) == 0 {
 			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
 		}
@@ -1195,31 +3596,53 @@ func registerTasks(api huma.API, e engine.Engine) {
 		if authErr != nil {
 			return nil, authErr
 		}
-		if input.Body.WorkOutcomes == nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "work_outcomes is required", nil)
+		if input.Body.Title == "" || input.Body.Decision == "" || input.Body.DeciderID == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "title, decision, and decider_id are required", nil)
 		}
-		data, err := json.Marshal(input.Body.WorkOutcomes)
-		if err != nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid work_outcomes", map[string]any{"error": err.Error()})
+		if isNullRaw(bodyMap["rationale"]) {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "rationale must be array", map[string]any{"field": "rationale", "reason": "must be array"})
 		}
-		workOutcomes := string(data)
-		t, err := e.TaskDone(ctx, input.ID, workOutcomes, actorID, input.Force)
+		if isNullRaw(bodyMap["alternatives"]) {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "alternatives must be array", map[string]any{"field": "alternatives", "reason": "must be array"})
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		d := domain.Decision{
+			ID:        input.Body.ID,
+			ProjectID: projectID,
+			Title:     input.Body.Title,
+			Decision:  input.Body.Decision,
+			DeciderID: input.Body.DeciderID,
+		}
+		if input.Body.Context != nil {
+			if data, err := json.Marshal(input.Body.Context); err == nil {
+				d.ContextJSON = string(data)
+			} else {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid context", map[string]any{"error": err.Error()})
+			}
+		}
+		if len(input.Body.Rationale) > 0 {
+			d.RationaleJSON = toJSONArray(input.Body.Rationale)
+		}
+		if len(input.Body.Alternatives) > 0 {
+			d.AlternativesJSON = toJSONArray(input.Body.Alternatives)
+		}
+		res, err := e.CreateDecision(ctx, d, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, t.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
-		}
 		return &struct {
-			Body TaskResponse `json:"body"`
-		}{Body: taskResponse(t)}, nil
+			Body DecisionResponse `json:"body"`
+		}{Body: decisionResponse(res)}, nil
 	})
+}
 
+func registerAttestations(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "claim-task",
-		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/claim",
-		Summary:     "Claim task lease",
+		OperationID:   "add-attestation",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/attestations",
+		Summary:       "Add attestation",
+		DefaultStatus: http.StatusCreated,
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
@@ -1229,706 +3652,623 @@ func registerTasks(api huma.API, e engine.Engine) {
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID    string `path:"project_id"`
-		ID           string `path:"id"`
-		LeaseSeconds int    `query:"lease_seconds" default:"900"`
+		ProjectID string                   `path:"project_id"`
+		Body      CreateAttestationRequest `json:"body"`
 	}) (*struct {
-		Body LeaseResponse `json:"body"`
+		Body AttestationResponse `json:"body"`
 	}, error) {
+		if len(bodyBytes(ctx)) == 0 {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		task, err := e.Repo.GetTask(ctx, input.ID)
-		if err != nil {
-			return nil, handleError(err)
+		if input.Body.EntityKind == "" || input.Body.EntityID == "" || input.Body.Kind == "" {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "entity_kind, entity_id and kind are required", nil)
 		}
-		if !projectMatches(input.ProjectID, task.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		payload := ""
+		if input.Body.Payload != nil {
+			b, err := json.Marshal(input.Body.Payload)
+			if err != nil {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid payload", map[string]any{"error": err.Error()})
+			}
+			payload = string(b)
 		}
-		lease, err := e.ClaimLease(ctx, input.ID, actorID, input.LeaseSeconds)
+		att := domain.Attestation{
+			ID:          strPtrValue(input.Body.ID),
+			ProjectID:   projectID,
+			EntityKind:  input.Body.EntityKind,
+			EntityID:    input.Body.EntityID,
+			Kind:        input.Body.Kind,
+			PayloadJSON: payload,
+		}
+		if input.Body.TS != nil {
+			att.TS = *input.Body.TS
+		}
+		res, err := e.AddAttestation(ctx, att, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body LeaseResponse `json:"body"`
-		}{Body: leaseResponse(lease)}, nil
+			Body AttestationResponse `json:"body"`
+		}{Body: attestationResponse(res)}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "release-task",
+		OperationID: "add-attestations-batch",
 		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/release",
-		Summary:     "Release task lease",
+		Path:        "/projects/{project_id}/attestations/batch",
+		Summary:     "Add attestations in batch",
+		Description: "Records up to 100 attestations in one transaction. Authority is checked per kind; each item reports its own status, and rejected items do not prevent the others from being recorded.",
 		Errors: []int{
+			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-		ID        string `path:"id"`
-	}) (*struct{}, error) {
+		ProjectID string                  `path:"project_id"`
+		Body      BatchAttestationRequest `json:"body"`
+	}) (*struct {
+		Body BatchAttestationResponse `json:"body"`
+	}, error) {
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		task, err := e.Repo.GetTask(ctx, input.ID)
-		if err != nil {
-			return nil, handleError(err)
-		}
-		if !projectMatches(input.ProjectID, task.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
-		}
-		if err := e.ReleaseLease(ctx, input.ID, actorID); err != nil {
-			return nil, handleError(err)
-		}
-		return &struct{}{}, nil
-	})
-
-	type treeInput struct {
-		ProjectID string `path:"project_id"`
-		Iteration string `query:"iteration_id"`
-		Status    string `query:"status"`
-	}
-	type treeNode struct {
-		Task     TaskResponse `json:"task"`
-		Children []treeNode   `json:"children"`
-	}
-	huma.Register(api, huma.Operation{
-		OperationID: "task-tree",
-		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks/tree",
-		Summary:     "Task tree",
-		Errors:      []int{http.StatusBadRequest},
-	}, func(ctx context.Context, input *treeInput) (*struct {
-		Body []treeNode `json:"body"`
-	}, error) {
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "task.tree"); err != nil {
-			return nil, handleError(err)
+		atts := make([]domain.Attestation, 0, len(input.Body.Attestations))
+		for i, item := range input.Body.Attestations {
+			payload := ""
+			if item.Payload != nil {
+				b, err := json.Marshal(item.Payload)
+				if err != nil {
+					return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid payload", map[string]any{"index": i, "error": err.Error()})
+				}
+				payload = string(b)
+			}
+			att := domain.Attestation{
+				EntityKind:  item.EntityKind,
+				EntityID:    item.EntityID,
+				Kind:        item.Kind,
+				PayloadJSON: payload,
+			}
+			if item.TS != nil {
+				att.TS = *item.TS
+			}
+			atts = append(atts, att)
 		}
-		tasks, err := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: projectID, Iteration: input.Iteration, Status: input.Status})
+		results, err := e.AddAttestations(ctx, projectID, atts, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		children := map[string][]domain.Task{}
-		var roots []domain.Task
-		for _, t := range tasks {
-			if t.ParentID != nil {
-				children[*t.ParentID] = append(children[*t.ParentID], t)
+		out := BatchAttestationResponse{Results: make([]BatchAttestationResult, 0, len(results))}
+		for i, res := range results {
+			item := BatchAttestationResult{Index: i, Status: http.StatusCreated}
+			if res.Err != nil {
+				out.Failed++
+				item.Status, item.Error = itemError(res.Err)
 			} else {
-				roots = append(roots, t)
-			}
-		}
-		var build func(domain.Task) treeNode
-		build = func(t domain.Task) treeNode {
-			kid := []treeNode{}
-			for _, c := range children[t.ID] {
-				kid = append(kid, build(c))
+				out.Created++
+				att := attestationResponse(res.Attestation)
+				item.Attestation = &att
 			}
-			return treeNode{Task: taskResponse(t), Children: kid}
-		}
-		res := []treeNode{}
-		for _, r := range roots {
-			res = append(res, build(r))
+			out.Results = append(out.Results, item)
 		}
 		return &struct {
-			Body []treeNode `json:"body"`
-		}{Body: res}, nil
+			Body BatchAttestationResponse `json:"body"`
+		}{Body: out}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "task-validation-status",
+		OperationID: "list-attestations",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks/{id}/validation",
-		Summary:     "Task validation status",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusNotFound,
-		},
+		Path:        "/projects/{project_id}/attestations",
+		Summary:     "List attestations",
+		Description: "Returns an ETag that changes with the project's latest event; send it back in If-None-Match to get 304 Not Modified while nothing changed.",
+		Errors:      []int{http.StatusBadRequest},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-		ID        string `path:"id"`
+		ProjectID  string `path:"project_id"`
+		EntityKind string `query:"entity_kind" enum:"project,iteration,task,decision"`
+		EntityID   string `query:"entity_id"`
+		Kind       string `query:"kind"`
+		Limit      int    `query:"limit" default:"50"`
+		Cursor     string `query:"cursor"`
+		conditional.Params
 	}) (*struct {
-		Body ValidationStatusResponse `json:"body"`
+		ETag string                `header:"ETag"`
+		Body paginatedAttestations `json:"body"`
 	}, error) {
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "task.validation.read"); err != nil {
+		if err := requirePermission(ctx, e, projectID, "attestation.list"); err != nil {
 			return nil, handleError(err)
 		}
-		t, err := e.Repo.GetTask(ctx, input.ID)
+		if err := auditRead(ctx, e, projectID, "attestations", ""); err != nil {
+			return nil, handleError(err)
+		}
+		etag, err := projectETag(ctx, e, projectID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, t.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "task not found in project", nil)
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
+		}
+		limit := normalizeLimit(input.Limit)
+		cursorTS, cursorID, err := parseCompositeCursor(input.Cursor)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+		}
+		f := repo.AttestationFilters{
+			ProjectID:  projectID,
+			EntityKind: input.EntityKind,
+			EntityID:   input.EntityID,
+			Kind:       input.Kind,
+			Limit:      limit + 1,
+			CursorTS:   cursorTS,
+			CursorID:   cursorID,
 		}
-		status, err := taskValidationStatus(ctx, e.Repo, t)
+		items, err := e.Repo.ListAttestations(ctx, f)
 		if err != nil {
 			return nil, handleError(err)
 		}
+		resp := paginatedAttestations{Items: []AttestationResponse{}}
+		if len(items) > limit {
+			resp.NextCursor = composeCursor(items[limit].TS, items[limit].ID)
+			items = items[:limit]
+		}
+		for _, att := range items {
+			resp.Items = append(resp.Items, attestationResponse(att))
+		}
 		return &struct {
-			Body ValidationStatusResponse `json:"body"`
-		}{Body: status}, nil
+			ETag string                `header:"ETag"`
+			Body paginatedAttestations `json:"body"`
+		}{ETag: etag, Body: resp}, nil
 	})
-}
 
-func registerValidations(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "create-validation",
+		OperationID:   "upload-evidence",
 		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/tasks/{id}/validations",
-		Summary:       "Create validation",
+		Path:          "/projects/{project_id}/attestations/{id}/evidence",
+		Summary:       "Attach evidence file to attestation",
 		DefaultStatus: http.StatusCreated,
+		MaxBodyBytes:  evidenceBodyLimit(e),
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusUnprocessableEntity,
+			http.StatusRequestEntityTooLarge,
+			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string            `path:"project_id"`
-		ID        string            `path:"id"`
-		Body      ValidationRequest `json:"body"`
+		ProjectID string `path:"project_id"`
+		ID        string `path:"id"`
+		RawBody   huma.MultipartFormFiles[struct {
+			File huma.FormFile `form:"file" contentType:"*/*" required:"true"`
+		}]
 	}) (*struct {
-		Body ValidationResponse `json:"body"`
+		Body EvidenceResponse `json:"body"`
 	}, error) {
-		if strings.TrimSpace(input.Body.Kind) == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "kind is required", map[string]any{"field": "kind"})
-		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
+		form := input.RawBody.Data()
+		if form == nil || !form.File.IsSet {
+			return nil, newAPIError(http.StatusBadRequest, "bad_request", "file is required", nil)
+		}
+		defer form.File.Close()
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		v, err := e.CreateValidation(ctx, engine.ValidationCreateOptions{
-			ProjectID: projectID,
-			TaskID:    input.ID,
-			Kind:      input.Body.Kind,
-			Status:    input.Body.Status,
-			Summary:   input.Body.Summary,
-			Issues:    input.Body.Issues,
-			URL:       input.Body.URL,
-			ActorID:   actorID,
+		ev, err := e.AddEvidence(ctx, engine.EvidenceOptions{
+			ProjectID:     projectID,
+			AttestationID: input.ID,
+			Name:          form.File.Filename,
+			ContentType:   form.File.ContentType,
+			Body:          form.File,
+			ActorID:       actorID,
 		})
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body ValidationResponse `json:"body"`
-		}{Body: validationResponse(v)}, nil
+			Body EvidenceResponse `json:"body"`
+		}{Body: evidenceResponse(ev, evidenceDownloadURL(ctx, ev))}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "list-validations",
+		OperationID: "list-evidence",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/tasks/{id}/validations",
-		Summary:     "List validations for task",
+		Path:        "/projects/{project_id}/attestations/{id}/evidence",
+		Summary:     "List attestation evidence",
 		Errors: []int{
-			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
+			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
 		ProjectID string `path:"project_id"`
 		ID        string `path:"id"`
 	}) (*struct {
-		Body ValidationsResponse `json:"body"`
+		Body []EvidenceResponse `json:"body"`
 	}, error) {
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		items, err := e.ListValidations(ctx, projectID, input.ID, actorID)
+		items, err := e.ListEvidence(ctx, projectID, input.ID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := ValidationsResponse{Items: []ValidationResponse{}}
-		for _, v := range items {
-			resp.Items = append(resp.Items, validationResponse(v))
+		if err := auditRead(ctx, e, projectID, "attestations", ""); err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]EvidenceResponse, 0, len(items))
+		for _, ev := range items {
+			resp = append(resp, evidenceResponse(ev, evidenceDownloadURL(ctx, ev)))
 		}
 		return &struct {
-			Body ValidationsResponse `json:"body"`
+			Body []EvidenceResponse `json:"body"`
 		}{Body: resp}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "get-validation",
+		OperationID: "download-evidence",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/validations/{id}",
-		Summary:     "Get validation",
+		Path:        "/projects/{project_id}/attestations/{id}/evidence/{evidence_id}/content",
+		Summary:     "Download evidence file",
 		Errors: []int{
-			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
+			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string `path:"project_id"`
-		ID        string `path:"id"`
+		ProjectID  string `path:"project_id"`
+		ID         string `path:"id"`
+		EvidenceID string `path:"evidence_id"`
 	}) (*struct {
-		Body ValidationResponse `json:"body"`
+		ContentType        string `header:"Content-Type"`
+		ContentDisposition string `header:"Content-Disposition"`
+		Body               []byte
 	}, error) {
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		v, err := e.GetValidation(ctx, projectID, input.ID, actorID)
+		ev, rc, err := e.OpenEvidence(ctx, projectID, input.ID, input.EvidenceID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		return &struct {
-			Body ValidationResponse `json:"body"`
-		}{Body: validationResponse(v)}, nil
-	})
-
-	huma.Register(api, huma.Operation{
-		OperationID: "update-validation",
-		Method:      http.MethodPatch,
-		Path:        "/projects/{project_id}/validations/{id}",
-		Summary:     "Update validation",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusForbidden,
-			http.StatusNotFound,
-			http.StatusUnprocessableEntity,
-		},
-	}, func(ctx context.Context, input *struct {
-		ProjectID string            `path:"project_id"`
-		ID        string            `path:"id"`
-		Body      ValidationRequest `json:"body"`
-	}) (*struct {
-		Body ValidationResponse `json:"body"`
-	}, error) {
-		actorID, authErr := actorIDFromContext(ctx)
-		if authErr != nil {
-			return nil, authErr
+		defer rc.Close()
+		if err := auditRead(ctx, e, projectID, "attestations", ""); err != nil {
+			return nil, handleError(err)
 		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		updated, err := e.UpdateValidation(ctx, engine.ValidationUpdateOptions{
-			ID:      input.ID,
-			Kind:    input.Body.Kind,
-			Status:  input.Body.Status,
-			Summary: input.Body.Summary,
-			Issues:  input.Body.Issues,
-			URL:     input.Body.URL,
-			ActorID: actorID,
-		})
+		data, err := io.ReadAll(rc)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if updated.ProjectID != projectID {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "validation not found in project", nil)
-		}
 		return &struct {
-			Body ValidationResponse `json:"body"`
-		}{Body: validationResponse(updated)}, nil
+			ContentType        string `header:"Content-Type"`
+			ContentDisposition string `header:"Content-Disposition"`
+			Body               []byte
+		}{
+			ContentType:        ev.ContentType,
+			ContentDisposition: mime.FormatMediaType("attachment", map[string]string{"filename": ev.Name}),
+			Body:               data,
+		}, nil
 	})
 }
 
-func registerWorkOutcomesUpdates(api huma.API, e engine.Engine) {
-	registerWorkOutcomesAppend(api, e)
-	registerWorkOutcomesPut(api, e)
-	registerWorkOutcomesMerge(api, e)
-}
-
-func registerWorkOutcomesAppend(api huma.API, e engine.Engine) {
+func registerEvents(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "append-task-work-outcomes",
-		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/append",
-		Summary:     "Append work outcomes entry",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusForbidden,
-			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusInternalServerError,
-		},
+		OperationID: "list-events",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/events",
+		Summary:     "List recent events",
+		Description: "Audit events (entity_kind rbac: RBAC changes, denied requests, actor and org changes, force use) are left out unless the caller also holds events.audit.read; asking for entity_kind=rbac without it is forbidden. Returns an ETag that changes with the project's latest event, for If-None-Match.",
+		Errors:      []int{http.StatusBadRequest, http.StatusForbidden},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                    `path:"project_id"`
-		ID        string                    `path:"id"`
-		Body      WorkOutcomesAppendRequest `json:"body"`
+		ProjectID  string `path:"project_id"`
+		Type       string `query:"type"`
+		EntityKind string `query:"entity_kind" enum:"project,iteration,task,decision,rbac"`
+		EntityID   string `query:"entity_id"`
+		Limit      int    `query:"limit" default:"50"`
+		Cursor     string `query:"cursor"`
+		conditional.Params
 	}) (*struct {
-		Body WorkOutcomesUpdateResponse `json:"body"`
+		ETag string          `header:"ETag"`
+		Body paginatedEvents `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := requirePermission(ctx, e, projectID, "project.events.read"); err != nil {
+			return nil, handleError(err)
 		}
-		path := strings.TrimSpace(input.Body.Path)
-		if path == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
+		if err := auditRead(ctx, e, projectID, "events", ""); err != nil {
+			return nil, handleError(err)
 		}
-		actorID, authErr := actorIDFromContext(ctx)
-		if authErr != nil {
-			return nil, authErr
+		etag, err := projectETag(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
 		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		task, length, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
-			existing, ok := workOutcomes[path]
-			if !ok || existing == nil {
-				workOutcomes[path] = []any{input.Body.Value}
-				l := 1
-				return &l, nil
+		if err := checkNotModified(&input.Params, etag); err != nil {
+			return nil, err
+		}
+		limit := normalizeLimit(input.Limit)
+		var cursorID int64
+		if input.Cursor != "" {
+			parsed, err := strconv.ParseInt(input.Cursor, 10, 64)
+			if err != nil {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
 			}
-			list, ok := existing.([]any)
-			if !ok {
-				return nil, fmt.Errorf("invalid work_outcomes.%s: must be array", path)
+			cursorID = parsed
+		}
+		filters := repo.EventFilters{ProjectID: projectID, Type: input.Type, EntityKind: input.EntityKind, EntityID: input.EntityID}
+		audit, err := canReadAuditEvents(ctx, e, projectID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		if !audit {
+			if input.EntityKind == events.AuditEntityKind {
+				return nil, handleError(auth.ForbiddenError{Permission: "events.audit.read"})
 			}
-			list = append(list, input.Body.Value)
-			workOutcomes[path] = list
-			l := len(list)
-			return &l, nil
-		})
+			filters.HideEntityKinds = []string{events.AuditEntityKind}
+		}
+		items, err := e.Repo.LatestEventsFrom(ctx, limit+1, cursorID, filters)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := WorkOutcomesUpdateResponse{
-			Path:         path,
-			WorkOutcomes: taskResponse(task).WorkOutcomes,
-			Length:       length,
+		resp := paginatedEvents{Items: []EventResponse{}}
+		if len(items) > limit {
+			resp.NextCursor = fmt.Sprintf("%d", items[limit].ID)
+			items = items[:limit]
+		}
+		for _, evt := range items {
+			resp.Items = append(resp.Items, eventResponse(evt))
 		}
 		return &struct {
-			Body WorkOutcomesUpdateResponse `json:"body"`
-		}{Body: resp}, nil
+			ETag string          `header:"ETag"`
+			Body paginatedEvents `json:"body"`
+		}{ETag: etag, Body: resp}, nil
 	})
-}
 
-func registerWorkOutcomesPut(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "put-task-work-outcomes",
-		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/put",
-		Summary:     "Set a work outcomes value",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusForbidden,
-			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusInternalServerError,
-		},
+		OperationID: "list-read-audit",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/read-audit",
+		Summary:     "List audited reads",
+		Description: "Reads of the project's config, events and attestations recorded under project.read_audit, newest first. Needs events.audit.read. since and until take RFC3339 or YYYY-MM-DD.",
+		Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                 `path:"project_id"`
-		ID        string                 `path:"id"`
-		Body      WorkOutcomesPutRequest `json:"body"`
+		ProjectID string `path:"project_id"`
+		Actor     string `query:"actor"`
+		Resource  string `query:"resource" enum:"config,events,attestations"`
+		Since     string `query:"since" doc:"Only reads at or after this time"`
+		Until     string `query:"until" doc:"Only reads before this time"`
+		Limit     int    `query:"limit" default:"50"`
+		Cursor    string `query:"cursor"`
 	}) (*struct {
-		Body WorkOutcomesUpdateResponse `json:"body"`
+		Body paginatedReadAudit `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
-		path := strings.TrimSpace(input.Body.Path)
-		if path == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
-		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
-			workOutcomes[path] = input.Body.Value
-			return nil, nil
+		limit := normalizeLimit(input.Limit)
+		var cursorID int64
+		if input.Cursor != "" {
+			parsed, err := strconv.ParseInt(input.Cursor, 10, 64)
+			if err != nil {
+				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+			}
+			cursorID = parsed
+		}
+		items, err := e.ReadAudit(ctx, actorID, repo.ReadAuditFilters{
+			ProjectID: projectID,
+			ActorID:   input.Actor,
+			Resource:  input.Resource,
+			Since:     input.Since,
+			Until:     input.Until,
+			Cursor:    cursorID,
+			Limit:     limit + 1,
 		})
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := WorkOutcomesUpdateResponse{
-			Path:         path,
-			WorkOutcomes: taskResponse(task).WorkOutcomes,
+		resp := paginatedReadAudit{Items: []ReadAuditEntryResponse{}}
+		if len(items) > limit {
+			items = items[:limit]
+			resp.NextCursor = fmt.Sprintf("%d", items[limit-1].ID)
+		}
+		for _, entry := range items {
+			resp.Items = append(resp.Items, readAuditEntryResponse(entry))
 		}
 		return &struct {
-			Body WorkOutcomesUpdateResponse `json:"body"`
+			Body paginatedReadAudit `json:"body"`
 		}{Body: resp}, nil
 	})
-}
 
-func registerWorkOutcomesMerge(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "merge-task-work-outcomes",
-		Method:      http.MethodPost,
-		Path:        "/projects/{project_id}/tasks/{id}/work-outcomes/merge",
-		Summary:     "Merge a work outcomes object",
-		Errors: []int{
-			http.StatusBadRequest,
-			http.StatusForbidden,
-			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusInternalServerError,
-		},
+		OperationID: "get-event-chain-head",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/events/head",
+		Summary:     "Get event chain head",
+		Description: "The latest hashed event of the project. Each event's hash covers its content and the previous event's hash, so an auditor who records the head hash periodically can later check with wl log verify --anchor that the history up to it was not altered.",
+		Errors:      []int{http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                   `path:"project_id"`
-		ID        string                   `path:"id"`
-		Body      WorkOutcomesMergeRequest `json:"body"`
+		ProjectID string `path:"project_id"`
 	}) (*struct {
-		Body WorkOutcomesUpdateResponse `json:"body"`
+		Body EventChainHeadResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
-		path := strings.TrimSpace(input.Body.Path)
-		if path == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "path is required", map[string]any{"field": "path"})
-		}
 		actorID, authErr := actorIDFromContext(ctx)
-		if authErr != nil {
-			return nil, authErr
-		}
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		task, _, err := mutateWorkOutcomes(ctx, e, projectID, input.ID, actorID, func(workOutcomes map[string]any) (*int, error) {
-			if input.Body.Value == nil {
-				return nil, fmt.Errorf("invalid work_outcomes.%s: value must be object", path)
-			}
-			existing, ok := workOutcomes[path]
-			if !ok || existing == nil {
-				workOutcomes[path] = input.Body.Value
-				return nil, nil
-			}
-			obj, ok := existing.(map[string]any)
-			if !ok {
-				return nil, fmt.Errorf("invalid work_outcomes.%s: must be object", path)
-			}
-			for k, v := range input.Body.Value {
-				obj[k] = v
-			}
-			workOutcomes[path] = obj
-			return nil, nil
-		})
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		head, err := e.EventChainHead(ctx, projectID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := WorkOutcomesUpdateResponse{
-			Path:         path,
-			WorkOutcomes: taskResponse(task).WorkOutcomes,
-		}
 		return &struct {
-			Body WorkOutcomesUpdateResponse `json:"body"`
-		}{Body: resp}, nil
+			Body EventChainHeadResponse `json:"body"`
+		}{Body: EventChainHeadResponse(head)}, nil
 	})
 }
 
-func registerIterations(api huma.API, e engine.Engine) {
+func registerRBAC(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "create-iteration",
-		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/iterations",
-		Summary:       "Create iteration",
-		DefaultStatus: http.StatusCreated,
+		OperationID: "whoami",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/me/permissions",
+		Summary:     "Current actor permissions",
 		Errors: []int{
 			http.StatusBadRequest,
-			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
-			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                 `path:"project_id"`
-		Body      CreateIterationRequest `json:"body"`
+		ProjectID string `path:"project_id"`
 	}) (*struct {
-		Body IterationResponse `json:"body"`
+		Body WhoAmIResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
-		actorID, authErr := actorIDFromContext(ctx)
+		principal, authErr := principalFromRequest(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		if input.Body.ID == "" || input.Body.Goal == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "id and goal are required", nil)
-		}
-		bodyProject := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		it := domain.Iteration{
-			ID:        input.Body.ID,
-			ProjectID: bodyProject,
-			Goal:      input.Body.Goal,
-		}
-		res, err := e.CreateIteration(ctx, it, actorID)
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		who, err := e.WhoAmI(ctx, projectID, principal.ActorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
 		return &struct {
-			Body IterationResponse `json:"body"`
-		}{Body: iterationResponse(res)}, nil
+			Body WhoAmIResponse `json:"body"`
+		}{Body: WhoAmIResponse{
+			ActorID:       who.ActorID,
+			OrgID:         principal.OrgID,
+			Roles:         nonNilSlice(who.Roles),
+			RoleExpiresAt: who.RoleExpiresAt,
+			Permissions:   nonNilSlice(who.Permissions),
+		}}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "list-iterations",
+		OperationID: "list-roles",
 		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/iterations",
-		Summary:     "List iterations",
-		Errors:      []int{http.StatusBadRequest},
+		Path:        "/projects/{project_id}/rbac/roles",
+		Summary:     "List roles",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
 	}, func(ctx context.Context, input *struct {
 		ProjectID string `path:"project_id"`
-		Limit     int    `query:"limit" default:"50"`
-		Cursor    string `query:"cursor"`
 	}) (*struct {
-		Body paginatedIterations `json:"body"`
+		Body []RoleResponse `json:"body"`
 	}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "iteration.list"); err != nil {
-			return nil, handleError(err)
-		}
-		limit := normalizeLimit(input.Limit)
-		cursorCreated, cursorID, err := parseCompositeCursor(input.Cursor)
-		if err != nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
 		}
-		items, err := e.Repo.ListIterationsWithCursor(ctx, projectID, limit+1, cursorCreated, cursorID)
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		roles, err := e.ListRoles(ctx, projectID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := paginatedIterations{Items: []IterationResponse{}}
-		if len(items) > limit {
-			resp.NextCursor = composeCursor(items[limit].CreatedAt, items[limit].ID)
-			items = items[:limit]
-		}
-		for _, it := range items {
-			resp.Items = append(resp.Items, iterationResponse(it))
+		resp := make([]RoleResponse, 0, len(roles))
+		for _, r := range roles {
+			resp = append(resp, roleResponse(r))
 		}
 		return &struct {
-			Body paginatedIterations `json:"body"`
+			Body []RoleResponse `json:"body"`
 		}{Body: resp}, nil
 	})
 
 	huma.Register(api, huma.Operation{
-		OperationID: "set-iteration-status",
-		Method:      http.MethodPatch,
-		Path:        "/projects/{project_id}/iterations/{id}/status",
-		Summary:     "Update iteration status",
+		OperationID: "get-role",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/rbac/roles/{role_id}",
+		Summary:     "Inspect role grants, attestation authorities and members",
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusUnprocessableEntity,
-			http.StatusConflict,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                    `path:"project_id"`
-		ID        string                    `path:"id"`
-		Body      SetIterationStatusRequest `json:"body"`
-		Force     bool                      `query:"force"`
+		ProjectID string `path:"project_id"`
+		RoleID    string `path:"role_id"`
 	}) (*struct {
-		Body IterationResponse `json:"body"`
+		Body RoleDetailResponse `json:"body"`
 	}, error) {
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		if input.Body.Status == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "status is required", nil)
-		}
-		it, err := e.SetIterationStatus(ctx, input.ID, input.Body.Status, actorID, input.Force)
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		role, err := e.InspectRole(ctx, projectID, actorID, input.RoleID)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		if !projectMatches(input.ProjectID, it.ProjectID) {
-			return nil, newAPIError(http.StatusNotFound, "not_found", "iteration not found in project", nil)
-		}
 		return &struct {
-			Body IterationResponse `json:"body"`
-		}{Body: iterationResponse(it)}, nil
+			Body RoleDetailResponse `json:"body"`
+		}{Body: roleDetailResponse(role)}, nil
 	})
-}
 
-func registerDecisions(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "create-decision",
-		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/decisions",
-		Summary:       "Create decision",
-		DefaultStatus: http.StatusCreated,
+		OperationID: "list-permissions",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/rbac/permissions",
+		Summary:     "List permissions",
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
-			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                `path:"project_id"`
-		Body      CreateDecisionRequest `json:"body"`
+		ProjectID string `path:"project_id"`
 	}) (*struct {
-		Body DecisionResponse `json:"body"`
+		Body []PermissionResponse `json:"body"`
 	}, error) {
-		bodyMap := rawBodyMap(ctx)
-		if len(bodyBytes(ctx)) == 0 {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
-		}
 		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
-		if input.Body.ID == "" || input.Body.Title == "" || input.Body.Decision == "" || input.Body.DeciderID == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "id, title, decision, and decider_id are required", nil)
-		}
-		if isNullRaw(bodyMap["rationale"]) {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "rationale must be array", map[string]any{"field": "rationale", "reason": "must be array"})
-		}
-		if isNullRaw(bodyMap["alternatives"]) {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "alternatives must be array", map[string]any{"field": "alternatives", "reason": "must be array"})
-		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		d := domain.Decision{
-			ID:        input.Body.ID,
-			ProjectID: projectID,
-			Title:     input.Body.Title,
-			Decision:  input.Body.Decision,
-			DeciderID: input.Body.DeciderID,
-		}
-		if input.Body.Context != nil {
-			if data, err := json.Marshal(input.Body.Context); err == nil {
-				d.ContextJSON = string(data)
-			} else {
-				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid context", map[string]any{"error": err.Error()})
-			}
-		}
-		if len(input.Body.Rationale) > 0 {
-			d.RationaleJSON = toJSONArray(input.Body.Rationale)
-		}
-		if len(input.Body.Alternatives) > 0 {
-			d.AlternativesJSON = toJSONArray(input.Body.Alternatives)
-		}
-		res, err := e.CreateDecision(ctx, d, actorID)
+		perms, err := e.ListPermissions(ctx, projectID, actorID)
 		if err != nil {
 			return nil, handleError(err)
 		}
+		resp := make([]PermissionResponse, 0, len(perms))
+		for _, p := range perms {
+			resp = append(resp, PermissionResponse{ID: p.ID, Description: p.Description})
+		}
 		return &struct {
-			Body DecisionResponse `json:"body"`
-		}{Body: decisionResponse(res)}, nil
+			Body []PermissionResponse `json:"body"`
+		}{Body: resp}, nil
 	})
-}
 
-func registerAttestations(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID:   "add-attestation",
+		OperationID:   "create-role",
 		Method:        http.MethodPost,
-		Path:          "/projects/{project_id}/attestations",
-		Summary:       "Add attestation",
+		Path:          "/projects/{project_id}/rbac/roles",
+		Summary:       "Define custom role",
 		DefaultStatus: http.StatusCreated,
 		Errors: []int{
 			http.StatusBadRequest,
 			http.StatusForbidden,
 			http.StatusNotFound,
 			http.StatusConflict,
-			http.StatusUnprocessableEntity,
 			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID string                   `path:"project_id"`
-		Body      CreateAttestationRequest `json:"body"`
+		ProjectID string            `path:"project_id"`
+		Body      CreateRoleRequest `json:"body"`
 	}) (*struct {
-		Body AttestationResponse `json:"body"`
+		Body RoleResponse `json:"body"`
 	}, error) {
 		if len(bodyBytes(ctx)) == 0 {
 			return nil, newAPIError(http.StatusBadRequest, "bad_request", "body required", nil)
@@ -1937,170 +4277,89 @@ func registerAttestations(api huma.API, e engine.Engine) {
 		if authErr != nil {
 			return nil, authErr
 		}
-		if input.Body.EntityKind == "" || input.Body.EntityID == "" || input.Body.Kind == "" {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "entity_kind, entity_id and kind are required", nil)
-		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		payload := ""
-		if input.Body.Payload != nil {
-			b, err := json.Marshal(input.Body.Payload)
-			if err != nil {
-				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid payload", map[string]any{"error": err.Error()})
-			}
-			payload = string(b)
-		}
-		att := domain.Attestation{
-			ID:          strPtrValue(input.Body.ID),
+		role, err := e.CreateRole(ctx, engine.RoleCreateOptions{
 			ProjectID:   projectID,
-			EntityKind:  input.Body.EntityKind,
-			EntityID:    input.Body.EntityID,
-			Kind:        input.Body.Kind,
-			PayloadJSON: payload,
-		}
-		if input.Body.TS != nil {
-			att.TS = *input.Body.TS
-		}
-		res, err := e.AddAttestation(ctx, att, actorID)
-		if err != nil {
-			return nil, handleError(err)
-		}
-		return &struct {
-			Body AttestationResponse `json:"body"`
-		}{Body: attestationResponse(res)}, nil
-	})
-
-	huma.Register(api, huma.Operation{
-		OperationID: "list-attestations",
-		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/attestations",
-		Summary:     "List attestations",
-		Errors:      []int{http.StatusBadRequest},
-	}, func(ctx context.Context, input *struct {
-		ProjectID  string `path:"project_id"`
-		EntityKind string `query:"entity_kind" enum:"project,iteration,task,decision"`
-		EntityID   string `query:"entity_id"`
-		Kind       string `query:"kind"`
-		Limit      int    `query:"limit" default:"50"`
-		Cursor     string `query:"cursor"`
-	}) (*struct {
-		Body paginatedAttestations `json:"body"`
-	}, error) {
-		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "attestation.list"); err != nil {
-			return nil, handleError(err)
-		}
-		limit := normalizeLimit(input.Limit)
-		cursorTS, cursorID, err := parseCompositeCursor(input.Cursor)
-		if err != nil {
-			return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
-		}
-		f := repo.AttestationFilters{
-			ProjectID:  projectID,
-			EntityKind: input.EntityKind,
-			EntityID:   input.EntityID,
-			Kind:       input.Kind,
-			Limit:      limit + 1,
-			CursorTS:   cursorTS,
-			CursorID:   cursorID,
-		}
-		items, err := e.Repo.ListAttestations(ctx, f)
+			RoleID:      input.Body.ID,
+			Description: input.Body.Description,
+			Grants:      input.Body.Grants,
+			ActorID:     actorID,
+		})
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := paginatedAttestations{Items: []AttestationResponse{}}
-		if len(items) > limit {
-			resp.NextCursor = composeCursor(items[limit].TS, items[limit].ID)
-			items = items[:limit]
-		}
-		for _, att := range items {
-			resp.Items = append(resp.Items, attestationResponse(att))
-		}
 		return &struct {
-			Body paginatedAttestations `json:"body"`
-		}{Body: resp}, nil
+			Body RoleResponse `json:"body"`
+		}{Body: roleResponse(role)}, nil
 	})
-}
-
-func registerEvents(api huma.API, e engine.Engine) {
-	huma.Register(api, huma.Operation{
-		OperationID: "list-events",
-		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/events",
-		Summary:     "List recent events",
-		Errors:      []int{http.StatusBadRequest},
+
+	huma.Register(api, huma.Operation{
+		OperationID: "update-role",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/rbac/roles/{role_id}",
+		Summary:     "Update custom role",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
 	}, func(ctx context.Context, input *struct {
-		ProjectID  string `path:"project_id"`
-		Type       string `query:"type"`
-		EntityKind string `query:"entity_kind" enum:"project,iteration,task,decision,rbac"`
-		EntityID   string `query:"entity_id"`
-		Limit      int    `query:"limit" default:"50"`
-		Cursor     string `query:"cursor"`
+		ProjectID string            `path:"project_id"`
+		RoleID    string            `path:"role_id"`
+		Body      UpdateRoleRequest `json:"body"`
 	}) (*struct {
-		Body paginatedEvents `json:"body"`
+		Body RoleResponse `json:"body"`
 	}, error) {
+		bodyMap := rawBodyMap(ctx)
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := requirePermission(ctx, e, projectID, "project.events.read"); err != nil {
-			return nil, handleError(err)
+		opts := engine.RoleUpdateOptions{
+			ProjectID:   projectID,
+			RoleID:      input.RoleID,
+			Description: input.Body.Description,
+			ActorID:     actorID,
 		}
-		limit := normalizeLimit(input.Limit)
-		var cursorID int64
-		if input.Cursor != "" {
-			parsed, err := strconv.ParseInt(input.Cursor, 10, 64)
-			if err != nil {
-				return nil, newAPIError(http.StatusBadRequest, "bad_request", "invalid cursor", map[string]any{"cursor": input.Cursor})
-			}
-			cursorID = parsed
+		if _, ok := bodyMap["grants"]; ok {
+			grants := input.Body.Grants
+			opts.Grants = &grants
 		}
-		items, err := e.Repo.LatestEventsFrom(ctx, limit+1, cursorID, projectID, input.Type, input.EntityKind, input.EntityID)
+		role, err := e.UpdateRole(ctx, opts)
 		if err != nil {
 			return nil, handleError(err)
 		}
-		resp := paginatedEvents{Items: []EventResponse{}}
-		if len(items) > limit {
-			resp.NextCursor = fmt.Sprintf("%d", items[limit].ID)
-			items = items[:limit]
-		}
-		for _, evt := range items {
-			resp.Items = append(resp.Items, eventResponse(evt))
-		}
 		return &struct {
-			Body paginatedEvents `json:"body"`
-		}{Body: resp}, nil
+			Body RoleResponse `json:"body"`
+		}{Body: roleResponse(role)}, nil
 	})
-}
 
-func registerRBAC(api huma.API, e engine.Engine) {
 	huma.Register(api, huma.Operation{
-		OperationID: "whoami",
-		Method:      http.MethodGet,
-		Path:        "/projects/{project_id}/me/permissions",
-		Summary:     "Current actor permissions",
+		OperationID: "delete-role",
+		Method:      http.MethodDelete,
+		Path:        "/projects/{project_id}/rbac/roles/{role_id}",
+		Summary:     "Delete custom role",
 		Errors: []int{
 			http.StatusBadRequest,
+			http.StatusForbidden,
 			http.StatusNotFound,
+			http.StatusInternalServerError,
 		},
 	}, func(ctx context.Context, input *struct {
 		ProjectID string `path:"project_id"`
-	}) (*struct {
-		Body WhoAmIResponse `json:"body"`
-	}, error) {
-		principal, authErr := principalFromRequest(ctx)
+		RoleID    string `path:"role_id"`
+	}) (*struct{}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
 		if authErr != nil {
 			return nil, authErr
 		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		who, err := e.WhoAmI(ctx, projectID, principal.ActorID)
-		if err != nil {
+		if err := e.DeleteRole(ctx, projectID, actorID, input.RoleID); err != nil {
 			return nil, handleError(err)
 		}
-		return &struct {
-			Body WhoAmIResponse `json:"body"`
-		}{Body: WhoAmIResponse{
-			ActorID:     who.ActorID,
-			OrgID:       principal.OrgID,
-			Roles:       nonNilSlice(who.Roles),
-			Permissions: nonNilSlice(who.Permissions),
-		}}, nil
+		return &struct{}{}, nil
 	})
 
 	huma.Register(api, huma.Operation{
@@ -2125,7 +4384,7 @@ func registerRBAC(api huma.API, e engine.Engine) {
 			return nil, authErr
 		}
 		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
-		if err := e.GrantRole(ctx, projectID, actorID, input.Body.ActorID, input.Body.RoleID); err != nil {
+		if err := e.GrantRoleUntil(ctx, projectID, actorID, input.Body.ActorID, input.Body.RoleID, input.Body.ExpiresAt); err != nil {
 			return nil, handleError(err)
 		}
 		return &struct{}{}, nil
@@ -2214,6 +4473,80 @@ func registerRBAC(api huma.API, e engine.Engine) {
 		}
 		return &struct{}{}, nil
 	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "apply-rbac-roster",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/rbac/apply",
+		Summary:     "Apply a team roster",
+		Description: "Reconciles the project's direct role grants, and its attestation authorities when given, with the roster: listed actors are registered and hold exactly their roles, other actors lose their project roles. Org-level grants are untouched. Returns the grants added and removed; `dry_run` reports them without applying. A roster that would take `rbac.manage` away from the caller is rejected.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusUnprocessableEntity,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string             `path:"project_id"`
+		DryRun    bool               `query:"dry_run" doc:"Report the changes without applying them"`
+		Body      ApplyRosterRequest `json:"body"`
+	}) (*struct {
+		Body RosterResultResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		res, err := e.ApplyRoster(ctx, projectID, actorID, input.Body.roster(), input.DryRun)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body RosterResultResponse `json:"body"`
+		}{Body: rosterResultResponse(res)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "simulate-permission",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/rbac/simulate",
+		Summary:     "Explain a permission check",
+		Description: "Explains whether an actor may perform an action without performing it: each check the server would run (active actor, permission, attestation authority or done_roles, archived project), whether it passes, the actor's grants satisfying it and, when it fails, the roles that would. `kind` is the attestation kind for `attestation.add` and the task type for `task.done`. Explaining another actor needs `rbac.manage`.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Actor     string `query:"actor" doc:"Actor to explain; defaults to the caller"`
+		Action    string `query:"action" required:"true" doc:"Permission id, e.g. task.done"`
+		Kind      string `query:"kind" doc:"Attestation kind for attestation.add, task type for task.done"`
+	}) (*struct {
+		Body PermissionSimulationResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		sim, err := e.SimulatePermission(ctx, engine.PermissionSimulationOptions{
+			ProjectID:     projectID,
+			ActorID:       actorID,
+			TargetActorID: input.Actor,
+			Action:        input.Action,
+			Kind:          input.Kind,
+		})
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body PermissionSimulationResponse `json:"body"`
+		}{Body: permissionSimulationResponse(sim)}, nil
+	})
 }
 
 func registerActorMissions(api huma.API, e engine.Engine) {
@@ -2370,6 +4703,109 @@ func registerActorMissions(api huma.API, e engine.Engine) {
 			Body ActorProfileResponse `json:"body"`
 		}{Body: actorProfileResponse(profile)}, nil
 	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deactivate-actor",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/actors/{actor_id}/deactivate",
+		Summary:     "Suspend actor and release its leases",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ActorID   string `path:"actor_id"`
+		Reason    string `query:"reason"`
+	}) (*struct {
+		Body ActorStatusResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		released, err := e.DeactivateActor(ctx, projectID, actorID, input.ActorID, input.Reason)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ActorStatusResponse `json:"body"`
+		}{Body: ActorStatusResponse{
+			ActorID:        input.ActorID,
+			Status:         engine.ActorStatusSuspended,
+			ReleasedLeases: nonNilSlice(released),
+		}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "scrub-actor",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/actors/{actor_id}/scrub",
+		Summary:     "Pseudonymize actor for a data-deletion request",
+		Description: "Replaces the actor id with a pseudonym everywhere it is stored, releases its leases, deletes its grants and API keys, and blocks the former id from signing in again. Rewritten events are rehashed. Needs actor.scrub.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ActorID   string `path:"actor_id"`
+		Reason    string `query:"reason"`
+	}) (*struct {
+		Body ActorScrubResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		scrub, err := e.ScrubActor(ctx, projectID, actorID, input.ActorID, input.Reason)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ActorScrubResponse `json:"body"`
+		}{Body: actorScrubResponse(scrub)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "reactivate-actor",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/actors/{actor_id}/reactivate",
+		Summary:     "Lift actor suspension",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		ActorID   string `path:"actor_id"`
+	}) (*struct {
+		Body ActorStatusResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		if err := e.ReactivateActor(ctx, projectID, actorID, input.ActorID); err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body ActorStatusResponse `json:"body"`
+		}{Body: ActorStatusResponse{
+			ActorID:        input.ActorID,
+			Status:         engine.ActorStatusActive,
+			ReleasedLeases: []string{},
+		}}, nil
+	})
 }
 
 func registerMe(api huma.API, e engine.Engine) {
@@ -2390,10 +4826,12 @@ func registerMe(api huma.API, e engine.Engine) {
 		}
 		roles := principal.Roles
 		perms := principal.Permissions
+		var expiries map[string]string
 		if len(perms) == 0 && e.Config != nil {
 			if who, err := e.WhoAmI(ctx, e.Config.Project.ID, principal.ActorID); err == nil {
 				if len(roles) == 0 {
 					roles = who.Roles
+					expiries = who.RoleExpiresAt
 				}
 				perms = who.Permissions
 			}
@@ -2401,10 +4839,11 @@ func registerMe(api huma.API, e engine.Engine) {
 		return &struct {
 			Body WhoAmIResponse `json:"body"`
 		}{Body: WhoAmIResponse{
-			ActorID:     principal.ActorID,
-			OrgID:       principal.OrgID,
-			Roles:       nonNilSlice(roles),
-			Permissions: nonNilSlice(perms),
+			ActorID:       principal.ActorID,
+			OrgID:         principal.OrgID,
+			Roles:         nonNilSlice(roles),
+			RoleExpiresAt: expiries,
+			Permissions:   nonNilSlice(perms),
 		}}, nil
 	})
 }
@@ -2477,21 +4916,8 @@ func isNullRaw(raw json.RawMessage) bool {
 	return len(trimmed) > 0 && bytes.Equal(trimmed, []byte("null"))
 }
 
-func parseWorkOutcomesMap(raw *string) (map[string]any, error) {
-	if raw == nil || *raw == "" {
-		return map[string]any{}, nil
-	}
-	var tmp any
-	if err := json.Unmarshal([]byte(*raw), &tmp); err != nil {
-		return nil, fmt.Errorf("invalid work_outcomes: %w", err)
-	}
-	obj, ok := tmp.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("invalid work_outcomes: must be object")
-	}
-	return obj, nil
-}
-
+// mutateWorkOutcomes runs mutate against a task's work outcomes through the
+// engine's versioned update, passing on the length mutate reports.
 func mutateWorkOutcomes(
 	ctx context.Context,
 	e engine.Engine,
@@ -2500,9 +4926,6 @@ func mutateWorkOutcomes(
 	actorID string,
 	mutate func(map[string]any) (*int, error),
 ) (domain.Task, *int, error) {
-	if err := requirePermission(ctx, e, projectID, "task.update"); err != nil {
-		return domain.Task{}, nil, err
-	}
 	task, err := e.Repo.GetTask(ctx, taskID)
 	if err != nil {
 		return domain.Task{}, nil, err
@@ -2510,42 +4933,31 @@ func mutateWorkOutcomes(
 	if !projectMatches(projectID, task.ProjectID) {
 		return domain.Task{}, nil, repo.ErrNotFound
 	}
-	if _, err := e.ClaimLease(ctx, taskID, actorID, 60); err != nil {
-		return domain.Task{}, nil, err
-	}
-	defer func() {
-		_ = e.ReleaseLease(ctx, taskID, actorID)
-	}()
-	task, err = e.Repo.GetTask(ctx, taskID)
-	if err != nil {
-		return domain.Task{}, nil, err
-	}
-	workOutcomes, err := parseWorkOutcomesMap(task.WorkOutcomesJSON)
-	if err != nil {
-		return domain.Task{}, nil, err
-	}
-	length, err := mutate(workOutcomes)
-	if err != nil {
-		return domain.Task{}, nil, err
-	}
-	data, err := json.Marshal(workOutcomes)
-	if err != nil {
-		return domain.Task{}, nil, fmt.Errorf("invalid work_outcomes: %w", err)
-	}
-	encoded := string(data)
-	opts := engine.TaskUpdateOptions{
-		ID:              taskID,
-		ActorID:         actorID,
-		WorkOutcomesSet: true,
-		SetWorkOutcomes: &encoded,
-	}
-	updated, err := e.UpdateTask(ctx, opts)
+	var length *int
+	updated, err := e.UpdateWorkOutcomes(ctx, taskID, actorID, func(workOutcomes map[string]any) error {
+		var err error
+		length, err = mutate(workOutcomes)
+		return err
+	})
 	if err != nil {
 		return domain.Task{}, nil, err
 	}
 	return updated, length, nil
 }
 
+// splitQueryValues flattens repeated and comma-separated query values.
+func splitQueryValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
 func normalizeLimit(in int) int {
 	if in <= 0 {
 		return 50
@@ -2574,6 +4986,35 @@ func composeCursor(ts, id string) string {
 	return ts + "|" + id
 }
 
+// Task cursors for the default order keep the plain created_at|id form.
+// Other orders wrap sort|key|id in base64 so keys such as titles survive the
+// query string, and a cursor cannot be replayed under a different order.
+func composeTaskCursor(sortBy repo.TaskSort, t domain.Task) string {
+	if sortBy == repo.DefaultTaskSort {
+		return composeCursor(t.CreatedAt, t.ID)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(sortBy.String() + "|" + sortBy.Key(t) + "|" + t.ID))
+}
+
+func parseTaskCursor(cursor string, sortBy repo.TaskSort) (string, string, error) {
+	if cursor == "" || sortBy == repo.DefaultTaskSort {
+		return parseCompositeCursor(cursor)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid cursor")
+	}
+	rest, ok := strings.CutPrefix(string(raw), sortBy.String()+"|")
+	if !ok {
+		return "", "", fmt.Errorf("invalid cursor")
+	}
+	i := strings.LastIndex(rest, "|")
+	if i < 0 || rest[i+1:] == "" {
+		return "", "", fmt.Errorf("invalid cursor")
+	}
+	return rest[:i], rest[i+1:], nil
+}
+
 func mapProjects(items []domain.Project) []ProjectResponse {
 	res := make([]ProjectResponse, 0, len(items))
 	for _, p := range items {
@@ -2582,6 +5023,33 @@ func mapProjects(items []domain.Project) []ProjectResponse {
 	return res
 }
 
+// withTaskLinks fills in DependsOn, ExternalRefs and Relations for listed tasks, which
+// ListTasks leaves empty, with batched queries instead of some per task.
+func withTaskLinks(ctx context.Context, r repo.Repository, tasks []domain.Task) error {
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	deps, err := r.ListDependenciesForTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	refs, err := r.ExternalRefsFor(ctx, "task", ids)
+	if err != nil {
+		return err
+	}
+	rels, err := r.RelationsForTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].DependsOn = deps[tasks[i].ID]
+		tasks[i].ExternalRefs = refs[tasks[i].ID]
+		tasks[i].Relations = rels[tasks[i].ID]
+	}
+	return nil
+}
+
 func mapTasks(items []domain.Task) []TaskResponse {
 	res := make([]TaskResponse, 0, len(items))
 	for _, t := range items {
@@ -2609,18 +5077,19 @@ func toJSONArray(items []string) string {
 	return string(b)
 }
 
-func taskValidationStatus(ctx context.Context, r repo.Repo, t domain.Task) (ValidationStatusResponse, error) {
+func taskValidationStatus(ctx context.Context, e engine.Engine, t domain.Task) (ValidationStatusResponse, error) {
 	required := decodeStringSlice(t.RequiredAttestationsJSON)
 	resp := ValidationStatusResponse{
 		Required: nonNilSlice(required),
 		Present:  []string{},
 		Missing:  []string{},
+		Expired:  []string{},
 	}
 	if len(required) == 0 {
 		resp.Satisfied = true
 		return resp, nil
 	}
-	atts, err := r.ListAttestations(ctx, repo.AttestationFilters{
+	atts, err := e.Repo.ListAttestations(ctx, repo.AttestationFilters{
 		EntityKind: "task",
 		EntityID:   t.ID,
 		ProjectID:  t.ProjectID,
@@ -2628,14 +5097,27 @@ func taskValidationStatus(ctx context.Context, r repo.Repo, t domain.Task) (Vali
 	if err != nil {
 		return resp, err
 	}
+	now := time.Now()
+	if e.Now != nil {
+		now = e.Now()
+	}
 	found := map[string]bool{}
+	expired := map[string]bool{}
 	for _, att := range atts {
+		if e.AttestationExpired(att, now) {
+			expired[att.Kind] = true
+			continue
+		}
 		found[att.Kind] = true
 	}
 	for _, req := range required {
-		if found[req] {
+		switch {
+		case found[req]:
 			resp.Present = append(resp.Present, req)
-		} else {
+		case expired[req]:
+			resp.Missing = append(resp.Missing, req)
+			resp.Expired = append(resp.Expired, req)
+		default:
 			resp.Missing = append(resp.Missing, req)
 		}
 	}
@@ -2650,6 +5132,27 @@ func projectFromPathOrHeader(ctx context.Context, pathProjectID, fallback string
 	return projectFromHeader(ctx, fallback)
 }
 
+// evidenceBodyLimit leaves room for multipart framing on top of the file limit.
+func evidenceBodyLimit(e engine.Engine) int64 {
+	limit := int64(config.DefaultEvidenceMaxBytes)
+	if e.Config != nil {
+		limit = e.Config.EvidenceMaxBytes()
+	}
+	return limit + 1<<20
+}
+
+// evidenceDownloadURL builds the API path serving the evidence content, keeping
+// the base path of the current request.
+func evidenceDownloadURL(ctx context.Context, ev domain.Evidence) string {
+	rel := fmt.Sprintf("/projects/%s/attestations/%s/evidence/%s/content", ev.ProjectID, ev.AttestationID, ev.ID)
+	if req, ok := ctx.Value(requestKey{}).(*http.Request); ok && req != nil {
+		if idx := strings.Index(req.URL.Path, "/projects/"); idx > 0 {
+			return req.URL.Path[:idx] + rel
+		}
+	}
+	return rel
+}
+
 func projectMatches(expected, actual string) bool {
 	if expected == "" {
 		return true
@@ -3,24 +3,46 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/golang-jwt/jwt/v5"
-
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"workline/internal/app"
 	"workline/internal/config"
 	"workline/internal/db"
 	"workline/internal/domain"
 	"workline/internal/engine"
+	"workline/internal/fieldcrypt"
 	"workline/internal/migrate"
 	"workline/internal/repo"
+	"workline/internal/server/worklinev1"
 )
 
 type authContext struct {
@@ -35,6 +57,7 @@ type testServer struct {
 	client    *http.Client
 	jwtSecret string
 	apiKey    string
+	handler   http.Handler
 	close     func()
 }
 
@@ -52,6 +75,12 @@ func newTestServer(t *testing.T) (*testServer, func()) {
 }
 
 func newTestServerWithAuth(t *testing.T, authCfg AuthConfig) (*testServer, func()) {
+	return newTestServerWithConfig(t, authCfg, nil)
+}
+
+// newTestServerWithConfig seeds the project, then lets configure adjust the
+// handler config (e.g. switch the engine to read-only) before serving.
+func newTestServerWithConfig(t *testing.T, authCfg AuthConfig, configure func(*Config)) (*testServer, func()) {
 	t.Helper()
 	defer func() {
 		if r := recover(); r != nil {
@@ -81,7 +110,7 @@ func newTestServerWithAuth(t *testing.T, authCfg AuthConfig) (*testServer, func(
 	}
 	orgID := "default-org"
 	e := engine.New(conn, cfg)
-	if _, err := e.InitProject(context.Background(), cfg.Project.ID, "default-org", "", "tester"); err != nil {
+	if _, err := e.InitProject(context.Background(), cfg.Project.ID, "default-org", "", "", "tester"); err != nil {
 		t.Fatalf("init project: %v", err)
 	}
 	if err := e.Repo.UpsertProjectConfig(context.Background(), cfg.Project.ID, cfg); err != nil {
@@ -95,7 +124,11 @@ func newTestServerWithAuth(t *testing.T, authCfg AuthConfig) (*testServer, func(
 	}); err != nil {
 		t.Fatalf("insert api key: %v", err)
 	}
-	handler, err := New(Config{Engine: e, BasePath: "/v0", Auth: authCfg})
+	serverCfg := Config{Engine: e, BasePath: "/v0", Auth: authCfg}
+	if configure != nil {
+		configure(&serverCfg)
+	}
+	handler, err := New(serverCfg)
 	if err != nil {
 		t.Fatalf("build handler: %v", err)
 	}
@@ -107,6 +140,7 @@ func newTestServerWithAuth(t *testing.T, authCfg AuthConfig) (*testServer, func(
 		client:    ts.Client(),
 		jwtSecret: jwtSecret,
 		apiKey:    apiKeyValue,
+		handler:   handler,
 		close: func() {
 			ts.Close()
 			conn.Close()
@@ -383,6 +417,7 @@ func TestPermissionGates(t *testing.T) {
 	}{
 		{"project list", http.MethodGet, srv.URL + "/v0/projects", nil, "project.list"},
 		{"project create", http.MethodPost, srv.URL + "/v0/projects", map[string]any{"id": "blocked-project", "org_id": "default-org"}, "project.create"},
+		{"org create", http.MethodPost, srv.URL + "/v0/orgs", map[string]any{"id": "blocked-org"}, "org.create"},
 		{"project read", http.MethodGet, srv.URL + "/v0/projects/perm-project", nil, "project.read"},
 		{"project update", http.MethodPatch, srv.URL + "/v0/projects/perm-project", map[string]any{"description": "blocked"}, "project.update"},
 		{"project delete", http.MethodDelete, srv.URL + "/v0/projects/perm-project", nil, "project.delete"},
@@ -618,6 +653,9 @@ func TestDecisionArraysPresent(t *testing.T) {
 			t.Fatalf("%s not array: %#v", key, val)
 		}
 	}
+	if payload["number"] != float64(1) {
+		t.Fatalf("expected the project's first decision to be number 1, got %v", payload["number"])
+	}
 }
 
 func TestTaskDoneWithAttestations(t *testing.T) {
@@ -878,6 +916,233 @@ func TestProjectsListArrayShape(t *testing.T) {
 	}
 }
 
+func TestCreateProjectKind(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects", map[string]any{
+		"id":     "lab",
+		"org_id": "default-org",
+		"kind":   "research",
+	}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create project: %d %s", res.StatusCode, string(data))
+	}
+	var project ProjectResponse
+	if err := json.Unmarshal(data, &project); err != nil {
+		t.Fatalf("unmarshal project: %v", err)
+	}
+	if project.Kind != "research" {
+		t.Fatalf("expected research kind, got %s", project.Kind)
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/lab/config", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), "experiment") {
+		t.Fatalf("expected research starter config, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects", map[string]any{
+		"id":     "garden",
+		"org_id": "default-org",
+		"kind":   "gardening",
+	}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown kind, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestOrgClaimScopesProjects(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	other := srv.bearerToken(t, "tester", "other-org", time.Now().Add(time.Hour))
+
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline", nil, bearerHeader(other))
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for foreign org, got %d: %s", res.StatusCode, string(data))
+	}
+	var apiErr struct {
+		Error apiErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(data, &apiErr); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if apiErr.Error.Code != "org_mismatch" {
+		t.Fatalf("unexpected error code %s", apiErr.Error.Code)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects", nil, bearerHeader(other))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("list projects status %d: %s", res.StatusCode, string(data))
+	}
+	var projects []ProjectResponse
+	if err := json.Unmarshal(data, &projects); err != nil {
+		t.Fatalf("unmarshal projects: %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("expected no projects for foreign org, got %v", projects)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/orgs/default-org/members", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("list members status %d: %s", res.StatusCode, string(data))
+	}
+	var members []OrgMemberResponse
+	if err := json.Unmarshal(data, &members); err != nil {
+		t.Fatalf("unmarshal members: %v", err)
+	}
+	if len(members) != 1 || members[0].ActorID != "tester" || members[0].Role != "owner" {
+		t.Fatalf("unexpected members: %v", members)
+	}
+}
+
+func TestOrgStatusAggregatesProjects(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+
+	for _, id := range []string{"dep-1", "blocked-1"} {
+		body := map[string]any{"id": id, "title": id, "type": "technical"}
+		if id == "blocked-1" {
+			body["depends_on"] = []string{"dep-1"}
+		}
+		res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/tasks", body, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s status %d: %s", id, res.StatusCode, string(data))
+		}
+	}
+
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/orgs/default-org/status", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("org status %d: %s", res.StatusCode, string(data))
+	}
+	var status OrgStatusResponse
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("unmarshal org status: %v", err)
+	}
+	if len(status.Projects) != 1 {
+		t.Fatalf("expected one project, got %v", status.Projects)
+	}
+	p := status.Projects[0]
+	if p.ProjectID != "workline" || p.Tasks != 2 || p.TaskCounts["planned"] != 2 || p.Blocked != 1 {
+		t.Fatalf("unexpected overview: %+v", p)
+	}
+
+	other := srv.bearerToken(t, "tester", "other-org", time.Now().Add(time.Hour))
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/orgs/default-org/status", nil, bearerHeader(other))
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for foreign org, got %d: %s", res.StatusCode, string(data))
+	}
+}
+
+func TestListStaleTasks(t *testing.T) {
+	var e engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Config.Project.Staleness.Days = 3
+		e = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+
+	for _, id := range []string{"idle-1", "busy-1"} {
+		res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/tasks", map[string]any{"id": id, "title": id, "type": "technical"}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s status %d: %s", id, res.StatusCode, string(data))
+		}
+	}
+	ctx := context.Background()
+	if _, err := e.DB.ExecContext(ctx, `UPDATE tasks SET status='in_progress', updated_at='2024-01-01T00:00:00Z' WHERE id='idle-1'`); err != nil {
+		t.Fatalf("age task: %v", err)
+	}
+	if stale, err := e.MarkStaleTasks(ctx, "workline", "system"); err != nil || len(stale) != 1 {
+		t.Fatalf("sweep: %+v, %v", stale, err)
+	}
+
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks?stale=true", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("list status %d: %s", res.StatusCode, string(data))
+	}
+	var page paginatedTasks
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("unmarshal tasks: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "idle-1" || !page.Items[0].Stale {
+		t.Fatalf("unexpected stale tasks: %+v", page.Items)
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks/busy-1", nil, nil)
+	if res.StatusCode != http.StatusOK || strings.Contains(string(data), `"stale"`) {
+		t.Fatalf("expected busy task without stale flag, got %d: %s", res.StatusCode, string(data))
+	}
+}
+
+func TestTaskDueDatesAndOverdueFilter(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+
+	for id, due := range map[string]string{"late-1": "2024-01-01", "later-1": "2999-01-01T00:00:00Z"} {
+		res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/tasks", map[string]any{"id": id, "title": id, "type": "technical", "due_at": due}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s status %d: %s", id, res.StatusCode, string(data))
+		}
+	}
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks?overdue=true", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("list status %d: %s", res.StatusCode, string(data))
+	}
+	var page paginatedTasks
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("unmarshal tasks: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "late-1" || page.Items[0].DueAt == nil || *page.Items[0].DueAt != "2024-01-01T23:59:59Z" {
+		t.Fatalf("unexpected overdue tasks: %+v", page.Items)
+	}
+
+	res, data = doJSON(t, client, http.MethodPatch, srv.URL+"/v0/projects/workline/tasks/late-1", map[string]any{"due_at": nil}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("clear due_at status %d: %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("unmarshal task: %v", err)
+	}
+	if task.DueAt != nil {
+		t.Fatalf("expected due_at cleared, got %v", *task.DueAt)
+	}
+	res, data = doJSON(t, client, http.MethodPatch, srv.URL+"/v0/projects/workline/tasks/late-1", map[string]any{"due_at": "tomorrow"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid due_at, got %d: %s", res.StatusCode, string(data))
+	}
+}
+
+func TestPreviewEscalations(t *testing.T) {
+	clock := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Now = func() time.Time { return clock }
+		c.Engine.Config.Project.Escalation = []config.EscalationRule{{Type: "bug", AfterDays: 7}}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	for id, priority := range map[string]int{"bug-1": 3, "bug-2": 1} {
+		res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"id": id, "title": id, "type": "bug", "priority": priority}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s status %d: %s", id, res.StatusCode, string(data))
+		}
+	}
+	clock = clock.AddDate(0, 0, 10)
+	res, data := doJSON(t, client, http.MethodGet, base+"/escalations/preview", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("preview status %d: %s", res.StatusCode, string(data))
+	}
+	var preview []EscalationResponse
+	if err := json.Unmarshal(data, &preview); err != nil {
+		t.Fatalf("unmarshal preview: %v", err)
+	}
+	if len(preview) != 1 || preview[0].TaskID != "bug-1" || preview[0].From != 3 || preview[0].To != 2 || preview[0].AfterDays != 7 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+}
+
 func TestTreeChildrenIncludedForLeaves(t *testing.T) {
 	srv, cleanup := newTestServer(t)
 	defer cleanup()
@@ -999,6 +1264,221 @@ func TestRoleGrantAllowsClaim(t *testing.T) {
 	}
 }
 
+func TestApplyRoster(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline/rbac/apply"
+	roster := map[string]any{
+		"actors": []map[string]any{{"id": "dev-2", "roles": []string{"dev"}}},
+	}
+
+	res, data := doJSON(t, client, http.MethodPost, base+"?dry_run=true", roster, nil)
+	var dry RosterResultResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &dry) != nil || !dry.DryRun || len(dry.Changes) == 0 {
+		t.Fatalf("dry run: %d %s", res.StatusCode, string(data))
+	}
+	devToken := srv.bearerToken(t, "dev-2", "default-org", time.Now().Add(time.Hour))
+	if res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks", nil, bearerHeader(devToken)); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected no grant after a dry run, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base, roster, nil)
+	var applied RosterResultResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &applied) != nil || applied.DryRun || len(applied.Changes) != len(dry.Changes) {
+		t.Fatalf("apply: %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks", nil, bearerHeader(devToken)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected the roster to grant dev, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base, map[string]any{
+		"actors": []map[string]any{{"id": "dev-2", "roles": []string{"wizard"}}},
+	}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown role, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestTimeBoxedRoleGrant(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second).Format(time.RFC3339)
+
+	res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/rbac/roles/grant", map[string]any{
+		"actor_id":   "temp-dev",
+		"role_id":    "dev",
+		"expires_at": expiresAt,
+	}, nil)
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		t.Fatalf("grant role: %d %s", res.StatusCode, string(data))
+	}
+	devToken := srv.bearerToken(t, "temp-dev", "default-org", time.Now().Add(time.Hour))
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/me/permissions", nil, bearerHeader(devToken))
+	var who WhoAmIResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &who) != nil || who.RoleExpiresAt["dev"] != expiresAt {
+		t.Fatalf("whoami: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/rbac/roles/grant", map[string]any{
+		"actor_id":   "temp-dev",
+		"role_id":    "dev",
+		"expires_at": "2001-01-01T00:00:00Z",
+	}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a past expiry, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/orgs/default-org/rbac/roles/grant", map[string]any{
+		"actor_id":   "temp-dev",
+		"role_id":    "dev",
+		"expires_at": expiresAt,
+	}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an expiring org grant, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestSimulatePermission(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline/rbac/simulate"
+
+	res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/rbac/roles/grant", map[string]any{"actor_id": "sim-dev", "role_id": "dev"}, nil)
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		t.Fatalf("grant role: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"?actor=sim-dev&action=rbac.manage", nil, nil)
+	var sim PermissionSimulationResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &sim) != nil || sim.Allowed || sim.ActorID != "sim-dev" {
+		t.Fatalf("simulate: %d %s", res.StatusCode, string(data))
+	}
+	if len(sim.Checks) < 2 || sim.Checks[1].Check != "permission" || sim.Checks[1].Passed || len(sim.Checks[1].Candidates) == 0 {
+		t.Fatalf("expected a failing permission check with candidates, got %+v", sim.Checks)
+	}
+
+	devToken := srv.bearerToken(t, "sim-dev", "default-org", time.Now().Add(time.Hour))
+	res, data = doJSON(t, client, http.MethodGet, base+"?action=task.claim", nil, bearerHeader(devToken))
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &sim) != nil || !sim.Allowed {
+		t.Fatalf("simulate self: %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodGet, base+"?actor=local-user&action=task.claim", nil, bearerHeader(devToken)); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 explaining another actor without rbac.manage, got %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodGet, base+"?action=task.fly", nil, nil); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown permission, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestReadAudit(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Config.Project.ReadAudit = config.ReadAuditConfig{Mode: "full", Resources: []string{"config", "events"}}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	for _, path := range []string{"/config", "/events?limit=5", "/attestations"} {
+		if res, data := doJSON(t, client, http.MethodGet, base+path, nil, nil); res.StatusCode != http.StatusOK {
+			t.Fatalf("get %s: %d %s", path, res.StatusCode, string(data))
+		}
+	}
+	res, data := doJSON(t, client, http.MethodGet, base+"/read-audit", nil, nil)
+	var page paginatedReadAudit
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &page) != nil || len(page.Items) != 2 {
+		t.Fatalf("read audit: %d %s", res.StatusCode, string(data))
+	}
+	if page.Items[0].Resource != "events" || page.Items[0].Target != "GET /v0/projects/workline/events?limit=5" || page.Items[1].Resource != "config" {
+		t.Fatalf("unexpected read audit %+v", page.Items)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/export/read-audit.csv?resource=config", nil, nil)
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/csv" {
+		t.Fatalf("export: %d %s", res.StatusCode, string(data))
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != "id,ts,actor_id,resource,target" || !strings.HasSuffix(lines[1], ",config,GET /v0/projects/workline/config") {
+		t.Fatalf("unexpected csv %q", string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/rbac/roles/grant", map[string]any{"actor_id": "audit-dev", "role_id": "dev"}, nil)
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		t.Fatalf("grant role: %d %s", res.StatusCode, string(data))
+	}
+	devToken := srv.bearerToken(t, "audit-dev", "default-org", time.Now().Add(time.Hour))
+	if res, data = doJSON(t, client, http.MethodGet, base+"/read-audit", nil, bearerHeader(devToken)); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 without events.audit.read, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestFieldEncryption(t *testing.T) {
+	var eng engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Config.Encryption = config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))}
+		c.Engine.Repo = repo.Repo{DB: c.Engine.DB, Fields: fieldcrypt.New(c.Engine.Config.Encryption)}
+		eng = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/attestations", map[string]any{
+		"entity_kind": "project",
+		"entity_id":   "workline",
+		"kind":        "ci.passed",
+		"payload":     map[string]any{"token": "s3cret"},
+	}, nil)
+	var att AttestationResponse
+	if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &att) != nil {
+		t.Fatalf("add attestation: %d %s", res.StatusCode, string(data))
+	}
+	var stored string
+	if err := eng.DB.QueryRow(`SELECT payload_json FROM attestations WHERE id=?`, att.ID).Scan(&stored); err != nil || !strings.HasPrefix(stored, "enc:v2:") || strings.Contains(stored, "s3cret") {
+		t.Fatalf("expected sealed payload, got %s, %v", stored, err)
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/attestations?entity_kind=project&entity_id=workline", nil, nil)
+	var page paginatedAttestations
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &page) != nil || len(page.Items) != 1 || page.Items[0].Payload["token"] != "s3cret" {
+		t.Fatalf("list attestations: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestScrubActor(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, nil)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/rbac/roles/grant", map[string]any{"actor_id": "erin", "role_id": "dev"}, nil)
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		t.Fatalf("grant role: %d %s", res.StatusCode, string(data))
+	}
+	erinToken := srv.bearerToken(t, "erin", "default-org", time.Now().Add(time.Hour))
+	if res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Erin's task", "type": "technical"}, bearerHeader(erinToken)); res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodPost, base+"/actors/local-user/scrub", nil, bearerHeader(erinToken)); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 without actor.scrub, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/actors/erin/scrub?reason=ticket-7", nil, nil)
+	var scrub ActorScrubResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &scrub) != nil || !strings.HasPrefix(scrub.Pseudonym, "anon-") {
+		t.Fatalf("scrub: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/events?limit=50", nil, nil)
+	if res.StatusCode != http.StatusOK || strings.Contains(string(data), `"erin"`) || !strings.Contains(string(data), scrub.Pseudonym) {
+		t.Fatalf("expected events to name only the pseudonym: %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodGet, base+"/tasks", nil, bearerHeader(erinToken)); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the scrubbed actor's token to be rejected, got %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodPost, base+"/actors/erin/scrub", nil, nil); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a second scrub to fail, got %d %s", res.StatusCode, string(data))
+	}
+}
+
 func TestForceRequiresPermission(t *testing.T) {
 	srv, cleanup := newTestServer(t)
 	defer cleanup()
@@ -1121,6 +1601,63 @@ func TestConfigEndpoint(t *testing.T) {
 	}
 }
 
+func TestConfigChangesRedactSecrets(t *testing.T) {
+	var e engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		e = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	ctx := context.Background()
+
+	cfg := config.Default("workline")
+	cfg.Webhooks = []config.WebhookConfig{{URL: "https://hooks.example.com/wl", Secret: "first-webhook-secret"}}
+	cfg.Digest.SMTP.Password = "first-smtp-password"
+	first, err := e.UpdateProjectConfig(ctx, "workline", "tester", cfg)
+	if err != nil {
+		t.Fatalf("add webhook: %v", err)
+	}
+	cfg.Webhooks[0].Secret = "rotated-webhook-secret"
+	cfg.Digest.SMTP.Password = "rotated-smtp-password"
+	rotated, err := e.UpdateProjectConfig(ctx, "workline", "tester", cfg)
+	if err != nil {
+		t.Fatalf("rotate secret: %v", err)
+	}
+	if len(rotated.Changes) != 2 {
+		t.Fatalf("expected webhook and password changes, got %+v", rotated.Changes)
+	}
+	for _, c := range rotated.Changes {
+		if !c.Redacted {
+			t.Fatalf("change to %s should be redacted: %+v", c.Path, c)
+		}
+	}
+
+	secrets := []string{"first-webhook-secret", "rotated-webhook-secret", "first-smtp-password", "rotated-smtp-password"}
+	assertNoSecrets := func(what string, data []byte) {
+		t.Helper()
+		for _, secret := range secrets {
+			if strings.Contains(string(data), secret) {
+				t.Fatalf("%s leaks %s: %s", what, secret, data)
+			}
+		}
+	}
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/events?type=config.updated", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), "hooks.example.com") {
+		t.Fatalf("events status %d: %s", res.StatusCode, data)
+	}
+	assertNoSecrets("config.updated events", data)
+	res, data = doJSON(t, client, http.MethodGet, fmt.Sprintf("%s/v0/projects/workline/config/diff?from=%d&to=%d", srv.URL, first.Version-1, rotated.Version), nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), `"redacted":true`) {
+		t.Fatalf("diff status %d: %s", res.StatusCode, data)
+	}
+	assertNoSecrets("config diff", data)
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/config/versions", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("versions status %d: %s", res.StatusCode, data)
+	}
+	assertNoSecrets("config versions", data)
+}
+
 func TestValidationEndpoint(t *testing.T) {
 	srv, cleanup := newTestServer(t)
 	defer cleanup()
@@ -1247,3 +1784,2294 @@ func TestPaginationProvidesCursor(t *testing.T) {
 		t.Fatalf("expected next_cursor to be set")
 	}
 }
+
+func TestReadOnlyModeRejectsWrites(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(cfg *Config) {
+		cfg.Engine.ReadOnly = true
+	})
+	defer cleanup()
+	client := srv.Client()
+
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected reads to work, got %d: %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/tasks", map[string]any{
+		"type":  "feature",
+		"title": "blocked",
+	}, nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", res.StatusCode, string(data))
+	}
+	var body struct {
+		Error apiErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if body.Error.Code != "read_only_mode" {
+		t.Fatalf("expected read_only_mode, got %+v", body.Error)
+	}
+}
+
+func TestCORSAndForwardedURLs(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(cfg *Config) {
+		cfg.CORS = CORSConfig{AllowedOrigins: []string{"https://dash.example"}}
+	})
+	defer cleanup()
+	client := srv.Client()
+
+	res, data := doJSON(t, client, http.MethodOptions, srv.URL+"/v0/projects", nil, map[string]string{
+		"Origin":                        "https://dash.example",
+		"Access-Control-Request-Method": http.MethodPost,
+	})
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected preflight 204, got %d: %s", res.StatusCode, string(data))
+	}
+	if got := res.Header.Get("Access-Control-Allow-Origin"); got != "https://dash.example" {
+		t.Fatalf("unexpected allow origin %q", got)
+	}
+	if !strings.Contains(res.Header.Get("Access-Control-Allow-Headers"), "X-Api-Key") {
+		t.Fatalf("expected default allowed headers, got %q", res.Header.Get("Access-Control-Allow-Headers"))
+	}
+	res, _ = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects", nil, map[string]string{"Origin": "https://evil.example"})
+	if got := res.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("unexpected allow origin for unlisted origin: %q", got)
+	}
+
+	forwarded := map[string]string{
+		"X-Forwarded-Proto":  "https",
+		"X-Forwarded-Host":   "api.example",
+		"X-Forwarded-Prefix": "/workline",
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/openapi.json", nil, forwarded)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("openapi status %d: %s", res.StatusCode, string(data))
+	}
+	var spec struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("unmarshal openapi: %v", err)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example/workline" {
+		t.Fatalf("unexpected servers: %+v", spec.Servers)
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/docs", nil, forwarded)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), "/workline/v0/openapi.json") {
+		t.Fatalf("expected docs to load the spec through the proxy prefix, got %d: %s", res.StatusCode, string(data))
+	}
+}
+
+func TestClientCertificateAuth(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{ClientCertActor: CertActorCN}, nil)
+	defer cleanup()
+
+	get := func(state *tls.ConnectionState) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/projects/workline/me/permissions", nil)
+		req.TLS = state
+		rec := httptest.NewRecorder()
+		srv.handler.ServeHTTP(rec, req)
+		return rec
+	}
+	verified := func(cn string) *tls.ConnectionState {
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}}
+	}
+
+	if rec := get(verified("tester")); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for verified client cert, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get(&tls.ConnectionState{}); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a verified chain, got %d", rec.Code)
+	}
+	if rec := get(verified("")); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for empty common name, got %d", rec.Code)
+	}
+}
+
+func TestInternalLeaseCleanup(t *testing.T) {
+	workspace := t.TempDir()
+	if _, err := db.EnsureWorkspace(workspace); err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	conn, err := db.Open(db.Config{Workspace: workspace})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	if err := migrate.Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	cfg := config.Default("workline")
+	e := engine.New(conn, cfg)
+	bg := context.Background()
+	if _, err := e.InitProject(bg, cfg.Project.ID, "default-org", "", "", "tester"); err != nil {
+		t.Fatalf("init project: %v", err)
+	}
+	task, err := e.CreateTask(bg, engine.TaskCreateOptions{ProjectID: cfg.Project.ID, Title: "lease", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	tracker := NewLeaseTracker()
+	leaseGone := func() bool {
+		_, err := e.Repo.GetLease(bg, task.ID)
+		return errors.Is(err, repo.ErrNotFound)
+	}
+
+	// A cancelled request still releases the lease it claimed.
+	ctx, cancel := context.WithCancel(context.WithValue(bg, leaseTrackerKey{}, tracker))
+	release, err := claimInternalLease(ctx, e, task.ID, "tester", 60)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if tracker.Len() != 1 {
+		t.Fatalf("expected tracked lease, got %d", tracker.Len())
+	}
+	cancel()
+	release()
+	if !leaseGone() || tracker.Len() != 0 {
+		t.Fatalf("expected lease released after cancellation")
+	}
+
+	// Leftover leases are released on shutdown.
+	ctx = context.WithValue(bg, leaseTrackerKey{}, tracker)
+	if _, err := claimInternalLease(ctx, e, task.ID, "tester", 60); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if err := tracker.ReleaseAll(bg, e); err != nil {
+		t.Fatalf("release all: %v", err)
+	}
+	if !leaseGone() || tracker.Len() != 0 {
+		t.Fatalf("expected lease released by ReleaseAll")
+	}
+
+	// A lease the actor already holds is left in place.
+	if _, err := e.ClaimLease(bg, task.ID, "tester", 300); err != nil {
+		t.Fatalf("claim own lease: %v", err)
+	}
+	release, err = claimInternalLease(ctx, e, task.ID, "tester", 60)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	release()
+	if leaseGone() || tracker.Len() != 0 {
+		t.Fatalf("expected actor's own lease to be kept")
+	}
+}
+
+func TestAttestationBatch(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	createRes, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Batch", "type": "feature"}, nil)
+	if createRes.StatusCode != http.StatusCreated {
+		t.Fatalf("create task status %d: %s", createRes.StatusCode, string(data))
+	}
+	var task TaskResponse
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("unmarshal task: %v", err)
+	}
+
+	res, body := doJSON(t, client, http.MethodPost, base+"/attestations/batch", map[string]any{
+		"attestations": []map[string]any{
+			{"entity_kind": "task", "entity_id": task.ID, "kind": "ci.passed"},
+			{"entity_kind": "task", "entity_id": task.ID, "kind": "no.such.kind"},
+		},
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("batch status %d: %s", res.StatusCode, string(body))
+	}
+	var out BatchAttestationResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("unmarshal batch: %v", err)
+	}
+	if out.Created != 1 || out.Failed != 1 || len(out.Results) != 2 {
+		t.Fatalf("unexpected batch result: %s", string(body))
+	}
+	if out.Results[0].Status != http.StatusCreated || out.Results[0].Attestation == nil {
+		t.Fatalf("expected first item created: %+v", out.Results[0])
+	}
+	if out.Results[1].Status != http.StatusForbidden || out.Results[1].Error == nil || out.Results[1].Error.Code != "forbidden_attestation_kind" {
+		t.Fatalf("expected second item forbidden: %+v", out.Results[1])
+	}
+
+	empty, emptyBody := doJSON(t, client, http.MethodPost, base+"/attestations/batch", map[string]any{"attestations": []any{}}, nil)
+	if empty.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty batch, got %d: %s", empty.StatusCode, string(emptyBody))
+	}
+}
+
+func TestBulkUpdateTasks(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	var ids []string
+	for _, title := range []string{"One", "Two"} {
+		res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": title, "type": "feature"}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create task status %d: %s", res.StatusCode, string(data))
+		}
+		var task TaskResponse
+		if err := json.Unmarshal(data, &task); err != nil {
+			t.Fatalf("unmarshal task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	res, body := doJSON(t, client, http.MethodPost, base+"/tasks/bulk-update", map[string]any{
+		"task_ids":    append(ids, "missing"),
+		"assignee_id": "alice",
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("bulk update status %d: %s", res.StatusCode, string(body))
+	}
+	var out BulkUpdateTasksResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("unmarshal bulk update: %v", err)
+	}
+	if out.Updated != 2 || out.Failed != 1 {
+		t.Fatalf("unexpected bulk result: %s", string(body))
+	}
+	if out.Results[0].Task == nil || out.Results[0].Task.AssigneeID == nil || *out.Results[0].Task.AssigneeID != "alice" {
+		t.Fatalf("expected assignee set: %+v", out.Results[0])
+	}
+	if out.Results[2].Status != http.StatusNotFound {
+		t.Fatalf("expected missing task 404, got %+v", out.Results[2])
+	}
+}
+
+func TestTaskCursorEncoding(t *testing.T) {
+	task := domain.Task{ID: "t-1", Title: "a|b & c", CreatedAt: "2024-01-01T00:00:00Z"}
+	if got := composeTaskCursor(repo.DefaultTaskSort, task); got != "2024-01-01T00:00:00Z|t-1" {
+		t.Fatalf("default cursor changed: %s", got)
+	}
+	byTitle := repo.TaskSort{Field: "title"}
+	cursor := composeTaskCursor(byTitle, task)
+	value, id, err := parseTaskCursor(cursor, byTitle)
+	if err != nil || value != task.Title || id != task.ID {
+		t.Fatalf("round trip: %q %q %v", value, id, err)
+	}
+	if _, _, err := parseTaskCursor(cursor, repo.TaskSort{Field: "title", Desc: true}); err == nil {
+		t.Fatalf("expected cursor from another sort order to be rejected")
+	}
+}
+
+func TestTaskHistoryEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "History", "type": "feature"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task status %d: %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("unmarshal task: %v", err)
+	}
+	if res, data := doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID, map[string]any{"assignee_id": "alice"}, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("assign status %d: %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/"+task.ID+"/history", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("history status %d: %s", res.StatusCode, string(data))
+	}
+	var history []TaskHistoryEntryResponse
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatalf("unmarshal history: %v", err)
+	}
+	last := history[len(history)-1]
+	if last.Category != "assignment" || last.Summary != "assigned to alice" {
+		t.Fatalf("unexpected history: %s", string(data))
+	}
+	if res, _ := doJSON(t, client, http.MethodGet, base+"/tasks/missing/history", nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing task, got %d", res.StatusCode)
+	}
+}
+
+func TestRevertTaskEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Revert", "type": "feature"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task status %d: %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("unmarshal task: %v", err)
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/revert", nil, nil)
+	if res.StatusCode != http.StatusConflict || !strings.Contains(string(data), "revert_unavailable") {
+		t.Fatalf("expected 409 revert_unavailable, got %d: %s", res.StatusCode, string(data))
+	}
+	if res, data := doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/claim", nil, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("claim status %d: %s", res.StatusCode, string(data))
+	}
+	if res, data := doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID, map[string]any{"status": "ready"}, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("update status %d: %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/revert", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("revert status %d: %s", res.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("unmarshal task: %v", err)
+	}
+	if task.Status != "planned" {
+		t.Fatalf("expected planned after revert, got %s", task.Status)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := map[string]any{}
+	if err := json.Unmarshal([]byte(`{"report":{"checks":[{"name":"lint","ok":false}],"a/b":1},"notes":"x"}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	var ops []JSONPatchOperation
+	if err := json.Unmarshal([]byte(`[
+		{"op":"test","path":"/report/checks/0/name","value":"lint"},
+		{"op":"replace","path":"/report/checks/0/ok","value":true},
+		{"op":"add","path":"/report/checks/-","value":{"name":"unit","ok":true}},
+		{"op":"add","path":"/report/checks/0","value":{"name":"fmt","ok":true}},
+		{"op":"remove","path":"/report/a~1b"},
+		{"op":"remove","path":"/notes"},
+		{"op":"add","path":"/report/summary","value":"green"}
+	]`), &ops); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyJSONPatch(doc, ops); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	got, _ := json.Marshal(doc)
+	want := `{"report":{"checks":[{"name":"fmt","ok":true},{"name":"lint","ok":true},{"name":"unit","ok":true}],"summary":"green"}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	for name, tc := range map[string]struct {
+		op       JSONPatchOperation
+		testFail bool
+	}{
+		"test mismatch":       {JSONPatchOperation{Op: "test", Path: "/report/summary", Value: "red"}, true},
+		"missing parent":      {JSONPatchOperation{Op: "add", Path: "/missing/x", Value: 1}, false},
+		"replace missing":     {JSONPatchOperation{Op: "replace", Path: "/nope", Value: 1}, false},
+		"index out of range":  {JSONPatchOperation{Op: "remove", Path: "/report/checks/3"}, false},
+		"leading zero":        {JSONPatchOperation{Op: "replace", Path: "/report/checks/01", Value: 1}, false},
+		"scalar parent":       {JSONPatchOperation{Op: "add", Path: "/report/summary/x", Value: 1}, false},
+		"unsupported op":      {JSONPatchOperation{Op: "move", Path: "/report"}, false},
+		"relative pointer":    {JSONPatchOperation{Op: "add", Path: "report", Value: 1}, false},
+		"non-object document": {JSONPatchOperation{Op: "replace", Path: "", Value: []any{}}, false},
+	} {
+		err := applyJSONPatch(doc, []JSONPatchOperation{tc.op})
+		var te jsonPatchTestError
+		if err == nil || errors.As(err, &te) != tc.testFail {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+		if !tc.testFail && !strings.Contains(err.Error(), "invalid") {
+			t.Fatalf("%s: expected a bad request error, got %v", name, err)
+		}
+	}
+}
+
+func TestWorkOutcomesPatchEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Patch", "type": "docs"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	url := base + "/tasks/" + task.ID + "/work-outcomes/patch"
+
+	res, data = doJSON(t, client, http.MethodPost, url, []map[string]any{
+		{"op": "add", "path": "/report", "value": map[string]any{"checks": []any{}}},
+		{"op": "add", "path": "/report/checks/-", "value": "lint"},
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("patch: %d %s", res.StatusCode, string(data))
+	}
+	var resp WorkOutcomesUpdateResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("unmarshal patch response: %v", err)
+	}
+	report, _ := resp.WorkOutcomes["report"].(map[string]any)
+	if checks, _ := report["checks"].([]any); len(checks) != 1 {
+		t.Fatalf("unexpected work outcomes %+v", resp.WorkOutcomes)
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, url, []map[string]any{
+		{"op": "add", "path": "/report/checks/-", "value": "unit"},
+		{"op": "test", "path": "/report/checks/0", "value": "fmt"},
+	}, nil)
+	if res.StatusCode != http.StatusConflict || !strings.Contains(string(data), "patch_test_failed") {
+		t.Fatalf("expected failed test to conflict, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/"+task.ID, nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("get task: %d %s", res.StatusCode, string(data))
+	}
+	_ = json.Unmarshal(data, &task)
+	report, _ = task.WorkOutcomes["report"].(map[string]any)
+	if checks, _ := report["checks"].([]any); len(checks) != 1 {
+		t.Fatalf("expected failed patch to leave work outcomes unchanged, got %+v", task.WorkOutcomes)
+	}
+}
+
+func TestWorkOutcomesNestedPaths(t *testing.T) {
+	doc := map[string]any{"notes": "x"}
+	set := func(path string, value any) error {
+		segs, err := parseWorkOutcomesPath(path)
+		if err != nil {
+			return err
+		}
+		return updateWorkOutcomesAt(doc, segs, func(any, bool, string) (any, error) { return value, nil })
+	}
+	for _, tc := range []struct {
+		path  string
+		value any
+	}{
+		{"results.tests[0].status", "pass"},
+		{"results.tests[1]", map[string]any{"status": "fail"}},
+		{"/results/tests/1/status", "pass"},
+		{"/results/a~1b", true},
+		{"flat", 1.0},
+	} {
+		if err := set(tc.path, tc.value); err != nil {
+			t.Fatalf("set %s: %v", tc.path, err)
+		}
+	}
+	got, _ := json.Marshal(doc)
+	want := `{"flat":1,"notes":"x","results":{"a/b":true,"tests":[{"status":"pass"},{"status":"pass"}]}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	var conflict workOutcomesPathError
+	if err := set("notes.sub", 1); !errors.As(err, &conflict) || conflict.Path != ".notes" || conflict.Got != "a string" {
+		t.Fatalf("expected conflict at .notes, got %v", err)
+	}
+	if err := set("results[0]", 1); !errors.As(err, &conflict) || conflict.Want != "an array" {
+		t.Fatalf("expected array conflict, got %v", err)
+	}
+	if err := set("results.tests[5]", 1); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Fatalf("expected index past end to be rejected, got %v", err)
+	}
+	for _, bad := range []string{"a..b", "a.", "a[x]", "a[1", "a[0]b"} {
+		if _, err := parseWorkOutcomesPath(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestWorkOutcomesNestedAppendEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Nested", "type": "docs"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/append", map[string]any{
+		"path":  "results.tests",
+		"value": map[string]any{"name": "unit"},
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("append: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/merge", map[string]any{
+		"path":  "results.tests[0]",
+		"value": map[string]any{"status": "pass"},
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("merge: %d %s", res.StatusCode, string(data))
+	}
+	var resp WorkOutcomesUpdateResponse
+	_ = json.Unmarshal(data, &resp)
+	got, _ := json.Marshal(resp.WorkOutcomes)
+	if string(got) != `{"results":{"tests":[{"name":"unit","status":"pass"}]}}` {
+		t.Fatalf("unexpected work outcomes %s", got)
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/append", map[string]any{
+		"path":  "results.tests[0].name",
+		"value": "x",
+	}, nil)
+	if res.StatusCode != http.StatusConflict || !strings.Contains(string(data), "path_conflict") {
+		t.Fatalf("expected path conflict, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestWorkOutcomesLimitsEndpoint(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(cfg *Config) {
+		projectCfg := cfg.Engine.Config
+		projectCfg.Project.WorkOutcomes.MaxBytes = 48
+		docs := projectCfg.Project.TaskTypes["docs"]
+		docs.WorkOutcomesSchema = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"summary": map[string]any{"type": "string"}},
+		}
+		projectCfg.Project.TaskTypes["docs"] = docs
+		if err := cfg.Engine.Repo.UpsertProjectConfig(context.Background(), projectCfg.Project.ID, projectCfg); err != nil {
+			t.Fatalf("seed project config: %v", err)
+		}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodGet, base+"/config", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("get config: %d %s", res.StatusCode, string(data))
+	}
+	if !strings.Contains(string(data), `"work_outcomes_schema"`) || !strings.Contains(string(data), `"max_bytes":48`) {
+		t.Fatalf("config does not expose work outcomes limits: %s", string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Limits", "type": "docs"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/put", map[string]any{
+		"path":  "summary",
+		"value": 42,
+	}, nil)
+	if res.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(string(data), "invalid_work_outcomes") {
+		t.Fatalf("expected schema violation, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/put", map[string]any{
+		"path":  "summary",
+		"value": strings.Repeat("x", 64),
+	}, nil)
+	if res.StatusCode != http.StatusUnprocessableEntity || !strings.Contains(string(data), `"max_bytes":48`) {
+		t.Fatalf("expected size violation, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/work-outcomes/put", map[string]any{
+		"path":  "summary",
+		"value": "ok",
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("put: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestSuggestAssigneeEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Suggest", "type": "docs"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/"+task.ID+"/suggest-assignee", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("suggest: %d %s", res.StatusCode, string(data))
+	}
+	var items []AssigneeSuggestionResponse
+	if err := json.Unmarshal(data, &items); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(items) == 0 || items[0].ActorID != "tester" || items[0].OpenTasks != 0 {
+		t.Fatalf("unexpected suggestions %+v", items)
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/missing/suggest-assignee", nil, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestClaimNextEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "Loop"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Next", "type": "docs", "iteration_id": "iter-1", "priority": 1}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	res, data = doJSON(t, client, http.MethodPatch, base+"/iterations/iter-1/status", map[string]any{"status": "running"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("set running: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/claim-next?type=feature", nil, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for unmatched type, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/claim-next?type=docs&lease_seconds=120", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("claim next: %d %s", res.StatusCode, string(data))
+	}
+	var claimed ClaimNextResponse
+	if err := json.Unmarshal(data, &claimed); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if claimed.Task.ID != task.ID || claimed.Lease.TaskID != task.ID || claimed.Lease.OwnerID != "tester" {
+		t.Fatalf("unexpected claim %+v", claimed)
+	}
+}
+
+func TestOpenAPISpecExport(t *testing.T) {
+	data, err := OpenAPISpec("/v0")
+	if err != nil {
+		t.Fatalf("openapi spec: %v", err)
+	}
+	again, err := OpenAPISpec("/v0")
+	if err != nil {
+		t.Fatalf("openapi spec: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("expected spec export to be deterministic")
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("unmarshal openapi: %v", err)
+	}
+	paths := spec["paths"].(map[string]any)
+	if _, ok := paths["/v0/projects/{project_id}/tasks"]; !ok {
+		t.Fatalf("expected routes under base path, got %d paths", len(paths))
+	}
+	for route, item := range paths {
+		for method, raw := range item.(map[string]any) {
+			op := raw.(map[string]any)
+			if id, _ := op["operationId"].(string); id == "" {
+				t.Fatalf("%s %s missing operationId", method, route)
+			}
+			resps := op["responses"].(map[string]any)
+			if _, ok := resps["default"]; !ok {
+				t.Fatalf("%s %s missing default error response", method, route)
+			}
+		}
+	}
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+	if _, ok := schemas["ApiError"]; !ok {
+		t.Fatalf("ApiError schema missing")
+	}
+	page, ok := schemas["PaginatedTasks"].(map[string]any)
+	if !ok {
+		t.Fatalf("PaginatedTasks schema missing")
+	}
+	if _, ok := page["properties"].(map[string]any)["next_cursor"]; !ok {
+		t.Fatalf("next_cursor missing from PaginatedTasks")
+	}
+	status := schemas["IterationResponse"].(map[string]any)["properties"].(map[string]any)["status"].(map[string]any)
+	if _, ok := status["enum"]; !ok {
+		t.Fatalf("iteration status enum missing: %#v", status)
+	}
+}
+
+func TestCheckOperationIDs(t *testing.T) {
+	oas := &huma.OpenAPI{Paths: map[string]*huma.PathItem{
+		"/a": {Get: &huma.Operation{OperationID: "get-a"}},
+		"/b": {Get: &huma.Operation{OperationID: "get-a"}, Post: &huma.Operation{}},
+	}}
+	err := checkOperationIDs(oas)
+	if err == nil {
+		t.Fatalf("expected duplicate and missing operation ids to be rejected")
+	}
+	if !strings.Contains(err.Error(), "POST /b: missing operation id") || !strings.Contains(err.Error(), `"get-a" already used`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTaskComponentEndpoints(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(cfg *Config) {
+		cfg.Engine.Config.Project.Components = map[string]config.ComponentConfig{"api": {}, "web": {}}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Rate limit", "type": "technical", "component": "api"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	if task.Component != "api" {
+		t.Fatalf("expected api component, got %q", task.Component)
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Lost", "type": "technical", "component": "mobile"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown component, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Unscoped", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks?component=api", nil, nil)
+	var page paginatedTasks
+	if err := json.Unmarshal(data, &page); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("list tasks: %d %s", res.StatusCode, string(data))
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != task.ID {
+		t.Fatalf("expected only the api task, got %+v", page.Items)
+	}
+
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID, map[string]any{"component": "web"}, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), `"component":"web"`) {
+		t.Fatalf("move component: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/status", nil, nil)
+	var status struct {
+		ComponentCounts map[string]map[string]int `json:"component_counts"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("status: %d %s", res.StatusCode, string(data))
+	}
+	if len(status.ComponentCounts) != 1 || status.ComponentCounts["web"]["planned"] != 1 {
+		t.Fatalf("unexpected component counts %v", status.ComponentCounts)
+	}
+}
+
+func TestMilestoneEndpoints(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "Beta"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	for _, title := range []string{"Signup", "Billing"} {
+		res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": title, "type": "technical", "iteration_id": "iter-1"}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+		}
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Docs", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var docs TaskResponse
+	_ = json.Unmarshal(data, &docs)
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/milestones", map[string]any{"id": "m-q3", "goal": "Public beta", "target_date": "next week"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad target date, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/milestones", map[string]any{"id": "m-q3", "goal": "Public beta", "target_date": "2099-09-30", "iteration_ids": []string{"iter-1"}}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create milestone: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/milestones", map[string]any{"id": "m-q3", "goal": "Again", "target_date": "2099-09-30"}, nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate milestone, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/milestones/m-q3/links", map[string]any{"task_ids": []string{docs.ID}}, nil)
+	var m MilestoneResponse
+	if err := json.Unmarshal(data, &m); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("link milestone: %d %s", res.StatusCode, string(data))
+	}
+	if len(m.IterationIDs) != 1 || len(m.TaskIDs) != 1 || m.TaskIDs[0] != docs.ID {
+		t.Fatalf("unexpected links %+v", m)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/milestones/m-q3/status", nil, nil)
+	var p MilestoneProgressResponse
+	if err := json.Unmarshal(data, &p); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("milestone status: %d %s", res.StatusCode, string(data))
+	}
+	if p.TotalTasks != 3 || p.DoneTasks != 0 || p.Iterations != 1 || p.Overdue {
+		t.Fatalf("unexpected progress %+v", p)
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/milestones", nil, nil)
+	var items []MilestoneResponse
+	if err := json.Unmarshal(data, &items); err != nil || res.StatusCode != http.StatusOK || len(items) != 1 {
+		t.Fatalf("list milestones: %d %s", res.StatusCode, string(data))
+	}
+	res, _ = doJSON(t, client, http.MethodGet, base+"/milestones/nope/status", nil, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown milestone, got %d", res.StatusCode)
+	}
+}
+
+func TestChangelogEndpoint(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "GA"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Ship it", "type": "technical", "iteration_id": "iter-1"}, nil)
+	var task TaskResponse
+	if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &task) != nil {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID+"?force=true", map[string]any{
+		"status":        "done",
+		"work_outcomes": map[string]any{"summary": "Shipped", "pr_url": "https://git.example.com/pr/7"},
+	}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("complete: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/iterations/iter-1/changelog", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/markdown") {
+		t.Fatalf("changelog: %d %s", res.StatusCode, string(data))
+	}
+	if want := "- Ship it (`" + task.ID + "`): Shipped <https://git.example.com/pr/7>"; !strings.Contains(string(data), "## technical") || !strings.Contains(string(data), want) {
+		t.Fatalf("unexpected changelog:\n%s", string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/iterations/iter-1/changelog?format=json", nil, nil)
+	var c domain.Changelog
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &c) != nil || len(c.Sections) != 1 || c.Sections[0].Entries[0].Links[0] != "https://git.example.com/pr/7" {
+		t.Fatalf("changelog json: %d %s", res.StatusCode, string(data))
+	}
+	if res, _ := doJSON(t, client, http.MethodGet, base+"/iterations/nope/changelog", nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown iteration, got %d", res.StatusCode)
+	}
+}
+
+func TestReleaseEndpoints(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "GA"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Ship", "type": "technical", "iteration_id": "iter-1", "priority": 1}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	release := map[string]any{"name": "wl", "version": "1.0.0", "checksum": "sha256:9f86d081", "url": "https://example.com/wl-1.0.0.tgz"}
+	res, data = doJSON(t, client, http.MethodPost, base+"/iterations/iter-1/releases", release, nil)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 before validation, got %d %s", res.StatusCode, string(data))
+	}
+
+	for _, status := range []string{"running", "delivered"} {
+		res, data = doJSON(t, client, http.MethodPatch, base+"/iterations/iter-1/status", map[string]any{"status": status}, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("set %s: %d %s", status, res.StatusCode, string(data))
+		}
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/attestations", map[string]any{"entity_kind": "iteration", "entity_id": "iter-1", "kind": "iteration.approved"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("attest iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPatch, base+"/iterations/iter-1/status", map[string]any{"status": "validated"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("set validated: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/iterations/iter-1/releases", map[string]any{"name": "wl", "version": "1.0.0", "checksum": "9f86d081"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad checksum, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/iterations/iter-1/releases", release, nil)
+	var rel ReleaseResponse
+	if err := json.Unmarshal(data, &rel); err != nil || res.StatusCode != http.StatusCreated {
+		t.Fatalf("create release: %d %s", res.StatusCode, string(data))
+	}
+	if rel.IterationID != "iter-1" || rel.CreatedBy != "tester" || rel.Checksum != "sha256:9f86d081" {
+		t.Fatalf("unexpected release %+v", rel)
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/iterations/iter-1/releases", release, nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate release, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/releases?iteration=iter-1", nil, nil)
+	var items []ReleaseResponse
+	if err := json.Unmarshal(data, &items); err != nil || res.StatusCode != http.StatusOK || len(items) != 1 || items[0].ID != rel.ID {
+		t.Fatalf("list releases: %d %s", res.StatusCode, string(data))
+	}
+	res, _ = doJSON(t, client, http.MethodGet, base+"/releases?iteration=nope", nil, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown iteration, got %d", res.StatusCode)
+	}
+}
+
+func TestCalendarFeed(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "Beta, part 1"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-2", "goal": "Not started"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Signup", "type": "technical", "iteration_id": "iter-1", "priority": 1}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPatch, base+"/iterations/iter-1/status", map[string]any{"status": "running"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("start iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/milestones", map[string]any{"id": "m-q3", "goal": "Public beta", "target_date": "2099-09-30"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create milestone: %d %s", res.StatusCode, string(data))
+	}
+
+	get := func(url string) (*http.Response, string) {
+		t.Helper()
+		res, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res, string(body)
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/calendar/feeds", map[string]any{"name": "team"}, nil)
+	var feed CalendarFeedResponse
+	if err := json.Unmarshal(data, &feed); err != nil || res.StatusCode != http.StatusCreated || feed.Token == "" || feed.ActorID != "tester" {
+		t.Fatalf("create feed: %d %s", res.StatusCode, string(data))
+	}
+	res, body := get(base + "/calendar.ics?token=" + feed.Token)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/calendar") {
+		t.Fatalf("calendar: %d %s %s", res.StatusCode, res.Header.Get("Content-Type"), body)
+	}
+	today := time.Now().UTC().Format("20060102")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:iteration-iter-1@workline.workline\r\n",
+		"DTSTART;VALUE=DATE:" + today + "\r\n",
+		`SUMMARY:Iteration iter-1: Beta\, part 1`,
+		"DESCRIPTION:Status: running (ongoing)\r\n",
+		"UID:milestone-m-q3@workline.workline\r\n",
+		"DTSTART;VALUE=DATE:20990930\r\nDTEND;VALUE=DATE:20991001\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("calendar missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "iter-2") {
+		t.Fatalf("pending iteration should not be listed:\n%s", body)
+	}
+
+	if res, _ := get(base + "/calendar.ics?token=nope"); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad token, got %d", res.StatusCode)
+	}
+	if res, _ := get(base + "/calendar.ics"); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", res.StatusCode)
+	}
+	if res, _ := get(base + "/calendar.ics?token=" + srv.apiKey); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("API keys must not be accepted in the query string, got %d", res.StatusCode)
+	}
+	if res, _ := get(base + "/tasks?token=" + feed.Token); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("token should only authenticate the calendar feed, got %d", res.StatusCode)
+	}
+	if res, _ := doJSON(t, client, http.MethodGet, base+"/tasks", nil, map[string]string{"Authorization": "", "X-Api-Key": feed.Token}); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("feed token should not work as an API key, got %d", res.StatusCode)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/calendar/feeds", nil, nil)
+	var feeds []CalendarFeedResponse
+	if err := json.Unmarshal(data, &feeds); err != nil || res.StatusCode != http.StatusOK || len(feeds) != 1 || feeds[0].Token != "" {
+		t.Fatalf("list feeds: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodDelete, base+"/calendar/feeds/"+feed.ID, nil, nil)
+	if res.StatusCode/100 != 2 {
+		t.Fatalf("revoke feed: %d %s", res.StatusCode, string(data))
+	}
+	if res, _ := get(base + "/calendar.ics?token=" + feed.Token); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a revoked feed, got %d", res.StatusCode)
+	}
+}
+
+func TestProjectReportMarkdown(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/iterations", map[string]any{"id": "iter-1", "goal": "Beta | launch"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create iteration: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Ship *login*", "type": "technical", "iteration_id": "iter-1"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var shipped TaskResponse
+	_ = json.Unmarshal(data, &shipped)
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+shipped.ID+"?force=true", map[string]any{"status": "done"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("done: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/report?format=md", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/markdown") {
+		t.Fatalf("report: %d %s %s", res.StatusCode, res.Header.Get("Content-Type"), string(data))
+	}
+	body := string(data)
+	for _, want := range []string{
+		"# Status report: workline\n",
+		"| iter-1 | Beta \\| launch | pending | 1/1 |\n",
+		"- Ship \\*login\\* (`" + shipped.ID + "`, iter-1, ",
+		"no attestations\n",
+		"## Blockers\n\nNone.\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("report missing %q:\n%s", want, body)
+		}
+	}
+	res, _ = doJSON(t, client, http.MethodGet, base+"/report?format=pdf", nil, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %d", res.StatusCode)
+	}
+}
+
+func TestBoardWIPLimits(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Config.Project.Board = config.BoardConfig{WIPLimits: map[string]int{"in_progress": 1}, StrictWIP: true}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	var ids []string
+	for _, title := range []string{"first", "second"} {
+		res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": title, "type": "technical"}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s: %d %s", title, res.StatusCode, string(data))
+		}
+		var task TaskResponse
+		_ = json.Unmarshal(data, &task)
+		ids = append(ids, task.ID)
+		res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/claim", map[string]any{}, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("claim %s: %d %s", title, res.StatusCode, string(data))
+		}
+	}
+	res, data := doJSON(t, client, http.MethodPatch, base+"/tasks/"+ids[0], map[string]any{"status": "in_progress"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("first to in_progress: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+ids[1], map[string]any{"status": "in_progress"}, nil)
+	if res.StatusCode != http.StatusConflict || !strings.Contains(string(data), "wip_exceeded") {
+		t.Fatalf("expected wip_exceeded conflict, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/board", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("board: %d %s", res.StatusCode, string(data))
+	}
+	var board BoardResponse
+	if err := json.Unmarshal(data, &board); err != nil {
+		t.Fatalf("decode board: %v", err)
+	}
+	if board.ProjectID != "workline" || !board.StrictWIP {
+		t.Fatalf("board: %s", string(data))
+	}
+	counts := map[string]int{}
+	for _, col := range board.Columns {
+		counts[col.Status] = col.Count
+		if col.Status == "in_progress" && (col.WIPLimit == nil || *col.WIPLimit != 1 || col.OverLimit || len(col.Tasks) != 1 || col.Tasks[0].ID != ids[0]) {
+			t.Fatalf("in_progress column: %+v", col)
+		}
+	}
+	if counts["planned"] != 1 || counts["in_progress"] != 1 {
+		t.Fatalf("column counts: %v", counts)
+	}
+}
+
+func TestDashboardUI(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	res, err := client.Get(srv.URL + "/ui")
+	if err != nil {
+		t.Fatalf("get /ui: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMovedPermanently || res.Header.Get("Location") != "/ui/" {
+		t.Fatalf("expected redirect to /ui/, got %d %q", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ui/", nil)
+	req.Header.Set("X-Forwarded-Prefix", "/workline")
+	res, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("get index: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("index: %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), `<meta name="workline-api" content="/workline/v0"/>`) {
+		t.Fatalf("index does not point at the API behind the prefix:\n%s", body)
+	}
+
+	res, err = client.Get(srv.URL + "/ui/app.js")
+	if err != nil {
+		t.Fatalf("get app.js: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.Contains(res.Header.Get("Content-Type"), "javascript") || !strings.Contains(string(body), "/auth/dev/login") {
+		t.Fatalf("app.js: %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	disabled, cleanupDisabled := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) { c.DisableUI = true })
+	defer cleanupDisabled()
+	res, err = client.Get(disabled.URL + "/ui/")
+	if err != nil {
+		t.Fatalf("get disabled ui: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 with the UI disabled, got %d", res.StatusCode)
+	}
+}
+
+func TestGRPCService(t *testing.T) {
+	var cfg Config
+	_, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) { cfg = *c })
+	defer cleanup()
+	defer func(poll time.Duration) { grpcEventPoll = poll }(grpcEventPoll)
+	grpcEventPoll = 10 * time.Millisecond
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPC(cfg)
+	go srv.Serve(lis)
+	defer srv.Shutdown(context.Background())
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := worklinev1.NewWorklineClient(conn)
+
+	if _, err := client.GetTask(context.Background(), &worklinev1.GetTaskRequest{Id: "missing"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without credentials, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key"), 10*time.Second)
+	defer cancel()
+
+	watch, err := client.WatchEvents(ctx, &worklinev1.WatchEventsRequest{ProjectId: "workline", AfterId: proto.Int64(0), Types: []string{"task.created", "lease.claimed"}})
+	if err != nil {
+		t.Fatalf("watch events: %v", err)
+	}
+	task, err := client.CreateTask(ctx, &worklinev1.CreateTaskRequest{ProjectId: "workline", Title: "Over gRPC", Type: "feature", WorkOutcomes: jsonStruct(map[string]any{"note": "draft"})})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if task.GetStatus() == "" || task.GetWorkOutcomes().GetFields()["note"].GetStringValue() != "draft" {
+		t.Fatalf("unexpected task %+v", task)
+	}
+	lease, err := client.ClaimTask(ctx, &worklinev1.ClaimTaskRequest{ProjectId: "workline", Id: task.GetId()})
+	if err != nil {
+		t.Fatalf("claim task: %v", err)
+	}
+	if lease.GetOwnerId() != "tester" {
+		t.Fatalf("unexpected lease %+v", lease)
+	}
+	for _, want := range []string{"task.created", "lease.claimed"} {
+		ev, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("recv event: %v", err)
+		}
+		if ev.GetType() != want || ev.GetEntityId() != task.GetId() {
+			t.Fatalf("expected %s for %s, got %+v", want, task.GetId(), ev)
+		}
+	}
+
+	validation, err := client.GetTaskValidation(ctx, &worklinev1.GetTaskValidationRequest{ProjectId: "workline", Id: task.GetId()})
+	if err != nil {
+		t.Fatalf("validation: %v", err)
+	}
+	if validation.GetSatisfied() || len(validation.GetMissing()) == 0 {
+		t.Fatalf("expected pending validation, got %+v", validation)
+	}
+	_, err = client.CompleteTask(ctx, &worklinev1.CompleteTaskRequest{ProjectId: "workline", Id: task.GetId(), WorkOutcomes: jsonStruct(map[string]any{"note": "done"})})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition before attestations, got %v", err)
+	}
+	if info := errorInfo(err); info == nil || info.GetReason() != "validation_failed" {
+		t.Fatalf("expected validation_failed reason, got %+v", info)
+	}
+	att, err := client.AddAttestation(ctx, &worklinev1.AddAttestationRequest{ProjectId: "workline", EntityKind: "task", EntityId: task.GetId(), Kind: "ci.passed", Payload: jsonStruct(map[string]any{"run": "42"})})
+	if err != nil {
+		t.Fatalf("attest: %v", err)
+	}
+	atts, err := client.ListAttestations(ctx, &worklinev1.ListAttestationsRequest{ProjectId: "workline", EntityId: task.GetId()})
+	if err != nil {
+		t.Fatalf("list attestations: %v", err)
+	}
+	if len(atts.GetAttestations()) != 1 || atts.GetAttestations()[0].GetId() != att.GetId() || att.GetPayload().GetFields()["run"].GetStringValue() != "42" {
+		t.Fatalf("unexpected attestations %+v", atts.GetAttestations())
+	}
+	done, err := client.CompleteTask(ctx, &worklinev1.CompleteTaskRequest{ProjectId: "workline", Id: task.GetId(), WorkOutcomes: jsonStruct(map[string]any{"note": "done"}), Force: true})
+	if err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	if done.GetCompletedAt() == "" {
+		t.Fatalf("expected completed task, got %+v", done)
+	}
+
+	list, err := client.ListTasks(ctx, &worklinev1.ListTasksRequest{ProjectId: "workline", Statuses: []string{done.GetStatus()}})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(list.GetTasks()) != 1 || list.GetTasks()[0].GetId() != task.GetId() {
+		t.Fatalf("unexpected list %+v", list.GetTasks())
+	}
+	_, err = client.GetTask(ctx, &worklinev1.GetTaskRequest{ProjectId: "workline", Id: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+}
+
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestNDJSONExport(t *testing.T) {
+	// Timestamps have second resolution; tick a minute per call so the
+	// updated_at order is deterministic.
+	clock := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Now = func() time.Time {
+			clock = clock.Add(time.Minute)
+			return clock
+		}
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	var ids []string
+	for _, title := range []string{"First", "Second", "Third"} {
+		res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": title, "type": "docs"}, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+		}
+		var task TaskResponse
+		_ = json.Unmarshal(data, &task)
+		ids = append(ids, task.ID)
+	}
+	res, data := doJSON(t, client, http.MethodPatch, base+"/tasks/"+ids[0], map[string]any{"priority": 1}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("update task: %d %s", res.StatusCode, string(data))
+	}
+
+	readLines := func(url string) []json.RawMessage {
+		t.Helper()
+		res, data := doJSON(t, client, http.MethodGet, url, nil, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("export %s: %d %s", url, res.StatusCode, string(data))
+		}
+		if res.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("unexpected content type %q", res.Header.Get("Content-Type"))
+		}
+		var lines []json.RawMessage
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			if line != "" {
+				lines = append(lines, json.RawMessage(line))
+			}
+		}
+		return lines
+	}
+
+	lines := readLines(base + "/export/tasks.ndjson")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(lines))
+	}
+	var last TaskResponse
+	_ = json.Unmarshal(lines[2], &last)
+	if last.ID != ids[0] {
+		t.Fatalf("expected the updated task last, got %s", last.ID)
+	}
+	var second TaskResponse
+	_ = json.Unmarshal(lines[1], &second)
+	rest := readLines(base + "/export/tasks.ndjson?cursor=" + second.UpdatedAt + "|" + second.ID)
+	if len(rest) != 1 || !strings.Contains(string(rest[0]), ids[0]) {
+		t.Fatalf("expected resume after the cursor to return only the last task, got %s", rest)
+	}
+
+	created := readLines(base + "/export/events.ndjson?type=task.created&limit=2")
+	if len(created) != 2 {
+		t.Fatalf("expected limit to stop after 2 events, got %d", len(created))
+	}
+	var ev EventResponse
+	_ = json.Unmarshal(created[1], &ev)
+	remaining := readLines(base + "/export/events.ndjson?type=task.created&cursor=" + strconv.FormatInt(ev.ID, 10))
+	if len(remaining) != 1 || !strings.Contains(string(remaining[0]), ids[2]) {
+		t.Fatalf("expected the third task.created after the cursor, got %s", remaining)
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/export/tasks.ndjson?cursor=bogus", nil, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad cursor, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestEphemeralServerFromFixture(t *testing.T) {
+	fx, err := app.LoadFixture("../../examples/demo-fixture.yml")
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	e, err := app.OpenEphemeral(context.Background(), fx, "tester")
+	if err != nil {
+		t.Fatalf("open ephemeral: %v", err)
+	}
+	t.Cleanup(func() { _ = e.DB.Close() })
+	handler, err := New(Config{Engine: e, BasePath: "/v0", Auth: AuthConfig{JWTSecret: "test-secret"}})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := srv.Client()
+	agent := map[string]string{"X-Api-Key": "demo-agent-key"}
+
+	res, data := doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/demo/tasks", nil, agent)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("list tasks: %d %s", res.StatusCode, string(data))
+	}
+	var list struct {
+		Items []TaskResponse `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("decode tasks: %v", err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatalf("expected the 3 fixture tasks, got %d", len(list.Items))
+	}
+	for _, task := range list.Items {
+		if task.ID == "task-crash" && task.Status != "ready" {
+			t.Fatalf("expected the fixture status to be forced, got %s", task.Status)
+		}
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/demo/tasks/task-crash/claim", map[string]any{}, agent)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("claim fixture task: %d %s", res.StatusCode, string(data))
+	}
+
+	bad := t.TempDir() + "/bad.yml"
+	if err := os.WriteFile(bad, []byte("project: demo\nprojcet: typo\n"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if _, err := app.LoadFixture(bad); err == nil {
+		t.Fatalf("expected unknown fixture keys to be rejected")
+	}
+}
+
+func TestSeedWorkspaceIsReproducible(t *testing.T) {
+	s, err := app.LoadSeed("../../examples/seed.yml")
+	if err != nil {
+		t.Fatalf("load seed: %v", err)
+	}
+	seedIDs := func() []string {
+		r, err := repo.NewMemorySQLite()
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		defer r.DB.Close()
+		ctx := context.Background()
+		if _, err := app.SeedWorkspace(ctx, r.DB, s, "tester"); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		tasks, err := r.ListTasks(ctx, repo.TaskFilters{ProjectID: "web"})
+		if err != nil {
+			t.Fatalf("list tasks: %v", err)
+		}
+		atts, err := r.ListAttestations(ctx, repo.AttestationFilters{ProjectID: "web"})
+		if err != nil {
+			t.Fatalf("list attestations: %v", err)
+		}
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		for _, att := range atts {
+			ids = append(ids, att.ID)
+		}
+		if _, err := app.SeedWorkspace(ctx, r.DB, s, "tester"); err == nil {
+			t.Fatalf("expected seeding an existing project to fail")
+		}
+		return ids
+	}
+	first, second := seedIDs(), seedIDs()
+	if len(first) != 4 {
+		t.Fatalf("expected 2 tasks and 2 attestations, got %v", first)
+	}
+	slices.Sort(first)
+	slices.Sort(second)
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected the same IDs on every seed, got %v and %v", first, second)
+	}
+}
+
+func TestSimulatePolicy(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/policies/simulate", map[string]any{"type": "feature", "attestations": []string{"ci.passed"}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("simulate: %d %s", res.StatusCode, string(data))
+	}
+	var sim PolicyCheckResponse
+	_ = json.Unmarshal(data, &sim)
+	if sim.Policy == "" || !slices.Contains(sim.Present, "ci.passed") || len(sim.Missing) == 0 || sim.Satisfied {
+		t.Fatalf("unexpected simulation %s", string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Real", "type": "feature"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	if !slices.Equal(task.RequiredAttestations, sim.Required) {
+		t.Fatalf("expected the simulated requirements %v, task got %v", sim.Required, task.RequiredAttestations)
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/policies/simulate", map[string]any{"type": "feature", "policy": "nope"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown policy, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/policies/simulate", map[string]any{"type": "feature"}, map[string]string{"Authorization": "Bearer " + srv.bearerToken(t, "stranger", "", time.Now().Add(time.Hour))})
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 without task.validation.read, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestVerifyProject(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Rushed", "type": "feature"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID+"?force=true", map[string]any{"status": "done"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("force done: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/verify?flag=true", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("verify: %d %s", res.StatusCode, string(data))
+	}
+	var report ComplianceReportResponse
+	_ = json.Unmarshal(data, &report)
+	if report.Checked != 1 || !report.Flagged || len(report.NonCompliant) != 1 {
+		t.Fatalf("unexpected report %s", string(data))
+	}
+	if issue := report.NonCompliant[0]; issue.TaskID != task.ID || !issue.Forced || !slices.Equal(issue.Missing, task.RequiredAttestations) {
+		t.Fatalf("expected the forced task with all its requirements missing, got %+v", issue)
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/events?type=compliance.flagged", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), task.ID) {
+		t.Fatalf("expected a compliance.flagged event: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestForceApprovalFlow(t *testing.T) {
+	var eng engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		c.Engine.Config.Project.Force.RequireApproval = true
+		eng = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "hotfix", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+	res, data = doJSON(t, client, http.MethodPatch, base+"/tasks/"+task.ID+"?force=true", map[string]any{"status": "done"}, nil)
+	var apiErr struct {
+		Error apiErrorBody `json:"error"`
+	}
+	_ = json.Unmarshal(data, &apiErr)
+	if res.StatusCode != http.StatusConflict || apiErr.Error.Code != "force_pending" {
+		t.Fatalf("expected force_pending, got %d %s", res.StatusCode, string(data))
+	}
+	requestID, _ := apiErr.Error.Details["request_id"].(string)
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/force-requests/"+requestID+"/approve", nil, nil)
+	if res.StatusCode != http.StatusForbidden || !strings.Contains(string(data), "self_approval") {
+		t.Fatalf("expected the requester to be refused, got %d %s", res.StatusCode, string(data))
+	}
+	if err := eng.GrantRole(context.Background(), "workline", "tester", "lead", "owner"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	lead := bearerHeader(srv.bearerToken(t, "lead", "default-org", time.Now().Add(time.Hour)))
+	res, data = doJSON(t, client, http.MethodGet, base+"/force-requests?status=pending", nil, lead)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), requestID) {
+		t.Fatalf("list: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/force-requests/"+requestID+"/approve", nil, lead)
+	var fr ForceRequestResponse
+	_ = json.Unmarshal(data, &fr)
+	if res.StatusCode != http.StatusOK || fr.Status != "approved" || fr.Operation != "task.update" {
+		t.Fatalf("approve: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/"+task.ID, nil, nil)
+	_ = json.Unmarshal(data, &task)
+	if res.StatusCode != http.StatusOK || task.Status != "done" {
+		t.Fatalf("expected the approved update to complete the task: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestAuditEventsNeedAuditRead(t *testing.T) {
+	var eng engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		eng = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+	if err := eng.GrantRole(context.Background(), "workline", "tester", "dana", "dev"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "visible", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d %s", res.StatusCode, string(data))
+	}
+	kinds := func(headers map[string]string) map[string]bool {
+		t.Helper()
+		res, data := doJSON(t, client, http.MethodGet, base+"/events?limit=200", nil, headers)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("events: %d %s", res.StatusCode, string(data))
+		}
+		var page paginatedEvents
+		_ = json.Unmarshal(data, &page)
+		seen := map[string]bool{}
+		for _, evt := range page.Items {
+			seen[evt.EntityKind] = true
+		}
+		return seen
+	}
+	if got := kinds(nil); !got["rbac"] || !got["task"] {
+		t.Fatalf("owner should see audit and task events: %v", got)
+	}
+	dana := bearerHeader(srv.bearerToken(t, "dana", "default-org", time.Now().Add(time.Hour)))
+	if got := kinds(dana); got["rbac"] || !got["task"] {
+		t.Fatalf("dev should see task events only: %v", got)
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/events?entity_kind=rbac", nil, dana)
+	assertForbiddenPermission(t, res, data, "events.audit.read")
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/export/events.ndjson", nil, dana)
+	if res.StatusCode != http.StatusOK || strings.Contains(string(data), `"entity_kind":"rbac"`) || !strings.Contains(string(data), "task.created") {
+		t.Fatalf("export should skip audit events: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "poll me", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d %s", res.StatusCode, string(data))
+	}
+	var task TaskResponse
+	_ = json.Unmarshal(data, &task)
+
+	revalidate := func(url string) string {
+		t.Helper()
+		res, data := doJSON(t, client, http.MethodGet, url, nil, nil)
+		etag := res.Header.Get("ETag")
+		if res.StatusCode != http.StatusOK || etag == "" {
+			t.Fatalf("%s: expected 200 with an ETag, got %d %q %s", url, res.StatusCode, etag, string(data))
+		}
+		res, data = doJSON(t, client, http.MethodGet, url, nil, map[string]string{"If-None-Match": etag})
+		if res.StatusCode != http.StatusNotModified || len(data) != 0 || res.Header.Get("ETag") != etag {
+			t.Fatalf("%s: expected 304 with the same ETag, got %d %q %s", url, res.StatusCode, res.Header.Get("ETag"), string(data))
+		}
+		return etag
+	}
+	taskETag := revalidate(base + "/tasks/" + task.ID)
+	listETag := revalidate(base + "/tasks?status=planned")
+	revalidate(base + "/config")
+	revalidate(base + "/iterations")
+	if listETag == revalidate(base+"/tasks") {
+		t.Fatalf("different queries should not share an ETag")
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks/"+task.ID+"/claim", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("claim: %d %s", res.StatusCode, string(data))
+	}
+	for url, etag := range map[string]string{base + "/tasks/" + task.ID: taskETag, base + "/tasks?status=planned": listETag} {
+		res, data = doJSON(t, client, http.MethodGet, url, nil, map[string]string{"If-None-Match": etag})
+		if res.StatusCode != http.StatusOK || res.Header.Get("ETag") == etag {
+			t.Fatalf("%s: expected a fresh representation after a change, got %d %s", url, res.StatusCode, string(data))
+		}
+	}
+}
+
+func TestListTasksIncludeDependencies(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	for _, body := range []map[string]any{
+		{"id": "dep-a", "title": "A", "type": "technical"},
+		{"id": "dep-b", "title": "B", "type": "technical"},
+		{"id": "dep-c", "title": "C", "type": "technical", "depends_on": []string{"dep-a", "dep-b"}},
+	} {
+		res, data := doJSON(t, client, http.MethodPost, base+"/tasks", body, nil)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s: %d %s", body["id"], res.StatusCode, string(data))
+		}
+	}
+	want := map[string][]string{"dep-a": {}, "dep-b": {}, "dep-c": {"dep-a", "dep-b"}}
+	checkDeps := func(endpoint string, tasks []TaskResponse) {
+		t.Helper()
+		if len(tasks) != len(want) {
+			t.Fatalf("%s: expected %d tasks, got %d", endpoint, len(want), len(tasks))
+		}
+		for _, task := range tasks {
+			deps := slices.Clone(task.DependsOn)
+			slices.Sort(deps)
+			if !slices.Equal(deps, want[task.ID]) {
+				t.Fatalf("%s: %s depends on %v", endpoint, task.ID, deps)
+			}
+		}
+	}
+
+	res, data := doJSON(t, client, http.MethodGet, base+"/tasks", nil, nil)
+	var page paginatedTasks
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &page) != nil {
+		t.Fatalf("list: %d %s", res.StatusCode, string(data))
+	}
+	checkDeps("list", page.Items)
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks/tree", nil, nil)
+	var tree []struct {
+		Task TaskResponse `json:"task"`
+	}
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &tree) != nil {
+		t.Fatalf("tree: %d %s", res.StatusCode, string(data))
+	}
+	var roots []TaskResponse
+	for _, node := range tree {
+		roots = append(roots, node.Task)
+	}
+	checkDeps("tree", roots)
+}
+
+func TestChangesLongPoll(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodGet, base+"/changes", nil, nil)
+	var start ChangesResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &start) != nil || len(start.Changes) != 0 {
+		t.Fatalf("changes without since: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, fmt.Sprintf("%s/changes?since=%d", base, start.Cursor), nil, nil)
+	var idle ChangesResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &idle) != nil || len(idle.Changes) != 0 || idle.Cursor != start.Cursor {
+		t.Fatalf("changes without wait: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/changes?since=0&wait=soon", nil, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid wait: %d %s", res.StatusCode, string(data))
+	}
+
+	type result struct {
+		status  int
+		body    []byte
+		elapsed time.Duration
+	}
+	done := make(chan result, 1)
+	go func() {
+		began := time.Now()
+		res, data := doJSON(t, client, http.MethodGet, fmt.Sprintf("%s/changes?since=%d&wait=10s&entity_kind=task", base, start.Cursor), nil, nil)
+		done <- result{res.StatusCode, data, time.Since(began)}
+	}()
+	time.Sleep(200 * time.Millisecond)
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"id": "changed-1", "title": "Wake up", "type": "technical"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: %d %s", res.StatusCode, string(data))
+	}
+	got := <-done
+	var changes ChangesResponse
+	if got.status != http.StatusOK || json.Unmarshal(got.body, &changes) != nil {
+		t.Fatalf("long poll: %d %s", got.status, string(got.body))
+	}
+	if got.elapsed >= 5*time.Second {
+		t.Fatalf("long poll waited %s after a change", got.elapsed)
+	}
+	if len(changes.Changes) != 1 || changes.Changes[0].EntityID != "changed-1" || changes.Changes[0].EntityKind != "task" || changes.Cursor <= start.Cursor {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestWebhookDeliveriesAndReplay(t *testing.T) {
+	var mu sync.Mutex
+	var signatures []string
+	fail := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-Workline-Signature") != engine.SignWebhook("hook-secret", body) {
+			t.Errorf("bad signature %q", r.Header.Get("X-Workline-Signature"))
+		}
+		signatures = append(signatures, r.Header.Get("X-Workline-Signature"))
+		if fail {
+			fail = false
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+	var e engine.Engine
+	disabled := false
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		// Disabled, so the background dispatcher leaves the receiver alone.
+		c.Engine.Config.Webhooks = []config.WebhookConfig{{URL: receiver.URL, Secret: "hook-secret", Enabled: &disabled}}
+		e = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline/webhooks/deliveries"
+	ctx := context.Background()
+
+	first, err := e.TestWebhook(ctx, "workline", receiver.URL, "", "tester")
+	if err != nil {
+		t.Fatalf("test webhook: %v", err)
+	}
+	if first.StatusCode != http.StatusServiceUnavailable || !strings.Contains(first.Error, "down for maintenance") || first.EventType != engine.WebhookTestEvent {
+		t.Fatalf("expected a failed test delivery, got %+v", first)
+	}
+	if _, err := e.TestWebhook(ctx, "workline", receiver.URL, "", "outsider"); err == nil {
+		t.Fatalf("expected webhook.manage to be required")
+	}
+
+	res, data := doJSON(t, client, http.MethodGet, base+"?failed=true", nil, nil)
+	var failed []WebhookDeliveryResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &failed) != nil || len(failed) != 1 || failed[0].ID != first.ID {
+		t.Fatalf("list failed deliveries: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base+"/"+first.ID+"/replay", map[string]any{}, nil)
+	var replay WebhookDeliveryResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &replay) != nil {
+		t.Fatalf("replay: %d %s", res.StatusCode, string(data))
+	}
+	if replay.StatusCode != http.StatusOK || replay.Error != "" || replay.ReplayOf == nil || *replay.ReplayOf != first.ID {
+		t.Fatalf("expected a successful replay of %s, got %+v", first.ID, replay)
+	}
+	mu.Lock()
+	if len(signatures) != 2 || signatures[0] != signatures[1] {
+		t.Fatalf("expected the replay to resend the same signed body, got %v", signatures)
+	}
+	mu.Unlock()
+
+	res, data = doJSON(t, client, http.MethodGet, base, nil, nil)
+	var all []WebhookDeliveryResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &all) != nil || len(all) != 2 || all[0].ID != replay.ID {
+		t.Fatalf("expected both attempts, newest first: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/missing/replay", map[string]any{}, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 replaying an unknown delivery, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestImportJira(t *testing.T) {
+	ctx := context.Background()
+	e, err := app.OpenEphemeral(ctx, app.Fixture{}, "tester")
+	if err != nil {
+		t.Fatalf("open ephemeral: %v", err)
+	}
+	t.Cleanup(func() { _ = e.DB.Close() })
+	export, err := app.LoadJiraExport("../../examples/jira-export.json")
+	if err != nil {
+		t.Fatalf("load export: %v", err)
+	}
+	opts := app.JiraImportOptions{ProjectID: "demo", ActorID: "tester"}
+	importJira := func(opts app.JiraImportOptions) app.JiraImportReport {
+		t.Helper()
+		report, err := app.ImportJira(ctx, e, export, opts)
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+		return report
+	}
+
+	dry := importJira(app.JiraImportOptions{ProjectID: "demo", ActorID: "tester", DryRun: true})
+	if dry.Created != 6 || dry.Dependencies != 1 || len(dry.Sprints) != 2 {
+		t.Fatalf("dry run: %+v", dry)
+	}
+	if tasks, _ := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: "demo"}); len(tasks) != 0 {
+		t.Fatalf("expected a dry run to import nothing, got %d tasks", len(tasks))
+	}
+
+	report := importJira(opts)
+	if report.Created != 6 || report.Dependencies != 1 || len(report.Warnings) != 2 {
+		t.Fatalf("import: %+v", report)
+	}
+	ids := map[string]string{}
+	for _, m := range append(report.Issues, report.Sprints...) {
+		if _, dup := ids[m.Key]; dup {
+			t.Fatalf("duplicate key %s", m.Key)
+		}
+		ids[m.Key] = m.EntityID
+	}
+	for _, m := range dry.Issues {
+		if ids[m.Key] != m.EntityID {
+			t.Fatalf("expected the dry run to report the ids the import uses: %s %s != %s", m.Key, m.EntityID, ids[m.Key])
+		}
+	}
+	task := func(key string) domain.Task {
+		t.Helper()
+		task, err := e.Repo.GetTask(ctx, ids[key])
+		if err != nil {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		return task
+	}
+	if bug := task("ACME-3"); bug.Type != "bug" || bug.Status != "review" || deref(bug.ParentID) != ids["ACME-1"] ||
+		deref(bug.IterationID) != ids["Sprint 8"] || len(bug.DependsOn) != 1 || bug.DependsOn[0] != ids["ACME-4"] || bug.Priority == nil || *bug.Priority != 1 {
+		t.Fatalf("ACME-3: %+v", bug)
+	}
+	if story := task("ACME-2"); story.Status != "done" || deref(story.AssigneeID) != "ana@example.com" || deref(story.IterationID) != ids["Sprint 7"] {
+		t.Fatalf("ACME-2: %+v", story)
+	}
+	if sdk := task("ACME-4"); sdk.Description != "Move to v5 before the rounding fix.\n- read the changelog" {
+		t.Fatalf("expected the Atlassian document flattened, got %q", sdk.Description)
+	}
+	if shim := task("ACME-5"); shim.Status != "canceled" || deref(shim.ParentID) != ids["ACME-4"] {
+		t.Fatalf("ACME-5: %+v", shim)
+	}
+	if it, err := e.Repo.GetIteration(ctx, ids["Sprint 7"]); err != nil || it.Status != "delivered" || it.Goal != "Sprint 7: Payments beta" {
+		t.Fatalf("sprint 7: %+v %v", it, err)
+	}
+
+	again := importJira(opts)
+	if again.Created != 0 || again.Updated != 0 || again.Unchanged != 6 || again.Dependencies != 0 {
+		t.Fatalf("expected a re-import to change nothing: %+v", again)
+	}
+	export.Issues[3].Fields.Status.Name = "In Progress"
+	export.Issues[3].Fields.Status.StatusCategory.Key = "indeterminate"
+	moved := importJira(opts)
+	if moved.Created != 0 || moved.Updated != 1 || task("ACME-4").Status != "in_progress" {
+		t.Fatalf("expected the status change to be synced: %+v", moved)
+	}
+	if tasks, _ := e.Repo.ListTasks(ctx, repo.TaskFilters{ProjectID: "demo"}); len(tasks) != 6 {
+		t.Fatalf("expected re-imports not to duplicate tasks, got %d", len(tasks))
+	}
+}
+
+func TestTaskExternalRefs(t *testing.T) {
+	var e engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		e = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline/tasks"
+
+	res, data := doJSON(t, client, http.MethodPost, base, map[string]any{
+		"title":         "Sync login bug",
+		"type":          "bug",
+		"external_refs": map[string]string{"jira": "ABC-123", "github": "org/repo#45"},
+	}, nil)
+	var created TaskResponse
+	if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &created) != nil || created.ExternalRefs["jira"] != "ABC-123" {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	for _, path := range []string{"/by-external/jira/ABC-123", "/by-external/github/org%2Frepo%2345"} {
+		res, data = doJSON(t, client, http.MethodGet, base+path, nil, nil)
+		var found TaskResponse
+		if res.StatusCode != http.StatusOK || json.Unmarshal(data, &found) != nil || found.ID != created.ID || found.ExternalRefs["github"] != "org/repo#45" {
+			t.Fatalf("lookup %s: %d %s", path, res.StatusCode, string(data))
+		}
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, base, map[string]any{
+		"title":         "Duplicate",
+		"type":          "bug",
+		"external_refs": map[string]string{"jira": "ABC-123"},
+	}, nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 reusing a jira id, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base, map[string]any{
+		"title":         "Bad system",
+		"type":          "bug",
+		"external_refs": map[string]string{"Jira Cloud": "ABC-124"},
+	}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid system, got %d %s", res.StatusCode, string(data))
+	}
+
+	// Linking a new jira id replaces the old one; an empty id unlinks github.
+	res, data = doJSON(t, client, http.MethodPatch, base+"/"+created.ID, map[string]any{
+		"external_refs": map[string]string{"jira": "ABC-200", "github": ""},
+	}, nil)
+	var updated TaskResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &updated) != nil || len(updated.ExternalRefs) != 1 || updated.ExternalRefs["jira"] != "ABC-200" {
+		t.Fatalf("update refs: %d %s", res.StatusCode, string(data))
+	}
+	for _, path := range []string{"/by-external/jira/ABC-123", "/by-external/github/org%2Frepo%2345"} {
+		if res, data = doJSON(t, client, http.MethodGet, base+path, nil, nil); res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404 for unlinked %s, got %d %s", path, res.StatusCode, string(data))
+		}
+	}
+	res, data = doJSON(t, client, http.MethodGet, base, nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), `"external_refs":{"jira":"ABC-200"}`) {
+		t.Fatalf("expected refs in the task list: %d %s", res.StatusCode, string(data))
+	}
+
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+	ctx := context.Background()
+	evts, err := e.Repo.LatestEvents(ctx, 1, "workline", "task.updated", "task", created.ID)
+	if err != nil || len(evts) != 1 {
+		t.Fatalf("latest event: %v %v", evts, err)
+	}
+	if _, err := e.DeliverWebhookEvent(ctx, "workline", config.WebhookConfig{URL: receiver.URL}, evts[0]); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	var delivered struct {
+		ExternalRefs map[string]string `json:"external_refs"`
+	}
+	if err := json.Unmarshal(body, &delivered); err != nil || delivered.ExternalRefs["jira"] != "ABC-200" {
+		t.Fatalf("expected refs in the webhook body: %s", string(body))
+	}
+}
+
+func TestTaskRelationsAndGraph(t *testing.T) {
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, nil)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline/tasks"
+	create := func(title string) TaskResponse {
+		t.Helper()
+		res, data := doJSON(t, client, http.MethodPost, base, map[string]any{"title": title, "type": "bug"}, nil)
+		var created TaskResponse
+		if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &created) != nil {
+			t.Fatalf("create %s: %d %s", title, res.StatusCode, string(data))
+		}
+		return created
+	}
+	original, dup := create("Login fails"), create("Cannot log in")
+
+	res, data := doJSON(t, client, http.MethodPost, base+"/"+dup.ID+"/relations", map[string]any{"type": "duplicates", "related_task_id": original.ID}, nil)
+	var rel TaskRelationResponse
+	if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &rel) != nil || rel.Closed != "canceled" {
+		t.Fatalf("add relation: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/"+dup.ID+"/relations", map[string]any{"type": "clones", "related_task_id": original.ID}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown type, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/"+dup.ID, nil, nil)
+	var got TaskResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &got) != nil || got.Status != "canceled" || len(got.Relations) != 1 || got.Relations[0].RelatedTaskID != original.ID {
+		t.Fatalf("get duplicate: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/"+original.ID+"/relations", nil, nil)
+	var rels []TaskRelationResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &rels) != nil || len(rels) != 1 || rels[0].TaskID != dup.ID {
+		t.Fatalf("list relations: %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodGet, base+"/graph", nil, nil)
+	var graph TaskGraphResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &graph) != nil || len(graph.Nodes) != 2 || len(graph.Edges) != 1 || graph.Edges[0].Type != "duplicates" {
+		t.Fatalf("graph: %d %s", res.StatusCode, string(data))
+	}
+
+	path := base + "/" + dup.ID + "/relations/duplicates/" + original.ID
+	if res, data = doJSON(t, client, http.MethodDelete, path, nil, nil); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove relation: %d %s", res.StatusCode, string(data))
+	}
+	if res, data = doJSON(t, client, http.MethodDelete, path, nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 removing again, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestScanGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	e, err := app.OpenEphemeral(ctx, app.Fixture{}, "tester")
+	if err != nil {
+		t.Fatalf("open ephemeral: %v", err)
+	}
+	t.Cleanup(func() { _ = e.DB.Close() })
+	task, err := e.CreateTask(ctx, engine.TaskCreateOptions{ProjectID: "demo", Title: "Login", ActorID: "tester"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Dev", "-c", "user.email=dev@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Scaffold")
+	base := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "Add login form\n\nWL-Task: "+task.ID+"\nWL-Task: missing")
+	sha := git("rev-parse", "HEAD")
+
+	opts := app.GitScanOptions{ProjectID: "demo", ActorID: "tester", Repo: dir, Since: base, Attest: true}
+	report, err := app.ScanGit(ctx, e, opts)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if report.Scanned != 1 || len(report.Links) != 2 || report.Links[0].Status != "attached" || !report.Links[0].Attested || report.Links[1].Status != "skipped" || len(report.Warnings) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	got, err := e.Repo.GetTask(ctx, task.ID)
+	if err != nil || got.WorkOutcomesJSON == nil || !strings.Contains(*got.WorkOutcomesJSON, `"sha":"`+sha+`"`) || !strings.Contains(*got.WorkOutcomesJSON, `"subject":"Add login form"`) {
+		t.Fatalf("expected the commit in work outcomes, got %v %v", deref(got.WorkOutcomesJSON), err)
+	}
+	atts, err := e.Repo.ListAttestations(ctx, repo.AttestationFilters{ProjectID: "demo", EntityKind: "task", EntityID: task.ID, Kind: app.CodeCommittedKind})
+	if err != nil || len(atts) != 1 {
+		t.Fatalf("expected one code.committed attestation, got %v %v", atts, err)
+	}
+
+	// The whole history again: the commit is already there.
+	opts.Since = strings.Repeat("0", 40)
+	report, err = app.ScanGit(ctx, e, opts)
+	if err != nil || report.Scanned != 2 || report.Links[0].Status != "already_attached" {
+		t.Fatalf("rescan: %+v %v", report, err)
+	}
+	if atts, _ = e.Repo.ListAttestations(ctx, repo.AttestationFilters{ProjectID: "demo", EntityKind: "task", EntityID: task.ID, Kind: app.CodeCommittedKind}); len(atts) != 1 {
+		t.Fatalf("expected no new attestation on rescan, got %d", len(atts))
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func TestArchivedProject(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	client := srv.Client()
+	base := srv.URL + "/v0/projects/workline"
+
+	res, data := doJSON(t, client, http.MethodPatch, base, map[string]any{"status": "archived"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("archive project: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Too late", "type": "bug"}, nil)
+	var apiErr struct {
+		Error apiErrorBody `json:"error"`
+	}
+	_ = json.Unmarshal(data, &apiErr)
+	if res.StatusCode != http.StatusConflict || apiErr.Error.Code != "project_archived" {
+		t.Fatalf("expected 409 project_archived, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, base+"/tasks", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected reads on an archived project, got %d %s", res.StatusCode, string(data))
+	}
+
+	var projects []ProjectResponse
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects", nil, nil)
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &projects) != nil || len(projects) != 0 {
+		t.Fatalf("expected archived project to be hidden, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects?include_archived=true", nil, nil)
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &projects) != nil || len(projects) != 1 || projects[0].Status != "archived" {
+		t.Fatalf("expected archived project with include_archived, got %d %s", res.StatusCode, string(data))
+	}
+
+	res, data = doJSON(t, client, http.MethodPatch, base, map[string]any{"status": "active"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unarchive project: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, base+"/tasks", map[string]any{"title": "Back", "type": "bug"}, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create task after unarchive: %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestRenameProject(t *testing.T) {
+	var e engine.Engine
+	srv, cleanup := newTestServerWithConfig(t, AuthConfig{JWTSecret: "test-secret"}, func(c *Config) {
+		e = c.Engine
+	})
+	defer cleanup()
+	client := srv.Client()
+
+	res, data := doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/tasks", map[string]any{"title": "Keep me", "type": "bug"}, nil)
+	var task TaskResponse
+	if res.StatusCode != http.StatusCreated || json.Unmarshal(data, &task) != nil {
+		t.Fatalf("create task: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/rename", map[string]any{"id": "workline"}, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 renaming to the same id, got %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/workline/rename", map[string]any{"id": "renamed"}, nil)
+	var p ProjectResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &p) != nil || p.ID != "renamed" {
+		t.Fatalf("rename: %d %s", res.StatusCode, string(data))
+	}
+
+	// The former id still resolves.
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/workline/tasks/"+task.ID, nil, nil)
+	var found TaskResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(data, &found) != nil || found.ProjectID != "renamed" {
+		t.Fatalf("get task through alias: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodGet, srv.URL+"/v0/projects/renamed/events?type=project.renamed", nil, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(data), `"from":"workline"`) {
+		t.Fatalf("expected project.renamed event: %d %s", res.StatusCode, string(data))
+	}
+	report, err := e.VerifyEventChain(context.Background(), engine.EventChainOptions{ProjectID: "renamed", ActorID: "tester"})
+	if err != nil || !report.Valid || report.Checked == 0 {
+		t.Fatalf("expected the chain to verify across the rename, got %+v %v", report, err)
+	}
+
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects", map[string]any{"id": "other", "org_id": "default-org"}, nil)
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		t.Fatalf("create project: %d %s", res.StatusCode, string(data))
+	}
+	res, data = doJSON(t, client, http.MethodPost, srv.URL+"/v0/projects/renamed/rename", map[string]any{"id": "other"}, nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 renaming onto an existing project, got %d %s", res.StatusCode, string(data))
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{engine.ProjectArchivedError{ProjectID: "old", Permission: "task.create"}, http.StatusConflict, "project_archived"},
+		{fmt.Errorf("get task: %w", repo.ErrNotFound), http.StatusNotFound, "not_found"},
+		{errors.New("lease owned by different actor"), http.StatusConflict, "lease_conflict"},
+		{errors.New("lease expired; reacquire"), http.StatusConflict, "lease_conflict"},
+		{errors.New("policy validation failed"), http.StatusUnprocessableEntity, "validation_failed"},
+		{errors.New("disk on fire"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, c := range cases {
+		status, code, message, details := DescribeError(c.err)
+		if status != c.status || code != c.code {
+			t.Fatalf("%v: got %d %s, want %d %s", c.err, status, code, c.status, c.code)
+		}
+		if message != c.err.Error() {
+			t.Fatalf("%v: message %q", c.err, message)
+		}
+		if code == "project_archived" && details["project_id"] != "old" {
+			t.Fatalf("expected project details, got %v", details)
+		}
+	}
+}
+
+// statusErrRepo fails actor status lookups, as a locked or broken database
+// would.
+type statusErrRepo struct {
+	repo.Repository
+	err error
+}
+
+func (r statusErrRepo) ActorStatus(context.Context, string) (string, error) {
+	return "", r.err
+}
+
+func TestActorSuspendedFailsClosed(t *testing.T) {
+	ctx := context.Background()
+	if err := checkActorActive(ctx, statusErrRepo{err: repo.ErrNotFound}, "newcomer"); err != nil {
+		t.Fatalf("unknown actor should be active, got %v", err)
+	}
+	err := checkActorActive(ctx, statusErrRepo{err: errors.New("database is locked")}, "tester")
+	if err == nil || err.GetStatus() != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the status lookup fails, got %v", err)
+	}
+	if _, err := actorSuspended(ctx, statusErrRepo{err: errors.New("database is locked")}, "tester"); err == nil {
+		t.Fatalf("expected lookup error to be returned")
+	}
+
+	svc := &grpcService{
+		engine: engine.Engine{Repo: statusErrRepo{err: errors.New("database is locked")}},
+		auth:   AuthConfig{JWTSecret: "test-secret"},
+	}
+	md := metadata.Pairs("authorization", "Bearer "+signToken(t, "test-secret", "tester", "default-org", time.Now().Add(time.Hour)))
+	if _, err := svc.authenticate(metadata.NewIncomingContext(ctx, md)); status.Code(err) != codes.Internal {
+		t.Fatalf("expected gRPC Internal when the status lookup fails, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"workline/internal/engine"
+)
+
+// LeaseTracker records leases the server claims on an actor's behalf while a
+// request runs, so they can be released if the request is cut off.
+type LeaseTracker struct {
+	mu     sync.Mutex
+	leases map[string]string // task id -> actor id
+}
+
+func NewLeaseTracker() *LeaseTracker {
+	return &LeaseTracker{leases: map[string]string{}}
+}
+
+type leaseTrackerKey struct{}
+
+func leaseTrackerFrom(ctx context.Context) *LeaseTracker {
+	t, _ := ctx.Value(leaseTrackerKey{}).(*LeaseTracker)
+	return t
+}
+
+func (t *LeaseTracker) add(taskID, actorID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.leases[taskID] = actorID
+}
+
+func (t *LeaseTracker) remove(taskID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.leases, taskID)
+}
+
+// Len returns the number of leases still held.
+func (t *LeaseTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.leases)
+}
+
+// ReleaseAll releases every lease still held, typically after shutdown gave
+// up waiting for in-flight requests.
+func (t *LeaseTracker) ReleaseAll(ctx context.Context, e engine.Engine) error {
+	t.mu.Lock()
+	leases := t.leases
+	t.leases = map[string]string{}
+	t.mu.Unlock()
+	var errs []error
+	for taskID, actorID := range leases {
+		if err := e.ReleaseLease(ctx, taskID, actorID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// claimInternalLease takes a short lease for a multi-step update unless the
+// actor already holds one. The returned release func is safe to defer: it
+// runs even when ctx is cancelled and leaves an actor's own lease in place.
+func claimInternalLease(ctx context.Context, e engine.Engine, taskID, actorID string, seconds int) (func(), error) {
+	if lease, err := e.Repo.GetLease(ctx, taskID); err == nil && lease.OwnerID == actorID {
+		if exp, err := time.Parse(time.RFC3339, lease.ExpiresAt); err == nil && time.Now().Before(exp) {
+			return func() {}, nil
+		}
+	}
+	if _, err := e.ClaimLease(ctx, taskID, actorID, seconds); err != nil {
+		return nil, err
+	}
+	tracker := leaseTrackerFrom(ctx)
+	tracker.add(taskID, actorID)
+	return func() {
+		if err := e.ReleaseLease(context.WithoutCancel(ctx), taskID, actorID); err == nil {
+			tracker.remove(taskID)
+		}
+	}, nil
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"workline/internal/engine"
+)
+
+// sweeperActor is recorded on events emitted by background sweeps.
+const sweeperActor = "system"
+
+func startAttestationSweeper(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runAttestationSweep(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runAttestationSweep(e engine.Engine) {
+	expired, err := e.ExpireAttestations(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("attestation sweep: %v", err)
+		return
+	}
+	if len(expired) > 0 {
+		log.Printf("attestation sweep: %d attestation(s) expired", len(expired))
+	}
+}
+
+func startStaleSweeper(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.Config.Project.Staleness.Days <= 0 || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runStaleSweep(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runStaleSweep(e engine.Engine) {
+	stale, err := e.MarkStaleTasks(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("staleness sweep: %v", err)
+		return
+	}
+	if len(stale) > 0 {
+		log.Printf("staleness sweep: %d task(s) marked stale", len(stale))
+	}
+}
+
+func startSLASweeper(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runSLASweep(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runSLASweep(e engine.Engine) {
+	breached, err := e.BreachSLAs(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("sla sweep: %v", err)
+		return
+	}
+	if len(breached) > 0 {
+		log.Printf("sla sweep: %d task(s) past their due date", len(breached))
+	}
+}
+
+func startEscalationSweeper(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || len(e.Config.Project.Escalation) == 0 || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runEscalationSweep(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runEscalationSweep(e engine.Engine) {
+	escalated, err := e.EscalatePriorities(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("escalation sweep: %v", err)
+		return
+	}
+	if len(escalated) > 0 {
+		log.Printf("escalation sweep: %d task(s) escalated", len(escalated))
+	}
+}
+
+func startRoleExpirySweeper(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runRoleExpirySweep(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runRoleExpirySweep(e engine.Engine) {
+	expired, err := e.ExpireRoleGrants(context.Background(), e.Config.Project.ID, sweeperActor)
+	if err != nil {
+		log.Printf("role expiry sweep: %v", err)
+		return
+	}
+	if len(expired) > 0 {
+		log.Printf("role expiry sweep: %d role grant(s) expired", len(expired))
+	}
+}
+
+func startHookRetrier(e engine.Engine, interval time.Duration) {
+	if interval <= 0 || e.DB == nil || e.Config == nil || e.ReadOnly {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runHookRetry(e)
+			<-ticker.C
+		}
+	}()
+}
+
+func runHookRetry(e engine.Engine) {
+	sent, err := e.SendHookDeliveries(context.Background())
+	if err != nil {
+		log.Printf("hook retry: %v", err)
+	}
+	if sent > 0 {
+		log.Printf("hook retry: %d webhook hook(s) delivered", sent)
+	}
+}
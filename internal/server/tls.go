@@ -0,0 +1,105 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures HTTPS and optional client-certificate verification.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of CAs trusted to sign client
+	// certificates; setting it enables client-certificate verification.
+	ClientCAFile string
+	// RequireClientCert rejects connections without a valid client
+	// certificate instead of falling back to tokens and API keys.
+	RequireClientCert bool
+}
+
+// Enabled reports whether TLS is configured.
+func (o TLSOptions) Enabled() bool {
+	return o.CertFile != "" || o.KeyFile != ""
+}
+
+// NewTLSConfig builds the server TLS config from the options.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("tls requires both a certificate and a key")
+	}
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load tls key pair: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.ClientCAFile == "" {
+		if opts.RequireClientCert {
+			return nil, errors.New("requiring client certificates needs a client CA bundle")
+		}
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(opts.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", opts.ClientCAFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if opts.RequireClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// Client certificate fields that can name the actor.
+const (
+	CertActorCN    = "cn"
+	CertActorEmail = "email"
+	CertActorDNS   = "dns"
+	CertActorURI   = "uri"
+)
+
+// ValidCertActorField reports whether field names a supported certificate field.
+func ValidCertActorField(field string) bool {
+	switch field {
+	case CertActorCN, CertActorEmail, CertActorDNS, CertActorURI:
+		return true
+	}
+	return false
+}
+
+// certActor returns the actor named by a verified client certificate, or ""
+// when there is none or the chosen field is empty.
+func certActor(state *tls.ConnectionState, field string) string {
+	if field == "" || state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	leaf := state.VerifiedChains[0][0]
+	switch field {
+	case CertActorCN:
+		return strings.TrimSpace(leaf.Subject.CommonName)
+	case CertActorEmail:
+		if len(leaf.EmailAddresses) > 0 {
+			return leaf.EmailAddresses[0]
+		}
+	case CertActorDNS:
+		if len(leaf.DNSNames) > 0 {
+			return leaf.DNSNames[0]
+		}
+	case CertActorURI:
+		if len(leaf.URIs) > 0 {
+			return leaf.URIs[0].String()
+		}
+	}
+	return ""
+}
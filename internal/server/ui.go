@@ -0,0 +1,36 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+var uiIndex = template.Must(template.ParseFS(uiFiles, "ui/index.html"))
+
+// registerUI serves the dashboard at /ui. The app is static files embedded in
+// the binary; only the index page is rendered, to tell the app where the API
+// lives so it keeps working behind a proxy prefix.
+func registerUI(r chi.Router, basePath, publicURL string) {
+	static, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/ui/", http.FileServer(http.FS(static)))
+	index := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		uiIndex.Execute(w, struct{ APIBase string }{APIBase: externalPrefix(req, publicURL) + basePath})
+	}
+	r.Get("/ui", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, externalPrefix(req, publicURL)+"/ui/", http.StatusMovedPermanently)
+	})
+	r.Get("/ui/", index)
+	r.Get("/ui/index.html", index)
+	r.Get("/ui/*", files.ServeHTTP)
+}
@@ -0,0 +1,238 @@
+// Workline dashboard: a thin client over the JSON API. It signs in with the
+// dev-login endpoint or an API key, then polls the event feed and redraws
+// the status, board and validation panes when something changes.
+(() => {
+  "use strict";
+
+  const api = document.querySelector('meta[name="workline-api"]').content;
+  const storeKey = "workline.auth";
+  const pollMs = 5000;
+  const $ = (id) => document.getElementById(id);
+
+  let auth = JSON.parse(localStorage.getItem(storeKey) || "null");
+  let project = localStorage.getItem("workline.project") || "";
+  let selectedTask = "";
+  let lastEventID = 0;
+  let timer = 0;
+
+  function el(tag, attrs, ...children) {
+    const node = document.createElement(tag);
+    for (const [k, v] of Object.entries(attrs || {})) {
+      if (k === "class") node.className = v;
+      else if (k.startsWith("on")) node.addEventListener(k.slice(2), v);
+      else node.setAttribute(k, v);
+    }
+    for (const child of children) {
+      if (child !== null && child !== undefined) node.append(child);
+    }
+    return node;
+  }
+
+  async function call(method, path, body) {
+    const headers = { Accept: "application/json" };
+    if (auth && auth.token) headers.Authorization = "Bearer " + auth.token;
+    if (auth && auth.key) headers["X-Api-Key"] = auth.key;
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+    const res = await fetch(api + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const data = res.status === 204 ? null : await res.json().catch(() => null);
+    if (!res.ok) {
+      const err = new Error((data && data.error && data.error.message) || res.statusText);
+      err.status = res.status;
+      throw err;
+    }
+    return data;
+  }
+
+  function showError(err) {
+    if (err && err.status === 401) {
+      signOut();
+      $("login-error").textContent = "Session expired or credentials rejected; sign in again.";
+      return;
+    }
+    $("error").textContent = err ? err.message : "";
+  }
+
+  function signIn(next) {
+    auth = next;
+    localStorage.setItem(storeKey, JSON.stringify(auth));
+    start();
+  }
+
+  function signOut() {
+    auth = null;
+    localStorage.removeItem(storeKey);
+    clearTimeout(timer);
+    $("app").hidden = true;
+    $("project").hidden = true;
+    $("logout").hidden = true;
+    $("whoami").textContent = "";
+    $("login").hidden = false;
+  }
+
+  async function start() {
+    $("login").hidden = true;
+    $("login-error").textContent = "";
+    try {
+      const me = await call("GET", "/me");
+      $("whoami").textContent = me.actor_id + (me.org_id ? " · " + me.org_id : "");
+      const projects = await call("GET", "/projects");
+      const select = $("project");
+      select.replaceChildren(...projects.map((p) => el("option", { value: p.id }, p.id)));
+      if (!projects.some((p) => p.id === project) && projects.length > 0) {
+        project = projects[0].id;
+      }
+      select.value = project;
+      select.hidden = projects.length < 2;
+      $("logout").hidden = false;
+      $("app").hidden = false;
+      await refresh(true);
+    } catch (err) {
+      showError(err);
+    }
+  }
+
+  // refresh polls the event feed and redraws everything when it moved or
+  // when forced (sign-in, project switch).
+  async function refresh(force) {
+    clearTimeout(timer);
+    if (!project) {
+      $("error").textContent = "No project visible to this actor.";
+      return;
+    }
+    const base = "/projects/" + encodeURIComponent(project);
+    try {
+      const events = await call("GET", base + "/events?limit=20");
+      const latest = events.items.length > 0 ? events.items[0].id : 0;
+      if (force || latest !== lastEventID) {
+        lastEventID = latest;
+        const [status, board] = await Promise.all([
+          call("GET", base + "/status"),
+          call("GET", base + "/board"),
+        ]);
+        renderStatus(status);
+        renderBoard(board);
+        renderEvents(events.items);
+        if (selectedTask) await showValidation(selectedTask);
+      }
+      $("error").textContent = "";
+    } catch (err) {
+      showError(err);
+      if (err.status === 401) return;
+    }
+    timer = setTimeout(refresh, pollMs);
+  }
+
+  function renderStatus(s) {
+    const counts = Object.entries(s.task_counts || {})
+      .map(([status, n]) => el("li", {}, el("strong", {}, String(n)), " " + status));
+    const it = s.iteration;
+    $("status").replaceChildren(
+      el("h2", {}, s.project_id + " ", el("span", { class: "pill" }, s.status)),
+      el("p", {}, it ? "Running iteration " + it.id + ": " + it.goal : "No running iteration."),
+      el("ul", { class: "counts" }, ...counts),
+    );
+  }
+
+  function renderBoard(board) {
+    const columns = board.columns.map((col) => {
+      const head = col.status + " (" + col.count + (col.wip_limit ? "/" + col.wip_limit : "") + ")";
+      const cards = col.tasks.map((t) => {
+        const req = t.required_attestations || [];
+        return el("li", {
+          class: "card" + (t.id === selectedTask ? " selected" : ""),
+          "data-id": t.id,
+          tabindex: "0",
+          onclick: () => selectTask(t.id),
+          onkeydown: (e) => { if (e.key === "Enter") selectTask(t.id); },
+        },
+          el("span", { class: "title" }, t.title),
+          el("span", { class: "meta" },
+            [t.type, t.assignee_id, t.priority != null ? "p" + t.priority : null].filter(Boolean).join(" · ")),
+          req.length > 0 ? el("span", { class: "badge", title: req.join(", ") }, req.length + " attestation" + (req.length > 1 ? "s" : "")) : null,
+          dueLabel(t),
+        );
+      });
+      return el("div", { class: "column" + (col.over_limit ? " over" : "") },
+        el("h3", {}, head),
+        el("ol", {}, ...cards));
+    });
+    $("board").replaceChildren(...columns);
+  }
+
+  // dueLabel shows when a task is due, flagged once an open task is past it.
+  function dueLabel(t) {
+    if (!t.due_at) return null;
+    const open = !t.completed_at && t.status !== "canceled" && t.status !== "rejected";
+    const overdue = open && new Date(t.due_at) < new Date();
+    return el("span", { class: "due" + (overdue ? " overdue" : ""), title: t.due_at },
+      (overdue ? "overdue, due " : "due ") + t.due_at.slice(0, 10));
+  }
+
+  function renderEvents(items) {
+    $("events").replaceChildren(...items.map((ev) => el("li", {},
+      el("time", { datetime: ev.ts }, new Date(ev.ts).toLocaleTimeString()),
+      " ", el("strong", {}, ev.type), " ",
+      ev.entity_kind + (ev.entity_id ? "/" + ev.entity_id : ""),
+      el("span", { class: "muted" }, " by " + ev.actor_id))));
+  }
+
+  async function selectTask(id) {
+    selectedTask = id;
+    document.querySelectorAll(".card").forEach((c) => c.classList.toggle("selected", c.dataset.id === id));
+    try {
+      await showValidation(id);
+    } catch (err) {
+      showError(err);
+    }
+  }
+
+  async function showValidation(id) {
+    const base = "/projects/" + encodeURIComponent(project) + "/tasks/" + encodeURIComponent(id);
+    const v = await call("GET", base + "/validation");
+    const kinds = (list, cls) => list.map((k) => el("li", { class: cls }, k));
+    $("validation").replaceChildren(
+      el("p", {}, el("code", {}, id)),
+      el("p", { class: v.satisfied ? "ok" : "pending" }, v.satisfied ? "Validation satisfied" : "Validation pending"),
+      v.required.length === 0 ? el("p", { class: "muted" }, "No attestations required.") : el("ul", { class: "kinds" },
+        ...kinds(v.present, "ok"),
+        ...kinds(v.missing.filter((k) => !v.expired.includes(k)), "pending"),
+        ...kinds(v.expired, "expired")),
+    );
+  }
+
+  $("dev-login").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const form = new FormData(e.target);
+    try {
+      const res = await call("POST", "/auth/dev/login", {
+        actor_id: form.get("actor_id"),
+        org_id: form.get("org_id"),
+      });
+      signIn({ token: res.token });
+    } catch (err) {
+      $("login-error").textContent = err.message;
+    }
+  });
+
+  $("key-login").addEventListener("submit", (e) => {
+    e.preventDefault();
+    signIn({ key: new FormData(e.target).get("key") });
+  });
+
+  $("logout").addEventListener("click", signOut);
+
+  $("project").addEventListener("change", (e) => {
+    project = e.target.value;
+    localStorage.setItem("workline.project", project);
+    selectedTask = "";
+    $("validation").replaceChildren(el("p", { class: "muted" }, "Select a task."));
+    refresh(true);
+  });
+
+  if (auth) start();
+  else signOut();
+})();
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"workline/internal/engine"
+)
+
+func registerWebhookDeliveries(api huma.API, e engine.Engine) {
+	huma.Register(api, huma.Operation{
+		OperationID: "list-webhook-deliveries",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/webhooks/deliveries",
+		Summary:     "List webhook deliveries",
+		Description: "Recent webhook delivery attempts, newest first, with the receiver's status code. Needs webhook.manage.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID string `path:"project_id"`
+		Failed    bool   `query:"failed" doc:"Only failed attempts"`
+		Limit     int    `query:"limit" default:"50"`
+	}) (*struct {
+		Body []WebhookDeliveryResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		items, err := e.ListWebhookDeliveries(ctx, projectID, input.Failed, normalizeLimit(input.Limit), actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		resp := make([]WebhookDeliveryResponse, 0, len(items))
+		for _, d := range items {
+			resp = append(resp, webhookDeliveryResponse(d))
+		}
+		return &struct {
+			Body []WebhookDeliveryResponse `json:"body"`
+		}{Body: resp}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "replay-webhook-delivery",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/webhooks/deliveries/{delivery_id}/replay",
+		Summary:     "Replay a webhook delivery",
+		Description: "Resends the body of a logged delivery to its webhook, signed with the current secret, and returns the new attempt; check its status_code and error. Needs webhook.manage.",
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusInternalServerError,
+		},
+	}, func(ctx context.Context, input *struct {
+		ProjectID  string `path:"project_id"`
+		DeliveryID string `path:"delivery_id"`
+	}) (*struct {
+		Body WebhookDeliveryResponse `json:"body"`
+	}, error) {
+		actorID, authErr := actorIDFromContext(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		projectID := projectFromPathOrHeader(ctx, input.ProjectID, e.Config.Project.ID)
+		d, err := e.ReplayWebhookDelivery(ctx, projectID, input.DeliveryID, actorID)
+		if err != nil {
+			return nil, handleError(err)
+		}
+		return &struct {
+			Body WebhookDeliveryResponse `json:"body"`
+		}{Body: webhookDeliveryResponse(d)}, nil
+	})
+}
@@ -1,25 +1,18 @@
 package server
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"workline/internal/config"
-	"workline/internal/domain"
 	"workline/internal/engine"
 )
 
 const (
 	defaultWebhookInterval = 2 * time.Second
-	defaultWebhookTimeout  = 5 * time.Second
 	defaultWebhookBatch    = 100
 )
 
@@ -27,7 +20,6 @@ type webhookDispatcher struct {
 	engine   engine.Engine
 	project  string
 	webhooks []config.WebhookConfig
-	client   *http.Client
 	mu       sync.Mutex
 	cursors  map[int]int64
 }
@@ -44,7 +36,6 @@ func startWebhookDispatcher(e engine.Engine) {
 		engine:   e,
 		project:  projectID,
 		webhooks: e.Config.Webhooks,
-		client:   &http.Client{Timeout: defaultWebhookTimeout},
 		cursors:  make(map[int]int64),
 	}
 	go d.run()
@@ -88,8 +79,12 @@ func (d *webhookDispatcher) dispatchWebhook(idx int, hook config.WebhookConfig)
 			d.setCursor(idx, evt.ID)
 			continue
 		}
-		if err := d.postEvent(ctx, hook, evt); err != nil {
-			log.Printf("webhook: deliver to %s failed: %v", hook.URL, err)
+		delivery, err := d.engine.DeliverWebhookEvent(ctx, d.project, hook, evt)
+		if err != nil {
+			log.Printf("webhook: log delivery to %s failed: %v", hook.URL, err)
+		}
+		if delivery.Error != "" {
+			log.Printf("webhook: deliver to %s failed: %s", hook.URL, delivery.Error)
 			return
 		}
 		d.setCursor(idx, evt.ID)
@@ -118,74 +113,6 @@ func (d *webhookDispatcher) setCursor(idx int, value int64) {
 	d.mu.Unlock()
 }
 
-type webhookEvent struct {
-	ID         int64           `json:"id"`
-	Type       string          `json:"type"`
-	ProjectID  string          `json:"project_id"`
-	EntityKind string          `json:"entity_kind"`
-	EntityID   string          `json:"entity_id,omitempty"`
-	ActorID    string          `json:"actor_id"`
-	TS         string          `json:"ts"`
-	Payload    json.RawMessage `json:"payload"`
-	PayloadRaw string          `json:"payload_raw,omitempty"`
-}
-
-func (d *webhookDispatcher) postEvent(ctx context.Context, hook config.WebhookConfig, evt domain.Event) error {
-	payload := json.RawMessage([]byte("{}"))
-	var raw string
-	if evt.Payload != "" {
-		if json.Valid([]byte(evt.Payload)) {
-			payload = json.RawMessage([]byte(evt.Payload))
-		} else {
-			raw = evt.Payload
-		}
-	}
-	body := webhookEvent{
-		ID:         evt.ID,
-		Type:       evt.Type,
-		ProjectID:  evt.ProjectID,
-		EntityKind: evt.EntityKind,
-		EntityID:   evt.EntityID,
-		ActorID:    evt.ActorID,
-		TS:         evt.TS,
-		Payload:    payload,
-		PayloadRaw: raw,
-	}
-	data, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-	timeout := defaultWebhookTimeout
-	if hook.TimeoutSeconds > 0 {
-		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
-	}
-	client := d.client
-	if timeout != d.client.Timeout {
-		client = &http.Client{Timeout: timeout}
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(data))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Workline-Event", evt.Type)
-	req.Header.Set("X-Workline-Delivery", fmt.Sprintf("%d", evt.ID))
-	req.Header.Set("X-Workline-Project", d.project)
-	if strings.TrimSpace(hook.Secret) != "" {
-		req.Header.Set("X-Workline-Secret", hook.Secret)
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
-		return fmt.Errorf("status %d: %s", res.StatusCode, strings.TrimSpace(string(bodyBytes)))
-	}
-	return nil
-}
-
 type eventFilter struct {
 	all bool
 	set map[string]struct{}
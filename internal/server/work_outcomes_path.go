@@ -0,0 +1,181 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// outcomePathSegment is one step of a work outcomes path: an object key, an
+// array index, or (JSON pointer "-") the end of an array.
+type outcomePathSegment struct {
+	key    string
+	index  int
+	append bool
+	// loose marks JSON pointer tokens that read as an index; they address an
+	// array element when the container is an array and a key otherwise.
+	loose bool
+}
+
+func (s outcomePathSegment) isIndex() bool {
+	return s.index >= 0 && !s.loose
+}
+
+func (s outcomePathSegment) String() string {
+	switch {
+	case s.append:
+		return "[-]"
+	case s.isIndex():
+		return fmt.Sprintf("[%d]", s.index)
+	default:
+		return "." + s.key
+	}
+}
+
+// workOutcomesPathError reports a path that runs into a value of the wrong
+// type, such as indexing into an object or appending to a string.
+type workOutcomesPathError struct {
+	Path string
+	Want string
+	Got  string
+}
+
+func (e workOutcomesPathError) Error() string {
+	return fmt.Sprintf("work_outcomes%s is %s, not %s", e.Path, e.Got, e.Want)
+}
+
+// parseWorkOutcomesPath accepts a dotted path with bracketed indexes
+// (results.tests[0].status) or a JSON pointer (/results/tests/0/status). A
+// plain key addresses a top-level member, as before nested paths existed.
+func parseWorkOutcomesPath(path string) ([]outcomePathSegment, error) {
+	if strings.HasPrefix(path, "/") {
+		tokens, err := parseJSONPointer(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		segs := make([]outcomePathSegment, 0, len(tokens))
+		for _, tok := range tokens {
+			seg := outcomePathSegment{key: tok, index: -1}
+			if tok == "-" {
+				seg.append = true
+			} else if idx, err := jsonArrayIndex(tok); err == nil {
+				seg.index, seg.loose = idx, true
+			}
+			segs = append(segs, seg)
+		}
+		return segs, nil
+	}
+	var segs []outcomePathSegment
+	for i, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" && (i > 0 || rest == "") {
+			return nil, fmt.Errorf("invalid path %q: empty key", path)
+		}
+		if name != "" {
+			segs = append(segs, outcomePathSegment{key: name, index: -1})
+		}
+		for rest != "" {
+			idxText, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+			idx, err := strconv.Atoi(idxText)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("invalid path %q: index %q must be a non-negative integer", path, idxText)
+			}
+			segs = append(segs, outcomePathSegment{index: idx})
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid path %q: unexpected %q after index", path, after)
+			}
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segs, nil
+}
+
+// updateWorkOutcomesAt replaces the value at segs with fn's result. Missing
+// or null containers along the way are created as objects, or as arrays when
+// the next segment is an index; an index equal to the array length appends.
+func updateWorkOutcomesAt(root map[string]any, segs []outcomePathSegment, fn func(current any, exists bool, path string) (any, error)) error {
+	_, err := updateOutcomeValue(root, true, segs, "", fn)
+	return err
+}
+
+func updateOutcomeValue(node any, exists bool, segs []outcomePathSegment, walked string, fn func(any, bool, string) (any, error)) (any, error) {
+	if len(segs) == 0 {
+		return fn(node, exists && node != nil, walked)
+	}
+	seg, rest := segs[0], segs[1:]
+	if !exists || node == nil {
+		if seg.isIndex() || seg.append {
+			node = []any{}
+		} else {
+			node = map[string]any{}
+		}
+	}
+	here := walked + seg.String()
+	switch n := node.(type) {
+	case map[string]any:
+		if seg.isIndex() || seg.append {
+			return nil, workOutcomesPathError{Path: walked, Want: "an array", Got: "an object"}
+		}
+		child, ok := n[seg.key]
+		updated, err := updateOutcomeValue(child, ok, rest, walked+"."+seg.key, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[seg.key] = updated
+		return n, nil
+	case []any:
+		if seg.append {
+			updated, err := updateOutcomeValue(nil, false, rest, here, fn)
+			if err != nil {
+				return nil, err
+			}
+			return append(n, updated), nil
+		}
+		if seg.index < 0 {
+			return nil, workOutcomesPathError{Path: walked, Want: "an object", Got: "an array"}
+		}
+		here = fmt.Sprintf("%s[%d]", walked, seg.index)
+		if seg.index > len(n) {
+			return nil, fmt.Errorf("invalid path: index %d beyond the %d elements of work_outcomes%s", seg.index, len(n), walked)
+		}
+		if seg.index == len(n) {
+			updated, err := updateOutcomeValue(nil, false, rest, here, fn)
+			if err != nil {
+				return nil, err
+			}
+			return append(n, updated), nil
+		}
+		updated, err := updateOutcomeValue(n[seg.index], true, rest, here, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[seg.index] = updated
+		return n, nil
+	default:
+		want := "an object"
+		if seg.isIndex() || seg.append {
+			want = "an array"
+		}
+		return nil, workOutcomesPathError{Path: walked, Want: want, Got: jsonTypeName(node)}
+	}
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,1988 @@
+// Workline gRPC API: the task lifecycle, attestations and the event feed for
+// agent orchestrators that prefer streaming RPC over REST polling. It mirrors
+// the HTTP API under /v0 and is served by `wl serve --grpc-addr`.
+//
+// Authenticate with the same credentials as HTTP, sent as metadata:
+// "authorization: Bearer <jwt>" or "x-api-key: <key>".
+//
+// The Go code in internal/server/worklinev1 is generated from this file;
+// run `make proto` after editing.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: workline/v1/workline.proto
+
+package worklinev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Task struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId            string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	IterationId          *string                `protobuf:"bytes,3,opt,name=iteration_id,json=iterationId,proto3,oneof" json:"iteration_id,omitempty"`
+	ParentId             *string                `protobuf:"bytes,4,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Type                 string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	Component            string                 `protobuf:"bytes,6,opt,name=component,proto3" json:"component,omitempty"`
+	Title                string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
+	Description          string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	Status               string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	AssigneeId           *string                `protobuf:"bytes,10,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	Priority             *int32                 `protobuf:"varint,11,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	Estimate             *float64               `protobuf:"fixed64,12,opt,name=estimate,proto3,oneof" json:"estimate,omitempty"`
+	WorkOutcomes         *structpb.Struct       `protobuf:"bytes,13,opt,name=work_outcomes,json=workOutcomes,proto3" json:"work_outcomes,omitempty"`
+	RequiredAttestations []string               `protobuf:"bytes,14,rep,name=required_attestations,json=requiredAttestations,proto3" json:"required_attestations,omitempty"`
+	DependsOn            []string               `protobuf:"bytes,15,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	CreatedAt            string                 `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            string                 `protobuf:"bytes,17,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	CompletedAt          *string                `protobuf:"bytes,18,opt,name=completed_at,json=completedAt,proto3,oneof" json:"completed_at,omitempty"`
+	// Non-blocking notices from the write that returned the task, such as a
+	// WIP limit being exceeded.
+	Warnings      []string `protobuf:"bytes,19,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_workline_v1_workline_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Task) GetIterationId() string {
+	if x != nil && x.IterationId != nil {
+		return *x.IterationId
+	}
+	return ""
+}
+
+func (x *Task) GetParentId() string {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return ""
+}
+
+func (x *Task) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Task) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetAssigneeId() string {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return ""
+}
+
+func (x *Task) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *Task) GetEstimate() float64 {
+	if x != nil && x.Estimate != nil {
+		return *x.Estimate
+	}
+	return 0
+}
+
+func (x *Task) GetWorkOutcomes() *structpb.Struct {
+	if x != nil {
+		return x.WorkOutcomes
+	}
+	return nil
+}
+
+func (x *Task) GetRequiredAttestations() []string {
+	if x != nil {
+		return x.RequiredAttestations
+	}
+	return nil
+}
+
+func (x *Task) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *Task) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Task) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Task) GetCompletedAt() string {
+	if x != nil && x.CompletedAt != nil {
+		return *x.CompletedAt
+	}
+	return ""
+}
+
+func (x *Task) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type Lease struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	AcquiredAt    string                 `protobuf:"bytes,3,opt,name=acquired_at,json=acquiredAt,proto3" json:"acquired_at,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lease) Reset() {
+	*x = Lease{}
+	mi := &file_workline_v1_workline_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lease) ProtoMessage() {}
+
+func (x *Lease) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lease.ProtoReflect.Descriptor instead.
+func (*Lease) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Lease) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *Lease) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *Lease) GetAcquiredAt() string {
+	if x != nil {
+		return x.AcquiredAt
+	}
+	return ""
+}
+
+func (x *Lease) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type Attestation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	EntityKind    string                 `protobuf:"bytes,3,opt,name=entity_kind,json=entityKind,proto3" json:"entity_kind,omitempty"`
+	EntityId      string                 `protobuf:"bytes,4,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
+	ActorId       string                 `protobuf:"bytes,6,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	Ts            string                 `protobuf:"bytes,7,opt,name=ts,proto3" json:"ts,omitempty"`
+	Payload       *structpb.Struct       `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+	ExpiredAt     *string                `protobuf:"bytes,9,opt,name=expired_at,json=expiredAt,proto3,oneof" json:"expired_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attestation) Reset() {
+	*x = Attestation{}
+	mi := &file_workline_v1_workline_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attestation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attestation) ProtoMessage() {}
+
+func (x *Attestation) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attestation.ProtoReflect.Descriptor instead.
+func (*Attestation) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Attestation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Attestation) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Attestation) GetEntityKind() string {
+	if x != nil {
+		return x.EntityKind
+	}
+	return ""
+}
+
+func (x *Attestation) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *Attestation) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Attestation) GetActorId() string {
+	if x != nil {
+		return x.ActorId
+	}
+	return ""
+}
+
+func (x *Attestation) GetTs() string {
+	if x != nil {
+		return x.Ts
+	}
+	return ""
+}
+
+func (x *Attestation) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Attestation) GetExpiredAt() string {
+	if x != nil && x.ExpiredAt != nil {
+		return *x.ExpiredAt
+	}
+	return ""
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ts            string                 `protobuf:"bytes,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,4,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	EntityKind    string                 `protobuf:"bytes,5,opt,name=entity_kind,json=entityKind,proto3" json:"entity_kind,omitempty"`
+	EntityId      string                 `protobuf:"bytes,6,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	ActorId       string                 `protobuf:"bytes,7,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	Payload       *structpb.Struct       `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_workline_v1_workline_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Event) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetTs() string {
+	if x != nil {
+		return x.Ts
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Event) GetEntityKind() string {
+	if x != nil {
+		return x.EntityKind
+	}
+	return ""
+}
+
+func (x *Event) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *Event) GetActorId() string {
+	if x != nil {
+		return x.ActorId
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type TaskValidation struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Required []string               `protobuf:"bytes,1,rep,name=required,proto3" json:"required,omitempty"`
+	Present  []string               `protobuf:"bytes,2,rep,name=present,proto3" json:"present,omitempty"`
+	Missing  []string               `protobuf:"bytes,3,rep,name=missing,proto3" json:"missing,omitempty"`
+	// Missing kinds that were attested but whose attestations all expired.
+	Expired       []string `protobuf:"bytes,4,rep,name=expired,proto3" json:"expired,omitempty"`
+	Satisfied     bool     `protobuf:"varint,5,opt,name=satisfied,proto3" json:"satisfied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskValidation) Reset() {
+	*x = TaskValidation{}
+	mi := &file_workline_v1_workline_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskValidation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskValidation) ProtoMessage() {}
+
+func (x *TaskValidation) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskValidation.ProtoReflect.Descriptor instead.
+func (*TaskValidation) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TaskValidation) GetRequired() []string {
+	if x != nil {
+		return x.Required
+	}
+	return nil
+}
+
+func (x *TaskValidation) GetPresent() []string {
+	if x != nil {
+		return x.Present
+	}
+	return nil
+}
+
+func (x *TaskValidation) GetMissing() []string {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+func (x *TaskValidation) GetExpired() []string {
+	if x != nil {
+		return x.Expired
+	}
+	return nil
+}
+
+func (x *TaskValidation) GetSatisfied() bool {
+	if x != nil {
+		return x.Satisfied
+	}
+	return false
+}
+
+type CreateTaskRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	// Optional; a deterministic id is derived when empty.
+	Id            string           `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string           `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Title         string           `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string           `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	IterationId   string           `protobuf:"bytes,6,opt,name=iteration_id,json=iterationId,proto3" json:"iteration_id,omitempty"`
+	ParentId      string           `protobuf:"bytes,7,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Component     string           `protobuf:"bytes,8,opt,name=component,proto3" json:"component,omitempty"`
+	AssigneeId    string           `protobuf:"bytes,9,opt,name=assignee_id,json=assigneeId,proto3" json:"assignee_id,omitempty"`
+	Priority      *int32           `protobuf:"varint,10,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	Estimate      *float64         `protobuf:"fixed64,11,opt,name=estimate,proto3,oneof" json:"estimate,omitempty"`
+	DependsOn     []string         `protobuf:"bytes,12,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	PolicyPreset  string           `protobuf:"bytes,13,opt,name=policy_preset,json=policyPreset,proto3" json:"policy_preset,omitempty"`
+	WorkOutcomes  *structpb.Struct `protobuf:"bytes,14,opt,name=work_outcomes,json=workOutcomes,proto3" json:"work_outcomes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetIterationId() string {
+	if x != nil {
+		return x.IterationId
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetAssigneeId() string {
+	if x != nil {
+		return x.AssigneeId
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *CreateTaskRequest) GetEstimate() float64 {
+	if x != nil && x.Estimate != nil {
+		return *x.Estimate
+	}
+	return 0
+}
+
+func (x *CreateTaskRequest) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *CreateTaskRequest) GetPolicyPreset() string {
+	if x != nil {
+		return x.PolicyPreset
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetWorkOutcomes() *structpb.Struct {
+	if x != nil {
+		return x.WorkOutcomes
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *GetTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListTasksRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId   string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Statuses    []string               `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	Types       []string               `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	IterationId string                 `protobuf:"bytes,4,opt,name=iteration_id,json=iterationId,proto3" json:"iteration_id,omitempty"`
+	AssigneeId  string                 `protobuf:"bytes,5,opt,name=assignee_id,json=assigneeId,proto3" json:"assignee_id,omitempty"`
+	// Page size; defaults to 50, at most 200.
+	Limit         int32  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string `protobuf:"bytes,7,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTasksRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetIterationId() string {
+	if x != nil {
+		return x.IterationId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetAssigneeId() string {
+	if x != nil {
+		return x.AssigneeId
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_workline_v1_workline_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type UpdateTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	AssigneeId    *string                `protobuf:"bytes,4,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	Priority      *int32                 `protobuf:"varint,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	Force         bool                   `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRequest) Reset() {
+	*x = UpdateTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetAssigneeId() string {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type ClaimTaskRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id        string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// Defaults to 900.
+	LeaseSeconds  int32 `protobuf:"varint,3,opt,name=lease_seconds,json=leaseSeconds,proto3" json:"lease_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimTaskRequest) Reset() {
+	*x = ClaimTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimTaskRequest) ProtoMessage() {}
+
+func (x *ClaimTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimTaskRequest.ProtoReflect.Descriptor instead.
+func (*ClaimTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClaimTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ClaimTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClaimTaskRequest) GetLeaseSeconds() int32 {
+	if x != nil {
+		return x.LeaseSeconds
+	}
+	return 0
+}
+
+type ClaimNextTaskRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	// Defaults to the running iteration.
+	IterationId       string   `protobuf:"bytes,2,opt,name=iteration_id,json=iterationId,proto3" json:"iteration_id,omitempty"`
+	Types             []string `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	ExcludeUnassigned bool     `protobuf:"varint,4,opt,name=exclude_unassigned,json=excludeUnassigned,proto3" json:"exclude_unassigned,omitempty"`
+	// Defaults to 900.
+	LeaseSeconds  int32 `protobuf:"varint,5,opt,name=lease_seconds,json=leaseSeconds,proto3" json:"lease_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimNextTaskRequest) Reset() {
+	*x = ClaimNextTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimNextTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimNextTaskRequest) ProtoMessage() {}
+
+func (x *ClaimNextTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimNextTaskRequest.ProtoReflect.Descriptor instead.
+func (*ClaimNextTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ClaimNextTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ClaimNextTaskRequest) GetIterationId() string {
+	if x != nil {
+		return x.IterationId
+	}
+	return ""
+}
+
+func (x *ClaimNextTaskRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ClaimNextTaskRequest) GetExcludeUnassigned() bool {
+	if x != nil {
+		return x.ExcludeUnassigned
+	}
+	return false
+}
+
+func (x *ClaimNextTaskRequest) GetLeaseSeconds() int32 {
+	if x != nil {
+		return x.LeaseSeconds
+	}
+	return 0
+}
+
+type ClaimNextTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Lease         *Lease                 `protobuf:"bytes,2,opt,name=lease,proto3" json:"lease,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimNextTaskResponse) Reset() {
+	*x = ClaimNextTaskResponse{}
+	mi := &file_workline_v1_workline_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimNextTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimNextTaskResponse) ProtoMessage() {}
+
+func (x *ClaimNextTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimNextTaskResponse.ProtoReflect.Descriptor instead.
+func (*ClaimNextTaskResponse) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ClaimNextTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *ClaimNextTaskResponse) GetLease() *Lease {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+type ReleaseTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseTaskRequest) Reset() {
+	*x = ReleaseTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseTaskRequest) ProtoMessage() {}
+
+func (x *ReleaseTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseTaskRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReleaseTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ReleaseTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ReleaseTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseTaskResponse) Reset() {
+	*x = ReleaseTaskResponse{}
+	mi := &file_workline_v1_workline_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseTaskResponse) ProtoMessage() {}
+
+func (x *ReleaseTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseTaskResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseTaskResponse) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{14}
+}
+
+type CompleteTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	WorkOutcomes  *structpb.Struct       `protobuf:"bytes,3,opt,name=work_outcomes,json=workOutcomes,proto3" json:"work_outcomes,omitempty"`
+	Force         bool                   `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTaskRequest) Reset() {
+	*x = CompleteTaskRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTaskRequest) ProtoMessage() {}
+
+func (x *CompleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CompleteTaskRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *CompleteTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CompleteTaskRequest) GetWorkOutcomes() *structpb.Struct {
+	if x != nil {
+		return x.WorkOutcomes
+	}
+	return nil
+}
+
+func (x *CompleteTaskRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type GetTaskValidationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskValidationRequest) Reset() {
+	*x = GetTaskValidationRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskValidationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskValidationRequest) ProtoMessage() {}
+
+func (x *GetTaskValidationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskValidationRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskValidationRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetTaskValidationRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *GetTaskValidationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type AddAttestationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	EntityKind    string                 `protobuf:"bytes,2,opt,name=entity_kind,json=entityKind,proto3" json:"entity_kind,omitempty"`
+	EntityId      string                 `protobuf:"bytes,3,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	Payload       *structpb.Struct       `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddAttestationRequest) Reset() {
+	*x = AddAttestationRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddAttestationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddAttestationRequest) ProtoMessage() {}
+
+func (x *AddAttestationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddAttestationRequest.ProtoReflect.Descriptor instead.
+func (*AddAttestationRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AddAttestationRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *AddAttestationRequest) GetEntityKind() string {
+	if x != nil {
+		return x.EntityKind
+	}
+	return ""
+}
+
+func (x *AddAttestationRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *AddAttestationRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *AddAttestationRequest) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type ListAttestationsRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId  string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	EntityKind string                 `protobuf:"bytes,2,opt,name=entity_kind,json=entityKind,proto3" json:"entity_kind,omitempty"`
+	EntityId   string                 `protobuf:"bytes,3,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Kind       string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Page size; defaults to 50, at most 200.
+	Limit         int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttestationsRequest) Reset() {
+	*x = ListAttestationsRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttestationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttestationsRequest) ProtoMessage() {}
+
+func (x *ListAttestationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttestationsRequest.ProtoReflect.Descriptor instead.
+func (*ListAttestationsRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListAttestationsRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListAttestationsRequest) GetEntityKind() string {
+	if x != nil {
+		return x.EntityKind
+	}
+	return ""
+}
+
+func (x *ListAttestationsRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *ListAttestationsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ListAttestationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAttestationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ListAttestationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attestations  []*Attestation         `protobuf:"bytes,1,rep,name=attestations,proto3" json:"attestations,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttestationsResponse) Reset() {
+	*x = ListAttestationsResponse{}
+	mi := &file_workline_v1_workline_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttestationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttestationsResponse) ProtoMessage() {}
+
+func (x *ListAttestationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttestationsResponse.ProtoReflect.Descriptor instead.
+func (*ListAttestationsResponse) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListAttestationsResponse) GetAttestations() []*Attestation {
+	if x != nil {
+		return x.Attestations
+	}
+	return nil
+}
+
+func (x *ListAttestationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type WatchEventsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	// Replay events with a greater id first (0 replays the whole feed). When
+	// unset, only events recorded after the call are sent.
+	AfterId *int64 `protobuf:"varint,2,opt,name=after_id,json=afterId,proto3,oneof" json:"after_id,omitempty"`
+	// Only send these event types; empty sends all.
+	Types         []string `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_workline_v1_workline_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workline_v1_workline_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_workline_v1_workline_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WatchEventsRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *WatchEventsRequest) GetAfterId() int64 {
+	if x != nil && x.AfterId != nil {
+		return *x.AfterId
+	}
+	return 0
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+var File_workline_v1_workline_proto protoreflect.FileDescriptor
+
+const file_workline_v1_workline_proto_rawDesc = "" +
+	"\n" +
+	"\x1aworkline/v1/workline.proto\x12\vworkline.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xd7\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tR\tprojectId\x12&\n" +
+	"\fiteration_id\x18\x03 \x01(\tH\x00R\viterationId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\x04 \x01(\tH\x01R\bparentId\x88\x01\x01\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x1c\n" +
+	"\tcomponent\x18\x06 \x01(\tR\tcomponent\x12\x14\n" +
+	"\x05title\x18\a \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12$\n" +
+	"\vassignee_id\x18\n" +
+	" \x01(\tH\x02R\n" +
+	"assigneeId\x88\x01\x01\x12\x1f\n" +
+	"\bpriority\x18\v \x01(\x05H\x03R\bpriority\x88\x01\x01\x12\x1f\n" +
+	"\bestimate\x18\f \x01(\x01H\x04R\bestimate\x88\x01\x01\x12<\n" +
+	"\rwork_outcomes\x18\r \x01(\v2\x17.google.protobuf.StructR\fworkOutcomes\x123\n" +
+	"\x15required_attestations\x18\x0e \x03(\tR\x14requiredAttestations\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\x0f \x03(\tR\tdependsOn\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x11 \x01(\tR\tupdatedAt\x12&\n" +
+	"\fcompleted_at\x18\x12 \x01(\tH\x05R\vcompletedAt\x88\x01\x01\x12\x1a\n" +
+	"\bwarnings\x18\x13 \x03(\tR\bwarningsB\x0f\n" +
+	"\r_iteration_idB\f\n" +
+	"\n" +
+	"_parent_idB\x0e\n" +
+	"\f_assignee_idB\v\n" +
+	"\t_priorityB\v\n" +
+	"\t_estimateB\x0f\n" +
+	"\r_completed_at\"{\n" +
+	"\x05Lease\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\tR\aownerId\x12\x1f\n" +
+	"\vacquired_at\x18\x03 \x01(\tR\n" +
+	"acquiredAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\tR\texpiresAt\"\x9f\x02\n" +
+	"\vAttestation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tR\tprojectId\x12\x1f\n" +
+	"\ventity_kind\x18\x03 \x01(\tR\n" +
+	"entityKind\x12\x1b\n" +
+	"\tentity_id\x18\x04 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04kind\x18\x05 \x01(\tR\x04kind\x12\x19\n" +
+	"\bactor_id\x18\x06 \x01(\tR\aactorId\x12\x0e\n" +
+	"\x02ts\x18\a \x01(\tR\x02ts\x121\n" +
+	"\apayload\x18\b \x01(\v2\x17.google.protobuf.StructR\apayload\x12\"\n" +
+	"\n" +
+	"expired_at\x18\t \x01(\tH\x00R\texpiredAt\x88\x01\x01B\r\n" +
+	"\v_expired_at\"\xe6\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x0e\n" +
+	"\x02ts\x18\x02 \x01(\tR\x02ts\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x04 \x01(\tR\tprojectId\x12\x1f\n" +
+	"\ventity_kind\x18\x05 \x01(\tR\n" +
+	"entityKind\x12\x1b\n" +
+	"\tentity_id\x18\x06 \x01(\tR\bentityId\x12\x19\n" +
+	"\bactor_id\x18\a \x01(\tR\aactorId\x121\n" +
+	"\apayload\x18\b \x01(\v2\x17.google.protobuf.StructR\apayload\"\x98\x01\n" +
+	"\x0eTaskValidation\x12\x1a\n" +
+	"\brequired\x18\x01 \x03(\tR\brequired\x12\x18\n" +
+	"\apresent\x18\x02 \x03(\tR\apresent\x12\x18\n" +
+	"\amissing\x18\x03 \x03(\tR\amissing\x12\x18\n" +
+	"\aexpired\x18\x04 \x03(\tR\aexpired\x12\x1c\n" +
+	"\tsatisfied\x18\x05 \x01(\bR\tsatisfied\"\xeb\x03\n" +
+	"\x11CreateTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12!\n" +
+	"\fiteration_id\x18\x06 \x01(\tR\viterationId\x12\x1b\n" +
+	"\tparent_id\x18\a \x01(\tR\bparentId\x12\x1c\n" +
+	"\tcomponent\x18\b \x01(\tR\tcomponent\x12\x1f\n" +
+	"\vassignee_id\x18\t \x01(\tR\n" +
+	"assigneeId\x12\x1f\n" +
+	"\bpriority\x18\n" +
+	" \x01(\x05H\x00R\bpriority\x88\x01\x01\x12\x1f\n" +
+	"\bestimate\x18\v \x01(\x01H\x01R\bestimate\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\f \x03(\tR\tdependsOn\x12#\n" +
+	"\rpolicy_preset\x18\r \x01(\tR\fpolicyPreset\x12<\n" +
+	"\rwork_outcomes\x18\x0e \x01(\v2\x17.google.protobuf.StructR\fworkOutcomesB\v\n" +
+	"\t_priorityB\v\n" +
+	"\t_estimate\"?\n" +
+	"\x0eGetTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xd5\x01\n" +
+	"\x10ListTasksRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1a\n" +
+	"\bstatuses\x18\x02 \x03(\tR\bstatuses\x12\x14\n" +
+	"\x05types\x18\x03 \x03(\tR\x05types\x12!\n" +
+	"\fiteration_id\x18\x04 \x01(\tR\viterationId\x12\x1f\n" +
+	"\vassignee_id\x18\x05 \x01(\tR\n" +
+	"assigneeId\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\a \x01(\tR\x06cursor\"]\n" +
+	"\x11ListTasksResponse\x12'\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x11.workline.v1.TaskR\x05tasks\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\xd4\x01\n" +
+	"\x11UpdateTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12$\n" +
+	"\vassignee_id\x18\x04 \x01(\tH\x00R\n" +
+	"assigneeId\x88\x01\x01\x12\x1f\n" +
+	"\bpriority\x18\x05 \x01(\x05H\x01R\bpriority\x88\x01\x01\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05forceB\x0e\n" +
+	"\f_assignee_idB\v\n" +
+	"\t_priority\"f\n" +
+	"\x10ClaimTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12#\n" +
+	"\rlease_seconds\x18\x03 \x01(\x05R\fleaseSeconds\"\xc2\x01\n" +
+	"\x14ClaimNextTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12!\n" +
+	"\fiteration_id\x18\x02 \x01(\tR\viterationId\x12\x14\n" +
+	"\x05types\x18\x03 \x03(\tR\x05types\x12-\n" +
+	"\x12exclude_unassigned\x18\x04 \x01(\bR\x11excludeUnassigned\x12#\n" +
+	"\rlease_seconds\x18\x05 \x01(\x05R\fleaseSeconds\"h\n" +
+	"\x15ClaimNextTaskResponse\x12%\n" +
+	"\x04task\x18\x01 \x01(\v2\x11.workline.v1.TaskR\x04task\x12(\n" +
+	"\x05lease\x18\x02 \x01(\v2\x12.workline.v1.LeaseR\x05lease\"C\n" +
+	"\x12ReleaseTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x15\n" +
+	"\x13ReleaseTaskResponse\"\x98\x01\n" +
+	"\x13CompleteTaskRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12<\n" +
+	"\rwork_outcomes\x18\x03 \x01(\v2\x17.google.protobuf.StructR\fworkOutcomes\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05force\"I\n" +
+	"\x18GetTaskValidationRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\xbb\x01\n" +
+	"\x15AddAttestationRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1f\n" +
+	"\ventity_kind\x18\x02 \x01(\tR\n" +
+	"entityKind\x12\x1b\n" +
+	"\tentity_id\x18\x03 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x121\n" +
+	"\apayload\x18\x05 \x01(\v2\x17.google.protobuf.StructR\apayload\"\xb8\x01\n" +
+	"\x17ListAttestationsRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1f\n" +
+	"\ventity_kind\x18\x02 \x01(\tR\n" +
+	"entityKind\x12\x1b\n" +
+	"\tentity_id\x18\x03 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\"y\n" +
+	"\x18ListAttestationsResponse\x12<\n" +
+	"\fattestations\x18\x01 \x03(\v2\x18.workline.v1.AttestationR\fattestations\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"v\n" +
+	"\x12WatchEventsRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x1e\n" +
+	"\bafter_id\x18\x02 \x01(\x03H\x00R\aafterId\x88\x01\x01\x12\x14\n" +
+	"\x05types\x18\x03 \x03(\tR\x05typesB\v\n" +
+	"\t_after_id2\x92\a\n" +
+	"\bWorkline\x12?\n" +
+	"\n" +
+	"CreateTask\x12\x1e.workline.v1.CreateTaskRequest\x1a\x11.workline.v1.Task\x129\n" +
+	"\aGetTask\x12\x1b.workline.v1.GetTaskRequest\x1a\x11.workline.v1.Task\x12J\n" +
+	"\tListTasks\x12\x1d.workline.v1.ListTasksRequest\x1a\x1e.workline.v1.ListTasksResponse\x12?\n" +
+	"\n" +
+	"UpdateTask\x12\x1e.workline.v1.UpdateTaskRequest\x1a\x11.workline.v1.Task\x12>\n" +
+	"\tClaimTask\x12\x1d.workline.v1.ClaimTaskRequest\x1a\x12.workline.v1.Lease\x12V\n" +
+	"\rClaimNextTask\x12!.workline.v1.ClaimNextTaskRequest\x1a\".workline.v1.ClaimNextTaskResponse\x12P\n" +
+	"\vReleaseTask\x12\x1f.workline.v1.ReleaseTaskRequest\x1a .workline.v1.ReleaseTaskResponse\x12C\n" +
+	"\fCompleteTask\x12 .workline.v1.CompleteTaskRequest\x1a\x11.workline.v1.Task\x12W\n" +
+	"\x11GetTaskValidation\x12%.workline.v1.GetTaskValidationRequest\x1a\x1b.workline.v1.TaskValidation\x12N\n" +
+	"\x0eAddAttestation\x12\".workline.v1.AddAttestationRequest\x1a\x18.workline.v1.Attestation\x12_\n" +
+	"\x10ListAttestations\x12$.workline.v1.ListAttestationsRequest\x1a%.workline.v1.ListAttestationsResponse\x12D\n" +
+	"\vWatchEvents\x12\x1f.workline.v1.WatchEventsRequest\x1a\x12.workline.v1.Event0\x01B0Z.workline/internal/server/worklinev1;worklinev1b\x06proto3"
+
+var (
+	file_workline_v1_workline_proto_rawDescOnce sync.Once
+	file_workline_v1_workline_proto_rawDescData []byte
+)
+
+func file_workline_v1_workline_proto_rawDescGZIP() []byte {
+	file_workline_v1_workline_proto_rawDescOnce.Do(func() {
+		file_workline_v1_workline_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_workline_v1_workline_proto_rawDesc), len(file_workline_v1_workline_proto_rawDesc)))
+	})
+	return file_workline_v1_workline_proto_rawDescData
+}
+
+var file_workline_v1_workline_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_workline_v1_workline_proto_goTypes = []any{
+	(*Task)(nil),                     // 0: workline.v1.Task
+	(*Lease)(nil),                    // 1: workline.v1.Lease
+	(*Attestation)(nil),              // 2: workline.v1.Attestation
+	(*Event)(nil),                    // 3: workline.v1.Event
+	(*TaskValidation)(nil),           // 4: workline.v1.TaskValidation
+	(*CreateTaskRequest)(nil),        // 5: workline.v1.CreateTaskRequest
+	(*GetTaskRequest)(nil),           // 6: workline.v1.GetTaskRequest
+	(*ListTasksRequest)(nil),         // 7: workline.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),        // 8: workline.v1.ListTasksResponse
+	(*UpdateTaskRequest)(nil),        // 9: workline.v1.UpdateTaskRequest
+	(*ClaimTaskRequest)(nil),         // 10: workline.v1.ClaimTaskRequest
+	(*ClaimNextTaskRequest)(nil),     // 11: workline.v1.ClaimNextTaskRequest
+	(*ClaimNextTaskResponse)(nil),    // 12: workline.v1.ClaimNextTaskResponse
+	(*ReleaseTaskRequest)(nil),       // 13: workline.v1.ReleaseTaskRequest
+	(*ReleaseTaskResponse)(nil),      // 14: workline.v1.ReleaseTaskResponse
+	(*CompleteTaskRequest)(nil),      // 15: workline.v1.CompleteTaskRequest
+	(*GetTaskValidationRequest)(nil), // 16: workline.v1.GetTaskValidationRequest
+	(*AddAttestationRequest)(nil),    // 17: workline.v1.AddAttestationRequest
+	(*ListAttestationsRequest)(nil),  // 18: workline.v1.ListAttestationsRequest
+	(*ListAttestationsResponse)(nil), // 19: workline.v1.ListAttestationsResponse
+	(*WatchEventsRequest)(nil),       // 20: workline.v1.WatchEventsRequest
+	(*structpb.Struct)(nil),          // 21: google.protobuf.Struct
+}
+var file_workline_v1_workline_proto_depIdxs = []int32{
+	21, // 0: workline.v1.Task.work_outcomes:type_name -> google.protobuf.Struct
+	21, // 1: workline.v1.Attestation.payload:type_name -> google.protobuf.Struct
+	21, // 2: workline.v1.Event.payload:type_name -> google.protobuf.Struct
+	21, // 3: workline.v1.CreateTaskRequest.work_outcomes:type_name -> google.protobuf.Struct
+	0,  // 4: workline.v1.ListTasksResponse.tasks:type_name -> workline.v1.Task
+	0,  // 5: workline.v1.ClaimNextTaskResponse.task:type_name -> workline.v1.Task
+	1,  // 6: workline.v1.ClaimNextTaskResponse.lease:type_name -> workline.v1.Lease
+	21, // 7: workline.v1.CompleteTaskRequest.work_outcomes:type_name -> google.protobuf.Struct
+	21, // 8: workline.v1.AddAttestationRequest.payload:type_name -> google.protobuf.Struct
+	2,  // 9: workline.v1.ListAttestationsResponse.attestations:type_name -> workline.v1.Attestation
+	5,  // 10: workline.v1.Workline.CreateTask:input_type -> workline.v1.CreateTaskRequest
+	6,  // 11: workline.v1.Workline.GetTask:input_type -> workline.v1.GetTaskRequest
+	7,  // 12: workline.v1.Workline.ListTasks:input_type -> workline.v1.ListTasksRequest
+	9,  // 13: workline.v1.Workline.UpdateTask:input_type -> workline.v1.UpdateTaskRequest
+	10, // 14: workline.v1.Workline.ClaimTask:input_type -> workline.v1.ClaimTaskRequest
+	11, // 15: workline.v1.Workline.ClaimNextTask:input_type -> workline.v1.ClaimNextTaskRequest
+	13, // 16: workline.v1.Workline.ReleaseTask:input_type -> workline.v1.ReleaseTaskRequest
+	15, // 17: workline.v1.Workline.CompleteTask:input_type -> workline.v1.CompleteTaskRequest
+	16, // 18: workline.v1.Workline.GetTaskValidation:input_type -> workline.v1.GetTaskValidationRequest
+	17, // 19: workline.v1.Workline.AddAttestation:input_type -> workline.v1.AddAttestationRequest
+	18, // 20: workline.v1.Workline.ListAttestations:input_type -> workline.v1.ListAttestationsRequest
+	20, // 21: workline.v1.Workline.WatchEvents:input_type -> workline.v1.WatchEventsRequest
+	0,  // 22: workline.v1.Workline.CreateTask:output_type -> workline.v1.Task
+	0,  // 23: workline.v1.Workline.GetTask:output_type -> workline.v1.Task
+	8,  // 24: workline.v1.Workline.ListTasks:output_type -> workline.v1.ListTasksResponse
+	0,  // 25: workline.v1.Workline.UpdateTask:output_type -> workline.v1.Task
+	1,  // 26: workline.v1.Workline.ClaimTask:output_type -> workline.v1.Lease
+	12, // 27: workline.v1.Workline.ClaimNextTask:output_type -> workline.v1.ClaimNextTaskResponse
+	14, // 28: workline.v1.Workline.ReleaseTask:output_type -> workline.v1.ReleaseTaskResponse
+	0,  // 29: workline.v1.Workline.CompleteTask:output_type -> workline.v1.Task
+	4,  // 30: workline.v1.Workline.GetTaskValidation:output_type -> workline.v1.TaskValidation
+	2,  // 31: workline.v1.Workline.AddAttestation:output_type -> workline.v1.Attestation
+	19, // 32: workline.v1.Workline.ListAttestations:output_type -> workline.v1.ListAttestationsResponse
+	3,  // 33: workline.v1.Workline.WatchEvents:output_type -> workline.v1.Event
+	22, // [22:34] is the sub-list for method output_type
+	10, // [10:22] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_workline_v1_workline_proto_init() }
+func file_workline_v1_workline_proto_init() {
+	if File_workline_v1_workline_proto != nil {
+		return
+	}
+	file_workline_v1_workline_proto_msgTypes[0].OneofWrappers = []any{}
+	file_workline_v1_workline_proto_msgTypes[2].OneofWrappers = []any{}
+	file_workline_v1_workline_proto_msgTypes[5].OneofWrappers = []any{}
+	file_workline_v1_workline_proto_msgTypes[9].OneofWrappers = []any{}
+	file_workline_v1_workline_proto_msgTypes[20].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_workline_v1_workline_proto_rawDesc), len(file_workline_v1_workline_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_workline_v1_workline_proto_goTypes,
+		DependencyIndexes: file_workline_v1_workline_proto_depIdxs,
+		MessageInfos:      file_workline_v1_workline_proto_msgTypes,
+	}.Build()
+	File_workline_v1_workline_proto = out.File
+	file_workline_v1_workline_proto_goTypes = nil
+	file_workline_v1_workline_proto_depIdxs = nil
+}
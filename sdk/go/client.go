@@ -38,6 +38,29 @@ type Task struct {
 	Title     string `json:"title"`
 	Type      string `json:"type"`
 	Status    string `json:"status"`
+	// ExternalRefs maps external systems to the task's ids there.
+	ExternalRefs         map[string]string `json:"external_refs,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	AssigneeID           *string           `json:"assignee_id,omitempty"`
+	Priority             *int              `json:"priority,omitempty"`
+	WorkOutcomes         map[string]any    `json:"work_outcomes,omitempty"`
+	RequiredAttestations []string          `json:"required_attestations,omitempty"`
+	UpdatedAt            string            `json:"updated_at,omitempty"`
+	DueAt                *string           `json:"due_at,omitempty"`
+	// Stale is set while the task sits without an update past the
+	// project's staleness threshold.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// TaskInput is a task to create with CreateTaskFrom. ID is optional.
+type TaskInput struct {
+	ID           string         `json:"id,omitempty"`
+	Type         string         `json:"type"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description,omitempty"`
+	AssigneeID   *string        `json:"assignee_id,omitempty"`
+	Priority     *int           `json:"priority,omitempty"`
+	WorkOutcomes map[string]any `json:"work_outcomes,omitempty"`
 }
 
 // Attestation represents a proof entry.
@@ -104,6 +127,20 @@ type PaginatedEvents struct {
 	NextCursor string  `json:"next_cursor"`
 }
 
+// Change is an entity touched by events after a changes cursor.
+type Change struct {
+	EntityKind string   `json:"entity_kind"`
+	EntityID   string   `json:"entity_id"`
+	EventID    int64    `json:"event_id"`
+	Types      []string `json:"types"`
+}
+
+// Changes is a long-poll result; Cursor is the since of the next call.
+type Changes struct {
+	Cursor  int64    `json:"cursor"`
+	Changes []Change `json:"changes"`
+}
+
 // CreateTask creates a task.
 func (c *Client) CreateTask(ctx context.Context, title, taskType string) (Task, error) {
 	body := map[string]any{
@@ -115,6 +152,49 @@ func (c *Client) CreateTask(ctx context.Context, title, taskType string) (Task,
 	return resp, err
 }
 
+// CreateTaskFrom creates a task with more than a title and type.
+func (c *Client) CreateTaskFrom(ctx context.Context, in TaskInput) (Task, error) {
+	var resp Task
+	err := c.do(ctx, http.MethodPost, c.projectPath("tasks"), in, &resp)
+	return resp, err
+}
+
+// GetTask fetches a task by id.
+func (c *Client) GetTask(ctx context.Context, id string) (Task, error) {
+	var resp Task
+	err := c.do(ctx, http.MethodGet, c.projectPath("tasks/"+url.PathEscape(id)), nil, &resp)
+	return resp, err
+}
+
+// UpdateTask sets the given fields of a task, such as status, assignee_id,
+// priority or work_outcomes; a nil value clears a field.
+func (c *Client) UpdateTask(ctx context.Context, id string, fields map[string]any) (Task, error) {
+	var resp Task
+	err := c.do(ctx, http.MethodPatch, c.projectPath("tasks/"+url.PathEscape(id)), fields, &resp)
+	return resp, err
+}
+
+// ClaimTask takes the lease on a task, which status and work outcome updates
+// need.
+func (c *Client) ClaimTask(ctx context.Context, id string, leaseSeconds int) error {
+	endpoint := c.projectPath(fmt.Sprintf("tasks/%s/claim?lease_seconds=%d", url.PathEscape(id), leaseSeconds))
+	return c.do(ctx, http.MethodPost, endpoint, nil, nil)
+}
+
+// ReleaseTask gives up the lease on a task.
+func (c *Client) ReleaseTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, c.projectPath(fmt.Sprintf("tasks/%s/release", url.PathEscape(id))), nil, nil)
+}
+
+// TaskByExternalRef finds the task linked to an id in an external system,
+// such as ("jira", "ABC-123") or ("github", "org/repo#45").
+func (c *Client) TaskByExternalRef(ctx context.Context, system, externalID string) (Task, error) {
+	var resp Task
+	endpoint := c.projectPath(fmt.Sprintf("tasks/by-external/%s/%s", url.PathEscape(system), url.PathEscape(externalID)))
+	err := c.do(ctx, http.MethodGet, endpoint, nil, &resp)
+	return resp, err
+}
+
 // AddAttestation adds a proof.
 func (c *Client) AddAttestation(ctx context.Context, entityKind, entityID, kind string, payload any) (Attestation, error) {
 	body := map[string]any{
@@ -128,6 +208,39 @@ func (c *Client) AddAttestation(ctx context.Context, entityKind, entityID, kind
 	return resp, err
 }
 
+// AttestationInput is one item of an AddAttestations batch.
+type AttestationInput struct {
+	EntityKind string `json:"entity_kind"`
+	EntityID   string `json:"entity_id"`
+	Kind       string `json:"kind"`
+	Payload    any    `json:"payload,omitempty"`
+}
+
+// BatchResult is the outcome of one batch item; Error is set when it was rejected.
+type BatchResult struct {
+	Index       int          `json:"index"`
+	Status      int          `json:"status"`
+	Attestation *Attestation `json:"attestation,omitempty"`
+	Error       *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// AttestationBatch summarises an AddAttestations call.
+type AttestationBatch struct {
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	Results []BatchResult `json:"results"`
+}
+
+// AddAttestations records up to 100 proofs in one request.
+func (c *Client) AddAttestations(ctx context.Context, items []AttestationInput) (AttestationBatch, error) {
+	var resp AttestationBatch
+	err := c.do(ctx, http.MethodPost, c.projectPath("attestations/batch"), map[string]any{"attestations": items}, &resp)
+	return resp, err
+}
+
 // Events returns recent events.
 func (c *Client) Events(ctx context.Context, limit int) ([]Event, error) {
 	page, err := c.EventsPage(ctx, limit, "")
@@ -152,6 +265,23 @@ func (c *Client) EventsPage(ctx context.Context, limit int, cursor string) (Pagi
 	return resp, err
 }
 
+// Changes waits up to wait for events after the since cursor and returns the
+// entities they touched; pass the returned Cursor as the next since. A since
+// of 0 starts from the first event. Keep wait below the client's Timeout.
+func (c *Client) Changes(ctx context.Context, since int64, wait time.Duration, entityKinds ...string) (Changes, error) {
+	q := url.Values{}
+	q.Set("since", fmt.Sprintf("%d", since))
+	if wait > 0 {
+		q.Set("wait", wait.String())
+	}
+	if len(entityKinds) > 0 {
+		q.Set("entity_kind", strings.Join(entityKinds, ","))
+	}
+	var resp Changes
+	err := c.do(ctx, http.MethodGet, c.projectPath("changes?"+q.Encode()), nil, &resp)
+	return resp, err
+}
+
 // ActorProfile returns the mission, actions, and attestations for an actor.
 func (c *Client) ActorProfile(ctx context.Context, actorID string) (ActorProfile, error) {
 	var resp ActorProfile
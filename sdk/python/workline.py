@@ -1,6 +1,6 @@
 import json
 import urllib.parse
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from typing import Any, Dict, List, Optional
 
 import requests
@@ -13,6 +13,7 @@ class Task:
     title: str
     type: str
     status: str
+    external_refs: Dict[str, str] = field(default_factory=dict)
 
 
 @dataclass
@@ -93,14 +94,14 @@ class WorklineClient:
     def _project_path(self, suffix: str) -> str:
         return urllib.parse.urljoin(self.base_url, f"/v0/projects/{self.project_id}/{suffix}")
 
-    def _request(self, method: str, url: str, body: Optional[Dict[str, Any]] = None):
+    def _request(self, method: str, url: str, body: Optional[Any] = None, timeout: Optional[float] = None):
         headers = {"Content-Type": "application/json"}
         if self.access_token:
             headers["Authorization"] = f"Bearer {self.access_token}"
         elif self.api_key:
             headers["X-Api-Key"] = self.api_key
         data = json.dumps(body) if body is not None else None
-        resp = self.session.request(method, url, data=data, headers=headers, timeout=self.timeout)
+        resp = self.session.request(method, url, data=data, headers=headers, timeout=timeout or self.timeout)
         if resp.status_code >= 300:
             try:
                 err = resp.json()
@@ -120,6 +121,20 @@ class WorklineClient:
             title=data["title"],
             type=data["type"],
             status=data["status"],
+            external_refs=data.get("external_refs") or {},
+        )
+
+    def task_by_external_ref(self, system: str, external_id: str) -> Task:
+        quoted = urllib.parse.quote(external_id, safe="")
+        url = self._project_path(f"tasks/by-external/{urllib.parse.quote(system, safe='')}/{quoted}")
+        data = self._request("GET", url)
+        return Task(
+            id=data["id"],
+            project_id=data["project_id"],
+            title=data["title"],
+            type=data["type"],
+            status=data["status"],
+            external_refs=data.get("external_refs") or {},
         )
 
     def add_attestation(self, entity_kind: str, entity_id: str, kind: str, payload: Any = None) -> Attestation:
@@ -139,6 +154,21 @@ class WorklineClient:
             payload=data.get("payload"),
         )
 
+    def add_attestations(self, attestations: List[Dict[str, Any]]) -> Dict[str, Any]:
+        """Records up to 100 attestations at once; see results[i]["error"] for rejected items."""
+        url = self._project_path("attestations/batch")
+        return self._request("POST", url, {"attestations": attestations})
+
+    def changes(self, since: int = 0, wait: float = 0, entity_kinds: Optional[List[str]] = None) -> Dict[str, Any]:
+        """Waits up to wait seconds for events after the since cursor; returns {"cursor", "changes"}."""
+        params: Dict[str, Any] = {"since": since}
+        if wait:
+            params["wait"] = f"{wait}s"
+        if entity_kinds:
+            params["entity_kind"] = ",".join(entity_kinds)
+        url = self._project_path(f"changes?{urllib.parse.urlencode(params)}")
+        return self._request("GET", url, timeout=self.timeout + wait)
+
     def events(self, limit: int = 20) -> List[Event]:
         url = self._project_path(f"events?limit={limit}")
         data = self._request("GET", url)
@@ -177,6 +207,26 @@ class WorklineClient:
         url = self._project_path(suffix)
         return self._request("GET", url)
 
+    def suggest_assignee(self, task_id: str) -> List[Dict[str, Any]]:
+        """Eligible actors for a task, least loaded first."""
+        url = self._project_path(f"tasks/{task_id}/suggest-assignee")
+        return self._request("GET", url)
+
+    def claim_next(
+        self,
+        iteration_id: Optional[str] = None,
+        types: Optional[List[str]] = None,
+        lease_seconds: int = 900,
+    ) -> Optional[Dict[str, Any]]:
+        """Claim the next available task; returns {"task", "lease"} or None."""
+        params: Dict[str, Any] = {"lease_seconds": lease_seconds}
+        if iteration_id:
+            params["iteration_id"] = iteration_id
+        if types:
+            params["type"] = ",".join(types)
+        url = self._project_path(f"tasks/claim-next?{urllib.parse.urlencode(params)}")
+        return self._request("POST", url)
+
     def decompose_task(self, task_id: str, subtasks: List[Dict[str, Any]]) -> Dict[str, Any]:
         url = self._project_path(f"tasks/{task_id}/decompose")
         return self._request("POST", url, {"subtasks": subtasks})
@@ -210,6 +260,11 @@ class WorklineClient:
         url = self._project_path(f"tasks/{task_id}/work-outcomes/merge")
         return self._request("POST", url, {"path": path, "value": value})
 
+    def patch_work_outcomes(self, task_id: str, operations: List[Dict[str, Any]]) -> Dict[str, Any]:
+        """Apply RFC 6902 add/remove/replace/test operations atomically."""
+        url = self._project_path(f"tasks/{task_id}/work-outcomes/patch")
+        return self._request("POST", url, operations)
+
     def actor_profile(self, actor_id: Optional[str] = None) -> ActorProfile:
         target = actor_id or self.actor_id
         if not target: